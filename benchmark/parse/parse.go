@@ -4,15 +4,22 @@
 
 // Package parse provides support for parsing benchmark results as
 // generated by 'go test -bench'.
+//
+// Beyond the results of the benchmarks, ParseResults reads the
+// configuration lines, such as "goos: linux", and the unit metadata
+// lines, such as "Unit ns/op better=lower", of the Go benchmark data
+// format (https://go.dev/design/14313-benchmark-format).
+// A Reader reports the same information as a sequence of events,
+// as the output of a benchmark run arrives.
 package parse // import "golang.org/x/tools/benchmark/parse"
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Flags used by Benchmark.Measured to indicate
@@ -34,6 +41,77 @@ type Benchmark struct {
 	MBPerS            float64 // MB processed per second
 	Measured          int     // which measurements were recorded
 	Ord               int     // ordinal position within a benchmark run
+
+	// Extra holds the measurements in units other than those above,
+	// such as those reported by testing.B.ReportMetric, in order.
+	Extra []Metric
+
+	// Config holds the configuration in effect for the benchmark,
+	// such as "goos" and "pkg", as set by the configuration lines
+	// read by ParseResults or ParseSet before it, if any.
+	// It may be shared with other benchmarks and must not be modified.
+	Config map[string]string
+}
+
+// A Metric is a measurement of a benchmark in some unit.
+type Metric struct {
+	Value float64
+	Unit  string // such as "ns/op" or "p99-ns"
+}
+
+// Metric returns the measurement of the benchmark in the given unit,
+// if it was recorded.
+func (b *Benchmark) Metric(unit string) (float64, bool) {
+	switch unit {
+	case "ns/op":
+		return b.NsPerOp, b.Measured&NsPerOp != 0
+	case "MB/s":
+		return b.MBPerS, b.Measured&MBPerS != 0
+	case "B/op":
+		return float64(b.AllocedBytesPerOp), b.Measured&AllocedBytesPerOp != 0
+	case "allocs/op":
+		return float64(b.AllocsPerOp), b.Measured&AllocsPerOp != 0
+	}
+	for _, m := range b.Extra {
+		if m.Unit == unit {
+			return m.Value, true
+		}
+	}
+	return 0, false
+}
+
+// A Name is the structure of a benchmark name,
+// such as "BenchmarkDecode/size=1K/gzip-8".
+type Name struct {
+	Base  string   // name of the top-level benchmark, such as "BenchmarkDecode"
+	Sub   []string // names of the sub-benchmarks, from the outermost, such as "size=1K" and "gzip"
+	Procs int      // GOMAXPROCS, from a suffix such as "-8"; 0 if there is none, as when GOMAXPROCS is 1
+}
+
+// ParseName returns the structure of the benchmark name.
+func ParseName(name string) Name {
+	var n Name
+	if i := strings.LastIndexByte(name, '-'); i >= 0 && i > strings.LastIndexByte(name, '/') {
+		if procs, err := strconv.Atoi(name[i+1:]); err == nil && procs > 0 && name[i+1] != '+' {
+			n.Procs = procs
+			name = name[:i]
+		}
+	}
+	parts := strings.Split(name, "/")
+	n.Base = parts[0]
+	if len(parts) > 1 {
+		n.Sub = parts[1:]
+	}
+	return n
+}
+
+// String returns the benchmark name with the structure n.
+func (n Name) String() string {
+	s := strings.Join(append([]string{n.Base}, n.Sub...), "/")
+	if n.Procs > 0 {
+		s += "-" + strconv.Itoa(n.Procs)
+	}
+	return s
 }
 
 // ParseLine extracts a Benchmark from a single line of testing.B
@@ -83,6 +161,10 @@ func (b *Benchmark) parseMeasurement(quant string, unit string) {
 			b.AllocsPerOp = i
 			b.Measured |= AllocsPerOp
 		}
+	default:
+		if f, err := strconv.ParseFloat(quant, 64); err == nil {
+			b.Extra = append(b.Extra, Metric{f, unit})
+		}
 	}
 }
 
@@ -101,6 +183,9 @@ func (b *Benchmark) String() string {
 	if (b.Measured & AllocsPerOp) != 0 {
 		fmt.Fprintf(buf, " %d allocs/op", b.AllocsPerOp)
 	}
+	for _, m := range b.Extra {
+		fmt.Fprintf(buf, " %s %s", strconv.FormatFloat(m.Value, 'f', -1, 64), m.Unit)
+	}
 	return buf.String()
 }
 
@@ -112,20 +197,71 @@ type Set map[string][]*Benchmark
 // ParseSet preserves the order of benchmarks that have identical
 // names.
 func ParseSet(r io.Reader) (Set, error) {
-	bb := make(Set)
-	scan := bufio.NewScanner(r)
-	ord := 0
-	for scan.Scan() {
-		if b, err := ParseLine(scan.Text()); err == nil {
-			b.Ord = ord
-			ord++
-			bb[b.Name] = append(bb[b.Name], b)
+	res, err := ParseResults(r)
+	if err != nil {
+		return nil, err
+	}
+	return res.Set(), nil
+}
+
+// Results holds the benchmarks and metadata parsed from testing.B output.
+type Results struct {
+	Benchmarks []*Benchmark // in order
+
+	// Units holds the metadata of units, such as "better" for
+	// "ns/op", by unit and then by key, from lines such as
+	// "Unit ns/op better=lower".
+	Units map[string]map[string]string
+}
+
+// ParseResults extracts the benchmarks and metadata from testing.B output,
+// ignoring any other lines.
+func ParseResults(r io.Reader) (*Results, error) {
+	res := &Results{Units: make(map[string]map[string]string)}
+	rd := NewReader(r)
+	for {
+		ev, err := rd.Next()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch ev.Kind {
+		case ResultEvent:
+			res.Benchmarks = append(res.Benchmarks, ev.Benchmark)
+		case UnitEvent:
+			if res.Units[ev.Unit] == nil {
+				res.Units[ev.Unit] = make(map[string]string)
+			}
+			res.Units[ev.Unit][ev.Key] = ev.Value
 		}
 	}
+}
 
-	if err := scan.Err(); err != nil {
-		return nil, err
+// parseConfigLine parses a configuration line of the form "key: value",
+// whose key begins with a lower case letter and contains no space or
+// upper case letters. An empty value unsets the key.
+func parseConfigLine(line string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(line, ":")
+	if !ok || key == "" {
+		return "", "", false
 	}
+	for i, r := range key {
+		if i == 0 && !unicode.IsLower(r) || unicode.IsSpace(r) || unicode.IsUpper(r) {
+			return "", "", false
+		}
+	}
+	if value != "" && value[0] != ' ' && value[0] != '\t' {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
 
-	return bb, nil
+// Set returns the benchmarks of res as a Set.
+func (res *Results) Set() Set {
+	bb := make(Set)
+	for _, b := range res.Benchmarks {
+		bb[b.Name] = append(bb[b.Name], b)
+	}
+	return bb
 }
@@ -68,8 +68,18 @@ func TestParseLine(t *testing.T) {
 		{
 			line: "BenchmarkBridge	100000000	        19.6 smoots", // unknown unit
 			want: &Benchmark{
-				Name: "BenchmarkBridge",
-				N:    100000000,
+				Name:  "BenchmarkBridge",
+				N:     100000000,
+				Extra: []Metric{{19.6, "smoots"}},
+			},
+		},
+		{
+			line: "BenchmarkDecode/size=1K-8	  5000	  2500 ns/op	  12.5 p99-ms	  3 B/op	  0.75 hit-ratio",
+			want: &Benchmark{
+				Name: "BenchmarkDecode/size=1K-8",
+				N:    5000, NsPerOp: 2500, AllocedBytesPerOp: 3,
+				Measured: NsPerOp | AllocedBytesPerOp,
+				Extra:    []Metric{{12.5, "p99-ms"}, {0.75, "hit-ratio"}},
 			},
 		},
 		{
@@ -195,6 +205,16 @@ func TestString(t *testing.T) {
 			},
 			wanted: "BenchmarkTest 100000000 5 allocs/op",
 		},
+		{
+			name: "extraTest",
+			input: &Benchmark{
+				Name: "BenchmarkTest",
+				N:    100000000, NsPerOp: 19.6,
+				Measured: NsPerOp,
+				Extra:    []Metric{{12.5, "p99-ms"}, {3, "hits/op"}},
+			},
+			wanted: "BenchmarkTest 100000000 19.60 ns/op 12.5 p99-ms 3 hits/op",
+		},
 	}
 
 	for _, tt := range tests {
@@ -206,3 +226,83 @@ func TestString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Name
+	}{
+		{"BenchmarkF", Name{Base: "BenchmarkF"}},
+		{"BenchmarkF-8", Name{Base: "BenchmarkF", Procs: 8}},
+		{"BenchmarkF/size=1K/gzip-16", Name{Base: "BenchmarkF", Sub: []string{"size=1K", "gzip"}, Procs: 16}},
+		{"BenchmarkF/a-b", Name{Base: "BenchmarkF", Sub: []string{"a-b"}}},
+		{"BenchmarkF-2/x", Name{Base: "BenchmarkF-2", Sub: []string{"x"}}},
+		{"BenchmarkF/-0", Name{Base: "BenchmarkF", Sub: []string{"-0"}}},
+		{"BenchmarkF/x-+4", Name{Base: "BenchmarkF", Sub: []string{"x-+4"}}},
+	}
+	for _, tt := range tests {
+		have := ParseName(tt.name)
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("ParseName(%q) = %+v, want %+v", tt.name, have, tt.want)
+		}
+		if s := have.String(); s != tt.name {
+			t.Errorf("ParseName(%q).String() = %q", tt.name, s)
+		}
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	in := `goos: linux
+goarch: amd64
+pkg: example.com/a
+cpu: Some CPU @ 2.00GHz
+Unit ns/op better=lower assume=nothing
+Unit hits/op better=higher
+BenchmarkA-8	100	10 ns/op	5 hits/op
+PASS
+ok  	example.com/a	1.0s
+pkg: example.com/b
+note:
+BenchmarkB	200	20 ns/op
+Not config: the key has upper case letters
+key:novalue
+`
+	res, err := ParseResults(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Benchmarks) != 2 {
+		t.Fatalf("parsed %d benchmarks, want 2", len(res.Benchmarks))
+	}
+	a, b := res.Benchmarks[0], res.Benchmarks[1]
+	wantA := map[string]string{"goos": "linux", "goarch": "amd64", "pkg": "example.com/a", "cpu": "Some CPU @ 2.00GHz"}
+	if !reflect.DeepEqual(a.Config, wantA) {
+		t.Errorf("config of A = %v, want %v", a.Config, wantA)
+	}
+	wantB := map[string]string{"goos": "linux", "goarch": "amd64", "pkg": "example.com/b", "cpu": "Some CPU @ 2.00GHz"}
+	if !reflect.DeepEqual(b.Config, wantB) {
+		t.Errorf("config of B = %v, want %v", b.Config, wantB)
+	}
+	if a.Ord != 0 || b.Ord != 1 {
+		t.Errorf("Ord = %d, %d, want 0, 1", a.Ord, b.Ord)
+	}
+	if v, ok := a.Metric("hits/op"); v != 5 || !ok {
+		t.Errorf("A.Metric(hits/op) = %v, %v, want 5, true", v, ok)
+	}
+	if v, ok := b.Metric("ns/op"); v != 20 || !ok {
+		t.Errorf("B.Metric(ns/op) = %v, %v, want 20, true", v, ok)
+	}
+	if _, ok := b.Metric("B/op"); ok {
+		t.Errorf("B.Metric(B/op) reported a measurement that was not recorded")
+	}
+	wantUnits := map[string]map[string]string{
+		"ns/op":   {"better": "lower", "assume": "nothing"},
+		"hits/op": {"better": "higher"},
+	}
+	if !reflect.DeepEqual(res.Units, wantUnits) {
+		t.Errorf("Units = %v, want %v", res.Units, wantUnits)
+	}
+	if set := res.Set(); len(set["BenchmarkA-8"]) != 1 || len(set["BenchmarkB"]) != 1 {
+		t.Errorf("Set = %v", set)
+	}
+}
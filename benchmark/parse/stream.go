@@ -0,0 +1,179 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// An EventKind is the kind of an Event.
+type EventKind int
+
+const (
+	StartEvent  EventKind = iota + 1 // a benchmark started running
+	ResultEvent                      // a benchmark reported its result
+	ConfigEvent                      // a configuration line set or unset a key
+	UnitEvent                        // a unit metadata line set a key of a unit
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case StartEvent:
+		return "StartEvent"
+	case ResultEvent:
+		return "ResultEvent"
+	case ConfigEvent:
+		return "ConfigEvent"
+	case UnitEvent:
+		return "UnitEvent"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// An Event is an item of testing.B output reported by a Reader.
+type Event struct {
+	Kind EventKind
+
+	// Name is the name of the benchmark of a StartEvent.
+	Name string
+
+	// Benchmark is the result of a ResultEvent,
+	// with its Ord and Config set.
+	Benchmark *Benchmark
+
+	// Key and Value are the key and value of a ConfigEvent,
+	// whose empty Value unsets the key, or of a UnitEvent.
+	Key, Value string
+
+	// Unit is the unit of a UnitEvent, such as "ns/op".
+	Unit string
+}
+
+// A Reader reads the events of testing.B output as it arrives,
+// so that a long benchmark run may be followed while it runs.
+//
+// The testing package prints the name of each benchmark before it
+// runs it, and its result on the same line when it completes. A Reader
+// reports a StartEvent as soon as it reads the name, and a ResultEvent
+// when it reads the rest of the line; each ResultEvent is preceded by
+// a StartEvent for the benchmark, even if the whole line arrived at
+// once. A Unit line, such as "Unit ns/op better=lower assume=nothing",
+// is reported as a UnitEvent for each of its keys.
+type Reader struct {
+	r       io.Reader
+	buf     []byte // input not yet parsed, starting at the beginning of a line
+	err     error  // error from r, reported once buf is parsed
+	started string // name of the benchmark whose StartEvent was reported for the current line
+	config  map[string]string
+	ord     int
+	pending []Event // events of the last line not yet reported
+}
+
+// NewReader returns a Reader that reads testing.B output from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// Next returns the next event. At the end of the input, Next returns
+// io.EOF; if reading the input fails, it returns the error.
+func (r *Reader) Next() (Event, error) {
+	for {
+		if len(r.pending) > 0 {
+			ev := r.pending[0]
+			r.pending = r.pending[1:]
+			return ev, nil
+		}
+		if i := bytes.IndexByte(r.buf, '\n'); i >= 0 {
+			line := string(bytes.TrimSuffix(r.buf[:i], []byte("\r")))
+			r.buf = r.buf[i+1:]
+			r.parseLine(line)
+			continue
+		}
+		if r.err != nil {
+			if len(r.buf) > 0 {
+				// The final line has no newline.
+				line := string(r.buf)
+				r.buf = nil
+				r.parseLine(line)
+				continue
+			}
+			return Event{}, r.err
+		}
+		if name, ok := parseStart(r.buf); ok && name != r.started {
+			r.started = name
+			return Event{Kind: StartEvent, Name: name}, nil
+		}
+		r.fill()
+	}
+}
+
+// fill reads more input into r.buf.
+func (r *Reader) fill() {
+	if len(r.buf) == cap(r.buf) {
+		buf := make([]byte, len(r.buf), max(2*cap(r.buf), 4096))
+		copy(buf, r.buf)
+		r.buf = buf
+	}
+	n, err := r.r.Read(r.buf[len(r.buf):cap(r.buf)])
+	r.buf = r.buf[:len(r.buf)+n]
+	if err != nil {
+		r.err = err
+	}
+}
+
+// parseStart reports whether the incomplete line is the name of a
+// benchmark printed before it runs, which is padded and followed by
+// a tab, and if so returns the name.
+func parseStart(line []byte) (string, bool) {
+	if !bytes.HasPrefix(line, []byte("Benchmark")) || !bytes.HasSuffix(line, []byte("\t")) {
+		return "", false
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) != 1 {
+		return "", false
+	}
+	return fields[0], true
+}
+
+// parseLine queues the events of a complete line.
+func (r *Reader) parseLine(line string) {
+	started := r.started
+	r.started = ""
+	if key, value, ok := parseConfigLine(line); ok {
+		// Copy the configuration, which earlier benchmarks share.
+		c := make(map[string]string, len(r.config)+1)
+		for k, v := range r.config {
+			c[k] = v
+		}
+		if value == "" {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		r.config = c
+		r.pending = append(r.pending, Event{Kind: ConfigEvent, Key: key, Value: value})
+		return
+	}
+	if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "Unit" {
+		for _, kv := range fields[2:] {
+			if k, v, ok := strings.Cut(kv, "="); ok && k != "" {
+				r.pending = append(r.pending, Event{Kind: UnitEvent, Unit: fields[1], Key: k, Value: v})
+			}
+		}
+		return
+	}
+	if b, err := ParseLine(line); err == nil {
+		b.Ord = r.ord
+		b.Config = r.config
+		r.ord++
+		if b.Name != started {
+			r.pending = append(r.pending, Event{Kind: StartEvent, Name: b.Name})
+		}
+		r.pending = append(r.pending, Event{Kind: ResultEvent, Benchmark: b})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// chunkReader returns one of its chunks from each call to Read.
+type chunkReader struct {
+	chunks []string
+	n      int // number of chunks read
+	err    error
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.n == len(r.chunks) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[r.n])
+	if n < len(r.chunks[r.n]) {
+		r.chunks[r.n] = r.chunks[r.n][n:]
+	} else {
+		r.n++
+	}
+	return n, nil
+}
+
+// describe returns a string describing the event.
+func describe(ev Event) string {
+	switch ev.Kind {
+	case StartEvent:
+		return "start " + ev.Name
+	case ResultEvent:
+		return fmt.Sprintf("result %d %s %v", ev.Benchmark.Ord, ev.Benchmark, ev.Benchmark.Config)
+	case ConfigEvent:
+		return fmt.Sprintf("config %s=%s", ev.Key, ev.Value)
+	case UnitEvent:
+		return fmt.Sprintf("unit %s %s=%s", ev.Unit, ev.Key, ev.Value)
+	}
+	return ev.Kind.String()
+}
+
+func TestReader(t *testing.T) {
+	cr := &chunkReader{chunks: []string{
+		"goos: linux\npkg: example.com/a\n",
+		"Unit ns/op better=lower assume=nothing\n",
+		"BenchmarkA-8   \t",
+		"     100\t  10 ns/op\n",
+		"BenchmarkB-8   \t     200\t  20 ns/op\nBenchmarkC-8 \t",
+		"\r\n",
+		"pkg:\n",
+		"BenchmarkD-8 \t 1 30 ns/op",
+	}}
+	want := []struct {
+		event string
+		read  int // number of chunks read before the event
+	}{
+		{"config goos=linux", 1},
+		{"config pkg=example.com/a", 1},
+		{"unit ns/op better=lower", 2},
+		{"unit ns/op assume=nothing", 2},
+		{"start BenchmarkA-8", 3}, // before the result arrives
+		{"result 0 BenchmarkA-8 100 10.00 ns/op map[goos:linux pkg:example.com/a]", 4},
+		{"start BenchmarkB-8", 5},
+		{"result 1 BenchmarkB-8 200 20.00 ns/op map[goos:linux pkg:example.com/a]", 5},
+		{"start BenchmarkC-8", 5}, // no result
+		{"config pkg=", 7},
+		{"start BenchmarkD-8", 8},
+		{"result 2 BenchmarkD-8 1 30.00 ns/op map[goos:linux]", 8},
+	}
+
+	r := NewReader(cr)
+	for i := 0; ; i++ {
+		ev, err := r.Next()
+		if err == io.EOF {
+			if i != len(want) {
+				t.Errorf("got %d events, want %d", i, len(want))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(want) {
+			t.Fatalf("unexpected event %q", describe(ev))
+		}
+		if got := describe(ev); got != want[i].event || cr.n != want[i].read {
+			t.Errorf("event %d = %q after reading %d chunks, want %q after %d", i, got, cr.n, want[i].event, want[i].read)
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	errRead := errors.New("read error")
+	r := NewReader(&chunkReader{chunks: []string{"BenchmarkA 1 2 ns/op\nBenchmarkB 1 3 ns/op"}, err: errRead})
+	var got []string
+	for {
+		ev, err := r.Next()
+		if err != nil {
+			if err != errRead {
+				t.Errorf("Next returned %v, want %v", err, errRead)
+			}
+			break
+		}
+		got = append(got, describe(ev))
+	}
+	want := "start BenchmarkA|result 0 BenchmarkA 1 2.00 ns/op map[]|start BenchmarkB|result 1 BenchmarkB 1 3.00 ns/op map[]"
+	if strings.Join(got, "|") != want {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestParseResultsLongLine(t *testing.T) {
+	in := "BenchmarkA 1 2 ns/op " + strings.Repeat("1 x/op ", 20000) + "\n"
+	res, err := ParseResults(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Benchmarks) != 1 || len(res.Benchmarks[0].Extra) != 20000 {
+		t.Errorf("failed to parse a long line")
+	}
+}
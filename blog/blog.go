@@ -13,6 +13,7 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -44,9 +45,10 @@ type Config struct {
 	Hostname      string        // Server host name, used for rendering ATOM feeds.
 	AnalyticsHTML template.HTML // Optional analytics HTML to insert at the beginning of <head>.
 
-	HomeArticles int    // Articles to display on the home page.
-	FeedArticles int    // Articles to include in Atom and JSON feeds.
-	FeedTitle    string // The title of the Atom XML feed
+	HomeArticles    int    // Articles to display on the home page.
+	FeedArticles    int    // Articles to include in Atom and JSON feeds.
+	TagFeedArticles int    // Articles to include in the feeds of each tag; if zero, FeedArticles.
+	FeedTitle       string // The title of the Atom XML feed
 
 	PlayEnabled     bool
 	ServeLocalLinks bool // rewrite golang.org/{pkg,cmd} links to host-less, relative paths.
@@ -74,9 +76,14 @@ type Server struct {
 	template  struct {
 		home, index, article, doc *template.Template
 	}
-	atomFeed []byte // pre-rendered Atom feed
-	jsonFeed []byte // pre-rendered JSON feed
-	content  http.Handler
+	atomFeed   []byte // pre-rendered Atom feed
+	jsonFeed   []byte // pre-rendered JSON feed, in the original format of /.json
+	jsonFeed11 []byte // pre-rendered JSON Feed 1.1
+
+	// pre-rendered Atom feeds and JSON Feeds 1.1 of each tag
+	tagAtomFeeds, tagJSONFeeds map[string][]byte
+
+	content http.Handler
 }
 
 // NewServer constructs a new Server using the specified config.
@@ -295,41 +302,52 @@ func (s *Server) loadDocs(root string) error {
 	return nil
 }
 
-// renderAtomFeed generates an XML Atom feed and stores it in the Server's
-// atomFeed field.
+// renderAtomFeed generates XML Atom feeds of all articles and of the
+// articles with each tag, and stores them in the Server's atomFeed and
+// tagAtomFeeds fields.
 func (s *Server) renderAtomFeed() error {
+	data, err := s.atomFeedOf(s.docs, "", s.cfg.FeedTitle, s.cfg.FeedArticles)
+	if err != nil {
+		return err
+	}
+	s.atomFeed = data
+	s.tagAtomFeeds = make(map[string][]byte)
+	for _, tag := range s.tags {
+		data, err := s.atomFeedOf(s.docTags[tag], tagFeedPath(tag), s.cfg.FeedTitle+" - "+tag, s.tagFeedArticles())
+		if err != nil {
+			return err
+		}
+		s.tagAtomFeeds[tag] = data
+	}
+	return nil
+}
+
+// atomFeedOf returns an XML Atom feed of at most max of the docs,
+// with the given title, whose URL is path+"/feed.atom" relative to
+// the base URL.
+func (s *Server) atomFeedOf(docs []*Doc, path, title string, max int) ([]byte, error) {
 	var updated time.Time
-	if len(s.docs) > 0 {
-		updated = s.docs[0].Time
+	if len(docs) > 0 {
+		updated = docs[0].Time
 	}
+	siteID := "tag:" + s.cfg.Hostname + ",2013:" + s.cfg.Hostname
 	feed := atom.Feed{
-		Title:   s.cfg.FeedTitle,
-		ID:      "tag:" + s.cfg.Hostname + ",2013:" + s.cfg.Hostname,
+		Title:   title,
+		ID:      siteID + path,
 		Updated: atom.Time(updated),
 		Link: []atom.Link{{
 			Rel:  "self",
-			Href: s.cfg.BaseURL + "/feed.atom",
+			Href: s.cfg.BaseURL + path + "/feed.atom",
 		}},
 	}
-	for i, doc := range s.docs {
-		if i >= s.cfg.FeedArticles {
+	for i, doc := range docs {
+		if i >= max {
 			break
 		}
 
-		// Use original article path as ID in atom feed
-		// to avoid articles being treated as new when renamed.
-		idPath := doc.Path
-		if len(doc.OldURL) > 0 {
-			old := doc.OldURL[0]
-			if !strings.HasPrefix(old, "/") {
-				old = "/" + old
-			}
-			idPath = old
-		}
-
 		e := &atom.Entry{
 			Title: doc.Title,
-			ID:    feed.ID + idPath,
+			ID:    siteID + idPath(doc),
 			Link: []atom.Link{{
 				Rel:  "alternate",
 				Href: doc.Permalink,
@@ -350,12 +368,33 @@ func (s *Server) renderAtomFeed() error {
 		}
 		feed.Entry = append(feed.Entry, e)
 	}
-	data, err := xml.Marshal(&feed)
-	if err != nil {
-		return err
+	return xml.Marshal(&feed)
+}
+
+// idPath returns the path that identifies doc in feeds: its original
+// path, so that articles are not treated as new when renamed.
+func idPath(doc *Doc) string {
+	if len(doc.OldURL) > 0 {
+		old := doc.OldURL[0]
+		if !strings.HasPrefix(old, "/") {
+			old = "/" + old
+		}
+		return old
 	}
-	s.atomFeed = data
-	return nil
+	return doc.Path
+}
+
+// tagFeedPath returns the path, relative to the base URL, of the
+// directory of the feeds of the given tag.
+func tagFeedPath(tag string) string {
+	return "/tags/" + url.PathEscape(tag)
+}
+
+func (s *Server) tagFeedArticles() int {
+	if s.cfg.TagFeedArticles != 0 {
+		return s.cfg.TagFeedArticles
+	}
+	return s.cfg.FeedArticles
 }
 
 type jsonItem struct {
@@ -390,9 +429,78 @@ func (s *Server) renderJSONFeed() error {
 		return err
 	}
 	s.jsonFeed = data
+
+	if s.jsonFeed11, err = s.jsonFeedOf(s.docs, "", s.cfg.FeedTitle, s.cfg.FeedArticles); err != nil {
+		return err
+	}
+	s.tagJSONFeeds = make(map[string][]byte)
+	for _, tag := range s.tags {
+		data, err := s.jsonFeedOf(s.docTags[tag], tagFeedPath(tag), s.cfg.FeedTitle+" - "+tag, s.tagFeedArticles())
+		if err != nil {
+			return err
+		}
+		s.tagJSONFeeds[tag] = data
+	}
 	return nil
 }
 
+// A jsonFeed11 is a feed in the JSON Feed format, version 1.1.
+// See https://www.jsonfeed.org/version/1.1/.
+type jsonFeed11 struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Items       []jsonFeed11Item `json:"items"`
+}
+
+type jsonFeed11Item struct {
+	ID            string             `json:"id"`
+	URL           string             `json:"url,omitempty"`
+	Title         string             `json:"title,omitempty"`
+	ContentHTML   string             `json:"content_html"`
+	DatePublished time.Time          `json:"date_published"`
+	Authors       []jsonFeed11Author `json:"authors,omitempty"`
+	Tags          []string           `json:"tags,omitempty"`
+}
+
+type jsonFeed11Author struct {
+	Name string `json:"name"`
+}
+
+// jsonFeedOf returns a JSON Feed 1.1 of at most max of the docs,
+// with the given title, whose URL is path+"/feed.json" relative to
+// the base URL.
+func (s *Server) jsonFeedOf(docs []*Doc, path, title string, max int) ([]byte, error) {
+	feed := jsonFeed11{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       title,
+		HomePageURL: s.cfg.BaseURL + "/",
+		FeedURL:     s.cfg.BaseURL + path + "/feed.json",
+		Items:       []jsonFeed11Item{},
+	}
+	for i, doc := range docs {
+		if i >= max {
+			break
+		}
+		item := jsonFeed11Item{
+			ID:            s.cfg.BaseURL + idPath(doc),
+			URL:           doc.Permalink,
+			Title:         doc.Title,
+			ContentHTML:   string(doc.HTML),
+			DatePublished: doc.Time,
+			Tags:          doc.Tags,
+		}
+		for _, a := range doc.Authors {
+			if name := authorName(a); name != "" {
+				item.Authors = append(item.Authors, jsonFeed11Author{Name: name})
+			}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return json.Marshal(feed)
+}
+
 // summary returns the first paragraph of text from the provided Doc.
 func summary(d *Doc) string {
 	if len(d.Sections) == 0 {
@@ -424,7 +532,8 @@ type rootData struct {
 }
 
 // ServeHTTP serves the front, index, and article pages
-// as well as the ATOM and JSON feeds.
+// as well as the ATOM and JSON feeds, including the feeds
+// of each tag at /tags/{tag}/feed.atom and /tags/{tag}/feed.json.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var (
 		d = rootData{
@@ -448,6 +557,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-type", "application/atom+xml; charset=utf-8")
 		w.Write(s.atomFeed)
 		return
+	case "/feed.json":
+		w.Header().Set("Content-type", "application/feed+json; charset=utf-8")
+		w.Write(s.jsonFeed11)
+		return
 	case "/.json":
 		if p := r.FormValue("jsonp"); validJSONPFunc.MatchString(p) {
 			w.Header().Set("Content-type", "application/javascript; charset=utf-8")
@@ -458,6 +571,18 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Write(s.jsonFeed)
 		return
 	default:
+		if tag, ok := strings.CutPrefix(p, "/tags/"); ok {
+			if tag, ok := strings.CutSuffix(tag, "/feed.atom"); ok && s.tagAtomFeeds[tag] != nil {
+				w.Header().Set("Content-type", "application/atom+xml; charset=utf-8")
+				w.Write(s.tagAtomFeeds[tag])
+				return
+			}
+			if tag, ok := strings.CutSuffix(tag, "/feed.json"); ok && s.tagJSONFeeds[tag] != nil {
+				w.Header().Set("Content-type", "application/feed+json; charset=utf-8")
+				w.Write(s.tagJSONFeeds[tag])
+				return
+			}
+		}
 		if redir, ok := s.redirects[p]; ok {
 			http.Redirect(w, r, redir, http.StatusMovedPermanently)
 			return
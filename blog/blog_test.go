@@ -6,7 +6,16 @@ package blog
 
 import (
 	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/tools/blog/atom"
+	"golang.org/x/tools/present"
 )
 
 func TestLinkRewrite(t *testing.T) {
@@ -42,3 +51,79 @@ func TestLinkRewrite(t *testing.T) {
 		}
 	}
 }
+
+func TestFeeds(t *testing.T) {
+	doc := func(title string, day int, tags ...string) *Doc {
+		return &Doc{
+			Doc: &present.Doc{
+				Title: title,
+				Time:  time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+				Tags:  tags,
+			},
+			Path:      "/" + strings.ToLower(title),
+			Permalink: "https://blog.example.com/" + strings.ToLower(title),
+			HTML:      "<p>" + template.HTML(title) + "</p>",
+		}
+	}
+	s := &Server{cfg: Config{
+		BaseURL:         "https://blog.example.com",
+		Hostname:        "blog.example.com",
+		FeedTitle:       "Blog",
+		FeedArticles:    10,
+		TagFeedArticles: 1,
+	}}
+	s.docs = []*Doc{doc("Third", 3, "go"), doc("Second", 2, "tools"), doc("First", 1, "go", "tools")}
+	s.docTags = make(map[string][]*Doc)
+	for _, d := range s.docs {
+		for _, tag := range d.Tags {
+			s.docTags[tag] = append(s.docTags[tag], d)
+		}
+	}
+	s.tags = []string{"go", "tools"}
+	if err := s.renderAtomFeed(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.renderJSONFeed(); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string) (string, string) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Header().Get("Content-type"), w.Body.String()
+	}
+
+	// The JSON Feed of all articles.
+	ctype, body := get("/feed.json")
+	if ctype != "application/feed+json; charset=utf-8" {
+		t.Errorf("/feed.json: got Content-type %q", ctype)
+	}
+	var feed jsonFeed11
+	if err := json.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Version != "https://jsonfeed.org/version/1.1" || len(feed.Items) != 3 || feed.Items[0].Title != "Third" {
+		t.Errorf("/feed.json: unexpected feed %+v", feed)
+	}
+
+	// The feeds of a tag are limited to TagFeedArticles.
+	_, body = get("/tags/tools/feed.json")
+	feed = jsonFeed11{}
+	if err := json.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Title != "Second" || feed.FeedURL != "https://blog.example.com/tags/tools/feed.json" {
+		t.Errorf("/tags/tools/feed.json: unexpected feed %+v", feed)
+	}
+	ctype, body = get("/tags/go/feed.atom")
+	if ctype != "application/atom+xml; charset=utf-8" {
+		t.Errorf("/tags/go/feed.atom: got Content-type %q", ctype)
+	}
+	var atomFeed atom.Feed
+	if err := xml.Unmarshal([]byte(body), &atomFeed); err != nil {
+		t.Fatal(err)
+	}
+	if len(atomFeed.Entry) != 1 || atomFeed.Entry[0].Title != "Third" || atomFeed.Entry[0].ID != "tag:blog.example.com,2013:blog.example.com/third" {
+		t.Errorf("/tags/go/feed.atom: unexpected feed %+v", atomFeed)
+	}
+}
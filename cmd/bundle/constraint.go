@@ -0,0 +1,301 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file defines the handling of build-constrained files.
+
+import (
+	"fmt"
+	"go/build"
+	"go/build/constraint"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// fileConstraint returns the build constraint of the named Go file,
+// combining its //go:build line, if any, with the constraint implied by
+// its name, such as x_windows.go. It returns nil for an unconstrained file.
+func fileConstraint(filename string, src []byte) (constraint.Expr, error) {
+	var expr constraint.Expr
+	for _, line := range headerLines(src) {
+		if constraint.IsGoBuild(line) {
+			x, err := constraint.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", filename, err)
+			}
+			expr = x
+			break
+		}
+	}
+	if x := nameConstraint(filename); x != nil {
+		if expr == nil {
+			expr = x
+		} else {
+			expr = &constraint.AndExpr{X: x, Y: expr}
+		}
+	}
+	return expr, nil
+}
+
+// headerLines returns the lines of the Go source preceding the package
+// clause, which are those that may hold build constraints.
+func headerLines(src []byte) []string {
+	var lines []string
+	inComment := false
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case inComment:
+			if _, after, ok := strings.Cut(line, "*/"); ok {
+				inComment = false
+				if strings.TrimSpace(after) != "" {
+					return lines
+				}
+			}
+		case line == "" || strings.HasPrefix(line, "//"):
+			lines = append(lines, line)
+		case strings.HasPrefix(line, "/*"):
+			inComment = !strings.Contains(line[len("/*"):], "*/")
+		default:
+			return lines // the package clause
+		}
+	}
+	return lines
+}
+
+// nameConstraint returns the constraint implied by the _GOOS, _GOARCH,
+// or _GOOS_GOARCH suffix of the file name, or nil if there is none.
+// As with the go command, the suffix must follow another element:
+// the file linux.go is unconstrained.
+func nameConstraint(filename string) constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	_, name, ok := strings.Cut(name, "_")
+	if !ok {
+		return nil
+	}
+	l := strings.Split(name, "_")
+	if n := len(l); n > 0 && l[n-1] == "test" {
+		l = l[:n-1]
+	}
+	n := len(l)
+	if n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
+		return &constraint.AndExpr{X: &constraint.TagExpr{Tag: l[n-2]}, Y: &constraint.TagExpr{Tag: l[n-1]}}
+	}
+	if n >= 1 && (knownOS[l[n-1]] || knownArch[l[n-1]]) {
+		return &constraint.TagExpr{Tag: l[n-1]}
+	}
+	return nil
+}
+
+// A buildConfig is a configuration of the go command under which
+// a set of build-constrained files is compiled.
+type buildConfig struct {
+	goos, goarch string
+	cgo          bool
+	tags         []string // additional build tags
+}
+
+func (c *buildConfig) String() string {
+	s := fmt.Sprintf("GOOS=%s GOARCH=%s", c.goos, c.goarch)
+	if c.cgo {
+		s += " CGO_ENABLED=1"
+	}
+	if len(c.tags) > 0 {
+		s += " -tags=" + strings.Join(c.tags, ",")
+	}
+	return s
+}
+
+// env returns the environment variables that select the configuration.
+func (c *buildConfig) env() []string {
+	cgo := "0"
+	if c.cgo {
+		cgo = "1"
+	}
+	return []string{"GOOS=" + c.goos, "GOARCH=" + c.goarch, "CGO_ENABLED=" + cgo}
+}
+
+// match reports whether the build tag is satisfied by the configuration.
+func (c *buildConfig) match(tag string) bool {
+	switch {
+	case tag == c.goos || tag == c.goarch:
+		return true
+	case tag == "linux":
+		return c.goos == "android"
+	case tag == "solaris":
+		return c.goos == "illumos"
+	case tag == "darwin":
+		return c.goos == "ios"
+	case tag == "unix":
+		return unixOS[c.goos]
+	case tag == "cgo":
+		return c.cgo
+	case tag == runtime.Compiler:
+		return true
+	}
+	return slices.Contains(build.Default.ReleaseTags, tag) || slices.Contains(c.tags, tag)
+}
+
+// maxCustomTags bounds the number of tags, other than those implied by
+// GOOS, GOARCH, and the like, for which satisfy tries every combination.
+const maxCustomTags = 10
+
+// satisfy returns a configuration that satisfies the build constraint,
+// preferring the configuration of the host. It reports false if there
+// is none, as for files marked "//go:build ignore".
+func satisfy(expr constraint.Expr) (*buildConfig, bool) {
+	// Collect the tags mentioned by the constraint.
+	goos := []string{runtime.GOOS}
+	goarch := []string{runtime.GOARCH}
+	var custom []string
+	var visit func(x constraint.Expr)
+	visit = func(x constraint.Expr) {
+		switch x := x.(type) {
+		case *constraint.AndExpr:
+			visit(x.X)
+			visit(x.Y)
+		case *constraint.OrExpr:
+			visit(x.X)
+			visit(x.Y)
+		case *constraint.NotExpr:
+			visit(x.X)
+		case *constraint.TagExpr:
+			tag := x.Tag
+			switch {
+			case knownOS[tag]:
+				if !slices.Contains(goos, tag) {
+					goos = append(goos, tag)
+				}
+			case knownArch[tag]:
+				if !slices.Contains(goarch, tag) {
+					goarch = append(goarch, tag)
+				}
+			case tag == "ignore", tag == "unix", tag == "cgo", tag == "gc", tag == "gccgo",
+				strings.HasPrefix(tag, "go1."), slices.Contains(custom, tag):
+				// These tags cannot be set, or are implied by others.
+			default:
+				custom = append(custom, tag)
+			}
+		}
+	}
+	visit(expr)
+	if len(custom) > maxCustomTags {
+		return nil, false
+	}
+	// Also try systems of each kind, so that constraints
+	// such as !linux && !windows or !unix can be satisfied.
+	for _, sys := range []string{"linux", "windows", "darwin", "plan9"} {
+		if !slices.Contains(goos, sys) {
+			goos = append(goos, sys)
+		}
+	}
+
+	for _, sys := range goos {
+		for _, arch := range goarch {
+			for _, cgo := range []bool{false, true} {
+				for mask := 0; mask < 1<<len(custom); mask++ {
+					c := &buildConfig{goos: sys, goarch: arch, cgo: cgo}
+					for i, tag := range custom {
+						if mask&(1<<i) != 0 {
+							c.tags = append(c.tags, tag)
+						}
+					}
+					if expr.Eval(c.match) {
+						return c, true
+					}
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// mentionsIgnore reports whether the constraint mentions the "ignore"
+// tag, conventionally used to exclude files from every build.
+func mentionsIgnore(expr constraint.Expr) bool {
+	found := false
+	expr.Eval(func(tag string) bool {
+		found = found || tag == "ignore"
+		return false
+	})
+	return found
+}
+
+// readFileConstraint reads the named file and returns its build constraint.
+func readFileConstraint(filename string) (constraint.Expr, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return fileConstraint(filename, src)
+}
+
+// The following lists are those of go/build.
+
+var knownOS = map[string]bool{
+	"aix":       true,
+	"android":   true,
+	"darwin":    true,
+	"dragonfly": true,
+	"freebsd":   true,
+	"hurd":      true,
+	"illumos":   true,
+	"ios":       true,
+	"js":        true,
+	"linux":     true,
+	"nacl":      true,
+	"netbsd":    true,
+	"openbsd":   true,
+	"plan9":     true,
+	"solaris":   true,
+	"wasip1":    true,
+	"windows":   true,
+	"zos":       true,
+}
+
+var unixOS = map[string]bool{
+	"aix":       true,
+	"android":   true,
+	"darwin":    true,
+	"dragonfly": true,
+	"freebsd":   true,
+	"hurd":      true,
+	"illumos":   true,
+	"ios":       true,
+	"linux":     true,
+	"netbsd":    true,
+	"openbsd":   true,
+	"solaris":   true,
+}
+
+var knownArch = map[string]bool{
+	"386":         true,
+	"amd64":       true,
+	"amd64p32":    true,
+	"arm":         true,
+	"armbe":       true,
+	"arm64":       true,
+	"arm64be":     true,
+	"loong64":     true,
+	"mips":        true,
+	"mipsle":      true,
+	"mips64":      true,
+	"mips64le":    true,
+	"mips64p32":   true,
+	"mips64p32le": true,
+	"ppc":         true,
+	"ppc64":       true,
+	"ppc64le":     true,
+	"riscv":       true,
+	"riscv64":     true,
+	"s390":        true,
+	"s390x":       true,
+	"sparc":       true,
+	"sparc64":     true,
+	"wasm":        true,
+}
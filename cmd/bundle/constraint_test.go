@@ -0,0 +1,66 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/build/constraint"
+	"testing"
+)
+
+func TestFileConstraint(t *testing.T) {
+	for _, test := range []struct {
+		filename, src, want string
+	}{
+		{"x.go", "package x\n", ""},
+		{"linux.go", "package x\n", ""},
+		{"x_linux.go", "package x\n", "linux"},
+		{"x_linux_arm64.go", "package x\n", "linux && arm64"},
+		{"x_arm64_test.go", "package x\n", "arm64"},
+		{"x.go", "// Copyright\n\n//go:build foo || bar\n\npackage x\n", "foo || bar"},
+		{"x_windows.go", "/* comment */\n//go:build cgo\n\npackage x\n", "windows && cgo"},
+		{"x.go", "package x\n\n//go:build foo\n", ""},
+	} {
+		expr, err := fileConstraint(test.filename, []byte(test.src))
+		if err != nil {
+			t.Errorf("fileConstraint(%s) failed: %v", test.filename, err)
+			continue
+		}
+		got := ""
+		if expr != nil {
+			got = expr.String()
+		}
+		if got != test.want {
+			t.Errorf("fileConstraint(%s, %q) = %q, want %q", test.filename, test.src, got, test.want)
+		}
+	}
+}
+
+func TestSatisfy(t *testing.T) {
+	for _, test := range []struct {
+		expr string
+		ok   bool
+	}{
+		{"linux", true},
+		{"windows && 386", true},
+		{"!linux && !windows", true},
+		{"!unix && !windows", true},
+		{"cgo && foo && !bar", true},
+		{"linux && windows", false},
+		{"ignore", false},
+	} {
+		expr, err := constraint.Parse("//go:build " + test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, ok := satisfy(expr)
+		if ok != test.ok {
+			t.Errorf("satisfy(%s) = %v, want %v", test.expr, ok, test.ok)
+			continue
+		}
+		if ok && !expr.Eval(c.match) {
+			t.Errorf("satisfy(%s) = %s, which does not satisfy it", test.expr, c)
+		}
+	}
+}
@@ -7,14 +7,12 @@
 //
 // Usage:
 //
-//	bundle [-o file] [-dst path] [-pkg name] [-prefix p] [-import old=new] [-tags build_constraints] <src>
+//	bundle [-o file] [-dst path] [-pkg name] [-prefix p] [-import old=new] [-tags build_constraints] <src> [deps...]
 //
 // The src argument specifies the import path of the package to bundle.
 // The bundling of a directory of source files into a single source file
 // necessarily imposes a number of constraints.
-// The package being bundled must not use cgo; must not use conditional
-// file compilation, whether with build tags or system-specific file names
-// like code_amd64.go; must not depend on any special comments, which
+// The package being bundled must not use cgo; must not depend on any special comments, which
 // may not be preserved; must not use any assembly sources;
 // must not use renaming imports; and must not use reflection-based APIs
 // that depend on the specific names of types or struct fields.
@@ -43,6 +41,22 @@
 // of the source package followed by an underscore. The -prefix option
 // specifies an alternate prefix.
 //
+// Files with build constraints, whether given by //go:build lines or by
+// system-specific file names like code_amd64.go, cannot be merged with
+// the rest of the package. Bundle writes those with the same constraint
+// to a separate file, named after the output file and the first of
+// them, and constrained alike: with -o h2_bundle.go, the files
+// sys_linux.go and sys_darwin.go of the source package become
+// h2_bundle_sys_linux.go and h2_bundle_sys_darwin.go. Files marked
+// “//go:build ignore” are omitted. When writing to standard output,
+// bundle writes only the unconstrained files.
+//
+// Additional arguments name dependencies of the source package, by
+// import path, to bundle along with it into the same output. Their
+// identifiers receive their own package name followed by an underscore
+// as a prefix, and references to them from the other bundled packages
+// are rewritten accordingly, so the output does not import them.
+//
 // Occasionally it is necessary to rewrite imports during the bundling
 // process. The -import option, which may be repeated, specifies that
 // an import of "old" should be rewritten to import "new" instead.
@@ -75,12 +89,16 @@ import (
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/format"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -115,7 +133,7 @@ func addImportMap(s string) {
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: bundle [options] <src>\n")
+	fmt.Fprintf(os.Stderr, "Usage: bundle [options] <src> [deps...]\n")
 	flag.PrintDefaults()
 }
 
@@ -126,7 +144,7 @@ func main() {
 	flag.Usage = usage
 	flag.Parse()
 	args := flag.Args()
-	if len(args) != 1 {
+	if len(args) < 1 {
 		usage()
 		os.Exit(2)
 	}
@@ -143,20 +161,29 @@ func main() {
 		*pkgName = pkgs[0].Name
 	}
 
-	code, err := bundle(args[0], pkgs[0].PkgPath, *pkgName, *prefix, *buildTags)
+	files, err := bundleFiles(args, pkgs[0].PkgPath, *pkgName, *prefix, *buildTags)
 	if err != nil {
 		log.Fatal(err)
 	}
 	if *outputFile != "" {
-		err := os.WriteFile(*outputFile, code, 0666)
-		if err != nil {
-			log.Fatal(err)
+		base := strings.TrimSuffix(*outputFile, ".go")
+		for _, file := range files {
+			name := *outputFile
+			if file.suffix != "" {
+				name = base + "_" + file.suffix + ".go"
+			}
+			if err := os.WriteFile(name, file.code, 0666); err != nil {
+				log.Fatal(err)
+			}
 		}
 	} else {
-		_, err := os.Stdout.Write(code)
+		_, err := os.Stdout.Write(files[0].code)
 		if err != nil {
 			log.Fatal(err)
 		}
+		if len(files) > 1 {
+			log.Printf("omitted %d files with build constraints; use -o to write them", len(files)-1)
+		}
 	}
 }
 
@@ -172,8 +199,8 @@ func isStandardImportPath(path string) bool {
 
 var testingOnlyPackagesConfig *packages.Config
 
-func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
-	// Load the initial package.
+// packagesConfig returns the configuration for loading the source packages.
+func packagesConfig() *packages.Config {
 	cfg := &packages.Config{}
 	if testingOnlyPackagesConfig != nil {
 		*cfg = *testingOnlyPackagesConfig
@@ -182,72 +209,276 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 		// std module vendor folder.
 		cfg.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
 	}
-	cfg.Mode = packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
-	pkgs, err := packages.Load(cfg, src)
+	cfg.Mode = packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+	return cfg
+}
+
+// bundle returns the bundled code of the files of the src package
+// that have no build constraints.
+func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
+	files, err := bundleFiles([]string{src}, dst, dstpkg, prefix, buildTags)
 	if err != nil {
 		return nil, err
 	}
-	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
-		return nil, fmt.Errorf("failed to load source package")
-	}
-	pkg := pkgs[0]
+	return files[0].code, nil
+}
 
-	if strings.Contains(prefix, "&") {
-		prefix = strings.Replace(prefix, "&", pkg.Syntax[0].Name.Name, -1)
-	}
+// A bundledFile is a file of bundled code.
+type bundledFile struct {
+	suffix string // added to the name of the output file; empty for the principal file
+	code   []byte
+}
 
-	objsToUpdate := make(map[types.Object]bool)
-	var rename func(from types.Object)
-	rename = func(from types.Object) {
-		if !objsToUpdate[from] {
-			objsToUpdate[from] = true
+// A unit is a set of files of a package that are bundled together.
+type unit struct {
+	pkg   *packages.Package
+	files []*ast.File
+}
 
-			// Renaming a type that is used as an embedded field
-			// requires renaming the field too. e.g.
-			// 	type T int // if we rename this to U..
-			// 	var s struct {T}
-			// 	print(s.T) // ...this must change too
-			if _, ok := from.(*types.TypeName); ok {
-				for id, obj := range pkg.TypesInfo.Uses {
-					if obj == from {
-						if field := pkg.TypesInfo.Defs[id]; field != nil {
-							rename(field)
-						}
-					}
-				}
+// A fileGroup is a set of files of a package with the same build constraint.
+type fileGroup struct {
+	pkg    *packages.Package // the package, as loaded for the host
+	expr   constraint.Expr
+	names  []string // base names of the files
+	suffix string   // added to the name of the output file
+}
+
+// bundleFiles bundles the srcs packages, the first of which is the
+// principal one, and the rest dependencies bundled along with it.
+// It returns the principal file, holding the files of the packages
+// that have no build constraints, followed by a file for each set of
+// files with the same constraint.
+func bundleFiles(srcs []string, dst, dstpkg, prefix, buildTags string) ([]bundledFile, error) {
+	// Load the packages.
+	cfg := packagesConfig()
+	pkgs, err := packages.Load(cfg, srcs...)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != len(srcs) {
+		return nil, fmt.Errorf("failed to load source package")
+	}
+	if len(srcs) > 1 {
+		// Put the packages in the order of srcs.
+		byPath := make(map[string]*packages.Package)
+		for _, pkg := range pkgs {
+			byPath[pkg.PkgPath] = pkg
+		}
+		for i, src := range srcs {
+			if pkgs[i] = byPath[src]; pkgs[i] == nil {
+				return nil, fmt.Errorf("no package %s; bundled packages must be named by import path", src)
 			}
 		}
 	}
 
-	// Rename each package-level object.
-	scope := pkg.Types.Scope()
-	for _, name := range scope.Names() {
-		rename(scope.Lookup(name))
+	// Choose the prefix for the identifiers of each package.
+	// The dependencies of the principal package always use the default.
+	prefixes := make(map[string]string) // maps package path to prefix
+	used := make(map[string]string)     // maps prefix to package path
+	for i, pkg := range pkgs {
+		p := "&_"
+		if i == 0 {
+			p = prefix
+		}
+		p = strings.Replace(p, "&", pkg.Name, -1)
+		if other, ok := used[p]; ok {
+			return nil, fmt.Errorf("packages %s and %s would have the same prefix %q", other, pkg.PkgPath, p)
+		}
+		prefixes[pkg.PkgPath] = p
+		used[p] = pkg.PkgPath
 	}
 
-	var out bytes.Buffer
+	var header bytes.Buffer
 	if buildTags != "" {
-		fmt.Fprintf(&out, "//go:build %s\n", buildTags)
+		fmt.Fprintf(&header, "//go:build %s\n", buildTags)
 	}
 
-	fmt.Fprintf(&out, "// Code generated by golang.org/x/tools/cmd/bundle. DO NOT EDIT.\n")
+	fmt.Fprintf(&header, "// Code generated by golang.org/x/tools/cmd/bundle. DO NOT EDIT.\n")
 	if *outputFile != "" && buildTags == "" {
-		fmt.Fprintf(&out, "//go:generate bundle %s\n", strings.Join(quoteArgs(os.Args[1:]), " "))
+		fmt.Fprintf(&header, "//go:generate bundle %s\n", strings.Join(quoteArgs(os.Args[1:]), " "))
 	} else {
-		fmt.Fprintf(&out, "//   $ bundle %s\n", strings.Join(os.Args[1:], " "))
+		fmt.Fprintf(&header, "//   $ bundle %s\n", strings.Join(os.Args[1:], " "))
 	}
-	fmt.Fprintf(&out, "\n")
+	fmt.Fprintf(&header, "\n")
 
 	// Concatenate package comments from all files...
-	for _, f := range pkg.Syntax {
+	for _, f := range pkgs[0].Syntax {
 		if doc := f.Doc.Text(); strings.TrimSpace(doc) != "" {
 			for _, line := range strings.Split(doc, "\n") {
-				fmt.Fprintf(&out, "// %s\n", line)
+				fmt.Fprintf(&header, "// %s\n", line)
 			}
 		}
 	}
 	// ...but don't let them become the actual package comment.
-	fmt.Fprintln(&out)
+	fmt.Fprintln(&header)
+
+	// Separate the files with build constraints from the others.
+	var units []unit
+	var groups []*fileGroup
+	for i, pkg := range pkgs {
+		u, gs, err := splitFiles(pkg)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+		for _, g := range gs {
+			if i > 0 {
+				g.suffix = pkg.Name + "_" + g.suffix
+			}
+			groups = append(groups, g)
+		}
+	}
+
+	code, err := generate(header.Bytes(), units, dst, dstpkg, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	files := []bundledFile{{"", code}}
+
+	for _, g := range groups {
+		bc, ok := satisfy(g.expr)
+		if !ok {
+			log.Printf("skipping %s of %s: cannot satisfy build constraint %s",
+				strings.Join(g.names, ", "), g.pkg.PkgPath, g.expr)
+			continue
+		}
+		u, err := g.load(cfg, bc)
+		if err != nil {
+			return nil, err
+		}
+		expr := g.expr
+		if buildTags != "" {
+			if x, err := constraint.Parse("//go:build " + buildTags); err == nil {
+				expr = &constraint.AndExpr{X: x, Y: expr}
+			}
+		}
+		var header bytes.Buffer
+		fmt.Fprintf(&header, "//go:build %s\n\n", expr)
+		fmt.Fprintf(&header, "// Code generated by golang.org/x/tools/cmd/bundle. DO NOT EDIT.\n\n")
+		code, err := generate(header.Bytes(), []unit{u}, dst, dstpkg, prefixes)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, bundledFile{g.suffix, code})
+	}
+	return files, nil
+}
+
+// splitFiles returns the unit of the files of pkg that have no build
+// constraints, and the groups of those that do, in order of constraint.
+// The groups include the files that were excluded when pkg was loaded,
+// other than those marked "//go:build ignore".
+func splitFiles(pkg *packages.Package) (unit, []*fileGroup, error) {
+	u := unit{pkg: pkg}
+	groups := make(map[string]*fileGroup)
+	add := func(filename string, expr constraint.Expr) {
+		key := expr.String()
+		g := groups[key]
+		if g == nil {
+			g = &fileGroup{
+				pkg:    pkg,
+				expr:   expr,
+				suffix: strings.TrimSuffix(filepath.Base(filename), ".go"),
+			}
+			groups[key] = g
+		}
+		g.names = append(g.names, filepath.Base(filename))
+	}
+	for _, f := range pkg.Syntax {
+		filename := pkg.Fset.File(f.Pos()).Name()
+		expr, err := readFileConstraint(filename)
+		if err != nil {
+			return unit{}, nil, err
+		}
+		if expr == nil {
+			u.files = append(u.files, f)
+		} else {
+			add(filename, expr)
+		}
+	}
+	for _, filename := range pkg.IgnoredFiles {
+		if !strings.HasSuffix(filename, ".go") || strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		expr, err := readFileConstraint(filename)
+		if err != nil {
+			return unit{}, nil, err
+		}
+		if expr != nil && !mentionsIgnore(expr) {
+			add(filename, expr)
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var result []*fileGroup
+	for _, key := range keys {
+		result = append(result, groups[key])
+	}
+	return u, result, nil
+}
+
+// load returns the unit of the files of the group. If the package as
+// loaded for the host does not include them, load loads it anew under
+// the build configuration bc, which must satisfy their constraint.
+func (g *fileGroup) load(cfg *packages.Config, bc *buildConfig) (unit, error) {
+	unitOf := func(pkg *packages.Package) unit {
+		u := unit{pkg: pkg}
+		for _, f := range pkg.Syntax {
+			if slices.Contains(g.names, filepath.Base(pkg.Fset.File(f.Pos()).Name())) {
+				u.files = append(u.files, f)
+			}
+		}
+		return u
+	}
+	if u := unitOf(g.pkg); len(u.files) == len(g.names) {
+		return u, nil
+	}
+
+	copy := *cfg
+	cfg = &copy
+	if cfg.Env == nil {
+		cfg.Env = os.Environ()
+	}
+	cfg.Env = append(cfg.Env[:len(cfg.Env):len(cfg.Env)], bc.env()...)
+	if len(bc.tags) > 0 {
+		cfg.BuildFlags = append(cfg.BuildFlags[:len(cfg.BuildFlags):len(cfg.BuildFlags)], "-tags="+strings.Join(bc.tags, ","))
+	}
+	pkgs, err := packages.Load(cfg, g.pkg.PkgPath)
+	if err != nil {
+		return unit{}, err
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+		return unit{}, fmt.Errorf("failed to load package %s with %s", g.pkg.PkgPath, bc)
+	}
+	u := unitOf(pkgs[0])
+	if len(u.files) != len(g.names) {
+		return unit{}, fmt.Errorf("loading package %s with %s did not include %s", g.pkg.PkgPath, bc, strings.Join(g.names, ", "))
+	}
+	return u, nil
+}
+
+// origin returns the generic object of which obj is an instance,
+// such as a field of an instantiated type, or else obj itself.
+func origin(obj types.Object) types.Object {
+	switch obj := obj.(type) {
+	case *types.Var:
+		return obj.Origin()
+	case *types.Func:
+		return obj.Origin()
+	}
+	return obj
+}
+
+// generate returns the formatted bundled code of the units,
+// preceded by the header. The prefixes map the path of each
+// bundled package to the prefix of its identifiers.
+func generate(header []byte, units []unit, dst, dstpkg string, prefixes map[string]string) ([]byte, error) {
+	var out bytes.Buffer
+	out.Write(header)
 
 	fmt.Fprintf(&out, "package %s\n\n", dstpkg)
 
@@ -268,28 +499,30 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 	// to deduplicate instances of the same import name and path.
 	var pkgStd = make(map[string]bool)
 	var pkgExt = make(map[string]bool)
-	for _, f := range pkg.Syntax {
-		for _, imp := range f.Imports {
-			path, err := strconv.Unquote(imp.Path.Value)
-			if err != nil {
-				log.Fatalf("invalid import path string: %v", err) // Shouldn't happen here since packages.Load succeeded.
-			}
-			if path == dst {
-				continue
-			}
-			if newPath, ok := importMap[path]; ok {
-				path = newPath
-			}
+	for _, u := range units {
+		for _, f := range u.files {
+			for _, imp := range f.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err != nil {
+					log.Fatalf("invalid import path string: %v", err) // Shouldn't happen here since packages.Load succeeded.
+				}
+				if _, ok := prefixes[path]; ok || path == dst {
+					continue
+				}
+				if newPath, ok := importMap[path]; ok {
+					path = newPath
+				}
 
-			var name string
-			if imp.Name != nil {
-				name = imp.Name.Name
-			}
-			spec := fmt.Sprintf("%s %q", name, path)
-			if isStandardImportPath(path) {
-				pkgStd[spec] = true
-			} else {
-				pkgExt[spec] = true
+				var name string
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				spec := fmt.Sprintf("%s %q", name, path)
+				if isStandardImportPath(path) {
+					pkgStd[spec] = true
+				} else {
+					pkgExt[spec] = true
+				}
 			}
 		}
 	}
@@ -307,29 +540,87 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 	}
 	fmt.Fprint(&out, ")\n\n")
 
+	for _, u := range units {
+		writeUnit(&out, u, dst, prefixes)
+	}
+
+	// Now format the entire thing.
+	result, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("formatting failed: %v", err)
+	}
+
+	return result, nil
+}
+
+// writeUnit writes the declarations of the files of the unit to out,
+// renaming the package-level identifiers of the bundled packages.
+func writeUnit(out *bytes.Buffer, u unit, dst string, prefixes map[string]string) {
+	pkg := u.pkg
+	prefix := prefixes[pkg.PkgPath]
+
+	objsToUpdate := make(map[types.Object]bool)
+	var rename func(from types.Object)
+	rename = func(from types.Object) {
+		if !objsToUpdate[from] {
+			objsToUpdate[from] = true
+
+			// Renaming a type that is used as an embedded field
+			// requires renaming the field too. e.g.
+			// 	type T int // if we rename this to U..
+			// 	var s struct {T}
+			// 	print(s.T) // ...this must change too
+			if _, ok := from.(*types.TypeName); ok {
+				for id, obj := range pkg.TypesInfo.Uses {
+					if obj == from {
+						if field := pkg.TypesInfo.Defs[id]; field != nil {
+							rename(field)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Rename each package-level object.
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		rename(scope.Lookup(name))
+	}
+
 	// Modify and print each file.
-	for _, f := range pkg.Syntax {
-		// Update renamed identifiers.
+	for _, f := range u.files {
+		// Update renamed identifiers. A use of a field or method of
+		// an instantiated generic type refers to an instance of the
+		// object, which is renamed as its origin is.
 		for id, obj := range pkg.TypesInfo.Defs {
-			if objsToUpdate[obj] {
+			if obj != nil && objsToUpdate[origin(obj)] {
 				id.Name = prefix + obj.Name()
 			}
 		}
 		for id, obj := range pkg.TypesInfo.Uses {
-			if objsToUpdate[obj] {
+			if objsToUpdate[origin(obj)] {
 				id.Name = prefix + obj.Name()
 			}
 		}
 
 		// For each qualified identifier that refers to the
-		// destination package, remove the qualifier.
+		// destination package, remove the qualifier, and for each
+		// that refers to another bundled package, replace it
+		// by that package's prefix.
 		// The "@@@." strings are removed in postprocessing.
 		ast.Inspect(f, func(n ast.Node) bool {
 			if sel, ok := n.(*ast.SelectorExpr); ok {
 				if id, ok := sel.X.(*ast.Ident); ok {
 					if obj, ok := pkg.TypesInfo.Uses[id].(*types.PkgName); ok {
-						if obj.Imported().Path() == dst {
+						path := obj.Imported().Path()
+						if path == dst {
+							id.Name = "@@@"
+						} else if prefix, ok := prefixes[path]; ok {
 							id.Name = "@@@"
+							if obj := pkg.TypesInfo.Uses[sel.Sel]; obj != nil {
+								sel.Sel.Name = prefix + obj.Name()
+							}
 						}
 					}
 				}
@@ -358,7 +649,7 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 
 			beg, end := sourceRange(decl)
 
-			printComments(&out, f.Comments, last, beg)
+			printComments(out, f.Comments, last, beg)
 
 			buf.Reset()
 			format.Node(&buf, pkg.Fset, &printer.CommentedNode{Node: decl, Comments: f.Comments})
@@ -366,21 +657,13 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 			// TODO(adonovan): not hygienic.
 			out.Write(bytes.Replace(buf.Bytes(), []byte("@@@."), nil, -1))
 
-			last = printSameLineComment(&out, f.Comments, pkg.Fset, end)
+			last = printSameLineComment(out, f.Comments, pkg.Fset, end)
 
 			out.WriteString("\n\n")
 		}
 
-		printLastComments(&out, f.Comments, last)
-	}
-
-	// Now format the entire thing.
-	result, err := format.Source(out.Bytes())
-	if err != nil {
-		log.Fatalf("formatting failed: %v", err)
+		printLastComments(out, f.Comments, last)
 	}
-
-	return result, nil
 }
 
 // sourceRange returns the [beg, end) interval of source code
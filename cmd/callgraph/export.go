@@ -0,0 +1,258 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+
+	"golang.org/x/tools/go/callgraph"
+)
+
+// A PackageEdge records the calls from one package to another.
+type PackageEdge struct {
+	Caller string // import path of calling package
+	Callee string // import path of called package
+	Calls  int    // number of calls
+
+	// The first call site, as in Edge, so that the
+	// default -format applies to collapsed edges too.
+	Filename string // containing file
+	Offset   int    // offset within file of '('
+	Line     int    // line number
+	Column   int    // column number of call
+	Dynamic  string // "dynamic" if any call is dynamic, otherwise "static"
+
+	pos token.Pos // position of first call site
+}
+
+// collapse returns the edges between the packages of the functions
+// related by the specified edges, in order. Calls within a package are
+// omitted.
+func collapse(fset *token.FileSet, edges []*callgraph.Edge) []*PackageEdge {
+	type key struct{ caller, callee string }
+	m := make(map[key]*PackageEdge)
+	var res []*PackageEdge
+	for _, e := range edges {
+		k := key{funcPkgPath(e.Caller.Func), funcPkgPath(e.Callee.Func)}
+		if k.caller == k.callee {
+			continue
+		}
+		pe := m[k]
+		if pe == nil {
+			pe = &PackageEdge{Caller: k.caller, Callee: k.callee, Dynamic: "static"}
+			m[k] = pe
+			res = append(res, pe)
+		}
+		pe.Calls++
+		if e.Site != nil && e.Site.Common().StaticCallee() == nil {
+			pe.Dynamic = "dynamic"
+		}
+		if pos := e.Pos(); pos.IsValid() && (!pe.pos.IsValid() || pos < pe.pos) {
+			pe.pos = pos
+			posn := fset.Position(pos)
+			pe.Filename, pe.Offset, pe.Line, pe.Column = posn.Filename, posn.Offset, posn.Line, posn.Column
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		x, y := res[i], res[j]
+		if x.Caller != y.Caller {
+			return x.Caller < y.Caller
+		}
+		return x.Callee < y.Callee
+	})
+	return res
+}
+
+// The graph type is the common model of the JSON and GraphML outputs.
+type graph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []*graphEdge `json:"edges"`
+}
+
+type graphNode struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`               // function, or package path under -collapse
+	Package  string `json:"package,omitempty"`  // package path, if any
+	Position string `json:"position,omitempty"` // declaration of function, if any
+}
+
+type graphEdge struct {
+	Caller      int    `json:"caller"`                // id of caller node
+	Callee      int    `json:"callee"`                // id of callee node
+	Position    string `json:"position,omitempty"`    // call site
+	Dynamic     bool   `json:"dynamic,omitempty"`     // dynamic call
+	Kind        string `json:"kind,omitempty"`        // e.g. "interface|go" (see callgraph.EdgeKind)
+	Description string `json:"description,omitempty"` // e.g. "static method call"
+	Calls       int    `json:"calls,omitempty"`       // number of calls, under -collapse
+}
+
+// makeGraph returns the graph model of the specified edges, whose
+// nodes are functions, or packages if collapsed is set. Nodes are
+// numbered in order of their names and edges are sorted.
+func makeGraph(fset *token.FileSet, edges []*callgraph.Edge, collapsed bool) *graph {
+	g := new(graph)
+	nodes := make(map[string]*graphNode)
+	node := func(name, pkg string, pos token.Pos) *graphNode {
+		n := nodes[name]
+		if n == nil {
+			n = &graphNode{Name: name, Package: pkg}
+			if pos.IsValid() {
+				n.Position = fset.Position(pos).String()
+			}
+			nodes[name] = n
+			g.Nodes = append(g.Nodes, n)
+		}
+		return n
+	}
+
+	// Edges refer to nodes by name until they are numbered.
+	type edge struct {
+		caller, callee *graphNode
+		*graphEdge
+	}
+	var es []edge
+	if collapsed {
+		for _, pe := range collapse(fset, edges) {
+			es = append(es, edge{
+				caller:    node(pe.Caller, pe.Caller, token.NoPos),
+				callee:    node(pe.Callee, pe.Callee, token.NoPos),
+				graphEdge: &graphEdge{Calls: pe.Calls},
+			})
+		}
+	} else {
+		for _, e := range edges {
+			caller, callee := e.Caller.Func, e.Callee.Func
+			ge := &graphEdge{Description: e.Description()}
+			if pos := e.Pos(); pos.IsValid() {
+				ge.Position = fset.Position(pos).String()
+			}
+			ge.Dynamic = e.Site != nil && e.Site.Common().StaticCallee() == nil
+			ge.Kind = e.Kind().String()
+			es = append(es, edge{
+				caller:    node(caller.String(), funcPkgPath(caller), caller.Pos()),
+				callee:    node(callee.String(), funcPkgPath(callee), callee.Pos()),
+				graphEdge: ge,
+			})
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].Name < g.Nodes[j].Name })
+	for i, n := range g.Nodes {
+		n.ID = i
+	}
+	for _, e := range es {
+		e.Caller, e.Callee = e.caller.ID, e.callee.ID
+		g.Edges = append(g.Edges, e.graphEdge)
+	}
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		x, y := g.Edges[i], g.Edges[j]
+		if x.Caller != y.Caller {
+			return x.Caller < y.Caller
+		}
+		if x.Callee != y.Callee {
+			return x.Callee < y.Callee
+		}
+		return x.Position < y.Position
+	})
+	return g
+}
+
+// writeJSON writes the specified edges to w as a JSON-encoded graph.
+func writeJSON(w io.Writer, fset *token.FileSet, edges []*callgraph.Edge, collapsed bool) error {
+	data, err := json.MarshalIndent(makeGraph(fset, edges, collapsed), "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// writeGraphML writes the specified edges to w in GraphML format
+// (see http://graphml.graphdrawing.org).
+func writeGraphML(w io.Writer, fset *token.FileSet, edges []*callgraph.Edge, collapsed bool) error {
+	g := makeGraph(fset, edges, collapsed)
+
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type key struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type graphml struct {
+		XMLName xml.Name `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+		Keys    []key    `xml:"key"`
+		Graph   struct {
+			ID          string `xml:"id,attr"`
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []node `xml:"node"`
+			Edges       []edge `xml:"edge"`
+		} `xml:"graph"`
+	}
+
+	var doc graphml
+	doc.Keys = []key{
+		{"name", "node", "name", "string"},
+		{"package", "node", "package", "string"},
+		{"position", "all", "position", "string"},
+		{"dynamic", "edge", "dynamic", "boolean"},
+		{"kind", "edge", "kind", "string"},
+		{"description", "edge", "description", "string"},
+		{"calls", "edge", "calls", "int"},
+	}
+	doc.Graph.ID = "callgraph"
+	doc.Graph.EdgeDefault = "directed"
+	// add appends a data element to list, unless its value is empty.
+	add := func(list []data, key, value string) []data {
+		if value != "" {
+			list = append(list, data{key, value})
+		}
+		return list
+	}
+	for _, n := range g.Nodes {
+		var d []data
+		d = add(d, "name", n.Name)
+		d = add(d, "package", n.Package)
+		d = add(d, "position", n.Position)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{fmt.Sprintf("n%d", n.ID), d})
+	}
+	for _, e := range g.Edges {
+		var d []data
+		d = add(d, "position", e.Position)
+		if e.Dynamic {
+			d = add(d, "dynamic", "true")
+		}
+		d = add(d, "kind", e.Kind)
+		d = add(d, "description", e.Description)
+		if e.Calls > 0 {
+			d = add(d, "calls", fmt.Sprint(e.Calls))
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{fmt.Sprintf("n%d", e.Caller), fmt.Sprintf("n%d", e.Callee), d})
+	}
+
+	out, err := xml.MarshalIndent(&doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, out)
+	return err
+}
@@ -0,0 +1,324 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file defines the interactive terminal explorer (-interactive).
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+)
+
+const interactiveHelp = `Commands:
+  find text      list the functions whose name contains text (or: /text)
+  open N|name    add a search result, or the named function, to the outline
+  callees N      expand line N of the outline to show its callees (or: +N)
+  callers N      expand line N of the outline to show its callers (or: -N)
+  fold N         hide the callers or callees of line N
+  show           display the outline
+  mark N         bookmark the path from the top of the outline to line N
+  marks          list the bookmarks
+  dot file       write the calls shown in the outline and bookmarks
+                 to file in GraphViz (.dot) format
+  clear          empty the outline
+  help           display this message
+  quit           exit
+`
+
+// A session is an interactive exploration of a call graph. Its state
+// is an outline of functions of interest, each of which may be
+// expanded to show its callers or callees, and so on, recursively.
+type session struct {
+	e     *explorer
+	out   io.Writer
+	roots []*outlineNode
+	lines []*outlineNode    // visible nodes in display order; line N is lines[N-1]
+	found []*callgraph.Node // results of the most recent search
+	marks []bookmark
+}
+
+// An outlineNode is a line of the outline. Except at the top level,
+// it is related to its parent by a call edge, in either direction.
+type outlineNode struct {
+	node     *callgraph.Node
+	edge     *callgraph.Edge // call to (or from) parent; nil at top level
+	parent   *outlineNode
+	callers  bool // children are callers, not callees
+	children []*outlineNode
+}
+
+// A bookmark records a path through the outline.
+type bookmark struct {
+	path  string // e.g. "main -> f <- g"
+	edges []*callgraph.Edge
+}
+
+// explore runs an interactive session reading commands from in
+// and writing results to out, until in is exhausted or the user quits.
+func explore(in io.Reader, out io.Writer, e *explorer) error {
+	s := &session{e: e, out: out}
+	fmt.Fprintf(out, "callgraph: %d functions. Type 'help' for a list of commands.\n", len(e.nodes))
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(sc.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		switch {
+		case strings.HasPrefix(cmd, "/"):
+			cmd, arg = "find", strings.TrimSpace(strings.TrimPrefix(cmd+" "+arg, "/"))
+		case strings.HasPrefix(cmd, "+") && arg == "":
+			cmd, arg = "callees", cmd[1:]
+		case strings.HasPrefix(cmd, "-") && arg == "":
+			cmd, arg = "callers", cmd[1:]
+		}
+		if cmd == "quit" || cmd == "q" {
+			return nil
+		}
+		if err := s.do(cmd, arg); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	}
+}
+
+// do executes a single command.
+func (s *session) do(cmd, arg string) error {
+	switch cmd {
+	case "":
+		return nil
+
+	case "help", "?":
+		fmt.Fprint(s.out, interactiveHelp)
+
+	case "find":
+		if arg == "" {
+			return fmt.Errorf("usage: find text")
+		}
+		s.found = nil
+		larg := strings.ToLower(arg)
+		for _, n := range s.e.nodes {
+			if strings.Contains(strings.ToLower(n.Func.String()), larg) {
+				s.found = append(s.found, n)
+			}
+		}
+		for i, n := range s.found {
+			if i == maxResults {
+				fmt.Fprintf(s.out, "(%d more results not shown)\n", len(s.found)-maxResults)
+				break
+			}
+			fmt.Fprintf(s.out, "[%d] %s\n", i+1, n.Func)
+		}
+		if len(s.found) == 0 {
+			fmt.Fprintf(s.out, "no functions matching %q\n", arg)
+		}
+
+	case "open":
+		n, err := s.lookup(arg)
+		if err != nil {
+			return err
+		}
+		s.roots = append(s.roots, &outlineNode{node: n})
+		s.show()
+
+	case "callees", "callers", "fold":
+		o, err := s.line(arg)
+		if err != nil {
+			return err
+		}
+		o.children = nil
+		if cmd != "fold" {
+			o.callers = cmd == "callers"
+			edges := o.node.Out
+			if o.callers {
+				edges = o.node.In
+			}
+			for _, edge := range edges {
+				child := &outlineNode{node: edge.Callee, edge: edge, parent: o}
+				if o.callers {
+					child.node = edge.Caller
+				}
+				o.children = append(o.children, child)
+			}
+			if len(o.children) == 0 {
+				fmt.Fprintf(s.out, "%s has no %s\n", o.node.Func, cmd)
+			}
+		}
+		s.show()
+
+	case "show":
+		s.show()
+
+	case "clear":
+		s.roots = nil
+		s.lines = nil
+
+	case "mark":
+		o, err := s.line(arg)
+		if err != nil {
+			return err
+		}
+		if o.edge == nil {
+			return fmt.Errorf("line %s is at the top of the outline, so there is no path to it", arg)
+		}
+		var nodes []*outlineNode
+		for ; o != nil; o = o.parent {
+			nodes = append(nodes, o)
+		}
+		var b bookmark
+		for i := len(nodes) - 1; i >= 0; i-- {
+			o := nodes[i]
+			if o.edge != nil {
+				b.edges = append(b.edges, o.edge)
+				b.path += arrow(o)
+			}
+			b.path += o.node.Func.String()
+		}
+		s.marks = append(s.marks, b)
+		fmt.Fprintf(s.out, "bookmark %d: %s\n", len(s.marks), b.path)
+
+	case "marks":
+		for i, b := range s.marks {
+			fmt.Fprintf(s.out, "bookmark %d: %s\n", i+1, b.path)
+		}
+		if len(s.marks) == 0 {
+			fmt.Fprintln(s.out, "no bookmarks")
+		}
+
+	case "dot":
+		if arg == "" {
+			return fmt.Errorf("usage: dot file")
+		}
+		f, err := os.Create(arg)
+		if err != nil {
+			return err
+		}
+		n := s.writeDot(f)
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "wrote %d calls to %s\n", n, arg)
+
+	default:
+		return fmt.Errorf("unknown command %q; type 'help' for a list", cmd)
+	}
+	return nil
+}
+
+// lookup returns the node for the specified search result number,
+// or for the function of the specified name.
+func (s *session) lookup(arg string) (*callgraph.Node, error) {
+	if i, err := strconv.Atoi(arg); err == nil {
+		if i < 1 || i > len(s.found) {
+			return nil, fmt.Errorf("no search result %d", i)
+		}
+		return s.found[i-1], nil
+	}
+	for _, n := range s.e.nodes {
+		if n.Func.String() == arg {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("no function named %q", arg)
+}
+
+// line returns the outline node displayed on the specified line.
+func (s *session) line(arg string) (*outlineNode, error) {
+	i, err := strconv.Atoi(arg)
+	if err != nil || i < 1 || i > len(s.lines) {
+		return nil, fmt.Errorf("no line %q in outline", arg)
+	}
+	return s.lines[i-1], nil
+}
+
+// show displays the outline, numbering its lines.
+func (s *session) show() {
+	s.lines = nil
+	var visit func(o *outlineNode, depth int)
+	visit = func(o *outlineNode, depth int) {
+		s.lines = append(s.lines, o)
+		fmt.Fprintf(s.out, "%4d %s%s%s", len(s.lines), strings.Repeat("  ", depth), strings.TrimPrefix(arrow(o), " "), o.node.Func)
+		if o.edge != nil {
+			fmt.Fprintf(s.out, "  [%s", o.edge.Kind())
+			if posn := s.e.position(o.edge.Pos()); posn != "" {
+				fmt.Fprintf(s.out, " %s", posn)
+			}
+			fmt.Fprint(s.out, "]")
+		}
+		fmt.Fprintln(s.out)
+		for _, child := range o.children {
+			visit(child, depth+1)
+		}
+	}
+	for _, root := range s.roots {
+		visit(root, 0)
+	}
+	if len(s.lines) == 0 {
+		fmt.Fprintln(s.out, "outline is empty; use 'find' and 'open' to add functions")
+	}
+}
+
+// arrow returns the arrow that relates an outline node to its parent.
+func arrow(o *outlineNode) string {
+	switch {
+	case o.edge == nil:
+		return ""
+	case o.parent.callers:
+		return " <- "
+	default:
+		return " -> "
+	}
+}
+
+// writeDot writes the subgraph formed by the calls shown in the
+// outline and bookmarks to w in GraphViz format, in the manner of
+// -format=graphviz, and returns the number of calls.
+func (s *session) writeDot(w io.Writer) int {
+	type call struct{ caller, callee string }
+	seen := make(map[call]bool)
+	var calls []call
+	add := func(edge *callgraph.Edge) {
+		c := call{edge.Caller.Func.String(), edge.Callee.Func.String()}
+		if !seen[c] {
+			seen[c] = true
+			calls = append(calls, c)
+		}
+	}
+	var visit func(o *outlineNode)
+	visit = func(o *outlineNode) {
+		if o.edge != nil {
+			add(o.edge)
+		}
+		for _, child := range o.children {
+			visit(child)
+		}
+	}
+	for _, root := range s.roots {
+		visit(root)
+	}
+	for _, b := range s.marks {
+		for _, edge := range b.edges {
+			add(edge)
+		}
+	}
+
+	fmt.Fprint(w, "digraph callgraph {\n")
+	for _, root := range s.roots {
+		fmt.Fprintf(w, "  %q\n", root.node.Func.String())
+	}
+	for _, c := range calls {
+		fmt.Fprintf(w, "  %q -> %q\n", c.caller, c.callee)
+	}
+	fmt.Fprint(w, "}\n")
+	return len(calls)
+}
@@ -9,13 +9,10 @@ package main // import "golang.org/x/tools/cmd/callgraph"
 // TODO(adonovan):
 //
 // Features:
-// - restrict graph to a single package
 // - output
 //   - functions reachable from root (use digraph tool?)
 //   - unreachable functions (use digraph tool?)
 //   - dynamic (runtime) types
-//   - indexed output (numbered nodes)
-//   - JSON output
 //   - additional template fields:
 //     callee file/line/col
 
@@ -26,6 +23,7 @@ import (
 	"go/token"
 	"io"
 	"os"
+	"regexp"
 	"runtime"
 	"text/template"
 
@@ -42,7 +40,7 @@ import (
 // flags
 var (
 	algoFlag = flag.String("algo", "rta",
-		`Call graph construction algorithm (static, cha, rta, vta)`)
+		`Call graph construction algorithm (static, cha, rta, vta, rta+vta)`)
 
 	testFlag = flag.Bool("test", false,
 		"Loads test code (*_test.go) for imported packages")
@@ -52,13 +50,25 @@ var (
 		"A template expression specifying how to format an edge")
 
 	tagsFlag = flag.String("tags", "", "comma-separated list of extra build tags (see: go help buildconstraint)")
+
+	pkgFlag = flag.String("pkg", "", "Show only functions of packages whose path matches this regular expression")
+
+	funcFlag = flag.String("func", "", "Show only functions whose name matches this regular expression")
+
+	collapseFlag = flag.Bool("collapse", false, "Show calls between packages instead of functions")
+
+	httpFlag = flag.String("http", "", "Serve an interactive call graph explorer at this address (e.g. localhost:8080)")
+
+	interactiveFlag = flag.Bool("interactive", false, "Explore the call graph interactively in the terminal")
 )
 
 const Usage = `callgraph: display the call graph of a Go program.
 
 Usage:
 
-  callgraph [-algo=static|cha|rta|vta] [-test] [-format=...] package...
+  callgraph [-algo=static|cha|rta|vta|rta+vta] [-test] [-format=...]
+            [-pkg=regexp] [-func=regexp] [-collapse] [-http=addr]
+            [-interactive] package...
 
 Flags:
 
@@ -68,10 +78,14 @@ Flags:
             cha         Class Hierarchy Analysis
             rta         Rapid Type Analysis
             vta         Variable Type Analysis
+            rta+vta     Variable Type Analysis of the functions
+                        reachable by Rapid Type Analysis
 
            The algorithms are ordered by increasing precision in their
-           treatment of dynamic calls (and thus also computational cost).
-           RTA requires a whole program (main or test), and
+           treatment of dynamic calls (and thus also computational cost),
+           except rta+vta, which is nearly as precise as vta but
+           cheaper on large programs.
+           RTA and rta+vta require a whole program (main or test), and
            include only functions reachable from main.
 
 -test      Include the package's tests in the analysis.
@@ -82,6 +96,9 @@ Flags:
             digraph     output suitable for input to
                         golang.org/x/tools/cmd/digraph.
             graphviz    output in AT&T GraphViz (.dot) format.
+            json        a JSON object with lists of nodes and edges,
+                        in which edges refer to nodes by their id.
+            graphml     output in GraphML format.
 
            All other values are interpreted using text/template syntax.
            The default value is:
@@ -100,6 +117,7 @@ Flags:
                            Line        int    // line number
                            Column      int    // column number of call
                            Dynamic     string // "static" or "dynamic"
+                           Kind        string // e.g. "interface|go" (see callgraph.EdgeKind)
                            Description string // e.g. "static method call"
                    }
 
@@ -120,6 +138,49 @@ Flags:
            Consult the documentation for go/token, text/template, and
            golang.org/x/tools/go/ssa for more detail.
 
+-pkg       Show only the functions of packages whose import path matches
+           the given regular expression, and the calls between them.
+
+-func      Show only the functions whose name, as printed by the default
+           format, matches the given regular expression, and the calls
+           between them.
+
+-collapse  Show the calls between packages instead of functions: each
+           pair of packages related by one or more calls yields a
+           single edge. Calls within a package are omitted.
+           Under -collapse, the structure passed to the template is:
+
+                   type PackageEdge struct {
+                           Caller string // import path of calling package
+                           Callee string // import path of called package
+                           Calls  int    // number of calls
+
+                           // First call site, as in Edge:
+                           Filename string
+                           Offset   int
+                           Line     int
+                           Column   int
+                           Dynamic  string // "dynamic" if any call is dynamic
+                   }
+
+-http      Instead of printing the call graph, serve a web-based explorer
+           of it at the given address. The explorer lets you search for
+           functions by name and follow their callers and callees.
+           Under -algo=vta, it also shows, for each value of interface
+           or function type, the types and functions that may flow to it.
+           The -format, -pkg, -func, and -collapse flags are ignored.
+
+-interactive
+           Instead of printing the call graph, read commands from the
+           standard input to explore it. The explorer lets you search
+           for functions by name, add them to an outline, and expand
+           each line of the outline, incrementally, to show its
+           callers or callees. Paths through the outline may be
+           bookmarked, and the calls shown in the outline and the
+           bookmarks may be written to a file in GraphViz (.dot) format.
+           Type 'help' for a list of commands.
+           The -format, -pkg, -func, and -collapse flags are ignored.
+
 Examples:
 
   Show the call graph of the trivial web server application:
@@ -138,6 +199,12 @@ Examples:
       sed -ne 's/-dynamic-/--/p' |
       sed -ne 's/-->.*fmt_test.*$//p' | sort | uniq
 
+  Show the dependencies between the packages of the callgraph tool,
+  ignoring those into the standard library, in GraphML format:
+
+    callgraph -collapse -pkg=golang.org/x/tools -format=graphml \
+      golang.org/x/tools/cmd/callgraph
+
   Show all functions directly called by the callgraph tool's main function:
 
     callgraph -format=digraph golang.org/x/tools/cmd/callgraph |
@@ -158,7 +225,22 @@ func init() {
 
 func main() {
 	flag.Parse()
-	if err := doCallgraph("", "", *algoFlag, *formatFlag, *testFlag, flag.Args()); err != nil {
+	var v view
+	for _, f := range []struct {
+		name, value string
+		rx          **regexp.Regexp
+	}{{"-pkg", *pkgFlag, &v.pkg}, {"-func", *funcFlag, &v.fn}} {
+		if f.value != "" {
+			rx, err := regexp.Compile(f.value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "callgraph: invalid %s: %s\n", f.name, err)
+				os.Exit(1)
+			}
+			*f.rx = rx
+		}
+	}
+	v.collapse = *collapseFlag
+	if err := doCallgraph("", "", *algoFlag, *formatFlag, v, *testFlag, flag.Args()); err != nil {
 		fmt.Fprintf(os.Stderr, "callgraph: %s\n", err)
 		os.Exit(1)
 	}
@@ -166,12 +248,136 @@ func main() {
 
 var stdout io.Writer = os.Stdout
 
-func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) error {
+// A view specifies which part of the call graph to display.
+type view struct {
+	pkg, fn  *regexp.Regexp // if non-nil, show only the functions whose package or name matches
+	collapse bool           // show calls between packages instead of functions
+}
+
+// show reports whether the view includes function f.
+func (v view) show(f *ssa.Function) bool {
+	if v.pkg != nil && !v.pkg.MatchString(funcPkgPath(f)) {
+		return false
+	}
+	if v.fn != nil && !v.fn.MatchString(f.String()) {
+		return false
+	}
+	return true
+}
+
+// funcPkgPath returns the import path of the package of f,
+// or "" for a synthetic function that belongs to no package.
+func funcPkgPath(f *ssa.Function) string {
+	if f.Origin() != nil {
+		f = f.Origin()
+	}
+	if f.Pkg == nil {
+		return ""
+	}
+	return f.Pkg.Pkg.Path()
+}
+
+func doCallgraph(dir, gopath, algo, format string, v view, tests bool, args []string) error {
 	if len(args) == 0 {
 		fmt.Fprint(os.Stderr, Usage)
 		return nil
 	}
 
+	prog, cg, vtares, err := buildCallGraph(dir, gopath, algo, tests, args)
+	if err != nil {
+		return err
+	}
+
+	if *httpFlag != "" {
+		return serveExplorer(*httpFlag, newExplorer(prog, cg, vtares))
+	}
+	if *interactiveFlag {
+		return explore(os.Stdin, stdout, newExplorer(prog, cg, vtares))
+	}
+
+	// -- output------------------------------------------------------------
+
+	var edges []*callgraph.Edge
+	if err := callgraph.GraphVisitEdges(cg, func(edge *callgraph.Edge) error {
+		if v.show(edge.Caller.Func) && v.show(edge.Callee.Func) {
+			edges = append(edges, edge)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return writeJSON(stdout, prog.Fset, edges, v.collapse)
+	case "graphml":
+		return writeGraphML(stdout, prog.Fset, edges, v.collapse)
+	}
+
+	var before, after string
+
+	// Pre-canned formats.
+	switch format {
+	case "digraph":
+		format = `{{printf "%q %q" .Caller .Callee}}`
+
+	case "graphviz":
+		before = "digraph callgraph {\n"
+		after = "}\n"
+		format = `  {{printf "%q" .Caller}} -> {{printf "%q" .Callee}}`
+	}
+
+	funcMap := template.FuncMap{
+		"posn": func(f *ssa.Function) token.Position {
+			return f.Prog.Fset.Position(f.Pos())
+		},
+	}
+	tmpl, err := template.New("-format").Funcs(funcMap).Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid -format template: %v", err)
+	}
+
+	// Allocate these once, outside the traversal.
+	var buf bytes.Buffer
+	execute := func(data any) error {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return err
+		}
+		stdout.Write(buf.Bytes())
+		if len := buf.Len(); len == 0 || buf.Bytes()[len-1] != '\n' {
+			fmt.Fprintln(stdout)
+		}
+		return nil
+	}
+
+	fmt.Fprint(stdout, before)
+	if v.collapse {
+		for _, edge := range collapse(prog.Fset, edges) {
+			if err := execute(edge); err != nil {
+				return err
+			}
+		}
+	} else {
+		data := Edge{fset: prog.Fset}
+		for _, edge := range edges {
+			data.position.Offset = -1
+			data.edge = edge
+			data.Caller = edge.Caller.Func
+			data.Callee = edge.Callee.Func
+			if err := execute(&data); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprint(stdout, after)
+	return nil
+}
+
+// buildCallGraph loads the specified packages and computes their call
+// graph using the specified algorithm. Under vta, it also returns the
+// result of the analysis.
+func buildCallGraph(dir, gopath, algo string, tests bool, args []string) (*ssa.Program, *callgraph.Graph, *vta.Result, error) {
 	cfg := &packages.Config{
 		Mode:       packages.LoadAllSyntax,
 		BuildFlags: []string{"-tags=" + *tagsFlag},
@@ -183,10 +389,10 @@ func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) er
 	}
 	initial, err := packages.Load(cfg, args...)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
 	if packages.PrintErrors(initial) > 0 {
-		return fmt.Errorf("packages contain errors")
+		return nil, nil, nil, fmt.Errorf("packages contain errors")
 	}
 
 	// Create and build SSA-form program representation.
@@ -196,7 +402,10 @@ func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) er
 
 	// -- call graph construction ------------------------------------------
 
-	var cg *callgraph.Graph
+	var (
+		cg     *callgraph.Graph
+		vtares *vta.Result
+	)
 
 	switch algo {
 	case "static":
@@ -206,81 +415,37 @@ func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) er
 		cg = cha.CallGraph(prog)
 
 	case "pta":
-		return fmt.Errorf("pointer analysis is no longer supported (see Go issue #59676)")
+		return nil, nil, nil, fmt.Errorf("pointer analysis is no longer supported (see Go issue #59676)")
 
-	case "rta":
+	case "rta", "rta+vta":
 		mains, err := mainPackages(pkgs)
 		if err != nil {
-			return err
+			return nil, nil, nil, err
 		}
 		var roots []*ssa.Function
 		for _, main := range mains {
 			roots = append(roots, main.Func("init"), main.Func("main"))
 		}
+		if algo == "rta+vta" {
+			cg = vta.PrunedCallGraph(roots)
+			break
+		}
 		rtares := rta.Analyze(roots, true)
 		cg = rtares.CallGraph
 
 		// NB: RTA gives us Reachable and RuntimeTypes too.
 
 	case "vta":
-		cg = vta.CallGraph(ssautil.AllFunctions(prog), nil)
+		vtares = vta.Analyze(ssautil.AllFunctions(prog), nil)
+		cg = vtares.CallGraph
 
 	default:
-		return fmt.Errorf("unknown algorithm: %s", algo)
+		return nil, nil, nil, fmt.Errorf("unknown algorithm: %s", algo)
 	}
 
 	cg.DeleteSyntheticNodes()
 
-	// -- output------------------------------------------------------------
-
-	var before, after string
-
-	// Pre-canned formats.
-	switch format {
-	case "digraph":
-		format = `{{printf "%q %q" .Caller .Callee}}`
-
-	case "graphviz":
-		before = "digraph callgraph {\n"
-		after = "}\n"
-		format = `  {{printf "%q" .Caller}} -> {{printf "%q" .Callee}}`
-	}
-
-	funcMap := template.FuncMap{
-		"posn": func(f *ssa.Function) token.Position {
-			return f.Prog.Fset.Position(f.Pos())
-		},
-	}
-	tmpl, err := template.New("-format").Funcs(funcMap).Parse(format)
-	if err != nil {
-		return fmt.Errorf("invalid -format template: %v", err)
-	}
-
-	// Allocate these once, outside the traversal.
-	var buf bytes.Buffer
-	data := Edge{fset: prog.Fset}
-
-	fmt.Fprint(stdout, before)
-	if err := callgraph.GraphVisitEdges(cg, func(edge *callgraph.Edge) error {
-		data.position.Offset = -1
-		data.edge = edge
-		data.Caller = edge.Caller.Func
-		data.Callee = edge.Callee.Func
-
-		buf.Reset()
-		if err := tmpl.Execute(&buf, &data); err != nil {
-			return err
-		}
-		stdout.Write(buf.Bytes())
-		if len := buf.Len(); len == 0 || buf.Bytes()[len-1] != '\n' {
-			fmt.Fprintln(stdout)
-		}
-		return nil
-	}); err != nil {
-		return err
-	}
-	fmt.Fprint(stdout, after)
-	return nil
+	return prog, cg, vtares, nil
 }
 
 // mainPackages returns the main packages to analyze.
@@ -326,4 +491,6 @@ func (e *Edge) Dynamic() string {
 	return "static"
 }
 
+func (e *Edge) Kind() string { return e.edge.Kind().String() }
+
 func (e *Edge) Description() string { return e.edge.Description() }
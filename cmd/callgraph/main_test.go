@@ -13,11 +13,15 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/internal/testenv"
 )
 
@@ -60,6 +64,12 @@ func TestCallgraph(t *testing.T) {
 			"pkg.main --> pkg.main2",
 			"pkg.main2 --> (pkg.D).f",
 		}},
+		{"rta+vta", false, []string{
+			// rta+vta is as precise as vta here.
+			"pkg.main --> (pkg.C).f",
+			"pkg.main --> pkg.main2",
+			"pkg.main2 --> (pkg.D).f",
+		}},
 		// tests: both the package's main and the test's main are called.
 		// The callgraph includes all the guts of the "testing" package.
 		{"rta", true, []string{
@@ -77,7 +87,7 @@ func TestCallgraph(t *testing.T) {
 	} {
 		const format = "{{.Caller}} --> {{.Callee}}"
 		stdout = new(bytes.Buffer)
-		if err := doCallgraph("testdata/src", gopath, test.algo, format, test.tests, []string{"pkg"}); err != nil {
+		if err := doCallgraph("testdata/src", gopath, test.algo, format, view{}, test.tests, []string{"pkg"}); err != nil {
 			t.Error(err)
 			continue
 		}
@@ -99,3 +109,235 @@ func TestCallgraph(t *testing.T) {
 		}
 	}
 }
+
+func TestCallgraphView(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		format string
+		view   view
+		want   string
+	}{
+		{"digraph", view{fn: regexp.MustCompile(`main2|D`)}, `"pkg.main2" "(pkg.D).f"
+`},
+		{"json", view{fn: regexp.MustCompile(`main2|D`)}, `{
+	"nodes": [
+		{
+			"id": 0,
+			"name": "(pkg.D).f",
+			"package": "pkg",
+			"position": "$GOPATH/src/pkg/pkg.go:13:10"
+		},
+		{
+			"id": 1,
+			"name": "pkg.main2",
+			"package": "pkg",
+			"position": "$GOPATH/src/pkg/pkg.go:22:6"
+		}
+	],
+	"edges": [
+		{
+			"caller": 1,
+			"callee": 0,
+			"position": "$GOPATH/src/pkg/pkg.go:24:5",
+			"dynamic": true,
+			"kind": "interface",
+			"description": "dynamic method call"
+		}
+	]
+}
+`},
+		{"graphml", view{fn: regexp.MustCompile(`main2|D`)}, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="name" for="node" attr.name="name" attr.type="string"></key>
+  <key id="package" for="node" attr.name="package" attr.type="string"></key>
+  <key id="position" for="all" attr.name="position" attr.type="string"></key>
+  <key id="dynamic" for="edge" attr.name="dynamic" attr.type="boolean"></key>
+  <key id="kind" for="edge" attr.name="kind" attr.type="string"></key>
+  <key id="description" for="edge" attr.name="description" attr.type="string"></key>
+  <key id="calls" for="edge" attr.name="calls" attr.type="int"></key>
+  <graph id="callgraph" edgedefault="directed">
+    <node id="n0">
+      <data key="name">(pkg.D).f</data>
+      <data key="package">pkg</data>
+      <data key="position">$GOPATH/src/pkg/pkg.go:13:10</data>
+    </node>
+    <node id="n1">
+      <data key="name">pkg.main2</data>
+      <data key="package">pkg</data>
+      <data key="position">$GOPATH/src/pkg/pkg.go:22:6</data>
+    </node>
+    <edge source="n1" target="n0">
+      <data key="position">$GOPATH/src/pkg/pkg.go:24:5</data>
+      <data key="dynamic">true</data>
+      <data key="kind">interface</data>
+      <data key="description">dynamic method call</data>
+    </edge>
+  </graph>
+</graphml>
+`},
+		// All calls are within package pkg.
+		{"digraph", view{collapse: true}, ""},
+		{"{{.Caller}}", view{pkg: regexp.MustCompile(`^fmt$`)}, ""},
+	} {
+		stdout = new(bytes.Buffer)
+		if err := doCallgraph("testdata/src", gopath, "vta", test.format, test.view, false, []string{"pkg"}); err != nil {
+			t.Error(err)
+			continue
+		}
+		got := strings.ReplaceAll(fmt.Sprint(stdout), gopath, "$GOPATH")
+		if got != test.want {
+			t.Errorf("callgraph(-format=%s, %+v) = %s, want %s", test.format, test.view, got, test.want)
+		}
+	}
+}
+
+// TestCollapse checks that the default format applies to the edges
+// between packages.
+func TestCollapse(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout = new(bytes.Buffer)
+	if err := doCallgraph("testdata/src", gopath, "vta", *formatFlag, view{collapse: true}, false, []string{"collapse"}); err != nil {
+		t.Fatal(err)
+	}
+	const want = "collapse\t--dynamic-7:5-->\tcollapse/sub\n"
+	if got := fmt.Sprint(stdout); got != want {
+		t.Errorf("callgraph(-collapse) = %q, want %q", got, want)
+	}
+}
+
+func TestExplorer(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, cg, res, err := buildCallGraph("testdata/src", gopath, "vta", false, []string{"pkg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newExplorer(prog, cg, res)
+	id := func(name string) int {
+		for f, id := range e.ids {
+			if f.String() == name {
+				return id
+			}
+		}
+		t.Fatalf("no function %s", name)
+		return -1
+	}
+	main2 := id("pkg.main2")
+
+	for _, test := range []struct {
+		url  string
+		want []string
+	}{
+		{"/?q=MAIN2", []string{fmt.Sprintf(`<a href="/func?id=%d">pkg.main2</a>`, main2)}},
+		{fmt.Sprintf("/func?id=%d", main2), []string{
+			fmt.Sprintf(`<a href="/func?id=%d">(pkg.D).f</a></td><td>interface</td>`, id("(pkg.D).f")),
+			fmt.Sprintf(`<a href="/func?id=%d">pkg.main</a></td><td>static</td>`, id("pkg.main")),
+			fmt.Sprintf(`<a href="/value?id=%d&amp;v=t0">`, main2),
+		}},
+		{fmt.Sprintf("/value?id=%d&v=t0", main2), []string{"<td>pkg.D</td>"}},
+	} {
+		req := httptest.NewRequest("GET", test.url, nil)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d", test.url, w.Code)
+			continue
+		}
+		body := w.Body.String()
+		for _, want := range test.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("GET %s: body does not contain %q:\n%s", test.url, want, body)
+			}
+		}
+	}
+
+	for _, url := range []string{"/func?id=-1", "/value?id=0&v=nonesuch", "/nonesuch"} {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want %d", url, w.Code, http.StatusNotFound)
+		}
+	}
+
+	// A graph's root node may have no function.
+	// Edges from it must not be linked.
+	root := cg.CreateNode(nil)
+	callgraph.AddEdge(root, nil, e.nodes[id("pkg.main")])
+	url := fmt.Sprintf("/func?id=%d", id("pkg.main"))
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	if body := w.Body.String(); !strings.Contains(body, "<td>&lt;root&gt;</td>") || strings.Contains(body, "id=-1") {
+		t.Errorf("GET %s: body lacks unlinked root caller:\n%s", url, body)
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, cg, res, err := buildCallGraph("testdata/src", gopath, "vta", false, []string{"pkg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := filepath.Join(t.TempDir(), "x.dot")
+	script := strings.Join([]string{
+		"/MAIN2",
+		"open 1",
+		"+1",
+		"-1",
+		"callees 1",
+		"mark 2",
+		"dot " + dot,
+		"+99",
+		"bogus",
+		"quit",
+		"show", // not reached
+	}, "\n")
+	var out bytes.Buffer
+	if err := explore(strings.NewReader(script), &out, newExplorer(prog, cg, res)); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.ReplaceAll(out.String(), gopath, "$GOPATH")
+	for _, want := range []string{
+		"[1] pkg.main2\n",
+		"   2   -> (pkg.D).f  [interface $GOPATH/src/pkg/pkg.go:",
+		"   2   <- pkg.main  [static $GOPATH/src/pkg/pkg.go:",
+		"bookmark 1: pkg.main2 -> (pkg.D).f\n",
+		"wrote 1 calls to ",
+		`error: no line "99" in outline`,
+		`error: unknown command "bogus"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "for a list\n> ") {
+		t.Errorf("session did not end at quit command:\n%s", got)
+	}
+
+	data, err := os.ReadFile(dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `  "pkg.main2" -> "(pkg.D).f"`; !strings.Contains(string(data), want) {
+		t.Errorf("dot file does not contain %q:\n%s", want, data)
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+import "collapse/sub"
+
+func main() {
+	var i sub.I = sub.T{}
+	i.M() // dynamic call
+
+	sub.F()
+}
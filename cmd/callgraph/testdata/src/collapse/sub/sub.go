@@ -0,0 +1,9 @@
+package sub
+
+type I interface{ M() }
+
+type T struct{}
+
+func (T) M() {}
+
+func F() {}
@@ -0,0 +1,342 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file defines the web-based call graph explorer (-http).
+
+import (
+	"fmt"
+	"go/token"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxResults bounds the number of functions listed by a search.
+const maxResults = 500
+
+// An explorer is an HTTP handler that serves pages for browsing
+// a call graph. Functions are identified in URLs by their index
+// in nodes.
+type explorer struct {
+	prog  *ssa.Program
+	nodes []*callgraph.Node     // in order of function name
+	ids   map[*ssa.Function]int // index of each function in nodes
+	vta   *vta.Result           // may be nil
+	mux   *http.ServeMux
+}
+
+// newExplorer returns an explorer of the call graph cg of prog.
+// If res is non-nil, it is the VTA result from which cg was
+// computed, and the explorer shows the types flowing to values.
+func newExplorer(prog *ssa.Program, cg *callgraph.Graph, res *vta.Result) *explorer {
+	e := &explorer{
+		prog: prog,
+		ids:  make(map[*ssa.Function]int),
+		vta:  res,
+		mux:  http.NewServeMux(),
+	}
+	for _, n := range cg.Nodes {
+		if n.Func != nil {
+			e.nodes = append(e.nodes, n)
+		}
+	}
+	sort.Slice(e.nodes, func(i, j int) bool {
+		return e.nodes[i].Func.String() < e.nodes[j].Func.String()
+	})
+	for i, n := range e.nodes {
+		e.ids[n.Func] = i
+	}
+	e.mux.HandleFunc("/", e.handleSearch)
+	e.mux.HandleFunc("/func", e.handleFunc)
+	e.mux.HandleFunc("/value", e.handleValue)
+	return e
+}
+
+func (e *explorer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e.mux.ServeHTTP(w, req)
+}
+
+// serveExplorer serves the explorer at the specified address
+// until an error occurs.
+func serveExplorer(addr string, e *explorer) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "callgraph: serving explorer at http://%s/\n", ln.Addr())
+	return http.Serve(ln, e)
+}
+
+// A funcLink is the template data for a link to a function page.
+type funcLink struct {
+	ID   int // -1 if the function is not in the graph
+	Name string
+}
+
+// link returns the link to function f. A nil f denotes
+// the synthetic root node of the graph, which has no page.
+func (e *explorer) link(f *ssa.Function) funcLink {
+	if f == nil {
+		return funcLink{-1, "<root>"}
+	}
+	id, ok := e.ids[f]
+	if !ok {
+		id = -1
+	}
+	return funcLink{id, f.String()}
+}
+
+// node returns the node identified by the "id" parameter of req.
+func (e *explorer) node(req *http.Request) *callgraph.Node {
+	id, err := strconv.Atoi(req.FormValue("id"))
+	if err != nil || id < 0 || id >= len(e.nodes) {
+		return nil
+	}
+	return e.nodes[id]
+}
+
+func (e *explorer) handleSearch(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+	q := req.FormValue("q")
+	data := struct {
+		Query     string
+		Funcs     []funcLink
+		Truncated bool
+	}{Query: q}
+	if q != "" {
+		lq := strings.ToLower(q)
+		for i, n := range e.nodes {
+			if name := n.Func.String(); strings.Contains(strings.ToLower(name), lq) {
+				if len(data.Funcs) == maxResults {
+					data.Truncated = true
+					break
+				}
+				data.Funcs = append(data.Funcs, funcLink{i, name})
+			}
+		}
+	}
+	e.execute(w, searchTemplate, data)
+}
+
+// A callLink is the template data for a call graph edge.
+type callLink struct {
+	Func     funcLink // the caller or callee
+	Position string
+	Kind     string
+}
+
+// A valueLink is the template data for a link to a value page.
+type valueLink struct {
+	Name string
+	Desc string
+}
+
+func (e *explorer) handleFunc(w http.ResponseWriter, req *http.Request) {
+	n := e.node(req)
+	if n == nil {
+		http.NotFound(w, req)
+		return
+	}
+	edgeData := func(edge *callgraph.Edge, f *ssa.Function) callLink {
+		return callLink{
+			Func:     e.link(f),
+			Position: e.position(edge.Pos()),
+			Kind:     edge.Kind().String(),
+		}
+	}
+	data := struct {
+		ID       int
+		Name     string
+		Position string
+		Callers  []callLink
+		Callees  []callLink
+		Values   []valueLink
+		VTA      bool
+	}{
+		ID:       e.ids[n.Func],
+		Name:     n.Func.String(),
+		Position: e.position(n.Func.Pos()),
+		VTA:      e.vta != nil,
+	}
+	for _, edge := range n.In {
+		data.Callers = append(data.Callers, edgeData(edge, edge.Caller.Func))
+	}
+	for _, edge := range n.Out {
+		data.Callees = append(data.Callees, edgeData(edge, edge.Callee.Func))
+	}
+	if e.vta != nil {
+		for _, v := range funcValues(n.Func) {
+			if _, ok := e.vta.ValueNode(v); ok {
+				data.Values = append(data.Values, valueLink{v.Name(), valueString(v)})
+			}
+		}
+	}
+	e.execute(w, funcTemplate, data)
+}
+
+func (e *explorer) handleValue(w http.ResponseWriter, req *http.Request) {
+	n := e.node(req)
+	if n == nil || e.vta == nil {
+		http.NotFound(w, req)
+		return
+	}
+	var v ssa.Value
+	for _, fv := range funcValues(n.Func) {
+		if fv.Name() == req.FormValue("v") {
+			v = fv
+			break
+		}
+	}
+	if v == nil {
+		http.NotFound(w, req)
+		return
+	}
+	type label struct {
+		Type string
+		Func *funcLink
+	}
+	data := struct {
+		Func   funcLink
+		Value  string
+		Type   string
+		Labels []label
+	}{
+		Func:  e.link(n.Func),
+		Value: valueString(v),
+		Type:  v.Type().String(),
+	}
+	for _, l := range e.vta.ValueLabels(v) {
+		lab := label{Type: l.Type.String()}
+		if l.Func != nil {
+			fl := e.link(l.Func)
+			lab.Func = &fl
+		}
+		data.Labels = append(data.Labels, lab)
+	}
+	e.execute(w, valueTemplate, data)
+}
+
+// position returns the position of pos, or "" if not valid.
+func (e *explorer) position(pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	return e.prog.Fset.Position(pos).String()
+}
+
+func (e *explorer) execute(w http.ResponseWriter, tmpl *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Printf("executing %s template: %v", tmpl.Name(), err)
+	}
+}
+
+// funcValues returns the parameters, free variables,
+// and instruction values of f.
+func funcValues(f *ssa.Function) []ssa.Value {
+	var values []ssa.Value
+	for _, p := range f.Params {
+		values = append(values, p)
+	}
+	for _, fv := range f.FreeVars {
+		values = append(values, fv)
+	}
+	for _, b := range f.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(ssa.Value); ok {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// valueString returns a description of a value,
+// such as "t3 = make I <- D (t2)".
+func valueString(v ssa.Value) string {
+	if _, ok := v.(ssa.Instruction); ok {
+		return v.Name() + " = " + v.String()
+	}
+	return v.Name() + " " + v.Type().String()
+}
+
+const pageHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>callgraph</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+code, td { font-family: monospace; }
+td { padding-right: 2em; }
+.none { color: gray; }
+</style>
+</head>
+<body>
+<form action="/"><input name="q" size="60" placeholder="Search functions"> <input type="submit" value="Search"></form>
+`
+
+const pageFooter = `</body>
+</html>
+`
+
+// funcLinkTemplate renders a funcLink, as plain text if the
+// function has no page.
+const funcLinkTemplate = `{{define "funclink"}}{{if ge .ID 0}}<a href="/func?id={{.ID}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}{{end}}`
+
+var searchTemplate = template.Must(template.New("search").Parse(pageHeader + `
+{{if .Query}}
+<h2>Functions matching <code>{{.Query}}</code></h2>
+{{range .Funcs}}<div><a href="/func?id={{.ID}}">{{.Name}}</a></div>
+{{else}}<p class="none">No matching functions.</p>
+{{end}}
+{{if .Truncated}}<p class="none">(Results truncated.)</p>{{end}}
+{{end}}
+` + pageFooter))
+
+var funcTemplate = template.Must(template.New("func").Parse(funcLinkTemplate + pageHeader + `
+<h2><code>{{.Name}}</code></h2>
+{{with .Position}}<p>Declared at {{.}}</p>{{end}}
+<h3>Callers</h3>
+{{if .Callers}}<table>
+{{range .Callers}}<tr><td>{{template "funclink" .Func}}</td><td>{{.Kind}}</td><td>{{.Position}}</td></tr>
+{{end}}</table>
+{{else}}<p class="none">None.</p>{{end}}
+<h3>Callees</h3>
+{{if .Callees}}<table>
+{{range .Callees}}<tr><td>{{template "funclink" .Func}}</td><td>{{.Kind}}</td><td>{{.Position}}</td></tr>
+{{end}}</table>
+{{else}}<p class="none">None.</p>{{end}}
+{{if .VTA}}
+<h3>Values</h3>
+{{$id := .ID}}
+{{range .Values}}<div><a href="/value?id={{$id}}&amp;v={{.Name}}"><code>{{.Desc}}</code></a></div>
+{{else}}<p class="none">No values of interface or function type.</p>
+{{end}}
+{{end}}
+` + pageFooter))
+
+var valueTemplate = template.Must(template.New("value").Parse(funcLinkTemplate + pageHeader + `
+<h2><code>{{.Value}}</code></h2>
+<p>Of type <code>{{.Type}}</code>, in {{template "funclink" .Func}}</p>
+<h3>Types and functions that may flow to this value</h3>
+{{if .Labels}}<table>
+{{range .Labels}}<tr><td>{{.Type}}</td><td>{{with .Func}}{{template "funclink" .}}{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p class="none">None.</p>{{end}}
+` + pageFooter))
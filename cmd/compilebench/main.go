@@ -10,6 +10,10 @@
 //
 // It times the compilation of various packages and prints results in
 // the format used by package testing (and expected by golang.org/x/perf/cmd/benchstat).
+// The results begin with goos and goarch configuration lines, and
+// metrics that do not vary from run to run, such as object file sizes,
+// are declared exact with "Unit" lines, so that benchstat does not
+// report confidence intervals for them.
 //
 // The options are:
 //
@@ -34,12 +38,21 @@
 //	-cpuprofile file
 //		Write a CPU profile of the compiler to file.
 //
+//	-cpuprofiledir dir
+//		Write a CPU profile of each benchmark to a file in dir.
+//
 //	-go path
 //		Path to "go" command (default "go").
 //
+//	-json file
+//		Also write the results to file in JSON form.
+//
 //	-memprofile file
 //		Write a memory profile of the compiler to file.
 //
+//	-memprofiledir dir
+//		Write a memory profile of each benchmark to a file in dir.
+//
 //	-memprofilerate rate
 //		Set runtime.MemProfileRate during compilation.
 //
@@ -58,6 +71,10 @@
 // Although -cpuprofile and -memprofile are intended to write a
 // combined profile for all the executed benchmarks to file,
 // today they write only the profile for the last benchmark executed.
+// To profile each compiled package separately, use -cpuprofiledir and
+// -memprofiledir, which write files named after the benchmark, such as
+// BenchmarkTemplate.cpuprof, adding the run number when -count is
+// greater than 1 (BenchmarkTemplate_3.cpuprof).
 //
 // The default memory profiling rate is one profile sample per 512 kB
 // allocated (see “go doc runtime.MemProfileRate”).
@@ -74,6 +91,25 @@
 //	compilebench -count 10 -compile $(toolstash -n compile) >old.txt
 //	compilebench -count 10 >new.txt
 //	benchstat old.txt new.txt
+//
+// # JSON output
+//
+// The -json flag writes a single JSON object of this form,
+// suitable for loading into a dashboard:
+//
+//	{
+//		"Config": {"goos": "linux", "goarch": "amd64"},
+//		"Results": [
+//			{
+//				"Name": "BenchmarkTemplate",
+//				"Run": 0,
+//				"Metrics": [
+//					{"Value": 152083312, "Unit": "ns/op"},
+//					{"Value": 201400000, "Unit": "user-ns/op"}
+//				]
+//			}
+//		]
+//	}
 package main
 
 import (
@@ -114,7 +150,10 @@ var (
 	flagRun            = flag.String("run", "", "run benchmarks matching `regexp`")
 	flagCount          = flag.Int("count", 1, "run benchmarks `n` times")
 	flagCpuprofile     = flag.String("cpuprofile", "", "write CPU profile to `file`")
+	flagCpuprofileDir  = flag.String("cpuprofiledir", "", "write CPU profile of each benchmark to `dir`")
+	flagJSON           = flag.String("json", "", "also write results in JSON form to `file`")
 	flagMemprofile     = flag.String("memprofile", "", "write memory profile to `file`")
+	flagMemprofileDir  = flag.String("memprofiledir", "", "write memory profile of each benchmark to `dir`")
 	flagMemprofilerate = flag.Int64("memprofilerate", -1, "set memory profile `rate`")
 	flagPackage        = flag.String("pkg", "", "if set, benchmark the package at path `pkg`")
 	flagShort          = flag.Bool("short", false, "skip long-running benchmarks")
@@ -199,6 +238,15 @@ func main() {
 		*flagAlloc = false
 		*flagCpuprofile = ""
 		*flagMemprofile = ""
+		*flagCpuprofileDir = ""
+		*flagMemprofileDir = ""
+	}
+	for _, dir := range []string{*flagCpuprofileDir, *flagMemprofileDir} {
+		if dir != "" {
+			if err := os.MkdirAll(dir, 0777); err != nil {
+				log.Fatal(err)
+			}
+		}
 	}
 
 	if *flagRun != "" {
@@ -217,6 +265,7 @@ func main() {
 		runRE = nil
 	}
 
+	printConfig()
 	for i := 0; i < *flagCount; i++ {
 		for _, tt := range tests {
 			if tt.r.long() && *flagShort {
@@ -229,6 +278,12 @@ func main() {
 			}
 		}
 	}
+
+	if *flagJSON != "" {
+		if err := writeJSON(*flagJSON); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 func toolPath(names ...string) (found, path string) {
@@ -247,6 +302,63 @@ func toolPath(names ...string) (found, path string) {
 	return "", ""
 }
 
+// A metric is a single measurement of a benchmark run.
+type metric struct {
+	Value int64
+	Unit  string
+}
+
+// A result records the metrics of one run of a benchmark.
+type result struct {
+	Name    string
+	Run     int
+	Metrics []metric
+}
+
+var results []result
+
+// exactUnits lists the units of metrics that are deterministic.
+var exactUnits = []string{
+	"text-bytes", "data-bytes", "bss-bytes", "exe-bytes",
+	"object-bytes", "export-bytes",
+}
+
+// printConfig prints the configuration lines that precede the results.
+func printConfig() {
+	fmt.Printf("goos: %s\n", runtime.GOOS)
+	fmt.Printf("goarch: %s\n", runtime.GOARCH)
+	for _, unit := range exactUnits {
+		fmt.Printf("Unit %s assume=exact\n", unit)
+	}
+}
+
+// report prints a benchmark line for the numbered run of the named
+// benchmark and records it for the JSON output.
+func report(name string, count int, metrics []metric) {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s 1", name)
+	for _, m := range metrics {
+		fmt.Fprintf(&buf, " %d %s", m.Value, m.Unit)
+	}
+	fmt.Println(buf.String())
+	results = append(results, result{name, count, metrics})
+}
+
+// writeJSON writes the configuration and recorded results to file.
+func writeJSON(file string) error {
+	data, err := json.MarshalIndent(struct {
+		Config  map[string]string
+		Results []result
+	}{
+		Config:  map[string]string{"goos": runtime.GOOS, "goarch": runtime.GOARCH},
+		Results: results,
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0666)
+}
+
 type Pkg struct {
 	ImportPath string
 	Dir        string
@@ -266,13 +378,15 @@ func goList(dir string) (*Pkg, error) {
 	return &pkg, nil
 }
 
-func runCmd(name string, cmd *exec.Cmd) error {
+func runCmd(name string, count int, cmd *exec.Cmd) error {
 	start := time.Now()
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%v\n%s", err, out)
 	}
-	fmt.Printf("%s 1 %d ns/op\n", name, time.Since(start).Nanoseconds())
+	wallns := time.Since(start).Nanoseconds()
+	userns := cmd.ProcessState.UserTime().Nanoseconds()
+	report(name, count, []metric{{wallns, "ns/op"}, {userns, "user-ns/op"}})
 	return nil
 }
 
@@ -288,7 +402,7 @@ func (r goBuild) run(name string, count int) error {
 	args = append(args, r.pkgs...)
 	cmd := exec.Command(*flagGoCmd, args...)
 	cmd.Dir = filepath.Join(goroot, "src")
-	return runCmd(name, cmd)
+	return runCmd(name, count, cmd)
 }
 
 type size struct {
@@ -324,11 +438,24 @@ func (r size) run(name string, count int) error {
 		return fmt.Errorf("not enough output from size: %s", out)
 	}
 	f := strings.Fields(lines[1])
+	var units []string
 	if strings.HasPrefix(lines[0], "__TEXT") && len(f) >= 2 { // OS X
-		fmt.Printf("%s 1 %s text-bytes %s data-bytes %v exe-bytes\n", name, f[0], f[1], info.Size())
+		units = []string{"text-bytes", "data-bytes"}
 	} else if strings.Contains(lines[0], "bss") && len(f) >= 3 {
-		fmt.Printf("%s 1 %s text-bytes %s data-bytes %s bss-bytes %v exe-bytes\n", name, f[0], f[1], f[2], info.Size())
+		units = []string{"text-bytes", "data-bytes", "bss-bytes"}
+	} else {
+		return nil
+	}
+	var metrics []metric
+	for i, unit := range units {
+		v, err := strconv.ParseInt(f[i], 0, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected output from size: %s", out)
+		}
+		metrics = append(metrics, metric{v, unit})
 	}
+	metrics = append(metrics, metric{info.Size(), "exe-bytes"})
+	report(name, count, metrics)
 	return nil
 }
 
@@ -383,7 +510,8 @@ func (c compile) run(name string, count int) error {
 		defer os.Remove(importcfg)
 	}
 	args = append(args, pkg.GoFiles...)
-	if err := runBuildCmd(name, count, pkg.Dir, compiler, args); err != nil {
+	metrics, err := runBuildCmd(name, count, pkg.Dir, compiler, args)
+	if err != nil {
 		return err
 	}
 
@@ -398,9 +526,11 @@ func (c compile) run(name string, count int) error {
 		i := bytes.Index(data, []byte("\n$$B\n")) + len("\n$$B\n")
 		// Count bytes to end of export data.
 		nexport := bytes.Index(data[i:], []byte("\n$$\n"))
-		fmt.Printf(" %d object-bytes %d export-bytes", len(data), nexport)
+		metrics = append(metrics,
+			metric{int64(len(data)), "object-bytes"},
+			metric{int64(nexport), "export-bytes"})
 	}
-	fmt.Println()
+	report(name, count, metrics)
 
 	os.Remove(opath)
 	return nil
@@ -461,33 +591,33 @@ func (r link) run(name string, count int) error {
 	args = append(args, strings.Fields(*flagLinkerFlags)...)
 	args = append(args, strings.Fields(r.flags)...)
 	args = append(args, "_compilebench_.o")
-	if err := runBuildCmd(name, count, pkg.Dir, linker, args); err != nil {
+	metrics, err := runBuildCmd(name, count, pkg.Dir, linker, args)
+	if err != nil {
 		return err
 	}
-	fmt.Println()
 	defer os.Remove(pkg.Dir + "/_compilebench_.exe")
+	report(name, count, metrics)
 
-	return err
+	return nil
 }
 
-// runBuildCmd runs "tool args..." in dir, measures standard build
-// tool metrics, and prints a benchmark line. The caller may print
-// additional metrics and then must print a newline.
+// runBuildCmd runs "tool args..." in dir and returns the standard build
+// tool metrics, to which the caller may add others before reporting them.
 //
 // This assumes tool accepts standard build tool flags like
 // -memprofilerate, -memprofile, and -cpuprofile.
-func runBuildCmd(name string, count int, dir, tool string, args []string) error {
+func runBuildCmd(name string, count int, dir, tool string, args []string) ([]metric, error) {
+	wantMemprofile := *flagAlloc || *flagMemprofile != "" || *flagMemprofileDir != ""
+	wantCpuprofile := *flagCpuprofile != "" || *flagCpuprofileDir != ""
 	var preArgs []string
 	if *flagMemprofilerate >= 0 {
 		preArgs = append(preArgs, "-memprofilerate", fmt.Sprint(*flagMemprofilerate))
 	}
-	if *flagAlloc || *flagCpuprofile != "" || *flagMemprofile != "" {
-		if *flagAlloc || *flagMemprofile != "" {
-			preArgs = append(preArgs, "-memprofile", "_compilebench_.memprof")
-		}
-		if *flagCpuprofile != "" {
-			preArgs = append(preArgs, "-cpuprofile", "_compilebench_.cpuprof")
-		}
+	if wantMemprofile {
+		preArgs = append(preArgs, "-memprofile", "_compilebench_.memprof")
+	}
+	if wantCpuprofile {
+		preArgs = append(preArgs, "-cpuprofile", "_compilebench_.cpuprof")
 	}
 	if *flagTrace {
 		fmt.Fprintf(os.Stderr, "running: %s %+v\n",
@@ -500,13 +630,13 @@ func runBuildCmd(name string, count int, dir, tool string, args []string) error
 	start := time.Now()
 	err := cmd.Run()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	end := time.Now()
 
 	haveAllocs, haveRSS := false, false
 	var allocs, allocbytes, rssbytes int64
-	if *flagAlloc || *flagMemprofile != "" {
+	if wantMemprofile {
 		out, err := os.ReadFile(dir + "/_compilebench_.memprof")
 		if err != nil {
 			log.Print("cannot find memory profile after compilation")
@@ -535,45 +665,53 @@ func runBuildCmd(name string, count int, dir, tool string, args []string) error
 			log.Println("missing stats in memprof (golang.org/issue/18641)")
 		}
 
-		if *flagMemprofile != "" {
-			outpath := *flagMemprofile
-			if *flagCount != 1 {
-				outpath = fmt.Sprintf("%s_%d", outpath, count)
-			}
-			if err := os.WriteFile(outpath, out, 0666); err != nil {
-				log.Print(err)
-			}
-		}
+		saveProfile(out, *flagMemprofile, *flagMemprofileDir, name, count, ".memprof")
 		os.Remove(dir + "/_compilebench_.memprof")
 	}
 
-	if *flagCpuprofile != "" {
+	if wantCpuprofile {
 		out, err := os.ReadFile(dir + "/_compilebench_.cpuprof")
 		if err != nil {
 			log.Print(err)
 		}
-		outpath := *flagCpuprofile
-		if *flagCount != 1 {
-			outpath = fmt.Sprintf("%s_%d", outpath, count)
-		}
-		if err := os.WriteFile(outpath, out, 0666); err != nil {
-			log.Print(err)
-		}
+		saveProfile(out, *flagCpuprofile, *flagCpuprofileDir, name, count, ".cpuprof")
 		os.Remove(dir + "/_compilebench_.cpuprof")
 	}
 
 	wallns := end.Sub(start).Nanoseconds()
 	userns := cmd.ProcessState.UserTime().Nanoseconds()
 
-	fmt.Printf("%s 1 %d ns/op %d user-ns/op", name, wallns, userns)
+	metrics := []metric{{wallns, "ns/op"}, {userns, "user-ns/op"}}
 	if haveAllocs {
-		fmt.Printf(" %d B/op %d allocs/op", allocbytes, allocs)
+		metrics = append(metrics, metric{allocbytes, "B/op"}, metric{allocs, "allocs/op"})
 	}
 	if haveRSS {
-		fmt.Printf(" %d maxRSS/op", rssbytes)
+		metrics = append(metrics, metric{rssbytes, "peak-RSS-bytes"})
 	}
+	return metrics, nil
+}
 
-	return nil
+// saveProfile writes the profile data of the numbered run of the named
+// benchmark to file, if set, and to a file named after the benchmark in
+// dir, if set. The run number is added to the names only when running
+// benchmarks more than once.
+func saveProfile(data []byte, file, dir, name string, count int, ext string) {
+	if file != "" {
+		if *flagCount != 1 {
+			file = fmt.Sprintf("%s_%d", file, count)
+		}
+		if err := os.WriteFile(file, data, 0666); err != nil {
+			log.Print(err)
+		}
+	}
+	if dir != "" {
+		if *flagCount != 1 {
+			name = fmt.Sprintf("%s_%d", name, count)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+ext), data, 0666); err != nil {
+			log.Print(err)
+		}
+	}
 }
 
 func checkCompilingRuntimeFlag(assembler string) error {
@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// captureStdout returns what f prints to standard output.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+	f()
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReport(t *testing.T) {
+	defer func(old []result) { results = old }(results)
+	results = nil
+
+	got := captureStdout(t, func() {
+		printConfig()
+		report("BenchmarkTemplate", 0, []metric{{152, "ns/op"}, {201, "user-ns/op"}})
+		report("BenchmarkTemplate", 1, []metric{{150, "ns/op"}, {198, "user-ns/op"}, {4096, "object-bytes"}})
+	})
+	want := fmt.Sprintf(`goos: %s
+goarch: %s
+Unit text-bytes assume=exact
+Unit data-bytes assume=exact
+Unit bss-bytes assume=exact
+Unit exe-bytes assume=exact
+Unit object-bytes assume=exact
+Unit export-bytes assume=exact
+BenchmarkTemplate 1 152 ns/op 201 user-ns/op
+BenchmarkTemplate 1 150 ns/op 198 user-ns/op 4096 object-bytes
+`, runtime.GOOS, runtime.GOARCH)
+	if got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "results.json")
+	if err := writeJSON(file); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Config  map[string]string
+		Results []result
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, data)
+	}
+	if want := map[string]string{"goos": runtime.GOOS, "goarch": runtime.GOARCH}; !reflect.DeepEqual(out.Config, want) {
+		t.Errorf("JSON Config = %v, want %v", out.Config, want)
+	}
+	if !reflect.DeepEqual(out.Results, results) {
+		t.Errorf("JSON Results = %+v, want %+v", out.Results, results)
+	}
+}
+
+func TestSaveProfile(t *testing.T) {
+	defer func(old int) { *flagCount = old }(*flagCount)
+
+	for _, test := range []struct {
+		count     int
+		file, dir string // names of the files written
+	}{
+		{1, "prof", "BenchmarkTemplate.cpuprof"},
+		{3, "prof_2", "BenchmarkTemplate_2.cpuprof"},
+	} {
+		*flagCount = test.count
+		tmp := t.TempDir()
+		dir := filepath.Join(tmp, "dir")
+		if err := os.Mkdir(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+		saveProfile([]byte("profile"), filepath.Join(tmp, "prof"), dir, "BenchmarkTemplate", 2, ".cpuprof")
+		for _, name := range []string{filepath.Join(tmp, test.file), filepath.Join(dir, test.dir)} {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				t.Errorf("-count=%d: %v", test.count, err)
+			} else if string(data) != "profile" {
+				t.Errorf("-count=%d: %s contains %q, want profile", test.count, name, data)
+			}
+		}
+	}
+}
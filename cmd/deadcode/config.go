@@ -0,0 +1,105 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file defines the matrix of build configurations
+// selected by the -goos, -goarch, and -tagsets flags.
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// A config is a build configuration in which the program is analyzed.
+type config struct {
+	goos, goarch string // empty => that of the go command's environment
+	tags         string // comma-separated list of build tags
+}
+
+func (c config) String() string {
+	var words []string
+	if c.goos != "" {
+		words = append(words, "GOOS="+c.goos)
+	}
+	if c.goarch != "" {
+		words = append(words, "GOARCH="+c.goarch)
+	}
+	if c.tags != "" {
+		words = append(words, "-tags="+c.tags)
+	}
+	return strings.Join(words, " ")
+}
+
+// env returns the environment of the go command for this configuration.
+func (c config) env() []string {
+	env := os.Environ()
+	if c.goos != "" {
+		env = append(env, "GOOS="+c.goos)
+	}
+	if c.goarch != "" {
+		env = append(env, "GOARCH="+c.goarch)
+	}
+	return env
+}
+
+// configs returns the cross product of the configurations
+// selected by the -goos, -goarch, and -tagsets flags, each of which
+// also uses the -tags flag. If none is set, it returns a single
+// configuration, that of the go command's environment.
+func configs() []config {
+	goos := splitList(*goosFlag, ",")
+	goarch := splitList(*goarchFlag, ",")
+	tagsets := splitList(*tagsetsFlag, ";")
+
+	// Ask the go command which GOOS/GOARCH pairs it supports,
+	// so that we can skip the others. (The user is likely to
+	// request a matrix such as {darwin,linux}x{386,amd64},
+	// not all of whose elements are meaningful.)
+	var supported map[string]bool
+	if len(goos) > 1 || len(goarch) > 1 {
+		out, err := exec.Command("go", "tool", "dist", "list").Output()
+		if err == nil {
+			supported = make(map[string]bool)
+			for _, line := range strings.Fields(string(out)) {
+				supported[line] = true
+			}
+		}
+	}
+
+	var configs []config
+	for _, sys := range goos {
+		for _, arch := range goarch {
+			if supported != nil && sys != "" && arch != "" && !supported[sys+"/"+arch] {
+				continue
+			}
+			for _, tags := range tagsets {
+				if *tagsFlag != "" {
+					tags = strings.Trim(*tagsFlag+","+tags, ",")
+				}
+				configs = append(configs, config{goos: sys, goarch: arch, tags: tags})
+			}
+		}
+	}
+	if len(configs) == 0 {
+		log.Fatalf("no supported GOOS/GOARCH combination among -goos=%s -goarch=%s", *goosFlag, *goarchFlag)
+	}
+	return configs
+}
+
+// splitList splits a list of items using the specified separator.
+// An empty list is treated as a single empty item,
+// which selects the default configuration.
+func splitList(list, sep string) []string {
+	if list == "" {
+		return []string{""}
+	}
+	var items []string
+	for _, item := range strings.Split(list, sep) {
+		items = append(items, strings.TrimSpace(item))
+	}
+	return items
+}
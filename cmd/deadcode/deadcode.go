@@ -27,6 +27,7 @@ import (
 	"golang.org/x/telemetry"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/callgraph/vta"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
@@ -41,11 +42,17 @@ var (
 	testFlag = flag.Bool("test", false, "include implicit test packages and executables")
 	tagsFlag = flag.String("tags", "", "comma-separated list of extra build tags (see: go help buildconstraint)")
 
+	goosFlag    = flag.String("goos", "", "comma-separated list of GOOS values in which to analyze the program (default: $GOOS)")
+	goarchFlag  = flag.String("goarch", "", "comma-separated list of GOARCH values in which to analyze the program (default: $GOARCH)")
+	tagsetsFlag = flag.String("tagsets", "", "semicolon-separated list of sets of build tags, each added to -tags, in which to analyze the program")
+
 	filterFlag    = flag.String("filter", "<module>", "report only packages matching this regular expression (default: module of first package)")
 	generatedFlag = flag.Bool("generated", false, "include dead functions in generated Go files")
 	whyLiveFlag   = flag.String("whylive", "", "show a path from main to the named function")
 	formatFlag    = flag.String("f", "", "format output records using template")
 	jsonFlag      = flag.Bool("json", false, "output JSON records")
+	summaryFlag   = flag.Bool("summary", false, "report only the amount of dead code in each package, largest first")
+	preciseFlag   = flag.Bool("precise", false, "also report, with low confidence, functions reachable only through infeasible dynamic calls")
 	cpuProfile    = flag.String("cpuprofile", "", "write CPU profile to this file")
 	memProfile    = flag.String("memprofile", "", "write memory profile to this file")
 )
@@ -111,26 +118,15 @@ func main() {
 		}
 	}
 
-	// Load, parse, and type-check the complete program(s).
-	cfg := &packages.Config{
-		BuildFlags: []string{"-tags=" + *tagsFlag},
-		Mode:       packages.LoadAllSyntax | packages.NeedModule,
-		Tests:      *testFlag,
-	}
-	initial, err := packages.Load(cfg, flag.Args()...)
-	if err != nil {
-		log.Fatalf("Load: %v", err)
-	}
-	if len(initial) == 0 {
-		log.Fatalf("no packages")
-	}
-	if packages.PrintErrors(initial) > 0 {
-		log.Fatalf("packages contain errors")
+	// Analyze the program in each configuration.
+	var analyses []*analysis
+	for _, c := range configs() {
+		analyses = append(analyses, analyze(c))
 	}
 
 	// If -filter is unset, use first module (if available).
 	if *filterFlag == "<module>" {
-		if mod := initial[0].Module; mod != nil && mod.Path != "" {
+		if mod := analyses[0].initial[0].Module; mod != nil && mod.Path != "" {
 			*filterFlag = "^" + regexp.QuoteMeta(mod.Path) + "\\b"
 		} else {
 			*filterFlag = "" // match any
@@ -141,6 +137,177 @@ func main() {
 		log.Fatalf("-filter: %v", err)
 	}
 
+	// The -whylive=fn flag causes deadcode to explain why a function
+	// is not dead, by showing a path to it from some root.
+	if *whyLiveFlag != "" {
+		whyLive(analyses)
+		return
+	}
+
+	// A function is dead if it is unreachable in every
+	// configuration, including those in which it is not
+	// compiled at all. As with -test variants (see analyze),
+	// we identify functions across configurations by position.
+	reachablePosn := make(map[token.Position]bool)
+	var preciseReachablePosn map[token.Position]bool
+	if *preciseFlag {
+		preciseReachablePosn = make(map[token.Position]bool)
+	}
+	generated := make(map[string]bool)
+	for _, a := range analyses {
+		for posn := range a.reachablePosn {
+			reachablePosn[posn] = true
+		}
+		for posn := range a.preciseReachablePosn {
+			preciseReachablePosn[posn] = true
+		}
+		for filename := range a.generated {
+			generated[filename] = true
+		}
+	}
+
+	// Group unreachable functions by package path.
+	byPkgPath := make(map[string][]*deadFunc)
+	seen := make(map[token.Position]bool)
+	for _, a := range analyses {
+		for _, f := range a.sourceFuncs {
+			if seen[f.posn] {
+				continue // suppress dups with same pos
+			}
+
+			// The high field records whether a function is dead
+			// with high confidence (that is, even according to RTA).
+			high := !reachablePosn[f.posn]
+			low := preciseReachablePosn != nil && !preciseReachablePosn[f.posn]
+			if high || low {
+				seen[f.posn] = true
+				f.high = high
+				pkgpath := f.fn.Pkg.Pkg.Path()
+				byPkgPath[pkgpath] = append(byPkgPath[pkgpath], f)
+			}
+		}
+	}
+
+	// Build array of jsonPackage objects.
+	var packages []any
+	pkgpaths := keys(byPkgPath)
+	sort.Strings(pkgpaths)
+	for _, pkgpath := range pkgpaths {
+		if !filter.MatchString(pkgpath) {
+			continue
+		}
+
+		// Print functions that appear within the same file in
+		// declaration order. This tends to keep related
+		// methods such as (T).Marshal and (*T).Unmarshal
+		// together better than sorting.
+		fns := byPkgPath[pkgpath]
+		sort.Slice(fns, func(i, j int) bool {
+			xposn, yposn := fns[i].posn, fns[j].posn
+			if xposn.Filename != yposn.Filename {
+				return xposn.Filename < yposn.Filename
+			}
+			return xposn.Line < yposn.Line
+		})
+
+		pkg := jsonPackage{
+			Name: fns[0].fn.Pkg.Pkg.Name(),
+			Path: pkgpath,
+		}
+		for _, f := range fns {
+			// Without -generated, skip functions declared in
+			// generated Go files.
+			// (Functions called by them may still be reported.)
+			gen := generated[f.posn.Filename]
+			if gen && !*generatedFlag {
+				continue
+			}
+
+			var confidence string
+			if *preciseFlag {
+				confidence = cond(f.high, "high", "low")
+			}
+			pkg.Funcs = append(pkg.Funcs, jsonFunction{
+				Name:       prettyName(f.fn, false),
+				Position:   toJSONPosition(f.posn),
+				Generated:  gen,
+				Confidence: confidence,
+				Lines:      f.lines,
+				Bytes:      f.bytes,
+			})
+			pkg.Lines += f.lines
+			pkg.Bytes += f.bytes
+		}
+		if len(pkg.Funcs) > 0 {
+			packages = append(packages, pkg)
+		}
+	}
+
+	// Default line-oriented format: "a/b/c.go:1:2: unreachable func: T.f"
+	format := `{{range .Funcs}}{{printf "%s: unreachable func: %s\n" .Position .Name}}{{end}}`
+	if *preciseFlag {
+		// "a/b/c.go:1:2: unreachable func: T.f (low confidence)"
+		format = `{{range .Funcs}}{{printf "%s: unreachable func: %s" .Position .Name}}{{if eq .Confidence "low"}} (low confidence){{end}}{{println}}{{end}}`
+	}
+	if *summaryFlag {
+		// Show the packages with the most removable code first.
+		sort.SliceStable(packages, func(i, j int) bool {
+			return packages[i].(jsonPackage).Bytes > packages[j].(jsonPackage).Bytes
+		})
+		// "a/b/c: 3 funcs, 45 lines, 1234 bytes"
+		format = `{{printf "%s: %d funcs, %d lines, %d bytes" .Path (len .Funcs) .Lines .Bytes}}`
+	}
+	if *formatFlag != "" {
+		format = *formatFlag
+	}
+	printObjects(format, packages)
+}
+
+// An analysis holds the results of analyzing
+// the program in a single build configuration.
+type analysis struct {
+	config      config
+	prog        *ssa.Program
+	initial     []*packages.Package
+	roots       []*ssa.Function
+	res         *rta.Result
+	sourceFuncs []*deadFunc
+	generated   map[string]bool // names of generated files
+
+	// reachable functions, by position
+	reachablePosn        map[token.Position]bool
+	preciseReachablePosn map[token.Position]bool // -precise only
+}
+
+// A deadFunc is a source-level function that is a candidate for deletion.
+type deadFunc struct {
+	fn           *ssa.Function
+	posn         token.Position // position of fn's name
+	lines, bytes int            // extent of declaration, including doc comment
+	high         bool           // dead according to RTA (not just VTA)
+}
+
+// analyze loads, parses, and type-checks the complete program(s)
+// in the specified configuration, and computes reachability from main.
+func analyze(c config) *analysis {
+	cfg := &packages.Config{
+		BuildFlags: []string{"-tags=" + c.tags},
+		Env:        c.env(),
+		Mode:       packages.LoadAllSyntax | packages.NeedModule,
+		Tests:      *testFlag,
+	}
+	where := cond(c.goos != "" || c.goarch != "", " ("+c.String()+")", "")
+	initial, err := packages.Load(cfg, flag.Args()...)
+	if err != nil {
+		log.Fatalf("Load%s: %v", where, err)
+	}
+	if len(initial) == 0 {
+		log.Fatalf("no packages")
+	}
+	if packages.PrintErrors(initial) > 0 {
+		log.Fatalf("packages contain errors%s", where)
+	}
+
 	// Create SSA-form program representation
 	// and find main packages.
 	prog, pkgs := ssautil.AllPackages(initial, ssa.InstantiateGenerics)
@@ -163,7 +330,7 @@ func main() {
 	// course address-taken and there exists a dynamic call of
 	// that signature, so when they are unreachable, it is
 	// invariably because the parent is unreachable.
-	var sourceFuncs []*ssa.Function
+	var sourceFuncs []*deadFunc
 	generated := make(map[string]bool)
 	packages.Visit(initial, nil, func(p *packages.Package) {
 		for _, file := range p.Syntax {
@@ -171,7 +338,13 @@ func main() {
 				if decl, ok := decl.(*ast.FuncDecl); ok {
 					obj := p.TypesInfo.Defs[decl.Name].(*types.Func)
 					fn := prog.FuncValue(obj)
-					sourceFuncs = append(sourceFuncs, fn)
+					lines, bytes := extent(p.Fset, decl)
+					sourceFuncs = append(sourceFuncs, &deadFunc{
+						fn:    fn,
+						posn:  prog.Fset.Position(fn.Pos()),
+						lines: lines,
+						bytes: bytes,
+					})
 				}
 			}
 
@@ -182,8 +355,8 @@ func main() {
 	})
 
 	// Compute the reachabilty from main.
-	// (Build a call graph only for -whylive.)
-	res := rta.Analyze(roots, *whyLiveFlag != "")
+	// (Build a call graph only for -whylive and -precise.)
+	res := rta.Analyze(roots, *whyLiveFlag != "" || *preciseFlag)
 
 	// Subtle: the -test flag causes us to analyze test variants
 	// such as "package p as compiled for p.test" or even "for q.test".
@@ -203,44 +376,88 @@ func main() {
 		}
 	}
 
-	// The -whylive=fn flag causes deadcode to explain why a function
-	// is not dead, by showing a path to it from some root.
-	if *whyLiveFlag != "" {
-		targets := make(map[*ssa.Function]bool)
-		for _, fn := range sourceFuncs {
-			if prettyName(fn, true) == *whyLiveFlag {
-				targets[fn] = true
+	// The -precise flag causes us to report as dead, with low
+	// confidence, the functions that RTA deems reachable but that
+	// are not reachable in the more precise VTA call graph. VTA does
+	// not model calls made by reflection, so, like RTA, we assume
+	// that the exported methods of every run-time type accessible to
+	// reflection may be called, and treat them as additional roots.
+	var preciseReachablePosn map[token.Position]bool
+	if *preciseFlag && *whyLiveFlag == "" {
+		preciseReachablePosn = make(map[token.Position]bool)
+		funcs := make(map[*ssa.Function]bool, len(res.Reachable))
+		for fn := range res.Reachable {
+			funcs[fn] = true
+		}
+		cg := vta.CallGraph(funcs, res.CallGraph)
+		seen := make(map[*callgraph.Node]bool)
+		var visit func(n *callgraph.Node)
+		visit = func(n *callgraph.Node) {
+			if n == nil || seen[n] {
+				return
+			}
+			seen[n] = true
+			if fn := n.Func; fn.Pos().IsValid() || fn.Name() == "init" {
+				preciseReachablePosn[prog.Fset.Position(fn.Pos())] = true
+			}
+			for _, e := range n.Out {
+				visit(e.Callee)
 			}
 		}
-		if len(targets) == 0 {
-			// Function is not part of the program.
-			//
-			// TODO(adonovan): improve the UX here in case
-			// of spelling or syntax mistakes. Some ideas:
-			// - a cmd/callgraph command to enumerate
-			//   available functions.
-			// - a deadcode -live flag to compute the complement.
-			// - a syntax hint: example.com/pkg.Func or (example.com/pkg.Type).Method
-			// - report the element of AllFunctions with the smallest
-			//   Levenshtein distance from *whyLiveFlag.
-			// - permit -whylive=regexp. But beware of spurious
-			//   matches (e.g. fmt.Print matches fmt.Println)
-			//   and the annoyance of having to quote parens (*T).f.
-			log.Fatalf("function %q not found in program", *whyLiveFlag)
-		}
-
-		// Opt: remove the unreachable ones.
-		for fn := range targets {
-			if !reachablePosn[prog.Fset.Position(fn.Pos())] {
-				delete(targets, fn)
+		for _, root := range roots {
+			visit(cg.Nodes[root])
+		}
+		res.RuntimeTypes.Iterate(func(T types.Type, skip any) {
+			if skip.(bool) || types.IsInterface(T) {
+				return // inaccessible to reflection, or no methods
+			}
+			mset := prog.MethodSets.MethodSet(T)
+			for i := 0; i < mset.Len(); i++ {
+				if sel := mset.At(i); sel.Obj().Exported() {
+					visit(cg.Nodes[prog.MethodValue(sel)])
+				}
+			}
+		})
+	}
+
+	return &analysis{
+		config:               c,
+		prog:                 prog,
+		initial:              initial,
+		roots:                roots,
+		res:                  res,
+		sourceFuncs:          sourceFuncs,
+		generated:            generated,
+		reachablePosn:        reachablePosn,
+		preciseReachablePosn: preciseReachablePosn,
+	}
+}
+
+// whyLive prints a path from some root to the function named by the
+// -whylive flag, using the first configuration in which it is reachable.
+func whyLive(analyses []*analysis) {
+	found := false
+	for _, a := range analyses {
+		targets := make(map[*ssa.Function]bool)
+		for _, f := range a.sourceFuncs {
+			if prettyName(f.fn, true) == *whyLiveFlag {
+				found = true
+				// Opt: ignore the unreachable ones.
+				if a.reachablePosn[f.posn] {
+					targets[f.fn] = true
+				}
 			}
 		}
 		if len(targets) == 0 {
-			log.Fatalf("function %s is dead code", *whyLiveFlag)
+			continue
+		}
+		if len(analyses) > 1 {
+			log.Printf("%s is live in configuration %s", *whyLiveFlag, a.config)
 		}
 
+		res := a.res
 		res.CallGraph.DeleteSyntheticNodes() // inline synthetic wrappers (except inits)
-		root, path := pathSearch(roots, res, targets)
+		root, path := pathSearch(a.roots, res, targets)
 		if root == nil {
 			// RTA doesn't add callgraph edges for reflective calls.
 			log.Fatalf("%s is reachable only through reflection", *whyLiveFlag)
@@ -258,7 +475,7 @@ func main() {
 			edges = append(edges, jsonEdge{
 				Initial:  cond(len(edges) == 0, prettyName(edge.Caller.Func, true), ""),
 				Kind:     cond(isStaticCall(edge), "static", "dynamic"),
-				Position: toJSONPosition(prog.Fset.Position(edge.Pos())),
+				Position: toJSONPosition(a.prog.Fset.Position(edge.Pos())),
 				Callee:   prettyName(edge.Callee.Func, true),
 			})
 		}
@@ -270,82 +487,36 @@ func main() {
 		return
 	}
 
-	// Group unreachable functions by package path.
-	byPkgPath := make(map[string]map[*ssa.Function]bool)
-	for _, fn := range sourceFuncs {
-		posn := prog.Fset.Position(fn.Pos())
-
-		if !reachablePosn[posn] {
-			reachablePosn[posn] = true // suppress dups with same pos
-
-			pkgpath := fn.Pkg.Pkg.Path()
-			m, ok := byPkgPath[pkgpath]
-			if !ok {
-				m = make(map[*ssa.Function]bool)
-				byPkgPath[pkgpath] = m
-			}
-			m[fn] = true
-		}
-	}
-
-	// Build array of jsonPackage objects.
-	var packages []any
-	pkgpaths := keys(byPkgPath)
-	sort.Strings(pkgpaths)
-	for _, pkgpath := range pkgpaths {
-		if !filter.MatchString(pkgpath) {
-			continue
-		}
-
-		m := byPkgPath[pkgpath]
-
-		// Print functions that appear within the same file in
-		// declaration order. This tends to keep related
-		// methods such as (T).Marshal and (*T).Unmarshal
-		// together better than sorting.
-		fns := keys(m)
-		sort.Slice(fns, func(i, j int) bool {
-			xposn := prog.Fset.Position(fns[i].Pos())
-			yposn := prog.Fset.Position(fns[j].Pos())
-			if xposn.Filename != yposn.Filename {
-				return xposn.Filename < yposn.Filename
-			}
-			return xposn.Line < yposn.Line
-		})
-
-		var functions []jsonFunction
-		for _, fn := range fns {
-			posn := prog.Fset.Position(fn.Pos())
-
-			// Without -generated, skip functions declared in
-			// generated Go files.
-			// (Functions called by them may still be reported.)
-			gen := generated[posn.Filename]
-			if gen && !*generatedFlag {
-				continue
-			}
-
-			functions = append(functions, jsonFunction{
-				Name:      prettyName(fn, false),
-				Position:  toJSONPosition(posn),
-				Generated: gen,
-			})
-		}
-		if len(functions) > 0 {
-			packages = append(packages, jsonPackage{
-				Name:  fns[0].Pkg.Pkg.Name(),
-				Path:  pkgpath,
-				Funcs: functions,
-			})
-		}
+	if !found {
+		// Function is not part of the program.
+		//
+		// TODO(adonovan): improve the UX here in case
+		// of spelling or syntax mistakes. Some ideas:
+		// - a cmd/callgraph command to enumerate
+		//   available functions.
+		// - a deadcode -live flag to compute the complement.
+		// - a syntax hint: example.com/pkg.Func or (example.com/pkg.Type).Method
+		// - report the element of AllFunctions with the smallest
+		//   Levenshtein distance from *whyLiveFlag.
+		// - permit -whylive=regexp. But beware of spurious
+		//   matches (e.g. fmt.Print matches fmt.Println)
+		//   and the annoyance of having to quote parens (*T).f.
+		log.Fatalf("function %q not found in program", *whyLiveFlag)
 	}
+	log.Fatalf("function %s is dead code", *whyLiveFlag)
+}
 
-	// Default line-oriented format: "a/b/c.go:1:2: unreachable func: T.f"
-	format := `{{range .Funcs}}{{printf "%s: unreachable func: %s\n" .Position .Name}}{{end}}`
-	if *formatFlag != "" {
-		format = *formatFlag
+// extent returns the number of lines and bytes spanned by a function
+// declaration, including its doc comment: the amount of source that
+// would be removed by deleting it.
+func extent(fset *token.FileSet, decl *ast.FuncDecl) (lines, bytes int) {
+	start := decl.Pos()
+	if decl.Doc != nil {
+		start = decl.Doc.Pos()
 	}
-	printObjects(format, packages)
+	startPosn := fset.Position(start)
+	endPosn := fset.Position(decl.End())
+	return endPosn.Line - startPosn.Line + 1, endPosn.Offset - startPosn.Offset
 }
 
 // prettyName is a fork of Function.String designed to reduce
@@ -573,9 +744,12 @@ func cond[T any](cond bool, t, f T) T {
 // Keep in sync with doc comment!
 
 type jsonFunction struct {
-	Name      string       // name (sans package qualifier)
-	Position  jsonPosition // file/line/column of declaration
-	Generated bool         // function is declared in a generated .go file
+	Name       string       // name (sans package qualifier)
+	Position   jsonPosition // file/line/column of declaration
+	Generated  bool         // function is declared in a generated .go file
+	Confidence string       `json:",omitempty"` // = high | low (-precise only)
+	Lines      int          // number of lines in declaration, including doc comment
+	Bytes      int          // number of bytes in declaration, including doc comment
 }
 
 func (f jsonFunction) String() string { return f.Name }
@@ -584,6 +758,8 @@ type jsonPackage struct {
 	Name  string         // declared name
 	Path  string         // full import path
 	Funcs []jsonFunction // non-empty list of package's dead functions
+	Lines int            // total number of lines in Funcs
+	Bytes int            // total number of bytes in Funcs
 }
 
 func (p jsonPackage) String() string { return p.Path }
@@ -39,6 +39,17 @@ will fail to recognize that calls to a linkname-annotated function
 with no body in fact dispatch to the function named in the annotation.
 This may result in the latter function being spuriously reported as dead.
 
+The -precise flag causes the tool to also report functions that RTA
+deems reachable only through dynamic calls that the more precise
+Variable Type Analysis (VTA) shows to be infeasible. Like RTA, it
+assumes that the exported methods of any type whose values may be
+inspected by reflection, for example when formatted by the fmt
+package, may be called by reflection, so such methods are not reported.
+Functions that VTA alone deems dead are reported with low confidence,
+since the analysis of dynamic calls is still approximate; functions
+that are dead even by the conservative RTA are reported with high
+confidence.
+
 By default, the tool does not report dead functions in generated files,
 as determined by the special comment described in
 https://go.dev/s/generatedcode. Use the -generated flag to include them.
@@ -49,11 +60,26 @@ may be referenced by another dead function, and a dead method may be
 required to satisfy an interface that is never called.
 Some judgement is required.
 
-The analysis is valid only for a single GOOS/GOARCH/-tags configuration,
+# Build configurations
+
+A single analysis is valid only for one GOOS/GOARCH/-tags configuration,
 so a function reported as dead may be live in a different configuration.
-Consider running the tool once for each configuration of interest.
-Consider using a line-oriented output format (see below) to make it
-easier to compute the intersection of results across all runs.
+The -goos and -goarch flags, each a comma-separated list, and the
+-tagsets flag, a semicolon-separated list of comma-separated build tag
+lists, cause the tool to analyze the program in every combination
+of them, skipping GOOS/GOARCH pairs not supported by the go command.
+The tags of each set are added to those of the -tags flag; an empty
+set selects only the -tags flag. A function is then reported as dead
+only if it is dead in every configuration, including those in which
+it is not compiled at all.
+
+Example: show dead code in a program for three platforms, each with
+and without the netgo tag:
+
+	$ deadcode -goos=linux,darwin,windows -goarch=amd64 -tagsets=';netgo' ./cmd/...
+
+When used with -whylive, the path is shown for the first configuration
+in which the function is live.
 
 # Output
 
@@ -82,6 +108,18 @@ by package:
 		Parsed.WriteNode
 		wrNode.writeNode
 
+# How much code is dead?
+
+Each dead function and package reports the number of lines and bytes
+of source that deleting it would remove, counting a function's
+declaration and its doc comment. The -summary flag uses them to print,
+for each package, the number of dead functions and their total lines
+and bytes, largest package first, to help prioritize cleanup:
+
+	$ deadcode -summary -test ./...
+	example.com/internal/legacy: 12 funcs, 240 lines, 7512 bytes
+	example.com/cmd/tool: 2 funcs, 14 lines, 318 bytes
+
 # Why is a function not dead?
 
 The -whylive=function flag explain why the named function is not dead
@@ -115,12 +153,17 @@ is static or dynamic, and its source line number. For example:
 		Name  string       // declared name
 		Path  string       // full import path
 		Funcs []Function   // list of dead functions within it
+		Lines int          // total lines of Funcs
+		Bytes int          // total bytes of Funcs
 	}
 
 	type Function struct {
-		Name      string   // name (sans package qualifier)
-		Position  Position // file/line/column of function declaration
-		Generated bool     // function is declared in a generated .go file
+		Name       string   // name (sans package qualifier)
+		Position   Position // file/line/column of function declaration
+		Generated  bool     // function is declared in a generated .go file
+		Confidence string   // = high | low (with -precise only)
+		Lines      int      // lines of declaration, including doc comment
+		Bytes      int      // bytes of declaration, including doc comment
 	}
 
 	type Edge struct {
@@ -12,12 +12,15 @@ package main // import "golang.org/x/tools/cmd/digraph"
 import (
 	"bufio"
 	"bytes"
+	"container/heap"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -38,6 +41,11 @@ func usage() {
 //go:embed doc.go
 var doc string
 
+var (
+	weighted  = flag.Bool("weighted", false, "read edge weights, which follow each successor, from the input")
+	jsonInput = flag.Bool("json", false, "read the input as a JSON adjacency list")
+)
+
 func main() {
 	flag.Usage = usage
 	flag.Parse()
@@ -272,6 +280,160 @@ func (g graph) somepath(from, to string) error {
 	return fmt.Errorf("no path from %q to %q", from, to)
 }
 
+// shortestpath returns the nodes of a path from one node to another
+// whose edges have the least total weight, or nil if there is none.
+func (g graph) shortestpath(w weights, from, to string) []string {
+	// Dijkstra's algorithm.
+	dist := map[string]float64{from: 0}
+	prev := make(map[string]string)
+	done := make(nodeset)
+	q := &distQueue{{from, 0}}
+	for q.Len() > 0 {
+		item := heap.Pop(q).(nodeDist)
+		if done[item.node] {
+			continue // stale entry
+		}
+		done[item.node] = true
+		if item.node == to {
+			var path []string
+			for node := to; node != from; node = prev[node] {
+				path = append(path, node)
+			}
+			path = append(path, from)
+			slices.Reverse(path)
+			return path
+		}
+		// Visit the successors in order, so that the result is deterministic.
+		for _, succ := range g[item.node].sort() {
+			d := item.dist + w.of(item.node, succ)
+			if old, ok := dist[succ]; !done[succ] && (!ok || d < old) {
+				dist[succ] = d
+				prev[succ] = item.node
+				heap.Push(q, nodeDist{succ, d})
+			}
+		}
+	}
+	return nil
+}
+
+// A nodeDist is a node and its distance from the origin of a search.
+type nodeDist struct {
+	node string
+	dist float64
+}
+
+// A distQueue is a priority queue of nodes, nearest first.
+type distQueue []nodeDist
+
+func (q distQueue) Len() int { return len(q) }
+func (q distQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].node < q[j].node
+}
+func (q distQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *distQueue) Push(x any)   { *q = append(*q, x.(nodeDist)) }
+func (q *distQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// condense returns the condensation of the graph, in which each strongly
+// connected component is replaced by a single node, named by the members of
+// the component separated by spaces. It returns a list of lines, in sorted
+// order, each holding a node of the condensation followed by its successors.
+func (g graph) condense() []nodelist {
+	component := make(map[string]string) // maps each node to the name of its component
+	for node := range g {
+		component[node] = node
+	}
+	for _, scc := range g.sccs() {
+		name := strings.Join(scc.sort(), " ")
+		for node := range scc {
+			component[node] = name
+		}
+	}
+	c := make(graph)
+	for node, succs := range g {
+		from := component[node]
+		c.addNode(from)
+		for succ := range succs {
+			if to := component[succ]; to != from {
+				c.addEdges(from, to)
+			}
+		}
+	}
+	var lines []nodelist
+	for _, node := range c.nodelist() {
+		lines = append(lines, append(nodelist{node}, c[node].sort()...))
+	}
+	return lines
+}
+
+// dominators returns the immediate dominator of each node reachable from
+// the root, which is its own immediate dominator.
+func (g graph) dominators(root string) map[string]string {
+	// The algorithm is that of Cooper, Harvey, and Kennedy,
+	// "A Simple, Fast Dominance Algorithm" (2001).
+
+	// Number the reachable nodes in postorder.
+	var postorder nodelist
+	index := make(map[string]int)
+	seen := make(nodeset)
+	var visit func(node string)
+	visit = func(node string) {
+		seen[node] = true
+		for _, succ := range g[node].sort() {
+			if !seen[succ] {
+				visit(succ)
+			}
+		}
+		index[node] = len(postorder)
+		postorder = append(postorder, node)
+	}
+	visit(root)
+
+	preds := g.transpose()
+	idom := map[string]string{root: root}
+	intersect := func(a, b string) string {
+		for a != b {
+			for index[a] < index[b] {
+				a = idom[a]
+			}
+			for index[b] < index[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		// Visit the nodes in reverse postorder, skipping the root.
+		for i := len(postorder) - 2; i >= 0; i-- {
+			node := postorder[i]
+			newIdom := ""
+			for _, pred := range preds[node].sort() {
+				if _, ok := idom[pred]; !ok {
+					continue // not yet processed, or unreachable
+				}
+				if newIdom == "" {
+					newIdom = pred
+				} else {
+					newIdom = intersect(pred, newIdom)
+				}
+			}
+			if idom[node] != newIdom {
+				idom[node] = newIdom
+				changed = true
+			}
+		}
+	}
+	return idom
+}
+
 func (g graph) toDot(w *bytes.Buffer) {
 	fmt.Fprintln(w, "digraph {")
 	for _, src := range g.nodelist() {
@@ -286,8 +448,70 @@ func (g graph) toDot(w *bytes.Buffer) {
 	fmt.Fprintln(w, "}")
 }
 
-func parse(rd io.Reader) (graph, error) {
+// An edge is a directed edge of a graph.
+type edge struct{ from, to string }
+
+// weights records the weights of the edges of a weighted graph.
+// An edge without a recorded weight has weight 1.
+type weights map[edge]float64
+
+func (w weights) of(from, to string) float64 {
+	if x, ok := w[edge{from, to}]; ok {
+		return x
+	}
+	return 1
+}
+
+// parseWeight parses the weight of an edge, which must be non-negative.
+func parseWeight(s string) (float64, error) {
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil || x < 0 || x != x {
+		return 0, fmt.Errorf("invalid weight %q", s)
+	}
+	return x, nil
+}
+
+// readGraph reads the graph, and the weights of its edges if any,
+// in the format specified by the -json and -weighted flags.
+func readGraph(rd io.Reader) (graph, weights, error) {
+	if *jsonInput {
+		return parseJSON(rd)
+	}
+	return parse(rd, *weighted)
+}
+
+// toJSON writes the graph as a JSON adjacency list (see parseJSON),
+// including the weights of its edges if it has any.
+func (g graph) toJSON(w weights, out *bytes.Buffer) error {
+	var adj any
+	if len(w) > 0 {
+		m := make(map[string]map[string]float64)
+		for from, succs := range g {
+			m[from] = make(map[string]float64)
+			for to := range succs {
+				m[from][to] = w.of(from, to)
+			}
+		}
+		adj = m
+	} else {
+		m := make(map[string]nodelist)
+		for from, succs := range g {
+			m[from] = append(nodelist{}, succs.sort()...)
+		}
+		adj = m
+	}
+	data, err := json.MarshalIndent(adj, "", "\t")
+	if err != nil {
+		return err
+	}
+	out.Write(data)
+	out.WriteByte('\n')
+	return nil
+}
+
+func parse(rd io.Reader, weighted bool) (graph, weights, error) {
 	g := make(graph)
+	w := make(weights)
 
 	var linenum int
 	// We avoid bufio.Scanner as it imposes a (configurable) limit
@@ -300,21 +524,69 @@ func parse(rd io.Reader) (graph, error) {
 		if err == io.EOF {
 			eof = true
 		} else if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		// Split into words, honoring double-quotes per Go spec.
 		words, err := split(line)
 		if err != nil {
-			return nil, fmt.Errorf("at line %d: %v", linenum, err)
-		}
-		if len(words) > 0 {
+			return nil, nil, fmt.Errorf("at line %d: %v", linenum, err)
+		}
+		if weighted && len(words) > 0 {
+			// Each successor is followed by the weight of the edge to it.
+			from := words[0]
+			g.addNode(from)
+			for i := 1; i < len(words); i += 2 {
+				if i+1 == len(words) {
+					return nil, nil, fmt.Errorf("at line %d: no weight for edge to %q", linenum, words[i])
+				}
+				x, err := parseWeight(words[i+1])
+				if err != nil {
+					return nil, nil, fmt.Errorf("at line %d: %v", linenum, err)
+				}
+				g.addEdges(from, words[i])
+				w[edge{from, words[i]}] = x
+			}
+		} else if len(words) > 0 {
 			g.addEdges(words[0], words[1:]...)
 		}
 		if eof {
 			break
 		}
 	}
-	return g, nil
+	return g, w, nil
+}
+
+// parseJSON parses a graph in the form of a JSON adjacency list: an
+// object whose keys are the nodes, and whose values list the successors
+// of each node, either as an array of nodes or, for a weighted graph,
+// as an object mapping each successor to the weight of the edge to it.
+func parseJSON(rd io.Reader) (graph, weights, error) {
+	var adj map[string]json.RawMessage
+	if err := json.NewDecoder(rd).Decode(&adj); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON input: %v", err)
+	}
+	g := make(graph)
+	w := make(weights)
+	for from, succs := range adj {
+		g.addNode(from)
+		var list []string
+		if err := json.Unmarshal(succs, &list); err == nil {
+			g.addEdges(from, list...)
+			continue
+		}
+		var m map[string]float64
+		if err := json.Unmarshal(succs, &m); err != nil {
+			return nil, nil, fmt.Errorf("invalid successors of node %q: %s", from, succs)
+		}
+		for to, x := range m {
+			if x < 0 {
+				return nil, nil, fmt.Errorf("invalid weight %v of edge %q -> %q", x, from, to)
+			}
+			g.addEdges(from, to)
+			w[edge{from, to}] = x
+		}
+	}
+	return g, w, nil
 }
 
 // Overridable for redirection.
@@ -323,7 +595,7 @@ var stdout io.Writer = os.Stdout
 
 func digraph(cmd string, args []string) error {
 	// Parse the input graph.
-	g, err := parse(stdin)
+	g, w, err := readGraph(stdin)
 	if err != nil {
 		return err
 	}
@@ -489,12 +761,72 @@ func digraph(cmd string, args []string) error {
 		sort.Strings(edgesSorted)
 		fmt.Fprintln(stdout, strings.Join(edgesSorted, "\n"))
 
+	case "shortestpath":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: digraph shortestpath <from> <to>")
+		}
+		from, to := args[0], args[1]
+		if g[from] == nil {
+			return fmt.Errorf("no such 'from' node %q", from)
+		}
+		if g[to] == nil {
+			return fmt.Errorf("no such 'to' node %q", to)
+		}
+		path := g.shortestpath(w, from, to)
+		if path == nil {
+			return fmt.Errorf("no path from %q to %q", from, to)
+		}
+		for i := 1; i < len(path); i++ {
+			if len(w) > 0 {
+				weight := strconv.FormatFloat(w.of(path[i-1], path[i]), 'g', -1, 64)
+				fmt.Fprintln(stdout, path[i-1], path[i], weight)
+			} else {
+				fmt.Fprintln(stdout, path[i-1], path[i])
+			}
+		}
+
+	case "condense":
+		if len(args) != 0 {
+			return fmt.Errorf("usage: digraph condense")
+		}
+		for _, line := range g.condense() {
+			for i, node := range line {
+				line[i] = quoteWord(node)
+			}
+			line.println(" ")
+		}
+
+	case "dominators":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: digraph dominators <root>")
+		}
+		root := args[0]
+		if g[root] == nil {
+			return fmt.Errorf("no such node %q", root)
+		}
+		var edges []string
+		for node, idom := range g.dominators(root) {
+			if node != root {
+				edges = append(edges, idom+" "+node)
+			}
+		}
+		sort.Strings(edges)
+		for _, e := range edges {
+			fmt.Fprintln(stdout, e)
+		}
+
 	case "to":
-		if len(args) != 1 || args[0] != "dot" {
-			return fmt.Errorf("usage: digraph to dot")
+		if len(args) != 1 || (args[0] != "dot" && args[0] != "json") {
+			return fmt.Errorf("usage: digraph to dot|json")
 		}
 		var b bytes.Buffer
-		g.toDot(&b)
+		if args[0] == "json" {
+			if err := g.toJSON(w, &b); err != nil {
+				return err
+			}
+		} else {
+			g.toDot(&b)
+		}
 		stdout.Write(b.Bytes())
 
 	default:
@@ -551,6 +883,17 @@ func split(line string) ([]string, error) {
 	return words, nil
 }
 
+// quoteWord returns the word in the form accepted by split,
+// quoting it if necessary.
+func quoteWord(word string) string {
+	if word == "" || strings.ContainsFunc(word, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || !unicode.IsPrint(r)
+	}) {
+		return strconv.Quote(word)
+	}
+	return word
+}
+
 // quotedLength returns the length in bytes of the prefix of input that
 // contain a possibly-valid double-quoted Go string literal.
 //
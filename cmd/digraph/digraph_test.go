@@ -379,3 +379,123 @@ c "d\"\\d"`
 	}
 
 }
+
+func TestWeighted(t *testing.T) {
+	const g = `
+a b 1 c 5
+b c 1
+c d 1
+d b 1
+"e f" a 0.5
+`
+	const gjson = `{"a": {"b": 1, "c": 5}, "b": {"c": 1}, "c": {"d": 1}, "d": {"b": 1}, "e f": {"a": 0.5}}`
+
+	for _, test := range []struct {
+		name  string
+		json  bool
+		input string
+		cmd   string
+		args  []string
+		want  string
+	}{
+		{"shortestpath", false, g, "shortestpath", []string{"a", "d"}, "a b 1\nb c 1\nc d 1\n"},
+		{"shortestpath json", true, gjson, "shortestpath", []string{"e f", "c"}, "e f a 0.5\na b 1\nb c 1\n"},
+		{"condense", false, g, "condense", nil, "a \"b c d\"\n\"b c d\"\n\"e f\" a\n"},
+		{"dominators", false, g, "dominators", []string{"a"}, "a b\na c\nc d\n"},
+		{"nodes json", true, gjson, "nodes", nil, "a\nb\nc\nd\ne f\n"},
+		{"to json", false, g, "to", []string{"json"}, `{
+	"a": {
+		"b": 1,
+		"c": 5
+	},
+	"b": {
+		"c": 1
+	},
+	"c": {
+		"d": 1
+	},
+	"d": {
+		"b": 1
+	},
+	"e f": {
+		"a": 0.5
+	}
+}
+`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			defer func(in io.Reader, out io.Writer) { stdin, stdout = in, out }(stdin, stdout)
+			defer func(w, j bool) { *weighted, *jsonInput = w, j }(*weighted, *jsonInput)
+			*weighted, *jsonInput = !test.json, test.json
+			stdin = strings.NewReader(test.input)
+			stdout = new(bytes.Buffer)
+			if err := digraph(test.cmd, test.args); err != nil {
+				t.Fatal(err)
+			}
+			got := stdout.(fmt.Stringer).String()
+			if got != test.want {
+				t.Errorf("digraph(%s, %s) = got %q, want %q", test.cmd, test.args, got, test.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"a b", "a b -1", "a b x"} {
+		defer func(in io.Reader, out io.Writer) { stdin, stdout = in, out }(stdin, stdout)
+		defer func(w bool) { *weighted = w }(*weighted)
+		*weighted = true
+		stdin = strings.NewReader(input)
+		stdout = new(bytes.Buffer)
+		if err := digraph("nodes", nil); err == nil {
+			t.Errorf("digraph -weighted nodes succeeded on input %q, want error", input)
+		}
+	}
+}
+
+func TestUnweighted(t *testing.T) {
+	const g = `
+a b c
+b d
+c d
+d e
+`
+	for _, test := range []struct {
+		name string
+		cmd  string
+		args []string
+		want string
+	}{
+		{"shortestpath", "shortestpath", []string{"a", "e"}, "a b\nb d\nd e\n"},
+		{"dominators", "dominators", []string{"a"}, "a b\na c\na d\nd e\n"},
+		{"condense", "condense", nil, "a b c\nb d\nc d\nd e\ne\n"},
+		{"to json", "to", []string{"json"}, `{
+	"a": [
+		"b",
+		"c"
+	],
+	"b": [
+		"d"
+	],
+	"c": [
+		"d"
+	],
+	"d": [
+		"e"
+	],
+	"e": []
+}
+`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			defer func(in io.Reader, out io.Writer) { stdin, stdout = in, out }(stdin, stdout)
+			stdin = strings.NewReader(g)
+			stdout = new(bytes.Buffer)
+			if err := digraph(test.cmd, test.args); err != nil {
+				t.Fatal(err)
+			}
+			got := stdout.(fmt.Stringer).String()
+			if got != test.want {
+				t.Errorf("digraph(%s, %s) = got %q, want %q", test.cmd, test.args, got, test.want)
+			}
+		})
+	}
+}
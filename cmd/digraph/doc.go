@@ -9,7 +9,7 @@ typical UNIX command pipelines.
 
 Usage:
 
-	your-application | digraph [command]
+	your-application | digraph [flags] [command]
 
 The supported commands are:
 
@@ -31,14 +31,23 @@ The supported commands are:
 		the list of nodes on some arbitrary path from the first node to the second
 	allpaths <node> <node>
 		the set of nodes on all paths from the first node to the second
+	shortestpath <node> <node>
+		the list of edges on a path of least total weight from the first node to the second
 	sccs
 		all strongly connected components (one per line)
 	scc <node>
 		the set of nodes strongly connected to the specified one
+	condense
+		the graph in which each strongly connected component is a single node
+	dominators <node>
+		the dominator tree of the nodes reachable from the specified one,
+		as edges from each node's immediate dominator to the node
 	focus <node>
 		the subgraph containing all directed paths that pass through the specified node
 	to dot
 		print the graph in Graphviz dot format (other formats may be supported in the future)
+	to json
+		print the graph as a JSON adjacency list
 
 Input format:
 
@@ -63,6 +72,29 @@ subtasks of getting dressed:
 The line "shirt tie sweater" indicates the two edges shirt -> tie and
 shirt -> sweater, not shirt -> tie -> sweater.
 
+With the -weighted flag, each successor is followed by the weight of the
+edge to it, a non-negative number, so that the line "a b 2 c 0.5"
+indicates the edge a -> b of weight 2 and a -> c of weight 0.5. Edges
+of unweighted graphs have weight 1. Only the shortestpath and "to json"
+commands use the weights; shortestpath prints the weight after each edge
+of the path.
+
+With the -json flag, the input is a JSON object whose keys are the nodes,
+and whose values list the successors of each node, either as an array of
+nodes or, for a weighted graph, as an object mapping each successor to the
+weight of the edge to it:
+
+	{"a": ["b", "c"], "b": [], "c": []}
+	{"a": {"b": 2, "c": 0.5}, "b": {}, "c": {}}
+
+The "to json" command prints a graph in the same form.
+
+The condense command prints each strongly connected component of more than
+one node, or of a node with an edge to itself, as a single node whose name
+is formed from those of its members, separated by spaces. Its output, like
+that of the transpose, allpaths, shortestpath, and dominators commands, is
+a graph in the input format, so that queries may be applied to it in turn.
+
 Example usage:
 
 Show which clothes (see above) must be donned before a jacket:
@@ -8,6 +8,8 @@
 package main // import "golang.org/x/tools/cmd/eg"
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
@@ -18,10 +20,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/refactor/eg"
+	"golang.org/x/tools/refactor/plan"
 )
 
 var (
@@ -30,22 +36,44 @@ var (
 	templateFlag   = flag.String("t", "", "template.go file specifying the refactoring")
 	transitiveFlag = flag.Bool("transitive", false, "apply refactoring to all dependencies too")
 	writeFlag      = flag.Bool("w", false, "rewrite input files in place (by default, the results are printed to standard output)")
+	diffFlag       = flag.Bool("diff", false, "print a unified diff of each rewrite instead of the rewritten files")
+	jsonFlag       = flag.Bool("json", false, "print a JSON plan of the rewrites instead of the rewritten files")
+	recursiveFlag  = flag.Bool("r", false, "apply refactoring to the packages beneath each named directory")
+	parallelFlag   = flag.Int("p", runtime.GOMAXPROCS(0), "number of files to refactor in parallel")
 	verboseFlag    = flag.Bool("v", false, "show verbose matcher diagnostics")
 )
 
 const usage = `eg: an example-based refactoring tool.
 
-Usage: eg -t template.go [-w] [-transitive] <packages>
+Usage: eg -t template.go [-w] [-diff] [-json] [-r] [-transitive] <packages>
 
 -help            show detailed help message
 -t template.go	 specifies the template file (use -help to see explanation)
 -w          	 causes files to be re-written in place.
+-diff            causes a unified diff of each rewrite to be printed
+                 instead of the rewritten file.
+-json            causes a JSON plan of the rewrites, in the form of package
+                 golang.org/x/tools/refactor/plan, to be printed instead
+                 of the rewritten files, so that they may be reviewed and
+                 applied later. Files that cannot be rewritten are
+                 reported as conflicts.
+-r               causes the packages beneath each directory to be refactored,
+                 as if each argument were followed by "/...". With no
+                 arguments, it refactors the packages beneath the current
+                 directory, such as those of the enclosing module.
 -transitive 	 causes all dependencies to be refactored too.
+-p n             refactor n files in parallel (default GOMAXPROCS).
 -v               show verbose matcher diagnostics
 -beforeedit cmd  a command to exec before each file is modified.
                  "{}" represents the name of the file.
+
+With -diff, the exit status is 3 if any file would be changed,
+so that eg can check in a CI job that no code matches the template.
 `
 
+// exitRewrites is the exit status of eg -diff when a file would change.
+const exitRewrites = 3
+
 func main() {
 	if err := doMain(); err != nil {
 		fmt.Fprintf(os.Stderr, "eg: %s\n", err)
@@ -63,6 +91,22 @@ func doMain() error {
 		os.Exit(2)
 	}
 
+	if *recursiveFlag {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		for i, arg := range args {
+			// The arguments are directories, not import paths.
+			if !filepath.IsAbs(arg) && !strings.HasPrefix(arg, ".") {
+				arg = "./" + arg
+			}
+			if !strings.HasSuffix(arg, "...") {
+				arg = strings.TrimSuffix(arg, "/") + "/..."
+			}
+			args[i] = arg
+		}
+	}
+
 	if len(args) == 0 {
 		fmt.Fprint(os.Stderr, usage)
 		os.Exit(1)
@@ -106,17 +150,30 @@ func doMain() error {
 		Selections: make(map[*ast.SelectorExpr]*types.Selection),
 		Scopes:     make(map[ast.Node]*types.Scope),
 	}
+	var tErr error
 	conf := types.Config{
 		Importer: pkgsImporter(pkgs),
+		// Ignore soft errors such as unused variables,
+		// which may be declared by statement patterns.
+		Error: func(err error) {
+			if err, ok := err.(types.Error); ok && err.Soft {
+				return
+			}
+			if tErr == nil {
+				tErr = err
+			}
+		},
 	}
-	tPkg, err := conf.Check("egtemplate", cfg.Fset, []*ast.File{tFile}, &tInfo)
-	if err != nil {
-		return err
+	tPkg, _ := conf.Check("egtemplate", cfg.Fset, []*ast.File{tFile}, &tInfo)
+	if tErr != nil {
+		return tErr
 	}
 
 	// Analyze the template.
-	xform, err := eg.NewTransformer(cfg.Fset, tPkg, tFile, &tInfo, *verboseFlag)
-	if err != nil {
+	newTransformer := func() (*eg.Transformer, error) {
+		return eg.NewTransformer(cfg.Fset, tPkg, tFile, &tInfo, *verboseFlag)
+	}
+	if _, err := newTransformer(); err != nil {
 		return err
 	}
 
@@ -127,53 +184,157 @@ func doMain() error {
 	} else {
 		all = pkgs
 	}
-	var hadErrors bool
-	for _, pkg := range pkgs {
+
+	// Each file may belong to several packages (for example,
+	// a package and its test variant); refactor it only once.
+	var files []*fileJob
+	seen := make(map[string]bool)
+	for _, pkg := range all {
 		for i, filename := range pkg.CompiledGoFiles {
-			if filename == tAbs {
+			if filename == tAbs || seen[filename] {
 				// Don't rewrite the template file.
 				continue
 			}
-			file := pkg.Syntax[i]
-			n := xform.Transform(pkg.TypesInfo, pkg.Types, file)
-			if n == 0 {
-				continue
+			seen[filename] = true
+			files = append(files, &fileJob{pkg: pkg, filename: filename, file: pkg.Syntax[i]})
+		}
+	}
+
+	// Transform the files in parallel, each worker using its
+	// own Transformer, as a Transformer is not concurrency-safe.
+	jobs := make(chan *fileJob)
+	var wg sync.WaitGroup
+	for i := 0; i < max(*parallelFlag, 1); i++ {
+		xform, err := newTransformer()
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.transform(cfg.Fset, xform)
 			}
-			fmt.Fprintf(os.Stderr, "=== %s (%d matches)\n", filename, n)
-			if *writeFlag {
-				// Run the before-edit command (e.g. "chmod +w",  "checkout") if any.
-				if *beforeeditFlag != "" {
-					args := strings.Fields(*beforeeditFlag)
-					// Replace "{}" with the filename, like find(1).
-					for i := range args {
-						if i > 0 {
-							args[i] = strings.Replace(args[i], "{}", filename, -1)
-						}
-					}
-					cmd := exec.Command(args[0], args[1:]...)
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-					if err := cmd.Run(); err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: edit hook %q failed (%s)\n",
-							args, err)
+		}()
+	}
+	for _, job := range files {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Report the results in order.
+	if *jsonFlag {
+		return printPlan(files)
+	}
+	var hadErrors, changed bool
+	for _, job := range files {
+		if job.n == 0 {
+			continue
+		}
+		filename := job.filename
+		fmt.Fprintf(os.Stderr, "=== %s (%d matches)\n", filename, job.n)
+		if job.err != nil {
+			fmt.Fprintf(os.Stderr, "eg: %s\n", job.err)
+			hadErrors = true
+			continue
+		}
+		if !*writeFlag && !*diffFlag {
+			os.Stdout.Write(job.after)
+			continue
+		}
+		if bytes.Equal(job.before, job.after) {
+			continue
+		}
+		changed = true
+		if *diffFlag {
+			fmt.Print(diff.Unified(filename+".orig", filename, string(job.before), string(job.after)))
+		}
+		if *writeFlag {
+			// Run the before-edit command (e.g. "chmod +w",  "checkout") if any.
+			if *beforeeditFlag != "" {
+				args := strings.Fields(*beforeeditFlag)
+				// Replace "{}" with the filename, like find(1).
+				for i := range args {
+					if i > 0 {
+						args[i] = strings.Replace(args[i], "{}", filename, -1)
 					}
 				}
-				if err := eg.WriteAST(cfg.Fset, filename, file); err != nil {
-					fmt.Fprintf(os.Stderr, "eg: %s\n", err)
-					hadErrors = true
+				cmd := exec.Command(args[0], args[1:]...)
+				cmd.Stdout = os.Stdout
+				cmd.Stderr = os.Stderr
+				if err := cmd.Run(); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: edit hook %q failed (%s)\n",
+						args, err)
 				}
-			} else {
-				format.Node(os.Stdout, cfg.Fset, file)
+			}
+			if err := os.WriteFile(filename, job.after, 0666); err != nil {
+				fmt.Fprintf(os.Stderr, "eg: %s\n", err)
+				hadErrors = true
 			}
 		}
 	}
 	if hadErrors {
 		os.Exit(1)
 	}
+	if *diffFlag && changed {
+		os.Exit(exitRewrites)
+	}
 
 	return nil
 }
 
+// printPlan prints the JSON plan of the results.
+func printPlan(files []*fileJob) error {
+	p := &plan.Plan{Tool: "eg"}
+	for _, job := range files {
+		if job.n == 0 {
+			continue
+		}
+		if job.err != nil {
+			p.Conflicts = append(p.Conflicts, plan.Note{Pos: job.filename, Message: job.err.Error()})
+			continue
+		}
+		p.AddFile(job.filename, job.before, job.after)
+	}
+	if len(p.Files) > 0 {
+		p.Notes = append(p.Notes, plan.Note{Message: "eg performs only superficial checks of type preservation; build the result before relying on it"})
+	}
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(data, '\n'))
+	return err
+}
+
+// A fileJob is a file to be refactored, and the result.
+type fileJob struct {
+	pkg      *packages.Package
+	filename string
+	file     *ast.File
+
+	n             int    // number of matches
+	before, after []byte // file contents, if n > 0
+	err           error
+}
+
+// transform applies the refactoring to the file, recording the result.
+func (job *fileJob) transform(fset *token.FileSet, xform *eg.Transformer) {
+	job.n = xform.Transform(job.pkg.TypesInfo, job.pkg.Types, job.file)
+	if job.n == 0 {
+		return
+	}
+	job.before, job.err = os.ReadFile(job.filename)
+	if job.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if job.err = format.Node(&buf, fset, job.file); job.err == nil {
+		job.after = buf.Bytes()
+	}
+}
+
 type pkgsImporter []*packages.Package
 
 func (p pkgsImporter) Import(path string) (tpkg *types.Package, err error) {
@@ -0,0 +1,146 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestMain runs eg, instead of the tests, in the child
+// processes started by the runEg function.
+func TestMain(m *testing.M) {
+	if os.Getenv("EG_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runEg runs eg in dir with the specified arguments, and returns its
+// standard output and error, and its exit status.
+func runEg(t *testing.T, dir string, args ...string) (stdout, stderr string, status int) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "EG_TEST_MAIN=1", "GOWORK=off", "GOFLAGS=-mod=mod")
+	var outBuf, errBuf strings.Builder
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatal(err)
+		}
+		status = exitErr.ExitCode()
+	}
+	return outBuf.String(), errBuf.String(), status
+}
+
+const template = `package template
+
+import (
+	"errors"
+	"fmt"
+)
+
+func before(s string) error { return fmt.Errorf("%s", s) }
+func after(s string) error  { return errors.New(s) }
+`
+
+// writeModule writes a module containing the template and the
+// packages p and p/q, each with several files, and returns its
+// directory.
+func writeModule(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module example.com\n\ngo 1.21\n",
+		"template.go": "//go:build ignore\n\n" + template,
+		"p/p_test.go": "package p\n\nimport \"testing\"\n\nfunc TestP(t *testing.T) {}\n",
+		"p/none.go":   "package p\n\nfunc none() {}\n",
+	}
+	for _, name := range []string{"p/a", "p/b", "p/c", "p/d", "p/q/q"} {
+		files[name+".go"] = fmt.Sprintf("package %s\n\nimport \"fmt\"\n\nfunc %s() error { return fmt.Errorf(\"%%s\", %q) }\n",
+			path.Base(path.Dir(name)), path.Base(name), path.Base(name))
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiff(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := writeModule(t)
+	stdout, stderr, status := runEg(t, dir, "-t", "template.go", "-diff", "-p", "3", "-r", "p")
+	if status != exitRewrites {
+		t.Fatalf("eg -diff exited with status %d, want %d\n%s", status, exitRewrites, stderr)
+	}
+	// Each file is reported once, despite the test variant of p,
+	// and in order, despite the parallel transformation.
+	var reported []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if name, ok := strings.CutPrefix(line, "=== "); ok {
+			rel, err := filepath.Rel(dir, strings.Fields(name)[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			reported = append(reported, filepath.ToSlash(rel))
+		}
+	}
+	if got, want := strings.Join(reported, " "), "p/a.go p/b.go p/c.go p/d.go p/q/q.go"; got != want {
+		t.Errorf("eg reported files %s, want %s\n%s", got, want, stderr)
+	}
+	for _, name := range []string{"a", "q"} {
+		want := fmt.Sprintf("+func %s() error { return errors.New(%q) }", name, name)
+		if !strings.Contains(stdout, want) {
+			t.Errorf("diff does not contain\n%s\n\ndiff:\n%s", want, stdout)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "p", "a.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "errors.New") {
+		t.Errorf("eg -diff rewrote p/a.go")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := writeModule(t)
+	if _, stderr, status := runEg(t, dir, "-t", "template.go", "-w", "./p"); status != 0 {
+		t.Fatalf("eg -w exited with status %d\n%s", status, stderr)
+	}
+	for name, rewritten := range map[string]bool{"a": true, "d": true, "q/q": false} {
+		data, err := os.ReadFile(filepath.Join(dir, "p", filepath.FromSlash(name)+".go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(data), "errors.New"); got != rewritten {
+			t.Errorf("p/%s.go rewritten = %t, want %t:\n%s", name, got, rewritten, data)
+		}
+	}
+
+}
@@ -3,31 +3,44 @@
 // license that can be found in the LICENSE file.
 
 // file2fuzz converts binary files, such as those used by go-fuzz, to the Go
-// fuzzing corpus format.
+// fuzzing corpus format, and back.
 //
 // Usage:
 //
-//	file2fuzz [-o output] [input...]
+//	file2fuzz [-o output] [-reverse] [input...]
 //
 // The default behavior is to read input from stdin and write the converted
 // output to stdout. If any position arguments are provided stdin is ignored
-// and the arguments are assumed to be input files to convert.
+// and the arguments are assumed to be input files to convert. An argument
+// that is a directory denotes all the files in the tree rooted there.
 //
 // The -o flag provides an path to write output files to. If only one positional
 // argument is specified it may be a file path or an existing directory, if there are
 // multiple inputs specified it must be a directory. If a directory is provided
-// the name of the file will be the SHA-256 hash of its contents.
+// the name of the file will be the SHA-256 hash of its contents, so inputs
+// with the same contents yield a single file, and a file already present
+// in the directory is not written again.
+//
+// The -reverse flag converts files in the Go fuzzing corpus format back to
+// binary files, such as those used by other fuzzers. Each file must hold a
+// single value of type []byte or string.
 package main
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"errors"
 	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 // encVersion1 is version 1 Go fuzzer corpus encoding.
@@ -37,10 +50,71 @@ func encodeByteSlice(b []byte) []byte {
 	return []byte(fmt.Sprintf("%s\n[]byte(%q)", encVersion1, b))
 }
 
+// decodeByteSlice returns the value of a file in the Go fuzzer corpus
+// format that holds a single []byte or string value.
+func decodeByteSlice(b []byte) ([]byte, error) {
+	header, rest, _ := bytes.Cut(b, []byte("\n"))
+	if string(bytes.TrimSpace(header)) != encVersion1 {
+		return nil, fmt.Errorf("not a Go fuzzer corpus file: missing %q header", encVersion1)
+	}
+	var values []string
+	for _, line := range bytes.Split(rest, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if len(values) > 0 {
+			return nil, fmt.Errorf("more than one value; only files holding a single []byte or string can be converted")
+		}
+		v, err := parseBytes(string(line))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no value")
+	}
+	return []byte(values[0]), nil
+}
+
+// parseBytes parses a []byte or string value in the Go fuzzer corpus format,
+// such as []byte("hello") or string("hello").
+func parseBytes(line string) (string, error) {
+	e, err := parser.ParseExpr(line)
+	if err != nil {
+		return "", fmt.Errorf("malformed value %q: %v", line, err)
+	}
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", fmt.Errorf("malformed value %q", line)
+	}
+	switch fun := call.Fun.(type) {
+	case *ast.ArrayType:
+		if elt, ok := fun.Elt.(*ast.Ident); ok && fun.Len == nil && (elt.Name == "byte" || elt.Name == "uint8") {
+			break
+		}
+		return "", fmt.Errorf("value %q is not of type []byte or string", line)
+	case *ast.Ident:
+		if fun.Name == "string" {
+			break
+		}
+		return "", fmt.Errorf("value %q is not of type []byte or string", line)
+	default:
+		return "", fmt.Errorf("malformed value %q", line)
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", fmt.Errorf("malformed value %q", line)
+	}
+	return strconv.Unquote(lit.Value)
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: file2fuzz [-o output] [input...]\nconverts files to Go fuzzer corpus format\n")
-	fmt.Fprintf(os.Stderr, "\tinput: files to convert\n")
+	fmt.Fprintf(os.Stderr, "usage: file2fuzz [-o output] [-reverse] [input...]\nconverts files to Go fuzzer corpus format\n")
+	fmt.Fprintf(os.Stderr, "\tinput: files or directories to convert\n")
 	fmt.Fprintf(os.Stderr, "\t-o: where to write converted file(s)\n")
+	fmt.Fprintf(os.Stderr, "\t-reverse: convert Go fuzzer corpus files back to binary files\n")
 	os.Exit(2)
 }
 func dirWriter(dir string) func([]byte) error {
@@ -50,6 +124,9 @@ func dirWriter(dir string) func([]byte) error {
 		if err := os.MkdirAll(dir, 0777); err != nil {
 			return err
 		}
+		if _, err := os.Stat(name); err == nil {
+			return nil // already present
+		}
 		if err := os.WriteFile(name, b, 0666); err != nil {
 			os.Remove(name)
 			return err
@@ -58,37 +135,58 @@ func dirWriter(dir string) func([]byte) error {
 	}
 }
 
-func convert(inputArgs []string, outputArg string) error {
-	var input []io.Reader
-	if args := inputArgs; len(args) == 0 {
-		input = []io.Reader{os.Stdin}
-	} else {
-		for _, a := range args {
-			f, err := os.Open(a)
+// inputFiles returns the names of the files denoted by the arguments,
+// and whether any of them is a directory.
+func inputFiles(args []string) ([]string, bool, error) {
+	var files []string
+	sawDir := false
+	for _, a := range args {
+		fi, err := os.Stat(a)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to open %q: %s", a, err)
+		}
+		if !fi.IsDir() {
+			files = append(files, a)
+			continue
+		}
+		sawDir = true
+		err = filepath.WalkDir(a, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
-				return fmt.Errorf("unable to open %q: %s", a, err)
+				return err
 			}
-			defer f.Close()
-			if fi, err := f.Stat(); err != nil {
-				return fmt.Errorf("unable to open %q: %s", a, err)
-			} else if fi.IsDir() {
-				return fmt.Errorf("%q is a directory, not a file", a)
+			if d.Type().IsRegular() {
+				files = append(files, path)
 			}
-			input = append(input, f)
+			return nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to read directory %q: %s", a, err)
 		}
 	}
+	return files, sawDir, nil
+}
+
+func convert(inputArgs []string, outputArg string, reverse bool) error {
+	files, sawDir, err := inputFiles(inputArgs)
+	if err != nil {
+		return err
+	}
+	multiple := len(inputArgs) > 1 || sawDir
 
 	var output func([]byte) error
 	if outputArg == "" {
 		if len(inputArgs) > 1 {
 			return errors.New("-o required with multiple input files")
 		}
+		if sawDir {
+			return errors.New("-o required with an input directory")
+		}
 		output = func(b []byte) error {
 			_, err := os.Stdout.Write(b)
 			return err
 		}
 	} else {
-		if len(inputArgs) > 1 {
+		if multiple {
 			output = dirWriter(outputArg)
 		} else {
 			if fi, err := os.Stat(outputArg); err != nil && !os.IsNotExist(err) {
@@ -103,14 +201,44 @@ func convert(inputArgs []string, outputArg string) error {
 		}
 	}
 
-	for _, f := range input {
-		b, err := io.ReadAll(f)
+	encode := func(name string, b []byte) ([]byte, error) {
+		return encodeByteSlice(b), nil
+	}
+	if reverse {
+		encode = func(name string, b []byte) ([]byte, error) {
+			v, err := decodeByteSlice(b)
+			if err != nil {
+				return nil, fmt.Errorf("unable to convert %s: %v", name, err)
+			}
+			return v, nil
+		}
+	}
+	convertOne := func(name string, b []byte) error {
+		out, err := encode(name, b)
 		if err != nil {
-			return fmt.Errorf("unable to read input: %s", err)
+			return err
 		}
-		if err := output(encodeByteSlice(b)); err != nil {
+		if err := output(out); err != nil {
 			return fmt.Errorf("unable to write output: %s", err)
 		}
+		return nil
+	}
+
+	if len(inputArgs) == 0 {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("unable to read input: %s", err)
+		}
+		return convertOne("standard input", b)
+	}
+	for _, name := range files {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("unable to read input: %s", err)
+		}
+		if err := convertOne(name, b); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -121,10 +249,11 @@ func main() {
 	log.SetPrefix("file2fuzz: ")
 
 	output := flag.String("o", "", "where to write converted file(s)")
+	reverse := flag.Bool("reverse", false, "convert Go fuzzer corpus files back to binary files")
 	flag.Usage = usage
 	flag.Parse()
 
-	if err := convert(flag.Args(), *output); err != nil {
+	if err := convert(flag.Args(), *output, *reverse); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -66,6 +66,7 @@ func TestFile2Fuzz(t *testing.T) {
 		inputFiles     []file
 		expectedStdout string
 		expectedFiles  []file
+		expectedCount  map[string]int // number of files in each directory
 		expectedError  string
 	}{
 		{
@@ -113,6 +114,55 @@ func TestFile2Fuzz(t *testing.T) {
 			inputFiles:    []file{{name: "output", dir: true}, {name: "input", content: "hello"}, {name: "input-2", content: "hello :)"}},
 			expectedError: "file2fuzz: -o required with multiple input files\n",
 		},
+		{
+			name:       "input directory, output directory",
+			args:       []string{"-o", "output", "input"},
+			inputFiles: []file{{name: "input", dir: true}, {name: "input/a", content: "hello"}, {name: "input/sub", dir: true}, {name: "input/sub/b", content: "hello :)"}, {name: "input/sub/c", content: "hello"}},
+			expectedFiles: []file{
+				{name: "output/ffc7b87a0377262d4f77926bd235551d78e6037bbe970d81ec39ac1d95542f7b", content: "go test fuzz v1\n[]byte(\"hello\")"},
+				{name: "output/28059db30ce420ff65b2c29b749804c69c601aeca21b3cbf0644244ff080d7a5", content: "go test fuzz v1\n[]byte(\"hello :)\")"},
+			},
+			expectedCount: map[string]int{"output": 2},
+		},
+		{
+			name:          "input directory, no output",
+			args:          []string{"input"},
+			inputFiles:    []file{{name: "input", dir: true}, {name: "input/a", content: "hello"}},
+			expectedError: "file2fuzz: -o required with an input directory\n",
+		},
+		{
+			name:           "reverse, stdin, stdout",
+			args:           []string{"-reverse"},
+			stdin:          "go test fuzz v1\n[]byte(\"hello\\x00\")\n",
+			expectedStdout: "hello\x00",
+		},
+		{
+			name:           "reverse, string value",
+			args:           []string{"-reverse", "input"},
+			inputFiles:     []file{{name: "input", content: "go test fuzz v1\nstring(\"hello\")\n"}},
+			expectedStdout: "hello",
+		},
+		{
+			name:       "reverse, input directory, output directory",
+			args:       []string{"-reverse", "-o", "output", "input"},
+			inputFiles: []file{{name: "input", dir: true}, {name: "input/a", content: "go test fuzz v1\n[]byte(\"hello\")"}, {name: "input/b", content: "go test fuzz v1\nstring(\"hello\")"}},
+			expectedFiles: []file{
+				{name: "output/2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", content: "hello"},
+			},
+			expectedCount: map[string]int{"output": 1},
+		},
+		{
+			name:          "reverse, multiple values",
+			args:          []string{"-reverse", "input"},
+			inputFiles:    []file{{name: "input", content: "go test fuzz v1\n[]byte(\"a\")\nint(1)\n"}},
+			expectedError: "file2fuzz: unable to convert input: more than one value; only files holding a single []byte or string can be converted\n",
+		},
+		{
+			name:          "reverse, not a corpus file",
+			args:          []string{"-reverse", "input"},
+			inputFiles:    []file{{name: "input", content: "hello"}},
+			expectedError: "file2fuzz: unable to convert input: not a Go fuzzer corpus file: missing \"go test fuzz v1\" header\n",
+		},
 	}
 
 	for _, tc := range tests {
@@ -153,6 +203,15 @@ func TestFile2Fuzz(t *testing.T) {
 					t.Fatalf("expected output file %q contains unexpected content: got %s, want %s", f.name, string(c), f.content)
 				}
 			}
+			for dir, n := range tc.expectedCount {
+				entries, err := os.ReadDir(filepath.Join(tmp, dir))
+				if err != nil {
+					t.Fatalf("failed to read output directory %q: %s", dir, err)
+				}
+				if len(entries) != n {
+					t.Fatalf("output directory %q contains %d files, want %d", dir, len(entries), n)
+				}
+			}
 		})
 	}
 }
@@ -64,6 +64,32 @@
 //
 // To see the changes fiximports would make without applying them, use
 // the -n flag.
+//
+// # Major version migration
+//
+// When a module makes incompatible changes, its path gains a major
+// version suffix such as /v2, and every import of its packages, within
+// the module itself and in modules that depend on it, must change
+// accordingly. Given the -major flag, fiximports performs this
+// migration instead of canonicalizing imports:
+//
+//	$ cd $REPO && fiximports -major v2 [dir...]
+//
+// The module enclosing the first directory (by default, the current
+// directory) is migrated to the named major version. Fiximports updates
+// the module line of its go.mod file; the imports and import comments
+// of its packages in all Go files beneath the directories, except those
+// in testdata and vendor directories; and the requirements and
+// replacements of the module in all other go.mod files beneath them.
+// Packages of other modules nested within the migrated one are left
+// alone.
+//
+// Fiximports cannot know the version of the migrated module that
+// dependents should require until it is published, so it reports
+// updated requirements without a replacement for review. It also
+// reports, without changing them, other mentions of the old module
+// path in strings and //go:generate directives, which may refer to
+// packages or merely to the repository.
 package main
 
 import (
@@ -93,6 +119,8 @@ var (
 		"a comma-separated list of domains from which packages should not be imported")
 	replaceFlag = flag.String("replace", "",
 		"a comma-separated list of noncanonical=canonical pairs of package paths.  If both items in a pair end with '...', they are treated as path prefixes.")
+	majorFlag = flag.String("major", "",
+		"migrate the enclosing module to the given major `version`, such as v2")
 )
 
 // seams for testing
@@ -104,22 +132,35 @@ var (
 const usage = `fiximports: rewrite import paths to use canonical package names.
 
 Usage: fiximports [-n] package...
+       fiximports [-n] -major version [dir...]
 
 The package... arguments specify a list of packages
 in the style of the go tool; see "go help packages".
 Hint: use "all" or "..." to match the entire workspace.
 
+With -major, fiximports migrates the module enclosing the first
+dir (by default, the current directory) to the given major version,
+rewriting imports of its packages beneath each dir.
+
 For details, see https://pkg.go.dev/golang.org/x/tools/cmd/fiximports
 
 Flags:
   -n:	       dry run: show changes, but don't apply them
   -baddomains  a comma-separated list of domains from which packages
                should not be imported
+  -major       the major version, such as v2, to which to migrate
+               the enclosing module
 `
 
 func main() {
 	flag.Parse()
 
+	if *majorFlag != "" {
+		if !migrateMajor(*majorFlag, flag.Args()...) {
+			os.Exit(1)
+		}
+		return
+	}
 	if len(flag.Args()) == 0 {
 		fmt.Fprint(stderr, usage)
 		os.Exit(1)
@@ -265,3 +265,93 @@ func TestDryRun(t *testing.T) {
 		t.Fatalf("fiximports failed: %s", stderr)
 	}
 }
+
+// TestMajor tests the -major flag.
+func TestMajor(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a/go.mod": "module example.com/a\n\ngo 1.21\n",
+		"a/a.go": `package a // import "example.com/a"
+
+import (
+	"example.com/a/nested/n"
+	"example.com/a/sub"
+)
+
+const pkg = "example.com/a/sub"
+
+var _, _ = n.Y, sub.X
+`,
+		"a/sub/sub.go":         "package sub\n\nvar X = 1\n",
+		"a/nested/go.mod":      "module example.com/a/nested\n\ngo 1.21\n",
+		"a/nested/n/n.go":      "package n\n\nvar Y = 1\n",
+		"a/testdata/t.go":      "package t\n\nimport _ \"example.com/a/sub\"\n",
+		"client/go.mod":        "module example.com/client\n\ngo 1.21\n\nrequire example.com/a v1.3.0\n\nreplace example.com/a => ../a\n",
+		"client/c.go":          "package client\n\nimport _ \"example.com/a\"\n",
+		"other/go.mod":         "module example.com/other\n\ngo 1.21\n\nrequire example.com/a v1.3.0\n",
+		"other/o.go":           "package other\n\nimport _ \"example.com/a/sub\"\n",
+		"other/o_unrelated.go": "package other\n\nimport _ \"example.com/abc\"\n",
+	} {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer func() { stderr = os.Stderr }()
+	stderr = new(bytes.Buffer)
+	gotRewrite := make(map[string]string)
+	writeFile = func(filename string, content []byte, mode os.FileMode) error {
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotRewrite[filepath.ToSlash(rel)] = string(content)
+		return nil
+	}
+
+	if !migrateMajor("v2", filepath.Join(dir, "a"), dir) {
+		t.Fatalf("migrateMajor failed: %s", stderr)
+	}
+
+	wantRewrite := map[string]string{
+		"a/go.mod": "module example.com/a/v2\n\ngo 1.21\n",
+		"a/a.go": `package a // import "example.com/a/v2"
+
+import (
+	"example.com/a/nested/n"
+	"example.com/a/v2/sub"
+)
+
+const pkg = "example.com/a/sub"
+
+var _, _ = n.Y, sub.X
+`,
+		"client/go.mod": "module example.com/client\n\ngo 1.21\n\nrequire example.com/a/v2 v2.0.0\n\nreplace example.com/a/v2 => ../a\n",
+		"client/c.go":   "package client\n\nimport _ \"example.com/a/v2\"\n",
+		"other/go.mod":  "module example.com/other\n\ngo 1.21\n\nrequire example.com/a/v2 v2.0.0\n",
+		"other/o.go":    "package other\n\nimport _ \"example.com/a/v2/sub\"\n",
+	}
+	for k, v := range gotRewrite {
+		if wantRewrite[k] != v {
+			t.Errorf("rewrite[%s] = <<%s>>, want <<%s>>", k, v, wantRewrite[k])
+		}
+		delete(wantRewrite, k)
+	}
+	for k, v := range wantRewrite {
+		t.Errorf("rewrite[%s] missing, want <<%s>>", k, v)
+	}
+
+	// Check that ambiguous cases are reported.
+	for _, want := range []string{
+		"a.go:8: string mentions example.com/a",
+		"check: require example.com/a/v2 v2.0.0: version not yet published?",
+	} {
+		if got := stderr.(*bytes.Buffer).String(); !strings.Contains(got, want) {
+			t.Errorf("stderr does not contain %q:\n%s", want, got)
+		}
+	}
+}
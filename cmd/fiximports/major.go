@@ -0,0 +1,422 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file implements the -major flag, which migrates a module to a
+// new major version.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/tools/internal/edit"
+)
+
+// majorRE matches the argument of the -major flag.
+var majorRE = regexp.MustCompile(`^v[1-9][0-9]*$`)
+
+// migrateMajor changes the path of the module enclosing the first of
+// dirs (by default, the current directory) to that of the given major
+// version, such as "v2", and rewrites the Go files and go.mod files
+// beneath dirs to match.
+// Invariant: a false result implies an error was already printed.
+func migrateMajor(major string, dirs ...string) bool {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	if !majorRE.MatchString(major) || major == "v1" {
+		fmt.Fprintf(stderr, "fiximports: -major: %q is not a major version v2 or later\n", major)
+		return false
+	}
+
+	gomod, err := findGoMod(dirs[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "fiximports: -major: %v\n", err)
+		return false
+	}
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		fmt.Fprintf(stderr, "fiximports: %v\n", err)
+		return false
+	}
+	mf, err := modfile.ParseLax(gomod, data, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "fiximports: %v\n", err)
+		return false
+	}
+	if mf.Module == nil {
+		fmt.Fprintf(stderr, "fiximports: %s: no module declaration\n", shortPath(gomod))
+		return false
+	}
+	oldPath := mf.Module.Mod.Path
+	prefix, pathMajor, ok := module.SplitPathVersion(oldPath)
+	if !ok || strings.HasPrefix(oldPath, "gopkg.in/") {
+		// gopkg.in paths denote the major version with a ".vN" suffix
+		// that is also part of the repository name.
+		fmt.Fprintf(stderr, "fiximports: -major: cannot migrate module %s, whose path does not end in /vN\n", oldPath)
+		return false
+	}
+	if strings.TrimPrefix(pathMajor, "/") == major {
+		fmt.Fprintf(stderr, "fiximports: -major: module %s is already at major version %s\n", oldPath, major)
+		return false
+	}
+	newPath := prefix + "/" + major
+
+	m := &migration{
+		oldPath: oldPath,
+		newPath: newPath,
+		major:   major,
+		root:    filepath.Dir(gomod),
+		seen:    make(map[string]bool),
+	}
+
+	// Find the Go files and go.mod files, and the paths
+	// of other modules nested within the migrated one.
+	var gofiles, modfiles []string
+	for _, dir := range append([]string{m.root}, dirs...) {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != dir && (name == "testdata" || name == "vendor" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				abs, err := filepath.Abs(path)
+				if err != nil {
+					return err
+				}
+				if m.seen[abs] {
+					return filepath.SkipDir // already visited
+				}
+				m.seen[abs] = true
+				return nil
+			}
+			switch {
+			case d.Name() == "go.mod":
+				modfiles = append(modfiles, path)
+			case strings.HasSuffix(path, ".go"):
+				gofiles = append(gofiles, path)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "fiximports: %v\n", err)
+			return false
+		}
+	}
+	for _, file := range modfiles {
+		if path := modulePath(file); path != oldPath && strings.HasPrefix(path, oldPath+"/") {
+			m.nested = append(m.nested, path)
+		}
+	}
+
+	ok = true
+	for _, file := range modfiles {
+		if !m.rewriteGoMod(file, sameFile(file, gomod)) {
+			ok = false
+		}
+	}
+	for _, file := range gofiles {
+		if !m.rewriteGoFile(file) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// A migration records the state of a -major migration.
+type migration struct {
+	oldPath, newPath string          // module paths before and after
+	major            string          // the new major version, such as "v2"
+	root             string          // root directory of the migrated module
+	nested           []string        // paths of modules nested within the old path
+	seen             map[string]bool // directories visited
+}
+
+// rewrite returns the path that replaces the import path, and reports
+// whether it denotes a package of the migrated module.
+func (m *migration) rewrite(path string) (string, bool) {
+	if path != m.oldPath && !strings.HasPrefix(path, m.oldPath+"/") ||
+		path == m.newPath || strings.HasPrefix(path, m.newPath+"/") {
+		return "", false
+	}
+	for _, nested := range m.nested {
+		if path == nested || strings.HasPrefix(path, nested+"/") {
+			return "", false // a package of another module
+		}
+	}
+	return m.newPath + path[len(m.oldPath):], true
+}
+
+// rewriteGoFile rewrites the imports and import comments of the Go file
+// that refer to packages of the migrated module, and reports other
+// mentions of the module path, which may or may not need updating.
+// Invariant: false result => error already printed.
+func (m *migration) rewriteGoFile(filename string) bool {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "\tERROR: %v\n", err)
+		return false
+	}
+	if !strings.Contains(string(data), m.oldPath) {
+		return true // fast path
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, data, parser.ParseComments)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n\tERROR: %v\n", shortPath(filename), err)
+		return false
+	}
+
+	buf := edit.NewBuffer(data)
+	at := func(p token.Pos) int {
+		return fset.File(p).Offset(p)
+	}
+	var fixed, mentions []string
+	replace := func(lit *ast.BasicLit, old, new string) {
+		buf.Replace(at(lit.Pos()), at(lit.End()), strconv.Quote(new))
+		fixed = append(fixed, fmt.Sprintf("%s -> %s", old, new))
+	}
+
+	// Rewrite imports.
+	imports := make(map[*ast.BasicLit]bool)
+	for _, imp := range f.Imports {
+		imports[imp.Path] = true
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if new, ok := m.rewrite(path); ok {
+			replace(imp.Path, path, new)
+		}
+	}
+
+	// Rewrite the import comment, if any.
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if fset.Position(c.Pos()).Line != fset.Position(f.Name.Pos()).Line {
+				continue
+			}
+			text, ok := strings.CutPrefix(c.Text, "// import ")
+			if !ok {
+				continue
+			}
+			path, err := strconv.Unquote(strings.TrimSpace(text))
+			if err != nil {
+				continue
+			}
+			if new, ok := m.rewrite(path); ok {
+				buf.Replace(at(c.Pos()), at(c.End()), "// import "+strconv.Quote(new))
+				fixed = append(fixed, fmt.Sprintf("%s -> %s (import comment)", path, new))
+			}
+		}
+	}
+
+	// Report other mentions of the module path in strings and
+	// //go:generate directives: they may refer to packages of the
+	// module, which need updating, or to its repository, which doesn't.
+	mentioned := func(s string) bool {
+		for i := 0; ; {
+			j := strings.Index(s[i:], m.oldPath)
+			if j < 0 {
+				return false
+			}
+			i += j + len(m.oldPath)
+			if i == len(s) || !strings.HasPrefix(s[i:], "/"+m.major) && !isPathChar(s[i]) {
+				return true
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && !imports[lit] && mentioned(lit.Value) {
+			mentions = append(mentions, fmt.Sprintf("%s:%d: string mentions %s", shortPath(filename), fset.Position(lit.Pos()).Line, m.oldPath))
+		}
+		return true
+	})
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "//go:generate ") && mentioned(c.Text) {
+				mentions = append(mentions, fmt.Sprintf("%s:%d: //go:generate mentions %s", shortPath(filename), fset.Position(c.Pos()).Line, m.oldPath))
+			}
+		}
+	}
+
+	if len(fixed) == 0 && len(mentions) == 0 {
+		return true
+	}
+	fmt.Fprintf(stderr, "%s\n", shortPath(filename))
+	for _, fix := range uniq(fixed) {
+		fmt.Fprintf(stderr, "\tfixed: %s\n", fix)
+	}
+	for _, mention := range mentions {
+		fmt.Fprintf(stderr, "\tcheck: %s\n", mention)
+	}
+	if len(fixed) > 0 && !*dryrun {
+		if err := writeFile(filename, buf.Bytes(), 0644); err != nil {
+			fmt.Fprintf(stderr, "\tERROR: %v\n", err)
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteGoMod updates the go.mod file: the module line of the migrated
+// module, and the requirements and replacements of the old module path
+// in others.
+// Invariant: false result => error already printed.
+func (m *migration) rewriteGoMod(filename string, isMigrated bool) bool {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n\tERROR: %v\n", shortPath(filename), err)
+		return false
+	}
+	f, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n\tERROR: %v\n", shortPath(filename), err)
+		return false
+	}
+
+	var fixed, mentions []string
+	if isMigrated {
+		f.AddModuleStmt(m.newPath)
+		fixed = append(fixed, fmt.Sprintf("module %s -> %s", m.oldPath, m.newPath))
+	}
+
+	// The requirements and replacements are updated in place,
+	// preserving their position and comments.
+	replaced := false
+	for _, r := range f.Replace {
+		if r.Old.Path != m.oldPath {
+			continue
+		}
+		// A replacement of a particular version of the old module
+		// cannot be carried over, as the versions differ.
+		if r.Old.Version != "" {
+			mentions = append(mentions, fmt.Sprintf("replace %s %s: not rewritten", r.Old.Path, r.Old.Version))
+			continue
+		}
+		replaced = true
+		setToken(r.Syntax, r.Old.Path, m.newPath)
+		r.Old.Path = m.newPath
+		fixed = append(fixed, fmt.Sprintf("replace %s -> %s", m.oldPath, m.newPath))
+	}
+
+	for _, r := range f.Require {
+		if r.Mod.Path != m.oldPath {
+			continue
+		}
+		// There is no way to know the version of the new module
+		// that a requirement should name until it is published.
+		version := m.major + ".0.0"
+		fixed = append(fixed, fmt.Sprintf("require %s %s -> %s %s", m.oldPath, r.Mod.Version, m.newPath, version))
+		setToken(r.Syntax, r.Mod.Version, version)
+		setToken(r.Syntax, r.Mod.Path, m.newPath)
+		r.Mod.Path, r.Mod.Version = m.newPath, version
+		if !replaced {
+			mentions = append(mentions, fmt.Sprintf("require %s %s: version not yet published?", m.newPath, version))
+		}
+	}
+
+	if len(fixed) == 0 && len(mentions) == 0 {
+		return true
+	}
+	fmt.Fprintf(stderr, "%s\n", shortPath(filename))
+	for _, fix := range fixed {
+		fmt.Fprintf(stderr, "\tfixed: %s\n", fix)
+	}
+	for _, mention := range mentions {
+		fmt.Fprintf(stderr, "\tcheck: %s\n", mention)
+	}
+	if len(fixed) > 0 && !*dryrun {
+		out, err := f.Format()
+		if err != nil {
+			fmt.Fprintf(stderr, "\tERROR: %v\n", err)
+			return false
+		}
+		if err := writeFile(filename, out, 0644); err != nil {
+			fmt.Fprintf(stderr, "\tERROR: %v\n", err)
+			return false
+		}
+	}
+	return true
+}
+
+// setToken replaces the first token of the go.mod line that denotes old by new.
+func setToken(line *modfile.Line, old, new string) {
+	for i, tok := range line.Token {
+		if tok == old || tok == modfile.AutoQuote(old) {
+			line.Token[i] = modfile.AutoQuote(new)
+			return
+		}
+	}
+}
+
+// findGoMod returns the go.mod file of the module enclosing dir.
+func findGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for d := dir; ; {
+		file := filepath.Join(d, "go.mod")
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("no go.mod file in %s or any parent directory", dir)
+		}
+		d = parent
+	}
+}
+
+// modulePath returns the module path declared by the go.mod file,
+// or "" if it cannot be read.
+func modulePath(gomod string) string {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return ""
+	}
+	return modfile.ModulePath(data)
+}
+
+// sameFile reports whether the two names denote the same file.
+func sameFile(x, y string) bool {
+	xi, err1 := os.Stat(x)
+	yi, err2 := os.Stat(y)
+	return err1 == nil && err2 == nil && os.SameFile(xi, yi)
+}
+
+// isPathChar reports whether c may continue an element of an import path.
+func isPathChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("-._~", c) >= 0
+}
+
+// uniq returns the sorted distinct elements of list.
+func uniq(list []string) []string {
+	sort.Strings(list)
+	var out []string
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
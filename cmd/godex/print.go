@@ -12,6 +12,8 @@ import (
 	"go/types"
 	"io"
 	"math/big"
+
+	"golang.org/x/tools/internal/aliases"
 )
 
 // TODO(gri) use tabwriter for alignment?
@@ -94,8 +96,12 @@ func (p *printer) printPackage(pkg *types.Package, filter func(types.Object) boo
 				case *types.Const:
 					consts = append(consts, obj)
 				case *types.TypeName:
-					// group into types with methods and types without
-					if named, m := methodsFor(obj); named != nil {
+					// group into types with methods and types without;
+					// an alias is declared as such, even if it denotes
+					// a type with methods, such as an instance List[int]
+					if isAlias(obj) {
+						typez = append(typez, obj)
+					} else if named, m := methodsFor(obj); named != nil {
 						typem = append(typem, named)
 						methods[named] = m
 					} else {
@@ -112,7 +118,7 @@ func (p *printer) printPackage(pkg *types.Package, filter func(types.Object) boo
 			}
 		} else if filter == nil {
 			// no filtering: collect top-level unexported types with methods
-			if obj, _ := obj.(*types.TypeName); obj != nil {
+			if obj, _ := obj.(*types.TypeName); obj != nil && !isAlias(obj) {
 				// see case *types.TypeName above
 				if named, m := methodsFor(obj); named != nil {
 					typem = append(typem, named)
@@ -140,8 +146,10 @@ func (p *printer) printPackage(pkg *types.Package, filter func(types.Object) boo
 
 	p.printDecl("type", len(typez), func() {
 		for _, obj := range typez {
-			p.printf("%s ", obj.Name())
+			p.print(obj.Name())
 			typ := obj.Type()
+			p.writeTypeParams(p.pkg, typeParams(typ))
+			p.print(" ")
 			if isAlias(obj) {
 				p.print("= ")
 				p.writeType(p.pkg, typ)
@@ -160,7 +168,9 @@ func (p *printer) printPackage(pkg *types.Package, filter func(types.Object) boo
 				p.print("\n")
 				first = false
 			}
-			p.printf("type %s ", obj.Name())
+			p.printf("type %s", obj.Name())
+			p.writeTypeParams(p.pkg, named.TypeParams())
+			p.print(" ")
 			p.writeType(p.pkg, named.Underlying())
 			p.print("\n")
 		}
@@ -338,12 +348,38 @@ func (p *printer) printFunc(recvType types.Type, obj *types.Func) {
 	if recvType != nil {
 		p.print("(")
 		p.writeType(p.pkg, recvType)
+		// The receiver of a method of a generic type
+		// is written with the method's names for the
+		// type parameters, as in func (*List[E]) Len() int.
+		if rparams := sig.RecvTypeParams(); rparams.Len() > 0 {
+			p.print("[")
+			for i, n := 0, rparams.Len(); i < n; i++ {
+				if i > 0 {
+					p.print(", ")
+				}
+				p.print(rparams.At(i).Obj().Name())
+			}
+			p.print("]")
+		}
 		p.print(") ")
 	}
 	p.print(obj.Name())
+	p.writeTypeParams(p.pkg, sig.TypeParams())
 	p.writeSignature(p.pkg, sig)
 }
 
+// typeParams returns the type parameters of the generic
+// type or alias typ, or nil.
+func typeParams(typ types.Type) *types.TypeParamList {
+	switch typ := typ.(type) {
+	case *types.Named:
+		return typ.TypeParams()
+	case *types.Alias:
+		return aliases.TypeParams(typ)
+	}
+	return nil
+}
+
 // combinedMethodSet returns the method set for a named type T
 // merged with all the methods of *T that have different names than
 // the methods of T.
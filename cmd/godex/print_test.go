@@ -0,0 +1,73 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+const genericSrc = `package p
+
+import "cmp"
+
+type List[E any] struct{ elems []E }
+
+func (l *List[T]) Len() int { return len(l.elems) }
+
+type Set[K comparable] = map[K]struct{}
+
+type Number interface{ ~int | ~float64 }
+
+type Ints = List[int]
+
+func Max[S ~[]E, E cmp.Ordered](s S) E { var e E; return e }
+
+func Sum[N Number](ns ...N) N { var n N; return n }
+`
+
+// TestPrintGeneric checks the printing of generic types and functions,
+// and of aliases of them and their instances.
+func TestPrintGeneric(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", genericSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	print(&buf, pkg, nil)
+	const want = `package p  // "p"
+
+type (
+	Ints = List[int]
+	Number interface {
+		~int | ~float64
+	}
+	Set[K comparable] = map[K]struct{}
+)
+
+type List[E any] struct {
+	elems []E
+}
+func (*List[T]) Len() int
+
+func Max[S ~[]E, E cmp.Ordered](s S) E
+func Sum[N Number](ns ...N) N
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -109,9 +109,18 @@ func (p *printer) writeTypeInternal(this *types.Package, typ types.Type, visited
 		//         m() interface{ T }
 		//     }
 		//
+		if t.IsImplicit() && t.NumEmbeddeds() == 1 {
+			// implicit constraint interface such as ~int in [T ~int]
+			p.writeTypeInternal(this, t.EmbeddedType(0), visited)
+			return
+		}
 		n := t.NumMethods()
-		if n == 0 {
-			p.print("interface{}")
+		if n == 0 && t.NumEmbeddeds() == 0 {
+			if t.IsComparable() {
+				p.print("interface{comparable}")
+			} else {
+				p.print("interface{}")
+			}
 			return
 		}
 
@@ -175,7 +184,27 @@ func (p *printer) writeTypeInternal(this *types.Package, typ types.Type, visited
 			p.print(")")
 		}
 
+	case *types.Union:
+		for i, n := 0, t.Len(); i < n; i++ {
+			if i > 0 {
+				p.print(" | ")
+			}
+			term := t.Term(i)
+			if term.Tilde() {
+				p.print("~")
+			}
+			p.writeTypeInternal(this, term.Type(), visited)
+		}
+
+	case *types.TypeParam:
+		p.print(t.Obj().Name())
+
 	case *types.Alias:
+		if obj := t.Obj(); obj.Pkg() == nil {
+			// predeclared alias such as any
+			p.print(obj.Name())
+			return
+		}
 		// TODO(adonovan): display something aliasy.
 		p.writeTypeInternal(this, types.Unalias(t), visited)
 
@@ -194,6 +223,16 @@ func (p *printer) writeTypeInternal(this *types.Package, typ types.Type, visited
 			s = obj.Name()
 		}
 		p.print(s)
+		if targs := t.TypeArgs(); targs.Len() > 0 {
+			p.print("[")
+			for i, n := 0, targs.Len(); i < n; i++ {
+				if i > 0 {
+					p.print(", ")
+				}
+				p.writeTypeInternal(this, targs.At(i), visited)
+			}
+			p.print("]")
+		}
 
 	default:
 		// For externally defined implementations of Type.
@@ -222,6 +261,26 @@ func (p *printer) writeTuple(this *types.Package, tup *types.Tuple, variadic boo
 	p.print(")")
 }
 
+// writeTypeParams writes the type parameter list of a generic type or
+// function declaration, such as [S ~[]E, E cmp.Ordered].
+func (p *printer) writeTypeParams(this *types.Package, list *types.TypeParamList) {
+	if list.Len() == 0 {
+		return
+	}
+	visited := make([]types.Type, 8)
+	p.print("[")
+	for i, n := 0, list.Len(); i < n; i++ {
+		if i > 0 {
+			p.print(", ")
+		}
+		tpar := list.At(i)
+		p.print(tpar.Obj().Name())
+		p.print(" ")
+		p.writeTypeInternal(this, tpar.Constraint(), visited)
+	}
+	p.print("]")
+}
+
 func (p *printer) writeSignature(this *types.Package, sig *types.Signature) {
 	p.writeSignatureInternal(this, sig, make([]types.Type, 8))
 }
@@ -26,6 +26,11 @@ The flags are:
 	-index_files=""
 		glob pattern specifying index files; if not empty,
 		the index is read from these files in sorted order
+	-index_cache=""
+		file in which to save the search index each time it is
+		updated; when the index is next built, including at the
+		next startup, only directories with changed files are
+		reindexed
 	-index_throttle=0.75
 		index throttle value; a value of 0 means no time is allocated
 		to the indexer (the indexer will never finish), a value of 1.0
@@ -49,6 +54,10 @@ The flags are:
 		(e.g., "BUG|TODO", ".*")
 	-goroot=$GOROOT
 		Go root directory
+	-modcache=false
+		serve documentation for every module in the module cache
+		(go env GOMODCACHE), at the versions required by the main
+		module, if any, or else the latest ones
 	-http=addr
 		HTTP service address (e.g., '127.0.0.1:6060' or just ':6060')
 	-templates=""
@@ -65,6 +74,14 @@ By default, godoc looks at the packages it finds via $GOROOT and $GOPATH (if set
 This behavior can be altered by providing an alternative $GOROOT with the -goroot
 flag.
 
+With the -modcache flag, godoc also serves the documentation of every
+module in the module cache, such as the private modules of a team, which
+need only be downloaded with 'go mod download' to be shown. A module
+required by the main module in the current directory is shown at the
+version it requires; any other module is shown at its latest version in
+the cache. Any version in the cache may be shown by adding it to the
+module path, as in /pkg/golang.org/x/text@v0.14.0/language/.
+
 When the -index flag is set, a search index is maintained.
 The index is created at startup.
 
@@ -44,6 +44,7 @@ import (
 	"golang.org/x/tools/godoc/vfs"
 	"golang.org/x/tools/godoc/vfs/gatefs"
 	"golang.org/x/tools/godoc/vfs/mapfs"
+	"golang.org/x/tools/godoc/vfs/modcache"
 	"golang.org/x/tools/godoc/vfs/zipfs"
 	"golang.org/x/tools/internal/gocommand"
 )
@@ -68,7 +69,8 @@ var (
 
 	// file system roots
 	// TODO(gri) consider the invariant that goroot always end in '/'
-	goroot = flag.String("goroot", findGOROOT(), "Go root directory")
+	goroot   = flag.String("goroot", findGOROOT(), "Go root directory")
+	modCache = flag.Bool("modcache", false, "serve documentation for every module in the module cache (go env GOMODCACHE), at the versions required by the main module, if any, or else the latest ones")
 
 	// layout control
 	showTimestamps = flag.Bool("timestamps", false, "show timestamps with directory listings")
@@ -79,6 +81,7 @@ var (
 	// search index
 	indexEnabled  = flag.Bool("index", false, "enable search index")
 	indexFiles    = flag.String("index_files", "", "glob pattern specifying index files; if not empty, the index is read from these files in sorted order")
+	indexCache    = flag.String("index_cache", "", "file in which to save the search index as it is updated, so that only changed directories are reindexed, including at the next startup")
 	indexInterval = flag.Duration("index_interval", 0, "interval of indexing; 0 for default (5m), negative to only index once at startup")
 	maxResults    = flag.Int("maxresults", 10000, "maximum number of full text search results shown")
 	indexThrottle = flag.Float64("index_throttle", 0.75, "index throttle value; 0.0 = no time allocated, 1.0 = full throttle")
@@ -257,6 +260,17 @@ func main() {
 		}
 	}
 
+	if *modCache {
+		// Bind the modules in the module cache, behind those of the
+		// build list, if any, so that the documentation of private
+		// modules can be served without an index such as pkg.go.dev.
+		mfs, err := newModCacheFS(goModFile)
+		if err != nil {
+			log.Fatalf("module cache: %v", err)
+		}
+		fs.Bind("/src", gatefs.New(mfs, fsGate), "/", vfs.BindAfter)
+	}
+
 	var corpus *godoc.Corpus
 	if goModFile != "" {
 		corpus = godoc.NewCorpus(moduleFS{fs})
@@ -270,6 +284,7 @@ func main() {
 		corpus.IndexFullText = false
 	}
 	corpus.IndexFiles = *indexFiles
+	corpus.IndexCacheFile = *indexCache
 	corpus.IndexDirectory = func(dir string) bool {
 		return dir != "/pkg" && !strings.HasPrefix(dir, "/pkg/")
 	}
@@ -386,6 +401,32 @@ func goMod() (string, error) {
 	return env.GoMod, nil
 }
 
+// newModCacheFS returns a file system presenting the modules in
+// the module cache, at the versions required by the go.mod file
+// goMod, if it is not empty.
+func newModCacheFS(goMod string) (*modcache.FS, error) {
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if ee := (*exec.ExitError)(nil); errors.As(err, &ee) {
+		return nil, fmt.Errorf("go command exited unsuccessfully: %v\n%s", ee.ProcessState.String(), ee.Stderr)
+	} else if err != nil {
+		return nil, err
+	}
+	fs, err := modcache.New(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, err
+	}
+	if goMod != "" && goMod != os.DevNull {
+		data, err := os.ReadFile(goMod)
+		if err != nil {
+			return nil, err
+		}
+		if err := fs.UseGoMod(goMod, data); err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
 // fillModuleCache does a best-effort attempt to fill the module cache
 // with all dependencies of the main module in the current directory
 // by invoking the go command. Module download logs are streamed to w.
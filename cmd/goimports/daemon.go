@@ -0,0 +1,329 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file implements the daemon mode of goimports, in which a
+// long-lived process serves formatting requests so that the results of
+// scanning GOPATH and the module cache are reused across invocations.
+//
+// The protocol is a sequence of JSON values, one per line: each request
+// is followed by exactly one response. The daemon serves either a
+// single client on its standard input and output, or any number of
+// clients connecting to a unix domain socket.
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/internal/imports"
+)
+
+var (
+	daemon = flag.Bool("daemon", false, "run as a daemon serving requests on -socket, or on stdin and stdout if -socket is not set")
+	socket = flag.String("socket", "", "unix domain `socket` on which the daemon listens; without -daemon, send files to the daemon listening there")
+)
+
+// rescanInterval is the minimum time after which the daemon discards
+// its knowledge of the directories outside the module cache, so that
+// packages added to GOPATH or to a workspace become visible.
+const rescanInterval = 30 * time.Second
+
+// A daemonRequest asks the daemon to process a file.
+type daemonRequest struct {
+	Filename string            `json:"filename"`      // the name used to decide visible imports
+	Src      []byte            `json:"src"`           // the file's contents
+	Env      map[string]string `json:"env,omitempty"` // the client's go environment; see goEnviron
+}
+
+// A daemonResponse is the result of a daemonRequest.
+type daemonResponse struct {
+	Src   []byte `json:"src,omitempty"`   // the processed file, if Error is empty
+	Error string `json:"error,omitempty"` // the error, if processing failed
+}
+
+// A server processes requests using a ProcessEnv for each module and
+// client environment, so that the caches of each remain warm. Requests
+// that use different ProcessEnvs are processed concurrently.
+type server struct {
+	mu       sync.Mutex            // guards envs
+	envs     map[envKey]*serverEnv // the ProcessEnv of each module and environment
+	modCache *imports.DirInfoCache // shared by all envs
+}
+
+// An envKey identifies the ProcessEnv of a request.
+type envKey struct {
+	root string // module root directory, or "" outside a module
+	env  string // the request's environment, encoded by envString
+}
+
+// A serverEnv is a ProcessEnv and its staleness information.
+// A ProcessEnv is not safe for concurrent use, so mu serializes
+// the requests that use it.
+type serverEnv struct {
+	mu      sync.Mutex
+	env     *imports.ProcessEnv
+	scanned time.Time // time of last reset of the resolver
+	modTime time.Time // modification time of go.mod when the resolver was created
+}
+
+func newServer() *server {
+	return &server{
+		envs:     make(map[envKey]*serverEnv),
+		modCache: imports.NewDirInfoCache(),
+	}
+}
+
+// process processes the file in req using the options of the daemon.
+func (s *server) process(req *daemonRequest) ([]byte, error) {
+	root, modTime := moduleRoot(filepath.Dir(req.Filename))
+	e := s.env(root, req.Env)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	opt := *options
+	opt.Env = e.update(modTime)
+	return imports.Process(req.Filename, req.Src, &opt)
+}
+
+// env returns the serverEnv for the module whose root directory is
+// root and for the client environment clientEnv, creating it if needed.
+func (s *server) env(root string, clientEnv map[string]string) *serverEnv {
+	key := envKey{root, envString(clientEnv)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.envs[key]
+	if !ok {
+		env := &imports.ProcessEnv{
+			GocmdRunner: options.Env.GocmdRunner,
+			BuildFlags:  options.Env.BuildFlags,
+			Logf:        options.Env.Logf,
+			WorkingDir:  root,
+			ModCache:    s.modCache,
+		}
+		if clientEnv != nil {
+			// The go command must see the client's environment,
+			// not the daemon's, so unset the daemon's variables
+			// that the client does not have.
+			env.Env = make(map[string]string)
+			for k := range goEnviron() {
+				env.Env[k] = ""
+			}
+			for k, v := range clientEnv {
+				env.Env[k] = v
+			}
+		}
+		e = &serverEnv{env: env}
+		s.envs[key] = e
+	}
+	return e
+}
+
+// update invalidates any state of the ProcessEnv that may be stale,
+// given the current modification time of go.mod, and returns it.
+// Callers must hold e.mu.
+func (e *serverEnv) update(modTime time.Time) *imports.ProcessEnv {
+	now := time.Now()
+	if e.scanned.IsZero() {
+		e.scanned, e.modTime = now, modTime
+		return e.env
+	}
+	if !modTime.Equal(e.modTime) {
+		e.env.ClearModuleInfo()
+		e.modTime = modTime
+	}
+	if now.Sub(e.scanned) > rescanInterval {
+		if r, err := e.env.GetResolver(); err == nil {
+			e.env.UpdateResolver(r.ClearForNewScan())
+		}
+		e.scanned = now
+	}
+	return e.env
+}
+
+// goEnviron returns the variables of the environment that affect the
+// go command: those whose names begin with GO or CGO_, and HOME, from
+// which the defaults of GOPATH and others derive.
+func goEnviron() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && (strings.HasPrefix(k, "GO") || strings.HasPrefix(k, "CGO_") || k == "HOME") {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// envString returns a canonical encoding of env,
+// distinguishing a nil env (the daemon's environment)
+// from an empty one.
+func envString(env map[string]string) string {
+	if env == nil {
+		return ""
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf strings.Builder
+	buf.WriteString("env")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "\x00%s=%s", k, env[k])
+	}
+	return buf.String()
+}
+
+// moduleRoot returns the directory containing the go.mod file
+// of the module enclosing dir, and that file's modification time.
+// It returns "" if dir is not within a module.
+func moduleRoot(dir string) (string, time.Time) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", time.Time{}
+	}
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, fi.ModTime()
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", time.Time{}
+		}
+		dir = parent
+	}
+}
+
+// serve reads requests from r and writes a response
+// to w for each one, until r reaches end of file.
+func (s *server) serve(r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for {
+		var req daemonRequest
+		if err := dec.Decode(&req); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var resp daemonResponse
+		if res, err := s.process(&req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Src = res
+		}
+		if err := enc.Encode(&resp); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// runDaemon serves requests on the specified socket,
+// or on the standard input and output if socket is empty.
+func runDaemon(socket string) error {
+	s := newServer()
+	if socket == "" {
+		return s.serve(os.Stdin, os.Stdout)
+	}
+
+	// Remove the socket of a daemon that did not exit cleanly,
+	// but not that of one that is still running.
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			return fmt.Errorf("a daemon is already listening on %s", socket)
+		}
+		os.Remove(socket)
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	// Closing the listener removes the socket.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := s.serve(conn, conn); err != nil && verbose {
+				log.Printf("serving client: %v", err)
+			}
+		}()
+	}
+}
+
+// client sends requests to a daemon.
+type client struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// dialDaemon connects to the daemon listening on socket.
+func dialDaemon(socket string) (*client, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(conn), nil
+}
+
+func newClient(conn net.Conn) *client {
+	return &client{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(bufio.NewReader(conn)),
+	}
+}
+
+// process asks the daemon to process the file.
+func (c *client) process(filename string, src []byte) ([]byte, error) {
+	// The daemon's working directory may differ from ours.
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	req := &daemonRequest{Filename: filename, Src: src, Env: goEnviron()}
+	if err := c.enc.Encode(req); err != nil {
+		return nil, err
+	}
+	var resp daemonResponse
+	if err := c.dec.Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Src, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+const daemonSrc = `package m
+
+func f() { fmt.Println() }
+`
+
+// writeModule creates a module named m in a new directory
+// and returns the name of a file within it.
+func writeModule(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module m\n\ngo 1.20\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "m.go")
+}
+
+// startDaemon returns a client of a new server
+// that serves it until the end of the test.
+func startDaemon(t *testing.T) (*server, *client) {
+	s := newServer()
+	clientConn, serverConn := net.Pipe()
+	done := make(chan error)
+	go func() { done <- s.serve(serverConn, serverConn) }()
+	t.Cleanup(func() {
+		clientConn.Close()
+		<-done
+	})
+	return s, newClient(clientConn)
+}
+
+func TestDaemon(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	_, c := startDaemon(t)
+	filename := writeModule(t)
+	for range 2 {
+		got, err := c.process(filename, []byte(daemonSrc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), `import "fmt"`) {
+			t.Errorf("process returned:\n%s\nwant import of fmt", got)
+		}
+	}
+	if _, err := c.process(filename, []byte("package m\n\nfunc (")); err == nil {
+		t.Errorf("process of invalid file succeeded")
+	}
+}
+
+func TestDaemonEnv(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	s := newServer()
+	root := filepath.Dir(writeModule(t))
+	e := s.env(root, map[string]string{"GOOS": "plan9"})
+	if got := e.env.Env["GOOS"]; got != "plan9" {
+		t.Errorf("GOOS = %q, want the client's plan9", got)
+	}
+	if got, ok := e.env.Env["GOFLAGS"]; !ok || got != "" {
+		t.Errorf("GOFLAGS = %q, %t, want the daemon's unset", got, ok)
+	}
+	if s.env(root, map[string]string{"GOOS": "plan9"}) != e {
+		t.Errorf("same environment has different ProcessEnvs")
+	}
+	if s.env(root, map[string]string{"GOOS": "linux"}) == e {
+		t.Errorf("different environments share a ProcessEnv")
+	}
+	if e := s.env(root, nil); e.env.Env != nil {
+		t.Errorf("request without environment has Env %v, want the daemon's", e.env.Env)
+	}
+}
+
+func TestDaemonConcurrency(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	s, c := startDaemon(t)
+	filename := writeModule(t)
+
+	// A request for one module must not wait
+	// for one in progress for another.
+	other := s.env(filepath.Dir(writeModule(t)), goEnviron())
+	other.mu.Lock()
+	defer other.mu.Unlock()
+
+	done := make(chan error)
+	go func() {
+		_, err := c.process(filename, []byte(daemonSrc))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("request is blocked by one for another module")
+	}
+}
@@ -33,6 +33,27 @@ For GoSublime, follow the steps described here:
 
 For other editors, you probably know what to do.
 
+When given a directory, goimports processes the Go files in its tree
+concurrently, but prints their results in lexical order. It skips the files and directories ignored by .gitignore files, unless
+the -gitignore=false flag is given, and those matching the patterns
+of the -skip flag, a comma-separated list in the same syntax:
+
+	goimports -l -skip=vendor,testdata/golden .
+
+As with the go command, an argument of the form dir/... also denotes
+the tree rooted at dir.
+
+The -watch flag keeps goimports running after it has processed its
+arguments, processing each Go file again whenever it is created or
+modified, for use alongside code generators and editors that do not
+format files when saving them:
+
+	goimports -w -watch ./...
+
+Because the knowledge of available packages is retained in memory,
+processing a changed file is fast. The -watch flag requires -w, -l,
+or -d.
+
 To exclude directories in your $GOPATH from being scanned for Go
 files, goimports respects a configuration file at
 $GOPATH/src/.goimportsignore which may contain blank lines, comment
@@ -41,6 +62,54 @@ the configuration file to ignore when scanning. No globbing or regex
 patterns are allowed. Use the "-v" verbose flag to verify it's
 working and see what goimports is doing.
 
+By default, goimports sorts each group of consecutive import lines,
+separating the standard library from other packages, and the packages
+matching the -local prefixes from the rest. The -sections flag instead
+specifies a complete list of sections, into which all the imports of
+a file are reordered, separated by blank lines. Each section is one of
+"std" (the standard library), "external" (imports not in any other
+section), "module" (the module containing the file), or a list of
+import path prefixes separated by '|'. For example,
+
+	goimports -sections=std,external,example.com/org,module
+
+puts the packages of the example.com/org organization after other
+third-party packages, followed by those of the current module.
+The -sectionsfile flag reads the same list, one section per line,
+from a file; lines may contain '#' comments.
+
+The -report=json flag replaces the formatted output with a stream of
+JSON objects, one for each file that goimports changes, listing the
+imports added and removed. For example:
+
+	{
+		"File": "x.go",
+		"Added": [{"Path": "fmt", "Symbols": ["Println"]}],
+		"Removed": [{"Path": "os"}]
+	}
+
+The Symbols of an added import are those whose references required
+it. An import whose name changed is reported as both removed and
+added. The flag may be combined with -w.
+
+Finding the package for a missing import may require scanning GOPATH
+and the module cache, which can take seconds. To avoid repeating this
+work for every file an editor saves, start a long-lived daemon:
+
+	goimports -daemon -socket=/tmp/goimports.sock &
+
+and pass the same -socket flag to subsequent goimports commands, which
+then send files to the daemon for processing. If no daemon is
+listening, goimports processes the files itself. Formatting options
+such as -local and -sections are those of the daemon, but the go
+command runs in the environment (GOFLAGS, GOPATH, GOOS, and so on) of
+each client. Without -socket, the daemon serves a single client on its
+standard input and output, reading requests of the form
+{"filename": ..., "src": ..., "env": {"GOOS": ..., ...}} and writing
+responses of the form {"src": ...} or {"error": ...}, one JSON value
+per line, with file contents encoded in base64. If a request has no
+"env", the daemon's own environment is used.
+
 File bugs or feature requests at:
 
 	https://golang.org/issues/new?title=x/tools/cmd/goimports:+
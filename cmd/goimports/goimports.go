@@ -31,6 +31,9 @@ var (
 	doDiff = flag.Bool("d", false, "display diffs instead of rewriting files")
 	srcdir = flag.String("srcdir", "", "choose imports as if source code is from `dir`. When operating on a single file, dir may instead be the complete file name.")
 
+	sections     = flag.String("sections", "", "reorder imports into the specified sections, such as \"std,external,example.com/org,module\"; overrides -local")
+	sectionsFile = flag.String("sectionsfile", "", "read the import sections specification from `file`")
+
 	verbose bool // verbose logging
 
 	cpuProfile     = flag.String("cpuprofile", "", "CPU profile output")
@@ -47,6 +50,8 @@ var (
 		},
 	}
 	exitCode = 0
+
+	daemonClient *client // if non-nil, files are processed by the daemon
 )
 
 func init() {
@@ -138,13 +143,23 @@ func processFile(filename string, in io.Reader, out io.Writer, argType argumentT
 		}
 	}
 
-	res, err := imports.Process(target, src, opt)
+	var res []byte
+	if daemonClient != nil {
+		res, err = daemonClient.process(target, src)
+	} else {
+		res, err = imports.Process(target, src, opt)
+	}
 	if err != nil {
 		return err
 	}
 
 	if !bytes.Equal(src, res) {
 		// formatting has changed
+		if *reportFormat != "" {
+			if err := writeReport(out, filename, src, res); err != nil {
+				return err
+			}
+		}
 		if *list {
 			fmt.Fprintln(out, filename)
 		}
@@ -171,32 +186,18 @@ func processFile(filename string, in io.Reader, out io.Writer, argType argumentT
 			if err != nil {
 				return fmt.Errorf("computing diff: %s", err)
 			}
-			fmt.Printf("diff -u %s %s\n", filepath.ToSlash(filename+".orig"), filepath.ToSlash(filename))
+			fmt.Fprintf(out, "diff -u %s %s\n", filepath.ToSlash(filename+".orig"), filepath.ToSlash(filename))
 			out.Write(data)
 		}
 	}
 
-	if !*list && !*write && !*doDiff {
+	if !*list && !*write && !*doDiff && *reportFormat == "" {
 		_, err = out.Write(res)
 	}
 
 	return err
 }
 
-func visitFile(path string, f os.FileInfo, err error) error {
-	if err == nil && isGoFile(f) {
-		err = processFile(path, nil, os.Stdout, multipleArg)
-	}
-	if err != nil {
-		report(err)
-	}
-	return nil
-}
-
-func walkDir(path string) {
-	filepath.Walk(path, visitFile)
-}
-
 func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
@@ -262,21 +263,94 @@ func gofmtMain() {
 		log.SetFlags(log.LstdFlags | log.Lmicroseconds)
 		options.Env.Logf = log.Printf
 	}
+	switch {
+	case *sections != "" && *sectionsFile != "":
+		fmt.Fprintf(os.Stderr, "goimports: -sections and -sectionsfile are mutually exclusive\n")
+		exitCode = 2
+		return
+	case *sections != "":
+		s, err := imports.ParseSections(*sections)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "goimports: invalid -sections: %v\n", err)
+			exitCode = 2
+			return
+		}
+		options.Sections = s
+	case *sectionsFile != "":
+		s, err := imports.ReadSections(*sectionsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "goimports: %v\n", err)
+			exitCode = 2
+			return
+		}
+		options.Sections = s
+	}
+	switch {
+	case *reportFormat != "" && *reportFormat != "json":
+		fmt.Fprintf(os.Stderr, "goimports: unsupported -report format %q\n", *reportFormat)
+		exitCode = 2
+		return
+	case *reportFormat != "" && (*doDiff || *list):
+		fmt.Fprintf(os.Stderr, "goimports: -report cannot be combined with -d or -l\n")
+		exitCode = 2
+		return
+	}
 	if options.TabWidth < 0 {
 		fmt.Fprintf(os.Stderr, "negative tabwidth %d\n", options.TabWidth)
 		exitCode = 2
 		return
 	}
 
+	if *daemon {
+		if len(paths) > 0 {
+			fmt.Fprintf(os.Stderr, "goimports: -daemon does not accept file arguments\n")
+			exitCode = 2
+			return
+		}
+		if err := runDaemon(*socket); err != nil {
+			fmt.Fprintf(os.Stderr, "goimports: %v\n", err)
+			exitCode = 2
+		}
+		return
+	}
+	if *socket != "" {
+		// If no daemon is running, process the files ourselves.
+		if c, err := dialDaemon(*socket); err != nil {
+			if verbose {
+				log.Printf("not using daemon: %v", err)
+			}
+		} else {
+			defer c.conn.Close()
+			daemonClient = c
+		}
+	}
+
 	if len(paths) == 0 {
+		if *watch {
+			fmt.Fprintf(os.Stderr, "goimports: -watch requires files or directories\n")
+			exitCode = 2
+			return
+		}
 		if err := processFile("<standard input>", os.Stdin, os.Stdout, fromStdin); err != nil {
 			report(err)
 		}
 		return
 	}
 
+	if *watch && !*write && !*list && !*doDiff {
+		fmt.Fprintf(os.Stderr, "goimports: -watch requires -w, -l, or -d\n")
+		exitCode = 2
+		return
+	}
+	for i, path := range paths {
+		// As with the go command, dir/... denotes the tree rooted at dir.
+		if path == "..." || strings.HasSuffix(path, "/...") {
+			paths[i] = filepath.Clean(strings.TrimSuffix(path, "..."))
+		}
+	}
+
 	argType := singleArg
-	if len(paths) > 1 {
+	if len(paths) > 1 || *watch {
 		argType = multipleArg
 	}
 
@@ -285,13 +359,16 @@ func gofmtMain() {
 		case err != nil:
 			report(err)
 		case dir.IsDir():
-			walkDir(path)
+			walkDir(path, os.Stdout)
 		default:
 			if err := processFile(path, nil, os.Stdout, argType); err != nil {
 				report(err)
 			}
 		}
 	}
+	if *watch {
+		watchFiles(paths)
+	}
 }
 
 func writeTempFile(dir, prefix string, data []byte) (string, error) {
@@ -0,0 +1,138 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/internal/imports"
+)
+
+var reportFormat = flag.String("report", "", "instead of the formatted files, print a report of the changes in the specified `format` (only \"json\" is supported)")
+
+// A fileReport describes the changes made to a file.
+type fileReport struct {
+	File    string         // the name of the file
+	Added   []importReport `json:",omitempty"` // the imports added
+	Removed []importReport `json:",omitempty"` // the imports removed
+}
+
+// An importReport describes an import that was added or removed.
+type importReport struct {
+	Path    string   // the import path
+	Name    string   `json:",omitempty"` // the explicit name, if any
+	Symbols []string `json:",omitempty"` // for added imports, the symbols that required it
+}
+
+// writeReport writes a JSON report of the changes between src and res,
+// the original and processed contents of filename, to out.
+func writeReport(out io.Writer, filename string, src, res []byte) error {
+	r := &fileReport{File: filename}
+	fset := token.NewFileSet()
+	before, err := parseImports(fset, filename, src)
+	if err != nil {
+		return err
+	}
+	after, err := parseImports(fset, filename, res)
+	if err != nil {
+		return err
+	}
+	for key := range after.imports {
+		if !before.imports[key] {
+			imp := importReport{Path: key.path, Name: key.name}
+			name := key.name
+			if name == "" {
+				// goimports names any import whose package name
+				// differs from the one assumed from its path.
+				name = imports.ImportPathToAssumedName(key.path)
+			}
+			imp.Symbols = before.refs[name]
+			r.Added = append(r.Added, imp)
+		}
+	}
+	for key := range before.imports {
+		if !after.imports[key] {
+			r.Removed = append(r.Removed, importReport{Path: key.path, Name: key.name})
+		}
+	}
+	for _, imps := range [][]importReport{r.Added, r.Removed} {
+		sort.Slice(imps, func(i, j int) bool {
+			if imps[i].Path != imps[j].Path {
+				return imps[i].Path < imps[j].Path
+			}
+			return imps[i].Name < imps[j].Name
+		})
+	}
+
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+type importKey struct{ path, name string }
+
+// importsAndRefs holds the imports of a file,
+// and the symbols it refers to in each package.
+type importsAndRefs struct {
+	imports map[importKey]bool
+	refs    map[string][]string // sorted symbols of qualified identifiers, by qualifier
+}
+
+// parseImports parses src and returns its imports and references.
+func parseImports(fset *token.FileSet, filename string, src []byte) (*importsAndRefs, error) {
+	f, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		// The file may be a fragment lacking a package clause
+		// (see imports.Options.Fragment).
+		f, err = parser.ParseFile(fset, filename, "package p;"+string(src), parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r := &importsAndRefs{
+		imports: make(map[importKey]bool),
+		refs:    make(map[string][]string),
+	}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		key := importKey{path: path}
+		if spec.Name != nil {
+			key.name = spec.Name.Name
+		}
+		r.imports[key] = true
+	}
+	seen := make(map[string]map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && ast.IsExported(sel.Sel.Name) {
+				if seen[x.Name] == nil {
+					seen[x.Name] = make(map[string]bool)
+				}
+				if !seen[x.Name][sel.Sel.Name] {
+					seen[x.Name][sel.Sel.Name] = true
+					r.refs[x.Name] = append(r.refs[x.Name], sel.Sel.Name)
+				}
+			}
+		}
+		return true
+	})
+	for _, syms := range r.refs {
+		sort.Strings(syms)
+	}
+	return r, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		src, res string
+		want     string
+	}{
+		{
+			name: "addremove",
+			src: `package p
+
+import "os"
+
+func f() { fmt.Println(); fmt.Sprint(); fmt.Println() }
+`,
+			res: `package p
+
+import "fmt"
+
+func f() { fmt.Println(); fmt.Sprint(); fmt.Println() }
+`,
+			want: `{
+	"File": "x.go",
+	"Added": [
+		{
+			"Path": "fmt",
+			"Symbols": [
+				"Println",
+				"Sprint"
+			]
+		}
+	],
+	"Removed": [
+		{
+			"Path": "os"
+		}
+	]
+}
+`,
+		},
+		{
+			name: "named",
+			src: `package p
+
+var _ = yaml.Marshal
+`,
+			res: `package p
+
+import yaml "gopkg.in/yaml.v3"
+
+var _ = yaml.Marshal
+`,
+			want: `{
+	"File": "x.go",
+	"Added": [
+		{
+			"Path": "gopkg.in/yaml.v3",
+			"Name": "yaml",
+			"Symbols": [
+				"Marshal"
+			]
+		}
+	]
+}
+`,
+		},
+		{
+			name: "fragment",
+			src:  "func f() { strings.ToUpper(\"\") }\n",
+			res:  "import \"strings\"\n\nfunc f() { strings.ToUpper(\"\") }\n",
+			want: `{
+	"File": "x.go",
+	"Added": [
+		{
+			"Path": "strings",
+			"Symbols": [
+				"ToUpper"
+			]
+		}
+	]
+}
+`,
+		},
+	} {
+		var buf bytes.Buffer
+		if err := writeReport(&buf, "x.go", []byte(test.src), []byte(test.res)); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", test.name, got, test.want)
+		}
+	}
+}
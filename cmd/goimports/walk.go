@@ -0,0 +1,286 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	skip      = flag.String("skip", "", "comma-separated list of `patterns`, in .gitignore syntax, of files and directories to skip when walking directory trees")
+	gitignore = flag.Bool("gitignore", true, "skip files and directories ignored by .gitignore files when walking directory trees")
+)
+
+// outputMu serializes the output of concurrent calls to processFile,
+// and guards exitCode.
+var outputMu sync.Mutex
+
+// A walkResult is the output of processing a file during a walk.
+type walkResult struct {
+	filename string
+	out      bytes.Buffer
+	err      error
+	done     chan struct{} // closed when out and err are set
+}
+
+// walkDir processes the Go files in the tree rooted at root,
+// concurrently, writing the output for each file to out in the
+// order of the walk, which is lexical.
+func walkDir(root string, out io.Writer) {
+	workers := runtime.GOMAXPROCS(0)
+	if daemonClient != nil {
+		workers = 1 // the client is not safe for concurrent use
+	} else {
+		// Create the resolver before it is used concurrently.
+		options.Env.GetResolver()
+	}
+	files := make(chan *walkResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range files {
+				r.err = processFile(r.filename, nil, &r.out, multipleArg)
+				close(r.done)
+			}
+		}()
+	}
+
+	// Write the results in order as they complete. The capacity of
+	// results bounds the number of files processed ahead of the
+	// first file whose output has not been written.
+	results := make(chan *walkResult, 4*workers)
+	written := make(chan struct{})
+	go func() {
+		for r := range results {
+			<-r.done
+			outputMu.Lock()
+			out.Write(r.out.Bytes())
+			if r.err != nil {
+				report(r.err)
+			}
+			outputMu.Unlock()
+		}
+		close(written)
+	}()
+
+	walkGoFiles(root, func(filename string) {
+		r := &walkResult{filename: filename, done: make(chan struct{})}
+		results <- r
+		files <- r
+	}, nil)
+	close(files)
+	close(results)
+	wg.Wait()
+	<-written
+}
+
+// walkGoFiles calls fn for each Go file in the tree rooted at root
+// that is not skipped according to the -skip and -gitignore flags.
+// If dirFn is non-nil, it is called for each directory not skipped.
+func walkGoFiles(root string, fn, dirFn func(filename string)) {
+	rules := make(map[string][]ignoreRule) // rules in effect in each directory
+	filepath.WalkDir(root, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			outputMu.Lock()
+			report(err)
+			outputMu.Unlock()
+			return nil
+		}
+		var dirRules []ignoreRule
+		if filename == root {
+			dirRules = rootRules(root)
+		} else {
+			dirRules = rules[filepath.Dir(filename)]
+			if ignored(dirRules, filename, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if d.IsDir() {
+			if *gitignore {
+				dirRules = append(dirRules[:len(dirRules):len(dirRules)], readIgnoreFile(filename)...)
+			}
+			rules[filename] = dirRules
+			if dirFn != nil {
+				dirFn(filename)
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil && isGoFile(info) {
+			fn(filename)
+		}
+		return nil
+	})
+}
+
+// An ignoreRule is a pattern of a .gitignore file
+// or of the -skip flag.
+type ignoreRule struct {
+	dir      string   // directory to which the pattern is relative
+	prefix   string   // slash-separated path of dir relative to the directory of the pattern, if different
+	elems    []string // slash-separated elements of the pattern
+	anchored bool     // pattern must match the path relative to dir, not just its base name
+	dirOnly  bool     // pattern matches only directories
+	negate   bool     // pattern re-includes a previously ignored path
+}
+
+// parseIgnoreRule parses a line of a .gitignore file in dir.
+// It returns false for blank lines and comments.
+func parseIgnoreRule(dir, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || line[0] == '#' {
+		return ignoreRule{}, false
+	}
+	r := ignoreRule{dir: dir}
+	if line[0] == '!' {
+		r.negate = true
+		line = line[1:]
+	} else if line[0] == '\\' {
+		line = line[1:] // escaped '#' or '!'
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	r.elems = strings.Split(line, "/")
+	return r, true
+}
+
+// match reports whether the rule matches the file.
+func (r *ignoreRule) match(filename string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.elems[0], filepath.Base(filename))
+		return ok
+	}
+	rel, err := filepath.Rel(r.dir, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return matchElems(r.elems, strings.Split(path.Join(r.prefix, filepath.ToSlash(rel)), "/"))
+}
+
+// matchElems reports whether the path elements match the pattern
+// elements, in which "**" matches any number of path elements.
+func matchElems(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchElems(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+// ignored reports whether the file is ignored by the rules,
+// the last matching one of which takes precedence.
+func ignored(rules []ignoreRule, filename string, isDir bool) bool {
+	if *gitignore && isDir && filepath.Base(filename) == ".git" {
+		return true
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].match(filename, isDir) {
+			return !rules[i].negate
+		}
+	}
+	return false
+}
+
+// readIgnoreFile returns the rules of the .gitignore file in dir, if any.
+func readIgnoreFile(dir string) []ignoreRule {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if r, ok := parseIgnoreRule(dir, sc.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// rootRules returns the rules in effect in the directory root of a
+// walk: those of the -skip flag and of the .gitignore files of its
+// parent directories within the same git repository.
+func rootRules(root string) []ignoreRule {
+	var rules []ignoreRule
+	for _, pattern := range strings.Split(*skip, ",") {
+		if r, ok := parseIgnoreRule(root, strings.TrimSpace(pattern)); ok {
+			rules = append(rules, r)
+		}
+	}
+	if !*gitignore {
+		return rules
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return rules
+	}
+	var dirs []string // parent directories within the repository, innermost first
+	if _, err := os.Stat(filepath.Join(abs, ".git")); err != nil {
+		for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+			dirs = append(dirs, dir)
+			if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+				break
+			}
+			if filepath.Dir(dir) == dir {
+				dirs = nil // not within a repository
+				break
+			}
+		}
+	}
+	var parentRules []ignoreRule
+	for i := len(dirs) - 1; i >= 0; i-- {
+		prefix, err := filepath.Rel(dirs[i], abs)
+		if err != nil {
+			continue
+		}
+		for _, r := range readIgnoreFile(dirs[i]) {
+			// Express the rule relative to root,
+			// which may be a relative path.
+			r.dir, r.prefix = root, filepath.ToSlash(prefix)
+			parentRules = append(parentRules, r)
+		}
+	}
+	return append(parentRules, rules...)
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+// writeTree creates the files, keyed by slash-separated name,
+// in a new directory and returns the directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestWalkGoFiles(t *testing.T) {
+	defer func(old string) { *skip = old }(*skip)
+	*skip = "skipped.go,/b/gen"
+
+	dir := writeTree(t, map[string]string{
+		".gitignore":       "*_ignored.go\n# comment\nout/\n!keep_ignored.go\n",
+		"a.go":             "",
+		"a_ignored.go":     "",
+		"keep_ignored.go":  "",
+		"skipped.go":       "",
+		"x.txt":            "",
+		".hidden.go":       "",
+		"out/o.go":         "",
+		"b/b.go":           "",
+		"b/gen/g.go":       "",
+		"c/gen/g.go":       "",
+		"c/.gitignore":     "/d/\n**/deep.go\n",
+		"c/d/d.go":         "",
+		"c/e/d/d.go":       "",
+		"c/e/f/deep.go":    "",
+		".git/hooks/h.go":  "",
+		"c/sub_ignored.go": "",
+	})
+	var got []string
+	walkGoFiles(dir, func(filename string) {
+		rel, _ := filepath.Rel(dir, filename)
+		got = append(got, filepath.ToSlash(rel))
+	}, nil)
+	want := []string{
+		"a.go",
+		"b/b.go",
+		"c/e/d/d.go",
+		"c/gen/g.go",
+		"keep_ignored.go",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("walkGoFiles visited %q, want %q", got, want)
+	}
+}
+
+func TestWalkDirOrder(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+	defer func(old bool) { *list = old }(*list)
+	*list = true
+
+	// Each file is misformatted, so that -l lists it.
+	files := make(map[string]string)
+	var want []string
+	for i := range 30 {
+		name := fmt.Sprintf("d%d/f%02d.go", i%3, i)
+		files[name] = "package p\nvar  x = 1\n"
+	}
+	dir := writeTree(t, files)
+	for name := range files {
+		want = append(want, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	sort.Strings(want)
+
+	var buf bytes.Buffer
+	walkDir(dir, &buf)
+	if got := strings.Fields(buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("walkDir listed:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+var watch = flag.Bool("watch", false, "after processing the files, watch them for changes and process each file again when it changes; requires -w, -l, or -d")
+
+// pollInterval is the interval at which watchFiles checks for changes.
+const pollInterval = 500 * time.Millisecond
+
+// A fileStamp records the state of a file, to detect changes.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stat returns the stamp of the named file,
+// or false if it does not exist.
+func stat(filename string) (fileStamp, bool) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return fileStamp{}, false
+	}
+	return fileStamp{fi.ModTime(), fi.Size()}, true
+}
+
+// A watchState records the state of the watched files.
+//
+// Walking the directory trees on every poll would be costly for large
+// trees, so the result of a walk is cached: the Go files it found are
+// merely checked for changes, and the trees are walked again only
+// when one of their directories changes, as it does when a file is
+// created, removed, or renamed, or when one of their .gitignore files
+// changes.
+type watchState struct {
+	files map[string]fileStamp // the watched Go files
+	dirs  map[string]fileStamp // the walked directories, and their .gitignore files
+}
+
+// watchFiles processes the Go files among paths, and within the
+// directory trees among them, whenever they are created or modified,
+// until the process is interrupted. It reuses the state of
+// options.Env, so that finding packages remains fast.
+func watchFiles(paths []string) {
+	w := snapshot(paths)
+	lastScan := time.Now()
+	for {
+		time.Sleep(pollInterval)
+		next := w.update(paths)
+		changed := changedFiles(w.files, next.files)
+		if len(changed) > 0 && daemonClient == nil && time.Since(lastScan) > rescanInterval {
+			// Forget the directories scanned so far,
+			// so that new packages may be found.
+			if r, err := options.Env.GetResolver(); err == nil {
+				options.Env.UpdateResolver(r.ClearForNewScan())
+			}
+			lastScan = time.Now()
+		}
+		for _, filename := range changed {
+			if err := processFile(filename, nil, os.Stdout, multipleArg); err != nil {
+				report(err)
+			}
+			// Don't process the file again merely because we wrote it.
+			if stamp, ok := stat(filename); ok {
+				next.files[filename] = stamp
+			}
+		}
+		w = next
+	}
+}
+
+// changedFiles returns, in order, the files of current
+// that are not in old or whose stamps differ.
+func changedFiles(old, current map[string]fileStamp) []string {
+	var changed []string
+	for filename, stamp := range current {
+		if prev, ok := old[filename]; !ok || prev != stamp {
+			changed = append(changed, filename)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// snapshot walks paths and returns the current state of the Go files
+// among them and within the directory trees among them.
+func snapshot(paths []string) *watchState {
+	w := &watchState{
+		files: make(map[string]fileStamp),
+		dirs:  make(map[string]fileStamp),
+	}
+	addFile := func(filename string) {
+		if stamp, ok := stat(filename); ok {
+			w.files[filename] = stamp
+		}
+	}
+	addDir := func(dir string) {
+		if stamp, ok := stat(dir); ok {
+			w.dirs[dir] = stamp
+		}
+		ignore := filepath.Join(dir, ".gitignore")
+		if stamp, ok := stat(ignore); ok {
+			w.dirs[ignore] = stamp
+		}
+	}
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err != nil {
+			continue
+		} else if fi.IsDir() {
+			walkGoFiles(path, addFile, addDir)
+		} else {
+			addFile(path)
+		}
+	}
+	return w
+}
+
+// update returns the current state of the Go files among paths and
+// within the directory trees among them, walking the trees again only
+// if their directories have changed since w was recorded.
+func (w *watchState) update(paths []string) *watchState {
+	for name, old := range w.dirs {
+		if stamp, ok := stat(name); !ok || stamp != old {
+			return snapshot(paths)
+		}
+	}
+	next := &watchState{
+		files: make(map[string]fileStamp, len(w.files)),
+		dirs:  w.dirs,
+	}
+	for filename := range w.files {
+		if stamp, ok := stat(filename); ok {
+			next.files[filename] = stamp
+		}
+	}
+	for _, path := range paths {
+		if _, ok := w.dirs[path]; ok {
+			continue
+		}
+		// An argument that did not exist may have been created.
+		if isDir(path) {
+			return snapshot(paths)
+		}
+		if stamp, ok := stat(path); ok {
+			next.files[path] = stamp
+		}
+	}
+	return next
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchState(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.go":   "package a\n",
+		"b/b.go": "package b\n",
+	})
+	join := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+	// touch sets the modification time of the file to a time
+	// distinct from those of earlier calls, as the resolution of
+	// file times may be coarse.
+	clock := time.Now().Add(-time.Hour)
+	touch := func(name string) {
+		clock = clock.Add(time.Second)
+		if err := os.Chtimes(join(name), clock, clock); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch("b")
+
+	w := snapshot([]string{dir})
+	if got, want := changedFiles(nil, w.files), []string{join("a.go"), join("b/b.go")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot found %q, want %q", got, want)
+	}
+
+	// A modified file is detected without walking the tree.
+	touch("b/b.go")
+	next := w.update([]string{dir})
+	if got, want := changedFiles(w.files, next.files), []string{join("b/b.go")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after modification, changed files are %q, want %q", got, want)
+	}
+	w = next
+
+	// The tree is not walked again while its directories are unchanged,
+	// so a file whose creation is concealed by restoring the time of
+	// its directory goes unnoticed.
+	fi, err := os.Stat(join("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(join("b/c.go"), []byte("package b\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(join("b"), fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	next = w.update([]string{dir})
+	if got := changedFiles(w.files, next.files); len(got) > 0 {
+		t.Errorf("with unchanged directories, changed files are %q, want none", got)
+	}
+	w = next
+
+	// Once the directory changes, the tree is walked again.
+	touch("b")
+	next = w.update([]string{dir})
+	if got, want := changedFiles(w.files, next.files), []string{join("b/c.go")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after directory change, changed files are %q, want %q", got, want)
+	}
+	w = next
+
+	// So it is when a .gitignore file changes.
+	if err := os.WriteFile(join(".gitignore"), []byte("c.go\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	touch(".")
+	w = w.update([]string{dir})
+	if _, ok := w.files[join("b/c.go")]; ok {
+		t.Errorf("b/c.go is watched despite .gitignore")
+	}
+	if err := os.WriteFile(join(".gitignore"), []byte("a.go\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	touch(".gitignore")
+	if _, ok := w.update([]string{dir}).files[join("b/c.go")]; !ok {
+		t.Errorf("after .gitignore change, b/c.go is not watched")
+	}
+}
@@ -6,7 +6,7 @@
 //
 // Usage:
 //
-//	gonew srcmod[@version] [dstmod [dir]]
+//	gonew srcmod[@version][#subdir] [dstmod [dir]]
 //
 // Gonew makes a copy of the srcmod module, changing its module path to dstmod.
 // It writes that new module to a new directory named by dir.
@@ -30,11 +30,56 @@
 // into ./quote:
 //
 //	gonew rsc.io/quote
+//
+// # Versions and subdirectories
+//
+// The version may be anything accepted by 'go get', such as a semantic version,
+// a branch or tag name, or a commit hash, and defaults to latest.
+// Gonew fetches the module using 'go mod download', so it is cached in the module
+// cache like any other dependency.
+//
+// A repository may hold several templates in subdirectories of one module.
+// To copy just the subdirectory subdir of srcmod, append #subdir:
+//
+//	gonew example.com/templates@v1.2.3#cmd/server your.domain/myserver
+//
+// The new module contains only the files of that subdirectory, with import paths
+// beginning srcmod/subdir rewritten to begin with dstmod, and the go.mod file of
+// srcmod if the subdirectory has none of its own. If dstmod is omitted, it is
+// srcmod/subdir.
+//
+// # Templates
+//
+// A template module may contain a file gonew.json in its root directory
+// (or in the subdirectory containing the template),
+// which gonew reads but does not copy. It declares placeholders, which are
+// replaced throughout the contents and paths of the copied files, and commands
+// to run in the new module's directory after it is written:
+//
+//	{
+//		"Placeholders": [
+//			{"Name": "PROJECT", "Description": "project name"},
+//			{"Name": "LICENSE", "Description": "license", "Default": "BSD-3-Clause"}
+//		],
+//		"Run": [
+//			["go", "generate", "./..."]
+//		]
+//	}
+//
+// Each occurrence of {{PROJECT}} in a file or path is replaced by the value of
+// the PROJECT placeholder, which is given by the -var flag:
+//
+//	gonew -var PROJECT=myprog -var LICENSE=MIT example.com/template your.domain/myprog
+//
+// A placeholder without a default value must be given a value. The values
+// are also substituted in the commands, which gonew runs only if given the
+// -run flag, as they may do anything.
 package main
 
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"go/parser"
@@ -53,8 +98,24 @@ import (
 	"golang.org/x/tools/internal/edit"
 )
 
+var (
+	vars    = make(map[string]string) // values of -var flags
+	runCmds = flag.Bool("run", false, "run the commands listed by the template after copying it")
+)
+
+func init() {
+	flag.Func("var", "set the template placeholder `name=value`; may be repeated", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("want name=value")
+		}
+		vars[name] = value
+		return nil
+	})
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: gonew srcmod[@version] [dstmod [dir]]\n")
+	fmt.Fprintf(os.Stderr, "usage: gonew [-var name=value]... [-run] srcmod[@version][#subdir] [dstmod [dir]]\n")
 	fmt.Fprintf(os.Stderr, "See https://pkg.go.dev/golang.org/x/tools/cmd/gonew.\n")
 	os.Exit(2)
 }
@@ -70,7 +131,7 @@ func main() {
 		usage()
 	}
 
-	srcMod := args[0]
+	srcMod, subdir, _ := strings.Cut(args[0], "#")
 	srcModVers := srcMod
 	if !strings.Contains(srcModVers, "@") {
 		srcModVers += "@latest"
@@ -80,7 +141,19 @@ func main() {
 		log.Fatalf("invalid source module name: %v", err)
 	}
 
-	dstMod := srcMod
+	// srcPath is the import path of the template's root directory.
+	srcPath := srcMod
+	if subdir != "" {
+		if subdir != path.Clean(subdir) || subdir == "." || strings.HasPrefix(subdir, "../") {
+			log.Fatalf("invalid source subdirectory %q", subdir)
+		}
+		srcPath = srcMod + "/" + subdir
+		if err := module.CheckImportPath(srcPath); err != nil {
+			log.Fatalf("invalid source subdirectory: %v", err)
+		}
+	}
+
+	dstMod := srcPath
 	if len(args) >= 2 {
 		dstMod = args[1]
 		if err := module.CheckPath(dstMod); err != nil {
@@ -111,12 +184,27 @@ func main() {
 	}
 
 	var info struct {
-		Dir string
+		Dir   string
+		GoMod string
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
 		log.Fatalf("go mod download -json %s: invalid JSON output: %v\n%s%s", srcMod, err, stderr.Bytes(), stdout.Bytes())
 	}
 
+	root := filepath.Join(info.Dir, filepath.FromSlash(subdir))
+	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+		log.Fatalf("module %s has no subdirectory %s", srcModVers, subdir)
+	}
+
+	config, err := readConfig(root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	placeholders, err := config.replacer(vars)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	if needMkdir {
 		if err := os.MkdirAll(dir, 0777); err != nil {
 			log.Fatal(err)
@@ -124,14 +212,19 @@ func main() {
 	}
 
 	// Copy from module cache into new directory, making edits as needed.
-	filepath.WalkDir(info.Dir, func(src string, d fs.DirEntry, err error) error {
+	haveGoMod := false
+	filepath.WalkDir(root, func(src string, d fs.DirEntry, err error) error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		rel, err := filepath.Rel(info.Dir, src)
+		rel, err := filepath.Rel(root, src)
 		if err != nil {
 			log.Fatal(err)
 		}
+		if rel == configFile {
+			return nil
+		}
+		rel = placeholders.Replace(rel)
 		dst := filepath.Join(dir, rel)
 		if d.IsDir() {
 			if err := os.MkdirAll(dst, 0777); err != nil {
@@ -145,12 +238,15 @@ func main() {
 			log.Fatal(err)
 		}
 
+		data = []byte(placeholders.Replace(string(data)))
+
 		isRoot := !strings.Contains(rel, string(filepath.Separator))
 		if strings.HasSuffix(rel, ".go") {
-			data = fixGo(data, rel, srcMod, dstMod, isRoot)
+			data = fixGo(data, rel, srcPath, dstMod, isRoot)
 		}
 		if rel == "go.mod" {
 			data = fixGoMod(data, srcMod, dstMod)
+			haveGoMod = true
 		}
 
 		if err := os.WriteFile(dst, data, 0666); err != nil {
@@ -159,9 +255,116 @@ func main() {
 		return nil
 	})
 
+	// A template in a subdirectory uses the go.mod file of its module,
+	// so that the new module has the same requirements.
+	if subdir != "" && !haveGoMod {
+		data, err := os.ReadFile(info.GoMod)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), fixGoMod(data, srcMod, dstMod), 0666); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(config.Run) > 0 {
+		if !*runCmds {
+			log.Printf("not running the commands listed by the template; use -run to run:")
+		}
+		for _, args := range config.Run {
+			for i, arg := range args {
+				args[i] = placeholders.Replace(arg)
+			}
+			if !*runCmds {
+				log.Printf("\t%s", strings.Join(args, " "))
+				continue
+			}
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Dir = dir
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				log.Fatalf("%s: %v", strings.Join(args, " "), err)
+			}
+		}
+	}
+
 	log.Printf("initialized %s in %s", dstMod, dir)
 }
 
+// configFile is the name of the file in the root directory of
+// a template module that holds its configuration.
+const configFile = "gonew.json"
+
+// A templateConfig is the configuration of a template module.
+type templateConfig struct {
+	// Placeholders are replaced, in the form {{Name}},
+	// in the contents and paths of the template's files.
+	Placeholders []struct {
+		Name        string
+		Description string
+		Default     *string // nil if a value is required
+	}
+
+	// Run lists commands to run in the new module's
+	// directory after it is written.
+	Run [][]string
+}
+
+// readConfig reads the configuration of the template module in dir.
+// A template without a configuration file has an empty configuration.
+func readConfig(dir string) (*templateConfig, error) {
+	config := new(templateConfig)
+	data, err := os.ReadFile(filepath.Join(dir, configFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", configFile, err)
+	}
+	for _, p := range config.Placeholders {
+		if !token.IsIdentifier(p.Name) {
+			return nil, fmt.Errorf("%s: invalid placeholder name %q", configFile, p.Name)
+		}
+	}
+	for _, args := range config.Run {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s: empty command", configFile)
+		}
+	}
+	return config, nil
+}
+
+// replacer returns a Replacer that replaces the placeholders declared
+// by the configuration with the given values, or their defaults.
+func (config *templateConfig) replacer(values map[string]string) (*strings.Replacer, error) {
+	var oldnew []string
+	declared := make(map[string]bool)
+	for _, p := range config.Placeholders {
+		declared[p.Name] = true
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Default == nil {
+				desc := ""
+				if p.Description != "" {
+					desc = " (" + p.Description + ")"
+				}
+				return nil, fmt.Errorf("missing value for placeholder %s%s; use -var %s=value", p.Name, desc, p.Name)
+			}
+			value = *p.Default
+		}
+		oldnew = append(oldnew, "{{"+p.Name+"}}", value)
+	}
+	for name := range values {
+		if !declared[name] {
+			return nil, fmt.Errorf("template declares no placeholder %s", name)
+		}
+	}
+	return strings.NewReplacer(oldnew...), nil
+}
+
 // fixGo rewrites the Go source in data to replace srcMod with dstMod.
 // isRoot indicates whether the file is in the root directory of the module,
 // in which case we also update the package name.
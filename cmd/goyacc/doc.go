@@ -58,6 +58,41 @@ or has consumed the token returned by the most recent call to Lex),
 Lookahead returns -1. Calling Lookahead is equivalent to reading
 yychar from within in a grammar action.
 
+A lexer may also implement yyErrorReporter, in which case the parser
+reports syntax errors by calling its SyntaxError method in place of
+Error, passing a *yySyntaxError that records the unexpected token,
+the tokens expected in its place, if known, and the message that
+would have been passed to Error. If the lexer implements yyPosLexer,
+whose Pos method returns the position of the token most recently
+returned by Lex, the error also records the position of the
+unexpected token.
+
+	type yyErrorReporter interface {
+		yyLexer
+		SyntaxError(err *yySyntaxError)
+	}
+
+	type yyPosLexer interface {
+		yyLexer
+		Pos() yyPos
+	}
+
+By default, the generated code has two package-level variables that
+control the behavior of every parser: yyDebug, the verbosity of the
+debugging output, and yyErrorVerbose, which causes syntax errors to
+name the unexpected and expected tokens. With the -reentrant flag,
+these are instead the fields Debug and ErrorVerbose of the parser,
+yyNewParser returns a *yyParserImpl so that they can be set, and
+yyErrorMessage is a method of yyParserImpl. The generated code then
+has no mutable package-level state, so distinct parsers may be used
+concurrently with different settings.
+
+As in Bison, the %expect N declaration states that the grammar has
+exactly N shift/reduce conflicts, and %expect-rr N that it has N
+reduce/reduce conflicts. If either is present, goyacc does not report
+the expected conflicts, but exits with an error if the numbers differ,
+taking an absent declaration to expect no conflicts of its kind.
+
 Multiple grammars compiled into a single program should be placed in
 distinct packages.  If that is impossible, the "-p prefix" flag to
 goyacc sets the prefix, by default yy, that begins the names of
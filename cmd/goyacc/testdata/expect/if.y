@@ -0,0 +1,46 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This grammar has the classic "dangling else" ambiguity, which
+// causes one shift/reduce conflict. The %expect declaration states
+// that it is expected, so goyacc does not report it. The conflict is
+// resolved by shifting, so that an else belongs to the nearest if.
+
+%{
+
+package main
+
+%}
+
+%union {
+	s string
+}
+
+%type	<s>	stmt
+
+%token	IF ELSE X
+
+%expect 1
+
+%%
+
+top:
+	stmt
+	{
+		yylex.(*lexer).result = $1
+	}
+
+stmt:
+	X
+	{
+		$$ = "x"
+	}
+|	IF stmt
+	{
+		$$ = "if(" + $2 + ")"
+	}
+|	IF stmt ELSE stmt
+	{
+		$$ = "if(" + $2 + " else " + $4 + ")"
+	}
@@ -0,0 +1,55 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file holds the go generate command to run yacc on the grammar in if.y.
+// To build if:
+//	% go generate
+//	% go build
+
+//go:generate goyacc -o if.go if.y
+
+// If demonstrates the %expect declaration, in a grammar with an
+// expected shift/reduce conflict.
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A lexer returns the tokens of the space-separated words of s.
+type lexer struct {
+	words  []string
+	result string // the statement, set by the grammar
+}
+
+func (l *lexer) Lex(lval *yySymType) int {
+	if len(l.words) == 0 {
+		return 0
+	}
+	w := l.words[0]
+	l.words = l.words[1:]
+	switch w {
+	case "if":
+		return IF
+	case "else":
+		return ELSE
+	case "x":
+		return X
+	}
+	return int(w[0])
+}
+
+func (l *lexer) Error(s string) {
+	fmt.Println(s)
+}
+
+func main() {
+	for _, input := range []string{"if x", "if if x else x", "if x else if x else x"} {
+		l := &lexer{words: strings.Fields(input)}
+		if yyNewParser().Parse(l) == 0 {
+			fmt.Printf("%s: %s\n", input, l.result)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file holds the go generate command to run yacc on the grammar in sum.y.
+// To build sum:
+//	% go generate
+//	% go build
+
+//go:generate goyacc -reentrant -o sum.go -p sum sum.y
+
+// Sum demonstrates parsers generated with the -reentrant flag, which
+// may be used concurrently with different settings.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// A lexer returns the numbers and other characters of s.
+type lexer struct {
+	s      string
+	result int    // the sum, set by the grammar
+	err    string // the syntax error, if any
+}
+
+func (l *lexer) Lex(lval *sumSymType) int {
+	for len(l.s) > 0 && l.s[0] == ' ' {
+		l.s = l.s[1:]
+	}
+	if len(l.s) == 0 {
+		return 0
+	}
+	n := 0
+	for n < len(l.s) && '0' <= l.s[n] && l.s[n] <= '9' {
+		n++
+	}
+	if n == 0 {
+		c := l.s[0]
+		l.s = l.s[1:]
+		return int(c)
+	}
+	lval.num, _ = strconv.Atoi(l.s[:n])
+	l.s = l.s[n:]
+	return NUM
+}
+
+func (l *lexer) Error(s string) {
+	l.err = s
+}
+
+// sum returns the sum in input, or the syntax error,
+// reported verbosely if verbose is set.
+func sum(input string, verbose bool) string {
+	p := sumNewParser()
+	p.ErrorVerbose = verbose
+	l := &lexer{s: input}
+	if p.Parse(l) != 0 {
+		return l.err
+	}
+	return strconv.Itoa(l.result)
+}
+
+func main() {
+	inputs := []string{"1 + 2 + 3", "1 + + 2", "1 + + 2"}
+	results := make([]string, len(inputs))
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = sum(input, i == 1)
+		}()
+	}
+	wg.Wait()
+	for i, input := range inputs {
+		fmt.Printf("%s: %s\n", input, results[i])
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This grammar, of sums of numbers, is compiled with the -reentrant
+// flag, so each parser has its own ErrorVerbose setting.
+
+%{
+
+package main
+
+%}
+
+%union {
+	num int
+}
+
+%type	<num>	expr
+
+%token	<num>	NUM
+
+%left '+'
+
+%%
+
+top:
+	expr
+	{
+		sumlex.(*lexer).result = $1
+	}
+
+expr:
+	NUM
+|	expr '+' expr
+	{
+		$$ = $1 + $3
+	}
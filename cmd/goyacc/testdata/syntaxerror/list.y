@@ -0,0 +1,39 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This grammar, of comma-separated lists of names, is used with a
+// lexer that reports the positions of tokens and receives syntax
+// errors as values.
+
+%{
+
+package main
+
+%}
+
+%union {
+	names []string
+}
+
+%type	<names>	list
+
+%token	NAME
+
+%%
+
+top:
+	'[' list ']'
+	{
+		yylex.(*lexer).result = $2
+	}
+
+list:
+	NAME
+	{
+		$$ = []string{yylex.(*lexer).text}
+	}
+|	list ',' NAME
+	{
+		$$ = append($1, yylex.(*lexer).text)
+	}
@@ -0,0 +1,91 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file holds the go generate command to run yacc on the grammar in list.y.
+// To build list:
+//	% go generate
+//	% go build
+
+//go:generate goyacc -o list.go list.y
+
+// List demonstrates a lexer that implements yyErrorReporter and
+// yyPosLexer, so that it receives syntax errors as *yySyntaxError
+// values recording the position of the unexpected token.
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A lexer returns the tokens of s, which are names
+// and single characters, and tracks their positions.
+type lexer struct {
+	s         string
+	line, col int      // position of the next character
+	pos       yyPos    // position of the last token
+	text      string   // text of the last token
+	result    []string // the list, set by the grammar
+	err       *yySyntaxError
+}
+
+func (l *lexer) Lex(lval *yySymType) int {
+	for len(l.s) > 0 && (l.s[0] == ' ' || l.s[0] == '\n') {
+		l.next(1)
+	}
+	l.pos = yyPos{Line: l.line, Column: l.col}
+	if len(l.s) == 0 {
+		return 0
+	}
+	n := 0
+	for n < len(l.s) && 'a' <= l.s[n] && l.s[n] <= 'z' {
+		n++
+	}
+	if n == 0 {
+		c := l.s[0]
+		l.next(1)
+		return int(c)
+	}
+	l.text = l.s[:n]
+	l.next(n)
+	return NAME
+}
+
+// next advances past the first n characters of l.s.
+func (l *lexer) next(n int) {
+	for _, c := range l.s[:n] {
+		if c == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.s = l.s[n:]
+}
+
+func (l *lexer) Pos() yyPos {
+	return l.pos
+}
+
+func (l *lexer) Error(s string) {
+	panic("Error called in place of SyntaxError: " + s)
+}
+
+func (l *lexer) SyntaxError(err *yySyntaxError) {
+	l.err = err
+}
+
+func main() {
+	for _, input := range []string{"[a, b]", "[a,\n b c]", "[a,\n ,]"} {
+		l := &lexer{s: input, line: 1, col: 1}
+		if yyNewParser().Parse(l) != 0 {
+			err := l.err
+			fmt.Printf("%q: %d:%d: %v: unexpected %s, expecting %s\n", input,
+				err.Pos.Line, err.Pos.Column, err, err.Token, strings.Join(err.Expected, " or "))
+		} else {
+			fmt.Printf("%q: %q\n", input, l.result)
+		}
+	}
+}
@@ -125,6 +125,8 @@ const (
 	TYPENAME
 	UNION
 	ERROR
+	EXPECT
+	EXPECTRR
 )
 
 const ENDFILE = 0
@@ -161,11 +163,14 @@ var vflag string  // -v [y.output]	- y.output file
 var lflag bool    // -l			- disable line directives
 var prefix string // name prefix for identifiers, default yy
 
+var reentrant bool // -reentrant	- no package-level variables in the parser
+
 func init() {
 	flag.StringVar(&oflag, "o", "y.go", "parser output")
 	flag.StringVar(&prefix, "p", "yy", "name prefix to use in generated code")
 	flag.StringVar(&vflag, "v", "y.output", "create parsing tables")
 	flag.BoolVar(&lflag, "l", false, "disable line directives")
+	flag.BoolVar(&reentrant, "reentrant", false, "keep all parser state, including Debug and ErrorVerbose, in the parser")
 }
 
 var initialstacksize = 16
@@ -275,6 +280,10 @@ var zzexcp = 0
 var zzclose = 0
 var zzrrconf = 0
 var zzsrconf = 0
+
+// expected numbers of conflicts, from %expect and %expect-rr
+var expectsr = -1
+var expectrr = -1
 var zzstate = 0
 
 // optimizer arrays
@@ -322,6 +331,8 @@ var resrv = []Resrv{
 	{"union", UNION},
 	{"struct", UNION},
 	{"error", ERROR},
+	{"expect", EXPECT},
+	{"expect-rr", EXPECTRR},
 }
 
 type Error struct {
@@ -366,9 +377,32 @@ func main() {
 
 	others()
 
+	if !conflictsExpected() {
+		exit(1)
+	}
 	exit(0)
 }
 
+// conflictsExpected reports whether the numbers of conflicts are those
+// declared by %expect and %expect-rr, reporting any difference. As in
+// Bison, either declaration implies that the other kind of conflict
+// is not expected unless it too is declared.
+func conflictsExpected() bool {
+	if expectsr < 0 && expectrr < 0 {
+		return true
+	}
+	ok := true
+	if n := max(expectsr, 0); zzsrconf != n {
+		fmt.Fprintf(stderr, "yacc: expected %v shift/reduce conflicts, found %v\n", n, zzsrconf)
+		ok = false
+	}
+	if n := max(expectrr, 0); zzrrconf != n {
+		fmt.Fprintf(stderr, "yacc: expected %v reduce/reduce conflicts, found %v\n", n, zzrrconf)
+		ok = false
+	}
+	return ok
+}
+
 func setup() {
 	var j, ty int
 
@@ -384,7 +418,11 @@ func setup() {
 		fmt.Fprintf(stderr, "yacc: stack size too small\n")
 		usage()
 	}
-	yaccpar = strings.Replace(yaccpartext, "$$", prefix, -1)
+	yaccpar = yaccpartext
+	if reentrant {
+		yaccpar = reentrantReplacer.Replace(yaccpar)
+	}
+	yaccpar = strings.Replace(yaccpar, "$$", prefix, -1)
 	openup()
 
 	fmt.Fprintf(ftable, "// Code generated by goyacc %s. DO NOT EDIT.\n", strings.Join(os.Args[1:], " "))
@@ -438,6 +476,17 @@ outer:
 			}
 			errors = append(errors, Error{lno, tokens, tokname})
 
+		case EXPECT, EXPECTRR:
+			n := gettok()
+			if n != NUMBER {
+				errorf("bad %%expect construction")
+			}
+			if t == EXPECT {
+				expectsr = numbval
+			} else {
+				expectrr = numbval
+			}
+
 		case TYPEDEF:
 			t = gettok()
 			if t != TYPENAME {
@@ -929,6 +978,15 @@ func gettok() int {
 		}
 
 		getword(c)
+		if tokname == "expect" {
+			// %expect-rr
+			if c = getrune(finput); c == '-' {
+				getword(getrune(finput))
+				tokname = "expect-" + tokname
+			} else {
+				ungetrune(finput, c)
+			}
+		}
 		// find a reserved word
 		for i := range resrv {
 			if tokname == resrv[i].name {
@@ -3014,7 +3072,7 @@ func summary() {
 		fmt.Fprintf(foutput, "%v goto entries\n", zzgoent)
 		fmt.Fprintf(foutput, "%v entries saved by goto default\n", zzgobest)
 	}
-	if zzsrconf != 0 || zzrrconf != 0 {
+	if (zzsrconf != 0 || zzrrconf != 0) && (expectsr < 0 && expectrr < 0) {
 		fmt.Printf("\nconflicts: ")
 		if zzsrconf != 0 {
 			fmt.Printf("%v shift/reduce", zzsrconf)
@@ -3220,6 +3278,24 @@ func gofmt() {
 }
 
 var yaccpar string // will be processed version of yaccpartext: s/$$/prefix/g
+
+// reentrantReplacer rewrites yaccpartext for the -reentrant flag,
+// replacing the package-level variables by fields of the parser,
+// and the functions that use them by methods.
+var reentrantReplacer = strings.NewReplacer(
+	"var (\n\t$$Debug        = 0\n\t$$ErrorVerbose = false\n)\n\n", "",
+	"\tchar  int\n}", "\tchar  int\n\n\tDebug        int  // debugging verbosity, from 0 to 4\n\tErrorVerbose bool // report the unexpected and expected tokens of syntax errors\n}",
+	"func $$NewParser() $$Parser {", "func $$NewParser() *$$ParserImpl {",
+	"func $$ErrorMessage(", "func ($$rcvr *$$ParserImpl) ErrorMessage(",
+	"func $$reportError(", "func ($$rcvr *$$ParserImpl) reportError(",
+	"func $$lex1(", "func ($$rcvr *$$ParserImpl) lex1(",
+	"$$ErrorMessage(", "$$rcvr.ErrorMessage(",
+	"$$reportError(", "$$rcvr.reportError(",
+	"$$lex1(", "$$rcvr.lex1(",
+	"$$Debug", "$$rcvr.Debug",
+	"$$ErrorVerbose", "$$rcvr.ErrorVerbose",
+)
+
 var yaccpartext = `
 /*	parser for yacc output	*/
 
@@ -3273,8 +3349,6 @@ func $$Statname(s int) string {
 }
 
 func $$ErrorMessage(state, lookAhead int) string {
-	const TOKSTART = 4
-
 	if !$$ErrorVerbose {
 		return "syntax error"
 	}
@@ -3288,15 +3362,33 @@ func $$ErrorMessage(state, lookAhead int) string {
 	res := "syntax error: unexpected " + $$Tokname(lookAhead)
 
 	// To match Bison, suggest at most four expected tokens.
-	expected := make([]int, 0, 4)
+	expected := $$ExpectedTokens(state)
+	if len(expected) > 4 {
+		return res
+	}
+	for i, tok := range expected {
+		if i == 0 {
+			res += ", expecting "
+		} else {
+			res += " or "
+		}
+		res += $$Tokname(tok)
+	}
+	return res
+}
+
+// $$ExpectedTokens returns the tokens that the parser can shift or
+// accept in the given state, or nil if its default action is to
+// accept or reduce.
+func $$ExpectedTokens(state int) []int {
+	const TOKSTART = 4
+
+	var expected []int
 
 	// Look for shiftable tokens.
 	base := int($$Pact[state])
 	for tok := TOKSTART; tok-1 < len($$Toknames); tok++ {
 		if n := base + tok; n >= 0 && n < $$Last && int($$Chk[int($$Act[n])]) == tok {
-			if len(expected) == cap(expected) {
-				return res
-			}
 			expected = append(expected, tok)
 		}
 	}
@@ -3313,27 +3405,63 @@ func $$ErrorMessage(state, lookAhead int) string {
 			if tok < TOKSTART || $$Exca[i+1] == 0 {
 				continue
 			}
-			if len(expected) == cap(expected) {
-				return res
-			}
 			expected = append(expected, tok)
 		}
 
 		// If the default action is to accept or reduce, give up.
 		if $$Exca[i+1] != 0 {
-			return res
+			return nil
 		}
 	}
+	return expected
+}
 
-	for i, tok := range expected {
-		if i == 0 {
-			res += ", expecting "
-		} else {
-			res += " or "
-		}
-		res += $$Tokname(tok)
+// A $$SyntaxError describes a syntax error detected by the parser.
+type $$SyntaxError struct {
+	Pos      $$Pos    // position of the unexpected token, if the lexer is a $$PosLexer
+	Token    string   // name of the unexpected token
+	Expected []string // names of the expected tokens, if known
+	Msg      string   // the message that would be passed to Error
+}
+
+func (e *$$SyntaxError) Error() string {
+	return e.Msg
+}
+
+// A $$Pos is a position in the input.
+type $$Pos struct {
+	Line, Column int
+}
+
+// A $$PosLexer is a lexer that reports the position
+// of the token most recently returned by Lex.
+type $$PosLexer interface {
+	$$Lexer
+	Pos() $$Pos
+}
+
+// A $$ErrorReporter is a lexer whose SyntaxError method
+// the parser calls to report syntax errors, in place of Error.
+type $$ErrorReporter interface {
+	$$Lexer
+	SyntaxError(err *$$SyntaxError)
+}
+
+func $$reportError(lex $$Lexer, state, lookAhead int) {
+	msg := $$ErrorMessage(state, lookAhead)
+	r, ok := lex.($$ErrorReporter)
+	if !ok {
+		lex.Error(msg)
+		return
 	}
-	return res
+	err := &$$SyntaxError{Token: $$Tokname(lookAhead), Msg: msg}
+	if l, ok := lex.($$PosLexer); ok {
+		err.Pos = l.Pos()
+	}
+	for _, tok := range $$ExpectedTokens(state) {
+		err.Expected = append(err.Expected, $$Tokname(tok))
+	}
+	r.SyntaxError(err)
 }
 
 func $$lex1(lex $$Lexer, lval *$$SymType) (char, token int) {
@@ -3472,7 +3600,7 @@ $$default:
 		/* error ... attempt to resume parsing */
 		switch Errflag {
 		case 0: /* brand new error */
-			$$lex.Error($$ErrorMessage($$state, $$token))
+			$$reportError($$lex, $$state, $$token)
 			Nerrs++
 			if $$Debug >= 1 {
 				__yyfmt__.Printf("%s", $$Statname($$state))
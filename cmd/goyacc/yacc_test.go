@@ -0,0 +1,145 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestMain runs goyacc, instead of the tests, in the child
+// processes started by the goyacc function.
+func TestMain(m *testing.M) {
+	if os.Getenv("GOYACC_TEST_MAIN") == "1" {
+		main() // does not return
+	}
+	os.Exit(m.Run())
+}
+
+// goyacc runs goyacc in dir with the specified arguments,
+// and returns its combined output.
+func goyacc(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOYACC_TEST_MAIN=1")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// copyTestdata copies the files of testdata/name to a new module
+// directory, returning it and the arguments of the goyacc command
+// in the go:generate directive of its main.go file.
+func copyTestdata(t *testing.T, name string) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	entries, err := os.ReadDir(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args []string
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", name, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if e.Name() == "main.go" {
+			for _, line := range strings.Split(string(data), "\n") {
+				if rest, ok := strings.CutPrefix(line, "//go:generate goyacc "); ok {
+					args = strings.Fields(rest)
+				}
+			}
+		}
+	}
+	if args == nil {
+		t.Fatalf("testdata/%s/main.go has no go:generate directive", name)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+name+"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return dir, args
+}
+
+// TestGrammars generates the parsers of the example programs
+// in testdata, and checks the output of the programs.
+func TestGrammars(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"reentrant", `1 + 2 + 3: 6
+1 + + 2: syntax error: unexpected '+', expecting NUM
+1 + + 2: syntax error
+`},
+		{"syntaxerror", `"[a, b]": ["a" "b"]
+"[a,\n b c]": 2:4: syntax error: unexpected NAME, expecting ']' or ','
+"[a,\n ,]": 2:2: syntax error: unexpected ',', expecting NAME
+`},
+		{"expect", `if x: if(x)
+if if x else x: if(if(x else x))
+if x else if x else x: if(x else if(x else x))
+`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir, args := copyTestdata(t, test.name)
+			if out, err := goyacc(t, dir, args...); err != nil {
+				t.Fatalf("goyacc %s failed: %v\n%s", strings.Join(args, " "), err, out)
+			}
+			cmd := exec.Command("go", "run", ".")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("go run failed: %v\n%s", err, out)
+			}
+			if got := string(out); got != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestExpect checks that goyacc fails if the number of conflicts
+// differs from that declared by %expect.
+func TestExpect(t *testing.T) {
+	dir, args := copyTestdata(t, "expect")
+	grammar := filepath.Join(dir, "if.y")
+	data, err := os.ReadFile(grammar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		decl, want string
+	}{
+		{"%expect 0", "yacc: expected 0 shift/reduce conflicts, found 1"},
+		{"%expect 2", "yacc: expected 2 shift/reduce conflicts, found 1"},
+		{"%expect-rr 1", "yacc: expected 0 shift/reduce conflicts, found 1\nyacc: expected 1 reduce/reduce conflicts, found 0"},
+	} {
+		src := strings.Replace(string(data), "%expect 1", test.decl, 1)
+		if err := os.WriteFile(grammar, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+		out, err := goyacc(t, dir, args...)
+		if err == nil {
+			t.Errorf("with %s, goyacc succeeded", test.decl)
+		} else if !strings.Contains(out, test.want) {
+			t.Errorf("with %s, goyacc printed:\n%s\nwant:\n%s", test.decl, out, test.want)
+		}
+	}
+}
@@ -4,6 +4,9 @@
 
 // This program takes an HTML file and outputs a corresponding article file in
 // present format. See: golang.org/x/tools/present
+//
+// With the -markdown flag, it instead outputs CommonMark, using fenced
+// code blocks and image links, for migration to Markdown-based systems.
 package main // import "golang.org/x/tools/cmd/html2article"
 
 import (
@@ -22,6 +25,8 @@ import (
 	"golang.org/x/net/html/atom"
 )
 
+var markdown = flag.Bool("markdown", false, "output CommonMark instead of present format")
+
 func main() {
 	flag.Parse()
 
@@ -46,6 +51,11 @@ func convert(w io.Writer, r io.Reader) error {
 	if body == nil {
 		return errors.New("couldn't find body")
 	}
+	if *markdown {
+		article := limitNewlineRuns(makeMarkdownHeadings(strings.TrimSpace(text(body))))
+		_, err = fmt.Fprintf(w, "# Title\n\n%s", article)
+		return err
+	}
 	article := limitNewlineRuns(makeHeadings(strings.TrimSpace(text(body))))
 	_, err = fmt.Fprintf(w, "Title\n\n%s", article)
 	return err
@@ -130,6 +140,26 @@ func isBoldTitle(s string) bool {
 		strings.HasSuffix(s, "*")
 }
 
+// makeMarkdownHeadings is like makeHeadings, for Markdown: it turns
+// lines that are entirely bold into second-level headings. Unlike
+// present, Markdown does not require text to begin with a heading.
+func makeMarkdownHeadings(body string) string {
+	buf := new(bytes.Buffer)
+	inFence := false
+	for _, s := range strings.Split(body, "\n") {
+		if strings.HasPrefix(s, "```") {
+			inFence = !inFence
+		}
+		if !inFence && len(s) > len("****") && strings.HasPrefix(s, "**") && strings.HasSuffix(s, "**") &&
+			!strings.Contains(s[len("**"):len(s)-len("**")], "**") {
+			s = "## " + strings.TrimSpace(s[len("**"):len(s)-len("**")])
+		}
+		buf.WriteString(s)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
 func indent(buf *bytes.Buffer, s string) {
 	for _, l := range strings.Split(s, "\n") {
 		if l != "" {
@@ -140,6 +170,21 @@ func indent(buf *bytes.Buffer, s string) {
 	}
 }
 
+// fence writes s to buf as a fenced code block.
+func fence(buf *bytes.Buffer, s string) {
+	// The fence must be longer than any run of backquotes in s.
+	delim := "```"
+	for strings.Contains(s, delim) {
+		delim += "`"
+	}
+	buf.WriteString(delim)
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Trim(s, "\n"))
+	buf.WriteByte('\n')
+	buf.WriteString(delim)
+	buf.WriteByte('\n')
+}
+
 func unwrap(buf *bytes.Buffer, s string) {
 	var cont bool
 	for _, l := range strings.Split(s, "\n") {
@@ -194,7 +239,11 @@ func text(n *html.Node) string {
 			unwrap(&buf, childText(n))
 			buf.WriteByte('\n')
 		case atom.Pre:
-			indent(&buf, childText(n))
+			if *markdown {
+				fence(&buf, childText(n))
+			} else {
+				indent(&buf, childText(n))
+			}
 			buf.WriteByte('\n')
 		case atom.A:
 			href, text := attr(n, "href"), childText(n)
@@ -213,7 +262,11 @@ func text(n *html.Node) string {
 			} else if u.Host == "www.google.com" && u.Path == "/url" {
 				href = u.Query().Get("q")
 			}
-			fmt.Fprintf(&buf, "[[%s][%s]]", href, text)
+			if *markdown {
+				fmt.Fprintf(&buf, "[%s](%s)", text, href)
+			} else {
+				fmt.Fprintf(&buf, "[[%s][%s]]", href, text)
+			}
 		case atom.Code:
 			buf.WriteString(highlight(n, "`"))
 		case atom.B:
@@ -222,10 +275,14 @@ func text(n *html.Node) string {
 			buf.WriteString(highlight(n, "_"))
 		case atom.Img:
 			src := attr(n, "src")
-			fmt.Fprintf(&buf, ".image %s\n", src)
+			if *markdown {
+				fmt.Fprintf(&buf, "![%s](%s)\n", attr(n, "alt"), src)
+			} else {
+				fmt.Fprintf(&buf, ".image %s\n", src)
+			}
 		case atom.Iframe:
 			src, w, h := attr(n, "src"), attr(n, "width"), attr(n, "height")
-			fmt.Fprintf(&buf, "\n.iframe %s %s %s\n", src, h, w)
+			writeIframe(&buf, src, h, w)
 		case atom.Param:
 			if attr(n, "name") == "movie" {
 				// Old style YouTube embed.
@@ -234,7 +291,7 @@ func text(n *html.Node) string {
 				if i := strings.Index(u, "&"); i >= 0 {
 					u = u[:i]
 				}
-				fmt.Fprintf(&buf, "\n.iframe %s 540 304\n", u)
+				writeIframe(&buf, u, "540", "304")
 			}
 		case atom.Title:
 		default:
@@ -254,10 +311,38 @@ func childText(node *html.Node) string {
 }
 
 func highlight(node *html.Node, char string) string {
+	if *markdown {
+		// Markdown emphasis may span spaces, but not begin or end with one.
+		t := childText(node)
+		if strings.TrimSpace(t) == "" {
+			return t
+		}
+		switch char {
+		case "*":
+			char = "**"
+		case "_":
+			char = "*"
+		}
+		trimmed := strings.TrimSpace(t)
+		lead := t[:strings.Index(t, trimmed)]
+		trail := t[len(lead)+len(trimmed):]
+		return lead + char + trimmed + char + trail
+	}
 	t := strings.Replace(childText(node), " ", char, -1)
 	return fmt.Sprintf("%s%s%s", char, t, char)
 }
 
+// writeIframe writes an embedded frame to buf, as a present command
+// or, since Markdown has no such syntax, as raw HTML.
+func writeIframe(buf *bytes.Buffer, src, height, width string) {
+	if *markdown {
+		fmt.Fprintf(buf, "\n<iframe src=\"%s\" width=\"%s\" height=\"%s\"></iframe>\n",
+			html.EscapeString(src), html.EscapeString(width), html.EscapeString(height))
+		return
+	}
+	fmt.Fprintf(buf, "\n.iframe %s %s %s\n", src, height, width)
+}
+
 type selector func(*html.Node) bool
 
 func isTag(a atom.Atom) selector {
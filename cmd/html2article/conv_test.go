@@ -0,0 +1,71 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testHTML = `<html><head><title>T</title></head><body>
+<p><b>Introduction</b></p>
+<p>See the <a href="https://go.dev/doc">docs</a> and <i>read them</i>.</p>
+<pre>func main() {
+	println("` + "```" + `")
+}
+</pre>
+<img src="gopher.png" alt="A gopher">
+<iframe src="https://example.com/v?a=1&amp;b=2" width="560" height="315"></iframe>
+</body></html>
+`
+
+func TestConvert(t *testing.T) {
+	defer func(old bool) { *markdown = old }(*markdown)
+
+	for _, test := range []struct {
+		markdown bool
+		want     string
+	}{
+		{false, `Title
+
+* Introduction
+
+See the [[https://go.dev/doc][docs]] and _read_them_.
+
+	func main() {
+		println("` + "```" + `")
+	}
+
+.image gopher.png
+
+.iframe https://example.com/v?a=1&b=2 315 560
+`},
+		{true, `# Title
+
+## Introduction
+
+See the [docs](https://go.dev/doc) and *read them*.
+
+` + "````" + `
+func main() {
+	println("` + "```" + `")
+}
+` + "````" + `
+
+![A gopher](gopher.png)
+
+<iframe src="https://example.com/v?a=1&amp;b=2" width="560" height="315"></iframe>
+`},
+	} {
+		*markdown = test.markdown
+		var buf strings.Builder
+		if err := convert(&buf, strings.NewReader(testHTML)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("with -markdown=%t, got:\n%s\nwant:\n%s", test.markdown, got, test.want)
+		}
+	}
+}
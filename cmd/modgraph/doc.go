@@ -0,0 +1,72 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+The modgraph command prints the module requirement graph of the main
+module, or of the modules of a go.work workspace, in Graphviz dot
+format or as JSON.
+
+Usage:
+
+	modgraph [flags]
+
+By default, modgraph runs 'go mod graph' and 'go list -m all' in the
+current directory to obtain the graph, the versions selected by minimal
+version selection, and the replace directives in effect. With the -in
+flag, it instead reads the output of 'go mod graph' from the named
+file, or from the standard input if the name is "-", in which case the
+selected versions and replacements are unknown.
+
+Each node of the graph is a module version, path@version, or just path
+for a main module; each edge is a requirement in the go.mod file of a
+module version. In dot output, main modules are drawn in bold; versions
+of a module that are not selected, and are thus only present because of
+requirements that minimal version selection overrides, are gray; and
+replaced modules are labeled with their replacement.
+
+The flags are:
+
+	-format=dot
+		the output format, dot or json
+	-in=file
+		read the output of 'go mod graph' from file ("-" for standard input)
+	-std
+		include the go and toolchain requirements, which are
+		pruned by default
+	-collapse=version
+		merge the versions of each module into a single node, whose
+		incoming edges are labeled with the versions required
+	-collapse=owner
+		merge the modules of each owner, such as golang.org/x or
+		github.com/user, into a single node, whose incoming edges
+		are labeled with the module versions required
+	-target=module
+		highlight the paths from the main modules to the specified
+		module, which may be a path or a path@version
+
+For instance, to see why the module golang.org/x/text is required:
+
+	$ modgraph -collapse=version -target=golang.org/x/text | dot -Tsvg > graph.svg
+
+The JSON output is an object with these fields:
+
+	{
+		"Nodes": [{
+			"ID":        string,       // node name, e.g. "golang.org/x/mod@v0.17.0"
+			"Path":      string,       // module path, or owner with -collapse=owner
+			"Version":   string,       // version, if any
+			"Main":      bool,         // node is a main module
+			"Selected":  bool,         // version is selected (when known)
+			"Replace":   string,       // replacement, e.g. "../mod", if any
+			"Highlight": bool          // node is on a path to the -target
+		}],
+		"Edges": [{
+			"From":      string,       // ID of requiring node
+			"To":        string,       // ID of required node
+			"Versions":  []string,     // versions required, with -collapse
+			"Highlight": bool          // edge is on a path to the -target
+		}]
+	}
+*/
+package main
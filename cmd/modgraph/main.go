@@ -0,0 +1,451 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main // import "golang.org/x/tools/cmd/modgraph"
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+//go:embed doc.go
+var doc string
+
+func usage() {
+	// Extract the content of the /* ... */ comment in doc.go.
+	_, after, _ := strings.Cut(doc, "/*")
+	doc, _, _ := strings.Cut(after, "*/")
+	io.WriteString(flag.CommandLine.Output(), doc)
+	os.Exit(2)
+}
+
+var (
+	formatFlag   = flag.String("format", "dot", "output format: dot or json")
+	inFlag       = flag.String("in", "", "read the output of 'go mod graph' from `file` (\"-\" for standard input)")
+	stdFlag      = flag.Bool("std", false, "include the go and toolchain requirements")
+	collapseFlag = flag.String("collapse", "", "merge nodes by module path (version) or owner (owner)")
+	targetFlag   = flag.String("target", "", "highlight the paths to the specified `module`")
+)
+
+// Overridden by tests.
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 0 {
+		usage()
+	}
+	if err := modgraph(); err != nil {
+		fmt.Fprintf(os.Stderr, "modgraph: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func modgraph() error {
+	switch *formatFlag {
+	case "dot", "json":
+	default:
+		return fmt.Errorf("invalid -format=%s; want dot or json", *formatFlag)
+	}
+	switch *collapseFlag {
+	case "", "version", "owner":
+	default:
+		return fmt.Errorf("invalid -collapse=%s; want version or owner", *collapseFlag)
+	}
+
+	var (
+		data []byte
+		mods map[string]*modInfo // nil => unknown
+		err  error
+	)
+	switch *inFlag {
+	case "":
+		data, err = goCmd("mod", "graph")
+		if err != nil {
+			return err
+		}
+		mods, err = listModules()
+		if err != nil {
+			return err
+		}
+	case "-":
+		data, err = io.ReadAll(stdin)
+	default:
+		data, err = os.ReadFile(*inFlag)
+	}
+	if err != nil {
+		return err
+	}
+
+	reqs, err := parseGraph(data)
+	if err != nil {
+		return err
+	}
+	g := buildGraph(reqs, mods, *collapseFlag, *stdFlag)
+	if *targetFlag != "" {
+		if !g.highlight(*targetFlag) {
+			return fmt.Errorf("no path to module %s", *targetFlag)
+		}
+	}
+
+	if *formatFlag == "json" {
+		return g.writeJSON(stdout)
+	}
+	g.writeDot(stdout)
+	return nil
+}
+
+// goCmd runs the go command with the specified arguments
+// and returns its standard output.
+func goCmd(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, stderr.Bytes())
+	}
+	return out, nil
+}
+
+// A module is a module version, as it appears in 'go mod graph' output.
+// The version of a main module is empty.
+type module struct {
+	path, version string
+}
+
+func (m module) String() string {
+	if m.version == "" {
+		return m.path
+	}
+	return m.path + "@" + m.version
+}
+
+// A requirement is an edge of the module graph.
+type requirement struct {
+	from, to module
+}
+
+// parseGraph parses the output of 'go mod graph', each line of which
+// is a requirement of the form "path[@version] path@version".
+func parseGraph(data []byte) ([]requirement, error) {
+	var reqs []requirement
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		words := strings.Fields(sc.Text())
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) != 2 {
+			return nil, fmt.Errorf("line %d: want two modules, got %q", line, sc.Text())
+		}
+		var mods [2]module
+		for i, word := range words {
+			path, version, _ := strings.Cut(word, "@")
+			mods[i] = module{path, version}
+		}
+		reqs = append(reqs, requirement{mods[0], mods[1]})
+	}
+	return reqs, sc.Err()
+}
+
+// modInfo is the subset of the output of 'go list -m -json' used by modgraph.
+type modInfo struct {
+	Path    string
+	Version string
+	Main    bool
+	Replace *modInfo
+}
+
+// listModules returns information about the modules in the build
+// list, including the selected version of each, indexed by path.
+func listModules() (map[string]*modInfo, error) {
+	data, err := goCmd("list", "-m", "-json", "all")
+	if err != nil {
+		return nil, err
+	}
+	mods := make(map[string]*modInfo)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		m := new(modInfo)
+		if err := dec.Decode(m); err != nil {
+			return nil, fmt.Errorf("go list -m -json all: %v", err)
+		}
+		mods[m.Path] = m
+	}
+	return mods, nil
+}
+
+// owner returns the owner of the module path: for a path beginning with
+// a hostname, that and the next element, such as golang.org/x or
+// github.com/user; otherwise, the first element.
+func owner(path string) string {
+	elems := strings.Split(path, "/")
+	if len(elems) > 2 && strings.Contains(elems[0], ".") {
+		return elems[0] + "/" + elems[1]
+	}
+	return elems[0]
+}
+
+// A graph is the module graph, possibly with some nodes merged.
+type graph struct {
+	nodes []*node
+	edges []*edge
+	byID  map[string]*node
+
+	selectionKnown bool // whether the Selected fields are meaningful
+}
+
+type node struct {
+	ID        string
+	Path      string
+	Version   string `json:",omitempty"`
+	Main      bool   `json:",omitempty"`
+	Selected  bool   `json:",omitempty"`
+	Replace   string `json:",omitempty"`
+	Highlight bool   `json:",omitempty"`
+
+	succs, preds []*edge
+}
+
+type edge struct {
+	From      string
+	To        string
+	Versions  []string `json:",omitempty"`
+	Highlight bool     `json:",omitempty"`
+
+	from, to *node
+}
+
+// buildGraph returns the graph of the specified requirements. If mods
+// is non-nil, it holds the build list, which determines the selected
+// versions and replacements. The collapse mode ("", "version", or
+// "owner") determines which module versions are merged. The go and
+// toolchain pseudo-modules are omitted unless std is set.
+func buildGraph(reqs []requirement, mods map[string]*modInfo, collapse string, std bool) *graph {
+	g := &graph{byID: make(map[string]*node), selectionKnown: mods != nil}
+
+	isMain := func(m module) bool {
+		if mods != nil {
+			if info := mods[m.path]; info != nil {
+				return info.Main
+			}
+		}
+		return m.version == ""
+	}
+
+	nodeFor := func(m module) *node {
+		id := m.String()
+		path, version := m.path, m.version
+		switch collapse {
+		case "version":
+			id, version = m.path, ""
+		case "owner":
+			if !isMain(m) {
+				path = owner(m.path)
+				id, version = path, ""
+			}
+		}
+		n := g.byID[id]
+		if n == nil {
+			n = &node{ID: id, Path: path, Version: version, Main: isMain(m)}
+			g.byID[id] = n
+			g.nodes = append(g.nodes, n)
+		}
+		if info := mods[m.path]; info != nil {
+			// A merged node is selected if any of its versions is.
+			if info.Main || info.Version == m.version {
+				n.Selected = true
+				if r := info.Replace; r != nil && collapse != "owner" {
+					n.Replace = module{r.Path, r.Version}.String()
+				}
+			}
+		}
+		return n
+	}
+
+	edges := make(map[[2]*node]*edge)
+	for _, req := range reqs {
+		if !std && (isPseudo(req.from) || isPseudo(req.to)) {
+			continue
+		}
+		from, to := nodeFor(req.from), nodeFor(req.to)
+		if from == to {
+			continue // e.g. two modules of one owner
+		}
+		e := edges[[2]*node{from, to}]
+		if e == nil {
+			e = &edge{From: from.ID, To: to.ID, from: from, to: to}
+			edges[[2]*node{from, to}] = e
+			g.edges = append(g.edges, e)
+			from.succs = append(from.succs, e)
+			to.preds = append(to.preds, e)
+		}
+		if collapse != "" && req.to.version != "" {
+			// Edges to an owner are labeled with the module too.
+			v := req.to.version
+			if collapse == "owner" {
+				v = req.to.String()
+			}
+			if !contains(e.Versions, v) {
+				e.Versions = append(e.Versions, v)
+			}
+		}
+	}
+
+	sort.Slice(g.nodes, func(i, j int) bool { return g.nodes[i].ID < g.nodes[j].ID })
+	sort.Slice(g.edges, func(i, j int) bool {
+		x, y := g.edges[i], g.edges[j]
+		if x.From != y.From {
+			return x.From < y.From
+		}
+		return x.To < y.To
+	})
+	return g
+}
+
+// isPseudo reports whether m is one of the go and toolchain
+// pseudo-modules that represent the required Go version.
+func isPseudo(m module) bool {
+	return m.path == "go" || m.path == "toolchain"
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight marks the nodes and edges on paths from the roots of the
+// graph (the main modules, or else the nodes without predecessors) to
+// the nodes of the target module, which is a path or path@version. It
+// reports whether there are any such paths.
+func (g *graph) highlight(target string) bool {
+	path, version, _ := strings.Cut(target, "@")
+	var targets, roots []*node
+	for _, n := range g.nodes {
+		if n.ID == target || n.Path == path && (version == "" || n.Version == version) {
+			targets = append(targets, n)
+		}
+		if n.Main {
+			roots = append(roots, n)
+		}
+	}
+	if roots == nil {
+		for _, n := range g.nodes {
+			if len(n.preds) == 0 {
+				roots = append(roots, n)
+			}
+		}
+	}
+
+	// Find the nodes reachable from the roots
+	// and the nodes that reach a target.
+	visit := func(start []*node, next func(*node) []*node) map[*node]bool {
+		seen := make(map[*node]bool)
+		var walk func(n *node)
+		walk = func(n *node) {
+			if !seen[n] {
+				seen[n] = true
+				for _, m := range next(n) {
+					walk(m)
+				}
+			}
+		}
+		for _, n := range start {
+			walk(n)
+		}
+		return seen
+	}
+	forward := visit(roots, func(n *node) (succs []*node) {
+		for _, e := range n.succs {
+			succs = append(succs, e.to)
+		}
+		return
+	})
+	reverse := visit(targets, func(n *node) (preds []*node) {
+		for _, e := range n.preds {
+			preds = append(preds, e.from)
+		}
+		return
+	})
+
+	found := false
+	for _, n := range g.nodes {
+		if forward[n] && reverse[n] {
+			n.Highlight = true
+			found = true
+		}
+	}
+	for _, e := range g.edges {
+		e.Highlight = e.from.Highlight && e.to.Highlight
+	}
+	return found
+}
+
+func (g *graph) writeJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(struct {
+		Nodes []*node
+		Edges []*edge
+	}{g.nodes, g.edges}, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func (g *graph) writeDot(w io.Writer) {
+	fmt.Fprintf(w, "digraph modules {\n")
+	fmt.Fprintf(w, "\tnode [shape=box];\n")
+	for _, n := range g.nodes {
+		var attrs []string
+		if n.Replace != "" {
+			attrs = append(attrs, fmt.Sprintf("label=%q", n.ID+"\n=> "+n.Replace))
+		}
+		if n.Main {
+			attrs = append(attrs, "style=bold")
+		}
+		if n.Highlight {
+			attrs = append(attrs, "color=red")
+		} else if g.selectionKnown && !n.Selected && !n.Main {
+			attrs = append(attrs, "color=gray", "fontcolor=gray")
+		}
+		fmt.Fprintf(w, "\t%q%s;\n", n.ID, dotAttrs(attrs))
+	}
+	for _, e := range g.edges {
+		var attrs []string
+		if len(e.Versions) > 0 {
+			attrs = append(attrs, fmt.Sprintf("label=%q", strings.Join(e.Versions, ", ")))
+		}
+		if e.Highlight {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(w, "\t%q -> %q%s;\n", e.From, e.To, dotAttrs(attrs))
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+func dotAttrs(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
@@ -0,0 +1,243 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const graph1 = `
+example.com/m go@1.22.0
+example.com/m golang.org/x/mod@v0.17.0
+example.com/m golang.org/x/text@v0.14.0
+example.com/m github.com/user/a@v1.1.0
+golang.org/x/mod@v0.17.0 go@1.18
+github.com/user/a@v1.1.0 github.com/user/b@v1.0.0
+github.com/user/a@v1.1.0 golang.org/x/text@v0.3.0
+github.com/user/b@v1.0.0 golang.org/x/text@v0.3.0
+`
+
+// mods1 is the build list of graph1.
+var mods1 = map[string]*modInfo{
+	"example.com/m":      {Path: "example.com/m", Main: true},
+	"golang.org/x/mod":   {Path: "golang.org/x/mod", Version: "v0.17.0", Replace: &modInfo{Path: "../mod"}},
+	"golang.org/x/text":  {Path: "golang.org/x/text", Version: "v0.14.0"},
+	"github.com/user/a":  {Path: "github.com/user/a", Version: "v1.1.0"},
+	"github.com/user/b":  {Path: "github.com/user/b", Version: "v1.0.0"},
+	"go":                 {Path: "go", Version: "1.22.0"},
+	"example.com/unused": {Path: "example.com/unused", Version: "v1.0.0"},
+}
+
+func TestDot(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		mods     map[string]*modInfo
+		collapse string
+		std      bool
+		target   string
+		want     string
+	}{
+		{
+			name: "basic",
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold];
+	"github.com/user/a@v1.1.0";
+	"github.com/user/b@v1.0.0";
+	"golang.org/x/mod@v0.17.0";
+	"golang.org/x/text@v0.14.0";
+	"golang.org/x/text@v0.3.0";
+	"example.com/m" -> "github.com/user/a@v1.1.0";
+	"example.com/m" -> "golang.org/x/mod@v0.17.0";
+	"example.com/m" -> "golang.org/x/text@v0.14.0";
+	"github.com/user/a@v1.1.0" -> "github.com/user/b@v1.0.0";
+	"github.com/user/a@v1.1.0" -> "golang.org/x/text@v0.3.0";
+	"github.com/user/b@v1.0.0" -> "golang.org/x/text@v0.3.0";
+}
+`,
+		},
+		{
+			name: "std",
+			std:  true,
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold];
+	"github.com/user/a@v1.1.0";
+	"github.com/user/b@v1.0.0";
+	"go@1.18";
+	"go@1.22.0";
+	"golang.org/x/mod@v0.17.0";
+	"golang.org/x/text@v0.14.0";
+	"golang.org/x/text@v0.3.0";
+	"example.com/m" -> "github.com/user/a@v1.1.0";
+	"example.com/m" -> "go@1.22.0";
+	"example.com/m" -> "golang.org/x/mod@v0.17.0";
+	"example.com/m" -> "golang.org/x/text@v0.14.0";
+	"github.com/user/a@v1.1.0" -> "github.com/user/b@v1.0.0";
+	"github.com/user/a@v1.1.0" -> "golang.org/x/text@v0.3.0";
+	"github.com/user/b@v1.0.0" -> "golang.org/x/text@v0.3.0";
+	"golang.org/x/mod@v0.17.0" -> "go@1.18";
+}
+`,
+		},
+		{
+			name: "selected",
+			mods: mods1,
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold];
+	"github.com/user/a@v1.1.0";
+	"github.com/user/b@v1.0.0";
+	"golang.org/x/mod@v0.17.0" [label="golang.org/x/mod@v0.17.0\n=> ../mod"];
+	"golang.org/x/text@v0.14.0";
+	"golang.org/x/text@v0.3.0" [color=gray, fontcolor=gray];
+	"example.com/m" -> "github.com/user/a@v1.1.0";
+	"example.com/m" -> "golang.org/x/mod@v0.17.0";
+	"example.com/m" -> "golang.org/x/text@v0.14.0";
+	"github.com/user/a@v1.1.0" -> "github.com/user/b@v1.0.0";
+	"github.com/user/a@v1.1.0" -> "golang.org/x/text@v0.3.0";
+	"github.com/user/b@v1.0.0" -> "golang.org/x/text@v0.3.0";
+}
+`,
+		},
+		{
+			name:     "collapse version",
+			collapse: "version",
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold];
+	"github.com/user/a";
+	"github.com/user/b";
+	"golang.org/x/mod";
+	"golang.org/x/text";
+	"example.com/m" -> "github.com/user/a" [label="v1.1.0"];
+	"example.com/m" -> "golang.org/x/mod" [label="v0.17.0"];
+	"example.com/m" -> "golang.org/x/text" [label="v0.14.0"];
+	"github.com/user/a" -> "github.com/user/b" [label="v1.0.0"];
+	"github.com/user/a" -> "golang.org/x/text" [label="v0.3.0"];
+	"github.com/user/b" -> "golang.org/x/text" [label="v0.3.0"];
+}
+`,
+		},
+		{
+			name:     "collapse owner",
+			collapse: "owner",
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold];
+	"github.com/user";
+	"golang.org/x";
+	"example.com/m" -> "github.com/user" [label="github.com/user/a@v1.1.0"];
+	"example.com/m" -> "golang.org/x" [label="golang.org/x/mod@v0.17.0, golang.org/x/text@v0.14.0"];
+	"github.com/user" -> "golang.org/x" [label="golang.org/x/text@v0.3.0"];
+}
+`,
+		},
+		{
+			name:   "target",
+			target: "github.com/user/b",
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold, color=red];
+	"github.com/user/a@v1.1.0" [color=red];
+	"github.com/user/b@v1.0.0" [color=red];
+	"golang.org/x/mod@v0.17.0";
+	"golang.org/x/text@v0.14.0";
+	"golang.org/x/text@v0.3.0";
+	"example.com/m" -> "github.com/user/a@v1.1.0" [color=red];
+	"example.com/m" -> "golang.org/x/mod@v0.17.0";
+	"example.com/m" -> "golang.org/x/text@v0.14.0";
+	"github.com/user/a@v1.1.0" -> "github.com/user/b@v1.0.0" [color=red];
+	"github.com/user/a@v1.1.0" -> "golang.org/x/text@v0.3.0";
+	"github.com/user/b@v1.0.0" -> "golang.org/x/text@v0.3.0";
+}
+`,
+		},
+		{
+			name:   "target version",
+			target: "golang.org/x/text@v0.14.0",
+			want: `digraph modules {
+	node [shape=box];
+	"example.com/m" [style=bold, color=red];
+	"github.com/user/a@v1.1.0";
+	"github.com/user/b@v1.0.0";
+	"golang.org/x/mod@v0.17.0";
+	"golang.org/x/text@v0.14.0" [color=red];
+	"golang.org/x/text@v0.3.0";
+	"example.com/m" -> "github.com/user/a@v1.1.0";
+	"example.com/m" -> "golang.org/x/mod@v0.17.0";
+	"example.com/m" -> "golang.org/x/text@v0.14.0" [color=red];
+	"github.com/user/a@v1.1.0" -> "github.com/user/b@v1.0.0";
+	"github.com/user/a@v1.1.0" -> "golang.org/x/text@v0.3.0";
+	"github.com/user/b@v1.0.0" -> "golang.org/x/text@v0.3.0";
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			reqs, err := parseGraph([]byte(graph1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := buildGraph(reqs, test.mods, test.collapse, test.std)
+			if test.target != "" && !g.highlight(test.target) {
+				t.Fatalf("highlight(%s) found no path", test.target)
+			}
+			var buf bytes.Buffer
+			g.writeDot(&buf)
+			if got := buf.String(); got != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	reqs, err := parseGraph([]byte(graph1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := buildGraph(reqs, mods1, "version", false)
+	if !g.highlight("golang.org/x/text") {
+		t.Fatal("highlight found no path")
+	}
+	var buf bytes.Buffer
+	if err := g.writeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Nodes []node
+		Edges []edge
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Nodes) != 5 || len(got.Edges) != 6 {
+		t.Fatalf("got %d nodes and %d edges, want 5 and 6:\n%s", len(got.Nodes), len(got.Edges), buf.Bytes())
+	}
+	for _, n := range got.Nodes {
+		// Every node but golang.org/x/mod is on a path to golang.org/x/text.
+		if want := n.ID != "golang.org/x/mod"; n.Highlight != want {
+			t.Errorf("node %s: Highlight = %t, want %t", n.ID, n.Highlight, want)
+		}
+		if !n.Selected {
+			t.Errorf("node %s: not selected", n.ID)
+		}
+		if n.ID == "golang.org/x/mod" && n.Replace != "../mod" {
+			t.Errorf("node %s: Replace = %q, want %q", n.ID, n.Replace, "../mod")
+		}
+	}
+}
+
+func TestParseGraphError(t *testing.T) {
+	_, err := parseGraph([]byte("a@v1.0.0 b@v1.0.0\nc\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("parseGraph: got error %v, want error on line 2", err)
+	}
+}
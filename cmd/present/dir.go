@@ -5,6 +5,8 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
@@ -12,6 +14,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -114,7 +117,33 @@ func parse(name string, mode present.ParseMode) (*present.Doc, error) {
 		return nil, err
 	}
 	defer f.Close()
-	return present.Parse(f, name, mode)
+	ctx := present.Context{ReadFile: os.ReadFile, RenderDiagram: renderDiagram}
+	return ctx.Parse(f, name, mode)
+}
+
+// renderDiagram renders Graphviz diagrams using the dot command, if it
+// is installed. Other diagrams are left for the browser to render.
+func renderDiagram(kind string, src []byte) ([]byte, error) {
+	if kind != "graphviz" {
+		return nil, nil
+	}
+	dot, err := exec.LookPath("dot")
+	if err != nil {
+		return nil, nil
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(dot, "-Tsvg")
+	cmd.Stdin = bytes.NewReader(src)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("dot: %v\n%s", err, stderr.Bytes())
+	}
+	// Omit the XML declaration and doctype, which don't belong in HTML.
+	if i := bytes.Index(out, []byte("<svg")); i > 0 {
+		out = out[i:]
+	}
+	return out, nil
 }
 
 // dirList scans the given path and writes a directory listing to w.
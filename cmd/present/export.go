@@ -0,0 +1,140 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/present"
+)
+
+// exportDocs renders each presentable file beneath *contentPath to a
+// static HTML file in the corresponding location beneath dir, so that
+// foo/talk.slide becomes dir/foo/talk.html. It copies the other files
+// of the content, such as images, alongside them, and the static
+// resources of the templates to dir/static.
+//
+// If playURL is empty, code snippets are read-only. Otherwise it is
+// the URL of a playground compile endpoint, such as
+// https://play.golang.org/compile, that runs them.
+func exportDocs(fsys fs.FS, dir, playURL string) error {
+	present.PlayEnabled = present.PlayEnabled && playURL != ""
+	if present.PlayEnabled {
+		// As with -use_playground, only Go snippets are playable.
+		*usePlayground = true
+	}
+
+	out, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	// Copy the static resources.
+	err = fs.WalkDir(fsys, "static", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		return writeFile(filepath.Join(out, filepath.FromSlash(path)), data)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Create the playground script, which the server would serve
+	// as /play.js, directing requests for /compile to playURL.
+	if present.PlayEnabled {
+		scripts, err := readScripts(fsys)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(scripts, "\n$.ajaxPrefilter(function(options) { if (options.url === '/compile') options.url = %q; });\n", playURL)
+		fmt.Fprintf(scripts, "initPlayground(new HTTPTransport());\n")
+		if err := writeFile(filepath.Join(out, "play.js"), scripts.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	// Render the documents and copy the other files.
+	root := filepath.Clean(*contentPath)
+	n := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if abs, _ := filepath.Abs(path); abs == out {
+			return filepath.SkipDir // don't export the output
+		}
+		if d.IsDir() {
+			if path != root && !showDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if !isDoc(path) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return writeFile(filepath.Join(out, rel), data)
+		}
+
+		var buf bytes.Buffer
+		if err := renderDoc(&buf, path); err != nil {
+			return err
+		}
+		// Make references to the server's resources relative.
+		depth := strings.Count(filepath.ToSlash(rel), "/")
+		html := relativize(buf.String(), strings.Repeat("../", depth))
+		n++
+		return writeFile(filepath.Join(out, strings.TrimSuffix(rel, filepath.Ext(rel))+".html"), []byte(html))
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported %d files to %s", n, dir)
+	return nil
+}
+
+// relativize rewrites the references in a rendered document to the
+// resources served by the present server at /static/ and /play.js
+// so that they are relative to the document, using the specified
+// prefix to reach the root of the export directory.
+func relativize(html, prefix string) string {
+	for _, q := range []string{`'`, `"`} {
+		html = strings.ReplaceAll(html, q+"/static/", q+prefix+"static/")
+		html = strings.ReplaceAll(html, q+"/play.js"+q, q+prefix+"play.js"+q)
+	}
+
+	// slides.js loads styles.css from PERMANENT_URL_PREFIX,
+	// which is initially /static/, once the document is loaded.
+	slides := `<script src='` + prefix + `static/slides.js'></script>`
+	html = strings.Replace(html, slides, slides+"\n    <script>PERMANENT_URL_PREFIX = '"+prefix+"static/';</script>", 1)
+	return html
+}
+
+// writeFile writes data to the named file, creating its directory if necessary.
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0666)
+}
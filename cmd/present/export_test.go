@@ -0,0 +1,103 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/present"
+)
+
+func TestRelativize(t *testing.T) {
+	const html = `<link rel="stylesheet" href="/static/article.css">
+<script src='/static/slides.js'></script>
+<script src='/play.js'></script>
+<a href="/static">not a resource</a>`
+	got := relativize(html, "../")
+	want := `<link rel="stylesheet" href="../static/article.css">
+<script src='../static/slides.js'></script>
+    <script>PERMANENT_URL_PREFIX = '../static/';</script>
+<script src='../play.js'></script>
+<a href="/static">not a resource</a>`
+	if got != want {
+		t.Errorf("relativize:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportDocs(t *testing.T) {
+	defer func(old string) { *contentPath = old }(*contentPath)
+	defer func(old bool) { present.PlayEnabled = old }(present.PlayEnabled)
+	defer func(old bool) { *usePlayground = old }(*usePlayground)
+
+	if err := initTemplates(embedFS); err != nil {
+		t.Fatal(err)
+	}
+	content := t.TempDir()
+	for name, data := range map[string]string{
+		"talk.slide":          "Talk\n\n* Slide\n\n.image gopher.png\n",
+		"gopher.png":          "PNG",
+		"sub/post.article":    "Post\n\n* Section\n\nText.\n",
+		".hidden/talk.slide":  "Hidden\n",
+		"_private/talk.slide": "Private\n",
+	} {
+		name = filepath.Join(content, filepath.FromSlash(name))
+		if err := writeFile(name, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*contentPath = content
+	present.PlayEnabled = true
+
+	for _, test := range []struct {
+		playURL  string
+		wantPlay bool
+	}{
+		{"", false},
+		{"https://play.golang.org/compile", true},
+	} {
+		// The output directory is within the content,
+		// so as to check that it is not exported itself.
+		out := filepath.Join(content, "out"+filepath.Base(t.TempDir()))
+		if err := exportDocs(embedFS, out, test.playURL); err != nil {
+			t.Fatal(err)
+		}
+		read := func(name string) string {
+			data, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+			if err != nil {
+				t.Error(err)
+			}
+			return string(data)
+		}
+
+		if got := read("gopher.png"); got != "PNG" {
+			t.Errorf("gopher.png contains %q", got)
+		}
+		read("static/slides.js")
+		talk := read("talk.html")
+		if !strings.Contains(talk, `src='static/slides.js'`) {
+			t.Errorf("talk.html does not refer to static/slides.js:\n%s", talk)
+		}
+		post := read("sub/post.html")
+		if !strings.Contains(post, `"../static/`) || strings.Contains(post, `"/static/`) {
+			t.Errorf("sub/post.html does not refer to ../static:\n%s", post)
+		}
+		for _, name := range []string{".hidden/talk.html", "_private/talk.html", filepath.Base(out)} {
+			if _, err := os.Stat(filepath.Join(out, name)); err == nil {
+				t.Errorf("%s was exported", name)
+			}
+		}
+
+		play, err := os.ReadFile(filepath.Join(out, "play.js"))
+		if hasPlay := err == nil; hasPlay != test.wantPlay {
+			t.Errorf("with playground URL %q, play.js exists = %t", test.playURL, hasPlay)
+		} else if hasPlay && !strings.Contains(string(play), test.playURL) {
+			t.Errorf("play.js does not refer to %s", test.playURL)
+		}
+		present.PlayEnabled = true
+	}
+}
@@ -25,6 +25,9 @@ var (
 	basePath      = flag.String("base", "", "base path for slide template and static resources")
 	contentPath   = flag.String("content", ".", "base path for presentation content")
 	usePlayground = flag.Bool("use_playground", false, "run code snippets using play.golang.org; if false, run them locally and deliver results by WebSocket transport")
+	exportDir     = flag.String("export", "", "instead of serving, render the content as static HTML files in this directory")
+	playURL       = flag.String("play_url", "", "with -use_playground, URL of the playground service that runs code snippets, such as a self-hosted sandbox; if empty, play.golang.org")
+	exportPlay    = flag.String("export_play", "", "with -export, URL of the playground compile endpoint that runs code snippets (e.g., 'https://play.golang.org/compile'); if empty, snippets are read-only")
 )
 
 //go:embed static templates
@@ -60,6 +63,13 @@ func main() {
 		log.Fatalf("Failed to parse templates: %v", err)
 	}
 
+	if *exportDir != "" {
+		if err := exportDocs(fsys, *exportDir, *exportPlay); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	}
+
 	ln, err := net.Listen("tcp", *httpAddr)
 	if err != nil {
 		log.Fatal(err)
@@ -95,6 +105,9 @@ func main() {
 	}
 
 	initPlayground(fsys, origin)
+	if present.NotesEnabled {
+		http.Handle("/remote", newRemoteHandler(origin))
+	}
 	http.Handle("/static/", http.FileServer(http.FS(fsys)))
 
 	if !ln.Addr().(*net.TCPAddr).IP.IsLoopback() &&
@@ -12,15 +12,15 @@ import (
 	"net/url"
 	"time"
 
+	// This will register a handler at /compile that will proxy to the
+	// respective endpoints at play.golang.org, or at the service given by
+	// -play_url. This allows the frontend to call these endpoints without
+	// needing cross-origin request sharing (CORS). Note that this is
+	// imported regardless of whether the endpoints are used or not (in the
+	// case of a local socket connection, they are not called).
+	"golang.org/x/tools/playground"
 	"golang.org/x/tools/playground/socket"
 	"golang.org/x/tools/present"
-
-	// This will register a handler at /compile that will proxy to the
-	// respective endpoints at play.golang.org. This allows the frontend to call
-	// these endpoints without needing cross-origin request sharing (CORS).
-	// Note that this is imported regardless of whether the endpoints are used or
-	// not (in the case of a local socket connection, they are not called).
-	_ "golang.org/x/tools/playground"
 )
 
 var scripts = []string{"jquery.js", "jquery-ui.js", "playground.js", "play.js"}
@@ -30,20 +30,30 @@ var scripts = []string{"jquery.js", "jquery-ui.js", "playground.js", "play.js"}
 // initializes the playground with the specified transport.
 func playScript(fsys fs.FS, transport string) {
 	modTime := time.Now()
+	buf, err := readScripts(fsys)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(buf, "\ninitPlayground(new %v());\n", transport)
+	b := buf.Bytes()
+	http.HandleFunc("/play.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-type", "application/javascript")
+		http.ServeContent(w, r, "", modTime, bytes.NewReader(b))
+	})
+}
+
+// readScripts returns the concatenation of the scripts
+// specified by the variable above.
+func readScripts(fsys fs.FS) (*bytes.Buffer, error) {
 	var buf bytes.Buffer
 	for _, p := range scripts {
 		b, err := fs.ReadFile(fsys, "static/"+p)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		buf.Write(b)
 	}
-	fmt.Fprintf(&buf, "\ninitPlayground(new %v());\n", transport)
-	b := buf.Bytes()
-	http.HandleFunc("/play.js", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-type", "application/javascript")
-		http.ServeContent(w, r, "", modTime, bytes.NewReader(b))
-	})
+	return &buf, nil
 }
 
 func initPlayground(fsys fs.FS, origin *url.URL) {
@@ -51,6 +61,9 @@ func initPlayground(fsys fs.FS, origin *url.URL) {
 		return
 	}
 	if *usePlayground {
+		if *playURL != "" {
+			playground.DefaultBackend = &playground.Client{BaseURL: *playURL}
+		}
 		playScript(fsys, "HTTPTransport")
 		return
 	}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// A remoteMessage is sent over the /remote websocket when a window
+// showing a presentation moves to another slide.
+type remoteMessage struct {
+	Slide int // 0 for the title slide
+}
+
+// A remoteHub relays the messages of each window showing a
+// presentation to the other windows showing the same presentation,
+// such as the presenter's notes window, or a phone used as a remote
+// control.
+type remoteHub struct {
+	mu    sync.Mutex
+	decks map[string]map[*websocket.Conn]bool // by path of presentation
+}
+
+// newRemoteHandler returns a websocket server for the /remote
+// endpoint, which accepts connections from the given origin.
+func newRemoteHandler(origin *url.URL) websocket.Server {
+	hub := &remoteHub{decks: make(map[string]map[*websocket.Conn]bool)}
+	return websocket.Server{
+		Config:    websocket.Config{Origin: origin},
+		Handshake: remoteHandshake,
+		Handler:   websocket.Handler(hub.serve),
+	}
+}
+
+// remoteHandshake checks the origin of a request during the websocket
+// handshake, as the playground's socket handler does.
+func remoteHandshake(c *websocket.Config, req *http.Request) error {
+	o, err := websocket.Origin(c, req)
+	if err != nil {
+		log.Println("bad websocket origin:", err)
+		return websocket.ErrBadWebSocketOrigin
+	}
+	_, port, err := net.SplitHostPort(c.Origin.Host)
+	if err != nil {
+		log.Println("bad websocket origin:", err)
+		return websocket.ErrBadWebSocketOrigin
+	}
+	if c.Origin.Scheme != o.Scheme || (c.Origin.Host != o.Host && c.Origin.Host != net.JoinHostPort(o.Host, port)) {
+		log.Println("bad websocket origin:", o)
+		return websocket.ErrBadWebSocketOrigin
+	}
+	return nil
+}
+
+func (hub *remoteHub) serve(c *websocket.Conn) {
+	deck := c.Request().URL.Query().Get("deck")
+
+	hub.mu.Lock()
+	conns := hub.decks[deck]
+	if conns == nil {
+		conns = make(map[*websocket.Conn]bool)
+		hub.decks[deck] = conns
+	}
+	conns[c] = true
+	hub.mu.Unlock()
+
+	defer func() {
+		hub.mu.Lock()
+		delete(conns, c)
+		if len(conns) == 0 {
+			delete(hub.decks, deck)
+		}
+		hub.mu.Unlock()
+	}()
+
+	dec := json.NewDecoder(c)
+	for {
+		var m remoteMessage
+		if err := dec.Decode(&m); err != nil {
+			return
+		}
+		// Send the message without holding the lock,
+		// so that a slow connection delays only this one.
+		hub.mu.Lock()
+		others := make([]*websocket.Conn, 0, len(conns))
+		for other := range conns {
+			if other != c {
+				others = append(others, other)
+			}
+		}
+		hub.mu.Unlock()
+		for _, other := range others {
+			// A failed send closes the connection, ending its
+			// own call to serve.
+			if err := websocket.JSON.Send(other, m); err != nil {
+				other.Close()
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestRemote(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nil)
+	origin, err := url.Parse("http://" + srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Config.Handler = newRemoteHandler(origin)
+	srv.Start()
+	defer srv.Close()
+
+	dial := func(deck string) *websocket.Conn {
+		t.Helper()
+		u := "ws" + strings.TrimPrefix(srv.URL, "http") + "/remote?deck=" + url.QueryEscape(deck)
+		c, err := websocket.Dial(u, "", origin.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	receive := func(c *websocket.Conn) (remoteMessage, error) {
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		var m remoteMessage
+		err := websocket.JSON.Receive(c, &m)
+		return m, err
+	}
+
+	presenter, notes, phone := dial("talk.slide"), dial("talk.slide"), dial("talk.slide")
+	defer presenter.Close()
+	defer notes.Close()
+	defer phone.Close()
+	other := dial("other.slide")
+	defer other.Close()
+
+	// A closed connection does not prevent delivery to the others.
+	closed := dial("talk.slide")
+	closed.Close()
+
+	if err := websocket.JSON.Send(presenter, remoteMessage{Slide: 3}); err != nil {
+		t.Fatal(err)
+	}
+	for name, c := range map[string]*websocket.Conn{"notes": notes, "phone": phone} {
+		if m, err := receive(c); err != nil || m.Slide != 3 {
+			t.Errorf("%s window received %+v, %v, want slide 3", name, m, err)
+		}
+	}
+
+	if err := websocket.JSON.Send(phone, remoteMessage{Slide: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := receive(presenter); err != nil || m.Slide != 4 {
+		t.Errorf("presenter received %+v, %v, want slide 4", m, err)
+	}
+
+	// Messages go only to the windows showing the same presentation,
+	// not to the sender.
+	if m, err := receive(other); err == nil {
+		t.Errorf("window of another presentation received %+v", m)
+	}
+	if m, err := receive(phone); err == nil {
+		t.Errorf("sender received its own message %+v", m)
+	}
+}
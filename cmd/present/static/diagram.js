@@ -0,0 +1,75 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Renders in the browser the diagrams that the present server did not
+// render: those of .diagram commands, and fenced code blocks in
+// Markdown whose language is mermaid, dot, or graphviz. The renderers
+// are loaded from a CDN only if the document has such diagrams.
+
+(function() {
+  'use strict';
+
+  var MERMAID_URL = 'https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.min.js';
+  var VIZ_URL = 'https://cdn.jsdelivr.net/npm/@viz-js/viz@3/lib/viz-standalone.js';
+
+  function loadScript(src, onload) {
+    var s = document.createElement('script');
+    s.src = src;
+    s.onload = onload;
+    document.head.appendChild(s);
+  }
+
+  // Replace the <pre> of each fenced code block of the given language
+  // with a diagram element like the one of a .diagram command.
+  function convertFenced(lang, kind) {
+    var codes = document.querySelectorAll('pre > code.language-' + lang);
+    for (var i = 0; i < codes.length; i++) {
+      var pre = codes[i].parentNode;
+      var div = document.createElement('div');
+      div.className = 'diagram';
+      var src = document.createElement('pre');
+      src.className = 'diagram-' + kind;
+      src.textContent = codes[i].textContent;
+      div.appendChild(src);
+      pre.parentNode.replaceChild(div, pre);
+    }
+  }
+
+  function renderMermaid(nodes) {
+    loadScript(MERMAID_URL, function() {
+      window.mermaid.initialize({startOnLoad: false});
+      window.mermaid.run({nodes: nodes});
+    });
+  }
+
+  function renderGraphviz(nodes) {
+    loadScript(VIZ_URL, function() {
+      window.Viz.instance().then(function(viz) {
+        for (var i = 0; i < nodes.length; i++) {
+          var pre = nodes[i];
+          try {
+            pre.parentNode.replaceChild(viz.renderSVGElement(pre.textContent), pre);
+          } catch (e) {
+            console.error('rendering graphviz diagram:', e);
+          }
+        }
+      });
+    });
+  }
+
+  window.addEventListener('load', function() {
+    convertFenced('mermaid', 'mermaid');
+    convertFenced('dot', 'graphviz');
+    convertFenced('graphviz', 'graphviz');
+
+    var mermaid = document.querySelectorAll('pre.diagram-mermaid');
+    if (mermaid.length > 0) {
+      renderMermaid(mermaid);
+    }
+    var graphviz = document.querySelectorAll('pre.diagram-graphviz');
+    if (graphviz.length > 0) {
+      renderGraphviz(graphviz);
+    }
+  });
+})();
@@ -55,15 +55,7 @@ function initNotes() {
   w.document.body.appendChild(slides);
 
   var curSlide = parseInt(localStorage.getItem(destSlideKey()), 10);
-  var formattedNotes = '';
-  var section = sections[curSlide - 1];
-  // curSlide is 0 when initialized from the first page of slides.
-  // Check if section is valid before retrieving Notes.
-  if (section) {
-    formattedNotes = formatNotes(section.Notes);
-  } else if (curSlide == 0) {
-    formattedNotes = formatNotes(titleNotes);
-  }
+  var formattedNotes = notesFor(curSlide);
 
   // setTimeout needed for Firefox
   setTimeout(function() {
@@ -75,6 +67,8 @@ function initNotes() {
   notes.innerHTML = formattedNotes;
   w.document.body.appendChild(notes);
 
+  w.document.body.appendChild(createPresenterControls(w.document));
+
   w.document.close();
 
   function addPresenterNotesStyle() {
@@ -108,18 +102,150 @@ function updateNotes() {
   // The storage event listener on notesWindow will update notes
   if (!notesWindow) return;
   var destSlide = parseInt(localStorage.getItem(destSlideKey()), 10);
-  var section = sections[destSlide - 1];
   var el = notesWindow.document.getElementById('presenter-notes');
 
   if (!el) return;
 
-  if (section && section.Notes) {
-    el.innerHTML = formatNotes(section.Notes);
-  } else if (destSlide == 0) {
-    el.innerHTML = formatNotes(titleNotes);
-  } else {
-    el.innerHTML = '';
+  el.innerHTML = notesFor(destSlide);
+}
+
+// notesFor returns the formatted notes of the given slide,
+// where slide 0 is the title slide.
+function notesFor(slide) {
+  // slide is 0 when initialized from the first page of slides.
+  if (slide == 0) return formatNotes(titleNotes);
+  // Check if section is valid before retrieving Notes.
+  var section = sections[slide - 1];
+  return section ? formatNotes(section.Notes) : '';
+}
+
+/* Presenter controls */
+
+// createPresenterControls returns an element for doc, which is the
+// document of the notes window or of the presenter view, showing a
+// timer, which is reset when clicked, and buttons that move to the
+// previous and next slides.
+function createPresenterControls(doc) {
+  var controls = doc.createElement('div');
+  controls.id = 'presenter-controls';
+
+  var prev = doc.createElement('button');
+  prev.textContent = '\u25C0';
+  prev.title = 'Previous slide';
+  prev.onclick = function() {
+    prevSlide();
+  };
+  controls.appendChild(prev);
+
+  var timer = doc.createElement('span');
+  timer.id = 'presenter-timer';
+  timer.title = 'Click to reset';
+  var start = Date.now();
+  function tick() {
+    var secs = Math.floor((Date.now() - start) / 1000);
+    var mins = Math.floor(secs / 60);
+    secs %= 60;
+    timer.textContent = mins + ':' + (secs < 10 ? '0' : '') + secs;
+  }
+  timer.onclick = function() {
+    start = Date.now();
+    tick();
+  };
+  tick();
+  // Use the interval of this window, which outlives the notes window.
+  var interval = setInterval(function() {
+    if (!doc.defaultView || doc.defaultView.closed) {
+      clearInterval(interval);
+      return;
+    }
+    tick();
+  }, 1000);
+  controls.appendChild(timer);
+
+  var next = doc.createElement('button');
+  next.textContent = '\u25B6';
+  next.title = 'Next slide';
+  next.onclick = function() {
+    nextSlide();
+  };
+  controls.appendChild(next);
+
+  return controls;
+}
+
+// initPresenterView turns this window into a presenter view, which shows
+// the notes of the current slide and the presenter controls beneath the
+// slides. It is used when the presentation is opened with ?presenter in
+// the URL, typically on another device, and kept in step with the other
+// windows showing the presentation by the present server.
+function initPresenterView() {
+  document.body.classList.add('presenter-view');
+
+  var panel = document.createElement('div');
+  panel.id = 'presenter-panel';
+  panel.appendChild(createPresenterControls(document));
+  var notes = document.createElement('div');
+  notes.id = 'presenter-notes';
+  panel.appendChild(notes);
+  document.body.appendChild(panel);
+
+  var link = document.createElement('link');
+  link.rel = 'stylesheet';
+  link.type = 'text/css';
+  link.href = PERMANENT_URL_PREFIX + 'notes.css';
+  document.querySelector('head').appendChild(link);
+
+  updatePresenterView();
+}
+
+function updatePresenterView() {
+  if (!document.body.classList.contains('presenter-view')) return;
+  document.getElementById('presenter-notes').innerHTML = notesFor(curSlide);
+}
+
+/* Remote control */
+
+// remoteSocket is the connection to the /remote endpoint of the present
+// server, which relays moves to another slide between the windows showing
+// this presentation, possibly on other devices. It is null if the
+// presentation is not served by the present command.
+var remoteSocket = null;
+
+// remoteMove is set while moving to a slide received from remoteSocket,
+// so that the move is not sent back.
+var remoteMove = false;
+
+function connectRemote() {
+  if (!window.WebSocket || !/^https?:$/.test(location.protocol)) return;
+  var scheme = location.protocol == 'https:' ? 'wss:' : 'ws:';
+  var ws = new WebSocket(
+    scheme + '//' + location.host + '/remote?deck=' + encodeURIComponent(location.pathname)
+  );
+  ws.onopen = function() {
+    remoteSocket = ws;
+  };
+  ws.onclose = function() {
+    remoteSocket = null;
+  };
+  ws.onmessage = function(e) {
+    var m = JSON.parse(e.data);
+    remoteMove = true;
+    try {
+      goToSlide(m.Slide);
+    } finally {
+      remoteMove = false;
+    }
+  };
+}
+
+// syncSlide tells the other windows showing this presentation
+// that it has moved to the current slide.
+function syncSlide() {
+  localStorage.setItem(destSlideKey(), curSlide);
+  if (remoteSocket && !remoteMove) {
+    remoteSocket.send(JSON.stringify({ Slide: curSlide }));
   }
+  updatePresenterView();
 }
 
 /* Playground syncing */
@@ -212,7 +212,7 @@ function prevSlide() {
     updateSlides();
   }
 
-  if (notesEnabled) localStorage.setItem(destSlideKey(), curSlide);
+  if (notesEnabled) syncSlide();
 }
 
 function nextSlide() {
@@ -223,7 +223,17 @@ function nextSlide() {
     updateSlides();
   }
 
-  if (notesEnabled) localStorage.setItem(destSlideKey(), curSlide);
+  if (notesEnabled) syncSlide();
+}
+
+// goToSlide moves to the given slide, where slide 0 is the title slide.
+function goToSlide(slide) {
+  while (slide > curSlide && curSlide < slideEls.length - 1) {
+    nextSlide();
+  }
+  while (slide < curSlide) {
+    prevSlide();
+  }
 }
 
 /* Slide events */
@@ -613,6 +623,13 @@ function setupNotesSync() {
   setupPlayResizeSync();
   localStorage.setItem(destSlideKey(), curSlide);
   window.addEventListener('storage', updateOtherWindow, false);
+
+  // Only the top-level window talks to the server; the slides in the
+  // notes window follow it through local storage.
+  if (isParentWindow) {
+    connectRemote();
+    if (/[?&]presenter\b/.test(location.search)) initPresenterView();
+  }
 }
 
 // An update to local storage is caught only by the other window
@@ -622,13 +639,7 @@ function updateOtherWindow(e) {
   var isRemoveStorageEvent = !e.newValue;
   if (isRemoveStorageEvent) return;
 
-  var destSlide = localStorage.getItem(destSlideKey());
-  while (destSlide > curSlide) {
-    nextSlide();
-  }
-  while (destSlide < curSlide) {
-    prevSlide();
-  }
+  goToSlide(parseInt(localStorage.getItem(destSlideKey()), 10));
 
   updatePlay(e);
   updateNotes();
@@ -40,6 +40,8 @@ var deferflag = flag.Bool("defer", true, "Include testing of defer stmts.")
 var recurflag = flag.Bool("recur", true, "Include testing of recursive calls.")
 var takeaddrflag = flag.Bool("takeaddr", true, "Include functions that take the address of their parameters and results.")
 var methodflag = flag.Bool("method", true, "Include testing of method calls.")
+var genericsflag = flag.Bool("generics", true, "Include testing of generic functions.")
+var ifaceflag = flag.Bool("iface", true, "Include testing of interface-typed params and returns.")
 var inlimitflag = flag.Int("inmax", -1, "Max number of input params.")
 var outlimitflag = flag.Int("outmax", -1, "Max number of input params.")
 var pragmaflag = flag.String("pragma", "", "Tag generated test routines with pragma //go:<value>.")
@@ -98,6 +100,12 @@ func setupTunables() {
 	if !*methodflag {
 		tunables.DisableMethodCalls()
 	}
+	if !*genericsflag {
+		tunables.DisableGenerics()
+	}
+	if !*ifaceflag {
+		tunables.DisableInterfaces()
+	}
 	if *inlimitflag != -1 {
 		tunables.LimitInputs(*inlimitflag)
 	}
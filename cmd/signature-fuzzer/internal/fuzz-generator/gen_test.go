@@ -149,6 +149,8 @@ func TestExhaustive(t *testing.T) {
 				tunables.takeAddress = false
 				tunables.doFuncCallValues = false
 				tunables.doSkipCompare = false
+				tunables.genericPerc = 0
+				tunables.ifacePerc = 0
 				checkTunables(tunables)
 			},
 		},
@@ -212,6 +214,20 @@ func TestExhaustive(t *testing.T) {
 				checkTunables(tunables)
 			},
 		},
+		{
+			"addgenerics",
+			func() {
+				tunables.genericPerc = 30
+				checkTunables(tunables)
+			},
+		},
+		{
+			"addiface",
+			func() {
+				tunables.ifacePerc = 30
+				checkTunables(tunables)
+			},
+		},
 	}
 
 	// Loop over scenarios and make sure each one works properly.
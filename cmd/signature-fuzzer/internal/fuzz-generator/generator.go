@@ -218,6 +218,16 @@ type TunableParams struct {
 	// Fraction of the time that we decided to skip sub-components of
 	// composite values. Ranges from 0 to 100.
 	skipCompareFraction uint8
+
+	// Percentage of the numeric or string params and returns of a
+	// (non-method) test function whose type we turn into a type
+	// parameter of the function. Ranges from 0 to 100.
+	genericPerc uint8
+
+	// Percentage of the numeric or string params and returns whose
+	// type we turn into an interface type, whose method is then
+	// called to check the value. Ranges from 0 to 100.
+	ifacePerc uint8
 }
 
 // SetTunables accepts a TunableParams object, checks to make sure
@@ -281,6 +291,8 @@ var tunables = TunableParams{
 	doSkipCompare:         true,
 	skipCompareFraction:   10,
 	addrFractions:         [4]uint8{50, 25, 15, 10},
+	genericPerc:           15,
+	ifacePerc:             10,
 }
 
 func DefaultTunables() TunableParams {
@@ -353,6 +365,12 @@ func checkTunables(t TunableParams) {
 	if t.skipCompareFraction > 100 {
 		log.Fatal(errors.New("skipCompareFraction not between 0 and 100"))
 	}
+	if t.genericPerc > 100 {
+		log.Fatal(errors.New("genericPerc bad value, over 100"))
+	}
+	if t.ifacePerc > 100 {
+		log.Fatal(errors.New("ifacePerc bad value, over 100"))
+	}
 }
 
 func (t *TunableParams) DisableReflectionCalls() {
@@ -375,6 +393,14 @@ func (t *TunableParams) DisableDefer() {
 	t.doDefer = false
 }
 
+func (t *TunableParams) DisableGenerics() {
+	t.genericPerc = 0
+}
+
+func (t *TunableParams) DisableInterfaces() {
+	t.ifacePerc = 0
+}
+
 func (t *TunableParams) LimitInputs(n int) error {
 	if n > 100 {
 		return fmt.Errorf("value %d passed to LimitInputs is too large *(max 100)", n)
@@ -457,6 +483,8 @@ type funcdef struct {
 	structdefs  []structparm
 	arraydefs   []arrayparm
 	typedefs    []typedefparm
+	typeparams  []typeparm
+	ifacedefs   []ifaceparm
 	mapdefs     []mapparm
 	mapkeytypes []parm
 	mapkeytmps  []string
@@ -803,6 +831,29 @@ func (s *genstate) GenReturn(f *funcdef, depth int, pidx int) parm {
 	return s.GenParm(f, depth, false, pidx)
 }
 
+// wrapParm randomly decides whether to replace the type of the
+// numeric or string param or return p with a type parameter of the
+// test function (see typeparm) or an interface type (see ifaceparm),
+// returning the resulting parm.
+func (s *genstate) wrapParm(f *funcdef, p parm, pidx int) parm {
+	switch p.(type) {
+	case *numparm, *stringparm:
+	default:
+		return p
+	}
+	if p.IsControl() {
+		return p
+	}
+	// Methods can't have type parameters.
+	if !f.isMethod && uint8(s.wr.Intn(100)) < s.tunables.genericPerc {
+		return s.makeTypeParm(f, p)
+	}
+	if uint8(s.wr.Intn(100)) < s.tunables.ifacePerc {
+		return s.makeIfaceParm(f, p, pidx)
+	}
+	return p
+}
+
 // GenFunc cooks up the random signature (and other attributes) of a
 // given checker function, returning a funcdef object that describes
 // the new fcn.
@@ -833,7 +884,7 @@ func (s *genstate) GenFunc(fidx int, pidx int) *funcdef {
 	f.dodefc = uint8(s.wr.Intn(100))
 	pTaken := uint8(s.wr.Intn(100)) < s.tunables.takenFraction
 	for pi := 0; pi < numParams; pi++ {
-		newparm := s.GenParm(f, 0, needControl, pidx)
+		newparm := s.wrapParm(f, s.GenParm(f, 0, needControl, pidx), pidx)
 		if !pTaken {
 			newparm.SetAddrTaken(notAddrTaken)
 		}
@@ -849,7 +900,7 @@ func (s *genstate) GenFunc(fidx int, pidx int) *funcdef {
 
 	rTaken := uint8(s.wr.Intn(100)) < s.tunables.takenFraction
 	for ri := 0; ri < numReturns; ri++ {
-		r := s.GenReturn(f, 0, pidx)
+		r := s.wrapParm(f, s.GenReturn(f, 0, pidx), pidx)
 		if !rTaken {
 			r.SetAddrTaken(notAddrTaken)
 		}
@@ -965,6 +1016,13 @@ func (s *genstate) emitStructAndArrayDefs(f *funcdef, b *bytes.Buffer) {
 			td.target.TypeName()))
 		s.emitCompareFunc(f, b, &td)
 	}
+	for _, tp := range f.typeparams {
+		tp.emitConstraint(b)
+	}
+	for _, ip := range f.ifacedefs {
+		ip.emitDefs(b)
+		s.emitCompareFunc(f, b, &ip)
+	}
 	if f.mapkeyts != "" {
 		b.WriteString(fmt.Sprintf("type %s struct {\n", f.mapkeyts))
 		for i := range f.mapkeytypes {
@@ -1144,7 +1202,7 @@ func (s *genstate) emitCaller(f *funcdef, b *bytes.Buffer, pidx int) {
 	if f.isMethod {
 		pref = "rcvr"
 	}
-	b.WriteString(fmt.Sprintf("%s.Test%d(", pref, f.idx))
+	b.WriteString(fmt.Sprintf("%s.Test%d%s(", pref, f.idx, f.typeArgs(true)))
 	for pi := range f.params {
 		writeCom(b, pi)
 		b.WriteString(fmt.Sprintf("p%d", pi))
@@ -1163,8 +1221,8 @@ func (s *genstate) emitCaller(f *funcdef, b *bytes.Buffer, pidx int) {
 			b.WriteString("  rcv := reflect.ValueOf(rcvr)\n")
 			b.WriteString(fmt.Sprintf("  rc := rcv.MethodByName(\"Test%d\")\n", f.idx))
 		} else {
-			b.WriteString(fmt.Sprintf("  rc := reflect.ValueOf(%s.Test%d)\n",
-				s.checkerPkg(pidx), f.idx))
+			b.WriteString(fmt.Sprintf("  rc := reflect.ValueOf(%s.Test%d%s)\n",
+				s.checkerPkg(pidx), f.idx, f.typeArgs(true)))
 		}
 		b.WriteString("  ")
 		if len(f.returns) > 0 {
@@ -1682,7 +1740,16 @@ func (s *genstate) emitChecker(f *funcdef, b *bytes.Buffer, pidx int, emit bool)
 		b.WriteString(")")
 	}
 
-	b.WriteString(fmt.Sprintf(" Test%d(", f.idx))
+	b.WriteString(fmt.Sprintf(" Test%d", f.idx))
+	if len(f.typeparams) > 0 {
+		b.WriteString("[")
+		for ti, tp := range f.typeparams {
+			writeCom(b, ti)
+			b.WriteString(fmt.Sprintf("%s %s", tp.tname, tp.cname))
+		}
+		b.WriteString("]")
+	}
+	b.WriteString("(")
 
 	verb(4, "emitting checker p%d/Test%d", pidx, f.idx)
 
@@ -1798,6 +1865,28 @@ func (f *funcdef) complexityMeasure() int {
 	return v
 }
 
+// typeArgs returns the list of type arguments with which to
+// instantiate a generic test function, or "" if it isn't generic.
+// The caller uses the types of the params and returns, whereas the
+// checker passes its own type parameters on a recursive call.
+func (f *funcdef) typeArgs(caller bool) string {
+	if len(f.typeparams) == 0 {
+		return ""
+	}
+	b := bytes.NewBuffer(nil)
+	b.WriteString("[")
+	for ti, tp := range f.typeparams {
+		writeCom(b, ti)
+		if caller {
+			b.WriteString(tp.QualName())
+		} else {
+			b.WriteString(tp.tname)
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
 // emitRecursiveCall generates a recursive call to the test function in question.
 func (s *genstate) emitRecursiveCall(f *funcdef) string {
 	b := bytes.NewBuffer(nil)
@@ -1805,7 +1894,7 @@ func (s *genstate) emitRecursiveCall(f *funcdef) string {
 	if f.isMethod {
 		rcvr = "rcvr."
 	}
-	b.WriteString(fmt.Sprintf(" %sTest%d(", rcvr, f.idx))
+	b.WriteString(fmt.Sprintf(" %sTest%d%s(", rcvr, f.idx, f.typeArgs(false)))
 	for pi, p := range f.params {
 		writeCom(b, pi)
 		if p.IsControl() {
@@ -2255,7 +2344,7 @@ func Generate(c GenConfig) int {
 	if err != nil {
 		log.Fatal(err)
 	}
-	outf.WriteString(fmt.Sprintf("module %s\n\ngo 1.17\n", s.PkgPath))
+	outf.WriteString(fmt.Sprintf("module %s\n\ngo 1.18\n", s.PkgPath))
 	outf.Close()
 
 	verb(1, "closing files")
@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ifaceparm describes a parameter of interface type; it implements
+// the "parm" interface. The interface has a single method Value that
+// returns a value of type 'target', and is implemented by two
+// generated types, one with a value receiver and one with a pointer
+// receiver; the dynamic type of a given parm is one of them. Its
+// value is checked by calling the Value method.
+type ifaceparm struct {
+	aname  string
+	pkg    string // checker package
+	ptr    bool   // dynamic type is the pointer-receiver implementation
+	target parm
+	isBlank
+	addrTakenHow
+	isGenValFunc
+	skipCompare
+}
+
+func (p ifaceparm) qualify(n string, caller bool) string {
+	if caller {
+		return p.pkg + "." + n
+	}
+	return n
+}
+
+// implName returns the name of the implementation with a value
+// receiver, and ptrImplName that of the one with a pointer receiver.
+func (p ifaceparm) implName() string    { return "Impl" + p.aname }
+func (p ifaceparm) ptrImplName() string { return "PtrImpl" + p.aname }
+
+func (p ifaceparm) Declare(b *bytes.Buffer, prefix string, suffix string, caller bool) {
+	b.WriteString(fmt.Sprintf("%s %s%s", prefix, p.qualify(p.aname, caller), suffix))
+}
+
+func (p ifaceparm) GenElemRef(elidx int, path string) (string, parm) {
+	return p.target.GenElemRef(elidx, path+".Value()")
+}
+
+func (p ifaceparm) GenValue(s *genstate, f *funcdef, value int, caller bool) (string, int) {
+	rv, v := s.GenValue(f, p.target, value, caller)
+	if p.ptr {
+		return fmt.Sprintf("&%s{%s}", p.qualify(p.ptrImplName(), caller), rv), v
+	}
+	return fmt.Sprintf("%s(%s)", p.qualify(p.implName(), caller), rv), v
+}
+
+func (p ifaceparm) IsControl() bool {
+	return false
+}
+
+func (p ifaceparm) NumElements() int {
+	return 1
+}
+
+func (p ifaceparm) String() string {
+	return fmt.Sprintf("%s interface with Value() %s", p.aname, p.target.String())
+}
+
+func (p ifaceparm) TypeName() string {
+	return p.aname
+}
+
+func (p ifaceparm) QualName() string {
+	return p.qualify(p.aname, true)
+}
+
+// HasPointer returns true, so that interface values are compared
+// using a generated equal func that calls their Value methods.
+func (p ifaceparm) HasPointer() bool {
+	return true
+}
+
+// emitDefs writes out the definitions of the interface type and its
+// implementations.
+func (p ifaceparm) emitDefs(b *bytes.Buffer) {
+	tn := p.target.TypeName()
+	b.WriteString(fmt.Sprintf("type %s interface {\n  Value() %s\n}\n\n", p.aname, tn))
+	b.WriteString(fmt.Sprintf("type %s %s\n\n", p.implName(), tn))
+	b.WriteString("//go:noinline\n")
+	b.WriteString(fmt.Sprintf("func (x %s) Value() %s {\n  return %s(x)\n}\n\n", p.implName(), tn, tn))
+	b.WriteString(fmt.Sprintf("type %s struct {\n  V %s\n}\n\n", p.ptrImplName(), tn))
+	b.WriteString("//go:noinline\n")
+	b.WriteString(fmt.Sprintf("func (x *%s) Value() %s {\n  return x.V\n}\n\n", p.ptrImplName(), tn))
+}
+
+func (s *genstate) makeIfaceParm(f *funcdef, target parm, pidx int) parm {
+	var ip ifaceparm
+	ns := len(f.ifacedefs)
+	ip.aname = fmt.Sprintf("IfaceF%dI%d", f.idx, ns)
+	ip.pkg = s.checkerPkg(pidx)
+	ip.ptr = uint8(s.wr.Intn(100)) < 50
+	ip.target = target
+	ip.SetBlank(target.IsBlank())
+	ip.SetAddrTaken(target.AddrTaken())
+	target.SetBlank(false)
+	target.SetAddrTaken(notAddrTaken)
+	f.ifacedefs = append(f.ifacedefs, ip)
+	return &ip
+}
@@ -199,6 +199,10 @@ func containedParms(p parm) []parm {
 			addToWork(x.totype)
 		case *typedefparm:
 			addToWork(x.target)
+		case *typeparm:
+			addToWork(x.target)
+		case *ifaceparm:
+			addToWork(x.target)
 		}
 	}
 	rv := []parm{}
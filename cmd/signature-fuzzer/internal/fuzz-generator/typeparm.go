@@ -0,0 +1,139 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// typeparm describes a parameter whose type is a type parameter of
+// the checker function; it implements the "parm" interface. The type
+// parameter is constrained by a generated interface whose type set
+// includes the type of 'target', and the caller instantiates the
+// checker with that type.
+type typeparm struct {
+	tname      string   // name of the type parameter, e.g. "T0"
+	cname      string   // name of its constraint, e.g. "ConstraintF3T0"
+	terms      []string // union terms of the constraint, e.g. "~int8"
+	comparable bool     // whether the constraint embeds comparable
+	target     parm
+	isBlank
+	addrTakenHow
+	isGenValFunc
+	skipCompare
+}
+
+func (p typeparm) Declare(b *bytes.Buffer, prefix string, suffix string, caller bool) {
+	if caller {
+		p.target.Declare(b, prefix, suffix, caller)
+		return
+	}
+	b.WriteString(fmt.Sprintf("%s %s%s", prefix, p.tname, suffix))
+}
+
+func (p typeparm) GenElemRef(elidx int, path string) (string, parm) {
+	return path, &p
+}
+
+func (p typeparm) GenValue(s *genstate, f *funcdef, value int, caller bool) (string, int) {
+	rv, v := s.GenValue(f, p.target, value, caller)
+	if !caller {
+		// Convert the value of the type argument to the type parameter.
+		rv = p.tname + "(" + rv + ")"
+	}
+	return rv, v
+}
+
+func (p typeparm) IsControl() bool {
+	return false
+}
+
+func (p typeparm) NumElements() int {
+	return 1
+}
+
+func (p typeparm) String() string {
+	return fmt.Sprintf("%s type parameter instantiated with %s", p.tname, p.target.String())
+}
+
+func (p typeparm) TypeName() string {
+	return p.tname
+}
+
+// QualName returns the type argument, which is the type that the
+// caller sees.
+func (p typeparm) QualName() string {
+	return p.target.QualName()
+}
+
+func (p typeparm) HasPointer() bool {
+	return false
+}
+
+// emitConstraint writes out the definition of the constraint.
+func (p typeparm) emitConstraint(b *bytes.Buffer) {
+	b.WriteString(fmt.Sprintf("type %s interface {\n", p.cname))
+	if p.comparable {
+		b.WriteString("  comparable\n")
+	}
+	b.WriteString(fmt.Sprintf("  %s\n}\n\n", strings.Join(p.terms, " | ")))
+}
+
+// widerTypes returns the names of the numeric types to which any
+// value of the type of p may be converted without loss, not
+// including p's own type.
+func widerTypes(p parm) []string {
+	np, ok := p.(*numparm)
+	if !ok {
+		return nil
+	}
+	var rv []string
+	switch np.tag {
+	case "int", "uint":
+		for w := np.widthInBits * 2; w <= 64; w *= 2 {
+			rv = append(rv, fmt.Sprintf("%s%d", np.tag, w))
+		}
+	case "byte":
+		rv = []string{"uint16", "uint32", "uint64"}
+	case "float":
+		if np.widthInBits == 32 {
+			rv = []string{"float64"}
+		}
+	case "complex":
+		if np.widthInBits == 64 {
+			rv = []string{"complex128"}
+		}
+	}
+	return rv
+}
+
+func (s *genstate) makeTypeParm(f *funcdef, target parm) parm {
+	var tp typeparm
+	ns := len(f.typeparams)
+	tp.tname = fmt.Sprintf("T%d", ns)
+	tp.cname = fmt.Sprintf("ConstraintF%dT%d", f.idx, ns)
+	tilde := ""
+	if uint8(s.wr.Intn(100)) < 50 {
+		tilde = "~"
+	}
+	tp.terms = []string{tilde + target.TypeName()}
+	// The type set may also include wider types, since the values
+	// converted to the type parameter must be representable by
+	// every type in its type set.
+	for _, t := range widerTypes(target) {
+		if uint8(s.wr.Intn(100)) < 50 {
+			tp.terms = append(tp.terms, tilde+t)
+		}
+	}
+	tp.comparable = uint8(s.wr.Intn(100)) < 25
+	tp.target = target
+	tp.SetBlank(target.IsBlank())
+	target.SetBlank(false)
+	target.SetAddrTaken(notAddrTaken)
+	f.typeparams = append(f.typeparams, tp)
+	return &tp
+}
@@ -444,22 +444,50 @@ func (s *Segment) UncompressedSize(t *FileTOC, align uint64) uint64 {
 	return (sz + align - 1) & uint64(-int64(align))
 }
 
+// UncompressedSize returns the size of the section's contents
+// once uncompressed.
 func (s *Section) UncompressedSize() uint64 {
-	if !strings.HasPrefix(s.Name, "__z") {
-		return s.Size
+	if size, ok := s.compressedSize(); ok {
+		return size
+	}
+	return s.Size
+}
+
+// compressedSize reports whether the section is compressed, and if so,
+// returns its uncompressed size. A compressed section has a name
+// beginning "__z" and contents beginning with "ZLIB" and the
+// uncompressed size as a big-endian uint64.
+func (s *Section) compressedSize() (uint64, bool) {
+	if !strings.HasPrefix(s.Name, "__z") || s.Size < 12 {
+		return 0, false
 	}
 	b := make([]byte, 12)
-	n, err := s.sr.ReadAt(b, 0)
-	if err != nil {
+	if _, err := s.sr.ReadAt(b, 0); err != nil {
 		panic("Malformed object file")
 	}
-	if n != len(b) {
-		return s.Size
+	if string(b[:4]) != "ZLIB" {
+		return 0, false
 	}
-	if string(b[:4]) == "ZLIB" {
-		return binary.BigEndian.Uint64(b[4:12])
+	return binary.BigEndian.Uint64(b[4:12]), true
+}
+
+// UncompressedName returns the name of the section once uncompressed.
+// It differs from the section's name only for compressed DWARF
+// sections, whose names have a "__z" prefix in place of "__" and may
+// be truncated differently, such as "__zdebug_loclist" for
+// "__debug_loclists".
+func (s *Section) UncompressedName() string {
+	if _, ok := s.compressedSize(); !ok {
+		return s.Name
 	}
-	return s.Size
+	if suffix := dwarfSuffix(s); suffix != "" {
+		name := "__debug_" + suffix
+		if len(name) > 16 {
+			name = name[:16]
+		}
+		return name
+	}
+	return "__" + s.Name[3:]
 }
 
 func (s *Section) PutData(b []byte) {
@@ -471,31 +499,23 @@ func (s *Section) PutData(b []byte) {
 }
 
 func (s *Section) PutUncompressedData(b []byte) {
-	if strings.HasPrefix(s.Name, "__z") {
-		bb := make([]byte, 12)
-		n, err := s.sr.ReadAt(bb, 0)
+	if size, ok := s.compressedSize(); ok {
+		// Decompress starting at b[12:]
+		r, err := zlib.NewReader(io.NewSectionReader(s, 12, int64(s.Size)-12))
 		if err != nil {
-			panic("Malformed object file")
+			panic("Malformed object file (zlib.NewReader error)")
 		}
-		if n == len(bb) && string(bb[:4]) == "ZLIB" {
-			size := binary.BigEndian.Uint64(bb[4:12])
-			// Decompress starting at b[12:]
-			r, err := zlib.NewReader(io.NewSectionReader(s, 12, int64(size)-12))
-			if err != nil {
-				panic("Malformed object file (zlib.NewReader error)")
-			}
-			n, err := io.ReadFull(r, b[0:size])
-			if err != nil {
-				panic("Malformed object file (ReadFull error)")
-			}
-			if uint64(n) != size {
-				panic(fmt.Sprintf("PutUncompressedData, expected to read %d bytes, instead read %d", size, n))
-			}
-			if err := r.Close(); err != nil {
-				panic("Malformed object file (Close error)")
-			}
-			return
+		n, err := io.ReadFull(r, b[0:size])
+		if err != nil {
+			panic("Malformed object file (ReadFull error)")
+		}
+		if uint64(n) != size {
+			panic(fmt.Sprintf("PutUncompressedData, expected to read %d bytes, instead read %d", size, n))
 		}
+		if err := r.Close(); err != nil {
+			panic("Malformed object file (Close error)")
+		}
+		return
 	}
 	// Not compressed
 	s.PutData(b)
@@ -1186,19 +1206,40 @@ func (f *File) Section(name string) *Section {
 	return nil
 }
 
-// DWARF returns the DWARF debug information for the Mach-O file.
-func (f *File) DWARF() (*dwarf.Data, error) {
-	dwarfSuffix := func(s *Section) string {
-		switch {
-		case strings.HasPrefix(s.Name, "__debug_"):
-			return s.Name[8:]
-		case strings.HasPrefix(s.Name, "__zdebug_"):
-			return s.Name[9:]
-		default:
-			return ""
+// dwarfSuffix returns the name of the DWARF section held by s, without
+// its "__debug_" or "__zdebug_" prefix, or "" if s is not a DWARF section.
+func dwarfSuffix(s *Section) string {
+	sectname := s.Name
+	var pfx int
+	switch {
+	case strings.HasPrefix(sectname, "__debug_"):
+		pfx = 8
+	case strings.HasPrefix(sectname, "__zdebug_"):
+		pfx = 9
+	default:
+		return ""
+	}
+	// Mach-O executables truncate section names to 16 characters, mangling some DWARF sections.
+	// As of DWARFv5 these are the only problematic section names (see DWARFv5 Appendix G).
+	for _, longname := range []string{
+		"__debug_str_offsets",
+		"__zdebug_line_str",
+		"__zdebug_loclists",
+		"__zdebug_pubnames",
+		"__zdebug_pubtypes",
+		"__zdebug_rnglists",
+		"__zdebug_str_offsets",
+	} {
+		if sectname == longname[:16] {
+			sectname = longname
+			break
 		}
-
 	}
+	return sectname[pfx:]
+}
+
+// DWARF returns the DWARF debug information for the Mach-O file.
+func (f *File) DWARF() (*dwarf.Data, error) {
 	sectionData := func(s *Section) ([]byte, error) {
 		b, err := s.Data()
 		if err != nil && uint64(len(b)) < s.Size {
@@ -1247,10 +1288,14 @@ func (f *File) DWARF() (*dwarf.Data, error) {
 		return nil, err
 	}
 
-	// Look for DWARF4 .debug_types sections.
+	// Look for DWARF4 .debug_types sections and DWARF5 sections.
 	for i, s := range f.Sections {
 		suffix := dwarfSuffix(s)
-		if suffix != "types" {
+		if suffix == "" {
+			continue
+		}
+		if _, ok := dat[suffix]; ok {
+			// Already handled.
 			continue
 		}
 
@@ -1259,7 +1304,11 @@ func (f *File) DWARF() (*dwarf.Data, error) {
 			return nil, err
 		}
 
-		err = d.AddTypes(fmt.Sprintf("types-%d", i), b)
+		if suffix == "types" {
+			err = d.AddTypes(fmt.Sprintf("types-%d", i), b)
+		} else {
+			err = d.AddSection(".debug_"+suffix, b)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -5,6 +5,10 @@
 package macho
 
 import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -387,3 +391,58 @@ func TestTypeString(t *testing.T) {
 		t.Errorf("got %v, want %v", MhExecute.GoString(), "macho.Exec")
 	}
 }
+
+// newSection returns a section with the given name and contents,
+// compressing them as the Go linker does if compress is set.
+func newSection(t *testing.T, name string, data []byte, compress bool) *Section {
+	if compress {
+		var buf bytes.Buffer
+		buf.WriteString("ZLIB")
+		binary.Write(&buf, binary.BigEndian, uint64(len(data)))
+		w := zlib.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	}
+	r := bytes.NewReader(data)
+	s := &Section{SectionHeader: SectionHeader{Name: name, Seg: "__DWARF", Size: uint64(len(data))}}
+	s.sr = io.NewSectionReader(r, 0, int64(len(data)))
+	s.ReaderAt = s.sr
+	return s
+}
+
+func TestUncompressedSection(t *testing.T) {
+	data := bytes.Repeat([]byte("DWARF data "), 100)
+	for _, test := range []struct {
+		name     string
+		compress bool
+		want     string
+	}{
+		{"__debug_info", false, "__debug_info"},
+		{"__zdebug_info", true, "__debug_info"},
+		{"__zdebug_line_st", true, "__debug_line_str"},
+		{"__zdebug_loclist", true, "__debug_loclists"},
+		{"__zdebug_rnglist", true, "__debug_rnglists"},
+		{"__zdebug_str_off", true, "__debug_str_offs"},
+		{"__debug_str_offs", false, "__debug_str_offs"},
+		{"__zdebug_addr", false, "__zdebug_addr"}, // not actually compressed
+	} {
+		s := newSection(t, test.name, data, test.compress)
+		if got := s.UncompressedName(); got != test.want {
+			t.Errorf("UncompressedName(%s) = %s, want %s", test.name, got, test.want)
+		}
+		size := s.UncompressedSize()
+		if test.compress && size != uint64(len(data)) {
+			t.Errorf("UncompressedSize(%s) = %d, want %d", test.name, size, len(data))
+		}
+		got := make([]byte, size)
+		s.PutUncompressedData(got)
+		if test.compress && !bytes.Equal(got, data) {
+			t.Errorf("PutUncompressedData(%s) did not restore the data", test.name)
+		}
+	}
+}
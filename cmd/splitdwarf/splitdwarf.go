@@ -16,16 +16,23 @@ Unless a dSYM file name is provided on the command line,
 splitdwarf will place it where the OSX tools expect it, in
 "<osxMachoFile>.dSYM/Contents/Resources/DWARF/<osxMachoFile>",
 creating directories as necessary.
+
+Compressed DWARF sections, such as those written by the Go linker,
+are uncompressed and restored to their usual names, including the
+DWARF 5 sections whose names Mach-O truncates. If osxMachoFile is a
+universal ("fat") binary, each architecture is split separately and
+the dSYM file is itself a universal binary holding the debugging
+information of each architecture.
 */
 package main // import "golang.org/x/tools/cmd/splitdwarf"
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"syscall"
 
 	"golang.org/x/tools/cmd/splitdwarf/internal/macho"
@@ -67,12 +74,109 @@ for input_exe need to allow writing.
 	if err != nil {
 		fail("%v", err)
 	}
-	exeMacho, err := macho.NewFile(exeFile)
-	if err != nil {
-		fail("(internal) Couldn't create macho, %v", err)
+	var magic [4]byte
+	if _, err := exeFile.ReadAt(magic[:], 0); err != nil {
+		fail("%v", err)
+	}
+	var dsyms []*dsym
+	var fatExe *macho.FatFile
+	if binary.BigEndian.Uint32(magic[:]) == macho.MagicFat {
+		fatExe, err = macho.NewFatFile(exeFile)
+		if err != nil {
+			fail("(internal) Couldn't create fat macho, %v", err)
+		}
+		for _, arch := range fatExe.Arches {
+			dsyms = append(dsyms, split(inputExe, arch.File))
+		}
+	} else {
+		exeMacho, err := macho.NewFile(exeFile)
+		if err != nil {
+			fail("(internal) Couldn't create macho, %v", err)
+		}
+		dsyms = append(dsyms, split(inputExe, exeMacho))
 	}
 	// Postpone dealing with output till input is known-good
 
+	// Memory map the output file to get the buffer directly.
+	outDwarf := inputExe + ".dSYM/Contents/Resources/DWARF"
+	if len(os.Args) > 2 {
+		outDwarf = os.Args[2]
+	} else {
+		err := os.MkdirAll(outDwarf, 0755)
+		if err != nil {
+			fail("%v", err)
+		}
+		outDwarf = filepath.Join(outDwarf, filepath.Base(inputExe))
+	}
+
+	// Lay out the architectures of a universal binary,
+	// each at the alignment of its input counterpart.
+	offsets := []uint64{0}
+	size := dsyms[0].toc.FileSize()
+	if fatExe != nil {
+		offsets = offsets[:0]
+		size = uint64(8 + 5*4*len(dsyms)) // fat_header and fat_arch structs
+		for i, d := range dsyms {
+			offset := macho.RoundUp(size, 1<<fatExe.Arches[i].Align)
+			offsets = append(offsets, offset)
+			size = offset + d.toc.FileSize()
+		}
+		if size > 1<<32-1 {
+			fail("dwarf output for %s exceeds 4GB", inputExe)
+		}
+	}
+
+	dwarfFile, buffer := CreateMmapFile(outDwarf, int64(size))
+	if fatExe != nil {
+		binary.BigEndian.PutUint32(buffer[0:], macho.MagicFat)
+		binary.BigEndian.PutUint32(buffer[4:], uint32(len(dsyms)))
+		for i, d := range dsyms {
+			arch := fatExe.Arches[i].FatArchHeader
+			b := buffer[8+5*4*i:]
+			binary.BigEndian.PutUint32(b[0:], uint32(arch.Cpu))
+			binary.BigEndian.PutUint32(b[4:], arch.SubCpu)
+			binary.BigEndian.PutUint32(b[8:], uint32(offsets[i]))
+			binary.BigEndian.PutUint32(b[12:], uint32(d.toc.FileSize()))
+			binary.BigEndian.PutUint32(b[16:], arch.Align)
+		}
+	}
+	for i, d := range dsyms {
+		d.put(buffer[offsets[i]:])
+	}
+
+	err = syscall.Munmap(buffer)
+	if err != nil {
+		fail("Munmap %s for dwarf output failed, %v", outDwarf, err)
+	}
+	err = dwarfFile.Close()
+	if err != nil {
+		fail("Close %s for dwarf output after mmap/munmap failed, %v", outDwarf, err)
+	}
+
+	for i, d := range dsyms {
+		if d.exeNeedsUuid {
+			var offset uint64
+			if fatExe != nil {
+				offset = uint64(fatExe.Arches[i].Offset)
+			}
+			addUuid(inputExe, offset, d)
+		}
+	}
+}
+
+// A dsym describes the dSYM file holding the debugging information of
+// a Mach-O executable, or one architecture of a universal binary.
+type dsym struct {
+	exe          *macho.File
+	toc          *macho.FileTOC
+	uuid         *macho.Uuid
+	exeNeedsUuid bool                // whether the executable lacks the UUID
+	put          func(buffer []byte) // writes the dSYM file to buffer
+}
+
+// split prepares the dSYM file for the Mach-O executable exeMacho,
+// read from the file inputExe.
+func split(inputExe string, exeMacho *macho.File) *dsym {
 	// describe(&exeMacho.FileTOC)
 
 	// Offsets into __LINKEDIT:
@@ -247,9 +351,7 @@ for input_exe need to allow writing.
 			s.Align = 0 // This is apparently true for debugging sections; not sure if it generalizes.
 		}
 		offset += uint32(us)
-		if strings.HasPrefix(s.Name, "__z") {
-			s.Name = "__" + s.Name[3:] // remove "z"
-		}
+		s.Name = o.UncompressedName()
 		s.Reloff = 0
 		s.Nreloc = 0
 		newtoc.AddSection(s)
@@ -257,86 +359,77 @@ for input_exe need to allow writing.
 
 	// Write segments/sections.
 	// Only dwarf and linkedit contain anything interesting.
-
-	// Memory map the output file to get the buffer directly.
-	outDwarf := inputExe + ".dSYM/Contents/Resources/DWARF"
-	if len(os.Args) > 2 {
-		outDwarf = os.Args[2]
-	} else {
-		err := os.MkdirAll(outDwarf, 0755)
-		if err != nil {
-			fail("%v", err)
-		}
-		outDwarf = filepath.Join(outDwarf, filepath.Base(inputExe))
-	}
-	dwarfFile, buffer := CreateMmapFile(outDwarf, int64(newtoc.FileSize()))
-
-	// (1) Linkedit segment
-	// Symbol table
-	offset = uint32(newlinkedit.Offset)
-	for i := range linkeditsyms {
-		if exeMacho.Magic == macho.Magic64 {
-			offset += linkeditsyms[i].Put64(buffer[offset:], newtoc.ByteOrder)
-		} else {
-			offset += linkeditsyms[i].Put32(buffer[offset:], newtoc.ByteOrder)
+	put := func(buffer []byte) {
+		// (1) Linkedit segment
+		// Symbol table
+		offset := uint32(newlinkedit.Offset)
+		for i := range linkeditsyms {
+			if exeMacho.Magic == macho.Magic64 {
+				offset += linkeditsyms[i].Put64(buffer[offset:], newtoc.ByteOrder)
+			} else {
+				offset += linkeditsyms[i].Put32(buffer[offset:], newtoc.ByteOrder)
+			}
 		}
-	}
 
-	// Initial two bytes of string table, followed by actual zero-terminated strings.
-	buffer[linkeditstringbase] = ' '
-	buffer[linkeditstringbase+1] = 0
-	offset = linkeditstringbase + 2
-	for _, str := range linkeditstrings {
-		for i := 0; i < len(str); i++ {
-			buffer[offset] = str[i]
+		// Initial two bytes of string table, followed by actual zero-terminated strings.
+		buffer[linkeditstringbase] = ' '
+		buffer[linkeditstringbase+1] = 0
+		offset = linkeditstringbase + 2
+		for _, str := range linkeditstrings {
+			for i := 0; i < len(str); i++ {
+				buffer[offset] = str[i]
+				offset++
+			}
+			buffer[offset] = 0
 			offset++
 		}
-		buffer[offset] = 0
-		offset++
+
+		// (2) DWARF segment
+		ioff := newdwarf.Firstsect - dwarf.Firstsect
+		for i := dwarf.Firstsect; i < dwarf.Firstsect+dwarf.Nsect; i++ {
+			s := exeMacho.Sections[i]
+			j := i + ioff
+			s.PutUncompressedData(buffer[newtoc.Sections[j].Offset:])
+		}
+
+		// Because "text" overlaps the header and the loads, write them afterwards, just in case.
+		// Write header.
+		newtoc.Put(buffer)
 	}
 
-	// (2) DWARF segment
-	ioff := newdwarf.Firstsect - dwarf.Firstsect
-	for i := dwarf.Firstsect; i < dwarf.Firstsect+dwarf.Nsect; i++ {
-		s := exeMacho.Sections[i]
-		j := i + ioff
-		s.PutUncompressedData(buffer[newtoc.Sections[j].Offset:])
+	return &dsym{
+		exe:          exeMacho,
+		toc:          newtoc,
+		uuid:         uuid,
+		exeNeedsUuid: exeNeedsUuid,
+		put:          put,
 	}
+}
 
-	// Because "text" overlaps the header and the loads, write them afterwards, just in case.
-	// Write header.
-	newtoc.Put(buffer)
+// addUuid maps the original exe, modifies the header of the Mach-O file
+// at offset within it, and writes the UUID command of d.
+func addUuid(inputExe string, offset uint64, d *dsym) {
+	hdr := d.exe.FileTOC.FileHeader
+	oldCommandEnd := uint64(hdr.SizeCommands + d.toc.HdrSize())
+	hdr.NCommands += 1
+	hdr.SizeCommands += d.uuid.LoadSize(d.toc)
 
-	err = syscall.Munmap(buffer)
+	mapf, err := os.OpenFile(inputExe, os.O_RDWR, 0)
 	if err != nil {
-		fail("Munmap %s for dwarf output failed, %v", outDwarf, err)
+		fail("Updating UUID in binary failed, %v", err)
 	}
-	err = dwarfFile.Close()
+	defer mapf.Close()
+	// Universal binaries align each architecture to at least a page.
+	exebuf, err := syscall.Mmap(int(mapf.Fd()), int64(offset), int(macho.RoundUp(uint64(hdr.SizeCommands), 1<<pageAlign)),
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_FILE|syscall.MAP_SHARED)
 	if err != nil {
-		fail("Close %s for dwarf output after mmap/munmap failed, %v", outDwarf, err)
+		fail("Mmap of %s for UUID update failed, %v", inputExe, err)
 	}
-
-	if exeNeedsUuid { // Map the original exe, modify the header, and write the UUID command
-		hdr := exeMacho.FileTOC.FileHeader
-		oldCommandEnd := hdr.SizeCommands + newtoc.HdrSize()
-		hdr.NCommands += 1
-		hdr.SizeCommands += uuid.LoadSize(newtoc)
-
-		mapf, err := os.OpenFile(inputExe, os.O_RDWR, 0)
-		if err != nil {
-			fail("Updating UUID in binary failed, %v", err)
-		}
-		exebuf, err := syscall.Mmap(int(mapf.Fd()), 0, int(macho.RoundUp(uint64(hdr.SizeCommands), 1<<pageAlign)),
-			syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_FILE|syscall.MAP_SHARED)
-		if err != nil {
-			fail("Mmap of %s for UUID update failed, %v", inputExe, err)
-		}
-		_ = hdr.Put(exebuf, newtoc.ByteOrder)
-		_ = uuid.Put(exebuf[oldCommandEnd:], newtoc.ByteOrder)
-		err = syscall.Munmap(exebuf)
-		if err != nil {
-			fail("Munmap of %s for UUID update failed, %v", inputExe, err)
-		}
+	_ = hdr.Put(exebuf, d.toc.ByteOrder)
+	_ = d.uuid.Put(exebuf[oldCommandEnd:], d.toc.ByteOrder)
+	err = syscall.Munmap(exebuf)
+	if err != nil {
+		fail("Munmap of %s for UUID update failed, %v", inputExe, err)
 	}
 }
 
@@ -29,6 +29,17 @@ import (
 // after generating the string method for its type. The rule is that for testdata/x.go
 // we run stringer -type X and then compile and run the program. The resulting
 // binary panics if the String method for X is not correct, including for error cases.
+// The flags in endToEndFlags are passed to stringer for the files that need them.
+
+// endToEndFlags holds the additional flags to pass
+// to stringer for each file in testdata.
+var endToEndFlags = map[string][]string{
+	"flags.go":   {"-flags", "-values", "-isvalid"},
+	"flt.go":     {"-parse", "-values", "-isvalid"},
+	"marshal.go": {"-text", "-json"},
+	"parse.go":   {"-parse", "-values", "-isvalid"},
+	"str.go":     {"-text", "-json", "-parse", "-values", "-isvalid"},
+}
 
 func TestMain(m *testing.M) {
 	if os.Getenv("STRINGER_TEST_IS_STRINGER") != "" {
@@ -81,7 +92,7 @@ func TestEndToEnd(t *testing.T) {
 			if name == "cgo.go" {
 				testenv.NeedsTool(t, "cgo")
 			}
-			stringerCompileAndRun(t, t.TempDir(), stringer, typeName(name), name)
+			stringerCompileAndRun(t, t.TempDir(), stringer, typeName(name), name, endToEndFlags[name]...)
 		})
 	}
 }
@@ -339,7 +350,8 @@ func stringerPath(t *testing.T) string {
 
 // stringerCompileAndRun runs stringer for the named file and compiles and
 // runs the target binary in directory dir. That binary will panic if the String method is incorrect.
-func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName string) {
+// The flags are passed to stringer.
+func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName string, flags ...string) {
 	t.Logf("run: %s %s\n", fileName, typeName)
 	source := filepath.Join(dir, path.Base(fileName))
 	err := copy(source, filepath.Join("testdata", fileName))
@@ -348,7 +360,8 @@ func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName strin
 	}
 	stringSource := filepath.Join(dir, typeName+"_string.go")
 	// Run stringer in temporary directory.
-	err = run(t, stringer, "-type", typeName, "-output", stringSource, source)
+	args := append([]string{"-type", typeName, "-output", stringSource}, flags...)
+	err = run(t, stringer, append(args, source)...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -37,6 +37,7 @@ var golden = []Golden{
 	{"prime", "", false, prime_in, prime_out},
 	{"prefix", "Type", false, prefix_in, prefix_out},
 	{"tokens", "", true, tokens_in, tokens_out},
+	{"color", "", false, color_in, color_out},
 }
 
 // Each example starts with "type XXX [u]int", with a single space separating them.
@@ -448,6 +449,113 @@ func (i Token) String() string {
 }
 `
 
+// String-typed constants, which the generated code refers to by name.
+const color_in = `type Color string
+const (
+	Red   Color = "red"
+	Green Color = "green"
+	Blue  Color = "blue"
+	Azure Color = "blue"
+)
+`
+
+const color_out = `
+const _Color_name = "BlueGreenRed"
+
+var _Color_map = map[Color]string{
+	Blue:  _Color_name[0:4],
+	Green: _Color_name[4:9],
+	Red:   _Color_name[9:12],
+}
+
+func (i Color) String() string {
+	if str, ok := _Color_map[i]; ok {
+		return str
+	}
+	return "Color(" + strconv.Quote(string(i)) + ")"
+}
+`
+
+// goldenMarshal holds the tests of string-typed constants
+// with -text and -json, whose methods encode the values themselves.
+var goldenMarshal = []Golden{
+	{"color_marshal", "", false, color_in, color_out + colorMarshal_out},
+	{"color_parse", "", false, color_in, color_out + colorParse_out + colorMarshal_out + colorParseFunc_out},
+}
+
+const colorMarshal_out = `
+// MarshalText implements the encoding.TextMarshaler interface.
+// It returns an error if i is not one of the named values of the type.
+// Note that encoding/json does not call it for map keys, which it
+// writes as they are, since the type is a string type.
+func (i Color) MarshalText() ([]byte, error) {
+	if _, ok := _Color_map[i]; !ok {
+		return nil, fmt.Errorf("invalid Color value %q", string(i))
+	}
+	return []byte(i), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It returns an error if text is not one of the named values of the type.
+func (i *Color) UnmarshalText(text []byte) error {
+	v := Color(text)
+	if _, ok := _Color_map[v]; !ok {
+		return fmt.Errorf("invalid Color %q", text)
+	}
+	*i = v
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface,
+// encoding i as a JSON string holding its value.
+// It returns an error if i is not one of the named values of the type.
+// Note that encoding/json does not call it for map keys, which it
+// writes as they are, since the type is a string type.
+func (i Color) MarshalJSON() ([]byte, error) {
+	if _, ok := _Color_map[i]; !ok {
+		return nil, fmt.Errorf("invalid Color value %q", string(i))
+	}
+	return json.Marshal(string(i))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It returns an error if data is not a JSON string
+// holding one of the named values of the type.
+func (i *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Color should be a string, got %s", data)
+	}
+	v := Color(s)
+	if _, ok := _Color_map[v]; !ok {
+		return fmt.Errorf("invalid Color %q", s)
+	}
+	*i = v
+	return nil
+}
+`
+
+// With -parse, the names are parsed nonetheless.
+const colorParse_out = `
+var _Color_byName = map[string]Color{
+	"Blue":  Blue,
+	"Green": Green,
+	"Red":   Red,
+}
+`
+
+const colorParseFunc_out = `
+// ParseColor returns the Color value with the given name,
+// or an error if there is none.
+func ParseColor(s string) (Color, error) {
+	v, ok := _Color_byName[s]
+	if !ok {
+		return "", fmt.Errorf("invalid Color %q", s)
+	}
+	return v, nil
+}
+`
+
 func TestGolden(t *testing.T) {
 	testenv.NeedsTool(t, "go")
 
@@ -455,33 +563,46 @@ func TestGolden(t *testing.T) {
 	for _, test := range golden {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			input := "package test\n" + test.input
-			file := test.name + ".go"
-			absFile := filepath.Join(dir, file)
-			err := os.WriteFile(absFile, []byte(input), 0644)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			pkgs := loadPackages([]string{absFile}, nil, test.trimPrefix, test.lineComment, t.Logf)
-			if len(pkgs) != 1 {
-				t.Fatalf("got %d parsed packages but expected 1", len(pkgs))
-			}
-			// Extract the name and type of the constant from the first line.
-			tokens := strings.SplitN(test.input, " ", 3)
-			if len(tokens) != 3 {
-				t.Fatalf("%s: need type declaration on first line", test.name)
-			}
-
-			g := Generator{
-				pkg:  pkgs[0],
-				logf: t.Logf,
-			}
-			g.generate(tokens[1], findValues(tokens[1], pkgs[0]))
-			got := string(g.format())
-			if got != test.output {
-				t.Errorf("%s: got(%d)\n====\n%q====\nexpected(%d)\n====\n%q", test.name, len(got), got, len(test.output), test.output)
-			}
+			testGolden(t, dir, test, Generator{})
 		})
 	}
+	for _, test := range goldenMarshal {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			g := Generator{text: true, json: true}
+			g.parse = strings.HasSuffix(test.name, "_parse")
+			testGolden(t, dir, test, g)
+		})
+	}
+}
+
+// testGolden generates the code for the type declared by the test,
+// using g, which is configured with the flags of the test.
+func testGolden(t *testing.T, dir string, test Golden, g Generator) {
+	input := "package test\n" + test.input
+	file := test.name + ".go"
+	absFile := filepath.Join(dir, file)
+	err := os.WriteFile(absFile, []byte(input), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkgs := loadPackages([]string{absFile}, nil, test.lineComment, t.Logf)
+	if len(pkgs) != 1 {
+		t.Fatalf("got %d parsed packages but expected 1", len(pkgs))
+	}
+	// Extract the name and type of the constant from the first line.
+	tokens := strings.SplitN(test.input, " ", 3)
+	if len(tokens) != 3 {
+		t.Fatalf("%s: need type declaration on first line", test.name)
+	}
+
+	g.pkg = pkgs[0]
+	g.logf = t.Logf
+	typ := typeSpec{name: tokens[1], trimPrefix: test.trimPrefix}
+	g.generate(typ.name, findValues(typ, pkgs[0]))
+	got := string(g.format())
+	if got != test.output {
+		t.Errorf("%s: got(%d)\n====\n%q====\nexpected(%d)\n====\n%q", test.name, len(got), got, len(test.output), test.output)
+	}
 }
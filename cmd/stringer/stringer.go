@@ -12,8 +12,8 @@
 // It has helpful defaults designed for use with go generate.
 //
 // Stringer works best with constants that are consecutive values such as created using iota,
-// but creates good code regardless. In the future it might also provide custom support for
-// constant sets that are bit patterns.
+// but creates good code regardless. For constant sets that are bit patterns, see the
+// -flags flag below.
 //
 // For example, given this snippet,
 //
@@ -54,9 +54,9 @@
 // or a set of Go source files that represent a single Go package.
 //
 // The -type flag accepts a comma-separated list of types so a single run can
-// generate methods for multiple types. The default output file is t_string.go,
-// where t is the lower-cased name of the first type listed. It can be overridden
-// with the -output flag.
+// generate methods for multiple types, and may be repeated. The default output
+// file is t_string.go, where t is the lower-cased name of the first type listed.
+// It can be overridden with the -output flag.
 //
 // Types can also be declared in tests, in which case type declarations in the
 // non-test package or its test variant are preferred over types defined in the
@@ -70,6 +70,90 @@
 //	PillAspirin // Aspirin
 //
 // to suppress it in the output.
+//
+// Each type name given to the -type flag may be followed by a colon and a
+// comma-separated list of options of the form key=value that apply only to
+// that type. The option trimprefix=prefix overrides the -trimprefix flag for the
+// type, and the option transform=t changes the case of the names, after any
+// prefix is trimmed, where t is one of lower, upper, snake (lower case words
+// separated by underscores), or kebab (lower case words separated by hyphens).
+// For example,
+//
+//	stringer -type=Pill:trimprefix=Pill,transform=snake -type=Color:transform=upper
+//
+// prints PillBigRed as "big_red" and Red as "RED". The transformations do not
+// apply to line comments.
+//
+// The -text flag additionally generates MarshalText and UnmarshalText methods,
+// so that the type implements encoding.TextMarshaler and encoding.TextUnmarshaler
+// and its values can be stored by name in configuration files, and, by packages
+// such as encoding/json, in map keys and values. The -json flag generates
+// MarshalJSON and UnmarshalJSON methods that encode the values as JSON strings.
+// In either case, marshaling a value with no name, or unmarshaling a string that
+// is not a name, is an error.
+//
+// The -flags flag is for types whose constants are distinct bits, such as
+//
+//	type Perm uint
+//
+//	const (
+//		Read Perm = 1 << iota
+//		Write
+//		Exec
+//	)
+//
+// For them, stringer generates a String method that prints the names of the bits
+// that are set, separated by '|', so that Read|Write prints as "Read|Write", and
+// any remaining bits as a number. Zero prints as the name of a constant with value
+// zero, if there is one. Constants whose values have several bits set, such as masks,
+// are not used by the String method. Stringer also generates the methods
+//
+//	func (p Perm) Has(f Perm) bool // reports whether all the bits of f are set in p
+//	func (p *Perm) Set(f Perm)     // sets the bits of f in p
+//
+// The -flags flag cannot be combined with -text, -json, or -parse.
+//
+// Three more flags generate code that programs commonly need, for example
+// to validate command-line flags. For a type T, the -parse flag generates
+//
+//	func ParseT(s string) (T, error)
+//
+// which returns the value with the given name, or an error if there is none;
+// the -values flag generates
+//
+//	func TValues() []T
+//
+// which returns the distinct named values in increasing order; and the
+// -isvalid flag generates
+//
+//	func (t T) IsValid() bool
+//
+// which reports whether t is one of the named values or, with -flags, whether
+// every bit set in t is a named flag. For an unexported type t, the functions
+// are unexported too, and named parseT and tValues.
+//
+// The underlying type of T may also be a string or floating-point type,
+// as for the constants of enumerations that are encoded as strings in
+// JSON APIs:
+//
+//	type Color string
+//
+//	const (
+//		Red   Color = "red"
+//		Green Color = "green"
+//	)
+//
+// For such types the String method looks the value up in a map from the
+// constants to their names, so that Red prints as "Red" and Color("blue")
+// as `Color("blue")`, and the other methods and functions, which are
+// available for them too, except -flags, work as described above. The
+// exception is that, for a string type, the -text and -json methods
+// encode each value as itself, not as its name, as such APIs expect:
+// Red marshals as "red", and Color("blue") fails to marshal. However,
+// encoding/json writes map keys of a string type as they are, without
+// calling MarshalText, so a key with no name marshals without error.
+// Since the generated code refers to the constants by name, it need not
+// be regenerated when their values change, unless two of them become equal.
 package main // import "golang.org/x/tools/cmd/stringer"
 
 import (
@@ -86,16 +170,24 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/packages"
 )
 
 var (
-	typeNames   = flag.String("type", "", "comma-separated list of type names; must be set")
+	typeNames   typeFlag
 	output      = flag.String("output", "", "output file name; default srcdir/<type>_string.go")
 	trimprefix  = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
 	linecomment = flag.Bool("linecomment", false, "use line comment text as printed text when present")
 	buildTags   = flag.String("tags", "", "comma-separated list of build tags to apply")
+	text        = flag.Bool("text", false, "also generate MarshalText and UnmarshalText methods")
+	jsonMethods = flag.Bool("json", false, "also generate MarshalJSON and UnmarshalJSON methods")
+	flags       = flag.Bool("flags", false, "treat the constants as bit flags, printing the names of the bits that are set")
+	parse       = flag.Bool("parse", false, "also generate a ParseT function that returns the value with a given name")
+	values      = flag.Bool("values", false, "also generate a TValues function that returns the named values")
+	isValid     = flag.Bool("isvalid", false, "also generate an IsValid method that reports whether a value is named")
 )
 
 // Usage is a replacement usage function for the flags package.
@@ -109,16 +201,28 @@ func Usage() {
 	flag.PrintDefaults()
 }
 
+func init() {
+	flag.Var(&typeNames, "type", "comma-separated list of type `names`, each optionally followed by :options; may be repeated; must be set")
+}
+
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("stringer: ")
 	flag.Usage = Usage
 	flag.Parse()
-	if len(*typeNames) == 0 {
+	if len(typeNames) == 0 {
 		flag.Usage()
 		os.Exit(2)
 	}
-	types := strings.Split(*typeNames, ",")
+	if *flags && (*text || *jsonMethods || *parse) {
+		log.Fatal("-flags cannot be combined with -text, -json, or -parse")
+	}
+	types := []typeSpec(typeNames)
+	for i := range types {
+		if !types[i].hasTrimPrefix {
+			types[i].trimPrefix = *trimprefix
+		}
+	}
 	var tags []string
 	if len(*buildTags) > 0 {
 		tags = strings.Split(*buildTags, ",")
@@ -154,7 +258,7 @@ func main() {
 	// from which they were generated.
 	//
 	// Types will be excluded when generated, to avoid repetitions.
-	pkgs := loadPackages(args, tags, *trimprefix, *linecomment, nil /* logf */)
+	pkgs := loadPackages(args, tags, *linecomment, nil /* logf */)
 	sort.Slice(pkgs, func(i, j int) bool {
 		// Put x_test packages last.
 		iTest := strings.HasSuffix(pkgs[i].name, "_test")
@@ -167,7 +271,13 @@ func main() {
 	})
 	for _, pkg := range pkgs {
 		g := Generator{
-			pkg: pkg,
+			pkg:     pkg,
+			text:    *text,
+			json:    *jsonMethods,
+			flags:   *flags,
+			parse:   *parse,
+			values:  *values,
+			isValid: *isValid,
 		}
 
 		// Print the header and package clause.
@@ -175,17 +285,25 @@ func main() {
 		g.Printf("\n")
 		g.Printf("package %s", g.pkg.name)
 		g.Printf("\n")
-		g.Printf("import \"strconv\"\n") // Used by all methods.
+		switch {
+		case g.json:
+			g.Printf("import (\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"strconv\"\n)\n")
+		case g.text || g.parse:
+			g.Printf("import (\n\t\"fmt\"\n\t\"strconv\"\n)\n")
+		default:
+			g.Printf("import \"strconv\"\n") // Used by all methods.
+		}
 
 		// Run generate for types that can be found. Keep the rest for the remainingTypes iteration.
-		var foundTypes, remainingTypes []string
-		for _, typeName := range types {
-			values := findValues(typeName, pkg)
+		var foundTypes []string
+		var remainingTypes []typeSpec
+		for _, typ := range types {
+			values := findValues(typ, pkg)
 			if len(values) > 0 {
-				g.generate(typeName, values)
-				foundTypes = append(foundTypes, typeName)
+				g.generate(typ.name, values)
+				foundTypes = append(foundTypes, typ.name)
 			} else {
-				remainingTypes = append(remainingTypes, typeName)
+				remainingTypes = append(remainingTypes, typ)
 			}
 		}
 		if len(foundTypes) == 0 {
@@ -216,7 +334,11 @@ func main() {
 	}
 
 	if len(types) > 0 {
-		log.Fatalf("no values defined for types: %s", strings.Join(types, ","))
+		var names []string
+		for _, typ := range types {
+			names = append(names, typ.name)
+		}
+		log.Fatalf("no values defined for types: %s", strings.Join(names, ","))
 	}
 }
 
@@ -244,6 +366,15 @@ type Generator struct {
 	buf bytes.Buffer // Accumulated output.
 	pkg *Package     // Package we are scanning.
 
+	text bool // Generate MarshalText and UnmarshalText methods.
+	json bool // Generate MarshalJSON and UnmarshalJSON methods.
+
+	flags bool // Treat the constants as bit flags.
+
+	parse   bool // Generate a ParseT function.
+	values  bool // Generate a TValues function.
+	isValid bool // Generate an IsValid method.
+
 	logf func(format string, args ...interface{}) // test logging hook; nil when not testing
 }
 
@@ -256,10 +387,11 @@ type File struct {
 	pkg  *Package  // Package to which this file belongs.
 	file *ast.File // Parsed AST.
 	// These fields are reset for each type being generated.
-	typeName string  // Name of the constant type.
-	values   []Value // Accumulator for constant values of that type.
+	typeName   string  // Name of the constant type.
+	values     []Value // Accumulator for constant values of that type.
+	trimPrefix string  // Prefix to trim from the constant names.
+	transform  string  // Transformation of the trimmed names; see transforms.
 
-	trimPrefix  string
 	lineComment bool
 }
 
@@ -278,7 +410,7 @@ type Package struct {
 // logf is a test logging hook. It can be nil when not testing.
 func loadPackages(
 	patterns, tags []string,
-	trimPrefix string, lineComment bool,
+	lineComment bool,
 	logf func(format string, args ...interface{}),
 ) []*Package {
 	cfg := &packages.Config{
@@ -309,7 +441,6 @@ func loadPackages(
 				file: file,
 				pkg:  p,
 
-				trimPrefix:  trimPrefix,
 				lineComment: lineComment,
 			}
 		}
@@ -329,11 +460,13 @@ func loadPackages(
 	return out
 }
 
-func findValues(typeName string, pkg *Package) []Value {
+func findValues(typ typeSpec, pkg *Package) []Value {
 	values := make([]Value, 0, 100)
 	for _, file := range pkg.files {
 		// Set the state for this run of the walker.
-		file.typeName = typeName
+		file.typeName = typ.name
+		file.trimPrefix = typ.trimPrefix
+		file.transform = typ.transform
 		file.values = nil
 		if file.file != nil {
 			ast.Inspect(file.file, file.genDecl)
@@ -345,6 +478,19 @@ func findValues(typeName string, pkg *Package) []Value {
 
 // generate produces the String method for the named type.
 func (g *Generator) generate(typeName string, values []Value) {
+	if values[0].byName() {
+		// The generated code refers to the constants by name, so it
+		// needs no check that their values are unchanged; a duplicate
+		// map key error signifies that two of them have become equal.
+		if g.flags {
+			log.Fatalf("-flags requires a type whose underlying type is an integer type, not %s", typeName)
+		}
+		runs := splitIntoRuns(values)
+		g.buildMap(runs, typeName)
+		g.buildExtras(runs, typeName)
+		return
+	}
+
 	// Generate code that will fail if the constants change value.
 	g.Printf("func _() {\n")
 	g.Printf("\t// An \"invalid array index\" compiler error signifies that the constant values have changed.\n")
@@ -355,6 +501,11 @@ func (g *Generator) generate(typeName string, values []Value) {
 	}
 	g.Printf("}\n")
 	runs := splitIntoRuns(values)
+	if g.flags {
+		g.buildFlags(runs, typeName)
+		g.buildExtras(runs, typeName)
+		return
+	}
 	// The decision of which pattern to use depends on the number of
 	// runs in the numbers. If there's only one, it's easy. For more than
 	// one, there's a tradeoff between complexity and size of the data
@@ -375,6 +526,21 @@ func (g *Generator) generate(typeName string, values []Value) {
 	default:
 		g.buildMap(runs, typeName)
 	}
+	g.buildExtras(runs, typeName)
+}
+
+// buildExtras generates the functions and methods requested
+// in addition to the String method.
+func (g *Generator) buildExtras(runs [][]Value, typeName string) {
+	if g.text || g.json || g.parse {
+		g.buildByName(runs, typeName)
+	}
+	if g.values {
+		g.buildValues(runs, typeName)
+	}
+	if g.isValid {
+		g.buildIsValid(runs, typeName)
+	}
 }
 
 // splitIntoRuns breaks the values into runs of contiguous sequences.
@@ -390,7 +556,7 @@ func splitIntoRuns(values []Value) [][]Value {
 	// to fail to compile.
 	j := 1
 	for i := 1; i < len(values); i++ {
-		if values[i].value != values[i-1].value {
+		if !values[i].equal(&values[i-1]) {
 			values[j] = values[i]
 			j++
 		}
@@ -399,8 +565,9 @@ func splitIntoRuns(values []Value) [][]Value {
 	runs := make([][]Value, 0, 10)
 	for len(values) > 0 {
 		// One contiguous sequence per outer loop.
+		// Strings and floats are not contiguous.
 		i := 1
-		for i < len(values) && values[i].value == values[i-1].value+1 {
+		for i < len(values) && !values[i].byName() && values[i].value == values[i-1].value+1 {
 			i++
 		}
 		runs = append(runs, values[:i])
@@ -434,12 +601,34 @@ type Value struct {
 	value  uint64 // Will be converted to int64 when needed.
 	signed bool   // Whether the constant is a signed type.
 	str    string // The string representation given by the "go/constant" package.
+
+	// For a type whose underlying type is a string or floating-point
+	// type, kind is constant.String or constant.Float, val holds the
+	// value, and str is the name of the constant, since the generated
+	// code refers to such values by name. Otherwise kind is unset.
+	kind    constant.Kind
+	val     constant.Value
+	float32 bool // The underlying type is float32.
 }
 
 func (v *Value) String() string {
 	return v.str
 }
 
+// byName reports whether v is a string or floating-point value,
+// to which the generated code refers by name.
+func (v *Value) byName() bool {
+	return v.kind == constant.String || v.kind == constant.Float
+}
+
+// equal reports whether v and w are the same value.
+func (v *Value) equal(w *Value) bool {
+	if v.byName() {
+		return constant.Compare(v.val, token.EQL, w.val)
+	}
+	return v.value == w.value
+}
+
 // byValue lets us sort the constants into increasing order.
 // We take care in the Less method to sort in signed or unsigned order,
 // as appropriate.
@@ -448,6 +637,9 @@ type byValue []Value
 func (b byValue) Len() int      { return len(b) }
 func (b byValue) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
 func (b byValue) Less(i, j int) bool {
+	if b[i].byName() {
+		return constant.Compare(b[i].val, token.LSS, b[j].val)
+	}
 	if b[i].signed {
 		return int64(b[i].value) < int64(b[j].value)
 	}
@@ -516,11 +708,26 @@ func (f *File) genDecl(node ast.Node) bool {
 			if !ok {
 				log.Fatalf("no value for constant %s", name)
 			}
-			info := obj.Type().Underlying().(*types.Basic).Info()
+			basic := obj.Type().Underlying().(*types.Basic)
+			info := basic.Info()
+			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
+			if info&(types.IsString|types.IsFloat) != 0 {
+				v := Value{
+					originalName: name.Name,
+					str:          name.Name,
+					kind:         constant.String,
+					val:          value,
+				}
+				if info&types.IsFloat != 0 {
+					v.kind = constant.Float
+					v.float32 = basic.Kind() == types.Float32
+				}
+				f.values = append(f.values, f.named(v, vspec))
+				continue
+			}
 			if info&types.IsInteger == 0 {
-				log.Fatalf("can't handle non-integer constant type %s", typ)
+				log.Fatalf("can't handle constant type %s: its underlying type must be an integer, floating-point, or string type", typ)
 			}
-			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
 			if value.Kind() != constant.Int {
 				log.Fatalf("can't happen: constant is not an integer %s", name)
 			}
@@ -538,17 +745,25 @@ func (f *File) genDecl(node ast.Node) bool {
 				signed:       info&types.IsUnsigned == 0,
 				str:          value.String(),
 			}
-			if c := vspec.Comment; f.lineComment && c != nil && len(c.List) == 1 {
-				v.name = strings.TrimSpace(c.Text())
-			} else {
-				v.name = strings.TrimPrefix(v.originalName, f.trimPrefix)
-			}
-			f.values = append(f.values, v)
+			f.values = append(f.values, f.named(v, vspec))
 		}
 	}
 	return false
 }
 
+// named sets the printed name of the constant v declared by vspec.
+func (f *File) named(v Value, vspec *ast.ValueSpec) Value {
+	if c := vspec.Comment; f.lineComment && c != nil && len(c.List) == 1 {
+		v.name = strings.TrimSpace(c.Text())
+	} else {
+		v.name = strings.TrimPrefix(v.originalName, f.trimPrefix)
+		if f.transform != "" {
+			v.name = transforms[f.transform](v.name)
+		}
+	}
+	return v
+}
+
 // Helpers
 
 // usize returns the number of bits of the smallest unsigned integer
@@ -723,14 +938,395 @@ func (g *Generator) buildMap(runs [][]Value, typeName string) {
 		}
 	}
 	g.Printf("}\n\n")
-	g.Printf(stringMap, typeName)
+	g.Printf(stringMap, typeName, formatValue(&runs[0][0]))
 }
 
-// Argument to format is the type name.
+// formatValue returns an expression that formats the value i
+// of the same type as v, for use when i has no name.
+func formatValue(v *Value) string {
+	switch {
+	case v.kind == constant.String:
+		return "strconv.Quote(string(i))"
+	case v.kind == constant.Float && v.float32:
+		return "strconv.FormatFloat(float64(i), 'g', -1, 32)"
+	case v.kind == constant.Float:
+		return "strconv.FormatFloat(float64(i), 'g', -1, 64)"
+	}
+	return "strconv.FormatInt(int64(i), 10)"
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: expression formatting i as a number or quoted string
 const stringMap = `func (i %[1]s) String() string {
 	if str, ok := _%[1]s_map[i]; ok {
 		return str
 	}
+	return "%[1]s(" + %[2]s + ")"
+}
+`
+
+// buildByName generates the table that maps names to values, and the
+// functions and methods that use it, as selected by the Generator's
+// text, json, and parse fields.
+func (g *Generator) buildByName(runs [][]Value, typeName string) {
+	// The values of a string type are encoded as themselves, as
+	// usual in JSON APIs, not by name, so they need no table
+	// unless for the parse function.
+	isString := runs[0][0].kind == constant.String
+	if isString && !g.parse {
+		g.buildMarshalValue(typeName)
+		return
+	}
+	g.Printf("\nvar _%s_byName = map[string]%s{\n", typeName, typeName)
+	seen := make(map[string]bool)
+	for _, values := range runs {
+		for _, value := range values {
+			// Several values may share a line comment;
+			// the name denotes the first of them.
+			if !seen[value.name] {
+				seen[value.name] = true
+				g.Printf("\t%q: %s,\n", value.name, &value)
+			}
+		}
+	}
+	g.Printf("}\n")
+	// The verb and operand that format a value with no name,
+	// and the zero value.
+	verb, arg, zero := "d", "i", "0"
+	switch runs[0][0].kind {
+	case constant.String:
+		zero = `""`
+	case constant.Float:
+		verb, arg = "g", "float64(i)"
+	}
+	if isString {
+		g.buildMarshalValue(typeName)
+	} else {
+		if g.text {
+			g.Printf(marshalText, typeName, verb, arg)
+		}
+		if g.json {
+			g.Printf(marshalJSON, typeName, verb, arg)
+		}
+	}
+	if g.parse {
+		g.Printf(parseFunc, typeName, exportedFuncName("Parse", typeName), zero)
+	}
+}
+
+// exportedFuncName returns the name of a function for the named type,
+// formed by adding the verb as a prefix. The function is exported only
+// if the type is.
+func exportedFuncName(verb, typeName string) string {
+	if token.IsExported(typeName) {
+		return verb + typeName
+	}
+	r, size := utf8.DecodeRuneInString(typeName)
+	return strings.ToLower(verb) + string(unicode.ToUpper(r)) + typeName[size:]
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: function name
+//	[3]: zero value of the type
+const parseFunc = `
+// %[2]s returns the %[1]s value with the given name,
+// or an error if there is none.
+func %[2]s(s string) (%[1]s, error) {
+	v, ok := _%[1]s_byName[s]
+	if !ok {
+		return %[3]s, fmt.Errorf("invalid %[1]s %%q", s)
+	}
+	return v, nil
+}
+`
+
+// buildValues generates a function that returns the distinct values
+// of the type, in increasing order.
+func (g *Generator) buildValues(runs [][]Value, typeName string) {
+	g.Printf("\n// %sValues returns the distinct named values of the %s type, in increasing order.\n", typeName, typeName)
+	g.Printf("func %sValues() []%s {\n", typeName, typeName)
+	g.Printf("\treturn []%s{", typeName)
+	n := 0
+	for _, values := range runs {
+		for i := range values {
+			if n > 0 {
+				g.Printf(", ")
+			}
+			g.Printf("%s", &values[i])
+			n++
+		}
+	}
+	g.Printf("}\n")
+	g.Printf("}\n")
+}
+
+// buildIsValid generates a method that reports whether a value is
+// named, or, for flags, whether all its bits are named.
+func (g *Generator) buildIsValid(runs [][]Value, typeName string) {
+	if g.flags {
+		var mask uint64
+		for _, values := range runs {
+			for _, v := range values {
+				if v.value&(v.value-1) == 0 {
+					mask |= v.value
+				}
+			}
+		}
+		g.Printf("\n// IsValid reports whether every bit set in i is that of a named flag.\n")
+		g.Printf("func (i %s) IsValid() bool {\n", typeName)
+		g.Printf("\treturn i&^%#x == 0\n", mask)
+		g.Printf("}\n")
+		return
+	}
+	g.Printf("\n// IsValid reports whether i is one of the named values of the %s type.\n", typeName)
+	g.Printf("func (i %s) IsValid() bool {\n", typeName)
+	g.Printf("\tswitch {\n")
+	g.Printf("\tcase ")
+	for i, values := range runs {
+		if i > 0 {
+			g.Printf(",\n\t\t")
+		}
+		switch {
+		case len(values) == 1:
+			g.Printf("i == %s", &values[0])
+		case values[0].value == 0 && !values[0].signed:
+			g.Printf("i <= %s", &values[len(values)-1])
+		default:
+			g.Printf("%s <= i && i <= %s", &values[0], &values[len(values)-1])
+		}
+	}
+	g.Printf(":\n")
+	g.Printf("\t\treturn true\n")
+	g.Printf("\t}\n")
+	g.Printf("\treturn false\n")
+	g.Printf("}\n")
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: verb that formats a value of the type
+//	[3]: operand for the verb
+const marshalText = `
+// MarshalText implements the encoding.TextMarshaler interface.
+// It returns an error if i is not one of the named values of the type.
+func (i %[1]s) MarshalText() ([]byte, error) {
+	s := i.String()
+	if v, ok := _%[1]s_byName[s]; !ok || v != i {
+		return nil, fmt.Errorf("invalid %[1]s value %%%[2]s", %[3]s)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It returns an error if text is not the name of a value of the type.
+func (i *%[1]s) UnmarshalText(text []byte) error {
+	v, ok := _%[1]s_byName[string(text)]
+	if !ok {
+		return fmt.Errorf("invalid %[1]s %%q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: verb that formats a value of the type
+//	[3]: operand for the verb
+const marshalJSON = `
+// MarshalJSON implements the json.Marshaler interface,
+// encoding i as a JSON string holding its name.
+// It returns an error if i is not one of the named values of the type.
+func (i %[1]s) MarshalJSON() ([]byte, error) {
+	s := i.String()
+	if v, ok := _%[1]s_byName[s]; !ok || v != i {
+		return nil, fmt.Errorf("invalid %[1]s value %%%[2]s", %[3]s)
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It returns an error if data is not a JSON string
+// holding the name of a value of the type.
+func (i *%[1]s) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%[1]s should be a string, got %%s", data)
+	}
+	v, ok := _%[1]s_byName[s]
+	if !ok {
+		return fmt.Errorf("invalid %[1]s %%q", s)
+	}
+	*i = v
+	return nil
+}
+`
+
+// buildMarshalValue generates the methods selected by the Generator's
+// text and json fields for a type whose underlying type is a string
+// type, which encode each value as itself, and use the table that
+// maps values to names to check that it is one of the named values.
+func (g *Generator) buildMarshalValue(typeName string) {
+	if g.text {
+		g.Printf(marshalTextValue, typeName)
+	}
+	if g.json {
+		g.Printf(marshalJSONValue, typeName)
+	}
+}
+
+// Argument to format is the type name.
+const marshalTextValue = `
+// MarshalText implements the encoding.TextMarshaler interface.
+// It returns an error if i is not one of the named values of the type.
+// Note that encoding/json does not call it for map keys, which it
+// writes as they are, since the type is a string type.
+func (i %[1]s) MarshalText() ([]byte, error) {
+	if _, ok := _%[1]s_map[i]; !ok {
+		return nil, fmt.Errorf("invalid %[1]s value %%q", string(i))
+	}
+	return []byte(i), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It returns an error if text is not one of the named values of the type.
+func (i *%[1]s) UnmarshalText(text []byte) error {
+	v := %[1]s(text)
+	if _, ok := _%[1]s_map[v]; !ok {
+		return fmt.Errorf("invalid %[1]s %%q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Argument to format is the type name.
+const marshalJSONValue = `
+// MarshalJSON implements the json.Marshaler interface,
+// encoding i as a JSON string holding its value.
+// It returns an error if i is not one of the named values of the type.
+// Note that encoding/json does not call it for map keys, which it
+// writes as they are, since the type is a string type.
+func (i %[1]s) MarshalJSON() ([]byte, error) {
+	if _, ok := _%[1]s_map[i]; !ok {
+		return nil, fmt.Errorf("invalid %[1]s value %%q", string(i))
+	}
+	return json.Marshal(string(i))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It returns an error if data is not a JSON string
+// holding one of the named values of the type.
+func (i *%[1]s) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%[1]s should be a string, got %%s", data)
+	}
+	v := %[1]s(s)
+	if _, ok := _%[1]s_map[v]; !ok {
+		return fmt.Errorf("invalid %[1]s %%q", s)
+	}
+	*i = v
+	return nil
+}
+`
+
+// buildFlags generates the variables and methods for a type whose
+// constants are bit flags.
+func (g *Generator) buildFlags(runs [][]Value, typeName string) {
+	var bits []Value
+	var zero *Value
+	for _, run := range runs {
+		for i := range run {
+			v := &run[i]
+			switch {
+			case v.value == 0:
+				zero = v
+			case v.value&(v.value-1) == 0:
+				bits = append(bits, *v)
+			}
+			// Values with several bits set are masks, not flags.
+		}
+	}
+	g.Printf("\n")
+	zeroName := fmt.Sprintf("%s(0)", typeName)
+	if zero != nil {
+		zeroName = zero.name
+	}
+	if len(bits) == 0 {
+		g.Printf(stringFlagsZero, typeName, zeroName)
+	} else {
+		g.declareIndexAndNameVar(bits, typeName)
+		g.Printf("var _%s_flags = [...]%s{", typeName, typeName)
+		for i := range bits {
+			if i > 0 {
+				g.Printf(", ")
+			}
+			g.Printf("%s", &bits[i])
+		}
+		g.Printf("}\n\n")
+		g.Printf(stringFlags, typeName, zeroName)
+	}
+	g.Printf(flagsMethods, typeName)
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: string for zero
+const stringFlags = `func (i %[1]s) String() string {
+	if i == 0 {
+		return %[2]q
+	}
+	var b []byte
+	for j, f := range _%[1]s_flags {
+		if i&f != 0 {
+			if len(b) > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _%[1]s_name[_%[1]s_index[j]:_%[1]s_index[j+1]]...)
+			i &^= f
+		}
+	}
+	if i != 0 {
+		if len(b) > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "%[1]s("...)
+		b = strconv.AppendInt(b, int64(i), 10)
+		b = append(b, ')')
+	}
+	return string(b)
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: string for zero
+const stringFlagsZero = `func (i %[1]s) String() string {
+	if i == 0 {
+		return %[2]q
+	}
 	return "%[1]s(" + strconv.FormatInt(int64(i), 10) + ")"
 }
 `
+
+// Argument to format is the type name.
+const flagsMethods = `
+// Has reports whether all the flags set in f are set in i.
+func (i %[1]s) Has(f %[1]s) bool {
+	return i&f == f
+}
+
+// Set sets the flags that are set in f in *i.
+func (i *%[1]s) Set(f %[1]s) {
+	*i |= f
+}
+`
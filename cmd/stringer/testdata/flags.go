@@ -0,0 +1,63 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bit flags: run with -flags -values -isvalid.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Flags uint8
+
+const (
+	None Flags = 0
+	Read Flags = 1 << iota
+	Write
+	Exec
+	_
+	Sticky
+	ReadWrite       = Read | Write // no explicit type, so ignored by stringer
+	All       Flags = Read | Write | Exec | Sticky
+)
+
+func main() {
+	ck(None, "None")
+	ck(Read, "Read")
+	ck(Write, "Write")
+	ck(Exec, "Exec")
+	ck(Sticky, "Sticky")
+	ck(Read|Write, "Read|Write")
+	ck(ReadWrite, "Read|Write")
+	ck(Read|Exec|Sticky, "Read|Exec|Sticky")
+	ck(16, "Flags(16)")
+	ck(Write|16|128, "Write|Flags(144)")
+	ck(All, "Read|Write|Exec|Sticky")
+
+	var f Flags
+	f.Set(Read)
+	f.Set(Exec)
+	if f != Read|Exec {
+		panic("flags.go: Set")
+	}
+	if !f.Has(Read) || !f.Has(Read|Exec) || f.Has(Write) || f.Has(ReadWrite) || !f.Has(None) {
+		panic("flags.go: Has")
+	}
+
+	want := []Flags{None, Read, Write, Exec, Sticky, All}
+	if got := FlagsValues(); !slices.Equal(got, want) {
+		panic(fmt.Sprintf("flags.go: FlagsValues() = %v, want %v", got, want))
+	}
+	if !None.IsValid() || !(Read | Exec | Sticky).IsValid() || (Read | 16).IsValid() || Flags(128).IsValid() {
+		panic("flags.go: IsValid")
+	}
+}
+
+func ck(flags Flags, str string) {
+	if fmt.Sprint(flags) != str {
+		panic("flags.go: " + str)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Float-typed constants: run with -parse -values -isvalid.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Flt float32
+
+const (
+	Half    Flt = 0.5
+	Tenth   Flt = 0.1
+	One     Flt = 1
+	NegTwo  Flt = -2
+	Unit        = One
+	Quarter Flt = Half / 2
+)
+
+func main() {
+	ck(Half, "Half")
+	ck(Tenth, "Tenth")
+	ck(One, "One")
+	ck(NegTwo, "NegTwo")
+	ck(Unit, "One")
+	ck(Quarter, "Quarter")
+	ck(0.2, "Flt(0.2)")
+
+	if v, err := ParseFlt("Tenth"); err != nil || v != Tenth {
+		panic(fmt.Sprintf("flt.go: ParseFlt = %v, %v", v, err))
+	}
+	if _, err := ParseFlt("0.1"); err == nil {
+		panic("flt.go: ParseFlt succeeded for value, not name")
+	}
+
+	want := []Flt{NegTwo, Tenth, Quarter, Half, One}
+	if got := FltValues(); !slices.Equal(got, want) {
+		panic(fmt.Sprintf("flt.go: FltValues() = %v, want %v", got, want))
+	}
+	for _, v := range want {
+		if !v.IsValid() {
+			panic("flt.go: invalid: " + v.String())
+		}
+	}
+	if Flt(0.2).IsValid() {
+		panic("flt.go: 0.2 is valid")
+	}
+}
+
+func ck(flt Flt, want string) {
+	if fmt.Sprint(flt) != want {
+		panic("flt.go: " + want)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Marshaling test: run with -text and -json.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Marshal int
+
+const (
+	Zero Marshal = iota
+	One
+	Two
+	Deux         = Two
+	Ten  Marshal = 10
+)
+
+func main() {
+	ck(Zero, "Zero")
+	ck(One, "One")
+	ck(Two, "Two")
+	ck(Deux, "Two")
+	ck(Ten, "Ten")
+	ckBad(3)
+	ckBad(-1)
+	ckBadText("Deux")
+	ckBadText("three")
+	ckBadText("")
+
+	m := map[Marshal]Marshal{One: Ten}
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"One":"Ten"}` {
+		panic(fmt.Sprintf("marshal.go: map: %s, %v", data, err))
+	}
+	if err := json.Unmarshal([]byte(`1`), new(Marshal)); err == nil {
+		panic("marshal.go: unmarshaled JSON number")
+	}
+}
+
+func ck(m Marshal, str string) {
+	text, err := m.MarshalText()
+	if err != nil || string(text) != str {
+		panic("marshal.go: MarshalText: " + str)
+	}
+	var t Marshal
+	if err := t.UnmarshalText(text); err != nil || t != m {
+		panic("marshal.go: UnmarshalText: " + str)
+	}
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `"`+str+`"` {
+		panic("marshal.go: MarshalJSON: " + str)
+	}
+	var j Marshal
+	if err := json.Unmarshal(data, &j); err != nil || j != m {
+		panic("marshal.go: UnmarshalJSON: " + str)
+	}
+}
+
+func ckBad(m Marshal) {
+	if _, err := m.MarshalText(); err == nil {
+		panic(fmt.Sprintf("marshal.go: MarshalText(%d) succeeded", int(m)))
+	}
+	if _, err := json.Marshal(m); err == nil {
+		panic(fmt.Sprintf("marshal.go: MarshalJSON(%d) succeeded", int(m)))
+	}
+}
+
+func ckBadText(str string) {
+	var m Marshal
+	if err := m.UnmarshalText([]byte(str)); err == nil {
+		panic("marshal.go: UnmarshalText succeeded: " + str)
+	}
+	if err := json.Unmarshal([]byte(`"`+str+`"`), &m); err == nil {
+		panic("marshal.go: UnmarshalJSON succeeded: " + str)
+	}
+}
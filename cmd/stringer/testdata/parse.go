@@ -0,0 +1,58 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Parse, Values, and IsValid: run with -parse -values -isvalid.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Parse int
+
+const (
+	Neg Parse = -2
+	A   Parse = iota
+	B
+	C
+	Alias       = B
+	Ten   Parse = 10
+)
+
+func main() {
+	ck("Neg", Neg)
+	ck("A", A)
+	ck("B", B)
+	ck("C", C)
+	ck("Ten", Ten)
+	for _, s := range []string{"", "Alias", "D", "Parse(3)", "a"} {
+		if _, err := ParseParse(s); err == nil {
+			panic("parse.go: ParseParse succeeded: " + s)
+		}
+	}
+
+	want := []Parse{Neg, A, B, C, Ten}
+	if got := ParseValues(); !slices.Equal(got, want) {
+		panic(fmt.Sprintf("parse.go: ParseValues() = %v, want %v", got, want))
+	}
+	for _, v := range want {
+		if !v.IsValid() {
+			panic("parse.go: invalid: " + v.String())
+		}
+	}
+	for _, v := range []Parse{-3, -1, 0, 5, 9, 11} {
+		if v.IsValid() {
+			panic("parse.go: valid: " + v.String())
+		}
+	}
+}
+
+func ck(s string, want Parse) {
+	got, err := ParseParse(s)
+	if err != nil || got != want {
+		panic("parse.go: " + s)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// String-typed constants: run with -text -json -parse -values -isvalid.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+type Str string
+
+const (
+	Red    Str = "red"
+	Green  Str = "green"
+	Blue   Str = "blue"
+	Azure      = Blue
+	Empty  Str = ""
+	Quoted Str = "a\"b"
+)
+
+func main() {
+	ck(Red, "Red")
+	ck(Green, "Green")
+	ck(Blue, "Blue")
+	ck(Azure, "Blue")
+	ck(Empty, "Empty")
+	ck(Quoted, "Quoted")
+	ck("purple", `Str("purple")`)
+
+	for _, s := range []string{"Red", "Blue", "Quoted"} {
+		v, err := ParseStr(s)
+		if err != nil || v.String() != s {
+			panic(fmt.Sprintf("str.go: ParseStr(%q) = %q, %v", s, v, err))
+		}
+	}
+	if _, err := ParseStr("red"); err == nil {
+		panic("str.go: ParseStr succeeded for value, not name")
+	}
+
+	want := []Str{Empty, Quoted, Blue, Green, Red}
+	if got := StrValues(); !slices.Equal(got, want) {
+		panic(fmt.Sprintf("str.go: StrValues() = %q, want %q", got, want))
+	}
+	for _, v := range want {
+		if !v.IsValid() {
+			panic("str.go: invalid: " + v.String())
+		}
+	}
+	if Str("purple").IsValid() {
+		panic("str.go: purple is valid")
+	}
+
+	// The values, not their names, are marshaled,
+	// in map keys as in values.
+	data, err := json.Marshal(map[Str]Str{Red: Green})
+	if err != nil || string(data) != `{"red":"green"}` {
+		panic(fmt.Sprintf("str.go: json.Marshal = %s, %v", data, err))
+	}
+	var m map[Str]Str
+	if err := json.Unmarshal([]byte(`{"a\"b":"blue"}`), &m); err != nil || m[Quoted] != Blue {
+		panic(fmt.Sprintf("str.go: json.Unmarshal = %v, %v", m, err))
+	}
+	if err := json.Unmarshal([]byte(`{"x":"Blue"}`), &m); err == nil {
+		panic("str.go: json.Unmarshal succeeded for name, not value")
+	}
+	if _, err := json.Marshal(Str("purple")); err == nil {
+		panic("str.go: json.Marshal succeeded for unnamed value")
+	}
+}
+
+func ck(str Str, want string) {
+	if fmt.Sprint(str) != want {
+		panic("str.go: " + want)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A typeSpec is a type named by the -type flag,
+// with the options that apply to it.
+type typeSpec struct {
+	name       string
+	trimPrefix string // prefix trimmed from the constant names
+	transform  string // key of transforms applied to the trimmed names, or ""
+
+	hasTrimPrefix bool // trimPrefix was set by a trimprefix option, overriding -trimprefix
+}
+
+// typeFlag is the value of the -type flag, which may be repeated.
+// Each use of the flag holds a comma-separated list of type names,
+// each of which may be followed by a colon and options of the form
+// key=value, themselves separated by commas:
+//
+//	-type=Pill,Color:trimprefix=Color,transform=snake
+type typeFlag []typeSpec
+
+func (f *typeFlag) String() string {
+	var names []string
+	for _, t := range *f {
+		names = append(names, t.name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *typeFlag) Set(value string) error {
+	var t *typeSpec // the type to which options apply
+	for _, elem := range strings.Split(value, ",") {
+		option := elem
+		if key, _, ok := strings.Cut(elem, "="); !ok || strings.Contains(key, ":") {
+			// A type name, possibly followed by its first option.
+			name, rest, hasOption := strings.Cut(elem, ":")
+			if name == "" {
+				return fmt.Errorf("empty type name in %q", value)
+			}
+			*f = append(*f, typeSpec{name: name})
+			if !hasOption {
+				t = nil
+				continue
+			}
+			t = &(*f)[len(*f)-1]
+			option = rest
+		} else if t == nil {
+			return fmt.Errorf("option %q must follow a type name and colon", elem)
+		}
+		key, val, ok := strings.Cut(option, "=")
+		if !ok {
+			return fmt.Errorf("invalid option %q for type %s; want key=value", option, t.name)
+		}
+		switch key {
+		case "trimprefix":
+			t.trimPrefix, t.hasTrimPrefix = val, true
+		case "transform":
+			if transforms[val] == nil {
+				return fmt.Errorf("unknown transform %q for type %s", val, t.name)
+			}
+			t.transform = val
+		default:
+			return fmt.Errorf("unknown option %q for type %s", key, t.name)
+		}
+	}
+	return nil
+}
+
+// transforms maps the values of the transform option
+// to the functions they apply to the names of the constants.
+var transforms = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"snake": func(name string) string { return strings.ToLower(strings.Join(splitWords(name), "_")) },
+	"kebab": func(name string) string { return strings.ToLower(strings.Join(splitWords(name), "-")) },
+}
+
+// splitWords splits a name into the words of which it is composed,
+// where words are separated by underscores or changes of case.
+// An initialism forms a single word, so that "HTTPServer" is split
+// into "HTTP" and "Server".
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.Split(name, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, r := runes[i-1], runes[i]
+			if unicode.IsUpper(r) && (!unicode.IsUpper(prev) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
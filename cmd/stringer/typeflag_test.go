@@ -0,0 +1,75 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeFlag(t *testing.T) {
+	for _, test := range []struct {
+		values []string
+		want   typeFlag
+	}{
+		{[]string{"A"}, typeFlag{{name: "A"}}},
+		{[]string{"A,B"}, typeFlag{{name: "A"}, {name: "B"}}},
+		{[]string{"A", "B"}, typeFlag{{name: "A"}, {name: "B"}}},
+		{
+			[]string{"A:trimprefix=A,transform=snake,B"},
+			typeFlag{{name: "A", trimPrefix: "A", hasTrimPrefix: true, transform: "snake"}, {name: "B"}},
+		},
+		{
+			[]string{"A:trimprefix=", "B:transform=upper"},
+			typeFlag{{name: "A", hasTrimPrefix: true}, {name: "B", transform: "upper"}},
+		},
+	} {
+		var got typeFlag
+		for _, v := range test.values {
+			if err := got.Set(v); err != nil {
+				t.Fatalf("Set(%q) failed: %v", v, err)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("after Set(%q), got %+v, want %+v", test.values, got, test.want)
+		}
+	}
+
+	for _, value := range []string{
+		"",
+		"A,,B",
+		"trimprefix=A",
+		"A,trimprefix=A",
+		"A:trimprefix",
+		"A:prefix=A",
+		"A:transform=camel",
+	} {
+		var f typeFlag
+		if err := f.Set(value); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", value)
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	for _, test := range []struct {
+		name, transform, want string
+	}{
+		{"BigRed", "lower", "bigred"},
+		{"BigRed", "upper", "BIGRED"},
+		{"BigRed", "snake", "big_red"},
+		{"BigRed", "kebab", "big-red"},
+		{"bigRed", "snake", "big_red"},
+		{"HTTPServer", "snake", "http_server"},
+		{"ServeHTTP", "kebab", "serve-http"},
+		{"Big_Red", "snake", "big_red"},
+		{"Red2", "snake", "red2"},
+		{"X", "snake", "x"},
+	} {
+		if got := transforms[test.transform](test.name); got != test.want {
+			t.Errorf("%s(%q) = %q, want %q", test.transform, test.name, got, test.want)
+		}
+	}
+}
@@ -10,7 +10,8 @@
 //	toolstash [-n] [-v] save [tool...]
 //	toolstash [-n] [-v] restore [tool...]
 //	toolstash [-n] [-v] [-t] go run x.go
-//	toolstash [-n] [-v] [-t] [-cmp] compile x.go
+//	toolstash [-n] [-v] [-t] [-cmp] [-json report] [-shard i/n] compile x.go
+//	toolstash [-n] [-v] [-j n] [-json report] [-shard i/n] cmpall [build flags] [packages]
 //
 // The toolstash command manages a “stashed” copy of the Go toolchain
 // kept in $GOROOT/pkg/toolstash. In this case, the toolchain means the
@@ -56,6 +57,33 @@
 // The -cmp flag is a no-op when the command line is not invoking an
 // assembler or compiler.
 //
+// The -json flag causes toolstash -cmp to append a description of each
+// mismatching object to the named report file, as a JSON object per line,
+// instead of failing. Each object records the tool, the package, the
+// command line, the output file, the kind of mismatch, and the first
+// divergence in the assembly output:
+//
+//	{"Tool": "compile", "Package": "fmt", "Command": [...], "Output": "...",
+//	 "Reason": "compiler output differs", "Diff": "inconsistent log line: ..."}
+//
+// The installed tool's output is then left in place, so that a build
+// comparing many packages continues past a mismatch.
+//
+// The -shard i/n flag restricts -cmp to the i'th of n disjoint subsets of
+// the packages, chosen by a hash of the package path, so that the work of
+// comparing a large tree can be divided among several machines. The
+// commands for the packages of other shards run the installed tool only.
+//
+// The command “toolstash cmpall” builds the named packages (by default,
+// std) from scratch with go build -toolexec 'toolstash -cmp', comparing
+// the objects of each. It builds up to -j packages concurrently (by
+// default, the number of CPUs), records every mismatch in the -json report,
+// if any, and reports the mismatches and exits with a failure status at the
+// end of the build. For example, to compare the first of four shards of
+// the standard library:
+//
+//	toolstash -shard 0/4 -json report.json cmpall std
+//
 // For example, when working on code cleanup that should not affect
 // compiler output, toolstash can be used to compare the old and new
 // compiler output:
@@ -136,7 +164,7 @@ import (
 	"time"
 )
 
-var usageMessage = `usage: toolstash [-n] [-v] [-cmp] command line
+var usageMessage = `usage: toolstash [-n] [-v] [-cmp] [-json report] [-shard i/n] command line
 
 Examples:
 	toolstash save
@@ -144,6 +172,7 @@ Examples:
 	toolstash go run x.go
 	toolstash compile x.go
 	toolstash -cmp compile x.go
+	toolstash -j 8 -json report.json cmpall std
 
 For details, godoc golang.org/x/tools/cmd/toolstash
 `
@@ -159,6 +188,9 @@ var (
 	verbose = flag.Bool("v", false, "print commands being run")
 	cmp     = flag.Bool("cmp", false, "compare tool object files")
 	timing  = flag.Bool("t", false, "print time commands take")
+	jobs    = flag.Int("j", runtime.GOMAXPROCS(0), "number of packages to build concurrently (cmpall)")
+	report  = flag.String("json", "", "append JSON descriptions of mismatches to `file`")
+	shard   = flag.String("shard", "", "compare only the `i/n`'th shard of the packages")
 )
 
 var (
@@ -223,6 +255,10 @@ func main() {
 	case "restore":
 		restore()
 		return
+
+	case "cmpall":
+		cmpAll(cmd[1:])
+		return
 	}
 
 	tool = cmd[0]
@@ -237,11 +273,19 @@ func main() {
 			os.Exit(2)
 		}
 
-		if *cmp && canCmp(tool, cmd[1:]) {
+		switch {
+		case *cmp && canCmp(tool, cmd[1:]) && inShard(cmd[1:]):
 			compareTool()
 			return
+		case *cmp && canCmp(tool, cmd[1:]):
+			// Another shard compares this package.
+			// Run the installed tool.
+			if !strings.Contains(cmd[0], "/") && !strings.Contains(cmd[0], `\`) {
+				cmd[0] = filepath.Join(toolDir, tool)
+			}
+		default:
+			cmd[0] = toolStash
 		}
-		cmd[0] = toolStash
 	}
 
 	if *norun {
@@ -267,12 +311,14 @@ func compareTool() {
 		cmd[0] = filepath.Join(toolDir, tool)
 	}
 
+	origCmd := append([]string(nil), cmd...)
 	outfile, ok := cmpRun(false, cmd)
 	if ok {
 		os.Remove(outfile + ".stash")
 		return
 	}
 
+	var reason string
 	extra := "-S=2"
 	switch {
 	default:
@@ -294,9 +340,9 @@ func compareTool() {
 		_, ok := cmpRun(false, cmdN)
 		if !ok {
 			if useDashN {
-				log.Printf("compiler output differs, with optimizers disabled (-N)")
+				reason = "compiler output differs, with optimizers disabled (-N)"
 			} else {
-				log.Printf("compiler output differs")
+				reason = "compiler output differs"
 			}
 			if dashcIndex >= 0 {
 				cmd[dashcIndex] = "-c=1"
@@ -308,21 +354,43 @@ func compareTool() {
 			cmd[dashcIndex] = "-c=1"
 		}
 		cmd = injectflags(cmd, []string{"-v", "-m=2"}, false)
-		log.Printf("compiler output differs, only with optimizers enabled")
+		reason = "compiler output differs, only with optimizers enabled"
 
 	case tool == "asm" || strings.HasSuffix(tool, "a"): // assembler
-		log.Printf("assembler output differs")
+		reason = "assembler output differs"
 
 	case tool == "link" || strings.HasSuffix(tool, "l"): // linker
-		log.Printf("linker output differs")
+		reason = "linker output differs"
 		extra = "-v=2"
 	}
 
 	cmdS := injectflags(cmd, []string{extra}, false)
 	outfile, _ = cmpRun(true, cmdS)
-
-	fmt.Fprintf(os.Stderr, "\n%s\n", compareLogs(outfile))
-	os.Exit(2)
+	diff := compareLogs(outfile)
+
+	if *report == "" {
+		log.Print(reason)
+		fmt.Fprintf(os.Stderr, "\n%s\n", diff)
+		os.Exit(2)
+	}
+
+	// Record the mismatch, and restore the output of the original
+	// command, so that the build can continue.
+	m := &mismatch{
+		Tool:    tool,
+		Package: flagValue(origCmd[1:], "-p"),
+		Command: origCmd,
+		Output:  outfile,
+		Reason:  reason,
+		Diff:    diff,
+	}
+	writeReport(m)
+	log.Printf("%s: %s (recorded in %s)", m.name(), reason, *report)
+	if out, err := runCmd(origCmd, false, ""); err != nil {
+		log.Printf("running: %s", strings.Join(origCmd, " "))
+		os.Stderr.Write(out)
+		log.Fatal(err)
+	}
 }
 
 func injectflags(cmd []string, extra []string, addDashN bool) []string {
@@ -362,15 +430,25 @@ func cmpRun(keepLog bool, cmd []string) (outfile string, match bool) {
 		os.Exit(0)
 	}
 
+	// Run the installed and stashed tools concurrently.
+	var (
+		outStash []byte
+		errStash error
+		done     = make(chan bool)
+	)
+	go func() {
+		outStash, errStash = runCmd(cmdStash, keepLog, outfile+".stash.log")
+		close(done)
+	}()
 	out, err := runCmd(cmd, keepLog, outfile+".log")
+	<-done
 	if err != nil {
 		log.Printf("running: %s", strings.Join(cmd, " "))
 		os.Stderr.Write(out)
 		log.Fatal(err)
 	}
 
-	outStash, err := runCmd(cmdStash, keepLog, outfile+".stash.log")
-	if err != nil {
+	if err := errStash; err != nil {
 		log.Printf("running: %s", strings.Join(cmdStash, " "))
 		log.Printf("installed tool succeeded but stashed tool failed.\n")
 		if len(out) > 0 {
@@ -419,6 +497,9 @@ func sameObject(file1, file2 string) bool {
 		if err1 == io.EOF && err2 == io.EOF {
 			return true
 		}
+		if err1 == io.EOF || err2 == io.EOF {
+			return false // different lengths
+		}
 		if err1 != nil {
 			log.Fatalf("reading %s: %v", file1, err1)
 		}
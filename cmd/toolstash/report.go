@@ -0,0 +1,212 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A mismatch describes an object that differs between the installed
+// and stashed tools, as recorded in the -json report.
+type mismatch struct {
+	Tool    string   // name of the tool, such as "compile"
+	Package string   `json:",omitempty"` // import path of the package, from the -p flag
+	Command []string // command line of the installed tool
+	Output  string   // object file
+	Reason  string   // kind of mismatch, such as "compiler output differs"
+	Diff    string   // first divergence in the assembly output
+}
+
+// name returns the package of the mismatching object, or else its file.
+func (m *mismatch) name() string {
+	if m.Package != "" {
+		return m.Package
+	}
+	return m.Output
+}
+
+// writeReport appends m to the -json report. Each mismatch is written
+// in a single write to a file opened for appending, so that concurrent
+// toolstash processes do not interleave their records.
+func writeReport(m *mismatch) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := os.OpenFile(*report, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readReport returns the mismatches recorded in the named report.
+func readReport(name string) ([]*mismatch, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ms []*mismatch
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		m := new(mismatch)
+		if err := dec.Decode(m); err != nil {
+			return nil, fmt.Errorf("reading %s: %v", name, err)
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// flagValue returns the value of the named flag in args, or "".
+func flagValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if v, ok := strings.CutPrefix(arg, name+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseShard parses the -shard flag, of the form i/n.
+func parseShard(s string) (i, n int, err error) {
+	is, ns, ok := strings.Cut(s, "/")
+	if ok {
+		i, err = strconv.Atoi(is)
+		if err == nil {
+			n, err = strconv.Atoi(ns)
+		}
+	}
+	if !ok || err != nil || n <= 0 || i < 0 || i >= n {
+		return 0, 0, fmt.Errorf("invalid -shard %q: want i/n, with 0 <= i < n", s)
+	}
+	return i, n, nil
+}
+
+// inShard reports whether the tool command with the given arguments
+// belongs to the shard selected by the -shard flag. Commands are
+// assigned to shards by the package path given by their -p flag,
+// or else by their output file.
+func inShard(args []string) bool {
+	if *shard == "" {
+		return true
+	}
+	i, n, err := parseShard(*shard)
+	if err != nil {
+		log.Fatal(err)
+	}
+	key := flagValue(args, "-p")
+	if key == "" {
+		key = flagValue(args, "-o")
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32()%uint32(n)) == i
+}
+
+// cmpAll implements the cmpall command, which builds the packages named
+// by args with go build -toolexec 'toolstash -cmp', and reports the
+// mismatches.
+func cmpAll(args []string) {
+	if *shard != "" {
+		if _, _, err := parseShard(*shard); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	reportFile := *report
+	if reportFile == "" {
+		f, err := os.CreateTemp("", "toolstash-report-*.json")
+		if err != nil {
+			log.Fatal(err)
+		}
+		f.Close()
+		reportFile = f.Name()
+		defer os.Remove(reportFile)
+	} else {
+		// The tools run in the directories of the packages.
+		abs, err := filepath.Abs(reportFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reportFile = abs
+		if err := os.WriteFile(reportFile, nil, 0666); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		log.Fatal(err)
+	}
+	toolexec := []string{quoteArg(self), "-go", quoteArg(*goCmd), "-cmp", "-json", quoteArg(reportFile)}
+	if *shard != "" {
+		toolexec = append(toolexec, "-shard", *shard)
+	}
+	if *verbose {
+		toolexec = append(toolexec, "-v")
+	}
+	if len(args) == 0 {
+		args = []string{"std"}
+	}
+	goArgs := append([]string{*goCmd, "build", "-a", "-p", strconv.Itoa(max(*jobs, 1)), "-toolexec", strings.Join(toolexec, " ")}, args...)
+
+	if *norun {
+		fmt.Printf("%s\n", strings.Join(goArgs, " "))
+		return
+	}
+	if *verbose {
+		log.Print(strings.Join(goArgs, " "))
+	}
+	xcmd := exec.Command(goArgs[0], goArgs[1:]...)
+	xcmd.Stdout = os.Stdout
+	xcmd.Stderr = os.Stderr
+	buildErr := xcmd.Run()
+
+	ms, err := readReport(reportFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, m := range ms {
+		log.Printf("%s: %s", m.name(), m.Reason)
+	}
+	if buildErr != nil {
+		log.Fatalf("%s build: %v", *goCmd, buildErr)
+	}
+	if len(ms) > 0 {
+		log.Printf("%d mismatching objects", len(ms))
+		os.Exit(2)
+	}
+}
+
+// quoteArg quotes arg, if necessary, for the -toolexec flag of the go
+// command, which splits its value at spaces outside quotation marks.
+func quoteArg(arg string) string {
+	switch {
+	case !strings.ContainsAny(arg, " \t\n'\""):
+		return arg
+	case !strings.Contains(arg, "'"):
+		return "'" + arg + "'"
+	}
+	return `"` + arg + `"`
+}
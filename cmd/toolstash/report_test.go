@@ -0,0 +1,161 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		i, n int
+		ok   bool
+	}{
+		{"0/1", 0, 1, true},
+		{"3/4", 3, 4, true},
+		{"4/4", 0, 0, false},
+		{"-1/4", 0, 0, false},
+		{"0/0", 0, 0, false},
+		{"1", 0, 0, false},
+		{"a/b", 0, 0, false},
+	} {
+		i, n, err := parseShard(test.s)
+		if i != test.i || n != test.n || (err == nil) != test.ok {
+			t.Errorf("parseShard(%q) = %d, %d, %v, want %d, %d, ok=%t", test.s, i, n, err, test.i, test.n, test.ok)
+		}
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	args := []string{"-o", "x.o", "-p=fmt", "-trimpath", "x.go"}
+	for _, test := range []struct{ name, want string }{
+		{"-o", "x.o"},
+		{"-p", "fmt"},
+		{"-trimpath", "x.go"},
+		{"-D", ""},
+	} {
+		if got := flagValue(args, test.name); got != test.want {
+			t.Errorf("flagValue(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// TestInShard checks that the shards partition the packages,
+// and that commands without -p are assigned by their output.
+func TestInShard(t *testing.T) {
+	defer func(old string) { *shard = old }(*shard)
+
+	const n = 3
+	pkgs := []string{"fmt", "os", "runtime", "net/http", "go/types", "strings", "sort"}
+	count := make(map[string]int)
+	for i := range n {
+		*shard = fmt.Sprintf("%d/%d", i, n)
+		for _, pkg := range pkgs {
+			if inShard([]string{"-p", pkg, "-o", "x.o"}) {
+				count[pkg]++
+			}
+		}
+		// Without -p, the output file is the key.
+		if inShard([]string{"-o", "fmt"}) != inShard([]string{"-p", "fmt", "-o", "x.o"}) {
+			t.Errorf("shard %s: command without -p is not assigned by its output", *shard)
+		}
+	}
+	for _, pkg := range pkgs {
+		if count[pkg] != 1 {
+			t.Errorf("package %s is in %d shards, want 1", pkg, count[pkg])
+		}
+	}
+
+	*shard = ""
+	if !inShard([]string{"-p", "fmt"}) {
+		t.Errorf("without -shard, package is not compared")
+	}
+}
+
+// TestReport checks that concurrent writers of a report
+// do not interleave their records.
+func TestReport(t *testing.T) {
+	defer func(old string) { *report = old }(*report)
+	*report = filepath.Join(t.TempDir(), "report.json")
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			writeReport(&mismatch{
+				Tool:    "compile",
+				Package: fmt.Sprintf("p%02d", i),
+				Command: []string{"compile", "-p", fmt.Sprintf("p%02d", i)},
+				Output:  "x.o",
+				Reason:  "compiler output differs",
+				Diff:    fmt.Sprintf("inconsistent log line:\np%02d", i),
+			})
+		}()
+	}
+	wg.Wait()
+
+	ms, err := readReport(*report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range ms {
+		if m.Diff != "inconsistent log line:\n"+m.Package {
+			t.Errorf("record of %s has Diff %q", m.Package, m.Diff)
+		}
+		got = append(got, m.name())
+	}
+	sort.Strings(got)
+	var want []string
+	for i := range n {
+		want = append(want, fmt.Sprintf("p%02d", i))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report records packages %q, want %q", got, want)
+	}
+
+	if name := (&mismatch{Output: "x.o"}).name(); name != "x.o" {
+		t.Errorf("name of mismatch without package = %q, want output file", name)
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, test := range []struct{ arg, want string }{
+		{"/usr/bin/toolstash", "/usr/bin/toolstash"},
+		{"/My Tools/toolstash", "'/My Tools/toolstash'"},
+		{"/it's/toolstash", `"/it's/toolstash"`},
+	} {
+		if got := quoteArg(test.arg); got != test.want {
+			t.Errorf("quoteArg(%q) = %s, want %s", test.arg, got, test.want)
+		}
+	}
+}
+
+func TestSameObject(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	a := write("a", "object\n")
+	if !sameObject(a, write("b", "object\n")) {
+		t.Errorf("identical objects differ")
+	}
+	if sameObject(a, write("c", "object\nmore\n")) {
+		t.Errorf("objects of different lengths are the same")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intsets
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The binary encoding of a Sparse is a version byte followed by the
+// encoding of each block, in order. A block is encoded as its index
+// (offset / bitsPerBlock), a signed varint for the first block and an
+// unsigned varint delta from the previous index for the others;
+// then a byte whose ith bit is set if the ith 64-bit chunk of the
+// block's bits is nonzero; then each nonzero chunk in little-endian
+// order. The encoding is independent of the word size, so sets may
+// be exchanged between 32- and 64-bit platforms, within the limits
+// of the int type.
+
+const (
+	encodingVersion = 1
+	chunksPerBlock  = bitsPerBlock / 64
+	wordsPerChunk   = 64 / bitsPerWord
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Sparse)(nil)
+	_ encoding.BinaryUnmarshaler = (*Sparse)(nil)
+)
+
+// AppendBinary appends the binary encoding of the set s to data
+// and returns the extended slice.
+func (s *Sparse) AppendBinary(data []byte) ([]byte, error) {
+	data = append(data, encodingVersion)
+	prev := 0
+	for b := s.first(); b != &none; b = s.next(b) {
+		index := b.offset / bitsPerBlock
+		if b == &s.root {
+			data = binary.AppendVarint(data, int64(index))
+		} else {
+			data = binary.AppendUvarint(data, uint64(index-prev))
+		}
+		prev = index
+
+		var chunks [chunksPerBlock]uint64
+		var mask byte
+		for i := range chunks {
+			for j := 0; j < wordsPerChunk; j++ {
+				chunks[i] |= uint64(b.bits[i*wordsPerChunk+j]) << (j * bitsPerWord)
+			}
+			if chunks[i] != 0 {
+				mask |= 1 << i
+			}
+		}
+		data = append(data, mask)
+		for _, c := range chunks {
+			if c != 0 {
+				data = binary.LittleEndian.AppendUint64(data, c)
+			}
+		}
+	}
+	return data, nil
+}
+
+// MarshalBinary returns the binary encoding of the set s,
+// whose size is proportional to the number of its nonempty
+// 256-element blocks rather than to the number of its elements.
+func (s *Sparse) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+// UnmarshalBinary sets s to the set whose binary encoding,
+// as returned by MarshalBinary, is data.
+// If data is not a valid encoding, s is left empty.
+func (s *Sparse) UnmarshalBinary(data []byte) error {
+	s.Clear()
+	if err := s.decode(data); err != nil {
+		s.Clear()
+		return fmt.Errorf("intsets: invalid encoding: %v", err)
+	}
+	return nil
+}
+
+// decode appends the blocks encoded by data to the empty set s.
+func (s *Sparse) decode(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("no data")
+	}
+	if data[0] != encodingVersion {
+		return fmt.Errorf("unknown version %d", data[0])
+	}
+	data = data[1:]
+
+	const (
+		minIndex = int64(MinInt / bitsPerBlock)
+		maxIndex = int64(MaxInt / bitsPerBlock)
+	)
+	var index int64
+	for first := true; len(data) > 0; first = false {
+		var n int
+		if first {
+			index, n = binary.Varint(data)
+		} else {
+			var delta uint64
+			delta, n = binary.Uvarint(data)
+			if n > 0 && (delta == 0 || delta > uint64(maxIndex-index)) {
+				return errors.New("bad block index")
+			}
+			index += int64(delta)
+		}
+		if n <= 0 {
+			return errors.New("bad block index")
+		}
+		if index < minIndex || index > maxIndex {
+			return errors.New("block index out of range")
+		}
+		data = data[n:]
+
+		if len(data) == 0 {
+			return errors.New("missing block mask")
+		}
+		mask := data[0]
+		if mask == 0 || mask>>chunksPerBlock != 0 {
+			return fmt.Errorf("bad block mask %#x", mask)
+		}
+		data = data[1:]
+
+		b := s.insertBlockBefore(&none)
+		b.offset = int(index) * bitsPerBlock
+		for i := 0; i < chunksPerBlock; i++ {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			if len(data) < 8 {
+				return errors.New("truncated block")
+			}
+			c := binary.LittleEndian.Uint64(data)
+			if c == 0 {
+				return errors.New("zero chunk")
+			}
+			data = data[8:]
+			for j := 0; j < wordsPerChunk; j++ {
+				b.bits[i*wordsPerChunk+j] = word(c >> (j * bitsPerWord))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intsets
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// A Frozen is an immutable set of int values. Unlike a Sparse, a
+// Frozen may be shared freely, copied by assignment, and queried by
+// many goroutines at once.
+//
+// The update methods, such as Insert, return a new Frozen, leaving
+// the original unchanged. The new set shares with the original the
+// blocks of 256 elements that the update does not change, so an
+// update costs time and space proportional to the number of blocks
+// of the set, not to the number of its elements.
+//
+// A Frozen is created from a Sparse by Sparse.Freeze, and
+// its elements are copied back to a Sparse by Thaw.
+// The zero value for Frozen is a valid empty set.
+type Frozen struct {
+	// The blocks, in order of increasing offset, are never modified
+	// once the Frozen is created, and are unlinked: their next and
+	// prev fields are nil.
+	blocks []*block
+	len    int // number of elements
+}
+
+// Freeze returns an immutable copy of the set s.
+func (s *Sparse) Freeze() Frozen {
+	var f Frozen
+	for b := s.first(); b != &none; b = s.next(b) {
+		f.blocks = append(f.blocks, &block{offset: b.offset, bits: b.bits})
+		f.len += b.len()
+	}
+	return f
+}
+
+// Thaw sets s to a mutable copy of the set f.
+func (f Frozen) Thaw(s *Sparse) {
+	s.Clear()
+	for _, fb := range f.blocks {
+		b := s.insertBlockBefore(&none)
+		b.offset = fb.offset
+		b.bits = fb.bits
+	}
+}
+
+// search returns the index of the first block of f
+// whose offset is not less than offset.
+func (f Frozen) search(offset int) int {
+	return sort.Search(len(f.blocks), func(i int) bool { return f.blocks[i].offset >= offset })
+}
+
+// IsEmpty reports whether the set f is empty.
+func (f Frozen) IsEmpty() bool {
+	return f.len == 0
+}
+
+// Len returns the number of elements in the set f.
+func (f Frozen) Len() int {
+	return f.len
+}
+
+// Max returns the maximum element of the set f, or MinInt if f is empty.
+func (f Frozen) Max() int {
+	if f.IsEmpty() {
+		return MinInt
+	}
+	return f.blocks[len(f.blocks)-1].max()
+}
+
+// Min returns the minimum element of the set f, or MaxInt if f is empty.
+func (f Frozen) Min() int {
+	if f.IsEmpty() {
+		return MaxInt
+	}
+	return f.blocks[0].min(false)
+}
+
+// Has reports whether x is an element of the set f.
+func (f Frozen) Has(x int) bool {
+	offset, i := offsetAndBitIndex(x)
+	j := f.search(offset)
+	return j < len(f.blocks) && f.blocks[j].offset == offset && f.blocks[j].has(i)
+}
+
+// Insert returns the set f ∪ {x}.
+func (f Frozen) Insert(x int) Frozen {
+	offset, i := offsetAndBitIndex(x)
+	j := f.search(offset)
+	if j < len(f.blocks) && f.blocks[j].offset == offset {
+		if f.blocks[j].has(i) {
+			return f
+		}
+		b := &block{offset: offset, bits: f.blocks[j].bits}
+		b.insert(i)
+		return f.replace(j, j+1, f.len+1, b)
+	}
+	b := &block{offset: offset}
+	b.insert(i)
+	return f.replace(j, j, f.len+1, b)
+}
+
+// Remove returns the set f \ {x}.
+func (f Frozen) Remove(x int) Frozen {
+	offset, i := offsetAndBitIndex(x)
+	j := f.search(offset)
+	if j == len(f.blocks) || f.blocks[j].offset != offset || !f.blocks[j].has(i) {
+		return f
+	}
+	b := &block{offset: offset, bits: f.blocks[j].bits}
+	b.remove(i)
+	if b.empty() {
+		return f.replace(j, j+1, f.len-1)
+	}
+	return f.replace(j, j+1, f.len-1, b)
+}
+
+// replace returns a set of n elements whose blocks are those of f
+// with f.blocks[i:j] replaced by blocks.
+func (f Frozen) replace(i, j, n int, blocks ...*block) Frozen {
+	bb := make([]*block, 0, len(f.blocks)-(j-i)+len(blocks))
+	bb = append(bb, f.blocks[:i]...)
+	bb = append(bb, blocks...)
+	bb = append(bb, f.blocks[j:]...)
+	return Frozen{blocks: bb, len: n}
+}
+
+// Union returns the set f ∪ g.
+func (f Frozen) Union(g Frozen) Frozen {
+	switch {
+	case g.IsEmpty():
+		return f
+	case f.IsEmpty():
+		return g
+	}
+	var u Frozen
+	i, j := 0, 0
+	for i < len(f.blocks) || j < len(g.blocks) {
+		var b *block
+		switch {
+		case j == len(g.blocks) || i < len(f.blocks) && f.blocks[i].offset < g.blocks[j].offset:
+			b = f.blocks[i]
+			i++
+		case i == len(f.blocks) || f.blocks[i].offset > g.blocks[j].offset:
+			b = g.blocks[j]
+			j++
+		default:
+			fb, gb := f.blocks[i], g.blocks[j]
+			b = fb
+			for k := range fb.bits {
+				if fb.bits[k]|gb.bits[k] != fb.bits[k] {
+					b = &block{offset: fb.offset}
+					for k := range b.bits {
+						b.bits[k] = fb.bits[k] | gb.bits[k]
+					}
+					break
+				}
+			}
+			i++
+			j++
+		}
+		u.blocks = append(u.blocks, b)
+		u.len += b.len()
+	}
+	return u
+}
+
+// Equals reports whether the sets f and g represent the same elements.
+func (f Frozen) Equals(g Frozen) bool {
+	if f.len != g.len || len(f.blocks) != len(g.blocks) {
+		return false
+	}
+	for i, b := range f.blocks {
+		if b != g.blocks[i] && (b.offset != g.blocks[i].offset || b.bits != g.blocks[i].bits) {
+			return false
+		}
+	}
+	return true
+}
+
+// ForEach calls fn for each element of the set f in increasing order,
+// stopping early if fn returns false.
+func (f Frozen) ForEach(fn func(x int) bool) {
+	for _, b := range f.blocks {
+		if !b.iterate(fn) {
+			return
+		}
+	}
+}
+
+// AppendTo returns the result of appending the elements of f to slice
+// in order.
+func (f Frozen) AppendTo(slice []int) []int {
+	for _, b := range f.blocks {
+		b.forEach(func(x int) {
+			slice = append(slice, x)
+		})
+	}
+	return slice
+}
+
+// String returns a human-readable description of the set f.
+func (f Frozen) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, b := range f.blocks {
+		b.forEach(func(x int) {
+			if buf.Len() > 1 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(&buf, "%d", x)
+		})
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
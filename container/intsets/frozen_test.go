@@ -0,0 +1,116 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intsets_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/container/intsets"
+)
+
+// checkFrozen checks that the set f has the same elements as s.
+func checkFrozen(t *testing.T, msg string, f intsets.Frozen, s *intsets.Sparse) {
+	t.Helper()
+	if got, want := fmt.Sprint(f.AppendTo(nil)), fmt.Sprint(s.AppendTo(nil)); got != want {
+		t.Fatalf("%s: got %s, want %s", msg, got, want)
+	}
+	if f.Len() != s.Len() || f.IsEmpty() != s.IsEmpty() || f.Min() != s.Min() || f.Max() != s.Max() {
+		t.Fatalf("%s: Len, IsEmpty, Min, Max = %d, %t, %d, %d, want %d, %t, %d, %d", msg,
+			f.Len(), f.IsEmpty(), f.Min(), f.Max(), s.Len(), s.IsEmpty(), s.Min(), s.Max())
+	}
+	if got, want := f.String(), s.String(); got != want {
+		t.Fatalf("%s: String() = %s, want %s", msg, got, want)
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	var empty intsets.Frozen
+	checkFrozen(t, "zero Frozen", empty, new(intsets.Sparse))
+
+	prng := rand.New(rand.NewSource(0))
+	var s intsets.Sparse
+	f := s.Freeze()
+	for i := 0; i < 2000; i++ {
+		x := prng.Intn(5000) - 1000
+		old := f
+		oldElems := fmt.Sprint(old.AppendTo(nil))
+		if prng.Intn(3) == 0 {
+			s.Remove(x)
+			f = f.Remove(x)
+		} else {
+			s.Insert(x)
+			f = f.Insert(x)
+		}
+		checkFrozen(t, fmt.Sprintf("step %d", i), f, &s)
+		if f.Has(x) != s.Has(x) {
+			t.Fatalf("step %d: Has(%d) = %t", i, x, f.Has(x))
+		}
+		if got := fmt.Sprint(old.AppendTo(nil)); got != oldElems {
+			t.Fatalf("step %d: update changed the original set from %s to %s", i, oldElems, got)
+		}
+	}
+
+	if !f.Equals(s.Freeze()) {
+		t.Errorf("Equals(Freeze()) = false")
+	}
+	var thawed intsets.Sparse
+	thawed.Insert(-12345)
+	f.Thaw(&thawed)
+	if err := thawed.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if !thawed.Equals(&s) {
+		t.Errorf("Thaw() = %s, want %s", &thawed, &s)
+	}
+}
+
+func TestFrozenUnion(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+
+	for i := uint(0); i < 12; i++ {
+		X, Y := randomPset(prng, 1<<i), randomPset(prng, 1<<i)
+		x, y := &X.bits, &Y.bits
+
+		var z intsets.Sparse
+		z.Union(x, y)
+		fx, fy := x.Freeze(), y.Freeze()
+		u := fx.Union(fy)
+		checkFrozen(t, fmt.Sprintf("%s ∪ %s", x, y), u, &z)
+		if !u.Equals(fy.Union(fx)) {
+			t.Errorf("%s ∪ %s is not commutative", x, y)
+		}
+		checkFrozen(t, "original set", fx, x)
+	}
+}
+
+func TestFrozenConcurrentReaders(t *testing.T) {
+	var s intsets.Sparse
+	for i := 0; i < 1000; i += 3 {
+		s.Insert(i)
+	}
+	f := s.Freeze()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := f
+			for j := 0; j < 1000; j++ {
+				if f.Has(j) != (j%3 == 0) {
+					t.Errorf("Has(%d) = %t", j, f.Has(j))
+				}
+				g = g.Insert(j)
+			}
+			if g.Len() != 1000 || f.Len() != 334 {
+				t.Errorf("Len() = %d, %d, want 1000, 334", g.Len(), f.Len())
+			}
+		}()
+	}
+	wg.Wait()
+}
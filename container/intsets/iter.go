@@ -0,0 +1,22 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package intsets
+
+import "iter"
+
+// All returns an iterator over the elements of the set s
+// in increasing order. The set must not be mutated during
+// the iteration.
+func (s *Sparse) All() iter.Seq[int] {
+	return s.ForEach
+}
+
+// All returns an iterator over the elements of the set f
+// in increasing order.
+func (f Frozen) All() iter.Seq[int] {
+	return f.ForEach
+}
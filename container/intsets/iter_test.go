@@ -0,0 +1,46 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package intsets_test
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/container/intsets"
+)
+
+func TestAll(t *testing.T) {
+	var s intsets.Sparse
+	for _, x := range []int{-1000, 3, 5, 7, 300, 1 << 20} {
+		s.Insert(x)
+	}
+	if got, want := slices.Collect(s.All()), s.AppendTo(nil); !slices.Equal(got, want) {
+		t.Errorf("All(%s) = %v, want %v", &s, got, want)
+	}
+
+	var got []int
+	for x := range s.All() {
+		if x > 5 {
+			break
+		}
+		got = append(got, x)
+	}
+	if want := []int{-1000, 3, 5}; !slices.Equal(got, want) {
+		t.Errorf("All(%s) up to 5 = %v, want %v", &s, got, want)
+	}
+}
+
+func TestFrozenAll(t *testing.T) {
+	var s intsets.Sparse
+	for _, x := range []int{-1000, 3, 5, 7, 300, 1 << 20} {
+		s.Insert(x)
+	}
+	f := s.Freeze()
+	if got, want := slices.Collect(f.All()), s.AppendTo(nil); !slices.Equal(got, want) {
+		t.Errorf("All(%s) = %v, want %v", f, got, want)
+	}
+}
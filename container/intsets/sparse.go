@@ -10,6 +10,10 @@
 // space-efficient than equivalent operations on sets based on the Go
 // map type.  The IsEmpty, Min, Max, Clear and TakeMin operations
 // require constant time.
+//
+// Frozen is an immutable variant of Sparse that may be shared among
+// goroutines, whose updates return new sets that share most of their
+// representation with the original.
 package intsets // import "golang.org/x/tools/container/intsets"
 
 // TODO(adonovan):
@@ -231,6 +235,23 @@ func (b *block) forEach(f func(int)) {
 	}
 }
 
+// iterate calls f for each element of block b in order,
+// stopping early and returning false if f returns false.
+// f must not mutate b's enclosing Sparse.
+func (b *block) iterate(f func(int) bool) bool {
+	for i, w := range b.bits {
+		offset := b.offset + i*bitsPerWord
+		for w != 0 {
+			tz := ntz(w)
+			if !f(offset + tz) {
+				return false
+			}
+			w &^= 1 << uint(tz)
+		}
+	}
+	return true
+}
+
 // offsetAndBitIndex returns the offset of the block that would
 // contain x and the bit index of x within that block.
 func offsetAndBitIndex(x int) (int, uint) {
@@ -465,6 +486,19 @@ func (s *Sparse) forEach(f func(int)) {
 	}
 }
 
+// ForEach calls f for each element of the set s in increasing order,
+// stopping early if f returns false.
+//
+// f must not mutate s. A client that needs to modify s as it goes
+// should iterate over the result of AppendTo instead.
+func (s *Sparse) ForEach(f func(x int) bool) {
+	for b := s.first(); b != &none; b = s.next(b) {
+		if !b.iterate(f) {
+			return
+		}
+	}
+}
+
 // Copy sets s to the value of x.
 func (s *Sparse) Copy(x *Sparse) {
 	if s == x {
@@ -646,6 +680,57 @@ func (s *Sparse) Intersects(x *Sparse) bool {
 	return false
 }
 
+// IntersectionLen returns the number of elements in s ∩ x,
+// without computing the intersection.
+func (s *Sparse) IntersectionLen(x *Sparse) int {
+	var l int
+	sb := s.first()
+	xb := x.first()
+	for sb != &none && xb != &none {
+		switch {
+		case xb.offset < sb.offset:
+			xb = x.next(xb)
+		case xb.offset > sb.offset:
+			sb = s.next(sb)
+		default:
+			for i := range sb.bits {
+				l += popcount(sb.bits[i] & xb.bits[i])
+			}
+			sb = s.next(sb)
+			xb = x.next(xb)
+		}
+	}
+	return l
+}
+
+// UnionLen returns the number of elements in s ∪ x,
+// without computing the union.
+func (s *Sparse) UnionLen(x *Sparse) int {
+	if s == x {
+		return s.Len()
+	}
+	var l int
+	sb := s.first()
+	xb := x.first()
+	for sb != &none || xb != &none {
+		switch {
+		case sb == &none || xb != &none && xb.offset < sb.offset:
+			l += xb.len()
+			xb = x.next(xb)
+		case xb == &none || xb.offset > sb.offset:
+			l += sb.len()
+			sb = s.next(sb)
+		default:
+			for i := range sb.bits {
+				l += popcount(sb.bits[i] | xb.bits[i])
+			}
+			sb = s.next(sb)
+			xb = x.next(xb)
+		}
+	}
+	return l
+}
+
 // UnionWith sets s to the union s ∪ x, and reports whether s grew.
 func (s *Sparse) UnionWith(x *Sparse) bool {
 	if s == x {
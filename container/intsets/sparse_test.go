@@ -588,6 +588,122 @@ func TestSubsetOf(t *testing.T) {
 	}
 }
 
+func TestForEach(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+
+	for i := uint(0); i < 12; i++ {
+		X := randomPset(prng, 1<<i)
+		x := &X.bits
+		want := x.AppendTo(nil)
+
+		var got []int
+		x.ForEach(func(elt int) bool {
+			got = append(got, elt)
+			return true
+		})
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("ForEach(%s) visited %v", x, got)
+		}
+
+		// Stop halfway.
+		got = nil
+		x.ForEach(func(elt int) bool {
+			got = append(got, elt)
+			return len(got) < len(want)/2
+		})
+		if n := max(len(want)/2, min(len(want), 1)); fmt.Sprint(got) != fmt.Sprint(want[:n]) {
+			t.Errorf("ForEach(%s) stopping after %d elements visited %v", x, n, got)
+		}
+	}
+}
+
+func TestIntersectionLenAndUnionLen(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+
+	for i := uint(0); i < 12; i++ {
+		X, Y := randomPset(prng, 1<<i), randomPset(prng, 1<<i)
+		x, y := &X.bits, &Y.bits
+		x.Insert(-prng.Intn(1000)) // exercise negative blocks
+
+		var z intsets.Sparse
+		z.Intersection(x, y)
+		if got, want := x.IntersectionLen(y), z.Len(); got != want {
+			t.Errorf("IntersectionLen(%s, %s) = %d, want %d", x, y, got, want)
+		}
+		z.Union(x, y)
+		if got, want := x.UnionLen(y), z.Len(); got != want {
+			t.Errorf("UnionLen(%s, %s) = %d, want %d", x, y, got, want)
+		}
+		if got, want := x.UnionLen(x), x.Len(); got != want {
+			t.Errorf("UnionLen(%s, itself) = %d, want %d", x, got, want)
+		}
+		if got, want := x.IntersectionLen(&z), x.Len(); got != want {
+			t.Errorf("IntersectionLen(%s, %s) = %d, want %d", x, &z, got, want)
+		}
+	}
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+
+	for i := uint(0); i < 12; i++ {
+		X := randomPset(prng, 1<<i)
+		x := &X.bits
+		x.Insert(-prng.Intn(100000))
+		if i%2 == 0 {
+			x.Insert(intsets.MinInt)
+			x.Insert(intsets.MaxInt)
+		}
+
+		data, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var y intsets.Sparse
+		y.Insert(12345) // UnmarshalBinary replaces the old elements
+		if err := y.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary(MarshalBinary(%s)): %v", x, err)
+		}
+		if err := y.Check(); err != nil {
+			t.Fatalf("UnmarshalBinary(MarshalBinary(%s)): %v: %#v", x, err, &y)
+		}
+		if !y.Equals(x) {
+			t.Errorf("UnmarshalBinary(MarshalBinary(%s)) = %s", x, &y)
+		}
+	}
+
+	var empty intsets.Sparse
+	data, _ := empty.MarshalBinary()
+	if len(data) != 1 {
+		t.Errorf("MarshalBinary({}) = %x, want a single byte", data)
+	}
+
+	// A set of one element in each of two adjacent blocks.
+	var x intsets.Sparse
+	x.Insert(1)
+	x.Insert(300)
+	data, _ = x.MarshalBinary()
+	for _, bad := range [][]byte{
+		nil,                                   // no version
+		{2},                                   // unknown version
+		data[:len(data)-1],                    // truncated
+		data[:2],                              // missing mask
+		append(data[:len(data):len(data)], 1), // missing mask of third block
+		{1, 0, 0},                             // empty mask
+		{1, 0, 0x10},                          // mask has too many chunks
+		{1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0},     // zero chunk
+		{1, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0}, // zero delta
+	} {
+		var y intsets.Sparse
+		y.Insert(1)
+		if err := y.UnmarshalBinary(bad); err == nil {
+			t.Errorf("UnmarshalBinary(%x) succeeded: %s", bad, &y)
+		} else if !y.IsEmpty() {
+			t.Errorf("UnmarshalBinary(%x) failed but left %s", bad, &y)
+		}
+	}
+}
+
 func TestBitString(t *testing.T) {
 	for _, test := range []struct {
 		input []int
@@ -0,0 +1,670 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+// This file reads and writes the binary coverage data files written
+// to the GOCOVERDIR directory by programs built with "go build -cover"
+// (Go 1.20 and later). The format is defined by the Go toolchain's
+// internal/coverage package; see its defs.go for the details.
+//
+// A directory holds one or more meta-data files, named
+// covmeta.<hash>, each describing the coverable units of all the
+// packages of a program, and any number of counter data files, named
+// covcounters.<hash>.<pid>.<time>, each holding the counters of one
+// run of the program whose meta-data file has the given hash.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	metaFilePrefix    = "covmeta"
+	counterFilePrefix = "covcounters"
+
+	metaFileVersion    = 1
+	counterFileVersion = 1
+
+	metaFileHeaderSize    = 56 // size of the header of a meta-data file
+	metaSymbolHeaderSize  = 44 // size of the header of the meta-data of a package
+	counterFileHeaderSize = 32 // size of the header of a counter data file
+	counterFooterSize     = 16 // size of the footer of a segment of a counter data file
+
+	perBlock = 1 // counter granularity of a counter for each block
+
+	rawFlavor  = 1 // counters stored as uint32s
+	ulebFlavor = 2 // counters stored as ULEB128 values
+)
+
+var (
+	metaMagic    = []byte("\x00cvm")
+	counterMagic = []byte("\x00cwm")
+)
+
+// counterModes lists the modes in the order of their values in the
+// header of a meta-data file, starting at 1.
+var counterModes = []string{"set", "count", "atomic"}
+
+// ParseCoverDir reads the binary coverage data files written to the
+// directory dir, the value of the GOCOVERDIR environment variable
+// when running programs built with "go build -cover", and returns a
+// Profile for each source file described therein, as reported by
+// "go tool covdata textfmt".
+//
+// The counts of the runs of a program, and of the programs that
+// cover the same files, are merged as by Merge. Files not executed
+// by any run have profiles with counts of zero. The names of the
+// files are those recorded by the compiler, typically the import
+// path of their package followed by their base name.
+func ParseCoverDir(dir string) ([]*Profile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type pod struct {
+		meta     *metaFile
+		counters map[funcKey][]uint32
+	}
+	pods := make(map[string]*pod) // keyed by hash of meta-data file
+	var counterFiles []string
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case strings.HasPrefix(name, metaFilePrefix+"."):
+			meta, err := readMetaFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			pods[meta.hash] = &pod{meta: meta, counters: make(map[funcKey][]uint32)}
+		case strings.HasPrefix(name, counterFilePrefix+"."):
+			counterFiles = append(counterFiles, name)
+		}
+	}
+	for _, name := range counterFiles {
+		filename := filepath.Join(dir, name)
+		hash, counters, err := readCounterFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		p := pods[hash]
+		if p == nil {
+			return nil, fmt.Errorf("%s: no meta-data file for hash %s", filename, hash)
+		}
+		for key, c := range counters {
+			p.counters[key] = mergeCounters(p.meta.mode, p.counters[key], c)
+		}
+	}
+
+	var lists [][]*Profile
+	for _, p := range pods {
+		lists = append(lists, p.meta.profiles(p.counters))
+	}
+	return Merge(lists...)
+}
+
+// funcKey identifies a function of a package in a meta-data file.
+type funcKey struct{ pkg, fn uint32 }
+
+// mergeCounters merges the counters of a function from another run
+// into x, and returns the result.
+func mergeCounters(mode string, x, y []uint32) []uint32 {
+	for len(x) < len(y) {
+		x = append(x, 0)
+	}
+	for i, c := range y {
+		if mode == "set" {
+			x[i] |= c
+		} else if x[i] += c; x[i] < c {
+			x[i] = math.MaxUint32 // saturate on overflow
+		}
+	}
+	return x
+}
+
+// metaFile is the contents of a meta-data file.
+type metaFile struct {
+	hash string // hex hash that identifies the file and its counter data files
+	mode string
+	gran uint8 // counter granularity
+	pkgs []metaPackage
+}
+
+// metaPackage is the meta-data of a package.
+type metaPackage struct {
+	path  string
+	funcs []metaFunc
+}
+
+// metaFunc is the meta-data of a function.
+type metaFunc struct {
+	name  string
+	file  string
+	units []metaUnit
+}
+
+// metaUnit is the meta-data of a coverable unit of a function.
+type metaUnit struct {
+	startLine, startCol, endLine, endCol, numStmt int
+}
+
+// profiles returns a profile for each file of m, given the counters
+// of its functions.
+func (m *metaFile) profiles(counters map[funcKey][]uint32) []*Profile {
+	files := make(map[string]*Profile)
+	var profiles []*Profile
+	for i, pkg := range m.pkgs {
+		for j, fn := range pkg.funcs {
+			p := files[fn.file]
+			if p == nil {
+				p = &Profile{FileName: fn.file, Mode: m.mode}
+				files[fn.file] = p
+				profiles = append(profiles, p)
+			}
+			c := counters[funcKey{uint32(i), uint32(j)}]
+			for k, u := range fn.units {
+				var count uint32
+				if m.gran != perBlock && len(c) > 0 {
+					count = c[0] // a single counter for the function
+				} else if k < len(c) {
+					count = c[k]
+				}
+				p.Blocks = append(p.Blocks, ProfileBlock{
+					StartLine: u.startLine,
+					StartCol:  u.startCol,
+					EndLine:   u.endLine,
+					EndCol:    u.endCol,
+					NumStmt:   u.numStmt,
+					Count:     int(count),
+				})
+			}
+		}
+	}
+	return profiles
+}
+
+// readMetaFile reads the named meta-data file.
+func readMetaFile(filename string) (*metaFile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	m, err := decodeMetaFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid meta-data file: %v", filename, err)
+	}
+	return m, nil
+}
+
+// decodeMetaFile decodes the contents of a meta-data file.
+func decodeMetaFile(data []byte) (*metaFile, error) {
+	d := &decoder{data: data}
+	if !bytes.Equal(d.bytes(4), metaMagic) {
+		return nil, errors.New("bad magic number")
+	}
+	if v := d.uint32(); v > metaFileVersion {
+		return nil, fmt.Errorf("unknown version %d", v)
+	}
+	d.uint64() // total length
+	n := d.uint64()
+	if d.err == nil && n > uint64(len(data))/16 {
+		return nil, errors.New("bad number of packages")
+	}
+	m := &metaFile{hash: fmt.Sprintf("%x", d.bytes(16))}
+	d.uint32() // offset of string table
+	d.uint32() // length of string table
+	mode := int(d.uint8())
+	if mode < 1 || mode > len(counterModes) {
+		return nil, fmt.Errorf("bad counter mode %d", mode)
+	}
+	m.mode = counterModes[mode-1]
+	m.gran = d.uint8()
+	d.bytes(6) // padding
+
+	offsets := make([]uint64, n)
+	for i := range offsets {
+		offsets[i] = d.uint64()
+	}
+	lengths := make([]uint64, n)
+	for i := range lengths {
+		lengths[i] = d.uint64()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	for i := range offsets {
+		off, size := offsets[i], lengths[i]
+		if off > uint64(len(data)) || size > uint64(len(data))-off {
+			return nil, fmt.Errorf("package %d out of range", i)
+		}
+		pkg, err := decodePackage(data[off : off+size])
+		if err != nil {
+			return nil, fmt.Errorf("package %d: %v", i, err)
+		}
+		m.pkgs = append(m.pkgs, pkg)
+	}
+	return m, nil
+}
+
+// decodePackage decodes the meta-data of a package.
+func decodePackage(data []byte) (metaPackage, error) {
+	d := &decoder{data: data}
+	d.uint32() // length
+	d.uint32() // index of package name
+	pathIndex := d.uint32()
+	d.uint32()      // index of module path
+	d.bytes(16 + 4) // hash and padding
+	d.uint32()      // number of files
+	nfuncs := d.uint32()
+	if d.err == nil && uint64(nfuncs) > uint64(len(data))/4 {
+		return metaPackage{}, errors.New("bad number of functions")
+	}
+	offsets := make([]uint32, nfuncs)
+	for i := range offsets {
+		offsets[i] = d.uint32()
+	}
+	strs := d.stringTable()
+	str := func(i uint64) string {
+		if i >= uint64(len(strs)) {
+			d.fail(fmt.Errorf("bad string index %d", i))
+			return ""
+		}
+		return strs[i]
+	}
+
+	var pkg metaPackage
+	pkg.path = str(uint64(pathIndex))
+	for _, off := range offsets {
+		if d.err != nil {
+			break
+		}
+		if uint64(off) > uint64(len(data)) {
+			return metaPackage{}, fmt.Errorf("function out of range")
+		}
+		d.off = int(off)
+		var fn metaFunc
+		nunits := d.uleb()
+		fn.name = str(d.uleb())
+		fn.file = str(d.uleb())
+		for j := uint64(0); j < nunits && d.err == nil; j++ {
+			fn.units = append(fn.units, metaUnit{
+				startLine: int(d.uleb()),
+				startCol:  int(d.uleb()),
+				endLine:   int(d.uleb()),
+				endCol:    int(d.uleb()),
+				numStmt:   int(d.uleb()),
+			})
+		}
+		d.uleb() // whether the function is a literal
+		pkg.funcs = append(pkg.funcs, fn)
+	}
+	return pkg, d.err
+}
+
+// readCounterFile reads the named counter data file, and returns the
+// hash of its meta-data file and the merged counters of the functions
+// executed by all its segments.
+func readCounterFile(filename string) (string, map[funcKey][]uint32, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", nil, err
+	}
+	hash, counters, err := decodeCounterFile(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: invalid counter data file: %v", filename, err)
+	}
+	return hash, counters, nil
+}
+
+// decodeCounterFile decodes the contents of a counter data file.
+func decodeCounterFile(data []byte) (string, map[funcKey][]uint32, error) {
+	d := &decoder{data: data}
+	if !bytes.Equal(d.bytes(4), counterMagic) {
+		return "", nil, errors.New("bad magic number")
+	}
+	if v := d.uint32(); v > counterFileVersion {
+		return "", nil, fmt.Errorf("unknown version %d", v)
+	}
+	hash := fmt.Sprintf("%x", d.bytes(16))
+	flavor := d.uint8()
+	bigEndian := d.uint8() != 0
+	d.bytes(6) // padding
+	if d.err == nil && flavor != rawFlavor && flavor != ulebFlavor {
+		return "", nil, fmt.Errorf("unknown counter flavor %d", flavor)
+	}
+
+	// The number of segments is recorded in the footer of the last one.
+	if len(data) < counterFileHeaderSize+counterFooterSize {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	footer := data[len(data)-counterFooterSize:]
+	if !bytes.Equal(footer[:4], counterMagic) {
+		return "", nil, errors.New("bad magic number in footer")
+	}
+	nsegs := binary.LittleEndian.Uint32(footer[8:])
+
+	value := func() uint32 {
+		switch {
+		case flavor == ulebFlavor:
+			return uint32(d.uleb())
+		case bigEndian:
+			return binary.BigEndian.Uint32(d.bytes(4))
+		default:
+			return d.uint32()
+		}
+	}
+	counters := make(map[funcKey][]uint32)
+	for seg := uint32(0); seg < nsegs && d.err == nil; seg++ {
+		nfuncs := d.uint64()
+		strTabLen := d.uint32()
+		argsLen := d.uint32()
+		d.bytes(int(strTabLen) + int(argsLen)) // string table and arguments
+		d.bytes(-d.off & 3)                    // padding to a multiple of 4 bytes
+		for i := uint64(0); i < nfuncs && d.err == nil; i++ {
+			n := value()
+			key := funcKey{value(), value()}
+			if d.err == nil && uint64(n) > uint64(len(data)-d.off) {
+				return "", nil, errors.New("bad number of counters")
+			}
+			c := make([]uint32, n)
+			for j := range c {
+				c[j] = value()
+			}
+			// There are no duplicates within a segment,
+			// but there may be between segments.
+			counters[key] = mergeCounters("count", counters[key], c)
+		}
+		d.bytes(counterFooterSize)
+	}
+	if d.err != nil {
+		return "", nil, d.err
+	}
+	return hash, counters, nil
+}
+
+// A decoder decodes the little-endian values of the coverage data
+// files. After an error, all values are zero.
+type decoder struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// bytes returns the next n bytes.
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil || n < 0 || n > len(d.data)-d.off {
+		d.fail(io.ErrUnexpectedEOF)
+		return make([]byte, max(n, 0))
+	}
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *decoder) uint8() uint8   { return d.bytes(1)[0] }
+func (d *decoder) uint32() uint32 { return binary.LittleEndian.Uint32(d.bytes(4)) }
+func (d *decoder) uint64() uint64 { return binary.LittleEndian.Uint64(d.bytes(8)) }
+
+// uleb returns the next ULEB128-encoded value.
+func (d *decoder) uleb() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.off:])
+	if n <= 0 {
+		d.fail(errors.New("bad ULEB128 value"))
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// stringTable returns the strings of the next string table.
+func (d *decoder) stringTable() []string {
+	n := d.uleb()
+	if d.err == nil && n > uint64(len(d.data)-d.off) {
+		d.fail(errors.New("bad string table"))
+	}
+	var strs []string
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		l := d.uleb()
+		if l > uint64(len(d.data)-d.off) {
+			d.fail(io.ErrUnexpectedEOF)
+			break
+		}
+		strs = append(strs, string(d.bytes(int(l))))
+	}
+	return strs
+}
+
+// WriteCoverDir writes the profiles, which must all be in the same
+// mode, to the directory dir as a meta-data file and a counter data
+// file in the format of the files written to GOCOVERDIR, so that they
+// may be merged with coverage data collected from programs built with
+// "go build -cover", such as by "go tool covdata merge".
+// It writes nothing if there are no profiles.
+//
+// A textual profile records neither the names of packages nor those
+// of functions, so the package of each file is taken to be the
+// directory in its name, and the blocks of each file are recorded as
+// those of a single function named after the file.
+// Counts that do not fit in 32 bits are truncated to the largest value
+// that does.
+func WriteCoverDir(dir string, profiles []*Profile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	mode := profiles[0].Mode
+	modeIndex := -1
+	for i, m := range counterModes {
+		if m == mode {
+			modeIndex = i
+		}
+	}
+	if modeIndex < 0 {
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+
+	// Group the files by package.
+	pkgs := make(map[string][]*Profile)
+	for _, p := range profiles {
+		if p.Mode != mode {
+			return fmt.Errorf("cannot write profiles in modes %q and %q", mode, p.Mode)
+		}
+		dir := path.Dir(p.FileName)
+		pkgs[dir] = append(pkgs[dir], p)
+	}
+	paths := make([]string, 0, len(pkgs))
+	for path := range pkgs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Encode the meta-data and the counters of each package.
+	fileHash := fnv.New128a()
+	var blobs [][]byte
+	var counters []byte
+	nfuncs := 0
+	for i, pkgPath := range paths {
+		files := pkgs[pkgPath]
+		sort.Sort(byFileName(files))
+		blob := encodePackage(pkgPath, files)
+		blobs = append(blobs, blob)
+		fileHash.Write(blob[16:32]) // hash of the package
+
+		for j, p := range files {
+			var c []uint32
+			live := false
+			for _, b := range p.Blocks {
+				count := uint32(min(max(int64(b.Count), 0), math.MaxUint32))
+				c = append(c, count)
+				live = live || count != 0
+			}
+			if !live {
+				// Like the runtime, record only executed functions.
+				continue
+			}
+			nfuncs++
+			counters = binary.AppendUvarint(counters, uint64(len(c)))
+			counters = binary.AppendUvarint(counters, uint64(i))
+			counters = binary.AppendUvarint(counters, uint64(j))
+			for _, count := range c {
+				counters = binary.AppendUvarint(counters, uint64(count))
+			}
+		}
+	}
+	fileHash.Write([]byte(mode))
+	fileHash.Write([]byte("perblock"))
+	hash := fileHash.Sum(nil)
+
+	// Write the meta-data file.
+	strTab := appendStringTable(nil, []string{""})
+	preamble := metaFileHeaderSize + 16*len(blobs) + len(strTab)
+	size := preamble
+	for _, blob := range blobs {
+		size += len(blob)
+	}
+	meta := append([]byte(nil), metaMagic...)
+	meta = binary.LittleEndian.AppendUint32(meta, metaFileVersion)
+	meta = binary.LittleEndian.AppendUint64(meta, uint64(size))
+	meta = binary.LittleEndian.AppendUint64(meta, uint64(len(blobs)))
+	meta = append(meta, hash...)
+	meta = binary.LittleEndian.AppendUint32(meta, uint32(metaFileHeaderSize+16*len(blobs)))
+	meta = binary.LittleEndian.AppendUint32(meta, uint32(len(strTab)))
+	meta = append(meta, byte(modeIndex+1), perBlock, 0, 0, 0, 0, 0, 0)
+	off := preamble
+	for _, blob := range blobs {
+		meta = binary.LittleEndian.AppendUint64(meta, uint64(off))
+		off += len(blob)
+	}
+	for _, blob := range blobs {
+		meta = binary.LittleEndian.AppendUint64(meta, uint64(len(blob)))
+	}
+	meta = append(meta, strTab...)
+	for _, blob := range blobs {
+		meta = append(meta, blob...)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%s.%x", metaFilePrefix, hash)), meta, 0o666); err != nil {
+		return err
+	}
+
+	// Write the counter data file, with a single segment
+	// and no arguments.
+	cf := append([]byte(nil), counterMagic...)
+	cf = binary.LittleEndian.AppendUint32(cf, counterFileVersion)
+	cf = append(cf, hash...)
+	cf = append(cf, ulebFlavor, 0, 0, 0, 0, 0, 0, 0)
+	strTab = appendStringTable(nil, []string{""})
+	args := binary.AppendUvarint(nil, 0)
+	for (len(strTab)+len(args))%4 != 0 {
+		args = append(args, 0) // padding
+	}
+	cf = binary.LittleEndian.AppendUint64(cf, uint64(nfuncs))
+	cf = binary.LittleEndian.AppendUint32(cf, uint32(len(strTab)))
+	cf = binary.LittleEndian.AppendUint32(cf, uint32(len(args)))
+	cf = append(cf, strTab...)
+	cf = append(cf, args...)
+	cf = append(cf, counters...)
+	cf = append(cf, counterMagic...)
+	cf = binary.LittleEndian.AppendUint32(cf, 0)
+	cf = binary.LittleEndian.AppendUint32(cf, 1) // number of segments
+	cf = binary.LittleEndian.AppendUint32(cf, 0)
+	name := fmt.Sprintf("%s.%x.%d.%d", counterFilePrefix, hash, os.Getpid(), time.Now().UnixNano())
+	return os.WriteFile(filepath.Join(dir, name), cf, 0o666)
+}
+
+// encodePackage returns the meta-data of the package with the given
+// import path and files, with a function for each file.
+func encodePackage(pkgPath string, files []*Profile) []byte {
+	h := fnv.New128a()
+	hash32 := func(x int) {
+		h.Write(binary.LittleEndian.AppendUint32(nil, uint32(x)))
+	}
+	strs := []string{""}
+	index := func(s string) uint64 {
+		for i, str := range strs {
+			if str == s {
+				return uint64(i)
+			}
+		}
+		strs = append(strs, s)
+		return uint64(len(strs) - 1)
+	}
+	pathIndex := index(pkgPath)
+	nameIndex := index(path.Base(pkgPath))
+	modIndex := index("")
+	io.WriteString(h, pkgPath)
+	io.WriteString(h, path.Base(pkgPath))
+
+	var funcs [][]byte
+	for _, p := range files {
+		name := path.Base(p.FileName)
+		io.WriteString(h, name)
+		io.WriteString(h, p.FileName)
+		fn := binary.AppendUvarint(nil, uint64(len(p.Blocks)))
+		fn = binary.AppendUvarint(fn, index(name))
+		fn = binary.AppendUvarint(fn, index(p.FileName))
+		for _, b := range p.Blocks {
+			for _, x := range []int{b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt} {
+				fn = binary.AppendUvarint(fn, uint64(x))
+				hash32(x)
+			}
+		}
+		fn = binary.AppendUvarint(fn, 0) // not a function literal
+		hash32(0)
+		funcs = append(funcs, fn)
+	}
+
+	strTab := appendStringTable(nil, strs)
+	size := metaSymbolHeaderSize + 4*len(funcs) + len(strTab)
+	for _, fn := range funcs {
+		size += len(fn)
+	}
+	blob := binary.LittleEndian.AppendUint32(nil, uint32(size))
+	blob = binary.LittleEndian.AppendUint32(blob, uint32(nameIndex))
+	blob = binary.LittleEndian.AppendUint32(blob, uint32(pathIndex))
+	blob = binary.LittleEndian.AppendUint32(blob, uint32(modIndex))
+	blob = h.Sum(blob)
+	blob = append(blob, 0, 0, 0, 0) // unused and padding
+	blob = binary.LittleEndian.AppendUint32(blob, uint32(len(strs)))
+	blob = binary.LittleEndian.AppendUint32(blob, uint32(len(funcs)))
+	off := metaSymbolHeaderSize + 4*len(funcs) + len(strTab)
+	for _, fn := range funcs {
+		blob = binary.LittleEndian.AppendUint32(blob, uint32(off))
+		off += len(fn)
+	}
+	blob = append(blob, strTab...)
+	for _, fn := range funcs {
+		blob = append(blob, fn...)
+	}
+	return blob
+}
+
+// appendStringTable appends the encoding of a string table
+// holding strs to b.
+func appendStringTable(b []byte, strs []string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(strs)))
+	for _, s := range strs {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	return b
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestWriteCoverDir(t *testing.T) {
+	profiles := parse(t, `mode: count
+example.com/m/a.go:1.10,3.2 2 5
+example.com/m/a.go:4.1,4.9 1 0
+example.com/m/b.go:1.1,2.1 1 4294967296
+example.com/m/p/c.go:3.3,4.4 3 0
+`)
+	dir := t.TempDir()
+	if err := WriteCoverDir(dir, profiles); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseCoverDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `mode: count
+example.com/m/a.go:1.10,3.2 2 5
+example.com/m/a.go:4.1,4.9 1 0
+example.com/m/b.go:1.1,2.1 1 4294967295
+example.com/m/p/c.go:3.3,4.4 3 0
+`
+	if got := format(t, got); got != want {
+		t.Errorf("ParseCoverDir after WriteCoverDir returned:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A second run adds to the counts.
+	if err := WriteCoverDir(dir, profiles[:1]); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ParseCoverDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := format(t, got); !strings.Contains(got, "a.go:1.10,3.2 2 10\n") {
+		t.Errorf("ParseCoverDir after two runs returned:\n%s", got)
+	}
+}
+
+func TestWriteCoverDirMixedModes(t *testing.T) {
+	profiles := []*Profile{{FileName: "a.go", Mode: "set"}, {FileName: "b.go", Mode: "count"}}
+	if err := WriteCoverDir(t.TempDir(), profiles); err == nil {
+		t.Error("WriteCoverDir succeeded with mixed modes")
+	}
+}
+
+func TestParseCoverDirErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, data string
+	}{
+		{"covmeta.00", "not a meta-data file"},
+		{"covmeta.01", "\x00cvm\x01\x00\x00\x00"},
+		{"covcounters.00.1.2", "\x00cwm\x01\x00\x00\x00"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, test.name), []byte(test.data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseCoverDir(dir); err == nil {
+			t.Errorf("ParseCoverDir succeeded with %s containing %q", test.name, test.data)
+		}
+	}
+}
+
+// TestCoverDirGoCommand checks that ParseCoverDir agrees with
+// "go tool covdata textfmt" about the data written by a program built
+// with "go build -cover", and that the go command can read the data
+// written by WriteCoverDir.
+func TestCoverDirGoCommand(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+	testenv.NeedsGo1Point(t, 20)
+	t.Setenv("GOFLAGS", "")
+
+	src := t.TempDir()
+	for name, data := range map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.20\n",
+		"main.go": `package main
+
+import (
+	"example.com/m/p"
+	"os"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		p.F(len(os.Args))
+		return
+	}
+	p.F(0)
+}
+`,
+		"p/p.go": `package p
+
+var x int
+
+func F(n int) {
+	for i := 0; i < n; i++ {
+		x++
+	}
+	if n > 2 {
+		x--
+	}
+	func() { x *= 2 }()
+}
+
+func G() { x = 0 }
+`,
+	} {
+		filename := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exe := filepath.Join(t.TempDir(), "prog")
+	cmd := exec.Command("go", "build", "-cover", "-covermode=count", "-coverpkg=./...", "-o", exe, ".")
+	cmd.Dir = src
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+
+	coverDir := t.TempDir()
+	for _, args := range [][]string{nil, {"a", "b"}, {"a", "b", "c"}} {
+		cmd := exec.Command(exe, args...)
+		cmd.Env = append(os.Environ(), "GOCOVERDIR="+coverDir)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("running program failed: %v\n%s", err, out)
+		}
+	}
+
+	// textfmt returns the result of "go tool covdata textfmt" on dir.
+	textfmt := func(dir string) string {
+		t.Helper()
+		out := filepath.Join(t.TempDir(), "cover.out")
+		cmd := exec.Command("go", "tool", "covdata", "textfmt", "-i="+dir, "-o="+out)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go tool covdata failed: %v\n%s", err, output)
+		}
+		profiles, err := ParseProfiles(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return format(t, profiles)
+	}
+
+	profiles, err := ParseCoverDir(coverDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, want := format(t, profiles), textfmt(coverDir)
+	if got != want {
+		t.Errorf("ParseCoverDir returned:\n%s\nwant:\n%s", got, want)
+	}
+
+	written := t.TempDir()
+	if err := WriteCoverDir(written, profiles); err != nil {
+		t.Fatal(err)
+	}
+	if got := textfmt(written); got != want {
+		t.Errorf("go tool covdata textfmt after WriteCoverDir returned:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+)
+
+// FuncCoverage is the coverage of a function,
+// as reported by "go tool cover -func".
+type FuncCoverage struct {
+	FileName string // name of the file, as in the profile
+	Name     string // name of the function or method
+	Recv     string // receiver base type of a method, such as "T" or "*T"; empty for a function
+
+	StartLine, StartCol int // position of the declaration in the file
+	EndLine, EndCol     int // position of the end of the declaration
+
+	NumStmt int // number of statements
+	Covered int // number of statements executed at least once
+}
+
+// Percent returns the percentage of the statements of the function
+// that were executed, or 0 if it has none.
+func (f *FuncCoverage) Percent() float64 { return percent(f.Covered, f.NumStmt) }
+
+// Funcs returns the coverage of each function with a body declared in
+// the source file of the profile p, whose contents are src, in the
+// order of their declarations.
+func (p *Profile) Funcs(src []byte) ([]FuncCoverage, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, p.FileName, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var funcs []FuncCoverage
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || decl.Body == nil {
+			// Do not count declarations of assembly functions.
+			continue
+		}
+		start := fset.Position(decl.Pos())
+		end := fset.Position(decl.End())
+		fc := FuncCoverage{
+			FileName:  p.FileName,
+			Name:      decl.Name.Name,
+			StartLine: start.Line,
+			StartCol:  start.Column,
+			EndLine:   end.Line,
+			EndCol:    end.Column,
+		}
+		if decl.Recv != nil && len(decl.Recv.List) == 1 {
+			fc.Recv = recvString(decl.Recv.List[0].Type)
+		}
+		fc.Covered, fc.NumStmt = p.coverage(fc.StartLine, fc.StartCol, fc.EndLine, fc.EndCol)
+		funcs = append(funcs, fc)
+	}
+	return funcs, nil
+}
+
+// recvString returns the receiver base type of a method with
+// receiver type expr, without any type parameters.
+func recvString(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return "*" + recvString(expr.X)
+	case *ast.ParenExpr:
+		return recvString(expr.X)
+	case *ast.IndexExpr:
+		return recvString(expr.X)
+	case *ast.IndexListExpr:
+		return recvString(expr.X)
+	}
+	return ""
+}
+
+// coverage returns the number of statements executed and the total
+// number of statements in the blocks of p within the given extent.
+func (p *Profile) coverage(startLine, startCol, endLine, endCol int) (covered, total int) {
+	// The blocks are sorted, so we can stop counting as soon as we
+	// reach the end of the extent.
+	for _, b := range p.Blocks {
+		if b.StartLine > endLine || (b.StartLine == endLine && b.StartCol >= endCol) {
+			// Past the end of the extent.
+			break
+		}
+		if b.EndLine < startLine || (b.EndLine == startLine && b.EndCol <= startCol) {
+			// Before the beginning of the extent.
+			continue
+		}
+		total += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+	return covered, total
+}
+
+// PackageCoverage is the coverage of the statements of a package.
+type PackageCoverage struct {
+	ImportPath string // directory of the file names of the package, as in the profiles
+	NumStmt    int    // number of statements
+	Covered    int    // number of statements executed at least once
+}
+
+// Percent returns the percentage of the statements of the package
+// that were executed, or 0 if it has none.
+func (c *PackageCoverage) Percent() float64 { return percent(c.Covered, c.NumStmt) }
+
+// Packages returns the coverage of each package described by the
+// profiles, sorted by import path. The files of a package are those
+// in the same directory, and its statements are those of the blocks
+// of their profiles, so that it need not have the source files.
+func Packages(profiles []*Profile) []PackageCoverage {
+	pkgs := make(map[string]*PackageCoverage)
+	for _, p := range profiles {
+		dir := path.Dir(p.FileName)
+		pkg := pkgs[dir]
+		if pkg == nil {
+			pkg = &PackageCoverage{ImportPath: dir}
+			pkgs[dir] = pkg
+		}
+		for _, b := range p.Blocks {
+			pkg.NumStmt += b.NumStmt
+			if b.Count > 0 {
+				pkg.Covered += b.NumStmt
+			}
+		}
+	}
+	list := make([]PackageCoverage, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		list = append(list, *pkg)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ImportPath < list[j].ImportPath })
+	return list
+}
+
+// percent returns the percentage of covered in total, or 0 if total is 0.
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
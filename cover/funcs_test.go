@@ -0,0 +1,87 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"reflect"
+	"testing"
+)
+
+const funcsSrc = `package p
+
+func F(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+type T[K any] struct{}
+
+func (t *T[K]) M() {
+	println()
+}
+
+func Asm()
+
+func (T[K]) Unused() {
+	println()
+	println()
+}
+`
+
+func TestFuncs(t *testing.T) {
+	profiles := parse(t, `mode: set
+example.com/p/p.go:3.19,4.11 1 1
+example.com/p/p.go:4.11,6.3 1 0
+example.com/p/p.go:7.2,7.10 1 1
+example.com/p/p.go:12.20,14.2 1 1
+example.com/p/p.go:18.22,21.2 2 0
+example.com/p/q.go:1.1,2.2 3 1
+example.com/r/r.go:1.1,2.2 2 0
+`)
+	funcs, err := profiles[0].Funcs([]byte(funcsSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FuncCoverage{
+		{"example.com/p/p.go", "F", "", 3, 1, 8, 2, 3, 2},
+		{"example.com/p/p.go", "M", "*T", 12, 1, 14, 2, 1, 1},
+		{"example.com/p/p.go", "Unused", "T", 18, 1, 21, 2, 2, 0},
+	}
+	if !reflect.DeepEqual(funcs, want) {
+		t.Errorf("Funcs:\n got %+v\nwant %+v", funcs, want)
+	}
+	if got, want := funcs[0].Percent(), 100*2.0/3; got != want {
+		t.Errorf("Percent = %v, want %v", got, want)
+	}
+
+	if _, err := profiles[0].Funcs([]byte("package")); err == nil {
+		t.Errorf("Funcs of invalid source succeeded, want an error")
+	}
+}
+
+func TestPackages(t *testing.T) {
+	profiles := parse(t, `mode: count
+example.com/p/p.go:1.1,2.2 3 4
+example.com/p/p.go:3.1,4.2 1 0
+example.com/p/q.go:1.1,2.2 2 1
+example.com/r/r.go:1.1,2.2 2 0
+`)
+	got := Packages(profiles)
+	want := []PackageCoverage{
+		{"example.com/p", 6, 5},
+		{"example.com/r", 2, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Packages = %+v, want %+v", got, want)
+	}
+	if p := got[1].Percent(); p != 0 {
+		t.Errorf("Percent = %v, want 0", p)
+	}
+	if p := (&PackageCoverage{}).Percent(); p != 0 {
+		t.Errorf("Percent of no statements = %v, want 0", p)
+	}
+}
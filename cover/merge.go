@@ -0,0 +1,141 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Merge merges lists of profiles, such as those parsed from the
+// profiles written by the shards of a test run, and returns a Profile
+// for each source file described by any of them, sorted by file name.
+//
+// The blocks of the merged profile of a file are the union of the
+// blocks of its profiles. The count of a block at the same location in
+// several profiles is the sum of their counts, or, in "set" mode,
+// 1 if any of them is 1. All the profiles must be in the same mode;
+// Convert converts profiles between modes.
+//
+// Merge does not modify the given profiles.
+func Merge(lists ...[]*Profile) ([]*Profile, error) {
+	files := make(map[string]*Profile)
+	mode := ""
+	for _, list := range lists {
+		for _, p := range list {
+			if mode == "" {
+				mode = p.Mode
+			} else if p.Mode != mode {
+				return nil, fmt.Errorf("cannot merge profiles in modes %q and %q", mode, p.Mode)
+			}
+			m := files[p.FileName]
+			if m == nil {
+				m = &Profile{FileName: p.FileName, Mode: p.Mode}
+				files[p.FileName] = m
+			}
+			m.Blocks = append(m.Blocks, p.Blocks...)
+		}
+	}
+	profiles := make([]*Profile, 0, len(files))
+	for _, p := range files {
+		if err := p.mergeBlocks(); err != nil {
+			return nil, fmt.Errorf("%s: %v", p.FileName, err)
+		}
+		profiles = append(profiles, p)
+	}
+	sort.Sort(byFileName(profiles))
+	return profiles, nil
+}
+
+// Convert returns copies of the profiles in the given mode,
+// which is "set", "count", or "atomic".
+//
+// Converting to "set" mode reduces each nonzero count to 1.
+// Converting from "set" mode keeps the counts of 0 and 1, which are
+// then a lower bound of the number of times the blocks were executed.
+// The "count" and "atomic" modes differ only in how the counts were
+// collected, so converting between them keeps the counts.
+func Convert(profiles []*Profile, mode string) ([]*Profile, error) {
+	switch mode {
+	case "set", "count", "atomic":
+	default:
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+	converted := make([]*Profile, len(profiles))
+	for i, p := range profiles {
+		c := &Profile{FileName: p.FileName, Mode: mode, Blocks: make([]ProfileBlock, len(p.Blocks))}
+		copy(c.Blocks, p.Blocks)
+		if mode == "set" {
+			for j := range c.Blocks {
+				c.Blocks[j].Count = min(c.Blocks[j].Count, 1)
+			}
+		}
+		converted[i] = c
+	}
+	return converted, nil
+}
+
+// NormalizeFileNames returns copies of the profiles whose file names
+// are rewritten to be the same on every machine, so that profiles
+// recorded on different machines, such as absolute file names in
+// different working directories, may be merged.
+//
+// Each file name is converted to use slashes. Then, if it is within
+// one of the directories that are the keys of dirs, the longest such
+// directory is replaced by the corresponding value, as in
+// {"/home/ci/src/m": "example.com/m"}; an empty value leaves the name
+// relative to the directory. The keys of dirs may use either slashes
+// or backslashes. The profiles of files whose rewritten names are the
+// same are merged, as by Merge.
+func NormalizeFileNames(profiles []*Profile, dirs map[string]string) ([]*Profile, error) {
+	type rewrite struct{ dir, repl string }
+	var rewrites []rewrite
+	for dir, repl := range dirs {
+		dir = strings.TrimSuffix(strings.ReplaceAll(dir, `\`, "/"), "/")
+		rewrites = append(rewrites, rewrite{dir, strings.TrimSuffix(repl, "/")})
+	}
+	sort.Slice(rewrites, func(i, j int) bool { return len(rewrites[i].dir) > len(rewrites[j].dir) })
+
+	normalized := make([]*Profile, len(profiles))
+	for i, p := range profiles {
+		name := strings.ReplaceAll(p.FileName, `\`, "/")
+		for _, r := range rewrites {
+			if rest, ok := strings.CutPrefix(name, r.dir+"/"); ok {
+				if r.repl != "" {
+					rest = r.repl + "/" + rest
+				}
+				name = rest
+				break
+			}
+		}
+		normalized[i] = &Profile{FileName: name, Mode: p.Mode, Blocks: p.Blocks}
+	}
+	return Merge(normalized)
+}
+
+// WriteProfiles writes the profiles, which must all be in the same
+// mode, to w in the format read by ParseProfilesFromReader.
+// It writes nothing if there are no profiles.
+func WriteProfiles(w io.Writer, profiles []*Profile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	bw := bufio.NewWriter(w)
+	mode := profiles[0].Mode
+	fmt.Fprintf(bw, "mode: %s\n", mode)
+	for _, p := range profiles {
+		if p.Mode != mode {
+			return fmt.Errorf("cannot write profiles in modes %q and %q", mode, p.Mode)
+		}
+		for _, b := range p.Blocks {
+			fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n", p.FileName,
+				b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// parse parses the profile text, failing the test on error.
+func parse(t *testing.T, text string) []*Profile {
+	t.Helper()
+	profiles, err := ParseProfilesFromReader(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profiles
+}
+
+// format returns the profiles in the format of a profile file.
+func format(t *testing.T, profiles []*Profile) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteProfiles(&buf, profiles); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestMerge(t *testing.T) {
+	shard1 := parse(t, `mode: count
+m/a.go:1.1,2.2 1 3
+m/a.go:3.1,4.2 2 0
+m/b.go:1.1,2.2 1 1
+`)
+	shard2 := parse(t, `mode: count
+m/a.go:3.1,4.2 2 5
+m/a.go:5.1,6.2 1 1
+m/c.go:1.1,2.2 1 0
+`)
+	merged, err := Merge(shard1, shard2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `mode: count
+m/a.go:1.1,2.2 1 3
+m/a.go:3.1,4.2 2 5
+m/a.go:5.1,6.2 1 1
+m/b.go:1.1,2.2 1 1
+m/c.go:1.1,2.2 1 0
+`
+	if got := format(t, merged); got != want {
+		t.Errorf("Merge:\n%s\nwant:\n%s", got, want)
+	}
+	if got := format(t, shard1); !strings.Contains(got, "m/a.go:3.1,4.2 2 0") {
+		t.Errorf("Merge modified its input:\n%s", got)
+	}
+
+	set1, _ := Convert(shard1, "set")
+	set2, _ := Convert(shard2, "set")
+	merged, err = Merge(set1, set2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `mode: set
+m/a.go:1.1,2.2 1 1
+m/a.go:3.1,4.2 2 1
+m/a.go:5.1,6.2 1 1
+m/b.go:1.1,2.2 1 1
+m/c.go:1.1,2.2 1 0
+`
+	if got := format(t, merged); got != want {
+		t.Errorf("Merge in set mode:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := Merge(shard1, set2); err == nil {
+		t.Errorf("Merge of profiles in different modes succeeded, want an error")
+	}
+	bad := parse(t, "mode: count\nm/a.go:1.1,2.2 4 1\n")
+	if _, err := Merge(shard1, bad); err == nil {
+		t.Errorf("Merge of inconsistent blocks succeeded, want an error")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	profiles := parse(t, "mode: atomic\nm/a.go:1.1,2.2 1 7\nm/a.go:3.1,4.2 1 0\n")
+	for _, test := range []struct {
+		mode, want string
+	}{
+		{"count", "mode: count\nm/a.go:1.1,2.2 1 7\nm/a.go:3.1,4.2 1 0\n"},
+		{"set", "mode: set\nm/a.go:1.1,2.2 1 1\nm/a.go:3.1,4.2 1 0\n"},
+	} {
+		converted, err := Convert(profiles, test.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := format(t, converted); got != test.want {
+			t.Errorf("Convert(%s):\n%s\nwant:\n%s", test.mode, got, test.want)
+		}
+	}
+	if got := profiles[0].Blocks[0].Count; got != 7 {
+		t.Errorf("Convert modified its input: count = %d", got)
+	}
+	if _, err := Convert(profiles, "bogus"); err == nil {
+		t.Errorf("Convert to an unknown mode succeeded, want an error")
+	}
+}
+
+func TestNormalizeFileNames(t *testing.T) {
+	linux := parse(t, "mode: set\n/home/ci/src/m/a.go:1.1,2.2 1 1\n/home/ci/src/m/sub/b.go:1.1,2.2 1 0\n")
+	windows := parse(t, "mode: set\nC:\\work\\m\\sub\\b.go:1.1,2.2 1 1\nC:\\work\\other\\c.go:1.1,2.2 1 1\n")
+	profiles, err := Merge(linux, windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized, err := NormalizeFileNames(profiles, map[string]string{
+		"/home/ci/src/m":     "example.com/m",
+		"/home/ci/src/m/sub": "example.com/m/sub",
+		`C:\work\m\`:         "example.com/m",
+		`C:\work`:            "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `mode: set
+example.com/m/a.go:1.1,2.2 1 1
+example.com/m/sub/b.go:1.1,2.2 1 1
+other/c.go:1.1,2.2 1 1
+`
+	if got := format(t, normalized); got != want {
+		t.Errorf("NormalizeFileNames:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteProfiles(t *testing.T) {
+	const text = "mode: count\nm/a.go:1.1,2.2 1 3\nm/b.go:3.4,5.6 7 8\n"
+	if got := format(t, parse(t, text)); got != text {
+		t.Errorf("WriteProfiles after ParseProfilesFromReader:\n%s\nwant:\n%s", got, text)
+	}
+	if got := format(t, nil); got != "" {
+		t.Errorf("WriteProfiles(nil) = %q, want empty", got)
+	}
+}
@@ -2,8 +2,10 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package cover provides support for parsing coverage profiles
-// generated by "go test -coverprofile=cover.out".
+// Package cover provides support for parsing, merging, writing, and
+// summarizing coverage profiles generated by "go test -coverprofile=cover.out",
+// and for reading and writing the coverage data directories written by
+// programs built with "go build -cover".
 package cover // import "golang.org/x/tools/cover"
 
 import (
@@ -87,30 +89,9 @@ func ParseProfilesFromReader(rd io.Reader) ([]*Profile, error) {
 		return nil, err
 	}
 	for _, p := range files {
-		sort.Sort(blocksByStart(p.Blocks))
-		// Merge samples from the same location.
-		j := 1
-		for i := 1; i < len(p.Blocks); i++ {
-			b := p.Blocks[i]
-			last := p.Blocks[j-1]
-			if b.StartLine == last.StartLine &&
-				b.StartCol == last.StartCol &&
-				b.EndLine == last.EndLine &&
-				b.EndCol == last.EndCol {
-				if b.NumStmt != last.NumStmt {
-					return nil, fmt.Errorf("inconsistent NumStmt: changed from %d to %d", last.NumStmt, b.NumStmt)
-				}
-				if mode == "set" {
-					p.Blocks[j-1].Count |= b.Count
-				} else {
-					p.Blocks[j-1].Count += b.Count
-				}
-				continue
-			}
-			p.Blocks[j] = b
-			j++
+		if err := p.mergeBlocks(); err != nil {
+			return nil, err
 		}
-		p.Blocks = p.Blocks[:j]
 	}
 	// Generate a sorted slice.
 	profiles := make([]*Profile, 0, len(files))
@@ -121,6 +102,37 @@ func ParseProfilesFromReader(rd io.Reader) ([]*Profile, error) {
 	return profiles, nil
 }
 
+// mergeBlocks sorts the blocks of p and merges
+// the samples of blocks from the same location.
+func (p *Profile) mergeBlocks() error {
+	sort.Sort(blocksByStart(p.Blocks))
+	j := 1
+	for i := 1; i < len(p.Blocks); i++ {
+		b := p.Blocks[i]
+		last := p.Blocks[j-1]
+		if b.StartLine == last.StartLine &&
+			b.StartCol == last.StartCol &&
+			b.EndLine == last.EndLine &&
+			b.EndCol == last.EndCol {
+			if b.NumStmt != last.NumStmt {
+				return fmt.Errorf("inconsistent NumStmt: changed from %d to %d", last.NumStmt, b.NumStmt)
+			}
+			if p.Mode == "set" {
+				p.Blocks[j-1].Count |= b.Count
+			} else {
+				p.Blocks[j-1].Count += b.Count
+			}
+			continue
+		}
+		p.Blocks[j] = b
+		j++
+	}
+	if len(p.Blocks) > 0 {
+		p.Blocks = p.Blocks[:j]
+	}
+	return nil
+}
+
 // parseLine parses a line from a coverage file.
 // It is equivalent to the regex
 // ^(.+):([0-9]+)\.([0-9]+),([0-9]+)\.([0-9]+) ([0-9]+) ([0-9]+)$
@@ -188,7 +200,12 @@ func (b blocksByStart) Len() int      { return len(b) }
 func (b blocksByStart) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
 func (b blocksByStart) Less(i, j int) bool {
 	bi, bj := b[i], b[j]
-	return bi.StartLine < bj.StartLine || bi.StartLine == bj.StartLine && bi.StartCol < bj.StartCol
+	if bi.StartLine != bj.StartLine || bi.StartCol != bj.StartCol {
+		return bi.StartLine < bj.StartLine || bi.StartLine == bj.StartLine && bi.StartCol < bj.StartCol
+	}
+	// Order blocks with the same start by their end,
+	// so that blocks from the same location are adjacent.
+	return bi.EndLine < bj.EndLine || bi.EndLine == bj.EndLine && bi.EndCol < bj.EndCol
 }
 
 // Boundary represents the position in a source file of the beginning or end of a
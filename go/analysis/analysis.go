@@ -33,6 +33,15 @@ type Analyzer struct {
 	// depends on the driver which runs the analyzer.
 	Flags flag.FlagSet
 
+	// Config, if non-nil, is a pointer to a variable, typically of
+	// struct type, holding the default values of the analyzer's
+	// structured settings. A driver that accepts settings for the
+	// analyzer, in the form of a JSON object, decodes them using
+	// [encoding/json] into a copy of this variable and provides
+	// the copy to each pass as [Pass.Config]. Fields absent from
+	// the settings retain their default values.
+	Config any
+
 	// Run applies the analyzer to a package.
 	// It returns an error if the analyzer failed.
 	//
@@ -102,6 +111,12 @@ type Pass struct {
 
 	Module *Module // the package's enclosing module (possibly nil in some drivers)
 
+	// Config holds the settings of the analyzer: a pointer of the
+	// same type as Analyzer.Config, which must not be modified.
+	// Drivers that do not support settings may set it to nil, in
+	// which case the analyzer should use its defaults.
+	Config any
+
 	// Report reports a Diagnostic, a finding about a specific location
 	// in the analyzed source code such as a potential mistake.
 	// It may be called by the Run function.
@@ -7,6 +7,7 @@ package analysistest
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"go/format"
 	"go/token"
@@ -128,17 +129,57 @@ type Testing interface {
 // the computed diffs do not overlap. If that fails, break the test
 // into smaller parts.
 //
+// # Updating golden files
+//
+// When the test is run with the -analysistest.update flag, golden files
+// are rewritten to match the fixed source instead of being compared
+// against it. The sections of a txtar golden file keep their order, and
+// sections for new fix messages are appended. A missing golden file is
+// created, as plain Go source if all fixes to the file share a message,
+// or as a txtar archive otherwise.
+//
+// To test one alternative fix at a time, use [RunWithSuggestedFix].
+//
 // TODO(adonovan): the behavior of RunWithSuggestedFixes as documented
 // above is impractical for tests that report multiple diagnostics and
 // offer multiple alternative fixes for the same diagnostic, and it is
 // inconsistent with the interpretation of multiple diagnostics
 // described at Diagnostic.SuggestedFixes.
 // We need to rethink the analyzer testing API to better support such
-// cases. In the meantime, users of RunWithSuggestedFixes testing
-// analyzers that offer alternative fixes are advised to put each fix
-// in a separate .go file in the testdata.
+// cases. In the meantime, users testing analyzers that offer
+// alternative fixes are advised to use RunWithSuggestedFix to select
+// each alternative in turn, or to put each fix in a separate .go file
+// in the testdata.
 func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns ...string) []*Result {
+	return runWithSuggestedFixes(t, dir, a, "", patterns...)
+}
+
+// RunWithSuggestedFix behaves like RunWithSuggestedFixes, but applies
+// only the suggested fixes whose message is title, ignoring all
+// others. This allows each of several alternative fixes offered for
+// the same diagnostics to be tested in isolation.
+//
+// The fixed source of each file is compared against the section named
+// title of its txtar golden file, or, if the golden file is plain Go
+// source, against the entire file. It is an error if the analyzer
+// offers no fix with the specified message.
+func RunWithSuggestedFix(t Testing, dir string, a *analysis.Analyzer, title string, patterns ...string) []*Result {
+	if title == "" {
+		t.Errorf("RunWithSuggestedFix: empty fix title")
+		return nil
+	}
+	return runWithSuggestedFixes(t, dir, a, title, patterns...)
+}
+
+// update, if set, causes the golden files of RunWithSuggestedFixes
+// to be rewritten instead of compared.
+var update = flag.Bool("analysistest.update", false, "update golden files of RunWithSuggestedFixes to match the suggested fixes")
+
+// runWithSuggestedFixes implements RunWithSuggestedFixes, applying
+// only fixes with the specified message, if nonempty.
+func runWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, title string, patterns ...string) []*Result {
 	results := Run(t, dir, a, patterns...)
+	selected := false // whether any fix was selected by title
 
 	// If the immediate caller of RunWithSuggestedFixes is in
 	// x/tools, we apply stricter checks as required by gopls.
@@ -218,6 +259,15 @@ func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns
 		}
 
 		for file, fixes := range fileEdits {
+			if title != "" {
+				edits, ok := fixes[title]
+				if !ok {
+					continue
+				}
+				selected = true
+				fixes = map[string][]diff.Edit{title: edits}
+			}
+
 			// Get the original file contents.
 			orig, ok := fileContents[file]
 			if !ok {
@@ -226,10 +276,21 @@ func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns
 			}
 
 			// Get the golden file and read the contents.
-			ar, err := txtar.ParseFile(file.Name() + ".golden")
+			goldenFile := file.Name() + ".golden"
+			ar, err := txtar.ParseFile(goldenFile)
 			if err != nil {
-				t.Errorf("error reading %s.golden: %v", file.Name(), err)
-				continue
+				if !(*update && os.IsNotExist(err)) {
+					t.Errorf("error reading %s: %v", goldenFile, err)
+					continue
+				}
+				// Create a new golden file.
+				ar = new(txtar.Archive)
+				if len(fixes) > 1 {
+					for sf := range fixes {
+						ar.Files = append(ar.Files, txtar.File{Name: sf})
+					}
+					sort.Slice(ar.Files, func(i, j int) bool { return ar.Files[i].Name < ar.Files[j].Name })
+				}
 			}
 
 			if len(ar.Files) > 0 {
@@ -239,13 +300,19 @@ func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns
 					// we allow either just the comment, or just virtual
 					// files, not both. it is not clear how "both" should
 					// behave.
-					t.Errorf("%s.golden has leading comment; we don't know what to do with it", file.Name())
+					t.Errorf("%s has leading comment; we don't know what to do with it", goldenFile)
 					continue
 				}
 
-				for sf, edits := range fixes {
+				sfs := make([]string, 0, len(fixes))
+				for sf := range fixes {
+					sfs = append(sfs, sf)
+				}
+				sort.Strings(sfs) // for determinism of updates
+				for _, sf := range sfs {
+					edits := fixes[sf]
 					found := false
-					for _, vf := range ar.Files {
+					for i, vf := range ar.Files {
 						if vf.Name == sf {
 							found = true
 							// the file may contain multiple trailing
@@ -254,14 +321,29 @@ func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns
 							// this to a single newline.
 							golden := append(bytes.TrimRight(vf.Data, "\n"), '\n')
 
-							if err := applyDiffsAndCompare(orig, golden, edits, file.Name()); err != nil {
+							if *update {
+								if out, err := applyDiffs(orig, edits, file.Name()); err != nil {
+									t.Errorf("%s", err)
+								} else {
+									ar.Files[i].Data = out
+								}
+							} else if err := applyDiffsAndCompare(orig, golden, edits, file.Name()); err != nil {
 								t.Errorf("%s", err)
 							}
 							break
 						}
 					}
 					if !found {
-						t.Errorf("no section for suggested fix %q in %s.golden", sf, file.Name())
+						if *update {
+							out, err := applyDiffs(orig, edits, file.Name())
+							if err != nil {
+								t.Errorf("%s", err)
+								continue
+							}
+							ar.Files = append(ar.Files, txtar.File{Name: sf, Data: out})
+						} else {
+							t.Errorf("no section for suggested fix %q in %s", sf, goldenFile)
+						}
 					}
 				}
 			} else {
@@ -272,31 +354,56 @@ func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns
 					catchallEdits = append(catchallEdits, edits...)
 				}
 
-				if err := applyDiffsAndCompare(orig, ar.Comment, catchallEdits, file.Name()); err != nil {
+				if *update {
+					if out, err := applyDiffs(orig, catchallEdits, file.Name()); err != nil {
+						t.Errorf("%s", err)
+					} else {
+						ar.Comment = out
+					}
+				} else if err := applyDiffsAndCompare(orig, ar.Comment, catchallEdits, file.Name()); err != nil {
 					t.Errorf("%s", err)
 				}
 			}
+
+			if *update {
+				if err := os.WriteFile(goldenFile, txtar.Format(ar), 0666); err != nil {
+					t.Errorf("updating golden file: %v", err)
+				}
+			}
 		}
 	}
+	if title != "" && !selected {
+		t.Errorf("no suggested fix with message %q", title)
+	}
 	return results
 }
 
-// applyDiffsAndCompare applies edits to src and compares the results against
-// golden after formatting both. fileName is use solely for error reporting.
-func applyDiffsAndCompare(src, golden []byte, edits []diff.Edit, fileName string) error {
+// applyDiffs applies edits to src and returns the formatted result.
+// fileName is used solely for error reporting.
+func applyDiffs(src []byte, edits []diff.Edit, fileName string) ([]byte, error) {
 	out, err := diff.ApplyBytes(src, edits)
 	if err != nil {
-		return fmt.Errorf("%s: error applying fixes: %v (see possible explanations at RunWithSuggestedFixes)", fileName, err)
+		return nil, fmt.Errorf("%s: error applying fixes: %v (see possible explanations at RunWithSuggestedFixes)", fileName, err)
 	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error formatting resulting source: %v\n%s", fileName, err, out)
+	}
+	return formatted, nil
+}
+
+// applyDiffsAndCompare applies edits to src and compares the results against
+// golden after formatting both. fileName is use solely for error reporting.
+func applyDiffsAndCompare(src, golden []byte, edits []diff.Edit, fileName string) error {
 	wantRaw, err := format.Source(golden)
 	if err != nil {
-		return fmt.Errorf("%s.golden: error formatting golden file: %v\n%s", fileName, err, out)
+		return fmt.Errorf("%s.golden: error formatting golden file: %v", fileName, err)
 	}
 	want := string(wantRaw)
 
-	formatted, err := format.Source(out)
+	formatted, err := applyDiffs(src, edits, fileName)
 	if err != nil {
-		return fmt.Errorf("%s: error formatting resulting source: %v\n%s", fileName, err, out)
+		return err
 	}
 	if got := string(formatted); got != want {
 		unified := diff.Unified(fileName+".golden", "actual", want, got)
@@ -5,10 +5,12 @@
 package analysistest_test
 
 import (
+	"flag"
 	"fmt"
 	"go/token"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -204,6 +206,86 @@ func F() {} // want "say hello"`,
 	analysistest.RunWithSuggestedFixes(t, dir, noend, "a")
 }
 
+// TestAlternativeFixes tests the testing and updating of
+// alternative fixes for the same diagnostic.
+func TestAlternativeFixes(t *testing.T) {
+	alt := &analysis.Analyzer{
+		Name: "alt",
+		Doc:  "offers to insert a comment, or to delete the first decl",
+		Run: func(pass *analysis.Pass) (any, error) {
+			decl := pass.Files[0].Decls[0]
+			pass.Report(analysis.Diagnostic{
+				Pos:     decl.Pos(),
+				Message: "decl",
+				SuggestedFixes: []analysis.SuggestedFix{
+					{
+						Message:   "comment",
+						TextEdits: []analysis.TextEdit{{Pos: decl.Pos(), NewText: []byte("// F\n")}},
+					},
+					{
+						Message:   "delete",
+						TextEdits: []analysis.TextEdit{{Pos: decl.Pos(), End: decl.End()}},
+					},
+				},
+			})
+			return nil, nil
+		},
+	}
+
+	const src = `package a
+
+func F() {} // want "decl"
+`
+	filemap := map[string]string{
+		"a/a.go": src,
+		"a/a.go.golden": `-- comment --
+package a
+
+// F
+func F() {} // want "decl"
+-- delete --
+package a
+
+// want "decl"
+`,
+	}
+	dir, cleanup, err := analysistest.WriteFiles(filemap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	analysistest.RunWithSuggestedFixes(t, dir, alt, "a")
+	analysistest.RunWithSuggestedFix(t, dir, alt, "comment", "a")
+	analysistest.RunWithSuggestedFix(t, dir, alt, "delete", "a")
+
+	// A fix that is not offered is an error.
+	var errs []string
+	t2 := errorfunc(func(s string) { errs = append(errs, s) })
+	analysistest.RunWithSuggestedFix(t2, dir, alt, "nonesuch", "a")
+	if want := `no suggested fix with message "nonesuch"`; len(errs) != 1 || errs[0] != want {
+		t.Errorf("got errors %q, want %q", errs, want)
+	}
+
+	// With -analysistest.update, a missing golden file is created.
+	golden := filepath.Join(dir, "src/a/a.go.golden")
+	if err := os.Remove(golden); err != nil {
+		t.Fatal(err)
+	}
+	if err := flag.Set("analysistest.update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer flag.Set("analysistest.update", "false")
+	analysistest.RunWithSuggestedFixes(t, dir, alt, "a")
+	got, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filemap["a/a.go.golden"]; string(got) != want {
+		t.Errorf("updated golden file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestModule(t *testing.T) {
 	const content = `
 Test that analysis.pass.Module is populated.
@@ -0,0 +1,483 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines the optional on-disk cache of action outputs.
+//
+// Each action (a, p) is identified by a key that is a hash of
+// everything that could affect its outputs: the identity of the
+// driver executable (which determines the analyzer implementations),
+// the analyzer's name and flag values, the content of p's files and,
+// transitively, of the files of all p's dependencies, and the keys of
+// the action's prerequisite actions.
+//
+// A cache entry records the diagnostics reported by the action and
+// the facts that it exported about its own package. The in-memory
+// Result of an action is not serializable, so an action whose result
+// is needed by another action that must run is always executed.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// A cacheKey is a hash of all the inputs to an action.
+type cacheKey [sha256.Size]byte
+
+func (k cacheKey) String() string { return hex.EncodeToString(k[:]) }
+
+// A cache is a directory of action outputs, keyed by cacheKey.
+type cache struct {
+	dir string
+
+	mu        sync.Mutex
+	pkgHashes map[*packages.Package]cacheKey
+}
+
+func newCache(dir string, analyzers []*analysis.Analyzer) *cache {
+	// Facts are stored in interface-typed fields,
+	// so their concrete types must be registered.
+	forEachAnalyzer(analyzers, func(a *analysis.Analyzer) {
+		for _, f := range a.FactTypes {
+			gob.Register(f)
+		}
+	})
+	return &cache{dir: dir, pkgHashes: make(map[*packages.Package]cacheKey)}
+}
+
+// forEachAnalyzer calls f for each analyzer in the transitive
+// closure of the Requires graph, in unspecified order.
+func forEachAnalyzer(analyzers []*analysis.Analyzer, f func(*analysis.Analyzer)) {
+	seen := make(map[*analysis.Analyzer]bool)
+	var visit func([]*analysis.Analyzer)
+	visit = func(analyzers []*analysis.Analyzer) {
+		for _, a := range analyzers {
+			if !seen[a] {
+				seen[a] = true
+				f(a)
+				visit(a.Requires)
+			}
+		}
+	}
+	visit(analyzers)
+}
+
+// executableHash returns a hash of the running executable, so that
+// a rebuilt driver (with possibly changed analyzers) never sees
+// stale entries.
+var executableHash = sync.OnceValues(func() (cacheKey, error) {
+	var key cacheKey
+	exe, err := os.Executable()
+	if err != nil {
+		return key, err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return key, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", runtime.Version())
+	if _, err := io.Copy(h, f); err != nil {
+		return key, err
+	}
+	h.Sum(key[:0])
+	return key, nil
+})
+
+// packageHash returns a hash of the files of pkg and of all its
+// dependencies.
+func (c *cache) packageHash(pkg *packages.Package) (cacheKey, error) {
+	c.mu.Lock()
+	key, ok := c.pkgHashes[pkg]
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "id %s\npath %s\n", pkg.ID, pkg.PkgPath)
+	for _, files := range [][]string{pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles, pkg.EmbedFiles} {
+		for _, filename := range files {
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return key, err
+			}
+			fmt.Fprintf(h, "file %s %d\n", filename, len(data))
+			h.Write(data)
+		}
+	}
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		dep, err := c.packageHash(pkg.Imports[path])
+		if err != nil {
+			return key, err
+		}
+		fmt.Fprintf(h, "import %s %s\n", path, dep)
+	}
+	h.Sum(key[:0])
+
+	c.mu.Lock()
+	c.pkgHashes[pkg] = key
+	c.mu.Unlock()
+	return key, nil
+}
+
+// actionKey computes the key of act, whose dependencies
+// must already have keys.
+func (c *cache) actionKey(act *Action) (cacheKey, error) {
+	var key cacheKey
+	exe, err := executableHash()
+	if err != nil {
+		return key, err
+	}
+	pkg, err := c.packageHash(act.Package)
+	if err != nil {
+		return key, err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "exe %s\nanalyzer %s\npackage %s\n", exe, act.Analyzer.Name, pkg)
+	act.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(h, "flag %s=%s\n", f.Name, f.Value)
+	})
+	if act.config != nil {
+		config, err := json.Marshal(act.config)
+		if err != nil {
+			return key, err
+		}
+		fmt.Fprintf(h, "config %s\n", config)
+	}
+	for _, dep := range act.Deps {
+		if !dep.cacheable {
+			return key, fmt.Errorf("prerequisite %s is not cacheable", dep)
+		}
+		fmt.Fprintf(h, "dep %s %s\n", dep, dep.key)
+	}
+	h.Sum(key[:0])
+	return key, nil
+}
+
+func (c *cache) filename(key cacheKey) string {
+	s := key.String()
+	return filepath.Join(c.dir, s[:2], s)
+}
+
+// get returns the entry for key, or nil if there is none.
+func (c *cache) get(key cacheKey) *cacheEntry {
+	data, err := os.ReadFile(c.filename(key))
+	if err != nil {
+		return nil
+	}
+	entry := new(cacheEntry)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(entry); err != nil {
+		return nil // corrupt or stale format: treat as a miss
+	}
+	return entry
+}
+
+// put stores the entry for key. Errors are ignored: the cache is
+// only an optimization.
+func (c *cache) put(key cacheKey, entry *cacheEntry) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return
+	}
+	filename := c.filename(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+		return
+	}
+	// Write to a temporary file and rename it
+	// so that readers never observe a partial entry.
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// A cacheEntry is the serialized output of one action.
+type cacheEntry struct {
+	Diagnostics []cachedDiagnostic
+	Facts       []cachedFact
+}
+
+// A cachedPos is a token.Pos expressed relative to a named file.
+type cachedPos struct {
+	File   string
+	Offset int
+}
+
+type cachedDiagnostic struct {
+	Pos, End       cachedPos
+	Category       string
+	Message        string
+	URL            string
+	SuggestedFixes []cachedFix
+	Related        []cachedRelated
+}
+
+type cachedFix struct {
+	Message   string
+	TextEdits []cachedEdit
+}
+
+type cachedEdit struct {
+	Pos, End cachedPos
+	NewText  []byte
+}
+
+type cachedRelated struct {
+	Pos, End cachedPos
+	Message  string
+}
+
+// A cachedFact is a fact about the action's own package
+// or one of its objects (if Object is nonempty).
+type cachedFact struct {
+	Object objectpath.Path
+	Fact   analysis.Fact
+}
+
+// encodeEntry returns the cache entry for the outputs of act,
+// or an error if they cannot be faithfully serialized.
+func encodeEntry(act *Action) (*cacheEntry, error) {
+	fset := act.Package.Fset
+	files := syntaxFiles(act.Package)
+
+	pos := func(p token.Pos) (cachedPos, error) {
+		if !p.IsValid() {
+			return cachedPos{}, nil
+		}
+		f := fset.File(p)
+		if f == nil || files[f.Name()] != f {
+			return cachedPos{}, fmt.Errorf("position %v is not in a syntax file of the package", fset.Position(p))
+		}
+		return cachedPos{File: f.Name(), Offset: f.Offset(p)}, nil
+	}
+
+	entry := new(cacheEntry)
+	for _, d := range act.Diagnostics {
+		var (
+			cd  cachedDiagnostic
+			err error
+		)
+		if cd.Pos, err = pos(d.Pos); err != nil {
+			return nil, err
+		}
+		if cd.End, err = pos(d.End); err != nil {
+			return nil, err
+		}
+		cd.Category, cd.Message, cd.URL = d.Category, d.Message, d.URL
+		for _, sf := range d.SuggestedFixes {
+			cf := cachedFix{Message: sf.Message}
+			for _, edit := range sf.TextEdits {
+				var ce cachedEdit
+				if ce.Pos, err = pos(edit.Pos); err != nil {
+					return nil, err
+				}
+				if ce.End, err = pos(edit.End); err != nil {
+					return nil, err
+				}
+				ce.NewText = edit.NewText
+				cf.TextEdits = append(cf.TextEdits, ce)
+			}
+			cd.SuggestedFixes = append(cd.SuggestedFixes, cf)
+		}
+		for _, rel := range d.Related {
+			var cr cachedRelated
+			if cr.Pos, err = pos(rel.Pos); err != nil {
+				return nil, err
+			}
+			if cr.End, err = pos(rel.End); err != nil {
+				return nil, err
+			}
+			cr.Message = rel.Message
+			cd.Related = append(cd.Related, cr)
+		}
+		entry.Diagnostics = append(entry.Diagnostics, cd)
+	}
+
+	// Record only the facts exported by this action;
+	// those of dependencies are inherited afresh on a hit.
+	encoder := new(objectpath.Encoder)
+	for k, fact := range act.objectFacts {
+		if k.obj.Pkg() != act.Package.Types {
+			continue
+		}
+		path, err := encoder.For(k.obj)
+		if err != nil {
+			return nil, fmt.Errorf("fact about %v: %v", k.obj, err)
+		}
+		entry.Facts = append(entry.Facts, cachedFact{Object: path, Fact: fact})
+	}
+	for k, fact := range act.packageFacts {
+		if k.pkg == act.Package.Types {
+			entry.Facts = append(entry.Facts, cachedFact{Fact: fact})
+		}
+	}
+	return entry, nil
+}
+
+// restore populates the outputs of act from the cache entry.
+// The facts inherited from dependencies must already be present.
+func (entry *cacheEntry) restore(act *Action) error {
+	files := syntaxFiles(act.Package)
+
+	pos := func(p cachedPos) (token.Pos, error) {
+		if p.File == "" {
+			return token.NoPos, nil
+		}
+		f := files[p.File]
+		if f == nil || p.Offset > f.Size() {
+			return token.NoPos, fmt.Errorf("invalid position %s:#%d", p.File, p.Offset)
+		}
+		return f.Pos(p.Offset), nil
+	}
+
+	var diags []analysis.Diagnostic
+	for _, cd := range entry.Diagnostics {
+		var (
+			d   analysis.Diagnostic
+			err error
+		)
+		if d.Pos, err = pos(cd.Pos); err != nil {
+			return err
+		}
+		if d.End, err = pos(cd.End); err != nil {
+			return err
+		}
+		d.Category, d.Message, d.URL = cd.Category, cd.Message, cd.URL
+		for _, cf := range cd.SuggestedFixes {
+			sf := analysis.SuggestedFix{Message: cf.Message}
+			for _, ce := range cf.TextEdits {
+				var edit analysis.TextEdit
+				if edit.Pos, err = pos(ce.Pos); err != nil {
+					return err
+				}
+				if edit.End, err = pos(ce.End); err != nil {
+					return err
+				}
+				edit.NewText = ce.NewText
+				sf.TextEdits = append(sf.TextEdits, edit)
+			}
+			d.SuggestedFixes = append(d.SuggestedFixes, sf)
+		}
+		for _, cr := range cd.Related {
+			var rel analysis.RelatedInformation
+			if rel.Pos, err = pos(cr.Pos); err != nil {
+				return err
+			}
+			if rel.End, err = pos(cr.End); err != nil {
+				return err
+			}
+			rel.Message = cr.Message
+			d.Related = append(d.Related, rel)
+		}
+		diags = append(diags, d)
+	}
+
+	pkg := act.Package.Types
+	for _, cf := range entry.Facts {
+		t := reflect.TypeOf(cf.Fact)
+		if cf.Object == "" {
+			act.packageFacts[packageFactKey{pkg, t}] = cf.Fact
+			continue
+		}
+		obj, err := objectpath.Object(pkg, cf.Object)
+		if err != nil {
+			return err
+		}
+		act.objectFacts[objectFactKey{obj, t}] = cf.Fact
+	}
+	act.Diagnostics = diags
+	return nil
+}
+
+// syntaxFiles returns the token.Files of the package's
+// parsed Go files, keyed by name.
+func syntaxFiles(pkg *packages.Package) map[string]*token.File {
+	files := make(map[string]*token.File, len(pkg.Syntax))
+	for _, f := range pkg.Syntax {
+		if tf := pkg.Fset.File(f.FileStart); tf != nil {
+			files[tf.Name()] = tf
+		}
+	}
+	return files
+}
+
+// prepareCache computes the cache key of each action in the graph
+// and determines which actions may be satisfied from the cache.
+func prepareCache(c *cache, roots []*Action) {
+	forEach(roots, func(act *Action) error {
+		if act.Package.IllTyped {
+			return nil // don't cache results derived from bad input
+		}
+		key, err := c.actionKey(act)
+		if err != nil {
+			return nil // not cacheable
+		}
+		act.key, act.cacheable = key, true
+		if act.cached == nil {
+			act.cached = c.get(key)
+		}
+		return nil
+	})
+}
+
+// markMustRun ensures that each action whose outputs would be restored
+// (see prepareCache and reuse) is nonetheless executed if its Result
+// is needed by an action that is executed.
+func markMustRun(roots []*Action) {
+	var postorder []*Action
+	forEach(roots, func(act *Action) error {
+		postorder = append(postorder, act)
+		return nil
+	})
+
+	// Visit dependents before dependencies.
+	for i := len(postorder) - 1; i >= 0; i-- {
+		act := postorder[i]
+		if act.cached != nil && !act.mustRun {
+			continue
+		}
+		act.cached = nil
+		for _, dep := range act.Deps {
+			if dep.Package == act.Package {
+				dep.mustRun = true // horizontal edge: Result needed
+			}
+		}
+	}
+}
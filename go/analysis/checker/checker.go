@@ -31,6 +31,7 @@ package checker
 import (
 	"bytes"
 	"encoding/gob"
+	"encoding/json"
 	"fmt"
 	"go/types"
 	"io"
@@ -55,6 +56,19 @@ type Options struct {
 	SanityCheck bool      // check fact encoding is ok and deterministic
 	FactLog     io.Writer // if non-nil, log each exported fact to it
 
+	// CacheDir, if nonempty, is a directory in which the diagnostics
+	// and facts of each action are saved, keyed by a hash of its
+	// inputs, so that later runs over unchanged packages need not
+	// repeat the analysis. The Result of an action satisfied from
+	// the cache is nil.
+	CacheDir string
+
+	// Config holds the settings of each analyzer, keyed by analyzer
+	// name, as JSON objects to be decoded into a copy of its
+	// [analysis.Analyzer.Config] variable. Settings for analyzers
+	// that are not run are ignored.
+	Config map[string]json.RawMessage
+
 	// TODO(adonovan): add ReadFile so that an Overlay specified
 	// in the [packages.Config] can be communicated via
 	// Pass.ReadFile to each Analyzer.
@@ -128,11 +142,19 @@ type Action struct {
 	Duration    time.Duration // execution time of this step
 
 	opts         *Options
+	config       any // value of Pass.Config
 	once         sync.Once
 	pass         *analysis.Pass
 	objectFacts  map[objectFactKey]analysis.Fact
 	packageFacts map[packageFactKey]analysis.Fact
 	inputs       map[*analysis.Analyzer]any
+
+	// Cache state (see cache.go).
+	cache     *cache
+	key       cacheKey
+	cacheable bool        // key is valid
+	cached    *cacheEntry // non-nil => restore outputs instead of running
+	mustRun   bool        // Result is needed by another action
 }
 
 func (act *Action) String() string {
@@ -151,6 +173,31 @@ func (act *Action) String() string {
 //
 // If opts is nil, it is equivalent to new(Options).
 func Analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Options) (*Graph, error) {
+	return analyze(analyzers, pkgs, opts, nil, nil)
+}
+
+// Reanalyze is like Analyze, but it is intended for repeated analysis
+// of a changing set of packages, as by an interactive tool. It reuses
+// the diagnostics and facts of prev, the result of an earlier call to
+// Analyze or Reanalyze with the same analyzers and options, for each
+// action whose package is neither among the changed packages nor
+// depends on one of them, so that only the affected actions are run.
+//
+// The packages pkgs and their dependencies are typically the result
+// of loading the same patterns again after the change; the changed
+// packages are identified by their [packages.Package.ID]. As with the
+// on-disk cache (see [Options.CacheDir]), the Result of a reused
+// action is nil, unless its Result is needed by an action that must
+// run, in which case it is run again.
+func Reanalyze(prev *Graph, changed []string, analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Options) (*Graph, error) {
+	changedIDs := make(map[string]bool, len(changed))
+	for _, id := range changed {
+		changedIDs[id] = true
+	}
+	return analyze(analyzers, pkgs, opts, prev, changedIDs)
+}
+
+func analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Options, prev *Graph, changed map[string]bool) (*Graph, error) {
 	if opts == nil {
 		opts = new(Options)
 	}
@@ -159,6 +206,28 @@ func Analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Opt
 		return nil, err
 	}
 
+	// Decode the settings of each analyzer.
+	configs := make(map[*analysis.Analyzer]any)
+	var decodeConfigs func([]*analysis.Analyzer) error
+	decodeConfigs = func(analyzers []*analysis.Analyzer) error {
+		for _, a := range analyzers {
+			if _, ok := configs[a]; !ok {
+				config, err := analysisinternal.MakeConfig(a, opts.Config[a.Name])
+				if err != nil {
+					return err
+				}
+				configs[a] = config
+				if err := decodeConfigs(a.Requires); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := decodeConfigs(analyzers); err != nil {
+		return nil, err
+	}
+
 	// Construct the action graph.
 	//
 	// Each graph node (action) is one unit of analysis.
@@ -175,7 +244,7 @@ func Analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Opt
 		k := key{a, pkg}
 		act, ok := actions[k]
 		if !ok {
-			act = &Action{Analyzer: a, Package: pkg, opts: opts}
+			act = &Action{Analyzer: a, Package: pkg, opts: opts, config: configs[a]}
 
 			// Add a dependency on each required analyzers.
 			for _, req := range a.Requires {
@@ -211,6 +280,20 @@ func Analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Opt
 		}
 	}
 
+	if prev != nil {
+		reuse(prev, changed, roots)
+	}
+	if opts.CacheDir != "" {
+		c := newCache(opts.CacheDir, analyzers)
+		for _, act := range actions {
+			act.cache = c
+		}
+		prepareCache(c, roots)
+	}
+	if prev != nil || opts.CacheDir != "" {
+		markMustRun(roots)
+	}
+
 	// Execute the graph in parallel.
 	execAll(roots)
 
@@ -307,6 +390,16 @@ func (act *Action) execOnce() {
 		}
 	}
 
+	// Restore the outputs from the cache, if possible.
+	if act.cached != nil {
+		err := act.cached.restore(act)
+		act.cached = nil
+		if err == nil {
+			return
+		}
+		act.Diagnostics = nil // corrupt entry: run the analysis
+	}
+
 	// Quick (nonexhaustive) check that the correct go/packages mode bits were used.
 	// (If there were errors, all bets are off.)
 	if pkg := act.Package; pkg.Errors == nil {
@@ -334,6 +427,7 @@ func (act *Action) execOnce() {
 		TypesSizes:   act.Package.TypesSizes,
 		TypeErrors:   act.Package.TypeErrors,
 		Module:       module,
+		Config:       act.config,
 
 		ResultOf:          inputs,
 		Report:            func(d analysis.Diagnostic) { act.Diagnostics = append(act.Diagnostics, d) },
@@ -378,6 +472,12 @@ func (act *Action) execOnce() {
 	// Help detect (disallowed) calls after Run.
 	pass.ExportObjectFact = nil
 	pass.ExportPackageFact = nil
+
+	if act.cache != nil && act.cacheable && act.Err == nil {
+		if entry, err := encodeEntry(act); err == nil {
+			act.cache.put(act.key, entry)
+		}
+	}
 }
 
 // inheritFacts populates act.facts with
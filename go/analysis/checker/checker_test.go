@@ -0,0 +1,150 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// badFact marks a function whose name begins with "Bad".
+type badFact struct{}
+
+func (*badFact) AFact()         {}
+func (*badFact) String() string { return "bad" }
+
+// TestReanalyze checks that Reanalyze runs only the actions affected
+// by a change, and reuses the facts and diagnostics of the others.
+func TestReanalyze(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	files := map[string]string{
+		"a/a.go": `package a
+
+func BadFunc() {}
+`,
+		"b/b.go": `package b
+
+import "a"
+
+func f() { a.BadFunc() }
+`,
+		"c/c.go": `package c
+
+import "a"
+
+func f() { a.BadFunc() }
+`,
+	}
+	testdata, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var (
+		mu   sync.Mutex
+		runs []string // packages analyzed
+	)
+	bad := &analysis.Analyzer{
+		Name:      "bad",
+		Doc:       "reports calls to functions named Bad*",
+		FactTypes: []analysis.Fact{new(badFact)},
+		Run: func(pass *analysis.Pass) (any, error) {
+			mu.Lock()
+			runs = append(runs, pass.Pkg.Path())
+			mu.Unlock()
+			for _, f := range pass.Files {
+				ast.Inspect(f, func(n ast.Node) bool {
+					switch n := n.(type) {
+					case *ast.FuncDecl:
+						if strings.HasPrefix(n.Name.Name, "Bad") {
+							pass.ExportObjectFact(pass.TypesInfo.Defs[n.Name], new(badFact))
+						}
+					case *ast.CallExpr:
+						if fn, ok := typeutil.Callee(pass.TypesInfo, n).(*types.Func); ok && pass.ImportObjectFact(fn, new(badFact)) {
+							pass.Reportf(n.Pos(), "call of bad function %s", fn.Name())
+						}
+					}
+					return true
+				})
+			}
+			return nil, nil
+		},
+	}
+	analyzers := []*analysis.Analyzer{bad}
+
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  testdata,
+		Env:  append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off", "GOPROXY=off"),
+	}
+	load := func() []*packages.Package {
+		pkgs, err := packages.Load(cfg, "b", "c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkgs
+	}
+	// check asserts the packages analyzed since the last call,
+	// and the diagnostics of the graph.
+	check := func(graph *checker.Graph, wantRuns string, wantDiags int) {
+		t.Helper()
+		got := runs
+		sort.Strings(got)
+		if strings.Join(got, " ") != wantRuns {
+			t.Errorf("analyzed packages %q, want %q", got, wantRuns)
+		}
+		runs = nil
+		ndiags := 0
+		for _, root := range graph.Roots {
+			if root.Err != nil {
+				t.Errorf("%s: %v", root, root.Err)
+			}
+			ndiags += len(root.Diagnostics)
+		}
+		if ndiags != wantDiags {
+			t.Errorf("got %d diagnostics, want %d", ndiags, wantDiags)
+		}
+	}
+
+	graph, err := checker.Analyze(analyzers, load(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(graph, "a b c", 2)
+
+	// After a change to c, only c is reanalyzed;
+	// b's diagnostic and a's fact are reused.
+	cfile := filepath.Join(testdata, "src/c/c.go")
+	if err := os.WriteFile(cfile, []byte(files["c/c.go"]+"\nfunc g() { a.BadFunc() }\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	graph, err = checker.Reanalyze(graph, []string{"c"}, analyzers, load(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(graph, "c", 3)
+
+	// After a change to a, all are reanalyzed.
+	graph, err = checker.Reanalyze(graph, []string{"a"}, analyzers, load(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(graph, "a b c", 3)
+}
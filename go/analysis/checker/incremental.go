@@ -0,0 +1,67 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines the reuse of a previous graph's outputs by Reanalyze.
+//
+// The outputs of an action of the previous graph are transferred to
+// the corresponding action of the new graph (the one that applies the
+// same analyzer to the package of the same ID) through the same
+// representation as the on-disk cache: positions are expressed
+// relative to file names, and the objects of facts by their
+// objectpath, so that they may be bound to the newly loaded packages.
+
+import (
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// reuse arranges for each action of the graph whose package is not
+// affected by the changed packages to restore the outputs of the
+// corresponding action of prev instead of running.
+func reuse(prev *Graph, changed map[string]bool, roots []*Action) {
+	type key struct {
+		a  *analysis.Analyzer
+		id string
+	}
+	prevActions := make(map[key]*Action)
+	prev.All()(func(act *Action) bool {
+		prevActions[key{act.Analyzer, act.Package.ID}] = act
+		return true
+	})
+
+	// affected reports whether pkg is changed or depends on a changed package.
+	memo := make(map[*packages.Package]bool)
+	var affected func(pkg *packages.Package) bool
+	affected = func(pkg *packages.Package) bool {
+		res, ok := memo[pkg]
+		if !ok {
+			memo[pkg] = false // break cycles
+			res = changed[pkg.ID]
+			for _, imp := range pkg.Imports {
+				if res {
+					break
+				}
+				res = affected(imp)
+			}
+			memo[pkg] = res
+		}
+		return res
+	}
+
+	forEach(roots, func(act *Action) error {
+		if act.Package.IllTyped || affected(act.Package) {
+			return nil
+		}
+		old := prevActions[key{act.Analyzer, act.Package.ID}]
+		if old == nil || old.Err != nil || old.Package.IllTyped || old.objectFacts == nil {
+			return nil // not previously run successfully
+		}
+		if entry, err := encodeEntry(old); err == nil {
+			act.cached = entry
+		}
+		return nil
+	})
+}
@@ -78,6 +78,7 @@ The [Analyzer] type has more fields besides those shown above:
 		Name             string
 		Doc              string
 		Flags            flag.FlagSet
+		Config           any
 		Run              func(*Pass) (interface{}, error)
 		RunDespiteErrors bool
 		ResultType       reflect.Type
@@ -95,6 +96,25 @@ ambiguity. An IDE might expose the flags through a graphical interface,
 and a batch pipeline might configure them from a config file.
 See the "findcall" analyzer for an example of flags in action.
 
+The Config field holds a pointer to a variable, usually a struct,
+containing the default values of the analyzer's structured settings.
+Drivers accept the settings of all analyzers as a single JSON object
+whose fields are keyed by analyzer name, for example:
+
+	{"printf": {"wrappers": [{"func": "example.com/log.Infof", "kind": "printf"}]}}
+
+The driver decodes the settings of each analyzer into a copy of its
+Config variable and makes it available to the analysis as Pass.Config.
+The command-line drivers read this object from the file named by the
+-settings flag, and gopls from its "analysisConfig" setting, so that
+the same settings apply however the analyzer is run.
+
+When an analysis tool is run by "go vet -vettool", the go command
+caches the results of each package under a key that includes the
+command-line flags, and thus the name of the -settings file, but not
+its content. After editing the file, either rename it or clear the
+cache with "go clean -cache", or vet may report stale results.
+
 The RunDespiteErrors flag indicates whether the analysis is equipped to
 handle ill-typed code. If not, the driver will skip the analysis if
 there were parse or type errors.
@@ -143,6 +163,12 @@ configuration but may be part of other build configurations.
 The contents of these files may be read using Pass.ReadFile;
 see the "asmdecl" or "buildtags" analyzers for examples of loading
 non-Go files and reporting diagnostics against them.
+For analyzers that need only their structure, the AsmFuncs method
+reports the functions defined by the TEXT directives of the assembly
+files, with their frame and argument sizes, and the EmbedDirectives
+method reports the //go:embed directives of the Go files, with their
+patterns resolved to the embedded files. (The patterns are resolved
+against the file system directly, not through Pass.ReadFile.)
 
 The ResultOf field provides the results computed by the analyzers
 required by this one, as expressed in its Analyzer.Requires field. The
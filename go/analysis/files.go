@@ -0,0 +1,337 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+// This file defines parsed views of the non-Go inputs of a package:
+// the functions defined in its assembly files, and the files named
+// by its //go:embed directives.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// An AsmFunc describes a function defined by a TEXT directive in one
+// of the assembly files of a package.
+type AsmFunc struct {
+	File string // name of the assembly file (an element of Pass.OtherFiles)
+	Line int    // line number of the TEXT directive
+
+	// Pkg is the path of the package qualifying the symbol,
+	// such as "runtime" in "runtime·memmove", or "" for
+	// the package being analyzed.
+	Pkg string
+
+	Name      string // name of the function, without ABI selector
+	FrameSize int64  // size of the stack frame; 0 if omitted
+	ArgSize   int64  // size of the arguments and results, or -1 if omitted
+}
+
+// asmTEXT matches a TEXT directive; see also asmdecl.
+var asmTEXT = regexp.MustCompile(`\bTEXT\b(.*)·([^\(]+)\(SB\)(?:\s*,\s*([0-9A-Z|+()]+))?(?:\s*,\s*\$(-?[0-9]+)(?:-([0-9]+))?)?`)
+
+// AsmFuncs returns the functions defined by the assembly (.s) files
+// among the OtherFiles of the package, in file and line order.
+// It reads the files using ReadFile, if provided.
+func (pass *Pass) AsmFuncs() ([]AsmFunc, error) {
+	var funcs []AsmFunc
+	for _, filename := range pass.OtherFiles {
+		if !strings.HasSuffix(filename, ".s") {
+			continue
+		}
+		content, err := pass.readFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		sc := bufio.NewScanner(bytes.NewReader(content))
+		for line := 1; sc.Scan(); line++ {
+			text := sc.Text()
+			if i := strings.Index(text, "//"); i >= 0 {
+				text = text[:i] // ignore comments
+			}
+			m := asmTEXT.FindStringSubmatch(text)
+			if m == nil {
+				continue
+			}
+			fn := AsmFunc{
+				File: filename,
+				Line: line,
+				// The assembler uses Unicode division slash
+				// within identifiers for the path separator.
+				Pkg:     strings.ReplaceAll(strings.TrimSpace(m[1]), "∕", "/"),
+				Name:    m[2],
+				ArgSize: -1,
+			}
+			if i := strings.Index(fn.Name, "<"); i >= 0 {
+				fn.Name = fn.Name[:i] // e.g. "F<ABIInternal>"
+			}
+			if m[4] != "" {
+				fn.FrameSize, _ = strconv.ParseInt(m[4], 10, 64)
+			}
+			if m[5] != "" {
+				fn.ArgSize, _ = strconv.ParseInt(m[5], 10, 64)
+			}
+			funcs = append(funcs, fn)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+	}
+	return funcs, nil
+}
+
+// readFile reads a file using ReadFile, if provided.
+func (pass *Pass) readFile(filename string) ([]byte, error) {
+	if pass.ReadFile != nil {
+		return pass.ReadFile(filename)
+	}
+	return os.ReadFile(filename)
+}
+
+// An EmbedDirective is a //go:embed directive in one of the Go files
+// of a package.
+type EmbedDirective struct {
+	Pos      token.Pos // position of the directive's comment
+	Patterns []string  // patterns, unquoted
+	Files    []string  // sorted names of the files matched by the patterns
+}
+
+// EmbedDirectives returns the //go:embed directives of the Go files of
+// the package, in order, with their patterns resolved to files
+// following the rules of the go command: a pattern that matches a
+// directory matches all the files in its tree, except those of nested
+// modules and those whose names begin with '.' or '_', unless the
+// pattern has the prefix "all:".
+//
+// Unlike the other parts of the Pass, the files are found by reading
+// the directories of the package from the file system as it is at the
+// time of the call, not through ReadFile, which cannot list directories.
+// The result therefore ignores any virtual file tree provided by the
+// driver, such as unsaved editor buffers. Also, a driver that caches
+// the results of analyzers does not know that they depend on these
+// directories, and may report stale results after the directories change.
+//
+// It returns an error for a malformed directive, or for a pattern
+// that is invalid, matches no files, or names a file of another module.
+func (pass *Pass) EmbedDirectives() ([]EmbedDirective, error) {
+	var directives []EmbedDirective
+	for _, f := range pass.Files {
+		dir := filepath.Dir(pass.Fset.File(f.FileStart).Name())
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				args, ok := strings.CutPrefix(c.Text, "//go:embed")
+				if !ok || args != "" && !unicode.IsSpace(rune(args[0])) {
+					continue
+				}
+				posn := pass.Fset.Position(c.Pos())
+				patterns, err := parseEmbedPatterns(args)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v", posn, err)
+				}
+				files, err := resolveEmbed(dir, patterns)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v", posn, err)
+				}
+				directives = append(directives, EmbedDirective{
+					Pos:      c.Pos(),
+					Patterns: patterns,
+					Files:    files,
+				})
+			}
+		}
+	}
+	return directives, nil
+}
+
+// parseEmbedPatterns parses the space-separated, optionally quoted
+// patterns of a //go:embed directive.
+func parseEmbedPatterns(args string) ([]string, error) {
+	var patterns []string
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		var pattern string
+		switch args[0] {
+		case '"', '`':
+			quote := args[0]
+			i := 1
+			for ; i < len(args) && args[i] != quote; i++ {
+				if quote == '"' && args[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(args) {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+			var err error
+			pattern, err = strconv.Unquote(args[:i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args[:i+1])
+			}
+			args = args[i+1:]
+			if args != "" && !unicode.IsSpace(rune(args[0])) {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+		default:
+			i := strings.IndexFunc(args, unicode.IsSpace)
+			if i < 0 {
+				i = len(args)
+			}
+			pattern, args = args[:i], args[i:]
+		}
+		patterns = append(patterns, pattern)
+	}
+	if patterns == nil {
+		return nil, fmt.Errorf("no patterns in //go:embed")
+	}
+	return patterns, nil
+}
+
+// resolveEmbed returns the sorted names of the files matched by the
+// embed patterns, interpreted relative to dir, following the rules of
+// cmd/go: files of other modules, that is, in subdirectories of dir
+// containing a go.mod file, are excluded, as are files whose names could
+// not be packaged into a module.
+func resolveEmbed(dir string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pattern := range patterns {
+		count, err := resolveEmbedPattern(dir, pattern, func(filename string) {
+			if !seen[filename] {
+				seen[filename] = true
+				files = append(files, filename)
+			}
+		})
+		if err == nil && count == 0 {
+			err = fmt.Errorf("no matching files found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %v", pattern, err)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// resolveEmbedPattern calls add for each file matched by the embed
+// pattern, interpreted relative to dir, and returns their number,
+// including those also matched by other patterns.
+func resolveEmbedPattern(dir, pattern string, add func(filename string)) (int, error) {
+	glob, all := strings.CutPrefix(pattern, "all:")
+	if _, err := path.Match(glob, ""); err != nil || !fs.ValidPath(glob) || glob == "." {
+		return 0, fmt.Errorf("invalid pattern syntax")
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(glob)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pattern syntax")
+	}
+	count := 0
+	for _, match := range matches {
+		rel := filepath.ToSlash(match[len(dir)+1:])
+		info, err := os.Lstat(match)
+		if err != nil {
+			return 0, err
+		}
+		what := "file"
+		if info.IsDir() {
+			what = "directory"
+		}
+
+		// The directories along the path must not
+		// begin another module or have bad names.
+		for d := match; len(d) > len(dir)+1; d = filepath.Dir(d) {
+			if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+				return 0, fmt.Errorf("cannot embed %s %s: in different module", what, rel)
+			}
+			if elem := filepath.Base(d); badEmbedName(elem) {
+				if d == match {
+					return 0, fmt.Errorf("cannot embed %s %s: invalid name %s", what, rel, elem)
+				}
+				return 0, fmt.Errorf("cannot embed %s %s: in invalid directory %s", what, rel, elem)
+			}
+		}
+
+		switch {
+		case info.Mode().IsRegular():
+			add(match)
+			count++
+
+		case info.IsDir():
+			// Gather the files of the tree, stopping at module
+			// boundaries and skipping names that could not be
+			// packaged into a module, or are hidden.
+			n := 0
+			err := filepath.WalkDir(match, func(filename string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				name := d.Name()
+				if filename != match && (badEmbedName(name) || !all && (name[0] == '.' || name[0] == '_')) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() {
+					if _, err := os.Stat(filepath.Join(filename, "go.mod")); err == nil {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.Type().IsRegular() {
+					add(filename)
+					n++
+				}
+				return nil
+			})
+			if err != nil {
+				return 0, err
+			}
+			if n == 0 {
+				return 0, fmt.Errorf("cannot embed directory %s: contains no embeddable files", rel)
+			}
+			count += n
+
+		default:
+			return 0, fmt.Errorf("cannot embed irregular file %s", rel)
+		}
+	}
+	return count, nil
+}
+
+// badEmbedName reports whether a file or directory of the given name
+// cannot be embedded, either because the name is not a valid element
+// of a file path in a module (see golang.org/x/mod/module.CheckFilePath)
+// or because it holds the metadata of a version control system.
+func badEmbedName(name string) bool {
+	switch name {
+	case "", ".", "..", ".bzr", ".hg", ".git", ".svn":
+		return true
+	}
+	if name[len(name)-1] == '.' {
+		return true
+	}
+	for _, r := range name {
+		if r < utf8.RuneSelf {
+			if !('0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' ||
+				strings.ContainsRune("!#$%&()+,-.=@[]^_{}~ ", r)) {
+				return true
+			}
+		} else if !unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
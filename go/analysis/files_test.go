@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestFiles checks the parsed views of assembly files and embed
+// directives provided by Pass.AsmFuncs and Pass.EmbedDirectives.
+func TestFiles(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	files := map[string]string{
+		"a/a.go": `package a // want "asm a.s:5 Add frame=0 args=24" "asm a.s:8 Sum frame=16 args=-1" "asm a.s:10 runtime.memmove frame=0 args=-1"
+
+import "embed"
+
+//go:embed hello.txt
+var hello string // want "embed hello.txt: hello.txt"
+
+//go:embed static "with space.txt"
+var static embed.FS // want "embed static,with space.txt: static/sub/y.txt static/x.txt with space.txt"
+
+//go:embed all:static
+var all embed.FS // want "embed all:static: static/.hidden static/_under static/sub/.hidden static/sub/y.txt static/x.txt"
+
+//go:embed static hello.txt static/x.txt
+var overlap embed.FS // want "embed static,hello.txt,static/x.txt: hello.txt static/sub/y.txt static/x.txt"
+
+func Add(x, y int64) int64
+
+func Sum(xs []int64) int64
+`,
+		"a/a.s": `#include "textflag.h"
+
+// TEXT ·Commented(SB),NOSPLIT,$0-0
+
+TEXT ·Add(SB),NOSPLIT,$0-24
+	RET
+
+TEXT ·Sum<ABIInternal>(SB),NOSPLIT,$16
+	RET
+TEXT runtime·memmove(SB),NOSPLIT,$0
+	RET
+`,
+		"a/hello.txt":          "hello",
+		"a/with space.txt":     "",
+		"a/static/x.txt":       "",
+		"a/static/.hidden":     "",
+		"a/static/_under":      "",
+		"a/static/sub/y.txt":   "",
+		"a/static/sub/.hidden": "",
+		"a/static/mod/go.mod":  "module example.com/mod\n",
+		"a/static/mod/z.txt":   "",
+	}
+	dir, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	a := &analysis.Analyzer{
+		Name: "files",
+		Doc:  "report assembly functions and embed directives",
+		Run: func(pass *analysis.Pass) (any, error) {
+			funcs, err := pass.AsmFuncs()
+			if err != nil {
+				return nil, err
+			}
+			for _, fn := range funcs {
+				name := fn.Name
+				if fn.Pkg != "" {
+					name = fn.Pkg + "." + name
+				}
+				pass.Reportf(pass.Files[0].Package, "asm %s:%d %s frame=%d args=%d",
+					filepath.Base(fn.File), fn.Line, name, fn.FrameSize, fn.ArgSize)
+			}
+
+			directives, err := pass.EmbedDirectives()
+			if err != nil {
+				return nil, err
+			}
+			pkgdir := filepath.Join(dir, "src/a")
+			for _, d := range directives {
+				var rel []string
+				for _, f := range d.Files {
+					r, _ := filepath.Rel(pkgdir, f)
+					rel = append(rel, filepath.ToSlash(r))
+				}
+				// Report on the following line, as the
+				// directive's line cannot hold a comment.
+				tf := pass.Fset.File(d.Pos)
+				pos := tf.LineStart(tf.Line(d.Pos) + 1)
+				pass.Reportf(pos, "embed %s: %s", strings.Join(d.Patterns, ","), strings.Join(rel, " "))
+			}
+			return nil, nil
+		},
+	}
+	analysistest.Run(t, dir, a, "a")
+}
@@ -20,12 +20,17 @@ import (
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/internal/analysisinternal"
 )
 
 // flags common to all {single,multi,unit}checkers.
 var (
 	JSON    = false // -json
 	Context = -1    // -c=N: if N>0, display offending line plus N lines of context
+
+	// Config holds the analyzer settings loaded from the -settings
+	// file, keyed by analyzer name.
+	Config map[string]json.RawMessage
 )
 
 // Parse creates a flag for each of the analyzer's flags,
@@ -74,6 +79,10 @@ func Parse(analyzers []*analysis.Analyzer, multi bool) []*analysis.Analyzer {
 	// flags common to all checkers
 	flag.BoolVar(&JSON, "json", JSON, "emit JSON output")
 	flag.IntVar(&Context, "c", Context, `display offending line with this many lines of context`)
+	var settingsFile string
+	if flag.Lookup("settings") == nil { // (an analyzer flag of a singlechecker may take the name)
+		flag.StringVar(&settingsFile, "settings", "", "JSON file of analyzer settings, keyed by analyzer name")
+	}
 
 	// Add shims for legacy vet flags to enable existing
 	// scripts that run vet to continue to work.
@@ -96,6 +105,22 @@ func Parse(analyzers []*analysis.Analyzer, multi bool) []*analysis.Analyzer {
 		os.Exit(0)
 	}
 
+	// -settings: load the settings of the analyzers.
+	//
+	// The go vet cache key includes this flag but not the content
+	// of the file, which the go command does not know to hash.
+	// The limitation is documented in the analysis package.
+	if settingsFile != "" {
+		data, err := os.ReadFile(settingsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		Config, err = analysisinternal.ParseConfig(data)
+		if err != nil {
+			log.Fatalf("%s: %v", settingsFile, err)
+		}
+	}
+
 	everything := expand(analyzers)
 
 	// If any -NAME flag is true,  run only those analyzers. Otherwise,
@@ -170,7 +195,7 @@ func printFlags() {
 		// flags or fix as these have no effect on unitchecker
 		// (as invoked by 'go vet').
 		switch f.Name {
-		case "debug", "cpuprofile", "memprofile", "trace", "fix":
+		case "debug", "cpuprofile", "memprofile", "trace", "fix", "cache", "changes", "since":
 			return
 		}
 
@@ -17,6 +17,15 @@ import (
 )
 
 func main() {
+	if os.Getenv("SINGLE") == "1" {
+		// An analyzer of a singlechecker whose flags have the
+		// names of driver flags.
+		a := &analysis.Analyzer{Name: "a", Doc: "a"}
+		a.Flags.String("config", "", "config file")
+		a.Flags.String("settings", "", "settings file")
+		fmt.Println(analysisflags.Parse([]*analysis.Analyzer{a}, false))
+		os.Exit(0)
+	}
 	fmt.Println(analysisflags.Parse([]*analysis.Analyzer{
 		{Name: "a1", Doc: "a1"},
 		{Name: "a2", Doc: "a2"},
@@ -41,19 +50,24 @@ func TestExec(t *testing.T) {
 	}
 
 	for _, test := range []struct {
-		flags string
-		want  string // output should contain want
+		single bool
+		flags  string
+		want   string // output should contain want
 	}{
-		{"", "[a1 a2 a3]"},
-		{"-a1=0", "[a2 a3]"},
-		{"-a1=1", "[a1]"},
-		{"-a1", "[a1]"},
-		{"-a1=1 -a3=1", "[a1 a3]"},
-		{"-a1=1 -a3=0", "[a1]"},
-		{"-V=full", "analysisflags.test version devel"},
+		{false, "", "[a1 a2 a3]"},
+		{false, "-a1=0", "[a2 a3]"},
+		{false, "-a1=1", "[a1]"},
+		{false, "-a1", "[a1]"},
+		{false, "-a1=1 -a3=1", "[a1 a3]"},
+		{false, "-a1=1 -a3=0", "[a1]"},
+		{false, "-V=full", "analysisflags.test version devel"},
+		{true, "-config=x -settings=y", "[a]"},
 	} {
 		cmd := exec.Command(progname, "-test.run=TestExec")
 		cmd.Env = append(os.Environ(), "ANALYSISFLAGS_CHILD=1", "FLAGS="+test.flags)
+		if test.single {
+			cmd.Env = append(cmd.Env, "SINGLE=1")
+		}
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
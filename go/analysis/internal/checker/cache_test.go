@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/internal/checker"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// badFact marks a function whose name begins with "Bad".
+type badFact struct{}
+
+func (*badFact) AFact()         {}
+func (*badFact) String() string { return "bad" }
+
+// TestCache checks that a second run with the same -cache directory
+// reuses the facts and diagnostics of unchanged packages.
+func TestCache(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	files := map[string]string{
+		"a/a.go": `package a
+
+func BadFunc() {}
+`,
+		"b/b.go": `package b
+
+import "a"
+
+func f() { a.BadFunc() }
+`,
+	}
+	testdata, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	t.Setenv("GOPATH", testdata)
+	t.Setenv("GO111MODULE", "off")
+
+	var runs atomic.Int32
+	bad := &analysis.Analyzer{
+		Name:      "bad",
+		Doc:       "reports calls to functions named Bad*",
+		Requires:  []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes: []analysis.Fact{new(badFact)},
+		Run: func(pass *analysis.Pass) (any, error) {
+			runs.Add(1)
+			for _, f := range pass.Files {
+				for _, decl := range f.Decls {
+					if decl, ok := decl.(*ast.FuncDecl); ok && strings.HasPrefix(decl.Name.Name, "Bad") {
+						pass.ExportObjectFact(pass.TypesInfo.Defs[decl.Name], new(badFact))
+					}
+				}
+			}
+			inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+			inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+				call := n.(*ast.CallExpr)
+				if fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok && pass.ImportObjectFact(fn, new(badFact)) {
+					pass.Reportf(call.Pos(), "call of bad function %s", fn.Name())
+				}
+			})
+			return nil, nil
+		},
+	}
+
+	defer func(fix bool, dir string) { checker.Fix, checker.CacheDir = fix, dir }(checker.Fix, checker.CacheDir)
+	checker.Fix = false
+	checker.CacheDir = t.TempDir()
+
+	pattern := filepath.Join(testdata, "src/b")
+	run := func() (code int, nruns int32) {
+		runs.Store(0)
+		code = checker.Run([]string{pattern}, []*analysis.Analyzer{bad})
+		return code, runs.Load()
+	}
+
+	// The first run analyzes a and b.
+	if code, n := run(); code != 3 || n != 2 {
+		t.Errorf("first run: exit code %d, %d runs; want 3, 2", code, n)
+	}
+
+	// The second run is satisfied entirely from the cache,
+	// including the diagnostic in b and the fact about a.BadFunc.
+	if code, n := run(); code != 3 || n != 0 {
+		t.Errorf("second run: exit code %d, %d runs; want 3, 0", code, n)
+	}
+
+	// After a change to b, only b is reanalyzed.
+	bfile := filepath.Join(testdata, "src/b/b.go")
+	if err := os.WriteFile(bfile, []byte(files["b/b.go"]+"\nfunc g() { a.BadFunc() }\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if code, n := run(); code != 3 || n != 1 {
+		t.Errorf("run after change: exit code %d, %d runs; want 3, 1", code, n)
+	}
+}
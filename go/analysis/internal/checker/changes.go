@@ -0,0 +1,251 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// A changeSet records the lines of each file that were added or
+// modified by a change, keyed by absolute file name.
+// A line adjacent to a deletion counts as modified.
+type changeSet map[string]map[int]bool
+
+// loadChanges returns the set of changed lines described by the
+// -changes and -since flags, or nil if neither is set.
+func loadChanges() (changeSet, error) {
+	switch {
+	case Changes != "" && Since != "":
+		return nil, fmt.Errorf("-changes and -since are mutually exclusive")
+
+	case Changes != "":
+		var (
+			data []byte
+			err  error
+		)
+		if Changes == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(Changes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Paths in the patch are relative to the current directory.
+		dir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return parseUnifiedDiff(dir, data)
+
+	case Since != "":
+		// Paths in the diff are relative to the repository root.
+		root, err := gitOutput("rev-parse", "--show-toplevel")
+		if err != nil {
+			return nil, err
+		}
+		data, err := gitOutput("diff", "--no-color", "--no-ext-diff", "--unified=0", Since, "--")
+		if err != nil {
+			return nil, err
+		}
+		return parseUnifiedDiff(strings.TrimSpace(string(root)), data)
+	}
+	return nil, nil
+}
+
+// gitOutput runs git in the current directory and returns its output.
+func gitOutput(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+// parseUnifiedDiff returns the set of lines of the new version of
+// each file that are changed by the unified diff. File names in the
+// diff are interpreted relative to dir, after removing the "b/" prefix
+// used by git.
+func parseUnifiedDiff(dir string, data []byte) (changeSet, error) {
+	changes := make(changeSet)
+	var (
+		lines   map[int]bool // changed lines of current file, or nil if deleted
+		line    int          // number of the next line of the new file
+		left    int          // remaining lines of the new file in current hunk
+		oldLeft int          // remaining lines of the old file in current hunk
+	)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<25)
+	for lineno := 1; sc.Scan(); lineno++ {
+		text := sc.Text()
+		switch {
+		case left == 0 && oldLeft == 0 && strings.HasPrefix(text, "+++ "):
+			// A file header, which cannot appear within a hunk,
+			// where it may be an added line beginning "++ ".
+			name := strings.TrimPrefix(text, "+++ ")
+			if i := strings.IndexByte(name, '\t'); i >= 0 {
+				name = name[:i] // discard timestamp
+			}
+			if name == "/dev/null" {
+				lines = nil
+				continue
+			}
+			if unq, err := strconv.Unquote(name); err == nil {
+				name = unq // git quotes unusual file names
+			}
+			name = strings.TrimPrefix(name, "b/")
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(dir, filepath.FromSlash(name))
+			}
+			name = filepath.Clean(name)
+			lines = changes[name]
+			if lines == nil {
+				lines = make(map[int]bool)
+				changes[name] = lines
+			}
+			left, oldLeft = 0, 0
+
+		case strings.HasPrefix(text, "@@ "):
+			// @@ -l,s +l,s @@ optional section heading
+			fields := strings.Fields(text)
+			if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("line %d: malformed hunk header %q", lineno, text)
+			}
+			_, oldCount, err := parseRange(fields[1][1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: malformed hunk header %q: %v", lineno, text, err)
+			}
+			start, count, err := parseRange(fields[2][1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: malformed hunk header %q: %v", lineno, text, err)
+			}
+			line, left, oldLeft = start, count, oldCount
+			if count == 0 {
+				// Pure deletion after line start.
+				line++
+				if lines != nil {
+					lines[start] = true
+					lines[line] = true
+				}
+			}
+
+		case left == 0 && oldLeft == 0:
+			// Not within a hunk.
+
+		case strings.HasPrefix(text, "+"):
+			if lines != nil {
+				lines[line] = true
+			}
+			line++
+			left--
+
+		case strings.HasPrefix(text, "-"):
+			if lines != nil {
+				lines[line] = true // the line that replaced it, if any
+			}
+			oldLeft--
+
+		case strings.HasPrefix(text, " "), text == "":
+			line++
+			left--
+			oldLeft--
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// parseRange parses a hunk range of the form "start[,count]".
+func parseRange(s string) (start, count int, err error) {
+	count = 1
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		if count, err = strconv.Atoi(s[i+1:]); err != nil {
+			return 0, 0, err
+		}
+		s = s[:i]
+	}
+	if start, err = strconv.Atoi(s); err != nil {
+		return 0, 0, err
+	}
+	return start, count, nil
+}
+
+// filter discards from each root action the diagnostics that do not
+// touch any changed line. A diagnostic touches a line if its own
+// range, the range of any of its related information, or any edit
+// of its suggested fixes includes it, so that findings reported
+// elsewhere as a consequence of a change (for example, at the callers
+// of a changed function) are retained when the analyzer relates them
+// to the change.
+func (changes changeSet) filter(graph *checker.Graph) {
+	for _, act := range graph.Roots {
+		fset := act.Package.Fset
+		var keep []analysis.Diagnostic
+		for _, diag := range act.Diagnostics {
+			if changes.touches(fset, diag) {
+				keep = append(keep, diag)
+			}
+		}
+		act.Diagnostics = keep
+	}
+}
+
+func (changes changeSet) touches(fset *token.FileSet, diag analysis.Diagnostic) bool {
+	if changes.intersects(fset, diag.Pos, diag.End) {
+		return true
+	}
+	for _, rel := range diag.Related {
+		if changes.intersects(fset, rel.Pos, rel.End) {
+			return true
+		}
+	}
+	for _, fix := range diag.SuggestedFixes {
+		for _, edit := range fix.TextEdits {
+			if changes.intersects(fset, edit.Pos, edit.End) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// intersects reports whether the range [pos, end] includes a changed line.
+func (changes changeSet) intersects(fset *token.FileSet, pos, end token.Pos) bool {
+	if !pos.IsValid() {
+		return false
+	}
+	start := fset.Position(pos)
+	lines := changes[filepath.Clean(start.Filename)]
+	if lines == nil {
+		return false
+	}
+	last := start.Line
+	if end.IsValid() && end > pos {
+		last = fset.Position(end).Line
+	}
+	for line := start.Line; line <= last; line++ {
+		if lines[line] {
+			return true
+		}
+	}
+	return false
+}
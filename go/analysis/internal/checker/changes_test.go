@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/internal/checker"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestChanges checks that the -changes flag restricts fixes to the
+// diagnostics on changed lines.
+func TestChanges(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	files := map[string]string{
+		"rename/test.go": `package rename
+
+func Old() {
+	bar := 1
+	_ = bar
+}
+
+/*
+++ not a file header
+*/
+
+func New() {
+	bar := 2
+	_ = bar
+}
+`,
+		// The patch that added a comment and New, and later
+		// replaced the first statement of New.
+		"rename.patch": `--- a/rename/test.go
++++ b/rename/test.go
+@@ -6,0 +7,9 @@
++
++/*
++++ not a file header
++*/
++
++func New() {
++	bar := 2
++	_ = bar
++}
+--- a/rename/test.go
++++ b/rename/test.go
+@@ -13 +13 @@
+-	bar := 3
++	bar := 2
+`,
+	}
+	want := `package rename
+
+func Old() {
+	bar := 1
+	_ = bar
+}
+
+/*
+++ not a file header
+*/
+
+func New() {
+	baz := 2
+	_ = baz
+}
+`
+
+	testdata, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// The patch is relative to the current directory.
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(testdata, "src")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	defer func(fix bool) { checker.Fix, checker.Changes = fix, "" }(checker.Fix)
+	checker.Fix = true
+	checker.Changes = filepath.Join(testdata, "src/rename.patch")
+	path := filepath.Join(testdata, "src/rename/test.go")
+	if code := checker.Run([]string{"file=" + path}, []*analysis.Analyzer{renameAnalyzer}); code != 3 {
+		t.Errorf("exit code was %d, want 3", code)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("contents of rewritten file\ngot: %s\nwant: %s", got, want)
+	}
+}
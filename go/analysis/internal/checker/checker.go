@@ -56,6 +56,19 @@ var (
 
 	// Fix determines whether to apply all suggested fixes.
 	Fix bool
+
+	// CacheDir, if set, names a directory for caching the facts and
+	// diagnostics of each analysis action across runs.
+	CacheDir string
+
+	// Changes, if set, names a file containing a unified diff ("-"
+	// for standard input); only diagnostics on lines changed by it
+	// are reported or fixed.
+	Changes string
+
+	// Since, if set, is a git revision; only diagnostics on lines
+	// changed since that revision are reported or fixed.
+	Since string
 )
 
 // RegisterFlags registers command-line flags used by the analysis driver.
@@ -71,6 +84,9 @@ func RegisterFlags() {
 	flag.BoolVar(&IncludeTests, "test", IncludeTests, "indicates whether test files should be analyzed, too")
 
 	flag.BoolVar(&Fix, "fix", false, "apply all suggested fixes")
+	flag.StringVar(&CacheDir, "cache", "", "cache analysis facts and diagnostics in this directory")
+	flag.StringVar(&Changes, "changes", "", "report only diagnostics on lines changed by this unified diff file ('-' for stdin)")
+	flag.StringVar(&Since, "since", "", "report only diagnostics on lines changed since this git revision")
 }
 
 // Run loads the packages specified by args using go/packages,
@@ -123,6 +139,14 @@ func Run(args []string, analyzers []*analysis.Analyzer) int {
 		}()
 	}
 
+	// Determine the changed lines, if any, before loading
+	// so that errors in the flags are reported promptly.
+	changes, err := loadChanges()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
 	// Load the packages.
 	if dbg('v') {
 		log.SetPrefix("")
@@ -156,6 +180,8 @@ func Run(args []string, analyzers []*analysis.Analyzer) int {
 		SanityCheck: dbg('s'),
 		Sequential:  dbg('p'),
 		FactLog:     factLog,
+		CacheDir:    CacheDir,
+		Config:      analysisflags.Config,
 	}
 	if dbg('v') {
 		log.Printf("building graph of analysis passes")
@@ -166,6 +192,11 @@ func Run(args []string, analyzers []*analysis.Analyzer) int {
 		return 1
 	}
 
+	// Discard diagnostics unrelated to the changed lines.
+	if changes != nil {
+		changes.filter(graph)
+	}
+
 	// Apply all fixes from the root actions.
 	if Fix {
 		if err := applyFixes(graph.Roots); err != nil {
@@ -0,0 +1,214 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildcallgraph defines an Analyzer that constructs a
+// conservative call graph of a package, so that interprocedural
+// analyzers may share one computation. It does not report any
+// diagnostics itself but may be used as an input to other analyzers.
+//
+// The call graph of the package is computed by Class Hierarchy
+// Analysis (see [cha.CallGraph]) over the SSA form built by buildssa.
+// Calls into other packages are summarized by a [Calls] fact exported
+// for each package, recording the direct calls made by each of its
+// named functions and methods; the functions transitively reachable
+// from a given function are computed on demand from these facts (see
+// [CallGraph.Reaches]), so that the size of each fact is proportional
+// to the size of its package, not of the program.
+//
+// Because the methods of other packages' types are not available to
+// the analysis, a dynamic call through an interface is assumed to call
+// only the methods of the package under analysis that satisfy it.
+package buildcallgraph
+
+import (
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/ssa"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "buildcallgraph",
+	Doc:        "build a conservative call graph for later passes",
+	URL:        "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/buildcallgraph",
+	Run:        run,
+	Requires:   []*analysis.Analyzer{buildssa.Analyzer},
+	ResultType: reflect.TypeOf(new(CallGraph)),
+	FactTypes:  []analysis.Fact{new(Calls)},
+}
+
+// CallGraph is the result of the buildcallgraph analyzer.
+type CallGraph struct {
+	// Graph is the CHA call graph of the package. Functions of
+	// other packages appear only as callees, without bodies or edges
+	// of their own; use Reaches to account for them.
+	Graph *callgraph.Graph
+
+	pass  *analysis.Pass
+	calls *Calls // direct calls of the package under analysis
+
+	once    sync.Once
+	imports map[string]*Calls // direct calls of each dependency, by package path
+}
+
+// Calls is a package fact recording, for each named function and
+// method of a package, the named functions that it calls directly.
+// Calls made by anonymous functions and synthetic wrappers are
+// attributed to the named functions that enclose or call them.
+type Calls struct {
+	Edges map[string][]Callee // keyed by full name (as by [types.Func.FullName])
+}
+
+// A Callee identifies a called function.
+type Callee struct {
+	Pkg  string // package path
+	Func string // full name, as by [types.Func.FullName]
+}
+
+func (*Calls) AFact() {}
+
+func (c *Calls) String() string {
+	callers := make([]string, 0, len(c.Edges))
+	for caller := range c.Edges {
+		callers = append(callers, caller)
+	}
+	sort.Strings(callers)
+	var buf strings.Builder
+	buf.WriteString("calls(")
+	for i, caller := range callers {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(caller)
+		buf.WriteString(" -> ")
+		for j, callee := range c.Edges[caller] {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(callee.Func)
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	cg := &CallGraph{
+		Graph: cha.CallGraph(ssainput.Pkg.Prog),
+		pass:  pass,
+		calls: &Calls{Edges: make(map[string][]Callee)},
+	}
+
+	// Record the direct calls of each named function,
+	// and export them for use by dependent packages.
+	for _, fn := range ssainput.SrcFuncs {
+		obj, ok := fn.Object().(*types.Func)
+		if !ok {
+			continue
+		}
+		if callees := cg.directCallees(fn); len(callees) > 0 {
+			cg.calls.Edges[obj.FullName()] = callees
+		}
+	}
+	if len(cg.calls.Edges) > 0 {
+		pass.ExportPackageFact(cg.calls)
+	}
+	return cg, nil
+}
+
+// directCallees returns the named functions called directly by fn,
+// looking through calls to anonymous functions and synthetic wrappers,
+// in order of their first call.
+func (cg *CallGraph) directCallees(fn *ssa.Function) []Callee {
+	var callees []Callee
+	seen := make(map[*callgraph.Node]bool)
+	added := make(map[Callee]bool)
+	var visit func(n *callgraph.Node)
+	visit = func(n *callgraph.Node) {
+		for _, e := range n.Out {
+			callee := e.Callee
+			if seen[callee] {
+				continue
+			}
+			seen[callee] = true
+			obj := funcObject(callee.Func)
+			if obj != nil {
+				c := Callee{Pkg: obj.Pkg().Path(), Func: obj.FullName()}
+				if !added[c] {
+					added[c] = true
+					callees = append(callees, c)
+				}
+			}
+			// Look through functions that have no edges of their
+			// own in the Calls fact: anonymous functions, and
+			// wrappers and instances of functions of other packages.
+			if callee.Func.Blocks != nil && (obj == nil || obj.Pkg() != cg.pass.Pkg) {
+				visit(callee)
+			}
+		}
+	}
+	if n := cg.Graph.Nodes[fn]; n != nil {
+		visit(n)
+	}
+	return callees
+}
+
+// Reaches returns the sorted full names of the named functions
+// transitively reachable from fn, which must belong to the package
+// under analysis. Callees in other packages contribute the functions
+// that they reach according to the Calls facts of their packages.
+func (cg *CallGraph) Reaches(fn *ssa.Function) []string {
+	cg.once.Do(func() {
+		cg.imports = make(map[string]*Calls)
+		for _, fact := range cg.pass.AllPackageFacts() {
+			if calls, ok := fact.Fact.(*Calls); ok {
+				cg.imports[fact.Package.Path()] = calls
+			}
+		}
+	})
+
+	seen := make(map[Callee]bool)
+	if obj := funcObject(fn); obj != nil {
+		seen[Callee{Pkg: obj.Pkg().Path(), Func: obj.FullName()}] = true
+	}
+	var names []string
+	var visit func(callees []Callee)
+	visit = func(callees []Callee) {
+		for _, callee := range callees {
+			if seen[callee] {
+				continue
+			}
+			seen[callee] = true
+			names = append(names, callee.Func)
+			calls := cg.calls
+			if callee.Pkg != cg.pass.Pkg.Path() {
+				calls = cg.imports[callee.Pkg]
+			}
+			if calls != nil {
+				visit(calls.Edges[callee.Func])
+			}
+		}
+	}
+	visit(cg.directCallees(fn))
+	sort.Strings(names)
+	return names
+}
+
+// funcObject returns the declared function of which fn is
+// (an instance of) the body, or nil.
+func funcObject(fn *ssa.Function) *types.Func {
+	if fn.Origin() != nil {
+		fn = fn.Origin()
+	}
+	obj, _ := fn.Object().(*types.Func)
+	return obj
+}
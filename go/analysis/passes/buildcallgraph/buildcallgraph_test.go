@@ -0,0 +1,36 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildcallgraph_test
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/buildcallgraph"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, buildcallgraph.Analyzer, "a")
+
+	// Check the Result of the analysis of package a.
+	cg := results[0].Result.(*buildcallgraph.CallGraph)
+	ssainput := results[0].Pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	for _, test := range []struct {
+		fn   string
+		want []string
+	}{
+		{"unexported", []string{"a.F", "b.G", "b.h"}},
+		{"Dyn", []string{"(a.U).M", "b.G", "b.h"}},
+		{"Rec", []string{"a.rec"}},
+	} {
+		got := cg.Reaches(ssainput.Pkg.Func(test.fn))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Reaches(%s) = %v, want %v", test.fn, got, test.want)
+		}
+	}
+}
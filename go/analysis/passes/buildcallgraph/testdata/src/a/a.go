@@ -0,0 +1,17 @@
+package a // want package:`calls\(\(a.U\).M -> b.G; a.Dyn -> \(a.U\).M; a.F -> b.G; a.Rec -> a.rec; a.rec -> a.rec; a.unexported -> a.F\)`
+
+import "b"
+
+func F() { b.G() }
+
+func Dyn(i b.I) { i.M() }
+
+type U struct{}
+
+func (U) M() { b.G() }
+
+func Rec() { rec() }
+
+func rec() { rec() }
+
+func unexported() { F() }
@@ -0,0 +1,11 @@
+package b // want package:`calls\(\(b.T\).M -> b.G; b.G -> b.h\)`
+
+func G() { h() }
+
+func h() { println() }
+
+type T struct{}
+
+func (T) M() { G() }
+
+type I interface{ M() }
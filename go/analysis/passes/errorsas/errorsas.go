@@ -3,25 +3,43 @@
 // license that can be found in the LICENSE file.
 
 // The errorsas package defines an Analyzer that checks that the second argument to
-// errors.As is a pointer to a type implementing error.
+// errors.As is a pointer to a type implementing error, and reports
+// related misuses of errors.Is and fmt.Errorf.
 package errorsas
 
 import (
 	"errors"
+	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
+	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/go/ast/inspector"
 	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/versions"
 )
 
-const Doc = `report passing non-pointer or non-error values to errors.As
+const Doc = `report misuse of errors.As, errors.Is, and error wrapping
 
 The errorsas analysis reports calls to errors.As where the type
-of the second argument is not a pointer to a type implementing error.`
+of the second argument is not a pointer to a type implementing error.
+
+It also reports calls to errors.Is whose second argument is a newly
+allocated value, such as &MyError{}, that no error can equal, unless
+its type defines an Is method that might match it. Such calls are
+usually meant to test the type of an error, which is the job of
+errors.As; a suggested fix replaces the call by the equivalent use of
+errors.As.
+
+Finally, it reports calls to fmt.Errorf with more than one %w verb in
+files whose Go version is older than go1.20, the release that added
+support for wrapping multiple errors.`
 
 var Analyzer = &analysis.Analyzer{
 	Name:     "errorsas",
@@ -39,26 +57,47 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		return nil, nil
 	}
 
-	if !analysisutil.Imports(pass.Pkg, "errors") {
-		return nil, nil // doesn't directly import errors
+	if !analysisutil.Imports(pass.Pkg, "errors") && !analysisutil.Imports(pass.Pkg, "fmt") {
+		return nil, nil // doesn't directly import errors or fmt
 	}
 
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
+	var goversion string // effective file version ("" => unknown)
 	nodeFilter := []ast.Node{
+		(*ast.File)(nil),
 		(*ast.CallExpr)(nil),
 	}
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
-		call := n.(*ast.CallExpr)
-		fn := typeutil.StaticCallee(pass.TypesInfo, call)
-		if !analysisutil.IsFunctionNamed(fn, "errors", "As") {
+		if file, ok := n.(*ast.File); ok {
+			goversion = versions.FileVersion(pass.TypesInfo, file)
 			return
 		}
-		if len(call.Args) < 2 {
-			return // not enough arguments, e.g. called with return values of another function
-		}
-		if err := checkAsTarget(pass, call.Args[1]); err != nil {
-			pass.ReportRangef(call, "%v", err)
+		call := n.(*ast.CallExpr)
+		fn := typeutil.StaticCallee(pass.TypesInfo, call)
+		switch {
+		case analysisutil.IsFunctionNamed(fn, "errors", "As"):
+			if len(call.Args) < 2 {
+				return // not enough arguments, e.g. called with return values of another function
+			}
+			if err := checkAsTarget(pass, call.Args[1]); err != nil {
+				pass.Report(analysis.Diagnostic{
+					Pos:            call.Pos(),
+					End:            call.End(),
+					Message:        err.Error(),
+					SuggestedFixes: fixAsTarget(pass, call.Args[1]),
+				})
+			}
+
+		case analysisutil.IsFunctionNamed(fn, "errors", "Is"):
+			if len(call.Args) == 2 {
+				checkIsTarget(pass, call)
+			}
+
+		case analysisutil.IsFunctionNamed(fn, "fmt", "Errorf"):
+			if versions.Before(goversion, versions.Go1_20) {
+				checkMultipleWrap(pass, call)
+			}
 		}
 	})
 	return nil, nil
@@ -87,3 +126,156 @@ func checkAsTarget(pass *analysis.Pass, e ast.Expr) error {
 	}
 	return errors.New("second argument to errors.As must be a non-nil pointer to either a type that implements error, or to any interface type")
 }
+
+// fixAsTarget suggests taking the address of a variable passed as the
+// second argument to errors.As, if the result would be a valid target.
+func fixAsTarget(pass *analysis.Pass, e ast.Expr) []analysis.SuggestedFix {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := pass.TypesInfo.Uses[id].(*types.Var)
+	if !ok || v.Type() == errorType {
+		return nil
+	}
+	if _, ok := v.Type().Underlying().(*types.Interface); !ok && !types.Implements(v.Type(), errorType.Underlying().(*types.Interface)) {
+		return nil
+	}
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("Pass a pointer to %s", id.Name),
+		TextEdits: []analysis.TextEdit{{Pos: id.Pos(), End: id.Pos(), NewText: []byte("&")}},
+	}}
+}
+
+// checkIsTarget reports a call errors.Is(err, target) whose target is
+// a newly allocated error, such as &T{} or new(T), or a value T{} of
+// a non-comparable type; errors.Is never finds such a target.
+// Targets whose type has an Is method are not reported, since an
+// error in the chain of the same type may use that method to match
+// them by their contents.
+func checkIsTarget(pass *analysis.Pass, call *ast.CallExpr) {
+	target := ast.Unparen(call.Args[1])
+	t := pass.TypesInfo.TypeOf(target)
+	if t == nil || !types.Implements(t, errorType.Underlying().(*types.Interface)) {
+		return
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "Is"); obj != nil {
+		return
+	}
+	var (
+		what    string   // description of target
+		asArg   string   // equivalent argument to errors.As
+		dropped ast.Expr // expression whose evaluation the fix would remove
+	)
+	switch e := target.(type) {
+	case *ast.UnaryExpr:
+		if lit, ok := ast.Unparen(e.X).(*ast.CompositeLit); ok && e.Op == token.AND && lit.Type != nil {
+			what = "a newly allocated " + t.String()
+			asArg = "new(*" + analysisutil.Format(pass.Fset, lit.Type) + ")"
+			dropped = lit
+		}
+	case *ast.CallExpr:
+		if id, ok := ast.Unparen(e.Fun).(*ast.Ident); ok && len(e.Args) == 1 {
+			if b, ok := pass.TypesInfo.Uses[id].(*types.Builtin); ok && b.Name() == "new" {
+				what = "a newly allocated " + t.String()
+				asArg = "new(*" + analysisutil.Format(pass.Fset, e.Args[0]) + ")"
+			}
+		}
+	case *ast.CompositeLit:
+		if !types.Comparable(t) && e.Type != nil {
+			what = "a value of non-comparable type " + t.String()
+			asArg = "new(" + analysisutil.Format(pass.Fset, e.Type) + ")"
+			dropped = e
+		}
+	}
+	if what == "" {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:            call.Pos(),
+		End:            call.End(),
+		Message:        fmt.Sprintf("second argument to errors.Is is %s that no error can equal; use errors.As to test the type of an error", what),
+		SuggestedFixes: fixIsTarget(pass, call, asArg, dropped),
+	})
+}
+
+// fixIsTarget suggests replacing a call of errors.Is by a call of
+// errors.As with the specified target, unless the dropped part of the
+// original target, if any, has side effects.
+func fixIsTarget(pass *analysis.Pass, call *ast.CallExpr, target string, dropped ast.Expr) []analysis.SuggestedFix {
+	if dropped != nil && analysisutil.HasSideEffects(pass.TypesInfo, dropped) {
+		return nil
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil // e.g. dot import
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Use errors.As to test the type of the error",
+		TextEdits: []analysis.TextEdit{
+			{Pos: sel.Sel.Pos(), End: sel.Sel.End(), NewText: []byte("As")},
+			{Pos: call.Args[1].Pos(), End: call.Args[1].End(), NewText: []byte(target)},
+		},
+	}}
+}
+
+// checkMultipleWrap reports a call of fmt.Errorf whose constant format
+// contains more than one %w verb, which is not supported before
+// go1.20. If the format is a string literal without escapes, a
+// suggested fix replaces all but the first %w by %v.
+func checkMultipleWrap(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+	tv, ok := pass.TypesInfo.Types[call.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	format := constant.StringVal(tv.Value)
+	offsets := wrapVerbs(format)
+	if len(offsets) < 2 {
+		return
+	}
+	diag := analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: "fmt.Errorf call has more than one error-wrapping directive %w, which requires go1.20 or later",
+	}
+	if lit, ok := ast.Unparen(call.Args[0]).(*ast.BasicLit); ok && lit.Value[1:len(lit.Value)-1] == format {
+		var edits []analysis.TextEdit
+		for _, offset := range offsets[1:] {
+			pos := lit.Pos() + 1 + token.Pos(offset) // skip the quote
+			edits = append(edits, analysis.TextEdit{Pos: pos, End: pos + 1, NewText: []byte("v")})
+		}
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Wrap only the first error",
+			TextEdits: edits,
+		}}
+	}
+	pass.Report(diag)
+}
+
+// wrapVerbs returns the byte offsets of the verb character of each %w
+// directive in a format string.
+func wrapVerbs(format string) []int {
+	var offsets []int
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		// Skip flags, width, precision, and argument indexes.
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0 {
+			i++
+		}
+		if i == len(format) {
+			break
+		}
+		if format[i] == 'w' {
+			offsets = append(offsets, i)
+		}
+		_, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+	}
+	return offsets
+}
@@ -8,13 +8,27 @@
 package errorsas_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
 	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/internal/testfiles"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, errorsas.Analyzer, "a", "typeparams")
 }
+
+func TestFixes(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, errorsas.Analyzer, "fix")
+}
+
+func TestWrapVersions(t *testing.T) {
+	for _, file := range []string{"go119.txtar", "go120.txtar"} {
+		dir := testfiles.ExtractTxtarFileToTmp(t, filepath.Join(analysistest.TestData(), "src", "wrap", file))
+		analysistest.RunWithSuggestedFixes(t, dir, errorsas.Analyzer, "golang.org/fake/wrap")
+	}
+}
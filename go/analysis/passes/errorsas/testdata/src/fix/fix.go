@@ -0,0 +1,52 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the suggested fixes of the errorsas checker.
+
+package fix
+
+import "errors"
+
+type myError struct{}
+
+func (*myError) Error() string { return "" }
+
+type valError struct{ causes []error }
+
+func (valError) Error() string { return "" }
+
+type isError struct{}
+
+func (*isError) Error() string        { return "" }
+func (*isError) Is(target error) bool { return true }
+
+type iface interface{ Timeout() bool }
+
+var sentinel = &myError{}
+
+func mk() *myError { return nil }
+
+func _(err error) {
+	var (
+		e  error
+		m  *myError
+		f  iface
+		ve valError
+	)
+	errors.As(err, m) // want `second argument to errors.As must be a non-nil pointer`
+	errors.As(err, f) // want `second argument to errors.As must be a non-nil pointer`
+	errors.As(err, e) // want `second argument to errors.As must be a non-nil pointer`
+
+	_ = errors.Is(err, &myError{})                             // want `second argument to errors.Is is a newly allocated \*fix.myError that no error can equal`
+	_ = errors.Is(err, new(myError))                           // want `second argument to errors.Is is a newly allocated \*fix.myError`
+	_ = errors.Is(err, valError{})                             // want `second argument to errors.Is is a value of non-comparable type fix.valError`
+	_ = errors.Is(err, &myError{}) || errors.Is(err, sentinel) // want `newly allocated`
+
+	// OK
+	_ = errors.Is(err, sentinel)
+	_ = errors.Is(err, mk())
+	_ = errors.Is(err, ve)
+	_ = errors.Is(err, e)
+	_ = errors.Is(err, &isError{}) // may be matched by (*isError).Is
+}
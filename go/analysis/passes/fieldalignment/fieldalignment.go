@@ -18,6 +18,7 @@ import (
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/internal/analysisinternal"
 )
 
 const Doc = `find structs that would use less memory if their fields were sorted
@@ -47,6 +48,14 @@ In rare cases it may cause two variables each updated by its own goroutine
 to occupy the same CPU cache line, inducing a form of memory contention
 known as "false sharing" that slows down both goroutines.
 
+Because its reports are numerous in most code bases, the analyzer
+provides two flags to make it less aggressive. The -threshold=N flag
+reports only structs whose size would decrease by at least N bytes,
+and suppresses the reports about pointer bytes. The -groups flag
+treats blank lines and comments between fields as barriers: fields
+are reordered only within each group, keeping their comments, so that
+related fields stay together.
+
 Unlike most analyzers, which report likely mistakes, the diagnostics
 produced by fieldanalyzer very rarely indicate a significant problem,
 so the analyzer is not included in typical suites such as vet or
@@ -65,6 +74,16 @@ var Analyzer = &analysis.Analyzer{
 	Run:      run,
 }
 
+var (
+	threshold int  // -threshold flag
+	groups    bool // -groups flag
+)
+
+func init() {
+	Analyzer.Flags.IntVar(&threshold, "threshold", threshold, "report only structs whose size would decrease by at least this many bytes")
+	Analyzer.Flags.BoolVar(&groups, "groups", groups, "reorder fields only within groups separated by blank lines or comments")
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{
@@ -90,16 +109,13 @@ func fieldalignment(pass *analysis.Pass, node *ast.StructType, typ *types.Struct
 	maxAlign := pass.TypesSizes.Alignof(unsafePointerTyp)
 
 	s := gcSizes{wordSize, maxAlign}
+	if groups {
+		groupedFieldalignment(pass, node, typ, &s)
+		return
+	}
 	optimal, indexes := optimalOrder(typ, &s)
-	optsz, optptrs := s.Sizeof(optimal), s.ptrdata(optimal)
-
-	var message string
-	if sz := s.Sizeof(typ); sz != optsz {
-		message = fmt.Sprintf("struct of size %d could be %d", sz, optsz)
-	} else if ptrs := s.ptrdata(typ); ptrs != optptrs {
-		message = fmt.Sprintf("struct with %d pointer bytes could be %d", ptrs, optptrs)
-	} else {
-		// Already optimal order.
+	message := s.message(typ, optimal)
+	if message == "" {
 		return
 	}
 
@@ -157,72 +173,204 @@ func fieldalignment(pass *analysis.Pass, node *ast.StructType, typ *types.Struct
 	})
 }
 
-func optimalOrder(str *types.Struct, sizes *gcSizes) (*types.Struct, []int) {
-	nf := str.NumFields()
+// message returns the diagnostic for a struct of type typ whose fields
+// could be placed in the optimal order, or "" if there is nothing
+// worth reporting.
+func (s *gcSizes) message(typ, optimal *types.Struct) string {
+	optsz, optptrs := s.Sizeof(optimal), s.ptrdata(optimal)
+	if sz := s.Sizeof(typ); sz != optsz {
+		if sz-optsz < int64(threshold) {
+			return ""
+		}
+		return fmt.Sprintf("struct of size %d could be %d", sz, optsz)
+	}
+	if threshold > 0 {
+		return "" // no bytes to save
+	}
+	if ptrs := s.ptrdata(typ); ptrs != optptrs {
+		return fmt.Sprintf("struct with %d pointer bytes could be %d", ptrs, optptrs)
+	}
+	return "" // already optimal order
+}
 
-	type elem struct {
-		index   int
-		alignof int64
-		sizeof  int64
-		ptrdata int64
+// groupedFieldalignment is the -groups variant of fieldalignment.
+// It reorders each group of fields separately, keeping each field's
+// syntax, including its comments, intact.
+func groupedFieldalignment(pass *analysis.Pass, node *ast.StructType, typ *types.Struct, s *gcSizes) {
+	list := node.Fields.List
+	optimal, indexes := groupedOrder(pass.Fset, node, typ, s)
+	message := s.message(typ, optimal)
+	if message == "" {
+		return
 	}
 
-	elems := make([]elem, nf)
-	for i := 0; i < nf; i++ {
-		field := str.Field(i)
-		ft := field.Type()
-		elems[i] = elem{
-			i,
-			sizes.Alignof(ft),
-			sizes.Sizeof(ft),
-			sizes.ptrdata(ft),
-		}
+	diag := analysis.Diagnostic{
+		Pos:     node.Pos(),
+		End:     node.Pos() + token.Pos(len("struct")),
+		Message: message,
 	}
 
-	sort.Slice(elems, func(i, j int) bool {
-		ei := &elems[i]
-		ej := &elems[j]
-
-		// Place zero sized objects before non-zero sized objects.
-		zeroi := ei.sizeof == 0
-		zeroj := ej.sizeof == 0
-		if zeroi != zeroj {
-			return zeroi
+	// Replace the text of each field, with its doc and line
+	// comments, by that of the field that takes its place.
+	tokFile := pass.Fset.File(node.Pos())
+	content, err := analysisinternal.MakeReadFile(pass)(tokFile.Name())
+	if err == nil {
+		text := func(f *ast.Field) (start, end token.Pos) {
+			start, end = f.Pos(), f.End()
+			if f.Doc != nil {
+				start = f.Doc.Pos()
+			}
+			if f.Comment != nil {
+				end = f.Comment.End()
+			}
+			return start, end
 		}
-
-		// Next, place more tightly aligned objects before less tightly aligned objects.
-		if ei.alignof != ej.alignof {
-			return ei.alignof > ej.alignof
+		var edits []analysis.TextEdit
+		for i, index := range indexes {
+			if index == i {
+				continue
+			}
+			pos, end := text(list[i])
+			newPos, newEnd := text(list[index])
+			edits = append(edits, analysis.TextEdit{
+				Pos:     pos,
+				End:     end,
+				NewText: content[tokFile.Offset(newPos):tokFile.Offset(newEnd)],
+			})
 		}
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Rearrange fields within groups",
+			TextEdits: edits,
+		}}
+	}
+	pass.Report(diag)
+}
 
-		// Place pointerful objects before pointer-free objects.
-		noptrsi := ei.ptrdata == 0
-		noptrsj := ej.ptrdata == 0
-		if noptrsi != noptrsj {
-			return noptrsj
-		}
+// groupedOrder is like optimalOrder, but it orders the fields of each
+// group of node separately, where groups are separated by blank lines
+// or by comments on lines of their own. It returns the order of the
+// elements of node.Fields.List, each of which may declare several
+// fields, rather than of the fields of str.
+func groupedOrder(fset *token.FileSet, node *ast.StructType, str *types.Struct, sizes *gcSizes) (*types.Struct, []int) {
+	list := node.Fields.List
+
+	// Find the first field of each element of list.
+	first := make([]int, len(list)+1)
+	for i, f := range list {
+		first[i+1] = first[i] + max(len(f.Names), 1)
+	}
 
-		if !noptrsi {
-			// If both have pointers...
-
-			// ... then place objects with less trailing
-			// non-pointer bytes earlier. That is, place
-			// the field with the most trailing
-			// non-pointer bytes at the end of the
-			// pointerful section.
-			traili := ei.sizeof - ei.ptrdata
-			trailj := ej.sizeof - ej.ptrdata
-			if traili != trailj {
-				return traili < trailj
+	var indexes []int
+	var group []elem
+	flush := func() {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].less(&group[j])
+		})
+		for _, e := range group {
+			indexes = append(indexes, e.index)
+		}
+		group = group[:0]
+	}
+	for i, f := range list {
+		if i > 0 {
+			prev := list[i-1].End()
+			if c := list[i-1].Comment; c != nil {
+				prev = c.End()
+			}
+			if f.Doc != nil || fset.Position(f.Pos()).Line > fset.Position(prev).Line+1 {
+				flush() // barrier
 			}
 		}
+		// An element declaring n fields of type T
+		// is laid out as a single [n]T field.
+		t := str.Field(first[i]).Type()
+		if n := first[i+1] - first[i]; n > 1 {
+			t = types.NewArray(t, int64(n))
+		}
+		group = append(group, sizes.elem(i, t))
+	}
+	flush()
 
-		// Lastly, order by size.
-		if ei.sizeof != ej.sizeof {
-			return ei.sizeof > ej.sizeof
+	var fields []*types.Var
+	for _, index := range indexes {
+		for k := first[index]; k < first[index+1]; k++ {
+			fields = append(fields, str.Field(k))
 		}
+	}
+	return types.NewStruct(fields, nil), indexes
+}
+
+// An elem holds the layout properties of a field (or group of fields of the same type).
+type elem struct {
+	index   int
+	alignof int64
+	sizeof  int64
+	ptrdata int64
+}
+
+func (s *gcSizes) elem(index int, t types.Type) elem {
+	return elem{
+		index,
+		s.Alignof(t),
+		s.Sizeof(t),
+		s.ptrdata(t),
+	}
+}
+
+// less reports whether ei should precede ej in the optimal order.
+func (ei *elem) less(ej *elem) bool {
+	// Place zero sized objects before non-zero sized objects.
+	zeroi := ei.sizeof == 0
+	zeroj := ej.sizeof == 0
+	if zeroi != zeroj {
+		return zeroi
+	}
+
+	// Next, place more tightly aligned objects before less tightly aligned objects.
+	if ei.alignof != ej.alignof {
+		return ei.alignof > ej.alignof
+	}
 
-		return false
+	// Place pointerful objects before pointer-free objects.
+	noptrsi := ei.ptrdata == 0
+	noptrsj := ej.ptrdata == 0
+	if noptrsi != noptrsj {
+		return noptrsj
+	}
+
+	if !noptrsi {
+		// If both have pointers...
+
+		// ... then place objects with less trailing
+		// non-pointer bytes earlier. That is, place
+		// the field with the most trailing
+		// non-pointer bytes at the end of the
+		// pointerful section.
+		traili := ei.sizeof - ei.ptrdata
+		trailj := ej.sizeof - ej.ptrdata
+		if traili != trailj {
+			return traili < trailj
+		}
+	}
+
+	// Lastly, order by size.
+	if ei.sizeof != ej.sizeof {
+		return ei.sizeof > ej.sizeof
+	}
+
+	return false
+}
+
+func optimalOrder(str *types.Struct, sizes *gcSizes) (*types.Struct, []int) {
+	nf := str.NumFields()
+
+	elems := make([]elem, nf)
+	for i := 0; i < nf; i++ {
+		elems[i] = sizes.elem(i, str.Field(i).Type())
+	}
+
+	sort.Slice(elems, func(i, j int) bool {
+		return elems[i].less(&elems[j])
 	})
 
 	fields := make([]*types.Var, nf)
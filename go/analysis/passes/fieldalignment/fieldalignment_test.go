@@ -15,3 +15,17 @@ func TestTest(t *testing.T) {
 	testdata := analysistest.TestData()
 	analysistest.RunWithSuggestedFixes(t, testdata, fieldalignment.Analyzer, "a")
 }
+
+func TestGroups(t *testing.T) {
+	defer fieldalignment.Analyzer.Flags.Set("groups", "false")
+	fieldalignment.Analyzer.Flags.Set("groups", "true")
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, fieldalignment.Analyzer, "groups")
+}
+
+func TestThreshold(t *testing.T) {
+	defer fieldalignment.Analyzer.Flags.Set("threshold", "0")
+	fieldalignment.Analyzer.Flags.Set("threshold", "8")
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, fieldalignment.Analyzer, "threshold")
+}
@@ -0,0 +1,26 @@
+package groups
+
+type Good struct {
+	a bool
+	b bool
+
+	c int64
+}
+
+type Bad struct { // want "struct of size 40 could be 32"
+	// Protected by mu.
+	a bool  // a comment
+	b int64 // b comment
+	c bool
+	d int32
+
+	f, g bool
+	h    int64
+}
+
+type Multi struct { // want "struct of size 28 could be 24"
+	a       bool
+	x       [2]int32
+	b, c, d int32
+	e       bool
+}
@@ -0,0 +1,18 @@
+package threshold
+
+type Small struct {
+	a bool
+	b int32
+	c bool
+}
+
+type Large struct { // want "struct of size 24 could be 16"
+	a bool
+	b int64
+	c bool
+}
+
+type Pointers struct {
+	a int64
+	b *int
+}
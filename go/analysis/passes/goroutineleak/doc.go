@@ -0,0 +1,40 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package goroutineleak defines an Analyzer that reports common
+// patterns of goroutine and timer leaks.
+//
+// # Analyzer goroutineleak
+//
+// goroutineleak: report goroutines and timers that may leak
+//
+// The goroutineleak analyzer reports three patterns that cause
+// goroutines or timers to outlive their usefulness.
+//
+// First, a goroutine that sends to or receives from a channel created
+// by the function that started it, when no other code can perform the
+// complementary operation. For example:
+//
+//	func f() {
+//		ch := make(chan int)
+//		go func() { ch <- compute() }() // goroutine blocks forever
+//	}
+//
+// This check uses the SSA form of each function and is conservative: a
+// channel that is stored in a variable, field, or interface, passed
+// to a dynamic call, or otherwise escapes from view is never reported.
+// Channels passed to functions in other packages are tracked using a
+// fact that summarizes how each function uses its channel parameters.
+//
+// Second, a blocking select statement within a loop in a function that
+// has a context.Context parameter, when none of the select's cases
+// receives from ctx.Done() or a similar channel of struct{} values.
+// Such a loop cannot be stopped by cancelling the context.
+//
+// Third, a call to time.After in a case of a select statement within a
+// loop. Before Go 1.23, the timer created by each call is not garbage
+// collected until it fires, so a loop that usually completes the select
+// by another case accumulates timers. Files that use Go 1.23 or later
+// are not reported.
+package goroutineleak
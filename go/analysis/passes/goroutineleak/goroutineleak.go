@@ -0,0 +1,527 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goroutineleak
+
+import (
+	_ "embed"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/versions"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "goroutineleak",
+	Doc:       analysisutil.MustExtractDoc(doc, "goroutineleak"),
+	URL:       "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/goroutineleak",
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer, inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(chanParams)},
+}
+
+// chanParams is a fact recording, for a function with channel
+// parameters, how the function may use each of them.
+type chanParams struct {
+	Uses []chanUse // indexed like ssa.Function.Params
+}
+
+func (*chanParams) AFact() {}
+
+func (f *chanParams) String() string {
+	var parts []string
+	for _, u := range f.Uses {
+		parts = append(parts, u.String())
+	}
+	return "chanParams(" + strings.Join(parts, ", ") + ")"
+}
+
+// A chanUse is a set of operations that may be applied to a channel.
+type chanUse uint8
+
+const (
+	useSend   chanUse = 1 << iota // sent to
+	useRecv                       // received from
+	useClose                      // closed
+	useSelect                     // used in a select that may choose another case
+	useEscape                     // unknown uses
+)
+
+func (u chanUse) String() string {
+	if u == 0 {
+		return "-"
+	}
+	var names []string
+	for i, name := range []string{"send", "recv", "close", "select", "escape"} {
+		if u&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	c := &checker{
+		pass:     pass,
+		pkg:      ssainput.Pkg,
+		params:   make(map[*ssa.Parameter]chanUse),
+		visiting: make(map[ssa.Value]bool),
+	}
+
+	// Export the uses of the channel parameters
+	// of each function for the benefit of callers.
+	for _, fn := range ssainput.SrcFuncs {
+		obj, ok := fn.Object().(*types.Func)
+		if !ok {
+			continue
+		}
+		var fact chanParams
+		hasChan := false
+		for i, p := range fn.Params {
+			var u chanUse
+			if isChan(p.Type()) {
+				hasChan = true
+				u = c.paramUse(fn, i)
+			}
+			fact.Uses = append(fact.Uses, u)
+		}
+		if hasChan {
+			pass.ExportObjectFact(obj, &fact)
+		}
+	}
+
+	for _, fn := range ssainput.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if mc, ok := instr.(*ssa.MakeChan); ok {
+					c.checkChan(mc)
+				}
+			}
+		}
+	}
+
+	checkLoops(pass)
+	return nil, nil
+}
+
+type checker struct {
+	pass     *analysis.Pass
+	pkg      *ssa.Package
+	params   map[*ssa.Parameter]chanUse // memo of paramUse
+	visiting map[ssa.Value]bool         // values whose uses are being computed
+}
+
+// checkChan reports goroutines that block forever on the channel
+// created by mc because no other code performs the complementary
+// operation.
+func (c *checker) checkChan(mc *ssa.MakeChan) {
+	unbuffered := false
+	if k, ok := mc.Size.(*ssa.Const); ok && k.Value != nil {
+		if size, ok := constant.Int64Val(k.Value); ok && size == 0 {
+			unbuffered = true
+		}
+	}
+
+	// Partition the uses of the channel between the goroutines
+	// to which it is passed and all others. A channel held in a
+	// local variable captured by a function literal is used
+	// through the variable's address.
+	var (
+		goroutines []*ssa.Go
+		goUses     []chanUse
+		otherUses  []chanUse
+	)
+	partition := func(instr ssa.Instruction, x ssa.Value, u chanUse) {
+		if g := goroutine(instr, x); g != nil {
+			goroutines = append(goroutines, g)
+			goUses = append(goUses, u)
+		} else {
+			otherUses = append(otherUses, u)
+		}
+	}
+	for _, instr := range *mc.Referrers() {
+		if store, ok := instr.(*ssa.Store); ok && store.Val == mc {
+			if alloc, ok := store.Addr.(*ssa.Alloc); ok {
+				for _, instr := range *alloc.Referrers() {
+					if instr != store {
+						partition(instr, alloc, c.addrUseBy(instr, alloc, mc))
+					}
+				}
+				continue
+			}
+		}
+		partition(instr, mc, c.useBy(instr, mc))
+	}
+	var total chanUse
+	for _, u := range append(goUses, otherUses...) {
+		total |= u
+	}
+	if total&useEscape != 0 {
+		return
+	}
+
+	for i, g := range goroutines {
+		// The uses by others, including other goroutines.
+		var others chanUse
+		for j, u := range goUses {
+			if j != i {
+				others |= u
+			}
+		}
+		for _, u := range otherUses {
+			others |= u
+		}
+
+		switch goUses[i] {
+		case useRecv:
+			if others&(useSend|useClose) == 0 {
+				c.pass.Reportf(g.Pos(), "goroutine may block forever receiving from a channel that is never sent to or closed")
+			}
+		case useSend:
+			if unbuffered && others&useRecv == 0 {
+				c.pass.Reportf(g.Pos(), "goroutine may block forever sending to an unbuffered channel that is never received from")
+			}
+		}
+	}
+}
+
+// goroutine returns the go statement that passes v to a new
+// goroutine, either as an argument or as a free variable of the
+// function literal that it calls, if instr is such a statement or
+// function literal.
+func goroutine(instr ssa.Instruction, v ssa.Value) *ssa.Go {
+	switch instr := instr.(type) {
+	case *ssa.Go:
+		if instr.Call.Value != v {
+			return instr
+		}
+	case *ssa.MakeClosure:
+		if refs := *instr.Referrers(); len(refs) == 1 {
+			if g, ok := refs[0].(*ssa.Go); ok && g.Call.Value == instr {
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+// uses returns the set of operations that may be applied to the
+// channel v.
+func (c *checker) uses(v ssa.Value) chanUse {
+	if c.visiting[v] {
+		return 0 // a cycle of value flow; uses accounted for by caller
+	}
+	refs := v.Referrers()
+	if refs == nil {
+		return useEscape
+	}
+	c.visiting[v] = true
+	defer delete(c.visiting, v)
+
+	var u chanUse
+	for _, instr := range *refs {
+		u |= c.useBy(instr, v)
+	}
+	return u
+}
+
+// useBy returns the set of operations that instr may apply to the
+// channel v, one of its operands.
+func (c *checker) useBy(instr ssa.Instruction, v ssa.Value) chanUse {
+	switch instr := instr.(type) {
+	case *ssa.Send:
+		if instr.Chan == v && instr.X != v {
+			return useSend
+		}
+
+	case *ssa.UnOp:
+		if instr.Op == token.ARROW {
+			return useRecv
+		}
+
+	case *ssa.Select:
+		var u chanUse
+		for _, st := range instr.States {
+			if st.Send == v {
+				return useEscape
+			}
+			if st.Chan == v {
+				if st.Dir == types.SendOnly {
+					u |= useSend
+				} else {
+					u |= useRecv
+				}
+			}
+		}
+		if len(instr.States) > 1 || !instr.Blocking {
+			u |= useSelect
+		}
+		return u
+
+	case *ssa.MakeClosure:
+		var u chanUse
+		fn := instr.Fn.(*ssa.Function)
+		for i, b := range instr.Bindings {
+			if b == v {
+				u |= c.uses(fn.FreeVars[i])
+			}
+		}
+		return u
+
+	case ssa.CallInstruction:
+		return c.callUse(instr.Common(), v)
+
+	case *ssa.Phi:
+		return c.uses(instr)
+
+	case *ssa.ChangeType:
+		return c.uses(instr)
+
+	case *ssa.Store:
+		if alloc, ok := instr.Addr.(*ssa.Alloc); ok && instr.Val == v {
+			return c.addrUses(alloc, v)
+		}
+
+	case *ssa.DebugRef, *ssa.BinOp:
+		return 0 // no effect on the channel
+	}
+	return useEscape
+}
+
+// addrUses returns the set of operations that may be applied to the
+// channel v through addr, the address of a local variable to which v
+// is assigned.
+func (c *checker) addrUses(addr, v ssa.Value) chanUse {
+	if c.visiting[addr] {
+		return 0
+	}
+	c.visiting[addr] = true
+	defer delete(c.visiting, addr)
+
+	var u chanUse
+	for _, instr := range *addr.Referrers() {
+		u |= c.addrUseBy(instr, addr, v)
+	}
+	return u
+}
+
+// addrUseBy returns the set of operations that instr may apply to the
+// channel v through addr, one of its operands.
+func (c *checker) addrUseBy(instr ssa.Instruction, addr, v ssa.Value) chanUse {
+	switch instr := instr.(type) {
+	case *ssa.Store:
+		if instr.Addr == addr && instr.Val == v {
+			return 0
+		}
+		// The variable may hold another channel.
+
+	case *ssa.UnOp:
+		if instr.Op == token.MUL {
+			return c.uses(instr)
+		}
+
+	case *ssa.MakeClosure:
+		var u chanUse
+		fn := instr.Fn.(*ssa.Function)
+		for i, b := range instr.Bindings {
+			if b == addr {
+				u |= c.addrUses(fn.FreeVars[i], v)
+			}
+		}
+		return u
+
+	case *ssa.DebugRef:
+		return 0
+	}
+	return useEscape
+}
+
+// callUse returns the set of operations that the call may apply to
+// its argument v.
+func (c *checker) callUse(call *ssa.CallCommon, v ssa.Value) chanUse {
+	if call.Value == v {
+		return useEscape
+	}
+	if b, ok := call.Value.(*ssa.Builtin); ok {
+		switch b.Name() {
+		case "close":
+			return useClose
+		case "len", "cap":
+			return 0
+		}
+		return useEscape
+	}
+	callee := call.StaticCallee()
+	if callee == nil {
+		return useEscape // dynamic call
+	}
+	var u chanUse
+	for i, arg := range call.Args {
+		if arg == v {
+			u |= c.paramUse(callee, i)
+		}
+	}
+	return u
+}
+
+// paramUse returns the set of operations that fn may apply to its
+// channel parameter of index i (counting the receiver, if any).
+func (c *checker) paramUse(fn *ssa.Function, i int) chanUse {
+	if fn.Pkg != c.pkg || fn.Blocks == nil {
+		// Consult the summary of a function in another package.
+		if fn.Origin() != nil {
+			fn = fn.Origin()
+		}
+		if obj, ok := fn.Object().(*types.Func); ok {
+			var fact chanParams
+			if c.pass.ImportObjectFact(obj, &fact) && i < len(fact.Uses) {
+				return fact.Uses[i]
+			}
+		}
+		return useEscape
+	}
+
+	p := fn.Params[i]
+	u, ok := c.params[p]
+	if !ok {
+		// A recursive call is assumed to do anything.
+		c.params[p] = useEscape
+		u = c.uses(p)
+		c.params[p] = u
+	}
+	return u
+}
+
+func isChan(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Chan)
+	return ok
+}
+
+// checkLoops reports selects within loops that ignore cancellation,
+// and timers created by time.After in each iteration of a loop.
+func checkLoops(pass *analysis.Pass) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	var goversion string // effective file version ("" => unknown)
+	nodeFilter := []ast.Node{
+		(*ast.File)(nil),
+		(*ast.SelectStmt)(nil),
+		(*ast.CallExpr)(nil),
+	}
+	inspect.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.File:
+			goversion = versions.FileVersion(pass.TypesInfo, n)
+
+		case *ast.SelectStmt:
+			if inLoop(stack) && ownsContext(pass.TypesInfo, stack) && !cancellable(pass.TypesInfo, n) {
+				pass.Reportf(n.Pos(), "select in loop does not receive from ctx.Done(), so cancelling the context does not stop the loop")
+			}
+
+		case *ast.CallExpr:
+			fn, _ := typeutil.Callee(pass.TypesInfo, n).(*types.Func)
+			if analysisutil.IsFunctionNamed(fn, "time", "After") &&
+				versions.Before(goversion, versions.Go1_23) &&
+				inSelectCase(n, stack) && inLoop(stack) {
+				pass.Reportf(n.Pos(), "time.After in a loop creates a timer in each iteration that is not garbage collected until it fires; use time.NewTimer and Reset instead")
+			}
+		}
+		return true
+	})
+}
+
+// inLoop reports whether the innermost node of stack lies within the
+// body of a loop of the innermost enclosing function.
+func inLoop(stack []ast.Node) bool {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.ForStmt:
+			return stack[i+1] == n.Body
+		case *ast.RangeStmt:
+			return stack[i+1] == n.Body
+		case *ast.FuncLit, *ast.FuncDecl:
+			return false
+		}
+	}
+	return false
+}
+
+// ownsContext reports whether any function enclosing the innermost
+// node of stack has a parameter of type context.Context.
+func ownsContext(info *types.Info, stack []ast.Node) bool {
+	for _, n := range stack {
+		var ftype *ast.FuncType
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			ftype = n.Type
+		case *ast.FuncDecl:
+			ftype = n.Type
+		default:
+			continue
+		}
+		for _, field := range ftype.Params.List {
+			if analysisutil.IsNamedType(info.TypeOf(field.Type), "context", "Context") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cancellable reports whether the select statement does not block, or
+// has a case that receives from a channel of struct{} values, as
+// returned by ctx.Done().
+func cancellable(info *types.Info, sel *ast.SelectStmt) bool {
+	for _, stmt := range sel.Body.List {
+		cc := stmt.(*ast.CommClause)
+		var recv ast.Expr
+		switch comm := cc.Comm.(type) {
+		case nil:
+			return true // default case
+		case *ast.ExprStmt:
+			recv = comm.X
+		case *ast.AssignStmt:
+			recv = comm.Rhs[0]
+		}
+		if u, ok := astutil.Unparen(recv).(*ast.UnaryExpr); ok && u.Op == token.ARROW {
+			if ch, ok := info.TypeOf(u.X).Underlying().(*types.Chan); ok {
+				if elem, ok := ch.Elem().Underlying().(*types.Struct); ok && elem.NumFields() == 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// inSelectCase reports whether call appears in the communication
+// clause of a select case, as opposed to the statements of its body.
+func inSelectCase(call *ast.CallExpr, stack []ast.Node) bool {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.CommClause:
+			return n.Comm != nil && n.Comm.Pos() <= call.Pos() && call.End() <= n.Comm.End()
+		case *ast.FuncLit, *ast.BlockStmt:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package a
+
+import (
+	"context"
+	"time"
+)
+
+func compute() int { return 0 }
+
+func sendNoRecv() {
+	ch := make(chan int)
+	go func() { ch <- compute() }() // want "goroutine may block forever sending to an unbuffered channel that is never received from"
+}
+
+func sendRecv() int {
+	ch := make(chan int)
+	go func() { ch <- compute() }()
+	return <-ch
+}
+
+func sendBuffered() {
+	ch := make(chan int, 1)
+	go func() { ch <- compute() }()
+}
+
+func recvNoSend() {
+	ch := make(chan int)
+	go func() { // want "goroutine may block forever receiving from a channel that is never sent to or closed"
+		for v := range ch {
+			println(v)
+		}
+	}()
+}
+
+func recvClosed() {
+	ch := make(chan int)
+	go func() {
+		for v := range ch {
+			println(v)
+		}
+	}()
+	close(ch)
+}
+
+func recvArg() {
+	ch := make(chan int)
+	go consume(ch) // want "goroutine may block forever receiving from a channel that is never sent to or closed"
+}
+
+func consume(ch chan int) { // want consume:"chanParams\\(recv\\)"
+	<-ch
+}
+
+func producerConsumer() {
+	ch := make(chan int)
+	go func() { ch <- compute() }()
+	go consume(ch)
+}
+
+func escapes() chan int {
+	ch := make(chan int)
+	go func() { ch <- compute() }()
+	return ch
+}
+
+func selectTimeout() {
+	ch := make(chan int)
+	go func() {
+		select {
+		case ch <- compute():
+		case <-time.After(time.Second):
+		}
+	}()
+}
+
+func loop(ctx context.Context, ch chan int) { // want loop:"chanParams\\(-, recv\\|select\\)"
+	for {
+		select { // want "select in loop does not receive from ctx.Done\\(\\), so cancelling the context does not stop the loop"
+		case v := <-ch:
+			println(v)
+		case <-time.Tick(time.Second):
+		}
+	}
+}
+
+func loopDone(ctx context.Context, ch chan int) { // want loopDone:"chanParams\\(-, recv\\|select\\)"
+	for {
+		select {
+		case v := <-ch:
+			println(v)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func loopDefault(ctx context.Context, ch chan int) { // want loopDefault:"chanParams\\(-, recv\\|select\\)"
+	for {
+		select {
+		case v := <-ch:
+			println(v)
+		default:
+		}
+	}
+}
+
+func noContext(ch chan int) { // want noContext:"chanParams\\(recv\\|select\\)"
+	for {
+		select {
+		case v := <-ch:
+			println(v)
+		case <-time.Tick(time.Second):
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package b
+
+func Drain(ch chan int) { // want Drain:"chanParams\\(recv\\)"
+	for range ch {
+	}
+}
+
+func Fill(ch chan int) { // want Fill:"chanParams\\(send\\)"
+	ch <- 1
+}
+
+func Keep(ch chan int) { // want Keep:"chanParams\\(escape\\)"
+	kept = ch
+}
+
+var kept chan int
@@ -0,0 +1,27 @@
+package c
+
+import "b"
+
+func drained() {
+	ch := make(chan int)
+	go func() { ch <- 1 }()
+	b.Drain(ch)
+}
+
+func filled() {
+	ch := make(chan int)
+	go b.Drain(ch)
+	b.Fill(ch)
+}
+
+func notFilled() {
+	ch := make(chan int)
+	go b.Drain(ch) // want "goroutine may block forever receiving from a channel that is never sent to or closed"
+	b.Drain(ch)
+}
+
+func kept() {
+	ch := make(chan int)
+	go b.Drain(ch)
+	b.Keep(ch)
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package injection defines an Analyzer that reports SQL queries and
+// command arguments built from non-constant strings.
+//
+// # Analyzer injection
+//
+// injection: report SQL queries and commands built by string concatenation
+//
+// The injection analysis reports calls that execute a SQL query or a
+// command, such as (*sql.DB).Query or exec.Command, whose query text
+// or command argument is built by concatenating or formatting
+// non-constant data, for example:
+//
+//	db.Query("SELECT * FROM users WHERE name = '" + name + "'")
+//	exec.Command("sh", "-c", fmt.Sprintf("ls %s", dir))
+//
+// Such strings are prone to injection attacks should the data come
+// from an untrusted source. Queries should instead pass the data as
+// parameters:
+//
+//	db.Query("SELECT * FROM users WHERE name = ?", name)
+//
+// Unlike the taint analysis, which this analysis builds upon, no
+// source of untrusted data is needed: any concatenation or call to
+// fmt.Sprintf, fmt.Sprint, or fmt.Sprintln that has a non-constant
+// string operand is suspect. Operands of numeric or boolean type, and
+// strings formatted from them by functions such as strconv.Itoa, are
+// considered safe, as are choices between constant strings.
+//
+// By default, the queries of the database/sql package and the
+// arguments of exec.Command and exec.CommandContext are checked.
+// Other driver functions may be listed in the settings of the
+// analyzer, which drivers such as "go vet" read from the file named
+// by the -settings flag. The list replaces the default one, and names
+// each function as by [types.Func.FullName] together with the
+// zero-based indices of its sensitive parameters, not counting the
+// receiver; if omitted, all parameters are sensitive:
+//
+//	{"injection": {"sinks": [
+//		{"func": "os/exec.Command"},
+//		{"func": "(*example.com/sqlx.DB).Select", "args": [1]}
+//	]}}
+package injection
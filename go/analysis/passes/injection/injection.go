@@ -0,0 +1,246 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package injection
+
+import (
+	_ "embed"
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/analysis/passes/taint"
+	"golang.org/x/tools/go/ssa"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "injection",
+	Doc:      analysisutil.MustExtractDoc(doc, "injection"),
+	URL:      "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/injection",
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+	Config:   &config{Sinks: taint.DefaultSpec().Sinks},
+}
+
+// config holds the settings of the analyzer.
+type config struct {
+	Sinks []taint.Sink `json:"sinks"` // functions that execute queries or commands
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	settings, ok := pass.Config.(*config)
+	if !ok {
+		// The driver does not support settings.
+		settings = &config{Sinks: taint.DefaultSpec().Sinks}
+	}
+	for _, sink := range settings.Sinks {
+		if sink.Func == "" {
+			return nil, fmt.Errorf("sink has no func")
+		}
+	}
+	spec := &taint.Spec{
+		Sinks:    settings.Sinks,
+		IsSource: isSource,
+	}
+
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	for _, fn := range ssainput.SrcFuncs {
+		spec.Check(fn, func(flow taint.Flow) {
+			how := "string concatenation"
+			if call, ok := flow.Origin.(*ssa.Call); ok {
+				how = callee(call.Common())
+			}
+			what := "query"
+			if name := callee(flow.Sink.Common()); strings.HasPrefix(name, "os/exec.") {
+				what = "command"
+			}
+			diag := analysis.Diagnostic{
+				Pos:     flow.Sink.Pos(),
+				Message: fmt.Sprintf("%s built by %s of non-constant data may allow injection", what, how),
+			}
+			if pos := flow.Origin.Pos(); pos.IsValid() && pos != flow.Sink.Pos() {
+				diag.Related = []analysis.RelatedInformation{{Pos: pos, Message: what + " built here"}}
+			}
+			pass.Report(diag)
+		})
+	}
+	return nil, nil
+}
+
+// isSource reports whether v is a string concatenation or a call to a
+// fmt.Sprint function with an operand that may hold arbitrary data.
+// Operands that are themselves such values are not considered, so
+// that a flow is attributed to the innermost operation.
+func isSource(v ssa.Value) bool {
+	switch v := v.(type) {
+	case *ssa.BinOp:
+		if !isConcat(v) {
+			return false
+		}
+		for _, x := range []ssa.Value{v.X, v.Y} {
+			if !isConcat(x) && !isFormat(x) && !safe(x, nil) {
+				return true
+			}
+		}
+
+	case *ssa.Call:
+		if !isFormat(v) {
+			return false
+		}
+		args, ok := formatArgs(v.Common())
+		if !ok {
+			return true // args... passed through
+		}
+		for _, x := range args {
+			if !isConcat(x) && !isFormat(x) && !safe(x, nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// safe reports whether v cannot hold arbitrary string data: it is a
+// constant or a value of non-string basic type, a choice or
+// concatenation of safe values, or a formatting of safe values.
+// The seen map breaks cycles through φ-nodes.
+func safe(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if b, ok := v.Type().Underlying().(*types.Basic); ok && b.Info()&(types.IsNumeric|types.IsBoolean) != 0 {
+		return true
+	}
+	switch v := v.(type) {
+	case *ssa.Const:
+		return true
+
+	case *ssa.Phi:
+		if seen[v] {
+			return true
+		}
+		if seen == nil {
+			seen = make(map[ssa.Value]bool)
+		}
+		seen[v] = true
+		for _, edge := range v.Edges {
+			if !safe(edge, seen) {
+				return false
+			}
+		}
+		return true
+
+	case *ssa.BinOp:
+		return isConcat(v) && safe(v.X, seen) && safe(v.Y, seen)
+
+	case *ssa.Call:
+		common := v.Common()
+		if isFormat(v) {
+			args, ok := formatArgs(common)
+			if !ok {
+				return false
+			}
+			for _, x := range args {
+				if !safe(x, seen) {
+					return false
+				}
+			}
+			return true
+		}
+		if safeFuncs[callee(common)] {
+			return true
+		}
+	}
+	return false
+}
+
+// safeFuncs holds functions whose string results are formatted from
+// numeric or boolean values.
+var safeFuncs = map[string]bool{
+	"strconv.FormatBool":  true,
+	"strconv.FormatFloat": true,
+	"strconv.FormatInt":   true,
+	"strconv.FormatUint":  true,
+	"strconv.Itoa":        true,
+}
+
+func isConcat(v ssa.Value) bool {
+	binop, ok := v.(*ssa.BinOp)
+	if !ok || binop.Op != token.ADD {
+		return false
+	}
+	b, ok := binop.Type().Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
+
+func isFormat(v ssa.Value) bool {
+	call, ok := v.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	switch callee(call.Common()) {
+	case "fmt.Sprint", "fmt.Sprintf", "fmt.Sprintln":
+		return true
+	}
+	return false
+}
+
+// formatArgs returns the operands of a call to a fmt.Sprint function,
+// including the format, with the variadic arguments unboxed from
+// their interfaces. It returns false if the variadic slice was
+// passed explicitly.
+func formatArgs(common *ssa.CallCommon) ([]ssa.Value, bool) {
+	args := common.Args
+	n := len(args)
+	operands := append([]ssa.Value(nil), args[:n-1]...)
+
+	switch last := args[n-1].(type) {
+	case *ssa.Const:
+		// nil: no variadic arguments
+	case *ssa.Slice:
+		alloc, ok := last.X.(*ssa.Alloc)
+		if !ok {
+			return nil, false
+		}
+		for _, ref := range *alloc.Referrers() {
+			index, ok := ref.(*ssa.IndexAddr)
+			if !ok {
+				continue
+			}
+			for _, ref := range *index.Referrers() {
+				if store, ok := ref.(*ssa.Store); ok && store.Addr == index {
+					x := store.Val
+					if mi, ok := x.(*ssa.MakeInterface); ok {
+						x = mi.X
+					}
+					operands = append(operands, x)
+				}
+			}
+		}
+	default:
+		return nil, false
+	}
+	return operands, true
+}
+
+// callee returns the full name of the function statically called,
+// or "".
+func callee(common *ssa.CallCommon) string {
+	if common.IsInvoke() {
+		return common.Method.FullName()
+	}
+	if fn := common.StaticCallee(); fn != nil {
+		if fn.Origin() != nil {
+			fn = fn.Origin()
+		}
+		if obj, ok := fn.Object().(*types.Func); ok {
+			return obj.FullName()
+		}
+	}
+	return ""
+}
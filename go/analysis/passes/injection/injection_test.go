@@ -0,0 +1,32 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package injection_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/injection"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, injection.Analyzer, "a")
+}
+
+func TestConfig(t *testing.T) {
+	saved, err := json.Marshal(injection.Analyzer.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer json.Unmarshal(saved, injection.Analyzer.Config)
+
+	const settings = `{"sinks": [{"func": "(*config.DB).Select", "args": [1]}]}`
+	if err := json.Unmarshal([]byte(settings), injection.Analyzer.Config); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, analysistest.TestData(), injection.Analyzer, "config")
+}
@@ -0,0 +1,49 @@
+package a
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+func concat(db *sql.DB, name string) {
+	db.Query("SELECT * FROM users WHERE name = '" + name + "'") // want `query built by string concatenation of non-constant data may allow injection`
+
+	q := "SELECT * FROM users WHERE name = '" + name
+	q += "'"
+	db.Exec(q) // want `query built by string concatenation`
+
+	db.Query("SELECT * FROM users WHERE name = ?", name) // ok: parameter
+}
+
+func format(ctx context.Context, tx *sql.Tx, table string, id int) {
+	tx.QueryRowContext(ctx, fmt.Sprintf("SELECT * FROM %s", table)) // want `query built by fmt.Sprintf of non-constant data`
+	tx.QueryRowContext(ctx, fmt.Sprintf("SELECT * FROM t WHERE id = %d", id))
+	tx.QueryRowContext(ctx, fmt.Sprint("SELECT * FROM ", table)) // want `query built by fmt.Sprint of non-constant data`
+}
+
+func safe(db *sql.DB, id int, desc bool) {
+	q := "SELECT * FROM t"
+	if desc {
+		q += " ORDER BY id DESC"
+	}
+	q += " LIMIT " + strconv.Itoa(id)
+	db.Query(q) // ok: built from constants and numbers
+
+	const table = "t"
+	db.Query("SELECT * FROM " + table) // ok: constant
+}
+
+func command(ctx context.Context, dir string) {
+	exec.Command("ls", dir)                    // ok: no shell
+	exec.Command("sh", "-c", "ls "+dir)        // want `command built by string concatenation of non-constant data`
+	exec.CommandContext(ctx, "sh", "-c", "ls") // ok
+	cmd := fmt.Sprintf("ls %s", dir)
+	exec.CommandContext(ctx, "sh", "-c", cmd) // want `command built by fmt.Sprintf`
+}
+
+func args(db *sql.DB, args ...any) {
+	db.Query(fmt.Sprintf("SELECT %v", args...)) // want `query built by fmt.Sprintf`
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type DB struct{}
+
+func (*DB) Select(dest any, query string, args ...any) error { return nil }
+
+func f(db *DB, sqldb *sql.DB, table string) {
+	var rows []string
+	db.Select(&rows, "SELECT * FROM "+table) // want `query built by string concatenation`
+	db.Select(&rows, "SELECT * FROM t WHERE name = ?", table)
+	db.Select(fmt.Sprint(table), "SELECT * FROM t") // ok: insensitive argument
+
+	sqldb.Query("SELECT * FROM " + table) // ok: not listed in the settings
+}
@@ -100,4 +100,28 @@
 // function is assumed to be Printf-like, taking a format string before the
 // argument list. Otherwise it is assumed to be Print-like, taking a list
 // of arguments with no format string.
+//
+// # Specifying printf wrappers in the settings
+//
+// Wrappers that cannot be inferred, such as interface methods or
+// functions in packages that are not analyzed, may be declared in the
+// settings of the analyzer, which drivers such as "go vet" read from
+// the file named by the -settings flag. Each wrapper is identified by
+// its full name, as in the -funcs flag, and states its kind, one of
+// "print", "printf", or "errorf":
+//
+//	{"printf": {"wrappers": [
+//		{"func": "example.com/log.Infof", "kind": "printf"},
+//		{"func": "(example.com/log.Logger).Logf", "kind": "printf", "format": 1}
+//	]}}
+//
+// Additional wrappers may be declared in a JSON file of the same form
+// as the printf settings, without the enclosing "printf" key, named by
+// the analyzer's own -config flag.
+//
+// By default the format string of a printf wrapper is its last
+// parameter before the variadic arguments. The optional format field
+// gives the zero-based index of the format parameter, not counting the
+// receiver, for wrappers that declare other parameters after it, such
+// as func(format string, level int, args ...any).
 package printf
@@ -7,11 +7,13 @@ package printf
 import (
 	"bytes"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/token"
 	"go/types"
+	"os"
 	"reflect"
 	"regexp"
 	"sort"
@@ -29,6 +31,7 @@ import (
 
 func init() {
 	Analyzer.Flags.Var(isPrint, "funcs", "comma-separated list of print function names to check")
+	Analyzer.Flags.StringVar(&configFile, "config", "", "JSON file declaring additional print and printf wrappers")
 }
 
 //go:embed doc.go
@@ -42,6 +45,7 @@ var Analyzer = &analysis.Analyzer{
 	Run:        run,
 	ResultType: reflect.TypeOf((*Result)(nil)),
 	FactTypes:  []analysis.Fact{new(isWrapper)},
+	Config:     new(config),
 }
 
 // Kind is a kind of fmt function behavior.
@@ -69,7 +73,8 @@ func (kind Kind) String() string {
 // Result is the printf analyzer's result type. Clients may query the result
 // to learn whether a function behaves like fmt.Print or fmt.Printf.
 type Result struct {
-	funcs map[*types.Func]Kind
+	funcs    map[*types.Func]Kind
+	wrappers map[string]wrapper // from settings, keyed by FullName
 }
 
 // Kind reports whether fn behaves like fmt.Print or fmt.Printf.
@@ -86,6 +91,9 @@ func (r *Result) Kind(fn *types.Func) Kind {
 			return KindPrint
 		}
 	}
+	if w, ok := r.wrappers[fn.FullName()]; ok {
+		return w.kind
+	}
 
 	return r.funcs[fn]
 }
@@ -112,11 +120,88 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	res := &Result{
 		funcs: make(map[*types.Func]Kind),
 	}
+	settings, _ := pass.Config.(*config)
+	if configFile != "" {
+		// The wrappers declared by the -config file are
+		// added to those of the settings.
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		var file config
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s: %v", configFile, err)
+		}
+		if settings != nil {
+			file.Wrappers = append(settings.Wrappers, file.Wrappers...)
+		}
+		settings = &file
+	}
+	if settings != nil {
+		var err error
+		if res.wrappers, err = settings.wrappers(); err != nil {
+			return nil, err
+		}
+	}
 	findPrintfLike(pass, res)
-	checkCall(pass)
+	checkCall(pass, res)
 	return res, nil
 }
 
+var configFile string // -config flag
+
+// config holds the settings of the analyzer, or the contents of the
+// -config file, which declare print and printf wrappers in the form:
+//
+//	{"wrappers": [{"func": "(*example.com/log.Logger).Infof", "kind": "printf", "format": 1}]}
+//
+// The kind is one of "print", "printf", or "errorf". The optional
+// format is the zero-based index of the format parameter, not counting
+// the receiver; by default, it is the last parameter before the
+// variadic one.
+type config struct {
+	Wrappers []struct {
+		Func   string `json:"func"`
+		Kind   string `json:"kind"`
+		Format *int   `json:"format,omitempty"`
+	} `json:"wrappers,omitempty"`
+}
+
+// A wrapper is a print or printf wrapper declared by the settings.
+type wrapper struct {
+	kind   Kind
+	format int // index of format parameter, not counting the receiver, or -1 for default
+}
+
+// wrappers validates and returns the wrappers declared by the settings,
+// keyed by full name.
+func (config *config) wrappers() (map[string]wrapper, error) {
+	wrappers := make(map[string]wrapper)
+	for _, w := range config.Wrappers {
+		if w.Func == "" {
+			return nil, fmt.Errorf("wrapper has no func")
+		}
+		kind := KindNone
+		for _, k := range []Kind{KindPrint, KindPrintf, KindErrorf} {
+			if w.Kind == k.String() {
+				kind = k
+			}
+		}
+		if kind == KindNone {
+			return nil, fmt.Errorf("wrapper %s: invalid kind %q", w.Func, w.Kind)
+		}
+		format := -1
+		if w.Format != nil {
+			if kind == KindPrint || *w.Format < 0 {
+				return nil, fmt.Errorf("wrapper %s: invalid format index %d", w.Func, *w.Format)
+			}
+			format = *w.Format
+		}
+		wrappers[w.Func] = wrapper{kind, format}
+	}
+	return wrappers, nil
+}
+
 type printfWrapper struct {
 	obj     *types.Func
 	fdecl   *ast.FuncDecl
@@ -235,7 +320,7 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 				return true
 			}
 
-			fn, kind := printfNameAndKind(pass, call)
+			fn, kind := printfNameAndKind(pass, call, res)
 			if kind != 0 {
 				checkPrintfFwd(pass, w, call, kind, res)
 				return true
@@ -410,24 +495,24 @@ func stringConstantExpr(pass *analysis.Pass, expr ast.Expr) (string, bool) {
 }
 
 // checkCall triggers the print-specific checks if the call invokes a print function.
-func checkCall(pass *analysis.Pass) {
+func checkCall(pass *analysis.Pass, res *Result) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{
 		(*ast.CallExpr)(nil),
 	}
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		call := n.(*ast.CallExpr)
-		fn, kind := printfNameAndKind(pass, call)
+		fn, kind := printfNameAndKind(pass, call, res)
 		switch kind {
 		case KindPrintf, KindErrorf:
-			checkPrintf(pass, kind, call, fn)
+			checkPrintf(pass, kind, call, fn, res)
 		case KindPrint:
 			checkPrint(pass, call, fn)
 		}
 	})
 }
 
-func printfNameAndKind(pass *analysis.Pass, call *ast.CallExpr) (fn *types.Func, kind Kind) {
+func printfNameAndKind(pass *analysis.Pass, call *ast.CallExpr, res *Result) (fn *types.Func, kind Kind) {
 	fn, _ = typeutil.Callee(pass.TypesInfo, call).(*types.Func)
 	if fn == nil {
 		return nil, 0
@@ -449,6 +534,10 @@ func printfNameAndKind(pass *analysis.Pass, call *ast.CallExpr) (fn *types.Func,
 		return fn, kind
 	}
 
+	if w, ok := res.wrappers[fn.FullName()]; ok {
+		return fn, w.kind
+	}
+
 	var fact isWrapper
 	if pass.ImportObjectFact(fn, &fact) {
 		return fn, fact.Kind
@@ -500,11 +589,22 @@ type formatState struct {
 }
 
 // checkPrintf checks a call to a formatted print routine such as Printf.
-func checkPrintf(pass *analysis.Pass, kind Kind, call *ast.CallExpr, fn *types.Func) {
+func checkPrintf(pass *analysis.Pass, kind Kind, call *ast.CallExpr, fn *types.Func, res *Result) {
 	idx := formatStringIndex(pass, call)
 	if idx < 0 || idx >= len(call.Args) {
 		return
 	}
+	firstArg := idx + 1 // Arguments are immediately after format string...
+	if w, ok := res.wrappers[fn.FullName()]; ok && w.format >= 0 {
+		// ...unless a configured wrapper has other parameters between them.
+		// A method expression T.f takes the receiver as its first argument.
+		recv := pass.TypesInfo.Types[call.Fun].Type.(*types.Signature).Params().Len() -
+			fn.Type().(*types.Signature).Params().Len()
+		if w.format+recv > idx {
+			return // format index out of range
+		}
+		idx = w.format + recv
+	}
 	formatArg := call.Args[idx]
 	format, ok := stringConstantExpr(pass, formatArg)
 	if !ok {
@@ -533,7 +633,6 @@ func checkPrintf(pass *analysis.Pass, kind Kind, call *ast.CallExpr, fn *types.F
 		return
 	}
 
-	firstArg := idx + 1 // Arguments are immediately after format string.
 	if !strings.Contains(format, "%") {
 		if len(call.Args) > firstArg {
 			pass.Reportf(call.Lparen, "%s call has arguments but no formatting directives", fn.FullName())
@@ -5,6 +5,9 @@
 package printf_test
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
@@ -19,3 +22,27 @@ func Test(t *testing.T) {
 		"a", "b", "nofmt", "typeparams", "issue68744")
 	analysistest.RunWithSuggestedFixes(t, testdata, printf.Analyzer, "fix")
 }
+
+func TestConfig(t *testing.T) {
+	const settings = `{"wrappers": [
+		{"func": "(logging.Logger).Infof", "kind": "printf"},
+		{"func": "(logging.Logger).Emit", "kind": "printf", "format": 0}
+	]}`
+	if err := json.Unmarshal([]byte(settings), printf.Analyzer.Config); err != nil {
+		t.Fatal(err)
+	}
+	defer json.Unmarshal([]byte(`{"wrappers": null}`), printf.Analyzer.Config)
+
+	// The -config file adds to the wrappers of the settings.
+	config := filepath.Join(t.TempDir(), "config.json")
+	const data = `{"wrappers": [
+		{"func": "(logging.Logger).Print", "kind": "print"}
+	]}`
+	if err := os.WriteFile(config, []byte(data), 0666); err != nil {
+		t.Fatal(err)
+	}
+	printf.Analyzer.Flags.Set("config", config)
+	defer printf.Analyzer.Flags.Set("config", "")
+
+	analysistest.Run(t, analysistest.TestData(), printf.Analyzer, "config")
+}
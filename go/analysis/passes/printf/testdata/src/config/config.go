@@ -0,0 +1,15 @@
+// This file contains tests for print and printf wrappers
+// declared in the settings of the analyzer and its -config file.
+
+package config
+
+import "logging"
+
+func _(log logging.Logger, err error) {
+	log.Infof(1, "%d", "hello") // want `\(logging.Logger\).Infof format %d has arg "hello" of wrong type string`
+	log.Infof(1, "%s", "hello")
+	log.Emit("%s", 1, err)
+	log.Emit("%s %s", 1, err)              // want `\(logging.Logger\).Emit format %s reads arg #2, but call has 1 arg`
+	log.Print("%s", err)                   // want `\(logging.Logger\).Print call has possible Printf formatting directive %s`
+	logging.Logger.Emit(log, "%d", 1, "x") // want `\(logging.Logger\).Emit format %d has arg "x" of wrong type string`
+}
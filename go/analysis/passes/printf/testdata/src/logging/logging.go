@@ -0,0 +1,9 @@
+// Package logging declares printf wrappers that the analysis
+// cannot infer: they are interface methods.
+package logging
+
+type Logger interface {
+	Infof(level int, format string, args ...any)
+	Emit(format string, level int, args ...any)
+	Print(args ...any)
+}
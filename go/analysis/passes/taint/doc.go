@@ -0,0 +1,47 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package taint defines an Analyzer that reports flows of untrusted
+// data to sensitive operations, and a reusable engine for building
+// similar analyzers from a specification of sources, sinks, and
+// sanitizers.
+//
+// # Analyzer taint
+//
+// taint: report untrusted input reaching command execution or SQL queries
+//
+// The taint analysis tracks values obtained from untrusted sources,
+// such as environment variables and HTTP request parameters, through
+// the SSA form of each function and reports those that reach the
+// arguments of a sensitive sink, such as exec.Command or
+// (*sql.DB).Query. For example:
+//
+//	name := r.FormValue("name")
+//	db.Query("SELECT * FROM users WHERE name = '" + name + "'")
+//
+// A value passed through a sanitizer, such as strconv.Atoi, is no
+// longer considered tainted.
+//
+// The analysis is intraprocedural and conservative: the result of a
+// call to an arbitrary function is tainted if any of its arguments
+// is, and a variable or data structure becomes tainted when a tainted
+// value is stored in it.
+//
+// The -spec flag names a JSON file that replaces the default sources,
+// sinks, and sanitizers. Functions are named as by
+// [types.Func.FullName], for example "os.Getenv" or
+// "(*database/sql.DB).Query":
+//
+//	{
+//		"sources":    ["example.com/web.Param"],
+//		"sinks":      [{"func": "os/exec.Command"},
+//		               {"func": "(*database/sql.DB).QueryContext", "args": [1]}],
+//		"sanitizers": ["example.com/web.Escape"]
+//	}
+//
+// The args of a sink are the zero-based indices of its sensitive
+// parameters, not counting the receiver; if omitted, all arguments
+// are sensitive. The file is read once per process, when the first
+// package is analyzed.
+package taint
@@ -0,0 +1,401 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taint
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"os"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ssa"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "taint",
+	Doc:      analysisutil.MustExtractDoc(doc, "taint"),
+	URL:      "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/taint",
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+var specFile string // -spec flag
+
+func init() {
+	Analyzer.Flags.StringVar(&specFile, "spec", "", "JSON file specifying sources, sinks, and sanitizers, replacing the defaults")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	spec := DefaultSpec()
+	if specFile != "" {
+		var err error
+		spec, err = loadSpec(specFile)
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			return nil, nil // the error was reported by an earlier pass
+		}
+	}
+	return spec.run(pass)
+}
+
+var (
+	specsMu sync.Mutex
+	specs   = make(map[string]*Spec) // specs loaded by loadSpec, by file name; nil if invalid
+)
+
+// loadSpec returns the spec parsed from the named file, which it reads
+// only once, however many packages are analyzed. If the file cannot be
+// read or parsed, the first call returns the error, and later calls
+// return a nil spec and no error, so that the error is reported once.
+func loadSpec(filename string) (*Spec, error) {
+	specsMu.Lock()
+	defer specsMu.Unlock()
+	if spec, ok := specs[filename]; ok {
+		return spec, nil
+	}
+	specs[filename] = nil
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	specs[filename] = spec
+	return spec, nil
+}
+
+// NewAnalyzer returns an Analyzer with the specified name and
+// documentation that reports the flows of tainted data described by
+// spec. The spec must not be modified after the call.
+func NewAnalyzer(name, doc string, spec *Spec) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     name,
+		Doc:      doc,
+		Run:      spec.run,
+		Requires: []*analysis.Analyzer{buildssa.Analyzer},
+	}
+}
+
+func (spec *Spec) run(pass *analysis.Pass) (any, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	for _, fn := range ssainput.SrcFuncs {
+		spec.Check(fn, func(flow Flow) {
+			source := "source"
+			if flow.Source != nil {
+				source = calleeName(flow.Source.Common())
+			}
+			diag := analysis.Diagnostic{
+				Pos: flow.Sink.Pos(),
+				Message: fmt.Sprintf("untrusted data from %s reaches %s",
+					source, calleeName(flow.Sink.Common())),
+			}
+			if pos := flow.Origin.Pos(); pos.IsValid() {
+				diag.Related = []analysis.RelatedInformation{{Pos: pos, Message: "source of untrusted data"}}
+			}
+			pass.Report(diag)
+		})
+	}
+	return nil, nil
+}
+
+// A Spec specifies the sources, sinks, and sanitizers of a taint
+// analysis. Functions are named as by [types.Func.FullName].
+type Spec struct {
+	Sources    []string `json:"sources"`    // functions whose results are tainted
+	Sinks      []Sink   `json:"sinks"`      // functions that must not receive tainted arguments
+	Sanitizers []string `json:"sanitizers"` // functions whose results are never tainted
+
+	// IsSource, if non-nil, reports whether a value is tainted in
+	// itself, in addition to the results of calls to Sources.
+	// It allows a client to treat values produced by operations
+	// other than calls, such as string concatenations, as sources.
+	IsSource func(v ssa.Value) bool `json:"-"`
+
+	once  sync.Once
+	index *specIndex
+}
+
+// A Sink is a function some of whose parameters must not receive
+// tainted data.
+type Sink struct {
+	Func string `json:"func"`
+	// Args holds the zero-based indices of the sensitive
+	// parameters, not counting the receiver.
+	// If empty, all parameters are sensitive.
+	Args []int `json:"args,omitempty"`
+}
+
+// ParseSpec parses a JSON encoding of a Spec.
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := new(Spec)
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+	for _, sink := range spec.Sinks {
+		if sink.Func == "" {
+			return nil, fmt.Errorf("sink has no func")
+		}
+		for _, i := range sink.Args {
+			if i < 0 {
+				return nil, fmt.Errorf("sink %s: invalid argument index %d", sink.Func, i)
+			}
+		}
+	}
+	return spec, nil
+}
+
+// DefaultSpec returns the specification used by [Analyzer]: untrusted
+// input from the environment, standard input, and HTTP requests must
+// not reach command execution or SQL query text.
+func DefaultSpec() *Spec {
+	spec := &Spec{
+		Sources: []string{
+			"os.Getenv",
+			"os.LookupEnv",
+			"(*net/http.Request).FormValue",
+			"(*net/http.Request).PostFormValue",
+			"(*net/http.Request).PathValue",
+			"(*net/http.Request).Cookie",
+			"(net/url.Values).Get",
+			"(*bufio.Reader).ReadString",
+			"(*bufio.Scanner).Text",
+		},
+		Sinks: []Sink{
+			{Func: "os/exec.Command"},
+			{Func: "os/exec.CommandContext", Args: []int{1, 2}},
+		},
+		Sanitizers: []string{
+			"strconv.Atoi",
+			"strconv.ParseBool",
+			"strconv.ParseFloat",
+			"strconv.ParseInt",
+			"strconv.ParseUint",
+		},
+	}
+	for _, recv := range []string{"*database/sql.DB", "*database/sql.Tx", "*database/sql.Conn"} {
+		for _, method := range []string{"Exec", "Prepare", "Query", "QueryRow"} {
+			spec.Sinks = append(spec.Sinks,
+				Sink{Func: fmt.Sprintf("(%s).%s", recv, method), Args: []int{0}},
+				Sink{Func: fmt.Sprintf("(%s).%sContext", recv, method), Args: []int{1}})
+		}
+	}
+	return spec
+}
+
+type specIndex struct {
+	sources, sanitizers map[string]bool
+	sinks               map[string]Sink
+}
+
+func (spec *Spec) getIndex() *specIndex {
+	spec.once.Do(func() {
+		idx := &specIndex{
+			sources:    make(map[string]bool),
+			sanitizers: make(map[string]bool),
+			sinks:      make(map[string]Sink),
+		}
+		for _, name := range spec.Sources {
+			idx.sources[name] = true
+		}
+		for _, name := range spec.Sanitizers {
+			idx.sanitizers[name] = true
+		}
+		for _, sink := range spec.Sinks {
+			idx.sinks[sink.Func] = sink
+		}
+		spec.index = idx
+	})
+	return spec.index
+}
+
+// A Flow records that data from a source reaches a sink.
+type Flow struct {
+	Origin ssa.Value           // the tainted value at which the flow begins
+	Source ssa.CallInstruction // call of a source function, if Origin is one; otherwise nil
+	Sink   ssa.CallInstruction // call of a sink function
+	Arg    int                 // index of the tainted argument of Sink, not counting the receiver
+}
+
+// Check calls report for each call of a sink within fn that receives
+// tainted data, reporting only the first such argument of each call.
+//
+// The analysis is intraprocedural: tainted values are propagated
+// through the instructions of fn alone. The result of a call is
+// tainted if any of its operands is tainted, unless the callee is a
+// sanitizer; a memory location, map or channel becomes tainted when a
+// tainted value is stored in it, as does the variable or aggregate
+// that contains it.
+func (spec *Spec) Check(fn *ssa.Function, report func(Flow)) {
+	idx := spec.getIndex()
+
+	// taint maps each tainted value to its origin.
+	taint := make(map[ssa.Value]ssa.Value)
+	changed := false
+	mark := func(v ssa.Value, src ssa.Value) {
+		if _, ok := taint[v]; !ok && !isBoolean(v.Type()) {
+			taint[v] = src
+			changed = true
+		}
+	}
+
+	var operands []*ssa.Value // reused
+	for changed = true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Store:
+					if src, ok := taint[instr.Val]; ok {
+						mark(instr.Addr, src)
+						mark(root(instr.Addr), src)
+					}
+
+				case *ssa.MapUpdate:
+					for _, v := range []ssa.Value{instr.Key, instr.Value} {
+						if src, ok := taint[v]; ok {
+							mark(instr.Map, src)
+							break
+						}
+					}
+
+				case *ssa.Send:
+					if src, ok := taint[instr.X]; ok {
+						mark(instr.Chan, src)
+					}
+
+				default:
+					v, ok := instr.(ssa.Value)
+					if !ok {
+						continue
+					}
+					if _, ok := taint[v]; ok {
+						continue
+					}
+					if call, ok := instr.(*ssa.Call); ok {
+						name := calleeName(call.Common())
+						if idx.sources[name] {
+							mark(call, call)
+							continue
+						}
+						if idx.sanitizers[name] {
+							continue
+						}
+					}
+					if spec.IsSource != nil && spec.IsSource(v) {
+						mark(v, v)
+						continue
+					}
+					operands = instr.Operands(operands[:0])
+					for _, op := range operands {
+						if *op == nil {
+							continue
+						}
+						if src, ok := taint[*op]; ok {
+							mark(v, src)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(taint) == 0 {
+		return
+	}
+
+	// Report tainted arguments of sinks.
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			common := call.Common()
+			sink, ok := idx.sinks[calleeName(common)]
+			if !ok {
+				continue
+			}
+			args := common.Args
+			if !common.IsInvoke() && common.Signature().Recv() != nil {
+				args = args[1:] // skip receiver
+			}
+			for i, arg := range args {
+				if !sensitive(sink, i) {
+					continue
+				}
+				if origin, ok := taint[arg]; ok {
+					source, _ := origin.(ssa.CallInstruction)
+					if source != nil && !idx.sources[calleeName(source.Common())] {
+						source = nil // IsSource
+					}
+					report(Flow{Origin: origin, Source: source, Sink: call, Arg: i})
+					break
+				}
+			}
+		}
+	}
+}
+
+// sensitive reports whether the ith parameter of the sink is sensitive.
+func sensitive(sink Sink, i int) bool {
+	if len(sink.Args) == 0 {
+		return true
+	}
+	for _, j := range sink.Args {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
+
+// calleeName returns the full name of the function or method called,
+// or "" if it is dynamic.
+func calleeName(common *ssa.CallCommon) string {
+	if common.IsInvoke() {
+		return common.Method.FullName()
+	}
+	if fn := common.StaticCallee(); fn != nil {
+		if fn.Origin() != nil {
+			fn = fn.Origin()
+		}
+		if obj, ok := fn.Object().(*types.Func); ok {
+			return obj.FullName()
+		}
+	}
+	return ""
+}
+
+// root returns the variable or aggregate that contains the
+// location denoted by addr.
+func root(addr ssa.Value) ssa.Value {
+	for {
+		switch x := addr.(type) {
+		case *ssa.FieldAddr:
+			addr = x.X
+		case *ssa.IndexAddr:
+			addr = x.X
+		default:
+			return addr
+		}
+	}
+}
+
+func isBoolean(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsBoolean != 0
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/taint"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, taint.Analyzer, "a")
+}
+
+func TestCustomSpec(t *testing.T) {
+	spec, err := taint.ParseSpec([]byte(`{
+		"sources":    ["b.Param"],
+		"sinks":      [{"func": "(*b.Runner).Run", "args": [1]}],
+		"sanitizers": ["b.Escape"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := taint.NewAnalyzer("custom", "custom taint analysis", spec)
+	analysistest.Run(t, analysistest.TestData(), a, "b")
+}
+
+// TestSpecFlag checks that the -spec file is read only once,
+// however many times the analyzer is run.
+func TestSpecFlag(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "spec.json")
+	data := `{
+		"sources":    ["b.Param"],
+		"sinks":      [{"func": "(*b.Runner).Run", "args": [1]}],
+		"sanitizers": ["b.Escape"]
+	}`
+	if err := os.WriteFile(filename, []byte(data), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := taint.Analyzer.Flags.Set("spec", filename); err != nil {
+		t.Fatal(err)
+	}
+	defer taint.Analyzer.Flags.Set("spec", "")
+
+	analysistest.Run(t, analysistest.TestData(), taint.Analyzer, "b")
+
+	// A change to the file has no effect on later runs.
+	if err := os.WriteFile(filename, []byte("invalid"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, analysistest.TestData(), taint.Analyzer, "b")
+}
+
+func TestParseSpecError(t *testing.T) {
+	for _, data := range []string{
+		`{"sinks": [{"args": [0]}]}`,
+		`{"sinks": [{"func": "f", "args": [-1]}]}`,
+		`{"sources": 1}`,
+	} {
+		if _, err := taint.ParseSpec([]byte(data)); err == nil {
+			t.Errorf("ParseSpec(%s) succeeded, want error", data)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package a
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func command() {
+	exec.Command("ls", os.Getenv("DIR"))     // want `untrusted data from os.Getenv reaches os/exec.Command`
+	exec.Command("ls", "-l")                 // ok: constant
+	exec.Command(os.Getenv("SHELL"), "-c")   // want `untrusted data from os.Getenv reaches os/exec.Command`
+	exec.CommandContext(context.TODO(), "x") // ok
+
+	dir := os.Getenv("DIR")
+	args := []string{"-l", dir}
+	exec.Command("ls", args...) // want `untrusted data from os.Getenv reaches os/exec.Command`
+}
+
+func query(db *sql.DB, r *http.Request) {
+	name := r.FormValue("name")
+	db.Query("SELECT * FROM users WHERE name = '" + name + "'")                     // want `untrusted data from \(\*net/http.Request\).FormValue reaches \(\*database/sql.DB\).Query`
+	db.Query(fmt.Sprintf("SELECT * FROM users WHERE name = '%s'", name))            // want `untrusted data from \(\*net/http.Request\).FormValue reaches \(\*database/sql.DB\).Query`
+	db.Query("SELECT * FROM users WHERE name = ?", name)                            // ok: parameterized
+	db.QueryContext(context.TODO(), strings.ToUpper("SELECT "+r.FormValue("cols"))) // want `reaches \(\*database/sql.DB\).QueryContext`
+
+	// Sanitized by conversion to an integer.
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		return
+	}
+	db.Exec(fmt.Sprintf("DELETE FROM users WHERE id = %d", id)) // ok
+}
+
+func variable(db *sql.DB, r *http.Request) {
+	var q string
+	if r.Method == "GET" {
+		q = "SELECT " + r.URL.Query().Get("cols")
+	} else {
+		q = "SELECT *"
+	}
+	db.QueryRow(q) // want `untrusted data from \(net/url.Values\).Get reaches \(\*database/sql.DB\).QueryRow`
+}
+
+func closure(db *sql.DB) {
+	f := func() {
+		db.Exec(os.Getenv("SQL")) // want `reaches \(\*database/sql.DB\).Exec`
+	}
+	f()
+}
@@ -0,0 +1,19 @@
+package b
+
+// This package is analyzed with a custom spec.
+
+func Param(name string) string { return name }
+
+func Escape(s string) string { return s }
+
+type Runner struct{}
+
+func (*Runner) Run(tag, script string) {}
+
+func f(r *Runner) {
+	p := Param("x")
+	r.Run(p, "echo")        // ok: tag is not sensitive
+	r.Run("tag", "echo "+p) // want `untrusted data from b.Param reaches \(\*b.Runner\).Run`
+	r.Run("tag", Escape(p)) // ok: sanitized
+	r.Run("tag", "echo hi") // ok
+}
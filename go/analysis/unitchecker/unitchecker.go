@@ -14,6 +14,15 @@
 //	-flags          describe flags                    (to the build tool)
 //	foo.cfg         description of compilation unit (from the build tool)
 //
+// Each compilation unit is analyzed in a single address space, so
+// the in-memory results of prerequisite analyzers (see
+// [analysis.Analyzer.Requires]) need not be serializable: they are
+// recomputed within each unit from its source and the export data of
+// its dependencies. Analyzers need not declare which of their
+// requirements may be reconstructed in this way, since all of them
+// are; in particular, SSA-based analyzers that require buildssa, such
+// as nilness, are supported. Only facts cross unit boundaries.
+//
 // This package does not depend on go/packages.
 // If you need a standalone tool, use multichecker,
 // which supports this mode but can also load packages
@@ -276,50 +285,48 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		return nil, err
 	}
 
-	// Register fact types with gob.
-	// In VetxOnly mode, analyzers are only for their facts,
-	// so we can skip any analysis that neither produces facts
-	// nor depends on any analysis that produces facts.
-	//
-	// TODO(adonovan): fix: the command (and logic!) here are backwards.
-	// It should say "...nor is required by any...". (Issue 443099)
-	//
-	// Also build a map to hold working state and result.
+	// Register fact types with gob,
+	// and build a map to hold working state and result.
 	type action struct {
 		once        sync.Once
 		result      interface{}
 		err         error
-		usesFacts   bool // (transitively uses)
 		diagnostics []analysis.Diagnostic
 	}
 	actions := make(map[*analysis.Analyzer]*action)
-	var registerFacts func(a *analysis.Analyzer) bool
-	registerFacts = func(a *analysis.Analyzer) bool {
-		act, ok := actions[a]
-		if !ok {
-			act = new(action)
-			var usesFacts bool
+	var register func(a *analysis.Analyzer)
+	register = func(a *analysis.Analyzer) {
+		if _, ok := actions[a]; !ok {
+			actions[a] = new(action)
 			for _, f := range a.FactTypes {
-				usesFacts = true
 				gob.Register(f)
 			}
 			for _, req := range a.Requires {
-				if registerFacts(req) {
-					usesFacts = true
-				}
+				register(req)
 			}
-			act.usesFacts = usesFacts
-			actions[a] = act
 		}
-		return act.usesFacts
 	}
-	var filtered []*analysis.Analyzer
 	for _, a := range analyzers {
-		if registerFacts(a) || !cfg.VetxOnly {
-			filtered = append(filtered, a)
+		register(a)
+	}
+
+	// In VetxOnly mode, analyzers are run only for their facts,
+	// so the roots are just the analyzers that produce facts;
+	// their prerequisites are executed on demand. Analyzers that
+	// merely consume the results of others (for example, an SSA
+	// based checker requiring buildssa) are not run at all.
+	if cfg.VetxOnly {
+		var factAnalyzers []*analysis.Analyzer
+		for a := range actions {
+			if len(a.FactTypes) > 0 {
+				factAnalyzers = append(factAnalyzers, a)
+			}
 		}
+		sort.Slice(factAnalyzers, func(i, j int) bool {
+			return factAnalyzers[i].Name < factAnalyzers[j].Name
+		})
+		analyzers = factAnalyzers
 	}
-	analyzers = filtered
 
 	// Read facts from imported packages.
 	facts, err := facts.NewDecoder(pkg).Decode(makeFactImporter(cfg))
@@ -360,6 +367,12 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 				factFilter[reflect.TypeOf(f)] = true
 			}
 
+			config, err := analysisinternal.MakeConfig(a, analysisflags.Config[a.Name])
+			if err != nil {
+				act.err = err
+				return
+			}
+
 			module := &analysis.Module{
 				Path:      cfg.ModulePath,
 				Version:   cfg.ModuleVersion,
@@ -385,6 +398,7 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 				ExportPackageFact: facts.ExportPackageFact,
 				AllPackageFacts:   func() []analysis.PackageFact { return facts.AllPackageFacts(factFilter) },
 				Module:            module,
+				Config:            config,
 			}
 			pass.ReadFile = analysisinternal.MakeReadFile(pass)
 
@@ -15,6 +15,7 @@ import (
 
 	"golang.org/x/tools/go/analysis/passes/assign"
 	"golang.org/x/tools/go/analysis/passes/findcall"
+	"golang.org/x/tools/go/analysis/passes/nilness"
 	"golang.org/x/tools/go/analysis/passes/printf"
 	"golang.org/x/tools/go/analysis/unitchecker"
 	"golang.org/x/tools/internal/packagestest"
@@ -45,6 +46,7 @@ func minivet() {
 		findcall.Analyzer,
 		printf.Analyzer,
 		assign.Analyzer,
+		nilness.Analyzer, // requires buildssa
 	)
 }
 
@@ -85,6 +87,14 @@ func _() {
     i := 5
     i = i
 }
+`,
+			"d/d.go": `package d
+
+func _(p *int) {
+	if p == nil {
+		print(*p)
+	}
+}
 `,
 		}}})
 	defer exported.Cleanup()
@@ -98,6 +108,9 @@ func _() {
 `
 	const wantC = `# golang.org/fake/c
 ([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?c/c.go:5:5: self-assignment of i to i
+`
+	const wantD = `# golang.org/fake/d
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?d/d.go:5:9: nil dereference in load
 `
 	const wantAJSON = `# golang.org/fake/a
 \{
@@ -157,6 +170,7 @@ func _() {
 		{args: "golang.org/fake/a", wantOut: wantA, wantExitError: true},
 		{args: "golang.org/fake/b", wantOut: wantB, wantExitError: true},
 		{args: "golang.org/fake/c", wantOut: wantC, wantExitError: true},
+		{args: "golang.org/fake/d", wantOut: wantD, wantExitError: true},
 		{args: "golang.org/fake/a golang.org/fake/b", wantOut: wantA + wantB, wantExitError: true},
 		{args: "-json golang.org/fake/a", wantOut: wantAJSON, wantExitError: false},
 		{args: "-json golang.org/fake/c", wantOut: wantCJSON, wantExitError: false},
@@ -0,0 +1,89 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unitchecker
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+)
+
+type vetxFact struct{}
+
+func (*vetxFact) AFact() {}
+
+// TestVetxOnly checks that in VetxOnly mode only the analyzers that
+// produce facts (and their prerequisites) are run.
+func TestVetxOnly(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "p.go")
+	if err := os.WriteFile(src, []byte("package p\n\nfunc F() {}\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+	record := func(pass *analysis.Pass) {
+		mu.Lock()
+		ran = append(ran, pass.Analyzer.Name)
+		mu.Unlock()
+	}
+	facts := &analysis.Analyzer{
+		Name:      "facts",
+		Doc:       "exports a package fact",
+		FactTypes: []analysis.Fact{new(vetxFact)},
+		Run: func(pass *analysis.Pass) (any, error) {
+			record(pass)
+			pass.ExportPackageFact(new(vetxFact))
+			return nil, nil
+		},
+	}
+	ssa := &analysis.Analyzer{
+		Name:     "ssa",
+		Doc:      "consumes SSA but produces no facts",
+		Requires: []*analysis.Analyzer{buildssa.Analyzer, facts},
+		Run: func(pass *analysis.Pass) (any, error) {
+			record(pass)
+			if pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA).Pkg == nil {
+				t.Error("no SSA package")
+			}
+			return nil, nil
+		},
+	}
+
+	for _, test := range []struct {
+		vetxOnly bool
+		want     []string
+	}{
+		{false, []string{"facts", "ssa"}},
+		{true, []string{"facts"}},
+	} {
+		ran = nil
+		cfg := &Config{
+			ID:         "p",
+			Compiler:   "gc",
+			ImportPath: "p",
+			GoFiles:    []string{src},
+			VetxOnly:   test.vetxOnly,
+			VetxOutput: filepath.Join(dir, "p.vetx"),
+		}
+		if _, err := run(token.NewFileSet(), cfg, []*analysis.Analyzer{ssa}); err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(ran)
+		if !reflect.DeepEqual(ran, test.want) {
+			t.Errorf("VetxOnly=%t: ran %v, want %v", test.vetxOnly, ran, test.want)
+		}
+	}
+}
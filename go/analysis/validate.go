@@ -18,7 +18,8 @@ import (
 // that the Run is non-nil;
 // that the Requires graph is acyclic;
 // that analyzer fact types are unique;
-// that each fact type is a pointer.
+// that each fact type is a pointer;
+// that the Config, if any, is a non-nil pointer.
 //
 // Analyzer names need not be unique, though this may be confusing.
 func Validate(analyzers []*Analyzer) error {
@@ -53,6 +54,11 @@ func Validate(analyzers []*Analyzer) error {
 			if a.Run == nil {
 				return fmt.Errorf("analyzer %q has nil Run", a)
 			}
+			if a.Config != nil {
+				if v := reflect.ValueOf(a.Config); v.Kind() != reflect.Ptr || v.IsNil() {
+					return fmt.Errorf("analyzer %q has Config of type %T, want non-nil pointer", a, a.Config)
+				}
+			}
 			// fact types
 			for _, f := range a.FactTypes {
 				if f == nil {
@@ -150,3 +150,27 @@ func TestValidateNoRun(t *testing.T) {
 		t.Errorf("got unexpected error while validating analyzers withoutRun: %v", err)
 	}
 }
+
+func TestValidateConfig(t *testing.T) {
+	type settings struct{ N int }
+	for _, test := range []struct {
+		config any
+		ok     bool
+	}{
+		{nil, true},
+		{new(settings), true},
+		{settings{}, false},
+		{(*settings)(nil), false},
+	} {
+		a := &Analyzer{
+			Name:   "withConfig",
+			Doc:    "this analyzer has settings",
+			Run:    func(p *Pass) (interface{}, error) { return nil, nil },
+			Config: test.config,
+		}
+		err := Validate([]*Analyzer{a})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("Validate(Config=%#v) = %v, want ok=%t", test.config, err, test.ok)
+		}
+	}
+}
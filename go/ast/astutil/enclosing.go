@@ -11,6 +11,7 @@ import (
 	"go/ast"
 	"go/token"
 	"sort"
+	"strings"
 )
 
 // PathEnclosingInterval returns the node that encloses the source
@@ -170,6 +171,80 @@ func PathEnclosingInterval(root *ast.File, start, end token.Pos) (path []ast.Nod
 	return
 }
 
+// CommentEnclosingInterval returns the comment of file f that encloses
+// the source interval [start, end), along with the comment group to
+// which it belongs, or nils if the interval is not within a comment.
+// Unlike [PathEnclosingInterval], it considers all the comments of the
+// file, not only those that the syntax tree associates with a node.
+//
+// If the comment is a directive, as described by [ParseDirective],
+// CommentEnclosingInterval also returns the parsed directive.
+func CommentEnclosingInterval(f *ast.File, start, end token.Pos) (*ast.CommentGroup, *ast.Comment, *Directive) {
+	// f.Comments is sorted by position.
+	i := sort.Search(len(f.Comments), func(i int) bool {
+		return f.Comments[i].End() > start
+	})
+	if i == len(f.Comments) {
+		return nil, nil, nil
+	}
+	group := f.Comments[i]
+	for _, c := range group.List {
+		if c.Pos() <= start && end <= c.End() {
+			d, _ := ParseDirective(c)
+			return group, c, d
+		}
+	}
+	return nil, nil, nil
+}
+
+// A Directive is a comment of the form //tool:name args, such as
+// //go:noinline or //lint:ignore U1000 reason.
+type Directive struct {
+	Tool string    // e.g. "go"
+	Name string    // e.g. "linkname"
+	Args string    // the remainder of the comment, without leading space
+	Pos  token.Pos // position of the comment
+}
+
+// ParseDirective parses comment c as a directive, which has the form
+//
+//	//tool:name args
+//
+// where tool and name are non-empty and consist of lowercase letters
+// and digits, and there is no space after the "//". It reports whether
+// the comment is a directive. Line directives (//line) and cgo's
+// //export directives, which do not have this form, are not
+// recognized.
+func ParseDirective(c *ast.Comment) (*Directive, bool) {
+	text, ok := strings.CutPrefix(c.Text, "//")
+	if !ok {
+		return nil, false
+	}
+	word, args, _ := strings.Cut(text, " ")
+	tool, name, ok := strings.Cut(word, ":")
+	if !ok || !isDirectiveWord(tool) || !isDirectiveWord(name) {
+		return nil, false
+	}
+	return &Directive{
+		Tool: tool,
+		Name: name,
+		Args: strings.TrimLeft(args, " \t"),
+		Pos:  c.Pos(),
+	}, true
+}
+
+func isDirectiveWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // tokenNode is a dummy implementation of ast.Node for a single token.
 // They are used transiently by PathEnclosingInterval but never escape
 // this package.
@@ -231,3 +231,70 @@ func TestPathEnclosingInterval_Paths(t *testing.T) {
 		}
 	}
 }
+
+func TestCommentEnclosingInterval(t *testing.T) {
+	const src = `package p
+
+// Doc comment.
+//
+//go:noinline
+func f() {
+	//lint:ignore U1000   unused
+	x := 1 /* block */
+	_ = x
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf := fset.File(f.Package)
+	for _, test := range []struct {
+		substr  string
+		comment string // text of comment, or "" for none
+		dir     string // directive, as tool, name, args
+	}{
+		{"Doc", "// Doc comment.", ""},
+		{"noinline", "//go:noinline", "go noinline "},
+		{"U1000", "//lint:ignore U1000   unused", "lint ignore U1000   unused"},
+		{"block", "/* block */", ""},
+		{"x := 1", "", ""},
+		{"func f", "", ""},
+		{"unused\n\tx", "", ""}, // straddles the end of a comment
+	} {
+		i := strings.Index(src, test.substr)
+		if i < 0 {
+			t.Fatalf("%q is not a substring of input", test.substr)
+		}
+		start, end := tf.Pos(i), tf.Pos(i+len(test.substr))
+		group, c, d := astutil.CommentEnclosingInterval(f, start, end)
+
+		var got string
+		if c != nil {
+			got = c.Text
+			if !(group.Pos() <= c.Pos() && c.End() <= group.End()) {
+				t.Errorf("%q: comment is not within group", test.substr)
+			}
+		}
+		if got != test.comment {
+			t.Errorf("%q: got comment %q, want %q", test.substr, got, test.comment)
+		}
+		var gotDir string
+		if d != nil {
+			gotDir = fmt.Sprintf("%s %s %s", d.Tool, d.Name, d.Args)
+			if d.Pos != c.Pos() {
+				t.Errorf("%q: directive has wrong position", test.substr)
+			}
+		}
+		if gotDir != test.dir {
+			t.Errorf("%q: got directive %q, want %q", test.substr, gotDir, test.dir)
+		}
+	}
+
+	for _, text := range []string{"// go:noinline", "//Go:noinline", "//go:", "//:x", "/*go:noinline*/", "//line p.go:1"} {
+		if d, ok := astutil.ParseDirective(&ast.Comment{Text: text}); ok {
+			t.Errorf("ParseDirective(%q) = %+v, want failure", text, d)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"strconv"
 	"strings"
 )
@@ -488,3 +489,137 @@ func Imports(fset *token.FileSet, f *ast.File) [][]*ast.ImportSpec {
 
 	return groups
 }
+
+// EnsureImport ensures that file f imports the package of the
+// specified name and path, and returns the name by which the file may
+// refer to it.
+//
+// If the file already imports the package, other than by a blank or
+// dot import, EnsureImport returns the existing name. Otherwise it
+// adds an import, choosing the package name if it is free in f, or
+// else a variant of it with a numeric suffix, such as "fmt1". In that
+// case conflicts holds the identifiers of f that prevented use of the
+// package name; for an unnamed import of another package with that
+// name, the identifier is synthesized at the position of its path.
+//
+// The check for conflicts is syntactic and conservative: any
+// identifier of f with the same name, other than the selector of a
+// selector expression, is considered a conflict. Declarations in
+// other files of the package are not considered.
+func EnsureImport(fset *token.FileSet, f *ast.File, name, path string) (localName string, conflicts []*ast.Ident) {
+	for _, spec := range f.Imports {
+		if importPath(spec) == path {
+			switch n := importName(spec); n {
+			case "":
+				return name, nil
+			case "_", ".":
+				// Not usable in qualified identifiers.
+			default:
+				return n, nil
+			}
+		}
+	}
+
+	localName, conflicts = freeName(f, name, func(*ast.Ident) bool { return false })
+	if localName == name {
+		AddImport(fset, f, path)
+	} else {
+		AddNamedImport(fset, f, localName, path)
+	}
+	return localName, conflicts
+}
+
+// QualifyDotImport converts the dot import of path in file f, if any,
+// to a named import, and qualifies each reference in f to a member of
+// the imported package. It returns the chosen name, which is the
+// package name unless that would conflict with other identifiers of f,
+// as described at [EnsureImport], in which case conflicts holds them.
+// If f has no dot import of path, QualifyDotImport returns "".
+//
+// The file must have been type-checked, with the results recorded in
+// the Defs and Uses maps of info.
+func QualifyDotImport(f *ast.File, info *types.Info, path string) (name string, conflicts []*ast.Ident) {
+	var spec *ast.ImportSpec
+	for _, s := range f.Imports {
+		if importName(s) == "." && importPath(s) == path {
+			spec = s
+			break
+		}
+	}
+	if spec == nil {
+		return "", nil
+	}
+	pkgName, ok := info.Defs[spec.Name].(*types.PkgName)
+	if !ok {
+		panic("QualifyDotImport: file has not been type-checked")
+	}
+	pkg := pkgName.Imported()
+
+	// isMember reports whether id refers to a member of pkg.
+	isMember := func(id *ast.Ident) bool {
+		obj := info.Uses[id]
+		return obj != nil && obj.Pkg() == pkg && obj.Parent() == pkg.Scope()
+	}
+
+	name, conflicts = freeName(f, pkg.Name(), isMember)
+	if name == pkg.Name() {
+		spec.Name = nil
+	} else {
+		spec.Name.Name = name
+	}
+	Apply(f, func(c *Cursor) bool {
+		if _, ok := c.Parent().(*ast.SelectorExpr); ok && c.Name() == "Sel" {
+			return true // already qualified, e.g. by another import of pkg
+		}
+		if id, ok := c.Node().(*ast.Ident); ok && isMember(id) {
+			c.Replace(&ast.SelectorExpr{
+				X:   &ast.Ident{NamePos: id.NamePos, Name: name},
+				Sel: id,
+			})
+		}
+		return true
+	}, nil)
+	return name, conflicts
+}
+
+// freeName returns a name that is free in file f for use as the name
+// of an import: the preferred name if possible, or else a variant of
+// it with a numeric suffix, along with the identifiers that prevented
+// use of the preferred name. Identifiers for which ignore returns
+// true are not considered to conflict.
+func freeName(f *ast.File, preferred string, ignore func(*ast.Ident) bool) (string, []*ast.Ident) {
+	used := make(map[string][]*ast.Ident)
+	for _, spec := range f.Imports {
+		if spec.Name == nil {
+			// Guess the name of the imported package, as UsesImport does.
+			path := importPath(spec)
+			id := &ast.Ident{NamePos: spec.Path.Pos(), Name: path[strings.LastIndex(path, "/")+1:]}
+			used[id.Name] = append(used[id.Name], id)
+		}
+	}
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			if !ignore(n) {
+				used[n.Name] = append(used[n.Name], n)
+			}
+		}
+		return true
+	}
+	for _, decl := range f.Decls {
+		ast.Inspect(decl, visit)
+	}
+
+	name := preferred
+	for i := 1; used[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", preferred, i)
+	}
+	if name == preferred {
+		return name, nil
+	}
+	return name, used[preferred]
+}
@@ -8,8 +8,10 @@ import (
 	"bytes"
 	"go/ast"
 	"go/format"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"reflect"
 	"strconv"
 	"testing"
@@ -2126,3 +2128,152 @@ func TestUsesImport(t *testing.T) {
 		}
 	}
 }
+
+func TestEnsureImport(t *testing.T) {
+	for _, test := range []struct {
+		name, in, pkg, path string
+		wantName            string
+		wantConflicts       int
+		out                 string
+	}{
+		{
+			name: "existing",
+			in: `package foo
+
+import f "fmt"
+
+var _ = f.Sprint
+`,
+			pkg: "fmt", path: "fmt",
+			wantName: "f",
+			out: `package foo
+
+import f "fmt"
+
+var _ = f.Sprint
+`,
+		},
+		{
+			name: "free",
+			in: `package foo
+
+import _ "fmt"
+
+var x = 1
+`,
+			pkg: "fmt", path: "fmt",
+			wantName: "fmt",
+			out: `package foo
+
+import (
+	"fmt"
+	_ "fmt"
+)
+
+var x = 1
+`,
+		},
+		{
+			name: "conflict",
+			in: `package foo
+
+import "example.com/fmt"
+
+func f(fmt1 int) { fmt.Print(fmt1) }
+`,
+			pkg: "fmt", path: "fmt",
+			wantName:      "fmt2",
+			wantConflicts: 2, // the import and the use in f
+			out: `package foo
+
+import (
+	"example.com/fmt"
+	fmt2 "fmt"
+)
+
+func f(fmt1 int) { fmt.Print(fmt1) }
+`,
+		},
+	} {
+		f := parse(t, test.name, test.in)
+		name, conflicts := EnsureImport(fset, f, test.pkg, test.path)
+		if name != test.wantName || len(conflicts) != test.wantConflicts {
+			t.Errorf("%s: EnsureImport = %q, %d conflicts, want %q, %d", test.name, name, len(conflicts), test.wantName, test.wantConflicts)
+		}
+		if got := print(t, test.name, f); got != test.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", test.name, got, test.out)
+		}
+	}
+}
+
+func TestQualifyDotImport(t *testing.T) {
+	const in = `package foo
+
+import . "strings"
+
+func f(strings string) string { return ToUpper(strings) + TrimSpace(" x ") }
+`
+	const want = `package foo
+
+import strings1 "strings"
+
+func f(strings string) string { return strings1.ToUpper(strings) + strings1.TrimSpace(" x ") }
+`
+	f := parse(t, "dot", in)
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("foo", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+	name, conflicts := QualifyDotImport(f, info, "strings")
+	if name != "strings1" || len(conflicts) != 2 {
+		t.Errorf("QualifyDotImport = %q, %d conflicts, want %q, 2", name, len(conflicts), "strings1")
+	}
+	if got := print(t, "dot", f); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if name, _ := QualifyDotImport(f, info, "strings"); name != "" {
+		t.Errorf("second QualifyDotImport = %q, want none", name)
+	}
+}
+
+func TestQualifyDotImportQualified(t *testing.T) {
+	// References through another import of the package
+	// are already qualified.
+	const in = `package foo
+
+import (
+	"fmt"
+	. "fmt"
+)
+
+func f() { fmt.Println(Sprint(1)) }
+`
+	const want = `package foo
+
+import (
+	"fmt"
+	fmt1 "fmt"
+)
+
+func f() { fmt.Println(fmt1.Sprint(1)) }
+`
+	f := parse(t, "dot", in)
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("foo", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := QualifyDotImport(f, info, "fmt"); name != "fmt1" {
+		t.Errorf("QualifyDotImport = %q, want %q", name, "fmt1")
+	}
+	if got := print(t, "dot", f); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
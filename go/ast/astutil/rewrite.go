@@ -7,6 +7,7 @@ package astutil
 import (
 	"fmt"
 	"go/ast"
+	"go/types"
 	"reflect"
 	"sort"
 )
@@ -52,6 +53,32 @@ func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
 	return
 }
 
+// ApplyWithInfo is like [Apply] but additionally makes the type
+// information in info, which must describe the syntax tree rooted at
+// root, available through the TypeOf, ObjectOf, and Scope methods of
+// the Cursor.
+//
+// The Replace and Delete methods of the Cursor keep info consistent
+// with the modified tree: they remove the entries for the nodes that
+// are no longer part of it, and Replace records for the replacement
+// node the type, object, and selection of the node it replaces, if
+// the replacement has none of its own. The types of the nodes within
+// the replacement, and of nodes added by InsertBefore and InsertAfter,
+// are not computed; the client must record any that it needs.
+func ApplyWithInfo(root ast.Node, info *types.Info, pre, post ApplyFunc) (result ast.Node) {
+	parent := &struct{ ast.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+	a := &application{pre: pre, post: post, info: info}
+	a.cursor.app = a
+	a.apply(parent, "Node", nil, root)
+	return
+}
+
 var abort = new(int) // singleton, to signal termination of Apply
 
 // A Cursor describes a node encountered during Apply.
@@ -72,6 +99,7 @@ type Cursor struct {
 	name   string
 	iter   *iterator // valid if non-nil
 	node   ast.Node
+	app    *application // non-nil under ApplyWithInfo
 }
 
 // Node returns the current Node.
@@ -96,6 +124,39 @@ func (c *Cursor) Index() int {
 	return -1
 }
 
+// TypeOf returns the type of expression e, as by [types.Info.TypeOf],
+// or nil if not found. It returns nil if the traversal was not started
+// by [ApplyWithInfo].
+func (c *Cursor) TypeOf(e ast.Expr) types.Type {
+	if c.app == nil {
+		return nil
+	}
+	return c.app.info.TypeOf(e)
+}
+
+// ObjectOf returns the object denoted by the identifier id, as by
+// [types.Info.ObjectOf], or nil if not found. It returns nil if the
+// traversal was not started by [ApplyWithInfo].
+func (c *Cursor) ObjectOf(id *ast.Ident) types.Object {
+	if c.app == nil {
+		return nil
+	}
+	return c.app.info.ObjectOf(id)
+}
+
+// Scope returns the innermost scope recorded in info.Scopes for a
+// node enclosing the current Node, excluding any scope of the current
+// Node itself. The scope of a function, which is recorded for its
+// FuncType, encloses the function body too. Scope returns nil if no
+// enclosing node within the tree passed to [ApplyWithInfo] has a
+// scope, or if the traversal was not started by ApplyWithInfo.
+func (c *Cursor) Scope() *types.Scope {
+	if c.app == nil || len(c.app.scopes) == 0 {
+		return nil
+	}
+	return c.app.scopes[len(c.app.scopes)-1]
+}
+
 // field returns the current node's parent field value.
 func (c *Cursor) field() reflect.Value {
 	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
@@ -104,6 +165,9 @@ func (c *Cursor) field() reflect.Value {
 // Replace replaces the current Node with n.
 // The replacement node is not walked by Apply.
 func (c *Cursor) Replace(n ast.Node) {
+	if c.app != nil {
+		replaceInfo(c.app.info, c.node, n)
+	}
 	if _, ok := c.node.(*ast.File); ok {
 		file, ok := n.(*ast.File)
 		if !ok {
@@ -125,6 +189,9 @@ func (c *Cursor) Replace(n ast.Node) {
 // As a special case, if the current node is a package file,
 // Delete removes it from the package's Files map.
 func (c *Cursor) Delete() {
+	if c.app != nil {
+		forgetInfo(c.app.info, c.node, nil)
+	}
 	if _, ok := c.node.(*ast.File); ok {
 		delete(c.parent.(*ast.Package).Files, c.name)
 		return
@@ -179,6 +246,9 @@ type application struct {
 	pre, post ApplyFunc
 	cursor    Cursor
 	iter      iterator
+
+	info   *types.Info    // non-nil under ApplyWithInfo
+	scopes []*types.Scope // stack of scopes of enclosing nodes
 }
 
 func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.Node) {
@@ -199,6 +269,8 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		return
 	}
 
+	scoped := a.pushScope(n)
+
 	// walk children
 	// (the order of the cases matches the order of the corresponding node types in go/ast)
 	switch n := n.(type) {
@@ -233,7 +305,12 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 
 	case *ast.FuncLit:
 		a.apply(n, "Type", nil, n.Type)
+		// The function scope is that of the FuncType, but spans the body.
+		scoped := a.pushScope(n.Type)
 		a.apply(n, "Body", nil, n.Body)
+		if scoped {
+			a.popScope()
+		}
 
 	case *ast.CompositeLit:
 		a.apply(n, "Type", nil, n.Type)
@@ -424,7 +501,11 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.apply(n, "Recv", nil, n.Recv)
 		a.apply(n, "Name", nil, n.Name)
 		a.apply(n, "Type", nil, n.Type)
+		scoped := a.pushScope(n.Type)
 		a.apply(n, "Body", nil, n.Body)
+		if scoped {
+			a.popScope()
+		}
 
 	// Files and packages
 	case *ast.File:
@@ -449,6 +530,10 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
 	}
 
+	if scoped {
+		a.popScope()
+	}
+
 	if a.post != nil && !a.post(&a.cursor) {
 		panic(abort)
 	}
@@ -456,6 +541,24 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 	a.cursor = saved
 }
 
+// pushScope pushes the scope of node n, if any, onto the stack of
+// scopes of enclosing nodes, and reports whether it did so.
+func (a *application) pushScope(n ast.Node) bool {
+	if a.info == nil || n == nil {
+		return false
+	}
+	scope := a.info.Scopes[n]
+	if scope == nil {
+		return false
+	}
+	a.scopes = append(a.scopes, scope)
+	return true
+}
+
+func (a *application) popScope() {
+	a.scopes = a.scopes[:len(a.scopes)-1]
+}
+
 // An iterator controls iteration over a slice of nodes.
 type iterator struct {
 	index, step int
@@ -484,3 +587,77 @@ func (a *application) applyList(parent ast.Node, name string) {
 	}
 	a.iter = saved
 }
+
+// replaceInfo updates info for the replacement of node old by new.
+func replaceInfo(info *types.Info, old, new ast.Node) {
+	// Carry over the information about old to new.
+	if old, ok := old.(ast.Expr); ok {
+		if new, ok := new.(ast.Expr); ok {
+			if tv, ok := info.Types[old]; ok {
+				if _, ok := info.Types[new]; !ok {
+					info.Types[new] = tv
+				}
+			}
+		}
+	}
+	if old, ok := old.(*ast.Ident); ok {
+		if new, ok := new.(*ast.Ident); ok {
+			copyEntry(info.Defs, old, new)
+			copyEntry(info.Uses, old, new)
+			copyEntry(info.Instances, old, new)
+		}
+	}
+	if old, ok := old.(*ast.SelectorExpr); ok {
+		if new, ok := new.(*ast.SelectorExpr); ok {
+			copyEntry(info.Selections, old, new)
+		}
+	}
+
+	// Forget the nodes of old that are not reused by new.
+	keep := make(map[ast.Node]bool)
+	if new != nil {
+		ast.Inspect(new, func(n ast.Node) bool {
+			keep[n] = true
+			return true
+		})
+	}
+	forgetInfo(info, old, keep)
+}
+
+// copyEntry sets m[new] to m[old], unless m[new] is already set.
+func copyEntry[K comparable, V any](m map[K]V, old, new K) {
+	if v, ok := m[old]; ok {
+		if _, ok := m[new]; !ok {
+			m[new] = v
+		}
+	}
+}
+
+// forgetInfo deletes from info the entries for the nodes of the
+// syntax tree rooted at root, except those in keep.
+func forgetInfo(info *types.Info, root ast.Node, keep map[ast.Node]bool) {
+	if root == nil {
+		return
+	}
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil || keep[n] {
+			return false // a kept node is reused along with its subtree
+		}
+		if e, ok := n.(ast.Expr); ok {
+			delete(info.Types, e)
+		}
+		switch n := n.(type) {
+		case *ast.Ident:
+			delete(info.Defs, n)
+			delete(info.Uses, n)
+			delete(info.Instances, n)
+		case *ast.SelectorExpr:
+			delete(info.Selections, n)
+		case *ast.File:
+			delete(info.FileVersions, n)
+		}
+		delete(info.Implicits, n)
+		delete(info.Scopes, n)
+		return true
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"testing"
 
 	"golang.org/x/tools/go/ast/astutil"
@@ -266,6 +267,93 @@ func TestRewrite(t *testing.T) {
 	})
 }
 
+func TestApplyWithInfo(t *testing.T) {
+	const src = `package p
+
+func f(x int) int {
+	y := x * 2
+	return y
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:  make(map[ast.Expr]types.TypeAndValue),
+		Defs:   make(map[*ast.Ident]types.Object),
+		Uses:   make(map[*ast.Ident]types.Object),
+		Scopes: make(map[ast.Node]*types.Scope),
+	}
+	if _, err := new(types.Config).Check("p", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		bin   *ast.BinaryExpr
+		paren *ast.ParenExpr
+		oldY  *ast.Ident // use of y in return statement
+		newY  *ast.Ident // its replacement
+	)
+	astutil.ApplyWithInfo(f, info, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.BinaryExpr:
+			bin = n
+			if got := c.TypeOf(n); got != types.Typ[types.Int] {
+				t.Errorf("TypeOf(%s) = %v, want int", types.ExprString(n), got)
+			}
+			if scope := c.Scope(); scope == nil || scope.Lookup("x") == nil {
+				t.Errorf("Scope() at %s = %v, want function scope", types.ExprString(n), scope)
+			}
+			paren = &ast.ParenExpr{X: n}
+			c.Replace(paren)
+		case *ast.Ident:
+			if _, ok := c.Parent().(*ast.ReturnStmt); ok {
+				oldY = n
+				newY = ast.NewIdent("z")
+				if obj := c.ObjectOf(n); obj == nil || obj.Name() != "y" {
+					t.Errorf("ObjectOf(y) = %v", obj)
+				}
+				c.Replace(newY)
+			}
+		}
+		return true
+	}, nil)
+
+	if got := info.TypeOf(paren); got != types.Typ[types.Int] {
+		t.Errorf("after Replace, type of replacement = %v, want int", got)
+	}
+	if _, ok := info.Types[bin]; !ok {
+		t.Errorf("after Replace, type of reused %s was forgotten", types.ExprString(bin))
+	}
+	if obj := info.Uses[newY]; obj == nil || obj.Name() != "y" {
+		t.Errorf("after Replace, Uses[z] = %v, want y", obj)
+	}
+	if _, ok := info.Uses[oldY]; ok {
+		t.Errorf("after Replace, Uses still holds replaced identifier")
+	}
+	if _, ok := info.Types[oldY]; ok {
+		t.Errorf("after Replace, Types still holds replaced identifier")
+	}
+
+	// Delete the assignment y := (x * 2).
+	var defY *ast.Ident
+	astutil.ApplyWithInfo(f, info, func(c *astutil.Cursor) bool {
+		if assign, ok := c.Node().(*ast.AssignStmt); ok {
+			defY = assign.Lhs[0].(*ast.Ident)
+			c.Delete()
+		}
+		return true
+	}, nil)
+	if _, ok := info.Defs[defY]; ok {
+		t.Errorf("after Delete, Defs still holds deleted identifier")
+	}
+	if _, ok := info.Types[bin]; ok {
+		t.Errorf("after Delete, Types still holds deleted expression")
+	}
+}
+
 var sink ast.Node
 
 func BenchmarkRewrite(b *testing.B) {
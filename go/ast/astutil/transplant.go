@@ -0,0 +1,157 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package astutil
+
+// This file defines utilities for moving declarations between files.
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// TransplantDecl appends to file dst a copy of the declaration decl
+// of file src, along with copies of its comments, and returns the copy.
+//
+// Comments are not part of the syntax tree proper but are recorded in
+// File.Comments and printed according to their position, so moving a
+// declaration from one file to another usually loses or misplaces
+// them. TransplantDecl copies the comments that [ast.NewCommentMap]
+// associates with decl, including its doc comment, and re-bases the
+// positions of the copies so that they follow the existing content of
+// dst in the same layout as in src.
+//
+// The positions are allocated in a new token.File added to fset that
+// extends the token.File of dst, or the token.File added by the most
+// recent transplant into dst, so that successive transplants follow
+// one another. Both src and dst must have been
+// parsed using fset, and src is not modified. The copy does not share
+// any nodes with the original; its ast.Objects are nil.
+func TransplantDecl(fset *token.FileSet, dst, src *ast.File, decl ast.Decl) ast.Decl {
+	comments := ast.NewCommentMap(fset, src, src.Comments).Filter(decl).Comments()
+
+	// Compute the extent of decl and its comments,
+	// starting at the beginning of the line.
+	srcFile := fset.File(decl.Pos())
+	start, end := decl.Pos(), decl.End()
+	for _, cg := range comments {
+		if cg.Pos() < start {
+			start = cg.Pos()
+		}
+		if cg.End() > end {
+			end = cg.End()
+		}
+	}
+	startOffset := srcFile.Offset(srcFile.LineStart(srcFile.Line(start)))
+	endOffset := srcFile.Offset(end)
+
+	// The printer relates comments to nodes by offset and line,
+	// so the new file must continue the offsets and lines of dst,
+	// followed by those of the copied part of src.
+	dstFile := fset.File(lastPos(dst))
+	base := dstFile.Size() + 1
+	lines := append(dstFile.Lines(), base)
+	for _, line := range srcFile.Lines() {
+		if startOffset < line && line <= endOffset {
+			lines = append(lines, base+line-startOffset)
+		}
+	}
+	newFile := fset.AddFile(dstFile.Name(), -1, base+endOffset-startOffset+1)
+	newFile.SetLines(lines)
+
+	c := copier{
+		memo: make(map[any]reflect.Value),
+		pos: func(pos token.Pos) token.Pos {
+			if !pos.IsValid() || pos < start || pos > end {
+				return token.NoPos
+			}
+			return newFile.Pos(base + srcFile.Offset(pos) - startOffset)
+		},
+	}
+	newDecl := c.copy(reflect.ValueOf(decl)).Interface().(ast.Decl)
+	dst.Decls = append(dst.Decls, newDecl)
+	for _, cg := range comments {
+		dst.Comments = append(dst.Comments, c.copy(reflect.ValueOf(cg)).Interface().(*ast.CommentGroup))
+	}
+	return newDecl
+}
+
+// lastPos returns the greatest position of the declarations and
+// comments of f, or f.FileStart if there are none. Declarations
+// transplanted into f lie in later token.Files than its own.
+func lastPos(f *ast.File) token.Pos {
+	last := f.FileStart
+	for _, decl := range f.Decls {
+		if end := decl.End(); end > last {
+			last = end
+		}
+	}
+	for _, cg := range f.Comments {
+		if end := cg.End(); end > last {
+			last = end
+		}
+	}
+	return last
+}
+
+// A copier makes deep copies of syntax trees,
+// mapping their positions by the pos function.
+type copier struct {
+	memo map[any]reflect.Value // maps each original pointer to its copy
+	pos  func(token.Pos) token.Pos
+}
+
+var (
+	posType    = reflect.TypeOf(token.NoPos)
+	objectType = reflect.TypeOf((*ast.Object)(nil))
+	scopeType  = reflect.TypeOf((*ast.Scope)(nil))
+)
+
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || v.Type() == objectType || v.Type() == scopeType {
+			return reflect.Zero(v.Type())
+		}
+		if res, ok := c.memo[v.Interface()]; ok {
+			return res
+		}
+		res := reflect.New(v.Type().Elem())
+		c.memo[v.Interface()] = res
+		res.Elem().Set(c.copy(v.Elem()))
+		return res
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(c.copy(v.Elem()))
+		return res
+
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			res.Field(i).Set(c.copy(v.Field(i)))
+		}
+		return res
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return res
+
+	case reflect.Int:
+		if v.Type() == posType {
+			return reflect.ValueOf(c.pos(token.Pos(v.Int())))
+		}
+	}
+	return v
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package astutil_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+func TestTransplantDecl(t *testing.T) {
+	const src = `package a
+
+// Unrelated is not moved.
+var Unrelated int
+
+// F is moved.
+func F(x int) int {
+	// Double x.
+	y := x * 2
+
+	return y // result
+}
+
+// G is not moved.
+func G() {}
+`
+	const dst = `package b
+
+// H stays put.
+func H() {}
+`
+	const want = `package b
+
+// H stays put.
+func H() {}
+
+// F is moved.
+func F(x int) int {
+	// Double x.
+	y := x * 2
+
+	return y // result
+}
+`
+	fset := token.NewFileSet()
+	srcFile, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstFile, err := parser.ParseFile(fset, "b.go", dst, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decl ast.Decl
+	for _, d := range srcFile.Decls {
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Name.Name == "F" {
+			decl = d
+		}
+	}
+	newDecl := astutil.TransplantDecl(fset, dstFile, srcFile, decl)
+	if got := len(dstFile.Comments); got != 4 {
+		t.Errorf("destination has %d comment groups, want 4", got)
+	}
+	if fn := newDecl.(*ast.FuncDecl); fn.Doc == nil || fn.Doc != dstFile.Comments[1] {
+		t.Errorf("doc comment of copy is not the transplanted comment")
+	}
+	if newDecl == decl || newDecl.(*ast.FuncDecl).Body == decl.(*ast.FuncDecl).Body {
+		t.Errorf("copy shares nodes with original")
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, dstFile); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The source file is unchanged.
+	buf.Reset()
+	if err := format.Node(&buf, fset, srcFile); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != src {
+		t.Errorf("source file changed:\n%s", got)
+	}
+}
+
+func TestTransplantDeclSequence(t *testing.T) {
+	const src = `package a
+
+// A comes first.
+func A() {}
+
+// B comes second.
+var B = 1 // one
+
+// C comes third.
+type C struct {
+	// f is a field.
+	f int
+}
+`
+	const dst = `package b
+
+// H stays put.
+func H() {}
+`
+	const want = `package b
+
+// H stays put.
+func H() {}
+
+// C comes third.
+type C struct {
+	// f is a field.
+	f int
+}
+
+// A comes first.
+func A() {}
+
+// B comes second.
+var B = 1 // one
+`
+	fset := token.NewFileSet()
+	srcFile, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstFile, err := parser.ParseFile(fset, "b.go", dst, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Transplant the declarations out of order, so that
+	// their copies must not overlap with one another.
+	var prev ast.Decl = dstFile.Decls[0]
+	for _, i := range []int{2, 0, 1} {
+		newDecl := astutil.TransplantDecl(fset, dstFile, srcFile, srcFile.Decls[i])
+		if newDecl.Pos() <= prev.End() {
+			t.Errorf("copy of decl %d at %d does not follow previous decl ending at %d", i, newDecl.Pos(), prev.End())
+		}
+		prev = newDecl
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, dstFile); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -42,11 +42,28 @@ import (
 // (traversing) the syntax trees of a package.
 type Inspector struct {
 	events []event
+	idents map[string][]int32 // push events of identifiers, by name; nil unless built by NewWithIdentIndex
 }
 
 // New returns an Inspector for the specified syntax trees.
 func New(files []*ast.File) *Inspector {
-	return &Inspector{traverse(files)}
+	return &Inspector{events: traverse(files)}
+}
+
+// NewWithIdentIndex is like New, but the returned Inspector also
+// holds an index of identifiers by name, which makes [Inspector.Idents]
+// much faster at the cost of slower construction and more memory.
+// It is intended for analyzers that look for uses of a few specific
+// names, such as "Close" or "Lock".
+func NewWithIdentIndex(files []*ast.File) *Inspector {
+	in := New(files)
+	in.idents = make(map[string][]int32)
+	for i, ev := range in.events {
+		if id, ok := ev.node.(*ast.Ident); ok && ev.index > i {
+			in.idents[id.Name] = append(in.idents[id.Name], int32(i))
+		}
+	}
+	return in
 }
 
 // An event represents a push or a pop
@@ -177,6 +194,100 @@ func (in *Inspector) WithStack(types []ast.Node, f func(n ast.Node, push bool, s
 	}
 }
 
+// WithAncestors visits nodes in a similar manner to WithStack, but
+// calls f only before visiting the children of each node n whose type
+// is that of the last element of pattern and whose ancestors include
+// nodes of the types of the preceding elements, in order from
+// outermost to innermost, though not necessarily consecutively. For
+// example, the pattern
+//
+//	[]ast.Node{(*ast.FuncLit)(nil), (*ast.DeferStmt)(nil), (*ast.CallExpr)(nil)}
+//
+// matches each call within a defer statement within a function
+// literal. If f returns false, the children of n are not visited.
+//
+// Subtrees that cannot contain a match are skipped without being
+// visited, so WithAncestors is much faster than an equivalent
+// WithStack traversal that inspects the stack of each node.
+func (in *Inspector) WithAncestors(pattern []ast.Node, f func(n ast.Node, stack []ast.Node) (proceed bool)) {
+	if len(pattern) == 0 {
+		return
+	}
+	masks := make([]uint64, len(pattern))
+	for i, n := range pattern {
+		masks[i] = typeOf(n)
+	}
+	// need[k] is the union of the types of pattern[k:]:
+	// a subtree within which k elements of the pattern have
+	// been matched by ancestors must contain all of them.
+	need := make([]uint64, len(pattern))
+	for k := len(pattern) - 1; k >= 0; k-- {
+		need[k] = masks[k]
+		if k+1 < len(pattern) {
+			need[k] |= need[k+1]
+		}
+	}
+	last := len(pattern) - 1
+
+	var (
+		stack  []ast.Node
+		stages []int // stages[i] is the number of elements matched by stack[:i+1]
+	)
+	for i := 0; i < len(in.events); {
+		ev := in.events[i]
+		if ev.index > i {
+			// push
+			pop := ev.index
+			stage := 0
+			if len(stages) > 0 {
+				stage = stages[len(stages)-1]
+			}
+			stack = append(stack, ev.node)
+			if stage == last && ev.typ&masks[last] != 0 {
+				if !f(ev.node, stack) {
+					i = pop + 1
+					stack = stack[:len(stack)-1]
+					continue
+				}
+			} else if stage < last && ev.typ&masks[stage] != 0 {
+				stage++
+			}
+			if in.events[pop].typ&need[stage] != need[stage] {
+				// Subtree cannot contain a match: skip it.
+				i = pop + 1
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			stages = append(stages, stage)
+		} else {
+			// pop
+			stack = stack[:len(stack)-1]
+			stages = stages[:len(stages)-1]
+		}
+		i++
+	}
+}
+
+// Idents returns the identifiers with the specified name in the files
+// supplied to New, in depth-first order. If the Inspector was created
+// by [NewWithIdentIndex], Idents uses its index; otherwise, it
+// inspects every identifier.
+func (in *Inspector) Idents(name string) []*ast.Ident {
+	var idents []*ast.Ident
+	if in.idents != nil {
+		for _, i := range in.idents[name] {
+			idents = append(idents, in.events[i].node.(*ast.Ident))
+		}
+	} else {
+		in.Preorder([]ast.Node{(*ast.Ident)(nil)}, func(n ast.Node) {
+			if id := n.(*ast.Ident); id.Name == name {
+				idents = append(idents, id)
+			}
+		})
+	}
+	return idents
+}
+
 // traverse builds the table of events representing a traversal.
 func traverse(files []*ast.File) []event {
 	// Preallocate approximate number of events
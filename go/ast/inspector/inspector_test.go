@@ -161,6 +161,104 @@ func TestInspectPruning(t *testing.T) {
 }
 
 // compare calls t.Error if !slices.Equal(nodesA, nodesB).
+func TestWithAncestors(t *testing.T) {
+	inspect := inspector.New(netFiles)
+	for _, pattern := range [][]ast.Node{
+		{new(ast.FuncLit), new(ast.DeferStmt), new(ast.CallExpr)},
+		{new(ast.FuncDecl), new(ast.GoStmt)},
+		{new(ast.CallExpr), new(ast.CallExpr)},
+		{new(ast.Ident)},
+	} {
+		// Compute the expected matches by brute force.
+		var want []ast.Node
+		inspect.WithStack(nil, func(n ast.Node, push bool, stack []ast.Node) bool {
+			last := len(pattern) - 1
+			if push && reflect.TypeOf(n) == reflect.TypeOf(pattern[last]) {
+				k := 0
+				for _, anc := range stack[:len(stack)-1] {
+					if k < last && reflect.TypeOf(anc) == reflect.TypeOf(pattern[k]) {
+						k++
+					}
+				}
+				if k == last {
+					want = append(want, n)
+				}
+			}
+			return true
+		})
+
+		var got []ast.Node
+		inspect.WithAncestors(pattern, func(n ast.Node, stack []ast.Node) bool {
+			if stack[len(stack)-1] != n {
+				t.Errorf("innermost element of stack is %T, want %T", stack[len(stack)-1], n)
+			}
+			got = append(got, n)
+			return true
+		})
+		if len(want) == 0 {
+			t.Errorf("pattern %T matches nothing; test is ineffective", pattern)
+		}
+		compare(t, got, want)
+	}
+}
+
+func TestPreorderParallel(t *testing.T) {
+	inspect := inspector.New(netFiles)
+	for _, types := range [][]ast.Node{nil, {new(ast.CallExpr)}, {new(ast.FuncDecl), new(ast.Ident)}} {
+		var want []ast.Node
+		inspect.Preorder(types, func(n ast.Node) {
+			want = append(want, n)
+		})
+		got := inspector.PreorderParallel(inspect, types,
+			func(acc []ast.Node, n ast.Node) []ast.Node { return append(acc, n) },
+			func(x, y []ast.Node) []ast.Node { return append(x, y...) })
+		compare(t, got, want)
+	}
+
+	// No files.
+	if n := inspector.PreorderParallel(inspector.New(nil), nil,
+		func(acc int, n ast.Node) int { return acc + 1 },
+		func(x, y int) int { return x + y }); n != 0 {
+		t.Errorf("PreorderParallel over no files = %d, want 0", n)
+	}
+}
+
+func TestIdents(t *testing.T) {
+	plain := inspector.New(netFiles)
+	indexed := inspector.NewWithIdentIndex(netFiles)
+	for _, name := range []string{"Close", "err", "_", "noSuchName"} {
+		var want []*ast.Ident
+		for _, f := range netFiles {
+			ast.Inspect(f, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && id.Name == name {
+					want = append(want, id)
+				}
+				return true
+			})
+		}
+		compare(t, plain.Idents(name), want)
+		compare(t, indexed.Idents(name), want)
+	}
+}
+
+func BenchmarkIdents(b *testing.B) {
+	for _, test := range []struct {
+		name string
+		new  func([]*ast.File) *inspector.Inspector
+	}{
+		{"plain", inspector.New},
+		{"indexed", inspector.NewWithIdentIndex},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			inspect := test.new(netFiles)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				inspect.Idents("Close")
+			}
+		})
+	}
+}
+
 func compare[N comparable](t *testing.T, nodesA, nodesB []N) {
 	if len(nodesA) != len(nodesB) {
 		t.Errorf("inconsistent node lists: %d vs %d", len(nodesA), len(nodesB))
@@ -0,0 +1,201 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildutil
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// A FakeModule is a module of the fake workspace of FakeModuleContext.
+type FakeModule struct {
+	Path    string            // module path
+	Version string            // module version, or "" for the main module
+	Files   map[string]string // file contents, by slash-separated name relative to the module root
+}
+
+// FakeModuleContext returns a build.Context for a fake workspace
+// consisting of a main module, the one whose Version is empty, and the
+// modules it requires, directly or indirectly.
+//
+// If a module has no go.mod file, one is synthesized: the go.mod file
+// of the main module then requires the latest version of each other
+// module. Requirements are resolved as the go command would, by
+// selecting the greatest required version of each module; a required
+// module version absent from modules provides no packages. The main
+// module is also given a go.sum file, if it has none, that records
+// the true hashes of the selected module versions.
+//
+// As with FakeContext, the fake Context has a GOROOT of "/go" and no
+// GOPATH, and each package appears in the directory /go/src/path,
+// where path is its import path. The package of an import path is
+// provided by the selected module with the longest path that is a
+// prefix of it, so that a nested module hides the directory of the
+// enclosing module it replaces. The go.mod and go.sum files appear
+// in the directory of the module's root package.
+//
+// FakeModuleContext panics if modules does not contain exactly one
+// main module, or if a go.mod file cannot be parsed.
+func FakeModuleContext(modules ...FakeModule) *build.Context {
+	var main *FakeModule
+	byPath := make(map[string]map[string]*FakeModule) // module path -> version -> module
+	for i := range modules {
+		m := &modules[i]
+		if m.Version == "" {
+			if main != nil {
+				panic(fmt.Sprintf("FakeModuleContext: two main modules, %s and %s", main.Path, m.Path))
+			}
+			main = m
+			continue
+		}
+		if byPath[m.Path] == nil {
+			byPath[m.Path] = make(map[string]*FakeModule)
+		}
+		byPath[m.Path][m.Version] = m
+	}
+	if main == nil {
+		panic("FakeModuleContext: no main module")
+	}
+
+	// Synthesize the missing go.mod files.
+	goMod := make(map[*FakeModule]string)
+	for i := range modules {
+		m := &modules[i]
+		if data, ok := m.Files["go.mod"]; ok {
+			goMod[m] = data
+		} else if m != main {
+			goMod[m] = fmt.Sprintf("module %s\n", m.Path)
+		}
+	}
+	if _, ok := goMod[main]; !ok {
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "module %s\n\ngo %s\n", main.Path, goVersion())
+		var paths []string
+		for p := range byPath {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		if len(paths) > 0 {
+			buf.WriteString("\nrequire (\n")
+			for _, p := range paths {
+				var latest string
+				for v := range byPath[p] {
+					if latest == "" || semver.Compare(v, latest) > 0 {
+						latest = v
+					}
+				}
+				fmt.Fprintf(&buf, "\t%s %s\n", p, latest)
+			}
+			buf.WriteString(")\n")
+		}
+		goMod[main] = buf.String()
+	}
+
+	// Select the greatest required version of each module,
+	// adding the requirements of each newly selected one.
+	selected := make(map[string]string) // module path -> version
+	queue := []*FakeModule{main}
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		f, err := modfile.ParseLax(path.Join(m.Path, "go.mod"), []byte(goMod[m]), nil)
+		if err != nil {
+			panic(fmt.Sprintf("FakeModuleContext: %v", err))
+		}
+		for _, r := range f.Require {
+			p, v := r.Mod.Path, r.Mod.Version
+			if p == main.Path || semver.Compare(v, selected[p]) <= 0 {
+				continue
+			}
+			selected[p] = v
+			if dep := byPath[p][v]; dep != nil {
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	// Assign each package to the selected module that provides it.
+	list := []*FakeModule{main}
+	for p, v := range selected {
+		if m := byPath[p][v]; m != nil {
+			list = append(list, m)
+		}
+	}
+	provider := func(importPath string) *FakeModule {
+		var best *FakeModule
+		for _, m := range list {
+			if (importPath == m.Path || strings.HasPrefix(importPath, m.Path+"/")) &&
+				(best == nil || len(m.Path) > len(best.Path)) {
+				best = m
+			}
+		}
+		return best
+	}
+	pkgs := make(map[string]map[string]string)
+	addFile := func(importPath, base, content string) {
+		if pkgs[importPath] == nil {
+			pkgs[importPath] = make(map[string]string)
+		}
+		pkgs[importPath][base] = content
+	}
+	for _, m := range list {
+		for name, content := range m.Files {
+			dir, base := path.Split(name)
+			importPath := path.Join(m.Path, dir)
+			if provider(importPath) == m {
+				addFile(importPath, base, content)
+			}
+		}
+		addFile(m.Path, "go.mod", goMod[m])
+	}
+	if _, ok := main.Files["go.sum"]; !ok {
+		addFile(main.Path, "go.sum", goSum(list[1:], goMod))
+	}
+	return FakeContext(pkgs)
+}
+
+// goVersion returns the language version of the default build
+// context, such as "1.22".
+func goVersion() string {
+	tags := build.Default.ReleaseTags
+	if len(tags) == 0 {
+		return "1.22"
+	}
+	return strings.TrimPrefix(tags[len(tags)-1], "go")
+}
+
+// goSum returns the contents of a go.sum file for the module versions
+// mods, whose go.mod files are given by goMod.
+func goSum(mods []*FakeModule, goMod map[*FakeModule]string) string {
+	hash := func(files map[string]string, prefix string) string {
+		var names []string
+		for name := range files {
+			names = append(names, prefix+name)
+		}
+		h, err := dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(files[strings.TrimPrefix(name, prefix)])), nil
+		})
+		if err != nil {
+			panic(err) // can't happen: open never fails
+		}
+		return h
+	}
+	var lines []string
+	for _, m := range mods {
+		lines = append(lines,
+			fmt.Sprintf("%s %s %s\n", m.Path, m.Version, hash(m.Files, m.Path+"@"+m.Version+"/")),
+			fmt.Sprintf("%s %s/go.mod %s\n", m.Path, m.Version, hash(map[string]string{"go.mod": goMod[m]}, "")))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
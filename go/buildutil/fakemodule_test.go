@@ -0,0 +1,133 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildutil_test
+
+import (
+	"go/build"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+func TestFakeModuleContext(t *testing.T) {
+	ctxt := buildutil.FakeModuleContext(
+		buildutil.FakeModule{
+			Path: "example.com/m",
+			Files: map[string]string{
+				"go.mod": "module example.com/m\n\nrequire example.com/a v1.0.0\n",
+				"m.go":   `package m; import _ "example.com/a"`,
+				"p/p.go": `package p; import _ "example.com/b/sub"`,
+			},
+		},
+		buildutil.FakeModule{
+			Path:    "example.com/a",
+			Version: "v1.0.0",
+			Files: map[string]string{
+				"go.mod": "module example.com/a\n\nrequire example.com/b v1.1.0\n",
+				"a.go":   "package a",
+			},
+		},
+		buildutil.FakeModule{
+			Path:    "example.com/b",
+			Version: "v1.0.0",
+			Files:   map[string]string{"b.go": "package b // v1.0.0"},
+		},
+		buildutil.FakeModule{
+			Path:    "example.com/b",
+			Version: "v1.1.0",
+			Files: map[string]string{
+				"b.go":        "package b // v1.1.0",
+				"sub/sub.go":  "package sub // from example.com/b",
+				"other/o.go":  "package other",
+				"c/c.go":      "package c",
+				"c/c_test.go": "package c",
+			},
+		},
+		buildutil.FakeModule{
+			Path:    "example.com/b/sub",
+			Version: "v0.1.0",
+			Files:   map[string]string{"sub.go": "package sub // from example.com/b/sub"},
+		},
+		buildutil.FakeModule{
+			Path:    "example.com/unused",
+			Version: "v1.0.0",
+			Files:   map[string]string{"u.go": "package unused"},
+		},
+	)
+
+	// The greatest required version of example.com/b is selected,
+	// and example.com/b/sub, required by nothing, provides no packages.
+	for _, test := range []struct{ path, file, want string }{
+		{"example.com/m", "m.go", "package m"},
+		{"example.com/m/p", "p.go", "package p"},
+		{"example.com/a", "a.go", "package a"},
+		{"example.com/b", "b.go", "v1.1.0"},
+		{"example.com/b/sub", "sub.go", "from example.com/b"},
+		{"example.com/b/c", "c.go", "package c"},
+	} {
+		bp, err := ctxt.Import(test.path, "", 0)
+		if err != nil {
+			t.Errorf("Import(%q): %v", test.path, err)
+			continue
+		}
+		if got := read(t, ctxt, bp.Dir+"/"+test.file); !strings.Contains(got, test.want) {
+			t.Errorf("%s/%s = %q, want it to contain %q", test.path, test.file, got, test.want)
+		}
+	}
+	if _, err := ctxt.Import("example.com/unused", "", 0); err == nil {
+		t.Errorf("Import(example.com/unused) succeeded for an unrequired module")
+	}
+
+	// The go.sum file records the selected versions only.
+	sum := read(t, ctxt, "/go/src/example.com/m/go.sum")
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(sum), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "h1:") {
+			t.Fatalf("invalid go.sum line %q", line)
+		}
+		got = append(got, fields[0]+" "+fields[1])
+	}
+	want := []string{
+		"example.com/a v1.0.0",
+		"example.com/a v1.0.0/go.mod",
+		"example.com/b v1.1.0",
+		"example.com/b v1.1.0/go.mod",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("go.sum records:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFakeModuleContextSynthesizedGoMod(t *testing.T) {
+	ctxt := buildutil.FakeModuleContext(
+		buildutil.FakeModule{Path: "example.com/m", Files: map[string]string{"m.go": "package m"}},
+		buildutil.FakeModule{Path: "example.com/a", Version: "v1.2.0", Files: map[string]string{"a.go": "package a"}},
+		buildutil.FakeModule{Path: "example.com/a", Version: "v1.10.0", Files: map[string]string{"a.go": "package a // latest"}},
+	)
+	goMod := read(t, ctxt, "/go/src/example.com/m/go.mod")
+	if !strings.HasPrefix(goMod, "module example.com/m\n") || !strings.Contains(goMod, "\texample.com/a v1.10.0\n") {
+		t.Errorf("synthesized go.mod:\n%s", goMod)
+	}
+	if got := read(t, ctxt, "/go/src/example.com/a/a.go"); got != "package a // latest" {
+		t.Errorf("a.go = %q, want the latest version", got)
+	}
+}
+
+func read(t *testing.T, ctxt *build.Context, filename string) string {
+	t.Helper()
+	rc, err := buildutil.OpenFile(ctxt, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -37,6 +37,8 @@ package callgraph // import "golang.org/x/tools/go/callgraph"
 import (
 	"fmt"
 	"go/token"
+	"go/types"
+	"strings"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -115,6 +117,109 @@ func (e Edge) Pos() token.Pos {
 	return e.Site.Pos()
 }
 
+// An EdgeKind is a set of bits describing how the call of an edge is
+// resolved. Each edge has exactly one of the kinds StaticCall,
+// InterfaceCall, FuncValueCall, ReflectCall, and SyntheticCall, and may
+// also have one of the kinds GoCall and DeferCall.
+type EdgeKind uint16
+
+const (
+	StaticCall    EdgeKind = 1 << iota // call of a statically known function
+	InterfaceCall                      // dynamic dispatch of an interface method
+	FuncValueCall                      // dynamic call of a function value
+	ReflectCall                        // call made by reflection, e.g. by reflect.Value.Call
+	SyntheticCall                      // call with no site other than by reflection, e.g. from the root
+	GoCall                             // call in a go statement
+	DeferCall                          // call in a defer statement
+
+	// DynamicCall is the set of kinds of calls whose callee is
+	// not statically known.
+	DynamicCall = InterfaceCall | FuncValueCall | ReflectCall
+)
+
+var edgeKindNames = [...]string{"static", "interface", "funcvalue", "reflect", "synthetic", "go", "defer"}
+
+// String returns the names of the kinds in k, separated by "|",
+// for example "interface|go".
+func (k EdgeKind) String() string {
+	var names []string
+	for i, name := range edgeKindNames {
+		if k&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if k >= 1<<len(edgeKindNames) {
+		names = append(names, fmt.Sprintf("%#x", uint16(k&^(1<<len(edgeKindNames)-1))))
+	}
+	return strings.Join(names, "|")
+}
+
+// Kind returns the kind of the edge, which is a function of its site,
+// and so is available for the call graphs of all algorithms.
+func (e Edge) Kind() EdgeKind {
+	switch site := e.Site.(type) {
+	case nil:
+		if e.Caller != nil && isReflectCall(e.Caller.Func) {
+			return ReflectCall
+		}
+		return SyntheticCall
+	case *ssa.Go:
+		return GoCall | callKind(site.Common())
+	case *ssa.Defer:
+		return DeferCall | callKind(site.Common())
+	default:
+		return callKind(site.Common())
+	}
+}
+
+func callKind(common *ssa.CallCommon) EdgeKind {
+	switch {
+	case common.IsInvoke():
+		return InterfaceCall
+	case common.StaticCallee() != nil:
+		return StaticCall
+	default:
+		return FuncValueCall
+	}
+}
+
+// isReflectCall reports whether fn is a method of reflect.Value that
+// calls a function.
+func isReflectCall(fn *ssa.Function) bool {
+	if fn == nil || fn.Pkg == nil || fn.Pkg.Pkg.Path() != "reflect" {
+		return false
+	}
+	recv := fn.Signature.Recv()
+	return recv != nil && (fn.Name() == "Call" || fn.Name() == "CallSlice") &&
+		types.TypeString(recv.Type(), nil) == "reflect.Value"
+}
+
+// KeepEdges removes from g every edge that has none of the specified
+// kinds. For example, g.KeepEdges(DynamicCall) retains only the
+// dynamic calls. Nodes are not removed, even if left without edges.
+func (g *Graph) KeepEdges(kinds EdgeKind) {
+	for _, n := range g.Nodes {
+		out := n.Out[:0]
+		for _, e := range n.Out {
+			if e.Kind()&kinds != 0 {
+				out = append(out, e)
+			}
+		}
+		clear(n.Out[len(out):]) // aid GC
+		n.Out = out
+	}
+	for _, n := range g.Nodes {
+		in := n.In[:0]
+		for _, e := range n.In {
+			if e.Kind()&kinds != 0 {
+				in = append(in, e)
+			}
+		}
+		clear(n.In[len(in):]) // aid GC
+		n.In = in
+	}
+}
+
 // AddEdge adds the edge (caller, site, callee) to the call graph.
 // Elimination of duplicate edges is the caller's responsibility.
 func AddEdge(caller *Node, site ssa.CallInstruction, callee *Node) {
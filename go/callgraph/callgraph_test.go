@@ -4,6 +4,7 @@
 package callgraph_test
 
 import (
+	"reflect"
 	"sync"
 	"testing"
 
@@ -229,3 +230,73 @@ func reaches(source *ssa.Function, cg *callgraph.Graph, refs bool) map[*ssa.Func
 	visit(source)
 	return seen
 }
+
+const kindsEx = `
+-- go.mod --
+module x.io
+
+-- main.go --
+package main
+
+type I interface{ m() }
+
+type T int
+
+func (T) m() {}
+
+func f() {}
+
+var g = f
+
+func main() {
+	var i I = T(0)
+	f()
+	i.m()
+	g()
+	go f()
+	defer i.m()
+}
+`
+
+func TestEdgeKind(t *testing.T) {
+	pkgs := testfiles.LoadPackages(t, txtar.Parse([]byte(kindsEx)), ".")
+	prog, ssapkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+	main := ssapkgs[0].Members["main"].(*ssa.Function)
+
+	cg := cha.CallGraph(prog)
+	kinds := func() map[string]bool {
+		m := make(map[string]bool)
+		for _, e := range cg.Nodes[main].Out {
+			m[e.Site.String()+": "+e.Kind().String()] = true
+		}
+		return m
+	}
+	want := map[string]bool{
+		"f(): static":                          true,
+		"invoke t0.m(): interface":             true,
+		"t3(): funcvalue":                      true, // g()
+		"go f(): static|go":                    true,
+		"defer invoke t0.m(): interface|defer": true,
+	}
+	if got := kinds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("edge kinds = %v, want %v", got, want)
+	}
+
+	cg.KeepEdges(callgraph.DynamicCall)
+	want = map[string]bool{
+		"invoke t0.m(): interface":             true,
+		"t3(): funcvalue":                      true,
+		"defer invoke t0.m(): interface|defer": true,
+	}
+	if got := kinds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("edge kinds after KeepEdges(DynamicCall) = %v, want %v", got, want)
+	}
+	for _, n := range cg.Nodes {
+		for _, e := range n.In {
+			if e.Kind()&callgraph.DynamicCall == 0 {
+				t.Errorf("KeepEdges(DynamicCall) kept incoming edge %s of kind %s", e, e.Kind())
+			}
+		}
+	}
+}
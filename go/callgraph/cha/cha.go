@@ -37,41 +37,72 @@ func CallGraph(prog *ssa.Program) *callgraph.Graph {
 	cg := callgraph.New(nil) // TODO(adonovan) eliminate concept of rooted callgraph
 
 	allFuncs := ssautil.AllFunctions(prog)
+	for f := range allFuncs {
+		cg.CreateNode(f)
+	}
 
-	calleesOf := lazyCallees(allFuncs)
+	// Because every call to a highly polymorphic and
+	// frequently used abstract method such as
+	// (io.Writer).Write is assumed to call every concrete
+	// Write method in the program, the call graph can
+	// contain a lot of duplication.
+	edges(allFuncs, nil, func(site ssa.CallInstruction, g *ssa.Function) bool {
+		callgraph.AddEdge(cg.Nodes[site.Parent()], site, cg.CreateNode(g))
+		return true
+	})
 
-	addEdge := func(fnode *callgraph.Node, site ssa.CallInstruction, g *ssa.Function) {
-		gnode := cg.CreateNode(g)
-		callgraph.AddEdge(fnode, site, gnode)
-	}
+	return cg
+}
 
-	addEdges := func(fnode *callgraph.Node, site ssa.CallInstruction, callees []*ssa.Function) {
-		// Because every call to a highly polymorphic and
-		// frequently used abstract method such as
-		// (io.Writer).Write is assumed to call every concrete
-		// Write method in the program, the call graph can
-		// contain a lot of duplication.
-		for _, g := range callees {
-			addEdge(fnode, site, g)
-		}
-	}
+// Edges calls emit for each edge of the call graph of the specified
+// program computed by the Class Hierarchy Analysis algorithm, stopping
+// if emit returns false. The caller of each edge is site.Parent().
+// The edges are emitted in no particular order, and an edge may be
+// emitted more than once if a function contains several identical
+// call sites.
+//
+// Unlike [CallGraph], Edges does not materialize the graph, whose size
+// may be quadratic in that of the program, so its memory use is
+// bounded by that of the program and the implements relation. The
+// caller may process each edge as it is emitted, for example by
+// writing it to a file.
+//
+// If filter is non-nil, only the calls made by functions for which it
+// returns true are considered; calls may nonetheless be resolved to
+// functions anywhere in the program. A filter on packages can thus
+// cheaply restrict the graph to the calls made by the code of interest.
+func Edges(prog *ssa.Program, filter func(*ssa.Function) bool, emit func(site ssa.CallInstruction, callee *ssa.Function) bool) {
+	edges(ssautil.AllFunctions(prog), filter, emit)
+}
+
+// edges is the implementation of [Edges] for the set of all functions.
+func edges(allFuncs map[*ssa.Function]bool, filter func(*ssa.Function) bool, emit func(ssa.CallInstruction, *ssa.Function) bool) {
+	calleesOf := lazyCallees(allFuncs)
 
 	for f := range allFuncs {
-		fnode := cg.CreateNode(f)
+		if filter != nil && !filter(f) {
+			continue
+		}
 		for _, b := range f.Blocks {
 			for _, instr := range b.Instrs {
-				if site, ok := instr.(ssa.CallInstruction); ok {
-					if g := site.Common().StaticCallee(); g != nil {
-						addEdge(fnode, site, g)
-					} else {
-						addEdges(fnode, site, calleesOf(site))
+				site, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				if g := site.Common().StaticCallee(); g != nil {
+					if !emit(site, g) {
+						return
+					}
+				} else {
+					for _, g := range calleesOf(site) {
+						if !emit(site, g) {
+							return
+						}
 					}
 				}
 			}
 		}
 	}
-
-	return cg
 }
 
 var lazyCallees = chautil.LazyCallees
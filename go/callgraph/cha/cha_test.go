@@ -70,6 +70,60 @@ func TestCHA(t *testing.T) {
 	}
 }
 
+// TestEdges tests that Edges emits the edges of CallGraph, restricted
+// to the callers accepted by the filter.
+func TestEdges(t *testing.T) {
+	for _, filename := range inputs {
+		pkg, ssapkg := loadFile(t, filename, ssa.InstantiateGenerics)
+		prog := ssapkg.Prog
+
+		type edge struct {
+			site   ssa.CallInstruction
+			callee *ssa.Function
+		}
+		want := make(map[edge]bool)
+		callgraph.GraphVisitEdges(cha.CallGraph(prog), func(e *callgraph.Edge) error {
+			if e.Caller.Func.Pkg == ssapkg {
+				want[edge{e.Site, e.Callee.Func}] = true
+			}
+			return nil
+		})
+
+		got := make(map[edge]bool)
+		filter := func(f *ssa.Function) bool { return f.Pkg == ssapkg }
+		cha.Edges(prog, filter, func(site ssa.CallInstruction, callee *ssa.Function) bool {
+			if site.Parent().Pkg != ssapkg {
+				t.Errorf("%s: Edges emitted call from %s, which is rejected by the filter", filename, site.Parent())
+			}
+			got[edge{site, callee}] = true
+			return true
+		})
+		if len(want) == 0 {
+			t.Errorf("%s: no edges from package %s", filename, pkg.Types.Path())
+		}
+		for e := range want {
+			if !got[e] {
+				t.Errorf("%s: Edges did not emit %s --> %s", filename, e.site.Parent(), e.callee)
+			}
+		}
+		for e := range got {
+			if !want[e] {
+				t.Errorf("%s: Edges emitted spurious %s --> %s", filename, e.site.Parent(), e.callee)
+			}
+		}
+
+		// Edges stops when emit returns false.
+		n := 0
+		cha.Edges(prog, nil, func(ssa.CallInstruction, *ssa.Function) bool {
+			n++
+			return false
+		})
+		if n != 1 {
+			t.Errorf("%s: Edges called emit %d times, want 1 as it returned false", filename, n)
+		}
+	}
+}
+
 // TestCHAGenerics is TestCHA tailored for testing generics,
 func TestCHAGenerics(t *testing.T) {
 	filename := "testdata/generics.go"
@@ -73,6 +73,8 @@ type Result struct {
 	// Types *A, A and B are accessible to reflection, but the unnamed
 	// type struct{B} is not.
 	RuntimeTypes typeutil.Map
+
+	r *rta // working state, for AddRoots; nil unless from AnalyzeIncremental
 }
 
 // Working state of the RTA algorithm.
@@ -306,6 +308,18 @@ func (r *rta) visitFunc(f *ssa.Function) {
 // graph; otherwise, only the other fields (reachable functions) are
 // populated.
 func Analyze(roots []*ssa.Function, buildCallGraph bool) *Result {
+	res := AnalyzeIncremental(roots, buildCallGraph)
+	if res != nil {
+		res.r = nil // release the working state
+	}
+	return res
+}
+
+// AnalyzeIncremental is like [Analyze], but the result retains the
+// working state of the analysis, so that more roots may be added to it
+// later by [Result.AddRoots]. The working state is comparable in size to
+// the result, so clients that do not add roots should use Analyze.
+func AnalyzeIncremental(roots []*ssa.Function, buildCallGraph bool) *Result {
 	if len(roots) == 0 {
 		return nil
 	}
@@ -337,7 +351,30 @@ func Analyze(roots []*ssa.Function, buildCallGraph bool) *Result {
 	r.concreteTypes.SetHasher(hasher)
 	r.interfaceTypes.SetHasher(hasher)
 
+	r.result.r = r
+	r.result.AddRoots(roots...)
+	return r.result
+}
+
+// AddRoots extends the results of the analysis with the functions,
+// runtime types, and call graph edges that are reachable from the
+// specified additional roots, as if they had been among the roots
+// passed to [AnalyzeIncremental]. Only the code newly found to be
+// reachable is analyzed, so a client that analyzes a program from
+// several roots, such as the main functions of several tests, may add
+// them one at a time at little more cost than analyzing them all at once.
+//
+// The result must have been returned by AnalyzeIncremental, and the
+// roots must belong to the same program as those passed to it.
+func (res *Result) AddRoots(roots ...*ssa.Function) {
+	r := res.r
+	if r == nil {
+		panic("rta: AddRoots called on a Result not returned by AnalyzeIncremental")
+	}
 	for _, root := range roots {
+		if g := res.CallGraph; g != nil {
+			g.CreateNode(root)
+		}
 		r.addReachable(root, false)
 	}
 
@@ -351,7 +388,6 @@ func Analyze(roots []*ssa.Function, buildCallGraph bool) *Result {
 			r.visitFunc(f)
 		}
 	}
-	return r.result
 }
 
 // interfaces(C) returns all currently known interfaces implemented by C.
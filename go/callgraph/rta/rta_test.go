@@ -82,10 +82,47 @@ func TestRTA(t *testing.T) {
 			}, true)
 
 			check(t, f, mainPkg, res)
+
+			// Adding the roots one at a time yields the same result.
+			res = rta.AnalyzeIncremental([]*ssa.Function{mainPkg.Func("main")}, true)
+			res.AddRoots(mainPkg.Func("init"))
+
+			check(t, f, mainPkg, res)
 		})
 	}
 }
 
+// TestAddRoots tests that AddRoots extends the results of an analysis
+// with the functions reachable from the new roots.
+func TestAddRoots(t *testing.T) {
+	ar, err := txtar.ParseFile("testdata/func.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs := testfiles.LoadPackages(t, ar, "./...")
+	prog, spkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+	mainPkg := spkgs[0]
+	b := mainPkg.Func("B")
+
+	res := rta.AnalyzeIncremental([]*ssa.Function{mainPkg.Func("main")}, true)
+	if _, ok := res.Reachable[b]; ok {
+		t.Fatalf("B is reachable from main")
+	}
+	n := len(res.Reachable)
+
+	res.AddRoots(b)
+	if _, ok := res.Reachable[b]; !ok {
+		t.Errorf("B is not reachable after AddRoots(B)")
+	}
+	if len(res.Reachable) != n+1 {
+		t.Errorf("AddRoots(B) added %d reachable functions, want 1", len(res.Reachable)-n)
+	}
+	if res.CallGraph.Nodes[b] == nil {
+		t.Errorf("call graph has no node for B after AddRoots(B)")
+	}
+}
+
 // check tests the RTA analysis results against the test expectations
 // defined by a comment starting with a line "WANT:".
 //
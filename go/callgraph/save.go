@@ -0,0 +1,182 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// A SavedGraph is the serializable form of a call graph, which does
+// not depend on an ssa.Program, so that a graph may be computed once,
+// saved by [Save], and later loaded by [Load], perhaps by another
+// process or a program written in another language.
+//
+// Functions are identified by their names as printed by
+// [ssa.Function.String], such as "(*example.com/p.T).Method", which
+// are stable across runs on the same source code; positions are
+// formatted as by [token.Position.String].
+//
+// The JSON encoding of a SavedGraph is its file format.
+type SavedGraph struct {
+	Nodes []SavedNode `json:"nodes"`
+	Edges []SavedEdge `json:"edges"`
+}
+
+// A SavedNode is a function of a SavedGraph.
+type SavedNode struct {
+	Func string `json:"func"`          // name of the function
+	Pos  string `json:"pos,omitempty"` // position of the function, if any
+}
+
+// A SavedEdge is a call edge of a SavedGraph.
+type SavedEdge struct {
+	Caller int    `json:"caller"`        // index of the caller in Nodes
+	Callee int    `json:"callee"`        // index of the callee in Nodes
+	Kind   string `json:"kind"`          // kind of the edge, as by EdgeKind.String
+	Pos    string `json:"pos,omitempty"` // position of the call site, if any
+}
+
+// Save writes the call graph g to w in the JSON encoding of a
+// [SavedGraph]. Nodes are sorted by name and edges by caller, callee
+// and position, so the output is deterministic. The node of a graph
+// whose Root.Func is nil is omitted.
+func Save(w io.Writer, g *Graph) error {
+	var fset *token.FileSet
+	var nodes []*Node
+	for fn, n := range g.Nodes {
+		if fn != nil {
+			nodes = append(nodes, n)
+			fset = fn.Prog.Fset
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Func.String() < nodes[j].Func.String() })
+
+	position := func(pos token.Pos) string {
+		if !pos.IsValid() {
+			return ""
+		}
+		return fset.Position(pos).String()
+	}
+	var saved SavedGraph
+	index := make(map[*Node]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+		saved.Nodes = append(saved.Nodes, SavedNode{Func: n.Func.String(), Pos: position(n.Func.Pos())})
+	}
+	for _, n := range nodes {
+		for _, e := range n.Out {
+			callee, ok := index[e.Callee]
+			if !ok {
+				continue // edge to nil root (unlikely)
+			}
+			saved.Edges = append(saved.Edges, SavedEdge{
+				Caller: index[n],
+				Callee: callee,
+				Kind:   e.Kind().String(),
+				Pos:    position(e.Pos()),
+			})
+		}
+	}
+	sort.SliceStable(saved.Edges, func(i, j int) bool {
+		x, y := saved.Edges[i], saved.Edges[j]
+		if x.Caller != y.Caller {
+			return x.Caller < y.Caller
+		}
+		if x.Callee != y.Callee {
+			return x.Callee < y.Callee
+		}
+		return x.Pos < y.Pos
+	})
+	return json.NewEncoder(w).Encode(&saved)
+}
+
+// Load reads a call graph in the format written by [Save].
+func Load(r io.Reader) (*SavedGraph, error) {
+	saved := new(SavedGraph)
+	if err := json.NewDecoder(r).Decode(saved); err != nil {
+		return nil, err
+	}
+	for i, e := range saved.Edges {
+		if e.Caller < 0 || e.Caller >= len(saved.Nodes) || e.Callee < 0 || e.Callee >= len(saved.Nodes) {
+			return nil, fmt.Errorf("edge %d refers to undefined node", i)
+		}
+	}
+	return saved, nil
+}
+
+// Graph returns the call graph of prog described by the saved graph,
+// which must have been computed from the same source code as prog.
+// Each function is resolved by name among the functions of prog, and
+// each edge is associated with the call instruction of its caller at
+// its position and of its kind; edges without such an instruction,
+// such as those of calls made by reflection, have a nil site. The
+// resulting graph has a root node with a nil function.
+//
+// Graph returns an error if a function or call site cannot be found.
+func (saved *SavedGraph) Graph(prog *ssa.Program) (*Graph, error) {
+	funcs := make(map[string]*ssa.Function)
+	for fn := range ssautil.AllFunctions(prog) {
+		funcs[fn.String()] = fn
+	}
+	g := New(nil)
+	nodes := make([]*Node, len(saved.Nodes))
+	var missing []string
+	for i, sn := range saved.Nodes {
+		fn, ok := funcs[sn.Func]
+		if !ok {
+			missing = append(missing, sn.Func)
+			continue
+		}
+		nodes[i] = g.CreateNode(fn)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("functions not found in program: %s", strings.Join(missing, ", "))
+	}
+
+	// sites maps each caller to its call instructions by position.
+	sites := make(map[*ssa.Function]map[string][]ssa.CallInstruction)
+	for _, e := range saved.Edges {
+		caller, callee := nodes[e.Caller], nodes[e.Callee]
+		m, ok := sites[caller.Func]
+		if !ok {
+			m = make(map[string][]ssa.CallInstruction)
+			for _, b := range caller.Func.Blocks {
+				for _, instr := range b.Instrs {
+					if call, ok := instr.(ssa.CallInstruction); ok {
+						var posn string
+						if pos := call.Pos(); pos.IsValid() {
+							posn = prog.Fset.Position(pos).String()
+						}
+						m[posn] = append(m[posn], call)
+					}
+				}
+			}
+			sites[caller.Func] = m
+		}
+		// Several calls may share a position, as in the
+		// synthetic code of wrappers. Choose the first one
+		// whose kind matches.
+		var site ssa.CallInstruction
+		for _, call := range m[e.Pos] {
+			if (Edge{caller, call, callee}).Kind().String() == e.Kind {
+				site = call
+				break
+			}
+		}
+		if site == nil && e.Pos != "" {
+			return nil, fmt.Errorf("no %s call in %s at %s", e.Kind, caller.Func, e.Pos)
+		}
+		AddEdge(caller, site, callee)
+	}
+	return g, nil
+}
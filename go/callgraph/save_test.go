@@ -0,0 +1,88 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/internal/testfiles"
+	"golang.org/x/tools/txtar"
+)
+
+func TestSaveLoad(t *testing.T) {
+	pkgs := testfiles.LoadPackages(t, txtar.Parse([]byte(kindsEx)), ".")
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	cg := cha.CallGraph(prog)
+	var buf bytes.Buffer
+	if err := callgraph.Save(&buf, cg); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	saved, err := callgraph.Load(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Nodes) == 0 || len(saved.Edges) == 0 {
+		t.Fatalf("saved graph has %d nodes and %d edges", len(saved.Nodes), len(saved.Edges))
+	}
+	cg2, err := saved.Graph(prog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The loaded graph has the same edges, including their sites.
+	type edge struct {
+		caller *ssa.Function
+		site   ssa.CallInstruction
+		callee *ssa.Function
+	}
+	edgeSet := func(g *callgraph.Graph) map[edge]bool {
+		m := make(map[edge]bool)
+		for _, n := range g.Nodes {
+			for _, e := range n.Out {
+				m[edge{e.Caller.Func, e.Site, e.Callee.Func}] = true
+			}
+		}
+		return m
+	}
+	want, got := edgeSet(cg), edgeSet(cg2)
+	for e := range want {
+		if !got[e] {
+			t.Errorf("loaded graph lacks edge %s --> %s", e.caller, e.callee)
+		}
+	}
+	for e := range got {
+		if !want[e] {
+			t.Errorf("loaded graph has spurious edge %s --> %s", e.caller, e.callee)
+		}
+	}
+
+	// Saving the loaded graph yields the same output.
+	buf.Reset()
+	if err := callgraph.Save(&buf, cg2); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != data {
+		t.Errorf("saving the loaded graph yields:\n%s\nwant:\n%s", buf.String(), data)
+	}
+
+	// Loading fails on a graph that doesn't match the program.
+	bad := &callgraph.SavedGraph{Nodes: []callgraph.SavedNode{{Func: "x.io.nonesuch"}}}
+	if _, err := bad.Graph(prog); err == nil {
+		t.Errorf("Graph of saved graph with unknown function succeeded")
+	}
+	if _, err := callgraph.Load(strings.NewReader(`{"nodes": [], "edges": [{"caller": 0, "callee": 1}]}`)); err == nil {
+		t.Errorf("Load of graph with undefined nodes succeeded")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph
+
+import "sort"
+
+// SCCs returns the strongly connected components of the call graph g.
+// Two functions belong to the same component if each may (transitively)
+// call the other.
+//
+// Components are returned in reverse topological order: a component
+// precedes those from which it is called. The nodes of each
+// component are ordered by ID.
+func SCCs(g *Graph) [][]*Node {
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	// Tarjan's algorithm.
+	var (
+		sccs    [][]*Node
+		stack   []*Node
+		index   = make(map[*Node]int) // preorder number, from 1
+		lowlink = make(map[*Node]int)
+		onStack = make(map[*Node]bool)
+	)
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		index[n] = len(index) + 1
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, e := range n.Out {
+			m := e.Callee
+			if index[m] == 0 {
+				visit(m)
+				lowlink[n] = min(lowlink[n], lowlink[m])
+			} else if onStack[m] {
+				lowlink[n] = min(lowlink[n], index[m])
+			}
+		}
+
+		if lowlink[n] == index[n] {
+			var scc []*Node
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				scc = append(scc, m)
+				if m == n {
+					break
+				}
+			}
+			sort.Slice(scc, func(i, j int) bool { return scc[i].ID < scc[j].ID })
+			sccs = append(sccs, scc)
+		}
+	}
+	for _, n := range nodes {
+		if index[n] == 0 {
+			visit(n)
+		}
+	}
+	return sccs
+}
+
+// A Condensation is the directed acyclic graph obtained from a call
+// graph by contracting each strongly connected component to a single
+// node.
+type Condensation struct {
+	// Components holds the strongly connected components,
+	// in reverse topological order, as returned by [SCCs].
+	Components [][]*Node
+
+	// Component maps each node to the index of its component.
+	Component map[*Node]int
+
+	// Succs holds, for each component, the indices of the other
+	// components called from it, in increasing order. Since the
+	// components are in reverse topological order, each successor
+	// index is less than that of the component.
+	Succs [][]int
+}
+
+// Condense returns the condensation of the call graph g.
+func Condense(g *Graph) *Condensation {
+	c := &Condensation{
+		Components: SCCs(g),
+		Component:  make(map[*Node]int, len(g.Nodes)),
+	}
+	for i, scc := range c.Components {
+		for _, n := range scc {
+			c.Component[n] = i
+		}
+	}
+	c.Succs = make([][]int, len(c.Components))
+	for i, scc := range c.Components {
+		seen := make(map[int]bool)
+		for _, n := range scc {
+			for _, e := range n.Out {
+				if j := c.Component[e.Callee]; j != i && !seen[j] {
+					seen[j] = true
+					c.Succs[i] = append(c.Succs[i], j)
+				}
+			}
+		}
+		sort.Ints(c.Succs[i])
+	}
+	return c
+}
+
+// A RecursionGroup is a set of mutually recursive functions:
+// a strongly connected component of the call graph that contains
+// a cycle, that is, either several functions or a single function
+// that calls itself.
+type RecursionGroup struct {
+	// Funcs holds the functions of the group, ordered by ID.
+	Funcs []*Node
+
+	// Entries holds the functions of the group that are called from
+	// outside it, ordered by ID. It is empty if the group is not
+	// called from outside, for example because it contains the root.
+	Entries []*Node
+}
+
+// RecursionGroups returns the recursion groups of the call graph g,
+// in reverse topological order.
+func RecursionGroups(g *Graph) []*RecursionGroup {
+	var groups []*RecursionGroup
+	for _, scc := range SCCs(g) {
+		if len(scc) == 1 && !callsItself(scc[0]) {
+			continue
+		}
+		members := make(map[*Node]bool, len(scc))
+		for _, n := range scc {
+			members[n] = true
+		}
+		group := &RecursionGroup{Funcs: scc}
+		for _, n := range scc {
+			for _, e := range n.In {
+				if !members[e.Caller] {
+					group.Entries = append(group.Entries, n)
+					break
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func callsItself(n *Node) bool {
+	for _, e := range n.Out {
+		if e.Callee == n {
+			return true
+		}
+	}
+	return false
+}
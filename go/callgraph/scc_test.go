@@ -0,0 +1,90 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph"
+)
+
+func TestSCCs(t *testing.T) {
+	g, nodes := makeGraph(
+		"main a", "main f",
+		"a b", "b c", "c a", // recursion group {a, b, c}
+		"b d", "d d", // self-recursive d
+		"f g", "g e", "e g", // recursion group {e, g} entered at g
+		"x y", // x is not reachable from main
+	)
+	names := make(map[*callgraph.Node]string)
+	for name, n := range nodes {
+		names[n] = name
+	}
+	// str returns a sorted list of the names of nodes,
+	// omitting the root, which has no name.
+	str := func(nodes []*callgraph.Node) string {
+		var s []string
+		for _, n := range nodes {
+			if name := names[n]; name != "" {
+				s = append(s, name)
+			}
+		}
+		sort.Strings(s)
+		return strings.Join(s, " ")
+	}
+
+	// Each component must precede its callers.
+	sccs := callgraph.SCCs(g)
+	pos := make(map[*callgraph.Node]int)
+	var got []string
+	for i, scc := range sccs {
+		for _, n := range scc {
+			pos[n] = i
+		}
+		if s := str(scc); s != "" {
+			got = append(got, s)
+		}
+	}
+	sort.Strings(got)
+	if want := "[a b c d e g f main x y]"; fmt.Sprint(got) != want {
+		t.Errorf("SCCs = %v, want %s", got, want)
+	}
+	for _, n := range g.Nodes {
+		for _, e := range n.Out {
+			if pos[e.Callee] > pos[n] {
+				t.Errorf("component of callee %s follows that of caller %s", names[e.Callee], names[n])
+			}
+		}
+	}
+
+	c := callgraph.Condense(g)
+	if len(c.Components) != len(sccs) {
+		t.Errorf("Condense has %d components, want %d", len(c.Components), len(sccs))
+	}
+	abc := c.Component[nodes["a"]]
+	var succs []string
+	for _, j := range c.Succs[abc] {
+		if j >= abc {
+			t.Errorf("successor %d of component %d is not lower", j, abc)
+		}
+		succs = append(succs, str(c.Components[j]))
+	}
+	if want := "[d]"; fmt.Sprint(succs) != want {
+		t.Errorf("successors of {a, b, c} = %v, want %s", succs, want)
+	}
+
+	got = nil
+	for _, group := range callgraph.RecursionGroups(g) {
+		got = append(got, fmt.Sprintf("{%s} via {%s}", str(group.Funcs), str(group.Entries)))
+	}
+	sort.Strings(got)
+	want := "[{a b c} via {a} {d} via {d} {e g} via {g}]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("RecursionGroups = %v, want %s", got, want)
+	}
+}
@@ -24,21 +24,50 @@ import (
 // It does not consider exportedness, nor treat main packages specially.
 func CallGraph(prog *ssa.Program) *callgraph.Graph {
 	cg := callgraph.New(nil)
+	edges(prog,
+		func(f *ssa.Function) { cg.CreateNode(f) },
+		func(site ssa.CallInstruction, g *ssa.Function) bool {
+			callgraph.AddEdge(cg.CreateNode(site.Parent()), site, cg.CreateNode(g))
+			return true
+		})
+	return cg
+}
 
+// Edges calls emit for each edge of the static call graph of the
+// specified program, stopping if emit returns false. The caller of
+// each edge is site.Parent(). The functions of the graph, and thus its
+// edges, are those described at [CallGraph].
+//
+// Unlike [CallGraph], Edges does not materialize the graph: it records
+// only the set of functions visited, so the caller may process each
+// edge as it is found, for example by writing it to a
+// [callgraph.EdgeWriter].
+func Edges(prog *ssa.Program, emit func(site ssa.CallInstruction, callee *ssa.Function) bool) {
+	edges(prog, func(*ssa.Function) {}, emit)
+}
+
+// edges is the implementation of [CallGraph] and [Edges]. It calls
+// visit for each function of the graph, before emitting its edges.
+func edges(prog *ssa.Program, visit func(*ssa.Function), emit func(ssa.CallInstruction, *ssa.Function) bool) {
 	// Recursively follow all static calls.
-	seen := make(map[int]bool) // node IDs already seen
-	var visit func(fnode *callgraph.Node)
-	visit = func(fnode *callgraph.Node) {
-		if !seen[fnode.ID] {
-			seen[fnode.ID] = true
+	seen := make(map[*ssa.Function]bool)
+	stopped := false
+	var visitFunc func(f *ssa.Function)
+	visitFunc = func(f *ssa.Function) {
+		if !seen[f] && !stopped {
+			seen[f] = true
+			visit(f)
 
-			for _, b := range fnode.Func.Blocks {
+			for _, b := range f.Blocks {
 				for _, instr := range b.Instrs {
 					if site, ok := instr.(ssa.CallInstruction); ok {
 						if g := site.Common().StaticCallee(); g != nil {
-							gnode := cg.CreateNode(g)
-							callgraph.AddEdge(fnode, site, gnode)
-							visit(gnode)
+							if !emit(site, g) {
+								stopped = true
+							}
+							if visitFunc(g); stopped {
+								return
+							}
 						}
 					}
 				}
@@ -54,7 +83,7 @@ func CallGraph(prog *ssa.Program) *callgraph.Graph {
 	// rootNames := []string{"init", "main"}
 	// for _, main := range ssautil.MainPackages(prog.AllPackages()) {
 	// 	for _, rootName := range rootNames {
-	// 		visit(cg.CreateNode(main.Func(rootName)))
+	// 		visitFunc(main.Func(rootName))
 	// 	}
 	// }
 	//
@@ -73,7 +102,7 @@ func CallGraph(prog *ssa.Program) *callgraph.Graph {
 		if !types.IsInterface(T) {
 			mset := prog.MethodSets.MethodSet(T)
 			for i := 0; i < mset.Len(); i++ {
-				visit(cg.CreateNode(prog.MethodValue(mset.At(i))))
+				visitFunc(prog.MethodValue(mset.At(i)))
 			}
 		}
 	}
@@ -84,7 +113,7 @@ func CallGraph(prog *ssa.Program) *callgraph.Graph {
 			switch mem := mem.(type) {
 			case *ssa.Function:
 				// package-level function
-				visit(cg.CreateNode(mem))
+				visitFunc(mem)
 
 			case *ssa.Type:
 				// methods of package-level non-interface non-parameterized types
@@ -98,6 +127,4 @@ func CallGraph(prog *ssa.Program) *callgraph.Graph {
 			}
 		}
 	}
-
-	return cg
 }
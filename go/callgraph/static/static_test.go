@@ -136,3 +136,37 @@ func TestStatic(t *testing.T) {
 		}
 	}
 }
+
+func TestEdges(t *testing.T) {
+	pkgs := testfiles.LoadPackages(t, txtar.Parse([]byte(input)), "./p")
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	type edge struct {
+		site   ssa.CallInstruction
+		callee *ssa.Function
+	}
+	want := make(map[edge]bool)
+	callgraph.GraphVisitEdges(static.CallGraph(prog), func(e *callgraph.Edge) error {
+		want[edge{e.Site, e.Callee.Func}] = true
+		return nil
+	})
+	got := make(map[edge]bool)
+	static.Edges(prog, func(site ssa.CallInstruction, callee *ssa.Function) bool {
+		got[edge{site, callee}] = true
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges emitted %v, want the edges of CallGraph, %v", got, want)
+	}
+
+	// Edges stops when emit returns false.
+	n := 0
+	static.Edges(prog, func(ssa.CallInstruction, *ssa.Function) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Edges called emit %d times, want 1 as it returned false", n)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// A StreamedEdge is a call edge in the stream format written by an
+// [EdgeWriter] and read by [ReadEdges].
+//
+// The stream is in the JSON Lines format: each line is the JSON
+// encoding of one StreamedEdge. Unlike a [SavedGraph], a stream has no
+// table of nodes, so each edge names its functions, as printed by
+// [ssa.Function.String], and edges may be written and read one at a
+// time, in any order. The same edge may appear more than once.
+// Positions are formatted as by token.Position.String.
+type StreamedEdge struct {
+	Caller string `json:"caller"`        // name of the calling function
+	Callee string `json:"callee"`        // name of the called function
+	Kind   string `json:"kind"`          // kind of the edge, as by EdgeKind.String
+	Pos    string `json:"pos,omitempty"` // position of the call site, if any
+}
+
+// An EdgeWriter writes call edges to an io.Writer in the stream format
+// described at [StreamedEdge].
+//
+// Its Emit method may be passed directly to a function that computes
+// the edges of a call graph incrementally, such as
+// [golang.org/x/tools/go/callgraph/cha.Edges], so that the edges are
+// written as they are found, without constructing the graph in memory:
+//
+//	w := bufio.NewWriter(out)
+//	ew := callgraph.NewEdgeWriter(w)
+//	cha.Edges(prog, nil, ew.Emit)
+//	if err := ew.Err(); err != nil { ... }
+//	if err := w.Flush(); err != nil { ... }
+//
+// Each edge is written by a single call to the underlying writer,
+// so it is usually wise to buffer it.
+type EdgeWriter struct {
+	enc *json.Encoder
+	err error
+}
+
+// NewEdgeWriter returns an EdgeWriter that writes to w.
+func NewEdgeWriter(w io.Writer) *EdgeWriter {
+	return &EdgeWriter{enc: json.NewEncoder(w)}
+}
+
+// Emit writes the edge from site.Parent() to callee. It returns false
+// if the edge could not be written, in which case Err reports the
+// error and subsequent calls do nothing.
+func (ew *EdgeWriter) Emit(site ssa.CallInstruction, callee *ssa.Function) bool {
+	if ew.err != nil {
+		return false
+	}
+	caller := site.Parent()
+	edge := StreamedEdge{
+		Caller: caller.String(),
+		Callee: callee.String(),
+		Kind:   Edge{Site: site}.Kind().String(),
+	}
+	if pos := site.Pos(); pos.IsValid() {
+		edge.Pos = caller.Prog.Fset.Position(pos).String()
+	}
+	ew.err = ew.enc.Encode(&edge)
+	return ew.err == nil
+}
+
+// Err returns the first error encountered while writing edges, if any.
+func (ew *EdgeWriter) Err() error {
+	return ew.err
+}
+
+// ReadEdges reads a stream of edges written by an [EdgeWriter],
+// calling f for each of them in order. It stops at the end of the
+// stream, returning nil, or at the first error, including one
+// returned by f.
+func ReadEdges(r io.Reader, f func(StreamedEdge) error) error {
+	dec := json.NewDecoder(r)
+	for i := 0; ; i++ {
+		var edge StreamedEdge
+		if err := dec.Decode(&edge); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("edge %d: %v", i, err)
+		}
+		if edge.Caller == "" || edge.Callee == "" {
+			return fmt.Errorf("edge %d lacks a caller or callee", i)
+		}
+		if err := f(edge); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/internal/testfiles"
+	"golang.org/x/tools/txtar"
+)
+
+func TestEdgeWriter(t *testing.T) {
+	pkgs := testfiles.LoadPackages(t, txtar.Parse([]byte(kindsEx)), ".")
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	// The streamed edges are those of the call graph.
+	want := make(map[callgraph.StreamedEdge]bool)
+	callgraph.GraphVisitEdges(cha.CallGraph(prog), func(e *callgraph.Edge) error {
+		edge := callgraph.StreamedEdge{
+			Caller: e.Caller.Func.String(),
+			Callee: e.Callee.Func.String(),
+			Kind:   e.Kind().String(),
+		}
+		if pos := e.Pos(); pos.IsValid() {
+			edge.Pos = prog.Fset.Position(pos).String()
+		}
+		want[edge] = true
+		return nil
+	})
+
+	var buf bytes.Buffer
+	ew := callgraph.NewEdgeWriter(&buf)
+	cha.Edges(prog, nil, ew.Emit)
+	if err := ew.Err(); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[callgraph.StreamedEdge]bool)
+	if err := callgraph.ReadEdges(&buf, func(edge callgraph.StreamedEdge) error {
+		got[edge] = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(want) == 0 {
+		t.Fatal("no edges")
+	}
+	for edge := range want {
+		if !got[edge] {
+			t.Errorf("stream lacks edge %v", edge)
+		}
+	}
+	for edge := range got {
+		if !want[edge] {
+			t.Errorf("stream has spurious edge %v", edge)
+		}
+	}
+
+	// Writing stops at the first error.
+	ew = callgraph.NewEdgeWriter(failingWriter{})
+	n := 0
+	cha.Edges(prog, nil, func(site ssa.CallInstruction, callee *ssa.Function) bool {
+		n++
+		return ew.Emit(site, callee)
+	})
+	if n != 1 || ew.Err() == nil {
+		t.Errorf("Emit was called %d times with error %v, want once with an error", n, ew.Err())
+	}
+
+	// Reading fails on a malformed edge.
+	for _, input := range []string{
+		`{"caller": "f"}`,
+		`{"caller": "f", "callee": "g"} {`,
+	} {
+		err := callgraph.ReadEdges(strings.NewReader(input), func(callgraph.StreamedEdge) error { return nil })
+		if err == nil {
+			t.Errorf("ReadEdges(%q) succeeded, want error", input)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
@@ -75,6 +75,157 @@ func PathSearch(start *Node, isEnd func(*Node) bool) []*Edge {
 	return search(start)
 }
 
+// ShortestPaths returns up to k shortest distinct paths from node
+// start to node end, each as an ordered list of edges, in order of
+// increasing length. The paths are simple: no node occurs twice in a
+// path. Paths are distinct if they differ in any edge, so two calls
+// from one function to another in different places yield distinct
+// paths. If start is end, the sole result is the empty path.
+// ShortestPaths returns nil if end is not reachable from start.
+//
+// Ties between paths of the same length are broken deterministically,
+// according to the order of the Out lists of the nodes.
+func ShortestPaths(start, end *Node, k int) [][]*Edge {
+	if k <= 0 {
+		return nil
+	}
+	first := bfsPath(start, end, nil, nil)
+	if first == nil {
+		return nil
+	}
+	paths := [][]*Edge{first}
+	if len(first) == 0 {
+		return paths
+	}
+
+	// This is Yen's algorithm: each path after the first is the
+	// shortest deviation, at some node, from the prefix of one of the
+	// paths already found.
+	var candidates [][]*Edge
+	contains := func(list [][]*Edge, path []*Edge) bool {
+		for _, p := range list {
+			if equalPaths(p, path) {
+				return true
+			}
+		}
+		return false
+	}
+	for len(paths) < k {
+		prev := paths[len(paths)-1]
+		for i := range prev {
+			// Deviate from prev at its ith node.
+			spur := prev[i].Caller
+			root := prev[:i]
+
+			// Forbid the edges by which the paths sharing the
+			// root leave the spur node, and the nodes of the root
+			// other than the spur node itself.
+			removedEdges := make(map[*Edge]bool)
+			for _, p := range paths {
+				if len(p) > i && equalPaths(p[:i], root) {
+					removedEdges[p[i]] = true
+				}
+			}
+			removedNodes := make(map[*Node]bool)
+			for _, e := range root {
+				removedNodes[e.Caller] = true
+			}
+
+			if tail := bfsPath(spur, end, removedNodes, removedEdges); tail != nil {
+				path := append(append([]*Edge(nil), root...), tail...)
+				if !contains(paths, path) && !contains(candidates, path) {
+					candidates = append(candidates, path)
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		best := 0
+		for i, c := range candidates {
+			if len(c) < len(candidates[best]) {
+				best = i
+			}
+		}
+		paths = append(paths, candidates[best])
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+	return paths
+}
+
+// bfsPath returns a shortest path from start to end that avoids the
+// specified nodes and edges, or nil if there is none.
+func bfsPath(start, end *Node, removedNodes map[*Node]bool, removedEdges map[*Edge]bool) []*Edge {
+	// pred maps each encountered node to the edge by which it was
+	// reached, or to nil for start.
+	pred := map[*Node]*Edge{start: nil}
+	queue := []*Node{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == end {
+			path := []*Edge{} // non-nil in case start is end
+			for e := pred[n]; e != nil; e = pred[e.Caller] {
+				path = append(path, e)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, e := range n.Out {
+			if _, ok := pred[e.Callee]; ok || removedEdges[e] || removedNodes[e.Callee] {
+				continue
+			}
+			pred[e.Callee] = e
+			queue = append(queue, e.Callee)
+		}
+	}
+	return nil
+}
+
+func equalPaths(x, y []*Edge) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ReachingRoots returns the nodes for which isRoot returns true and
+// from which node n is reachable, including n itself if it is a root,
+// in order of increasing distance from n. If isRoot is nil, the roots
+// are the nodes that have no callers.
+//
+// Together with [ShortestPaths], it answers the question of why a
+// function is reachable: which entry points can call it, and how.
+func ReachingRoots(n *Node, isRoot func(*Node) bool) []*Node {
+	if isRoot == nil {
+		isRoot = func(n *Node) bool { return len(n.In) == 0 }
+	}
+	var roots []*Node
+	seen := map[*Node]bool{n: true}
+	queue := []*Node{n}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if isRoot(n) {
+			roots = append(roots, n)
+		}
+		for _, e := range n.In {
+			if !seen[e.Caller] {
+				seen[e.Caller] = true
+				queue = append(queue, e.Caller)
+			}
+		}
+	}
+	return roots
+}
+
 // DeleteSyntheticNodes removes from call graph g all nodes for
 // functions that do not correspond to source syntax. For historical
 // reasons, nodes for g.Root and package initializers are always
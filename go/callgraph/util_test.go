@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// makeGraph returns a call graph with the specified edges,
+// each of the form "caller callee", and a map from names to nodes.
+func makeGraph(edges ...string) (*callgraph.Graph, map[string]*callgraph.Node) {
+	g := callgraph.New(nil)
+	nodes := make(map[string]*callgraph.Node)
+	node := func(name string) *callgraph.Node {
+		n := nodes[name]
+		if n == nil {
+			n = g.CreateNode(new(ssa.Function))
+			nodes[name] = n
+		}
+		return n
+	}
+	for _, e := range edges {
+		caller, callee, _ := strings.Cut(e, " ")
+		callgraph.AddEdge(node(caller), nil, node(callee))
+	}
+	return g, nodes
+}
+
+func TestShortestPaths(t *testing.T) {
+	_, nodes := makeGraph(
+		"main a", "main b", "main c",
+		"a f", "b a", "b f", "c d", "d f",
+		"f main", // a cycle
+	)
+	names := make(map[*callgraph.Node]string)
+	for name, n := range nodes {
+		names[n] = name
+	}
+	pathString := func(start *callgraph.Node, path []*callgraph.Edge) string {
+		s := names[start]
+		for _, e := range path {
+			s += " " + names[e.Callee]
+		}
+		return s
+	}
+
+	for _, test := range []struct {
+		start, end string
+		k          int
+		want       []string
+	}{
+		{"main", "f", 1, []string{"main a f"}},
+		{"main", "f", 3, []string{"main a f", "main b f", "main c d f"}},
+		{"main", "f", 10, []string{"main a f", "main b f", "main c d f", "main b a f"}},
+		{"a", "b", 10, []string{"a f main b"}},
+		{"d", "d", 2, []string{"d"}},
+		{"main", "f", 0, nil},
+	} {
+		var got []string
+		for _, path := range callgraph.ShortestPaths(nodes[test.start], nodes[test.end], test.k) {
+			got = append(got, pathString(nodes[test.start], path))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ShortestPaths(%s, %s, %d) = %q, want %q", test.start, test.end, test.k, got, test.want)
+		}
+	}
+
+	// Distinct call sites yield distinct paths.
+	_, nodes = makeGraph("main f", "main f")
+	if n := len(callgraph.ShortestPaths(nodes["main"], nodes["f"], 10)); n != 2 {
+		t.Errorf("ShortestPaths with two edges main->f returned %d paths, want 2", n)
+	}
+
+	// No path.
+	if paths := callgraph.ShortestPaths(nodes["f"], nodes["main"], 10); paths != nil {
+		t.Errorf("ShortestPaths(f, main) = %v, want nil", paths)
+	}
+}
+
+func TestReachingRoots(t *testing.T) {
+	_, nodes := makeGraph("main a", "init a", "a f", "test b", "b f", "c d")
+	names := make(map[*callgraph.Node]string)
+	for name, n := range nodes {
+		names[n] = name
+	}
+	for _, test := range []struct {
+		target string
+		isRoot func(*callgraph.Node) bool
+		want   string
+	}{
+		{"f", nil, "[main init test]"},
+		{"f", func(n *callgraph.Node) bool { return names[n] == "main" || names[n] == "a" }, "[a main]"},
+		{"d", nil, "[c]"},
+		{"main", nil, "[main]"},
+	} {
+		var got []string
+		for _, n := range callgraph.ReachingRoots(nodes[test.target], test.isRoot) {
+			got = append(got, names[n])
+		}
+		if fmt.Sprint(got) != test.want {
+			t.Errorf("ReachingRoots(%s) = %v, want %s", test.target, got, test.want)
+		}
+	}
+}
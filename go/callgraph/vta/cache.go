@@ -0,0 +1,541 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/internal/typeparams"
+)
+
+// A SummaryCache is an on-disk cache of the summaries of the
+// functions of each package that VTA uses to build the type
+// propagation graph. The summary of a function records the flows
+// induced by its instructions other than calls, which depend only on
+// the function itself; the flows at call sites depend on the initial
+// call graph and are always recomputed.
+//
+// Summaries are stored in one file per package, keyed by the import
+// path of the package and by the string returned by Key, which must
+// change whenever the package or any of its dependencies does. A
+// cryptographic hash of the export data of the package is a suitable
+// key. Repeated analyses of programs that share unchanged
+// dependencies, such as in continuous integration, may then reuse
+// their summaries.
+//
+// A SummaryCache may be used by several concurrent analyses.
+type SummaryCache struct {
+	// Dir is the directory in which summaries are stored.
+	// It must exist.
+	Dir string
+
+	// Key returns the cache key of a package,
+	// or "" if the package should not be cached.
+	Key func(pkg *ssa.Package) string
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// CacheStats holds statistics about the use of a [SummaryCache].
+type CacheStats struct {
+	Hits   int // functions whose summary was found in the cache
+	Misses int // functions whose summary was computed
+	Writes int // package summary files written
+	Errors int // summary files that could not be read or written
+}
+
+// Stats returns statistics about the use of the cache.
+func (c *SummaryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *SummaryCache) count(f func(*CacheStats)) {
+	c.mu.Lock()
+	f(&c.stats)
+	c.mu.Unlock()
+}
+
+// AnalyzeCached is like [Analyze] but reuses the function summaries
+// recorded in cache, and records those it computes.
+func AnalyzeCached(funcs map[*ssa.Function]bool, initial *callgraph.Graph, cache *SummaryCache) *Result {
+	return analyze(funcs, initial, cache)
+}
+
+// A summary is the serializable form of the flows
+// within the functions of a package.
+type summary struct {
+	Types []string            // type table, by types.TypeString
+	Nodes []savedNode         // node table
+	Funcs map[string][][2]int // edges of each function, as pairs of node indices
+}
+
+// A savedNode is the serializable form of a node.
+type savedNode struct {
+	Kind  string // one of the kind constants below
+	Type  int    // index into the type table, or -1
+	Name  string `json:",omitempty"` // key of the value or function
+	Index int    `json:",omitempty"` // field, tuple, or result index
+}
+
+// Node kinds in a savedNode.
+const (
+	kindConstant     = "const"
+	kindPointer      = "pointer"
+	kindMapKey       = "mapkey"
+	kindMapValue     = "mapvalue"
+	kindSliceElem    = "slice"
+	kindChannelElem  = "chan"
+	kindField        = "field"
+	kindGlobal       = "global"
+	kindLocal        = "local"
+	kindIndexedLocal = "indexed"
+	kindFunction     = "func"
+	kindResultVar    = "result"
+	kindPtrInterface = "ptrinterface"
+	kindPtrFunction  = "ptrfunction"
+	kindPanic        = "panic"
+	kindRecover      = "recover"
+)
+
+// pkgSummary holds the summary of a package during an analysis.
+type pkgSummary struct {
+	file    string                      // name of the cache file
+	saved   *summary                    // summary read from the file, or nil
+	edges   map[*ssa.Function][][2]node // edges of functions summarized by this analysis
+	changed bool
+}
+
+// summarizer mediates between a builder and a SummaryCache.
+type summarizer struct {
+	cache *SummaryCache
+	pkgs  map[*ssa.Package]*pkgSummary // nil values for uncached packages
+}
+
+// pkg returns the summary of the package of the function f,
+// or nil if f cannot be cached.
+func (s *summarizer) pkg(f *ssa.Function) *pkgSummary {
+	// Synthetic functions, such as wrappers and instantiations,
+	// are cheap and have no stable name within a package.
+	if f.Pkg == nil || f.Synthetic != "" || f.Blocks == nil {
+		return nil
+	}
+	if ps, ok := s.pkgs[f.Pkg]; ok {
+		return ps
+	}
+	var ps *pkgSummary
+	if key := s.cache.Key(f.Pkg); key != "" {
+		h := sha256.Sum256([]byte(f.Pkg.Pkg.Path() + "\x00" + key))
+		ps = &pkgSummary{
+			file:  filepath.Join(s.cache.Dir, hex.EncodeToString(h[:])+".vta"),
+			edges: make(map[*ssa.Function][][2]node),
+		}
+		if data, err := os.ReadFile(ps.file); err == nil {
+			ps.saved = new(summary)
+			if err := json.Unmarshal(data, ps.saved); err != nil {
+				ps.saved = nil
+				s.cache.count(func(st *CacheStats) { st.Errors++ })
+			}
+		} else if !os.IsNotExist(err) {
+			s.cache.count(func(st *CacheStats) { st.Errors++ })
+		}
+	}
+	s.pkgs[f.Pkg] = ps
+	return ps
+}
+
+// lookup returns the saved edges of function f, if any.
+func (s *summarizer) lookup(ps *pkgSummary, f *ssa.Function) ([][2]node, bool) {
+	if ps.saved == nil {
+		return nil, false
+	}
+	saved, ok := ps.saved.Funcs[f.String()]
+	if !ok {
+		return nil, false
+	}
+	d := decoder{sum: ps.saved, index: indexFunc(f), nodes: make(map[int]node)}
+	edges := make([][2]node, 0, len(saved))
+	for _, e := range saved {
+		x, ok1 := d.node(e[0])
+		y, ok2 := d.node(e[1])
+		if !ok1 || !ok2 {
+			// The summary does not match the function,
+			// perhaps because of an inadequate key.
+			return nil, false
+		}
+		edges = append(edges, [2]node{x, y})
+	}
+	s.cache.count(func(st *CacheStats) { st.Hits++ })
+	return edges, true
+}
+
+// record records the edges computed for function f.
+func (s *summarizer) record(ps *pkgSummary, f *ssa.Function, edges [][2]node) {
+	ps.edges[f] = edges
+	ps.changed = true
+	s.cache.count(func(st *CacheStats) { st.Misses++ })
+}
+
+// flush writes the summaries of the packages
+// for which new functions were summarized.
+func (s *summarizer) flush() {
+	for _, ps := range s.pkgs {
+		if ps == nil || !ps.changed {
+			continue
+		}
+		if err := ps.write(); err != nil {
+			s.cache.count(func(st *CacheStats) { st.Errors++ })
+		} else {
+			s.cache.count(func(st *CacheStats) { st.Writes++ })
+		}
+	}
+}
+
+// write saves the summary of the package, which combines the
+// functions previously saved with those summarized by this analysis.
+func (ps *pkgSummary) write() error {
+	enc := encoder{
+		sum:   &summary{Funcs: make(map[string][][2]int)},
+		types: make(map[string]int),
+		nodes: make(map[savedNode]int),
+	}
+	if ps.saved != nil {
+		enc.sum.Types = ps.saved.Types
+		enc.sum.Nodes = ps.saved.Nodes
+		for i, t := range ps.saved.Types {
+			enc.types[t] = i
+		}
+		for i, n := range ps.saved.Nodes {
+			enc.nodes[n] = i
+		}
+		for name, edges := range ps.saved.Funcs {
+			enc.sum.Funcs[name] = edges
+		}
+	}
+	for f, edges := range ps.edges {
+		saved := make([][2]int, 0, len(edges))
+		for _, e := range edges {
+			saved = append(saved, [2]int{enc.node(e[0]), enc.node(e[1])})
+		}
+		enc.sum.Funcs[f.String()] = saved
+	}
+	data, err := json.Marshal(enc.sum)
+	if err != nil {
+		return err
+	}
+
+	// Write atomically, as the file may be read concurrently.
+	tmp, err := os.CreateTemp(filepath.Dir(ps.file), filepath.Base(ps.file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), ps.file)
+}
+
+// An encoder converts nodes to their serializable form.
+type encoder struct {
+	sum   *summary
+	types map[string]int
+	nodes map[savedNode]int
+}
+
+func (e *encoder) node(n node) int {
+	sn := savedNode{Type: -1}
+	switch n := n.(type) {
+	case constant:
+		sn.Kind, sn.Type = kindConstant, e.typ(n.typ)
+	case pointer:
+		sn.Kind, sn.Type = kindPointer, e.typ(n.typ)
+	case mapKey:
+		sn.Kind, sn.Type = kindMapKey, e.typ(n.typ)
+	case mapValue:
+		sn.Kind, sn.Type = kindMapValue, e.typ(n.typ)
+	case sliceElem:
+		sn.Kind, sn.Type = kindSliceElem, e.typ(n.typ)
+	case channelElem:
+		sn.Kind, sn.Type = kindChannelElem, e.typ(n.typ)
+	case nestedPtrInterface:
+		sn.Kind, sn.Type = kindPtrInterface, e.typ(n.typ)
+	case nestedPtrFunction:
+		sn.Kind, sn.Type = kindPtrFunction, e.typ(n.typ)
+	case field:
+		sn.Kind, sn.Type, sn.Index = kindField, e.typ(n.StructType), n.index
+	case global:
+		sn.Kind, sn.Name = kindGlobal, valueKey(n.val)
+	case local:
+		sn.Kind, sn.Name = kindLocal, valueKey(n.val)
+	case indexedLocal:
+		sn.Kind, sn.Name, sn.Index, sn.Type = kindIndexedLocal, valueKey(n.val), n.index, e.typ(n.typ)
+	case function:
+		sn.Kind, sn.Name = kindFunction, valueKey(n.f)
+	case resultVar:
+		sn.Kind, sn.Name, sn.Index = kindResultVar, valueKey(n.f), n.index
+	case panicArg:
+		sn.Kind = kindPanic
+	case recoverReturn:
+		sn.Kind = kindRecover
+	default:
+		panic(fmt.Sprintf("encoding unrecognized node %v", n))
+	}
+	i, ok := e.nodes[sn]
+	if !ok {
+		i = len(e.sum.Nodes)
+		e.sum.Nodes = append(e.sum.Nodes, sn)
+		e.nodes[sn] = i
+	}
+	return i
+}
+
+func (e *encoder) typ(t types.Type) int {
+	s := types.TypeString(t, nil)
+	i, ok := e.types[s]
+	if !ok {
+		i = len(e.sum.Types)
+		e.sum.Types = append(e.sum.Types, s)
+		e.types[s] = i
+	}
+	return i
+}
+
+// A decoder converts saved nodes back to nodes
+// of the program under analysis.
+type decoder struct {
+	sum   *summary
+	index *funcIndex
+	nodes map[int]node // memoized results of node
+}
+
+func (d *decoder) node(i int) (node, bool) {
+	if n, ok := d.nodes[i]; ok {
+		return n, n != nil
+	}
+	n := d.decode(i)
+	d.nodes[i] = n
+	return n, n != nil
+}
+
+// decode returns the node for saved node i, or nil
+// if it does not denote a node of the function.
+func (d *decoder) decode(i int) node {
+	if i < 0 || i >= len(d.sum.Nodes) {
+		return nil
+	}
+	sn := d.sum.Nodes[i]
+
+	var t types.Type
+	if sn.Type >= 0 {
+		if sn.Type >= len(d.sum.Types) {
+			return nil
+		}
+		t = d.index.types[d.sum.Types[sn.Type]]
+		if t == nil {
+			return nil
+		}
+	}
+	v := d.index.values[sn.Name]
+
+	switch sn.Kind {
+	case kindConstant:
+		if t != nil {
+			return constant{typ: t}
+		}
+	case kindPointer:
+		if p, ok := t.(*types.Pointer); ok {
+			return pointer{typ: p}
+		}
+	case kindMapKey:
+		if t != nil {
+			return mapKey{typ: t}
+		}
+	case kindMapValue:
+		if t != nil {
+			return mapValue{typ: t}
+		}
+	case kindSliceElem:
+		if t != nil {
+			return sliceElem{typ: t}
+		}
+	case kindChannelElem:
+		if t != nil {
+			return channelElem{typ: t}
+		}
+	case kindPtrInterface:
+		if t != nil {
+			return nestedPtrInterface{typ: t}
+		}
+	case kindPtrFunction:
+		if t != nil {
+			return nestedPtrFunction{typ: t}
+		}
+	case kindField:
+		if s, ok := typeparams.CoreType(t).(*types.Struct); ok && sn.Index < s.NumFields() {
+			return field{StructType: t, index: sn.Index}
+		}
+	case kindGlobal:
+		if g, ok := v.(*ssa.Global); ok {
+			return global{val: g}
+		}
+	case kindLocal:
+		if v != nil {
+			return local{val: v}
+		}
+	case kindIndexedLocal:
+		if v != nil && t != nil {
+			return indexedLocal{val: v, index: sn.Index, typ: t}
+		}
+	case kindFunction:
+		if f, ok := v.(*ssa.Function); ok {
+			return function{f: f}
+		}
+	case kindResultVar:
+		if f, ok := v.(*ssa.Function); ok && sn.Index < f.Signature.Results().Len() {
+			return resultVar{f: f, index: sn.Index}
+		}
+	case kindPanic:
+		return panicArg{}
+	case kindRecover:
+		return recoverReturn{}
+	}
+	return nil
+}
+
+// valueKey returns a name for v that is unique within the
+// values that may appear in the summary of a function.
+func valueKey(v ssa.Value) string {
+	switch v := v.(type) {
+	case *ssa.Global:
+		return "global " + v.String()
+	case *ssa.Function:
+		return "func " + v.String()
+	case *ssa.Parameter:
+		return "param " + v.Parent().String() + " " + v.Name()
+	case *ssa.FreeVar:
+		return "freevar " + v.Parent().String() + " " + v.Name()
+	}
+	return "value " + v.Parent().String() + " " + v.Name()
+}
+
+// A funcIndex maps the keys of values and types that may appear
+// in the summary of a function to the values and types themselves.
+type funcIndex struct {
+	values map[string]ssa.Value
+	types  map[string]types.Type // nil for ambiguous types
+}
+
+// indexFunc returns the index of the values and types that
+// may appear in the summary of f: those of its parameters,
+// free variables, instructions and operands, and of the free
+// variables of its closures, along with their component types.
+func indexFunc(f *ssa.Function) *funcIndex {
+	index := &funcIndex{
+		values: make(map[string]ssa.Value),
+		types:  make(map[string]types.Type),
+	}
+	seen := make(map[types.Type]bool)
+	var addType func(t types.Type)
+	addType = func(t types.Type) {
+		if t == nil || seen[t] {
+			return
+		}
+		seen[t] = true
+		s := types.TypeString(t, nil)
+		if prev, ok := index.types[s]; !ok {
+			index.types[s] = t
+		} else if prev != nil && !types.Identical(prev, t) {
+			index.types[s] = nil
+		}
+		switch t := t.(type) {
+		case *types.Alias:
+			addType(types.Unalias(t))
+		case *types.Named:
+			addType(t.Underlying())
+		case *types.TypeParam:
+			addType(typeparams.CoreType(t))
+		case *types.Pointer:
+			addType(t.Elem())
+		case *types.Slice:
+			addType(t.Elem())
+		case *types.Array:
+			addType(t.Elem())
+		case *types.Chan:
+			addType(t.Elem())
+		case *types.Map:
+			addType(t.Key())
+			addType(t.Elem())
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				addType(t.Field(i).Type())
+			}
+		case *types.Tuple:
+			for i := 0; i < t.Len(); i++ {
+				addType(t.At(i).Type())
+			}
+		case *types.Signature:
+			addType(t.Params())
+			addType(t.Results())
+		}
+	}
+	addValue := func(v ssa.Value) {
+		if v == nil {
+			return
+		}
+		index.values[valueKey(v)] = v
+		addType(v.Type())
+	}
+
+	addValue(f)
+	for _, p := range f.Params {
+		addValue(p)
+	}
+	for _, fv := range f.FreeVars {
+		addValue(fv)
+	}
+	var rands []*ssa.Value
+	for _, b := range f.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(ssa.Value); ok {
+				addValue(v)
+			}
+			rands = instr.Operands(rands[:0])
+			for _, rand := range rands {
+				if *rand == nil {
+					continue
+				}
+				switch v := (*rand).(type) {
+				case *ssa.Function:
+					addValue(v)
+					for _, fv := range v.FreeVars {
+						addValue(fv)
+					}
+				case *ssa.Global:
+					addValue(v)
+				default:
+					addType(v.Type())
+				}
+			}
+		}
+	}
+	return index
+}
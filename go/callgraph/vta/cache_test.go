@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vta
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+func TestSummaryCache(t *testing.T) {
+	files := []string{
+		"testdata/src/callgraph_ho.go",
+		"testdata/src/callgraph_interfaces.go",
+		"testdata/src/callgraph_collections.go",
+		"testdata/src/callgraph_fields.go",
+		"testdata/src/callgraph_comma_maps.go",
+		"testdata/src/callgraph_recursive_types.go",
+		"testdata/src/callgraph_nested_ptr.go",
+		"testdata/src/callgraph_generics.go",
+		"testdata/src/select.go",
+		"testdata/src/panic.go",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			cache := &SummaryCache{
+				Dir: t.TempDir(),
+				Key: func(*ssa.Package) string { return "v1" },
+			}
+			var stats []CacheStats
+			for i := 0; i < 2; i++ {
+				// Load the program afresh each time,
+				// as would a separate process.
+				prog, _, err := testProg(t, file, ssa.BuilderMode(0))
+				if err != nil {
+					t.Fatal(err)
+				}
+				funcs := ssautil.AllFunctions(prog)
+				want := Analyze(funcs, nil)
+				got := AnalyzeCached(funcs, nil, cache)
+				if diff := cmp.Diff(graphStr(want), graphStr(got)); diff != "" {
+					t.Errorf("run %d: type propagation graph mismatch (-want +got):\n%s", i, diff)
+				}
+				wantCG, gotCG := callGraphStr(want.CallGraph), callGraphStr(got.CallGraph)
+				sort.Strings(wantCG)
+				sort.Strings(gotCG)
+				if diff := cmp.Diff(wantCG, gotCG); diff != "" {
+					t.Errorf("run %d: call graph mismatch (-want +got):\n%s", i, diff)
+				}
+				stats = append(stats, cache.Stats())
+			}
+
+			first, second := stats[0], stats[1]
+			if first.Hits != 0 || first.Misses == 0 || first.Writes != 1 || first.Errors != 0 {
+				t.Errorf("stats after first run = %+v, want only misses and one write", first)
+			}
+			if second.Hits != first.Misses || second.Misses != first.Misses || second.Writes != 1 || second.Errors != 0 {
+				t.Errorf("stats after second run = %+v, want %d hits and no new misses", second, first.Misses)
+			}
+		})
+	}
+}
+
+// graphStr returns the sorted edges of the type propagation graph of r.
+func graphStr(r *Result) []string {
+	var edges []string
+	for x, succs := range r.graph.m {
+		for y := range succs {
+			edges = append(edges, fmt.Sprintf("%v -> %v", r.graph.node[x], r.graph.node[y]))
+		}
+	}
+	sort.Strings(edges)
+	return edges
+}
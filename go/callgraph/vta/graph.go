@@ -309,9 +309,22 @@ func (g *vtaGraph) addEdge(x, y node) {
 // `callgraph` needed to establish interprocedural edges. Returns the
 // graph and a map for unique type representatives.
 func typePropGraph(funcs map[*ssa.Function]bool, callees calleesFunc) (*vtaGraph, *typeutil.Map) {
+	return summarizedTypePropGraph(funcs, callees, nil)
+}
+
+// summarizedTypePropGraph is like typePropGraph but, if cache is
+// non-nil, reuses the function summaries it holds.
+func summarizedTypePropGraph(funcs map[*ssa.Function]bool, callees calleesFunc, cache *SummaryCache) (*vtaGraph, *typeutil.Map) {
 	b := builder{callees: callees}
+	if cache != nil {
+		b.sum = &summarizer{cache: cache, pkgs: make(map[*ssa.Package]*pkgSummary)}
+	}
 	b.visit(funcs)
+	if b.sum != nil {
+		b.sum.flush()
+	}
 	b.callees = nil // ensure callees is not pinned by pointers to other fields of b.
+	b.sum = nil
 	return &b.graph, &b.canon
 }
 
@@ -329,6 +342,9 @@ type builder struct {
 	// types too, in particular type representatives. Each value is a
 	// pointer so this map is not expected to take much memory.
 	canon typeutil.Map
+
+	sum *summarizer // if non-nil, the cache of function summaries
+	rec *[][2]node  // if non-nil, records the edges added to graph
 }
 
 func (b *builder) visit(funcs map[*ssa.Function]bool) {
@@ -343,19 +359,53 @@ func (b *builder) visit(funcs map[*ssa.Function]bool) {
 }
 
 func (b *builder) fun(f *ssa.Function) {
+	var ps *pkgSummary
+	if b.sum != nil {
+		ps = b.sum.pkg(f)
+	}
+	if ps == nil {
+		for _, bl := range f.Blocks {
+			for _, instr := range bl.Instrs {
+				b.instr(instr)
+			}
+		}
+		return
+	}
+
+	// The flows at call sites depend on the initial call graph,
+	// so they are excluded from the summary and always computed.
+	if edges, ok := b.sum.lookup(ps, f); ok {
+		for _, e := range edges {
+			b.graph.addEdge(representative(e[0], &b.canon), representative(e[1], &b.canon))
+		}
+		for _, bl := range f.Blocks {
+			for _, instr := range bl.Instrs {
+				if _, ok := instr.(ssa.CallInstruction); ok {
+					b.instr(instr)
+				}
+			}
+		}
+		return
+	}
+	var edges [][2]node
 	for _, bl := range f.Blocks {
 		for _, instr := range bl.Instrs {
+			if _, ok := instr.(ssa.CallInstruction); !ok {
+				b.rec = &edges
+			}
 			b.instr(instr)
+			b.rec = nil
 		}
 	}
+	b.sum.record(ps, f, edges)
 }
 
 func (b *builder) instr(instr ssa.Instruction) {
 	switch i := instr.(type) {
 	case *ssa.Store:
-		b.addInFlowAliasEdges(b.nodeFromVal(i.Addr), b.nodeFromVal(i.Val))
+		b.addInFlowAliasEdges(nodeFromVal(i.Addr), nodeFromVal(i.Val))
 	case *ssa.MakeInterface:
-		b.addInFlowEdge(b.nodeFromVal(i.X), b.nodeFromVal(i))
+		b.addInFlowEdge(nodeFromVal(i.X), nodeFromVal(i))
 	case *ssa.MakeClosure:
 		b.closure(i)
 	case *ssa.UnOp:
@@ -371,7 +421,7 @@ func (b *builder) instr(instr ssa.Instruction) {
 		// is a separate variable. The a -> b flow can happen when
 		// A is a pointer to interface, but then the command is of
 		// type ChangeType, handled below.
-		b.addInFlowEdge(b.nodeFromVal(i.X), b.nodeFromVal(i))
+		b.addInFlowEdge(nodeFromVal(i.X), nodeFromVal(i))
 	case *ssa.ChangeType:
 		// change type command a := A(b) results in a and b being the
 		// same value. For concrete type A, there is no interesting flow.
@@ -387,7 +437,7 @@ func (b *builder) instr(instr ssa.Instruction) {
 		//	a := (*J)(b)
 		//
 		// When this happens we add flows between a <--> b.
-		b.addInFlowAliasEdges(b.nodeFromVal(i), b.nodeFromVal(i.X))
+		b.addInFlowAliasEdges(nodeFromVal(i), nodeFromVal(i.X))
 	case *ssa.TypeAssert:
 		b.tassert(i)
 	case *ssa.Extract:
@@ -435,10 +485,10 @@ func (b *builder) unop(u *ssa.UnOp) {
 	switch u.Op {
 	case token.MUL:
 		// Multiplication operator * is used here as a dereference operator.
-		b.addInFlowAliasEdges(b.nodeFromVal(u), b.nodeFromVal(u.X))
+		b.addInFlowAliasEdges(nodeFromVal(u), nodeFromVal(u.X))
 	case token.ARROW:
 		t := typeparams.CoreType(u.X.Type()).(*types.Chan).Elem()
-		b.addInFlowAliasEdges(b.nodeFromVal(u), channelElem{typ: t})
+		b.addInFlowAliasEdges(nodeFromVal(u), channelElem{typ: t})
 	default:
 		// There is no interesting type flow otherwise.
 	}
@@ -446,13 +496,13 @@ func (b *builder) unop(u *ssa.UnOp) {
 
 func (b *builder) phi(p *ssa.Phi) {
 	for _, edge := range p.Edges {
-		b.addInFlowAliasEdges(b.nodeFromVal(p), b.nodeFromVal(edge))
+		b.addInFlowAliasEdges(nodeFromVal(p), nodeFromVal(edge))
 	}
 }
 
 func (b *builder) tassert(a *ssa.TypeAssert) {
 	if !a.CommaOk {
-		b.addInFlowEdge(b.nodeFromVal(a.X), b.nodeFromVal(a))
+		b.addInFlowEdge(nodeFromVal(a.X), nodeFromVal(a))
 		return
 	}
 	// The case where a is <a.AssertedType, bool> register so there
@@ -462,7 +512,7 @@ func (b *builder) tassert(a *ssa.TypeAssert) {
 	t := tup.At(0).Type()
 
 	local := indexedLocal{val: a, typ: t, index: 0}
-	b.addInFlowEdge(b.nodeFromVal(a.X), local)
+	b.addInFlowEdge(nodeFromVal(a.X), local)
 }
 
 // extract instruction t1 := t2[i] generates flows between t2[i]
@@ -473,12 +523,12 @@ func (b *builder) extract(e *ssa.Extract) {
 	t := tup.At(e.Index).Type()
 
 	local := indexedLocal{val: e.Tuple, typ: t, index: e.Index}
-	b.addInFlowAliasEdges(b.nodeFromVal(e), local)
+	b.addInFlowAliasEdges(nodeFromVal(e), local)
 }
 
 func (b *builder) field(f *ssa.Field) {
 	fnode := field{StructType: f.X.Type(), index: f.Field}
-	b.addInFlowEdge(fnode, b.nodeFromVal(f))
+	b.addInFlowEdge(fnode, nodeFromVal(f))
 }
 
 func (b *builder) fieldAddr(f *ssa.FieldAddr) {
@@ -486,13 +536,13 @@ func (b *builder) fieldAddr(f *ssa.FieldAddr) {
 
 	// Since we are getting pointer to a field, make a bidirectional edge.
 	fnode := field{StructType: t, index: f.Field}
-	b.addInFlowEdge(fnode, b.nodeFromVal(f))
-	b.addInFlowEdge(b.nodeFromVal(f), fnode)
+	b.addInFlowEdge(fnode, nodeFromVal(f))
+	b.addInFlowEdge(nodeFromVal(f), fnode)
 }
 
 func (b *builder) send(s *ssa.Send) {
 	t := typeparams.CoreType(s.Chan.Type()).(*types.Chan).Elem()
-	b.addInFlowAliasEdges(channelElem{typ: t}, b.nodeFromVal(s.X))
+	b.addInFlowAliasEdges(channelElem{typ: t}, nodeFromVal(s.X))
 }
 
 // selekt generates flows for select statement
@@ -508,7 +558,7 @@ func (b *builder) selekt(s *ssa.Select) {
 		t := typeparams.CoreType(state.Chan.Type()).(*types.Chan).Elem()
 
 		if state.Dir == types.SendOnly {
-			b.addInFlowAliasEdges(channelElem{typ: t}, b.nodeFromVal(state.Send))
+			b.addInFlowAliasEdges(channelElem{typ: t}, nodeFromVal(state.Send))
 		} else {
 			// state.Dir == RecvOnly by definition of select instructions.
 			tupEntry := indexedLocal{val: s, typ: t, index: 2 + recvIndex}
@@ -523,7 +573,7 @@ func (b *builder) selekt(s *ssa.Select) {
 // slice elements are both modeled as SliceElem.
 func (b *builder) index(i *ssa.Index) {
 	et := sliceArrayElem(i.X.Type())
-	b.addInFlowAliasEdges(b.nodeFromVal(i), sliceElem{typ: et})
+	b.addInFlowAliasEdges(nodeFromVal(i), sliceElem{typ: et})
 }
 
 // indexAddr instruction a := &b[c] fetches address of a index
@@ -532,8 +582,8 @@ func (b *builder) index(i *ssa.Index) {
 // both modeled as SliceElem.
 func (b *builder) indexAddr(i *ssa.IndexAddr) {
 	et := sliceArrayElem(i.X.Type())
-	b.addInFlowEdge(sliceElem{typ: et}, b.nodeFromVal(i))
-	b.addInFlowEdge(b.nodeFromVal(i), sliceElem{typ: et})
+	b.addInFlowEdge(sliceElem{typ: et}, nodeFromVal(i))
+	b.addInFlowEdge(nodeFromVal(i), sliceElem{typ: et})
 }
 
 // lookup handles map query commands a := m[b] where m is of type
@@ -547,7 +597,7 @@ func (b *builder) lookup(l *ssa.Lookup) {
 	}
 
 	if !l.CommaOk {
-		b.addInFlowAliasEdges(b.nodeFromVal(l), mapValue{typ: t.Elem()})
+		b.addInFlowAliasEdges(nodeFromVal(l), mapValue{typ: t.Elem()})
 	} else {
 		i := indexedLocal{val: l, typ: t.Elem(), index: 0}
 		b.addInFlowAliasEdges(i, mapValue{typ: t.Elem()})
@@ -564,8 +614,8 @@ func (b *builder) mapUpdate(u *ssa.MapUpdate) {
 		return
 	}
 
-	b.addInFlowAliasEdges(mapKey{typ: t.Key()}, b.nodeFromVal(u.Key))
-	b.addInFlowAliasEdges(mapValue{typ: t.Elem()}, b.nodeFromVal(u.Value))
+	b.addInFlowAliasEdges(mapKey{typ: t.Key()}, nodeFromVal(u.Key))
+	b.addInFlowAliasEdges(mapValue{typ: t.Elem()}, nodeFromVal(u.Value))
 }
 
 // next instruction <ok, key, value> := next r, where r
@@ -597,10 +647,10 @@ func (b *builder) addInFlowAliasEdges(l, r node) {
 
 func (b *builder) closure(c *ssa.MakeClosure) {
 	f := c.Fn.(*ssa.Function)
-	b.addInFlowEdge(function{f: f}, b.nodeFromVal(c))
+	b.addInFlowEdge(function{f: f}, nodeFromVal(c))
 
 	for i, fv := range f.FreeVars {
-		b.addInFlowAliasEdges(b.nodeFromVal(fv), b.nodeFromVal(c.Bindings[i]))
+		b.addInFlowAliasEdges(nodeFromVal(fv), nodeFromVal(c.Bindings[i]))
 	}
 }
 
@@ -619,7 +669,7 @@ func (b *builder) panic(p *ssa.Panic) {
 		return
 	}
 
-	b.addInFlowEdge(b.nodeFromVal(p.X), panicArg{})
+	b.addInFlowEdge(nodeFromVal(p.X), panicArg{})
 }
 
 // call adds flows between arguments/parameters and return values/registers
@@ -628,7 +678,7 @@ func (b *builder) call(c ssa.CallInstruction) {
 	// When c is r := recover() call register instruction, we add Recover -> r.
 	if bf, ok := c.Common().Value.(*ssa.Builtin); ok && bf.Name() == "recover" {
 		if v, ok := c.(ssa.Value); ok {
-			b.addInFlowEdge(recoverReturn{}, b.nodeFromVal(v))
+			b.addInFlowEdge(recoverReturn{}, nodeFromVal(v))
 		}
 		return
 	}
@@ -645,7 +695,7 @@ func (b *builder) call(c ssa.CallInstruction) {
 		if results.Len() == 1 {
 			// When there is only one return value, the destination register does not
 			// have a tuple type.
-			b.addInFlowEdge(resultVar{f: f, index: 0}, b.nodeFromVal(site))
+			b.addInFlowEdge(resultVar{f: f, index: 0}, nodeFromVal(site))
 		} else {
 			tup := site.Type().(*types.Tuple)
 			for i := 0; i < results.Len(); i++ {
@@ -676,7 +726,7 @@ func addArgumentFlows(b *builder, c ssa.CallInstruction, f *ssa.Function) {
 		// The flow other way around would bake in information from the
 		// initial call graph.
 		if isFunction(f.Params[0].Type()) {
-			b.addInFlowEdge(b.nodeFromVal(cc.Value), b.nodeFromVal(f.Params[0]))
+			b.addInFlowEdge(nodeFromVal(cc.Value), nodeFromVal(f.Params[0]))
 		}
 	}
 
@@ -693,7 +743,7 @@ func addArgumentFlows(b *builder, c ssa.CallInstruction, f *ssa.Function) {
 		if len(f.Params) <= i+offset {
 			return
 		}
-		b.addInFlowAliasEdges(b.nodeFromVal(f.Params[i+offset]), b.nodeFromVal(v))
+		b.addInFlowAliasEdges(nodeFromVal(f.Params[i+offset]), nodeFromVal(v))
 	}
 }
 
@@ -701,7 +751,7 @@ func addArgumentFlows(b *builder, c ssa.CallInstruction, f *ssa.Function) {
 // statement to the result variables of the enclosing function.
 func (b *builder) rtrn(r *ssa.Return) {
 	for i, rs := range r.Results {
-		b.addInFlowEdge(b.nodeFromVal(rs), resultVar{f: r.Parent(), index: i})
+		b.addInFlowEdge(nodeFromVal(rs), resultVar{f: r.Parent(), index: i})
 	}
 }
 
@@ -761,7 +811,7 @@ func (b *builder) multiconvert(c *ssa.MultiConvert) {
 			ud := d.Type().Underlying()
 			if isValuePreserving(us, ud) {
 				// This is equivalent to a ChangeType.
-				b.addInFlowAliasEdges(b.nodeFromVal(c), b.nodeFromVal(c.X))
+				b.addInFlowAliasEdges(nodeFromVal(c), nodeFromVal(c.X))
 				return
 			}
 			// This is equivalent to either: SliceToArrayPointer,,
@@ -775,12 +825,16 @@ func (b *builder) multiconvert(c *ssa.MultiConvert) {
 // is no interesting type flow so the edge is omitted.
 func (b *builder) addInFlowEdge(s, d node) {
 	if hasInFlow(d) {
-		b.graph.addEdge(b.representative(s), b.representative(d))
+		s, d := representative(s, &b.canon), representative(d, &b.canon)
+		b.graph.addEdge(s, d)
+		if b.rec != nil {
+			*b.rec = append(*b.rec, [2]node{s, d})
+		}
 	}
 }
 
 // Creates const, pointer, global, func, and local nodes based on register instructions.
-func (b *builder) nodeFromVal(val ssa.Value) node {
+func nodeFromVal(val ssa.Value) node {
 	if p, ok := types.Unalias(val.Type()).(*types.Pointer); ok && !types.IsInterface(p.Elem()) && !isFunction(p.Elem()) {
 		// Nested pointer to interfaces are modeled as a special
 		// nestedPtrInterface node.
@@ -812,14 +866,15 @@ func (b *builder) nodeFromVal(val ssa.Value) node {
 
 // representative returns a unique representative for node `n`. Since
 // semantically equivalent types can have different implementations,
-// this method guarantees the same implementation is always used.
-func (b *builder) representative(n node) node {
+// this function guarantees the same implementation, subject to type
+// map `canon`, is always used.
+func representative(n node, canon *typeutil.Map) node {
 	if n.Type() == nil {
 		// panicArg and recoverReturn do not have
 		// types and are unique by definition.
 		return n
 	}
-	t := canonicalize(n.Type(), &b.canon)
+	t := canonicalize(n.Type(), canon)
 
 	switch i := n.(type) {
 	case constant:
@@ -839,7 +894,7 @@ func (b *builder) representative(n node) node {
 	case nestedPtrFunction:
 		return nestedPtrFunction{typ: t}
 	case field:
-		return field{StructType: canonicalize(i.StructType, &b.canon), index: i.index}
+		return field{StructType: canonicalize(i.StructType, canon), index: i.index}
 	case indexedLocal:
 		return indexedLocal{typ: t, val: i.val, index: i.index}
 	case local, global, panicArg, recoverReturn, function, resultVar:
@@ -0,0 +1,160 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vta
+
+import (
+	"go/types"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Result holds the results of VTA: the call graph, the type
+// propagation graph, and the labeling of its nodes.
+//
+// The methods of Result may be called concurrently.
+type Result struct {
+	// CallGraph is the call graph computed by VTA, as returned
+	// by [CallGraph].
+	CallGraph *callgraph.Graph
+
+	graph *vtaGraph
+	types propTypeMap
+
+	mu    sync.Mutex
+	canon *typeutil.Map // type representatives; guarded by mu
+	preds [][]idx       // preds[i] has the predecessors of node i; lazily built
+}
+
+// A Node is a node of the type propagation graph. It represents a
+// program construct to which types may flow, such as a local variable,
+// a global variable, a struct field, or all the elements of slices of
+// a given type.
+type Node struct {
+	n node
+}
+
+// String returns a description of the construct represented by the node.
+func (n Node) String() string { return n.n.String() }
+
+// Type returns the type of the construct represented by the node,
+// or nil for the nodes modeling the arguments of panic and the results
+// of recover.
+func (n Node) Type() types.Type { return n.n.Type() }
+
+// A Label is an element of the set of types and functions that VTA
+// found to reach a node of the type propagation graph.
+type Label struct {
+	Type types.Type    // a type that may flow to the node
+	Func *ssa.Function // if non-nil, the function value of that type
+}
+
+// Nodes returns the nodes of the type propagation graph.
+func (r *Result) Nodes() []Node {
+	nodes := make([]Node, len(r.graph.node))
+	for i, n := range r.graph.node {
+		nodes[i] = Node{n}
+	}
+	return nodes
+}
+
+// ValueNode returns the node of the type propagation graph that
+// represents the value v. It returns false if the graph has no such
+// node, which happens when no interesting types flow to or from v.
+func (r *Result) ValueNode(v ssa.Value) (Node, bool) {
+	if _, ok := v.(*ssa.Builtin); ok {
+		return Node{}, false
+	}
+	r.mu.Lock()
+	n := representative(nodeFromVal(v), r.canon)
+	r.mu.Unlock()
+	if _, ok := r.graph.idx[n]; !ok {
+		return Node{}, false
+	}
+	return Node{n}, true
+}
+
+// Successors returns the nodes to which types flow directly from n.
+func (r *Result) Successors(n Node) []Node {
+	i, ok := r.graph.idx[n.n]
+	if !ok {
+		return nil
+	}
+	var succs []idx
+	r.graph.successors(i)(func(j idx) bool {
+		succs = append(succs, j)
+		return true
+	})
+	return r.nodes(succs)
+}
+
+// Predecessors returns the nodes from which types flow directly to n.
+func (r *Result) Predecessors(n Node) []Node {
+	i, ok := r.graph.idx[n.n]
+	if !ok {
+		return nil
+	}
+	r.mu.Lock()
+	if r.preds == nil {
+		r.preds = make([][]idx, r.graph.numNodes())
+		for x := range r.graph.m {
+			r.graph.successors(idx(x))(func(y idx) bool {
+				r.preds[y] = append(r.preds[y], idx(x))
+				return true
+			})
+		}
+	}
+	preds := append([]idx(nil), r.preds[i]...)
+	r.mu.Unlock()
+	return r.nodes(preds)
+}
+
+// nodes returns the nodes with the given indices, in index order.
+func (r *Result) nodes(indices []idx) []Node {
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	nodes := make([]Node, len(indices))
+	for i, x := range indices {
+		nodes[i] = Node{r.graph.node[x]}
+	}
+	return nodes
+}
+
+// Labels returns the types and functions that may flow to n,
+// sorted by type and then function.
+func (r *Result) Labels(n Node) []Label {
+	var labels []Label
+	r.types.propTypes(n.n)(func(p propType) bool {
+		labels = append(labels, Label{Type: p.typ, Func: p.f})
+		return true
+	})
+	sort.Slice(labels, func(i, j int) bool {
+		x, y := labels[i], labels[j]
+		if tx, ty := types.TypeString(x.Type, nil), types.TypeString(y.Type, nil); tx != ty {
+			return tx < ty
+		}
+		return funcString(x.Func) < funcString(y.Func)
+	})
+	return labels
+}
+
+// ValueLabels returns the types and functions that may flow to the
+// value v, or nil if the graph has no node for v.
+func (r *Result) ValueLabels(v ssa.Value) []Label {
+	n, ok := r.ValueNode(v)
+	if !ok {
+		return nil
+	}
+	return r.Labels(n)
+}
+
+func funcString(f *ssa.Function) string {
+	if f == nil {
+		return ""
+	}
+	return f.String()
+}
@@ -60,6 +60,7 @@ import (
 	"go/types"
 
 	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/rta"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -74,12 +75,52 @@ import (
 // and function/method inputs can have. CallGraph is then sound, modulo use of
 // reflection and unsafe, if the initial call graph is sound.
 func CallGraph(funcs map[*ssa.Function]bool, initial *callgraph.Graph) *callgraph.Graph {
+	return Analyze(funcs, initial).CallGraph
+}
+
+// PrunedCallGraph computes the call graph of the functions reachable
+// from the specified roots. It first uses Rapid Type Analysis (see
+// [rta.Analyze]) to find the reachable functions and the types
+// instantiated by them, then refines the RTA call graph by running VTA
+// over the reachable functions alone. The result is close in precision
+// to that of VTA over the whole program at a fraction of the cost.
+//
+// The requirements on the roots are those of [rta.Analyze]: they must
+// be entrypoints of a complete program built with the
+// [ssa.InstantiateGenerics] mode flag. PrunedCallGraph returns nil if
+// there are no roots.
+func PrunedCallGraph(roots []*ssa.Function) *callgraph.Graph {
+	res := rta.Analyze(roots, true)
+	if res == nil {
+		return nil
+	}
+	funcs := make(map[*ssa.Function]bool, len(res.Reachable))
+	for f := range res.Reachable {
+		funcs[f] = true
+	}
+	return CallGraph(funcs, res.CallGraph)
+}
+
+// Analyze is like [CallGraph] but also returns the type propagation
+// graph from which the call graph was computed and the types and
+// functions that VTA found to reach each of its nodes, so that clients
+// may explain why a call edge exists.
+func Analyze(funcs map[*ssa.Function]bool, initial *callgraph.Graph) *Result {
+	return analyze(funcs, initial, nil)
+}
+
+func analyze(funcs map[*ssa.Function]bool, initial *callgraph.Graph, cache *SummaryCache) *Result {
 	callees := makeCalleesFunc(funcs, initial)
-	vtaG, canon := typePropGraph(funcs, callees)
+	vtaG, canon := summarizedTypePropGraph(funcs, callees, cache)
 	types := propagate(vtaG, canon)
 
 	c := &constructor{types: types, callees: callees, cache: make(methodCache)}
-	return c.construct(funcs)
+	return &Result{
+		CallGraph: c.construct(funcs),
+		graph:     vtaG,
+		canon:     canon,
+		types:     types,
+	}
 }
 
 // constructor type linearly traverses the input program
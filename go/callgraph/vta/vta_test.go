@@ -7,6 +7,7 @@
 package vta
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -113,6 +114,33 @@ func TestVTAProgVsFuncSet(t *testing.T) {
 	}
 }
 
+// TestPrunedCallGraph tests that VTA restricted to the functions
+// reachable by RTA from Baz ignores the flows of the unreachable Bar.
+func TestPrunedCallGraph(t *testing.T) {
+	prog, _, err := testProg(t, "testdata/src/callgraph_nested_ptr.go", ssa.InstantiateGenerics)
+	if err != nil {
+		t.Fatalf("couldn't load test `testdata/src/callgraph_nested_ptr.go`: %s", err)
+	}
+	var baz *ssa.Function
+	for f := range ssautil.AllFunctions(prog) {
+		if funcName(f) == "Baz" {
+			baz = f
+		}
+	}
+
+	g := PrunedCallGraph([]*ssa.Function{baz})
+	got := callGraphStr(g)
+	want := []string{"Baz: Do(i) -> Do; invoke t2.Foo() -> A.Foo"}
+	if diff := setdiff(want, got); len(diff) > 0 {
+		t.Errorf("pruned callgraph %v should contain %v (diff: %v)", got, want, diff)
+	}
+	for f := range g.Nodes {
+		if f != nil && funcName(f) == "Bar" {
+			t.Errorf("pruned callgraph contains unreachable function Bar")
+		}
+	}
+}
+
 // TestVTAPanicMissingDefinitions tests if VTA gracefully handles the case
 // where VTA panics when a definition of a function or method is not
 // available, which can happen when using analysis package. A successful
@@ -188,3 +216,55 @@ func TestVTACallGraphGo117(t *testing.T) {
 		t.Errorf("`%s`: want superset of %v;\n got %v", file, want, got)
 	}
 }
+
+func TestResult(t *testing.T) {
+	prog, _, err := testProg(t, "testdata/src/callgraph_ho.go", ssa.BuilderMode(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Analyze(ssautil.AllFunctions(prog), nil)
+	if res.CallGraph == nil {
+		t.Fatal("Analyze returned no call graph")
+	}
+
+	var finish *ssa.Function
+	for f := range ssautil.AllFunctions(prog) {
+		if funcName(f) == "Finish" {
+			finish = f
+		}
+	}
+	h := finish.Params[0]
+
+	// The functions flowing to h are those returned by Do.
+	var got []string
+	for _, l := range res.ValueLabels(h) {
+		if l.Func != nil {
+			got = append(got, l.Func.Name())
+		}
+	}
+	if want := []string{"Do$1", "Foo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ValueLabels(h) = %v, want %v", got, want)
+	}
+
+	// h receives the result of Do, passed by Baz.
+	n, ok := res.ValueNode(h)
+	if !ok {
+		t.Fatal("no node for h")
+	}
+	var preds []string
+	for _, p := range res.Predecessors(n) {
+		preds = append(preds, p.String())
+	}
+	if want := []string{"Local(t0)"}; !reflect.DeepEqual(preds, want) {
+		t.Errorf("Predecessors(h) = %v, want %v", preds, want)
+	}
+	for _, p := range res.Predecessors(n) {
+		found := false
+		for _, s := range res.Successors(p) {
+			found = found || s == n
+		}
+		if !found {
+			t.Errorf("%v is a predecessor of h but h is not among its successors", p)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package edit provides structured editing of Go source files.
+//
+// A [Script] accumulates changes expressed in terms of the syntax
+// tree of a file—replace this expression, insert these statements
+// after that one, rename this identifier—and renders them as a
+// minimal set of text edits to the original source. Only the text of
+// the edited nodes changes, so the formatting and comments of the
+// rest of the file are preserved.
+//
+// Example:
+//
+//	s, err := edit.New(fset, file, src)
+//	...
+//	s.Replace(call.Fun, ast.NewIdent("newFunc"))
+//	s.InsertAfter(stmt, &ast.ExprStmt{X: ...})
+//	out, err := s.Apply()
+//
+// New syntax is formatted by [go/format] and indented to match the
+// line on which it is inserted, except for the continuation lines of
+// multi-line raw string literals, whose text is preserved. Nodes passed to the Script must belong
+// to the file from which it was created; nodes of the new syntax may
+// include nodes of the file, but comments within them are lost. To
+// preserve them, use [Script.Text] and [Script.ReplaceText] instead.
+package edit // import "golang.org/x/tools/go/edit"
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// An Edit describes the replacement of a portion of a source file.
+type Edit struct {
+	Start, End int    // byte offsets of the region to replace
+	New        string // the replacement
+}
+
+func (e Edit) String() string {
+	return fmt.Sprintf("{Start:%d,End:%d,New:%q}", e.Start, e.End, e.New)
+}
+
+// A Script is a sequence of changes to a single Go source file.
+//
+// The methods that record changes do not report errors; instead, the
+// first error (such as a node that could not be formatted) is
+// reported by [Script.Edits] or [Script.Apply], along with any
+// conflict between changes.
+type Script struct {
+	fset  *token.FileSet
+	file  *ast.File
+	tok   *token.File
+	src   []byte
+	edits []Edit
+	err   error // first error, if any
+}
+
+// New returns a new empty Script for the specified file, whose
+// source is src and which was parsed using fset. It returns an error
+// if the file was not parsed using fset or its size is not that of src.
+func New(fset *token.FileSet, file *ast.File, src []byte) (*Script, error) {
+	tok := fset.File(file.FileStart)
+	if tok == nil {
+		return nil, fmt.Errorf("file was not parsed using fset")
+	}
+	if tok.Size() != len(src) {
+		return nil, fmt.Errorf("size of file %s (%d) is not len(src) (%d)", tok.Name(), tok.Size(), len(src))
+	}
+	return &Script{fset: fset, file: file, tok: tok, src: src}, nil
+}
+
+// Text returns the source text of node n of the file.
+func (s *Script) Text(n ast.Node) string {
+	start, end := s.offsets(n)
+	return string(s.src[start:end])
+}
+
+// ReplaceText replaces the source text of node n with text.
+// Lines of text after the first are indented
+// to match the line on which n starts.
+func (s *Script) ReplaceText(n ast.Node, text string) {
+	start, end := s.offsets(n)
+	s.add(start, end, s.indent(text, start))
+}
+
+// Replace replaces node n with the syntax new,
+// which must be of a kind that may appear in the same place.
+func (s *Script) Replace(n, new ast.Node) {
+	start, end := s.offsets(n)
+	if text, ok := s.format(new); ok {
+		s.add(start, end, s.indent(text, start))
+	}
+}
+
+// Delete deletes node n. If n occupies lines on its own,
+// as a statement or declaration typically does,
+// the lines are deleted too.
+func (s *Script) Delete(n ast.Node) {
+	start, end := s.offsets(n)
+	if ls, le := s.lineBounds(start, end); ls >= 0 {
+		start, end = ls, le
+	}
+	s.add(start, end, "")
+}
+
+// InsertBefore inserts the statements stmts, each on its own line,
+// before the statement before, which must not be the body of a
+// labeled or control-flow statement.
+func (s *Script) InsertBefore(before ast.Stmt, stmts ...ast.Stmt) {
+	s.insertStmts(before, false, stmts)
+}
+
+// InsertAfter inserts the statements stmts, each on its own line,
+// after the statement after, which must not be the body of a
+// labeled or control-flow statement.
+func (s *Script) InsertAfter(after ast.Stmt, stmts ...ast.Stmt) {
+	s.insertStmts(after, true, stmts)
+}
+
+func (s *Script) insertStmts(pos ast.Stmt, after bool, stmts []ast.Stmt) {
+	start, end := s.offsets(pos)
+	lineStart := s.lineStart(start)
+	indent := string(s.src[lineStart:start])
+	if strings.TrimSpace(indent) != "" {
+		// Statement does not start its line, e.g. "{ x }".
+		indent = leadingSpace(indent)
+	}
+	var buf strings.Builder
+	for _, stmt := range stmts {
+		text, ok := s.format(stmt)
+		if !ok {
+			return
+		}
+		if after {
+			buf.WriteString("\n" + indent)
+		}
+		buf.WriteString(s.indent(text, start))
+		if !after {
+			buf.WriteString("\n" + indent)
+		}
+	}
+	if after {
+		s.add(end, end, buf.String())
+	} else {
+		s.add(start, start, buf.String())
+	}
+}
+
+// InsertDecl inserts the declaration decl after the last
+// declaration of the file, separated from it by a blank line.
+func (s *Script) InsertDecl(decl ast.Decl) {
+	text, ok := s.format(decl)
+	if !ok {
+		return
+	}
+	var last ast.Node = s.file.Name
+	if len(s.file.Decls) > 0 {
+		last = s.file.Decls[len(s.file.Decls)-1]
+	}
+	_, end := s.offsets(last)
+	s.add(end, end, "\n\n"+text)
+}
+
+// Rename changes the name of the identifier id.
+func (s *Script) Rename(id *ast.Ident, name string) {
+	start, end := s.offsets(id)
+	s.add(start, end, name)
+}
+
+// RenameObject changes the name of each identifier of the file that
+// declares or refers to obj, according to info.
+//
+// It does not check that the new name is valid, nor that it does not
+// conflict with other declarations, nor does it rename references
+// in other files.
+func (s *Script) RenameObject(info *types.Info, obj types.Object, name string) {
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if info.Defs[id] == obj || info.Uses[id] == obj {
+				s.Rename(id, name)
+			}
+		}
+		return true
+	})
+}
+
+// Edits returns the text edits that apply the changes of the script,
+// ordered by position. Multiple insertions at the same position are
+// retained in the order they were recorded.
+//
+// It returns an error if any change could not be formatted or if
+// any two changes overlap.
+func (s *Script) Edits() ([]Edit, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	edits := append([]Edit(nil), s.edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].Start != edits[j].Start {
+			return edits[i].Start < edits[j].Start
+		}
+		return edits[i].End < edits[j].End
+	})
+	for i := 1; i < len(edits); i++ {
+		prev, edit := edits[i-1], edits[i]
+		if edit.Start < prev.End {
+			return nil, fmt.Errorf("conflicting edits at %s and %s",
+				s.tok.Position(s.tok.Pos(prev.Start)),
+				s.tok.Position(s.tok.Pos(edit.Start)))
+		}
+	}
+	return edits, nil
+}
+
+// Apply returns the source of the file with the changes of the script
+// applied. It returns an error under the same conditions as [Script.Edits].
+func (s *Script) Apply() ([]byte, error) {
+	edits, err := s.Edits()
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		out.Write(s.src[last:edit.Start])
+		out.WriteString(edit.New)
+		last = edit.End
+	}
+	out.Write(s.src[last:])
+	return out.Bytes(), nil
+}
+
+func (s *Script) add(start, end int, new string) {
+	s.edits = append(s.edits, Edit{start, end, new})
+}
+
+// offsets returns the offsets of the extent of node n,
+// which must belong to the file.
+func (s *Script) offsets(n ast.Node) (start, end int) {
+	if !(s.file.FileStart <= n.Pos() && n.End() <= s.file.FileEnd) {
+		panic(fmt.Sprintf("edit: %T node does not belong to file %s", n, s.tok.Name()))
+	}
+	return s.tok.Offset(n.Pos()), s.tok.Offset(n.End())
+}
+
+// format returns the formatted text of new syntax, recording an
+// error on failure. Positions of the new syntax that are those of the
+// file are honored, so nodes copied from the file keep their layout.
+func (s *Script) format(n ast.Node) (string, bool) {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, s.fset, n); err != nil {
+		if s.err == nil {
+			s.err = fmt.Errorf("formatting %T: %v", n, err)
+		}
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// indent indents each line of text after the first by the
+// leading space of the line containing the offset. Lines that
+// continue a raw string literal are left alone, as indenting
+// them would change the value of the literal.
+func (s *Script) indent(text string, offset int) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+	indent := leadingSpace(string(s.src[s.lineStart(offset):offset]))
+	if indent == "" {
+		return text
+	}
+	raw := rawStrings(text)
+	var buf strings.Builder
+	for i := 0; i < len(text); i++ {
+		buf.WriteByte(text[i])
+		if text[i] != '\n' || i+1 == len(text) || text[i+1] == '\n' {
+			continue
+		}
+		for len(raw) > 0 && raw[0][1] <= i {
+			raw = raw[1:]
+		}
+		if len(raw) == 0 || i < raw[0][0] {
+			buf.WriteString(indent)
+		}
+	}
+	return buf.String()
+}
+
+// rawStrings returns the [start, end) offsets of the raw string
+// literals of the Go source fragment text, in order.
+func rawStrings(text string) [][2]int {
+	var raw [][2]int
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(text))
+	var sc scanner.Scanner
+	sc.Init(file, []byte(text), nil, 0)
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.STRING && strings.HasPrefix(lit, "`") {
+			// lit lacks any carriage returns of the literal,
+			// so find its end in text.
+			start := file.Offset(pos)
+			end := len(text)
+			if i := strings.IndexByte(text[start+1:], '`'); i >= 0 {
+				end = start + 1 + i + 1
+			}
+			raw = append(raw, [2]int{start, end})
+		}
+	}
+	return raw
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func (s *Script) lineStart(offset int) int {
+	return bytes.LastIndexByte(s.src[:offset], '\n') + 1
+}
+
+// lineBounds returns the extent of the lines containing [start, end),
+// including the final newline, if the rest of those lines is blank.
+// Otherwise it returns -1, -1.
+func (s *Script) lineBounds(start, end int) (int, int) {
+	ls := s.lineStart(start)
+	if len(bytes.TrimSpace(s.src[ls:start])) > 0 {
+		return -1, -1
+	}
+	le := len(s.src)
+	if nl := bytes.IndexByte(s.src[end:], '\n'); nl >= 0 {
+		le = end + nl + 1
+	}
+	if len(bytes.TrimSpace(s.src[end:le])) > 0 {
+		return -1, -1
+	}
+	return ls, le
+}
+
+// leadingSpace returns the prefix of s consisting of spaces and tabs.
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edit_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/edit"
+)
+
+const src = `package p
+
+// f does things.
+func f(x int) int {
+	y := x + 1 // increment
+
+	if y > 0 {
+		println(y)
+	}
+	return y
+}
+`
+
+// parse parses src and returns the file and the nodes of interest.
+func parse(t *testing.T) (*token.FileSet, *ast.File, *ast.FuncDecl) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fset, f, f.Decls[0].(*ast.FuncDecl)
+}
+
+func TestScript(t *testing.T) {
+	fset, f, fn := parse(t)
+	body := fn.Body.List
+	assign := body[0].(*ast.AssignStmt)
+	ifStmt := body[1].(*ast.IfStmt)
+
+	s, err := edit.New(fset, f, []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Replace "x + 1" by "x * 2".
+	s.Replace(assign.Rhs[0], &ast.BinaryExpr{
+		X:  assign.Rhs[0].(*ast.BinaryExpr).X,
+		Op: token.MUL,
+		Y:  &ast.BasicLit{Kind: token.INT, Value: "2"},
+	})
+	// Insert a statement after the if, indented correctly.
+	s.InsertAfter(ifStmt, &ast.ExprStmt{X: &ast.CallExpr{
+		Fun:  ast.NewIdent("println"),
+		Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"done"`}},
+	}})
+	// Insert a multi-line statement before the if.
+	s.InsertBefore(ifStmt, &ast.ForStmt{Body: &ast.BlockStmt{List: []ast.Stmt{
+		&ast.BranchStmt{Tok: token.BREAK},
+	}}})
+	// Delete the call within the if, including its line.
+	s.Delete(ifStmt.Body.List[0])
+	s.InsertDecl(&ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{
+		&ast.ValueSpec{Names: []*ast.Ident{ast.NewIdent("v")}, Type: ast.NewIdent("int")},
+	}})
+
+	got, err := s.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `package p
+
+// f does things.
+func f(x int) int {
+	y := x * 2 // increment
+
+	for {
+		break
+	}
+	if y > 0 {
+	}
+	println("done")
+	return y
+}
+
+var v int
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenameObject(t *testing.T) {
+	fset, f, _ := parse(t)
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := edit.New(fset, f, []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.RenameObject(info, pkg.Scope().Lookup("f"), "g")
+	for id, obj := range info.Defs {
+		if id.Name == "y" && obj != nil {
+			s.RenameObject(info, obj, "result")
+		}
+	}
+	got, err := s.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.NewReplacer("func f", "func g", "y", "result").Replace(src)
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	fset, f, fn := parse(t)
+	assign := fn.Body.List[0].(*ast.AssignStmt)
+
+	s, err := edit.New(fset, f, []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Delete(assign)
+	s.ReplaceText(assign.Rhs[0], "0")
+	if _, err := s.Edits(); err == nil || !strings.Contains(err.Error(), "conflicting edits at p.go:5") {
+		t.Errorf("Edits returned error %v, want conflict", err)
+	}
+}
+
+func TestRawString(t *testing.T) {
+	fset, f, fn := parse(t)
+	ifStmt := fn.Body.List[1].(*ast.IfStmt)
+
+	s, err := edit.New(fset, f, []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The continuation lines of the raw string must not be
+	// indented, but those of the statement must be.
+	s.ReplaceText(ifStmt.Body.List[0], "println(`a\nb`,\n`c\n`, 1)")
+	got, err := s.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(src, "\t\tprintln(y)", "\t\tprintln(`a\nb`,\n\t\t`c\n`, 1)", 1)
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNewError(t *testing.T) {
+	fset, f, _ := parse(t)
+	if _, err := edit.New(fset, f, []byte(src+"\n")); err == nil {
+		t.Errorf("New with inconsistent src succeeded")
+	}
+	if _, err := edit.New(token.NewFileSet(), f, []byte(src)); err == nil {
+		t.Errorf("New with wrong FileSet succeeded")
+	}
+}
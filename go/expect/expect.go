@@ -5,6 +5,8 @@
 /*
 Package expect provides support for interpreting structured comments in Go
 source code (including go.mod and go.work files) as test expectations.
+Notes may also appear in the comments of other kinds of files, such as
+assembly files and templates; see ParseText.
 
 [Note: there is an open proposal (golang/go#70229) to deprecate, tag,
 and delete this package. If accepted, the last version of the package
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"go/token"
 	"os"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/go/expect"
@@ -50,6 +51,36 @@ func TestMarker(t *testing.T) {
 				"βMarker": "require golang.org/modfile v0.0.0",
 			},
 		},
+		{
+			filename:    "testdata/test.s",
+			expectNotes: 3,
+			expectMarkers: map[string]string{
+				"αAdd": "·add",
+				"βAdd": "ADDQ",
+			},
+			expectChecks: map[string][]interface{}{
+				"Sum": {"AX"},
+			},
+		},
+		{
+			filename:    "testdata/test.tmpl",
+			expectNotes: 3,
+			expectMarkers: map[string]string{
+				"αTitle": ".Title",
+				"βRange": "range",
+			},
+			expectChecks: map[string][]interface{}{
+				"Items": {int64(2)},
+			},
+		},
+		{
+			filename:    "testdata/test.txt",
+			expectNotes: 2,
+			expectMarkers: map[string]string{
+				"αFirst":  "first",
+				"βSecond": "second",
+			},
+		},
 		{
 			filename:    "testdata/go.fake.work",
 			expectNotes: 2,
@@ -162,3 +193,23 @@ func checkMarker(t *testing.T, fset *token.FileSet, readFile expect.ReadFile, ma
 		t.Errorf("%v: Expected end %v got %v", fset.Position(pos), fset.Position(expectEnd), fset.Position(end))
 	}
 }
+
+func TestParseText(t *testing.T) {
+	sql := expect.CommentSyntax{Line: "--", BlockStart: "/*", BlockEnd: "*/"}
+	content := []byte("SELECT 1; --@one\n/*@two(2)*/ SELECT 2; -- @notanote\n")
+	notes, err := expect.ParseText(token.NewFileSet(), "q.sql", content, sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, n := range notes {
+		names = append(names, n.Name)
+	}
+	if got := strings.Join(names, " "); got != "one two" {
+		t.Errorf("ParseText returned notes %q, want %q", got, "one two")
+	}
+
+	if _, err := expect.ParseText(token.NewFileSet(), "q.sql", []byte("/*@three"), sql); err == nil {
+		t.Errorf("ParseText succeeded with an unterminated comment")
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// func add(x, y int64) int64
+TEXT ·add(SB), NOSPLIT, $0-24 //@mark(αAdd, "·add")
+	MOVQ x+0(FP), AX
+	MOVQ y+8(FP), BX
+	ADDQ BX, AX /*@mark(βAdd, "ADDQ"), check(Sum, "AX")*/
+	MOVQ AX, ret+16(FP)
+	RET
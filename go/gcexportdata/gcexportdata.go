@@ -23,11 +23,16 @@
 //
 // In files written by the compiler, the export data is not at the
 // start of the file. Before calling Read, use [NewReader] to locate
-// the desired portion of the file.
+// the desired portion of the file, or pass the whole file to Read,
+// which locates the export data itself. Read reports an error
+// describing the format of export data it does not support, such as
+// that of gccgo or of Go 1.10 and earlier.
 //
 // The [Write] function in this package encodes the exported API of a
 // Go package ([types.Package]) as a file. Such files can be later
 // decoded by Read, but cannot be consumed by the compiler.
+// [WriteArchive] writes the same data in an archive laid out like
+// those of the compiler.
 //
 // # Future changes
 //
@@ -70,6 +75,7 @@ import (
 	"go/types"
 	"io"
 	"os/exec"
+	"runtime"
 
 	"golang.org/x/tools/internal/gcimporter"
 )
@@ -166,8 +172,16 @@ func Read(in io.Reader, fset *token.FileSet, imports map[string]*types.Package,
 		return nil, fmt.Errorf("reading export data for %q: %v", path, err)
 	}
 
-	if bytes.HasPrefix(data, []byte("!<arch>")) {
-		return nil, fmt.Errorf("can't read export data for %q directly from an archive file (call gcexportdata.NewReader first to extract export data)", path)
+	if bytes.HasPrefix(data, []byte("!<arch>\n")) || bytes.HasPrefix(data, []byte("go object ")) {
+		// An archive or object file written by the compiler,
+		// whose export data has not been located by NewReader.
+		data, err = exportData(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading export data for %q: %v", path, err)
+		}
+	}
+	if err := foreignFormat(data); err != nil {
+		return nil, fmt.Errorf("reading export data for %q: %v", path, err)
 	}
 
 	// The indexed export format starts with an 'i'; the older
@@ -177,7 +191,7 @@ func Read(in io.Reader, fset *token.FileSet, imports map[string]*types.Package,
 		switch data[0] {
 		case 'v', 'c', 'd':
 			// binary, produced by cmd/compile till go1.10
-			return nil, fmt.Errorf("binary (%c) import format is no longer supported", data[0])
+			return nil, fmt.Errorf("binary (%c) import format of Go 1.10 and earlier is no longer supported (export data for %s)", data[0], path)
 
 		case 'i':
 			// indexed, produced by cmd/compile till go1.19,
@@ -209,6 +223,44 @@ func Read(in io.Reader, fset *token.FileSet, imports map[string]*types.Package,
 	return nil, fmt.Errorf("empty export data for %s", path)
 }
 
+// exportData returns the export data within the contents of an
+// archive or object file written by the compiler.
+func exportData(data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	buf := bufio.NewReader(r)
+	_, size, err := gcimporter.FindExportData(buf)
+	if err != nil {
+		if bytes.HasPrefix(data, []byte("!<arch>\n")) && !bytes.Contains(data, []byte("__.PKGDEF")) {
+			// gccgo archives hold object files, but no __.PKGDEF.
+			err = fmt.Errorf("%v (not written by the gc compiler; gccgo export data is not supported)", err)
+		}
+		return nil, err
+	}
+	data = data[len(data)-r.Len()-buf.Buffered():]
+	if size >= 0 && size < int64(len(data)) {
+		data = data[:size]
+	}
+	return data, nil
+}
+
+// foreignFormat returns an error describing the format of export data
+// written by a toolchain other than gc, or nil if it is not one.
+func foreignFormat(data []byte) error {
+	switch {
+	case bytes.HasPrefix(data, []byte("v1;\n")),
+		bytes.HasPrefix(data, []byte("v2;\n")),
+		bytes.HasPrefix(data, []byte("v3;\n")):
+		return fmt.Errorf("gccgo export data (version %c) is not supported", data[1])
+	case bytes.HasPrefix(data, []byte("\x7fELF")),
+		bytes.HasPrefix(data, []byte("\x01\xdf")), // XCOFF32
+		bytes.HasPrefix(data, []byte("\x01\xf7")): // XCOFF64
+		return fmt.Errorf("object file not written by the gc compiler, such as by gccgo, whose export data is not supported")
+	case bytes.HasPrefix(data, []byte("package ")):
+		return fmt.Errorf("textual export data of Go 1.6 and earlier is no longer supported")
+	}
+	return nil
+}
+
 // Write writes encoded type information for the specified package to out.
 // The FileSet provides file position information for named objects.
 func Write(out io.Writer, fset *token.FileSet, pkg *types.Package) error {
@@ -218,6 +270,33 @@ func Write(out io.Writer, fset *token.FileSet, pkg *types.Package) error {
 	return gcimporter.IExportData(out, fset, pkg)
 }
 
+// WriteArchive writes encoded type information for the specified
+// package to out, as by [Write], in an archive (.a) file laid out like
+// those written by the compiler, so that the data may be located by
+// [NewReader] (or by Read itself) like that of compiler output.
+// Like the output of Write, the archive cannot be consumed by the
+// compiler.
+func WriteArchive(out io.Writer, fset *token.FileSet, pkg *types.Package) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "go object %s %s\n\n$$B\n", runtime.GOOS, runtime.GOARCH)
+	if err := Write(&buf, fset, pkg); err != nil {
+		return err
+	}
+	buf.WriteString("\n$$\n")
+	// The header of the member is its name, modification time,
+	// owner and group IDs, mode, size, and terminator. The size
+	// excludes the padding that follows a member of odd size.
+	hdr := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8o%-10d`\n", "__.PKGDEF", 0, 0, 0, 0o644, buf.Len())
+	if buf.Len()%2 != 0 {
+		buf.WriteByte('\n') // archive members are padded to an even size
+	}
+	if _, err := io.WriteString(out, "!<arch>\n"+hdr); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(out)
+	return err
+}
+
 // ReadBundle reads an export bundle from in, decodes it, and returns type
 // information for the packages.
 // File position information is added to fset.
@@ -0,0 +1,136 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gcexportdata_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/internal/testenv"
+)
+
+const src = `package p
+
+type T struct{ X int }
+
+func (T) M() string { return "" }
+
+var V = T{}
+`
+
+// checkPackage returns the type-checked package p of src.
+func checkPackage(t *testing.T, fset *token.FileSet) *types.Package {
+	t.Helper()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("example.com/p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestWriteArchive(t *testing.T) {
+	fset := token.NewFileSet()
+	pkg := checkPackage(t, fset)
+
+	var buf bytes.Buffer
+	if err := gcexportdata.WriteArchive(&buf, fset, pkg); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+	if !bytes.HasPrefix(archive, []byte("!<arch>\n__.PKGDEF")) {
+		t.Fatalf("WriteArchive wrote %.40q..., want an archive", archive)
+	}
+
+	// The header records the size of the member without the padding
+	// that makes it even, as the size of compiler output does.
+	const hdrEnd = len("!<arch>\n") + 60
+	size, err := strconv.Atoi(strings.TrimSpace(string(archive[hdrEnd-12 : hdrEnd-2])))
+	if err != nil {
+		t.Fatalf("bad size in archive header %q: %v", archive[:hdrEnd], err)
+	}
+	if pad := len(archive) - hdrEnd - size; pad != size%2 {
+		t.Errorf("archive member of size %d is followed by %d bytes, want %d", size, pad, size%2)
+	}
+	if member := archive[hdrEnd : hdrEnd+size]; !bytes.HasSuffix(member, []byte("\n$$\n")) {
+		t.Errorf("archive member ends with %q, want end of export data", member[max(0, len(member)-8):])
+	}
+
+	// read decodes the data, and checks the result.
+	read := func(name string, data []byte) {
+		t.Helper()
+		pkg2, err := gcexportdata.Read(bytes.NewReader(data), token.NewFileSet(), make(map[string]*types.Package), "example.com/p")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, want := strings.Join(pkg2.Scope().Names(), " "), "T V"; got != want {
+			t.Errorf("%s: names = %q, want %q", name, got, want)
+		}
+	}
+
+	r, err := gcexportdata.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(r); err != nil {
+		t.Fatal(err)
+	}
+	read("NewReader", data.Bytes())
+	read("whole archive", archive)
+}
+
+func TestReadCompilerOutput(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+	t.Setenv("GOFLAGS", "")
+
+	filename, path := gcexportdata.Find("fmt", "")
+	if filename == "" {
+		t.Skip("can't find export data for fmt")
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Read locates the export data without NewReader.
+	pkg, err := gcexportdata.Read(bytes.NewReader(data), token.NewFileSet(), make(map[string]*types.Package), path)
+	if err != nil && strings.Contains(err.Error(), "update tool") {
+		t.Skipf("toolchain is newer than this package: %v", err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Scope().Lookup("Println") == nil {
+		t.Errorf("fmt.Println not found")
+	}
+}
+
+func TestReadForeignFormats(t *testing.T) {
+	for _, test := range []struct {
+		data, want string
+	}{
+		{"v3;\npackage p\n", "gccgo export data (version 3)"},
+		{"\x7fELF\x02\x01\x01", "not written by the gc compiler"},
+		{"!<arch>\np.o/           0           0     0     644     4         `\nxxxx", "gccgo export data is not supported"},
+		{"go object linux amd64\n\n$$\npackage p\n", "textual export data"},
+		{"c\x01\x02", "binary (c) import format of Go 1.10 and earlier"},
+		{"?????", "unexpected export data"},
+	} {
+		_, err := gcexportdata.Read(strings.NewReader(test.data), token.NewFileSet(), make(map[string]*types.Package), "p")
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Read(%q) returned error %v, want one containing %q", test.data, err, test.want)
+		}
+	}
+}
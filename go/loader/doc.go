@@ -8,7 +8,9 @@
 // later use.
 //
 // Deprecated: This is an older API and does not have support
-// for modules. Use golang.org/x/tools/go/packages instead.
+// for modules unless Config.Packages is set, in which case it
+// delegates the location of packages to go/packages.
+// Use golang.org/x/tools/go/packages instead.
 //
 // The package defines two primary types: Config, which specifies a
 // set of initial packages to load and various other options; and
@@ -53,7 +55,9 @@
 // The WORKSPACE is the set of packages accessible to the loader.  The
 // workspace is defined by Config.Build, a *build.Context.  The
 // default context treats subdirectories of $GOROOT and $GOPATH as
-// packages, but this behavior may be overridden.
+// packages, but this behavior may be overridden.  If Config.Packages is
+// set, the workspace is instead the one seen by the go command,
+// including modules.
 //
 // An AD HOC package is one specified as a set of source files on the
 // command line.  In the simplest case, it may consist of a single file
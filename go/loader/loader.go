@@ -22,7 +22,9 @@ import (
 	"time"
 
 	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/internal/cgo"
+	"golang.org/x/tools/go/packages"
 )
 
 var ignoreVendor build.ImportMode
@@ -126,6 +128,27 @@ type Config struct {
 	//
 	// It must be safe to call concurrently from multiple goroutines.
 	AfterTypeCheck func(info *PackageInfo, files []*ast.File)
+
+	// If Packages is non-nil, Load uses go/packages, and thus the
+	// go command, to locate the initial packages and their
+	// dependencies, so that it supports modules. Its Dir, Env,
+	// BuildFlags, Overlay, and Context fields configure the query;
+	// its other fields are ignored, and Dir defaults to Cwd.
+	// Build and FindPackage are then not used to locate packages,
+	// though Build is still used to read files absent from the
+	// overlay.
+	//
+	// The keys of ImportPkgs are then package patterns, such as
+	// "./...", that denote each of the packages they match.
+	// The packages are parsed and type-checked by Load as usual.
+	Packages *packages.Config
+
+	// If DepsFromExportData is true and Packages is non-nil,
+	// the dependencies of the initial packages are loaded from
+	// the export data produced by the compiler rather than
+	// type-checked from source. Their PackageInfos have no Files
+	// and an empty Info.
+	DepsFromExportData bool
 }
 
 // A PkgSpec specifies a non-importable package to be created by Load.
@@ -405,6 +428,8 @@ type importer struct {
 	// packages.  Nodes are identified by their import paths.
 	graphMu sync.Mutex
 	graph   map[string]map[string]bool
+
+	packages *packagesFinder // non-nil if Config.Packages is set
 }
 
 type findpkgKey struct {
@@ -499,6 +524,16 @@ func (conf *Config) Load() (*Program, error) {
 		graph:    make(map[string]map[string]bool),
 	}
 
+	importPkgs := conf.ImportPkgs
+	if conf.Packages != nil {
+		f, err := newPackagesFinder(conf)
+		if err != nil {
+			return nil, err
+		}
+		imp.packages = f
+		importPkgs = f.importPkgs
+	}
+
 	// -- loading proper (concurrent phase) --------------------------------
 
 	var errpkgs []string // packages that contained errors
@@ -506,7 +541,7 @@ func (conf *Config) Load() (*Program, error) {
 	// Load the initially imported packages and their dependencies,
 	// in parallel.
 	// No vendor check on packages imported from the command line.
-	infos, importErrors := imp.importAll("", conf.Cwd, conf.ImportPkgs, ignoreVendor)
+	infos, importErrors := imp.importAll("", conf.Cwd, importPkgs, ignoreVendor)
 	for _, ie := range importErrors {
 		conf.TypeChecker.Error(ie.err) // failed to create package
 		errpkgs = append(errpkgs, ie.path)
@@ -518,7 +553,7 @@ func (conf *Config) Load() (*Program, error) {
 	// Augment the designated initial packages by their tests.
 	// Dependencies are loaded in parallel.
 	var xtestPkgs []*build.Package
-	for importPath, augment := range conf.ImportPkgs {
+	for importPath, augment := range importPkgs {
 		if !augment {
 			continue
 		}
@@ -708,10 +743,14 @@ func markErrorFreePackages(allPackages map[*types.Package]*PackageInfo) {
 
 // build returns the effective build context.
 func (conf *Config) build() *build.Context {
+	ctxt := &build.Default
 	if conf.Build != nil {
-		return conf.Build
+		ctxt = conf.Build
+	}
+	if conf.Packages != nil && conf.Packages.Overlay != nil {
+		ctxt = buildutil.OverlayContext(ctxt, conf.Packages.Overlay)
 	}
-	return &build.Default
+	return ctxt
 }
 
 // parsePackageFiles enumerates the files belonging to package path,
@@ -833,8 +872,12 @@ func (imp *importer) findPackage(importPath, fromDir string, mode build.ImportMo
 		imp.findpkg[key] = v
 		imp.findpkgMu.Unlock()
 
+		find := imp.conf.FindPackage
+		if imp.packages != nil {
+			find = imp.packages.find
+		}
 		ioLimit <- true
-		v.bp, v.err = imp.conf.FindPackage(imp.conf.build(), importPath, fromDir, mode)
+		v.bp, v.err = find(imp.conf.build(), importPath, fromDir, mode)
 		<-ioLimit
 
 		if _, ok := v.err.(*build.NoGoError); ok {
@@ -956,6 +999,11 @@ func (imp *importer) startLoad(bp *build.Package) *importInfo {
 // load implements package loading by parsing Go source files
 // located by go/build.
 func (imp *importer) load(bp *build.Package) *PackageInfo {
+	if imp.packages != nil && imp.conf.DepsFromExportData {
+		if filename := imp.packages.exportFile(bp.ImportPath); filename != "" {
+			return imp.loadExportData(bp, filename)
+		}
+	}
 	info := imp.newPackageInfo(bp.ImportPath, bp.Dir)
 	info.Importable = true
 	files, errs := imp.conf.parsePackageFiles(bp, 'g')
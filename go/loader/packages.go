@@ -0,0 +1,288 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loader
+
+// This file defines the use of go/packages to locate packages,
+// enabled by Config.Packages.
+
+import (
+	"bufio"
+	"fmt"
+	"go/build"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
+)
+
+// A packagesFinder locates packages using go/packages.
+// Its find method is used in place of Config.FindPackage.
+type packagesFinder struct {
+	cfg packages.Config
+
+	// importPkgs is Config.ImportPkgs with its patterns
+	// replaced by the paths of the packages they match.
+	importPkgs map[string]bool
+
+	mu     sync.Mutex                     // guards the fields below
+	byPath map[string]*packages.Package   // non-test packages, by package path
+	byDir  map[string][]*packages.Package // all packages, including test variants, by directory
+	tests  map[string]*packages.Package   // packages augmented by their tests, by package path
+	xtests map[string]*packages.Package   // external test packages, by path of the package under test
+}
+
+// newPackagesFinder queries the go command about the initial
+// packages of conf and their dependencies.
+func newPackagesFinder(conf *Config) (*packagesFinder, error) {
+	f := &packagesFinder{
+		cfg:        *conf.Packages,
+		importPkgs: make(map[string]bool),
+		byPath:     make(map[string]*packages.Package),
+		byDir:      make(map[string][]*packages.Package),
+		tests:      make(map[string]*packages.Package),
+		xtests:     make(map[string]*packages.Package),
+	}
+	f.cfg.Mode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+		packages.NeedImports | packages.NeedDeps | packages.NeedForTest
+	if conf.DepsFromExportData {
+		f.cfg.Mode |= packages.NeedExportFile
+	}
+	f.cfg.Fset = nil
+	f.cfg.ParseFile = nil
+	f.cfg.Tests = false
+	if f.cfg.Dir == "" {
+		f.cfg.Dir = conf.Cwd
+	}
+
+	var patterns []string
+	for pattern, tests := range conf.ImportPkgs {
+		patterns = append(patterns, pattern)
+		f.cfg.Tests = f.cfg.Tests || tests
+	}
+	if len(patterns) == 0 {
+		return f, nil
+	}
+	sort.Strings(patterns)
+	initial, err := packages.Load(&f.cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	f.add(initial)
+
+	// Map each pattern to the packages it matches.
+	// The go command does not say which pattern matched which
+	// package, so we match them again here.
+	for _, pattern := range patterns {
+		tests := conf.ImportPkgs[pattern]
+		matched := false
+		for _, p := range initial {
+			if p.ForTest == "" && f.byPath[p.PkgPath] == p && f.matchPattern(pattern, p) {
+				f.importPkgs[p.PkgPath] = f.importPkgs[p.PkgPath] || tests
+				matched = true
+			}
+		}
+		if !matched {
+			// Leave find to report the error.
+			f.importPkgs[pattern] = tests
+		}
+	}
+	return f, nil
+}
+
+// add records the packages pkgs and their dependencies.
+func (f *packagesFinder) add(pkgs []*packages.Package) {
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if _, ok := f.byPath[p.PkgPath]; ok && p.ForTest == "" {
+			return // already seen
+		}
+		if dir := packageDir(p); dir != "" {
+			f.byDir[dir] = append(f.byDir[dir], p)
+		}
+		switch {
+		case p.ForTest == "":
+			if !strings.HasSuffix(p.ID, ".test") {
+				f.byPath[p.PkgPath] = p
+			}
+		case p.PkgPath == p.ForTest:
+			f.tests[p.ForTest] = p
+		case p.PkgPath == p.ForTest+"_test":
+			f.xtests[p.ForTest] = p
+		}
+	})
+}
+
+// matchPattern reports whether the package pattern matches p.
+// Patterns are matched as by the go command, except that the
+// meta-packages "all", "std", and "cmd" match every package.
+func (f *packagesFinder) matchPattern(pattern string, p *packages.Package) bool {
+	switch pattern {
+	case "all", "std", "cmd":
+		return true
+	}
+	name := p.PkgPath
+	if build.IsLocalImport(pattern) || filepath.IsAbs(pattern) {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(f.cfg.Dir, pattern)
+		}
+		pattern = filepath.ToSlash(pattern)
+		name = filepath.ToSlash(packageDir(p))
+	}
+	if !strings.Contains(pattern, "...") {
+		return pattern == name
+	}
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if strings.HasSuffix(re, `/.*`) {
+		// "a/..." also matches "a".
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	return regexp.MustCompile(`^` + re + `$`).MatchString(name)
+}
+
+// find returns the package denoted by importPath in the directory
+// fromDir. It has the signature of Config.FindPackage.
+func (f *packagesFinder) find(ctxt *build.Context, importPath, fromDir string, mode build.ImportMode) (*build.Package, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var p *packages.Package
+	for _, from := range f.byDir[fromDir] {
+		if q := from.Imports[importPath]; q != nil {
+			p = f.byPath[q.PkgPath]
+			break
+		}
+	}
+	if p == nil {
+		p = f.byPath[importPath]
+	}
+	if p == nil {
+		// An import not yet known, such as one in an ad hoc
+		// package: ask the go command about it now.
+		cfg := f.cfg
+		cfg.Dir = fromDir
+		cfg.Tests = false
+		pkgs, err := packages.Load(&cfg, importPath)
+		if err != nil {
+			return nil, err
+		}
+		f.add(pkgs)
+		if len(pkgs) == 1 {
+			p = f.byPath[pkgs[0].PkgPath]
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("cannot find package %q in %s", importPath, fromDir)
+	}
+	if len(p.CompiledGoFiles) == 0 && len(p.Errors) > 0 {
+		return nil, p.Errors[0]
+	}
+
+	bp := &build.Package{
+		Dir:        packageDir(p),
+		Name:       p.Name,
+		ImportPath: p.PkgPath,
+		GoFiles:    p.CompiledGoFiles, // already processed by cgo
+	}
+	for path := range p.Imports {
+		bp.Imports = append(bp.Imports, path)
+	}
+	sort.Strings(bp.Imports)
+	if t := f.tests[p.PkgPath]; t != nil {
+		nontest := make(map[string]bool)
+		for _, file := range p.CompiledGoFiles {
+			nontest[file] = true
+		}
+		for _, file := range t.CompiledGoFiles {
+			if !nontest[file] {
+				bp.TestGoFiles = append(bp.TestGoFiles, file)
+			}
+		}
+	}
+	if x := f.xtests[p.PkgPath]; x != nil {
+		bp.XTestGoFiles = x.CompiledGoFiles
+	}
+	return bp, nil
+}
+
+// exportFile returns the name of the export data file of the
+// dependency whose package path is path, or "" if it is an initial
+// package or has no export data.
+func (f *packagesFinder) exportFile(path string) string {
+	if _, ok := f.importPkgs[path]; ok {
+		return ""
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p := f.byPath[path]; p != nil {
+		return p.ExportFile
+	}
+	return ""
+}
+
+// packageDir returns the directory of package p.
+func packageDir(p *packages.Package) string {
+	if p.Dir != "" {
+		return p.Dir
+	}
+	if len(p.GoFiles) > 0 {
+		return filepath.Dir(p.GoFiles[0])
+	}
+	return ""
+}
+
+// loadExportData loads the dependency bp from the export data file
+// filename, once its own dependencies are loaded.
+func (imp *importer) loadExportData(bp *build.Package, filename string) *PackageInfo {
+	info := &PackageInfo{
+		Importable: true,
+		errorFunc:  imp.conf.TypeChecker.Error,
+		dir:        bp.Dir,
+	}
+
+	// The export data refers to the dependencies,
+	// which must be loaded first.
+	imports := make(map[string]bool)
+	for _, path := range bp.Imports {
+		imports[path] = true
+	}
+	imp.importAll(bp.ImportPath, bp.Dir, imports, 0)
+
+	pkg, err := imp.readExportData(bp.ImportPath, filename)
+	if err != nil {
+		info.appendError(err)
+		pkg = types.NewPackage(bp.ImportPath, bp.Name)
+	}
+	info.Pkg = pkg
+
+	imp.progMu.Lock()
+	imp.prog.AllPackages[pkg] = info
+	imp.prog.importMap[bp.ImportPath] = pkg
+	imp.progMu.Unlock()
+	return info
+}
+
+// readExportData reads the package path from the export data file
+// filename, using the packages loaded so far for its dependencies.
+func (imp *importer) readExportData(path, filename string) (*types.Package, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gcexportdata.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("reading export data for %q: %v", path, err)
+	}
+
+	imp.progMu.Lock()
+	defer imp.progMu.Unlock()
+	return gcexportdata.Read(r, imp.conf.fset(), imp.prog.importMap, path)
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loader_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// writeModule writes the files of a small module
+// to a temporary directory and returns its name.
+func writeModule(t *testing.T) string {
+	t.Helper()
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GOFLAGS", "")
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":          "module example.com/m\n\ngo 1.18\n",
+		"a/a.go":          "package a\n\nimport \"example.com/m/b\"\n\nvar A = b.B\n",
+		"a/a_test.go":     "package a\n\nvar T = A + 1\n",
+		"a/a_ext_test.go": "package a_test\n\nimport \"example.com/m/a\"\n\nvar X = a.T\n",
+		"b/b.go":          "package b\n\nconst B = 1\n",
+		"c/c.go":          "package c\n\nimport \"example.com/m/b\"\n\nvar C = b.B\n",
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadPackages(t *testing.T) {
+	dir := writeModule(t)
+	conf := loader.Config{
+		Packages: &packages.Config{Dir: dir},
+		ImportPkgs: map[string]bool{
+			"./...":           false,
+			"example.com/m/a": true,
+		},
+	}
+	prog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var imported []string
+	for path := range prog.Imported {
+		imported = append(imported, path)
+	}
+	sort.Strings(imported)
+	if got, want := strings.Join(imported, " "), "example.com/m/a example.com/m/b example.com/m/c"; got != want {
+		t.Errorf("Imported = %s, want %s", got, want)
+	}
+
+	a := prog.Imported["example.com/m/a"]
+	if a.Pkg.Scope().Lookup("T") == nil {
+		t.Errorf("package a was not augmented by its tests")
+	}
+	if len(prog.Created) != 1 || prog.Created[0].Pkg.Path() != "example.com/m/a_test" {
+		t.Fatalf("Created = %v, want [example.com/m/a_test]", prog.Created)
+	}
+	if x := prog.Created[0].Pkg.Scope().Lookup("X"); x == nil || x.Type().String() != "int" {
+		t.Errorf("a_test.X = %v, want an int variable", x)
+	}
+}
+
+func TestLoadPackagesOverlay(t *testing.T) {
+	dir := writeModule(t)
+	conf := loader.Config{
+		Packages: &packages.Config{
+			Dir: dir,
+			Overlay: map[string][]byte{
+				filepath.Join(dir, "c", "c.go"): []byte("package c\n\nimport \"example.com/m/b\"\n\nvar D = b.B\n"),
+			},
+		},
+	}
+	conf.Import("example.com/m/c")
+	prog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := prog.Package("example.com/m/c")
+	if c.Pkg.Scope().Lookup("D") == nil || c.Pkg.Scope().Lookup("C") != nil {
+		t.Errorf("package c does not reflect the overlay: %v", c.Pkg.Scope().Names())
+	}
+}
+
+func TestLoadPackagesFromExportData(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+	dir := writeModule(t)
+	conf := loader.Config{
+		Packages:           &packages.Config{Dir: dir},
+		DepsFromExportData: true,
+		AllowErrors:        true,
+	}
+	conf.TypeChecker.Error = func(error) {}
+	conf.Import("./c")
+	prog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := prog.Package("example.com/m/b")
+	if b == nil {
+		t.Fatal("dependency b was not loaded")
+	}
+	if len(b.Errors) > 0 && strings.Contains(b.Errors[0].Error(), "update tool") {
+		t.Skipf("toolchain is newer than this package: %v", b.Errors[0])
+	}
+	if len(b.Files) > 0 || b.Pkg.Scope().Lookup("B") == nil || len(b.Errors) > 0 {
+		t.Errorf("dependency b was not loaded from export data: files %d, errors %v", len(b.Files), b.Errors)
+	}
+	c := prog.Package("example.com/m/c")
+	if len(c.Files) != 1 || len(c.Errors) > 0 {
+		t.Errorf("initial package c has %d files and errors %v, want 1 file and no errors", len(c.Files), c.Errors)
+	}
+}
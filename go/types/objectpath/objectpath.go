@@ -26,6 +26,7 @@ package objectpath
 import (
 	"fmt"
 	"go/types"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -93,6 +94,22 @@ type Path string
 // The encoding is not maximally compact---every R or P is
 // followed by an A, for example---but this simplifies the
 // encoder and decoder.
+//
+// The paths returned by [Encoder.ForLocal] for objects declared within
+// a function body start instead with the path of the enclosing
+// package-level function or method, followed by 'L', the indices of
+// the nested blocks (in the Scope.Child sense) that lead from the
+// function's scope to the block declaring the object, separated by
+// commas, then ':' and the object's name. For example, in
+//
+//	func F() {
+//		if true {
+//			type T struct{ X int }
+//		}
+//	}
+//
+// field X has the path "FL0:T.UF0". The colon, which cannot appear
+// in other paths, marks a local path.
 const (
 	// object->type operators
 	opType = '.' // .Type()		  (Object)
@@ -113,6 +130,9 @@ const (
 	opField  = 'F' // .Field(i)	(Struct)
 	opMethod = 'M' // .Method(i)	(Named or Interface; not Struct: "promoted" names are ignored)
 	opObj    = 'O' // .Obj()	(Named, TypeParam)
+
+	// function->object operator
+	opLocal = 'L' // .Scope().Child(i)...Lookup(name)	(Func)
 )
 
 // For is equivalent to new(Encoder).For(obj).
@@ -125,7 +145,9 @@ func For(obj types.Object) (Path, error) {
 // An Encoder amortizes the cost of encoding the paths of multiple objects.
 // The zero value of an Encoder is ready to use.
 type Encoder struct {
-	scopeMemo map[*types.Scope][]types.Object // memoization of scopeObjects
+	scopeMemo map[*types.Scope][]types.Object          // memoization of scopeObjects
+	funcMemo  map[*types.Package][]localFunc           // memoization of localFuncs
+	indexMemo map[*types.Package]map[types.Object]Path // memoization of search
 }
 
 // For returns the path to an object relative to its package,
@@ -263,13 +285,52 @@ func (enc *Encoder) For(obj types.Object) (Path, error) {
 	}
 
 	// 4. Search the API for the path to the var (field/param/result) or method.
+	if path, ok := enc.search(pkg, obj); ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("can't find path for %v in %s", obj, pkg.Path())
+}
+
+// search returns the path to obj, a var (field/param/result), method,
+// or type parameter of the API of pkg, by searching the API.
+//
+// The first search within a package stops when it finds obj. The
+// second builds an index of the paths of all objects in the API, so
+// that it and later searches need only consult the index. This
+// amortizes the cost of the search when the same Encoder is used for
+// many objects of the same package, while keeping the cost of a
+// single search low.
+func (enc *Encoder) search(pkg *types.Package, obj types.Object) (Path, bool) {
+	index, searched := enc.indexMemo[pkg]
+	if !searched {
+		if enc.indexMemo == nil {
+			enc.indexMemo = make(map[*types.Package]map[types.Object]Path)
+		}
+		enc.indexMemo[pkg] = nil // searched once, not yet indexed
+		if r := searchAPI(enc.scopeObjects(pkg.Scope()), &finder{obj: obj}); r != nil {
+			return Path(r), true
+		}
+		return "", false
+	}
+	if index == nil {
+		index = make(map[types.Object]Path)
+		searchAPI(enc.scopeObjects(pkg.Scope()), &finder{index: index})
+		enc.indexMemo[pkg] = index
+	}
+	path, ok := index[obj]
+	return path, ok
+}
 
+// searchAPI searches the API of a package, whose scope objects are
+// objs, for f.obj, and returns the path to it, or nil if not found.
+// In indexing mode (f.index != nil), it instead records the path of
+// each object it encounters, and returns nil.
+func searchAPI(objs []types.Object, f *finder) []byte {
 	// First inspect package-level named types.
 	// In the presence of path aliases, these give
 	// the best paths because non-types may
 	// refer to types, but not the reverse.
 	empty := make([]byte, 0, 48) // initial space
-	objs := enc.scopeObjects(scope)
 	for _, o := range objs {
 		tname, ok := o.(*types.TypeName)
 		if !ok {
@@ -281,26 +342,26 @@ func (enc *Encoder) For(obj types.Object) (Path, error) {
 
 		T := o.Type()
 		if alias, ok := T.(*types.Alias); ok {
-			if r := findTypeParam(obj, aliases.TypeParams(alias), path, opTypeParam); r != nil {
-				return Path(r), nil
+			if r := f.root().findTypeParam(aliases.TypeParams(alias), path, opTypeParam); r != nil {
+				return r
 			}
-			if r := find(obj, aliases.Rhs(alias), append(path, opRhs)); r != nil {
-				return Path(r), nil
+			if r := f.root().find(aliases.Rhs(alias), append(path, opRhs)); r != nil {
+				return r
 			}
 
 		} else if tname.IsAlias() {
 			// legacy alias
-			if r := find(obj, T, path); r != nil {
-				return Path(r), nil
+			if r := f.root().find(T, path); r != nil {
+				return r
 			}
 
 		} else if named, ok := T.(*types.Named); ok {
 			// defined (named) type
-			if r := findTypeParam(obj, named.TypeParams(), path, opTypeParam); r != nil {
-				return Path(r), nil
+			if r := f.root().findTypeParam(named.TypeParams(), path, opTypeParam); r != nil {
+				return r
 			}
-			if r := find(obj, named.Underlying(), append(path, opUnderlying)); r != nil {
-				return Path(r), nil
+			if r := f.root().find(named.Underlying(), append(path, opUnderlying)); r != nil {
+				return r
 			}
 		}
 	}
@@ -312,8 +373,8 @@ func (enc *Encoder) For(obj types.Object) (Path, error) {
 		if _, ok := o.(*types.TypeName); !ok {
 			if o.Exported() {
 				// exported non-type (const, var, func)
-				if r := find(obj, o.Type(), append(path, opType)); r != nil {
-					return Path(r), nil
+				if r := f.root().find(o.Type(), append(path, opType)); r != nil {
+					return r
 				}
 			}
 			continue
@@ -329,17 +390,147 @@ func (enc *Encoder) For(obj types.Object) (Path, error) {
 			for i := 0; i < T.NumMethods(); i++ {
 				m := T.Method(i)
 				path2 := appendOpArg(path, opMethod, i)
-				if m == obj {
-					return Path(path2), nil // found declared method
+				if f.found(m, path2) {
+					return path2 // found declared method
 				}
-				if r := find(obj, m.Type(), append(path2, opType)); r != nil {
-					return Path(r), nil
+				if r := f.root().find(m.Type(), append(path2, opType)); r != nil {
+					return r
 				}
 			}
 		}
 	}
 
-	return "", fmt.Errorf("can't find path for %v in %s", obj, pkg.Path())
+	return nil
+}
+
+// ForLocal is like [Encoder.For], but it also returns paths for objects
+// declared within the body of a package-level function or method, such
+// as local types, constants and variables, and the fields and methods
+// of local types.
+//
+// Such paths are meaningful only for packages produced by
+// type-checking syntax, since export data records no function bodies;
+// the Object function reports an error for them otherwise. They also
+// depend on the nesting of blocks within the function, so they are less
+// stable across edits than the paths returned by For.
+func (enc *Encoder) ForLocal(obj types.Object) (Path, error) {
+	path, err := enc.For(obj)
+	if err == nil || obj.Pkg() == nil {
+		return path, err
+	}
+	funcs := enc.localFuncs(obj.Pkg())
+
+	// Is obj declared in a local block?
+	if scope := obj.Parent(); scope != nil && scope.Lookup(obj.Name()) == obj {
+		var indices []int
+		for ; scope != nil; scope = scope.Parent() {
+			for _, fn := range funcs {
+				if fn.scope == scope {
+					slices.Reverse(indices)
+					return Path(appendLocal(fn.path, indices, obj.Name())), nil
+				}
+			}
+			parent := scope.Parent()
+			if parent == nil {
+				break
+			}
+			for i := 0; i < parent.NumChildren(); i++ {
+				if parent.Child(i) == scope {
+					indices = append(indices, i)
+					break
+				}
+			}
+		}
+		return "", err
+	}
+
+	// Search the types of the objects declared in each
+	// local block for the field, method or type parameter.
+	var search func(fn localFunc, scope *types.Scope, indices []int) []byte
+	search = func(fn localFunc, scope *types.Scope, indices []int) []byte {
+		for _, name := range scope.Names() {
+			o := scope.Lookup(name)
+			path := append(appendLocal(fn.path, indices, name), opType)
+			T := o.Type()
+			if _, ok := o.(*types.TypeName); ok {
+				if named, ok := T.(*types.Named); ok && named.Obj() == o {
+					T = named.Underlying()
+					path = append(path, opUnderlying)
+				} else if alias, ok := T.(*types.Alias); ok {
+					T = aliases.Rhs(alias)
+					path = append(path, opRhs)
+				}
+			}
+			if r := find(obj, T, path); r != nil {
+				return r
+			}
+		}
+		for i := 0; i < scope.NumChildren(); i++ {
+			if r := search(fn, scope.Child(i), append(indices[:len(indices):len(indices)], i)); r != nil {
+				return r
+			}
+		}
+		return nil
+	}
+	for _, fn := range funcs {
+		if r := search(fn, fn.scope, nil); r != nil {
+			return Path(r), nil
+		}
+	}
+	return "", err
+}
+
+// A localFunc is a package-level function or method
+// whose body may declare local objects.
+type localFunc struct {
+	scope *types.Scope
+	path  []byte // path to the function; callers must not modify
+}
+
+// localFuncs returns the package-level functions and methods of pkg
+// that have a scope, that is, those that were type-checked from syntax.
+func (enc *Encoder) localFuncs(pkg *types.Package) []localFunc {
+	if funcs, ok := enc.funcMemo[pkg]; ok {
+		return funcs
+	}
+	var funcs []localFunc
+	add := func(fn *types.Func, path []byte) {
+		if scope := fn.Scope(); scope != nil {
+			funcs = append(funcs, localFunc{scope, path[:len(path):len(path)]})
+		}
+	}
+	for _, o := range enc.scopeObjects(pkg.Scope()) {
+		switch o := o.(type) {
+		case *types.Func:
+			add(o, []byte(o.Name()))
+		case *types.TypeName:
+			if named, ok := types.Unalias(o.Type()).(*types.Named); ok && named.Obj() == o {
+				for i := 0; i < named.NumMethods(); i++ {
+					add(named.Method(i), appendOpArg(append([]byte(o.Name()), opType), opMethod, i))
+				}
+			}
+		}
+	}
+	if enc.funcMemo == nil {
+		enc.funcMemo = make(map[*types.Package][]localFunc)
+	}
+	enc.funcMemo[pkg] = funcs
+	return funcs
+}
+
+// appendLocal returns the path of the local object of the specified
+// name in the block denoted by indices, relative to the function whose
+// path is fnpath.
+func appendLocal(fnpath []byte, indices []int, name string) []byte {
+	path := append(fnpath, opLocal)
+	for i, index := range indices {
+		if i > 0 {
+			path = append(path, ',')
+		}
+		path = strconv.AppendInt(path, int64(index), 10)
+	}
+	path = append(path, ':')
+	return append(path, name...)
 }
 
 func appendOpArg(path []byte, op byte, arg int) []byte {
@@ -461,10 +652,32 @@ func find(obj types.Object, T types.Type, path []byte) []byte {
 // finder closes over search state for a call to find.
 type finder struct {
 	obj             types.Object             // the sought object
+	index           map[types.Object]Path    // in indexing mode, the paths of all objects found
 	seenTParamNames map[*types.TypeName]bool // for cycle breaking through type parameters
 	seenMethods     map[*types.Func]bool     // for cycle breaking through recursive interfaces
 }
 
+// root resets the cycle-breaking state of f before a search
+// from a new root, and returns f.
+func (f *finder) root() *finder {
+	clear(f.seenTParamNames)
+	clear(f.seenMethods)
+	return f
+}
+
+// found reports whether o, encountered at path, is the sought object.
+// In indexing mode, it records the path of o, if o has none yet, and
+// reports false so that the search continues.
+func (f *finder) found(o types.Object, path []byte) bool {
+	if f.index != nil {
+		if _, ok := f.index[o]; !ok {
+			f.index[o] = Path(path)
+		}
+		return false
+	}
+	return o == f.obj
+}
+
 func (f *finder) find(T types.Type, path []byte) []byte {
 	switch T := T.(type) {
 	case *types.Alias:
@@ -501,7 +714,7 @@ func (f *finder) find(T types.Type, path []byte) []byte {
 		for i := 0; i < T.NumFields(); i++ {
 			fld := T.Field(i)
 			path2 := appendOpArg(path, opField, i)
-			if fld == f.obj {
+			if f.found(fld, path2) {
 				return path2 // found field var
 			}
 			if r := f.find(fld.Type(), append(path2, opType)); r != nil {
@@ -513,7 +726,7 @@ func (f *finder) find(T types.Type, path []byte) []byte {
 		for i := 0; i < T.Len(); i++ {
 			v := T.At(i)
 			path2 := appendOpArg(path, opAt, i)
-			if v == f.obj {
+			if f.found(v, path2) {
 				return path2 // found param/result var
 			}
 			if r := f.find(v.Type(), append(path2, opType)); r != nil {
@@ -528,7 +741,7 @@ func (f *finder) find(T types.Type, path []byte) []byte {
 				return nil
 			}
 			path2 := appendOpArg(path, opMethod, i)
-			if m == f.obj {
+			if f.found(m, path2) {
 				return path2 // found interface method
 			}
 			if f.seenMethods == nil {
@@ -545,8 +758,8 @@ func (f *finder) find(T types.Type, path []byte) []byte {
 		if f.seenTParamNames[name] {
 			return nil
 		}
-		if name == f.obj {
-			return append(path, opObj)
+		if path2 := append(path, opObj); f.found(name, path2) {
+			return path2
 		}
 		if f.seenTParamNames == nil {
 			f.seenTParamNames = make(map[*types.TypeName]bool)
@@ -560,10 +773,6 @@ func (f *finder) find(T types.Type, path []byte) []byte {
 	panic(T)
 }
 
-func findTypeParam(obj types.Object, list *types.TypeParamList, path []byte, op byte) []byte {
-	return (&finder{obj: obj}).findTypeParam(list, path, op)
-}
-
 func (f *finder) findTypeParam(list *types.TypeParamList, path []byte, op byte) []byte {
 	for i := 0; i < list.Len(); i++ {
 		tparam := list.At(i)
@@ -582,17 +791,28 @@ func Object(pkg *types.Package, p Path) (types.Object, error) {
 		return nil, fmt.Errorf("empty path")
 	}
 
-	var pkgobj, suffix string
-	if dot := strings.IndexByte(pathstr, opType); dot < 0 {
-		pkgobj = pathstr
+	var obj types.Object
+	var suffix string
+	if colon := strings.IndexByte(pathstr, ':'); colon >= 0 {
+		// local object
+		var err error
+		obj, suffix, err = localObject(pkg, pathstr, colon)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		pkgobj = pathstr[:dot]
-		suffix = pathstr[dot:] // suffix starts with "."
-	}
+		var pkgobj string
+		if dot := strings.IndexByte(pathstr, opType); dot < 0 {
+			pkgobj = pathstr
+		} else {
+			pkgobj = pathstr[:dot]
+			suffix = pathstr[dot:] // suffix starts with "."
+		}
 
-	obj := pkg.Scope().Lookup(pkgobj)
-	if obj == nil {
-		return nil, fmt.Errorf("package %s does not contain %q", pkg.Path(), pkgobj)
+		obj = pkg.Scope().Lookup(pkgobj)
+		if obj == nil {
+			return nil, fmt.Errorf("package %s does not contain %q", pkg.Path(), pkgobj)
+		}
 	}
 
 	// abstraction of *types.{Pointer,Slice,Array,Chan,Map}
@@ -796,6 +1016,50 @@ func Object(pkg *types.Package, p Path) (types.Object, error) {
 	return obj, nil // success
 }
 
+// localObject returns the local object denoted by the local path
+// pathstr, whose (first) colon is at the specified index, and the
+// remainder of the path.
+func localObject(pkg *types.Package, pathstr string, colon int) (types.Object, string, error) {
+	prefix, rest := pathstr[:colon], pathstr[colon+1:]
+	l := strings.LastIndexByte(prefix, opLocal)
+	if l < 0 {
+		return nil, "", fmt.Errorf("invalid path: no %q before ':'", opLocal)
+	}
+	fnobj, err := Object(pkg, Path(prefix[:l]))
+	if err != nil {
+		return nil, "", err
+	}
+	fn, ok := fnobj.(*types.Func)
+	if !ok {
+		return nil, "", fmt.Errorf("cannot apply %q to %s (got %T, want func)", opLocal, fnobj, fnobj)
+	}
+	scope := fn.Scope()
+	if scope == nil {
+		return nil, "", fmt.Errorf("%s has no local scope (package not type-checked from syntax?)", fn)
+	}
+	if indices := prefix[l+1:]; indices != "" {
+		for _, numerals := range strings.Split(indices, ",") {
+			i, err := strconv.Atoi(numerals)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid path: bad block index %q", numerals)
+			}
+			if n := scope.NumChildren(); i < 0 || i >= n {
+				return nil, "", fmt.Errorf("block index %d out of range [0-%d)", i, n)
+			}
+			scope = scope.Child(i)
+		}
+	}
+	name, suffix := rest, ""
+	if dot := strings.IndexByte(rest, opType); dot >= 0 {
+		name, suffix = rest[:dot], rest[dot:]
+	}
+	obj := scope.Lookup(name)
+	if obj == nil {
+		return nil, "", fmt.Errorf("block of %s does not contain %q", fn, name)
+	}
+	return obj, suffix, nil
+}
+
 // scopeObjects is a memoization of scope objects.
 // Callers must not modify the result.
 func (enc *Encoder) scopeObjects(scope *types.Scope) []types.Object {
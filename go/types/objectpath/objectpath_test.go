@@ -21,6 +21,7 @@ import (
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/types/objectpath"
 	"golang.org/x/tools/internal/aliases"
+	"golang.org/x/tools/internal/testenv"
 	"golang.org/x/tools/internal/testfiles"
 	"golang.org/x/tools/txtar"
 )
@@ -440,3 +441,207 @@ func (T) X() { }
 		}
 	}
 }
+
+func TestLocalPaths(t *testing.T) {
+	const src = `package p
+
+func F(x int) {
+	const c = 1
+	if x > 0 {
+		type T struct{ X int }
+		var v struct{ Y int }
+		_ = v
+	}
+	for i := 0; i < x; i++ {
+		type I interface{ M() }
+	}
+}
+
+type U struct{}
+
+func (U) m() {
+	type A = struct{ Z int }
+	var y int
+	_ = y
+}
+
+func f() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]objectpath.Path{
+		"c": "FL:c",
+		"T": "FL0,0:T",
+		"X": "FL0,0:T.UF0",
+		"Y": "FL0,0:v.F0",
+		"i": "FL1:i",
+		"I": "FL1,0:I",
+		"M": "FL1,0:I.UM0",
+		"y": "U.M0L:y",
+	}
+	var enc objectpath.Encoder
+	for id, obj := range info.Defs {
+		if obj == nil || id.Name == "_" || obj.Name() == "f" {
+			continue // f is unexported, so has no path
+		}
+		path, err := enc.ForLocal(obj)
+		if err != nil {
+			t.Errorf("ForLocal(%v) failed: %v", obj, err)
+			continue
+		}
+		if p, ok := want[obj.Name()]; ok && path != p {
+			t.Errorf("ForLocal(%v) = %q, want %q", obj, path, p)
+		}
+		obj2, err := objectpath.Object(pkg, path)
+		if err != nil {
+			t.Errorf("Object(%q) failed: %v", path, err)
+		} else if obj2 != obj {
+			t.Errorf("Object(%q) = %v, want %v", path, obj2, obj)
+		}
+	}
+
+	// For does not return paths for local objects.
+	T := pkg.Scope().Lookup("F").(*types.Func).Scope().Child(0).Child(0).Lookup("T")
+	if _, err := objectpath.For(T); err == nil {
+		t.Errorf("For(%v) succeeded unexpectedly", T)
+	}
+
+	// Bad local paths.
+	for path, wantErr := range map[objectpath.Path]string{
+		"FL5:T":    "block index 5 out of range [0-2)",
+		"FL0:T":    `block of func p.F(x int) does not contain "T"`,
+		"UL:T":     "cannot apply 'L' to type p.U struct{} (got *types.TypeName, want func)",
+		"FLx:T":    `invalid path: bad block index "x"`,
+		"Nope.L:T": `package p does not contain "Nope"`,
+	} {
+		if _, err := objectpath.Object(pkg, path); err == nil || err.Error() != wantErr {
+			t.Errorf("Object(%q) returned error %v, want %q", path, err, wantErr)
+		}
+	}
+}
+
+// TestEncoderIndex checks that an Encoder used for many objects of a
+// package, which consults an index of the package's API, returns the
+// same paths as the For function, which searches the API afresh.
+func TestEncoderIndex(t *testing.T) {
+	const src = `package p
+
+type A = struct{ X, Y int }
+
+type T struct {
+	A
+	F func(x int) (y string)
+	M map[string]struct{ K int }
+}
+
+func (T) Method(a, b int) (c A) { return }
+
+type I interface {
+	M(i int) interface{ I }
+	N() I
+}
+
+type G[P any, Q interface{ ~[]P }] struct{ Elem P }
+
+func (g *G[P, Q]) Get(q Q) P { return g.Elem }
+
+func Fn[P interface{ Method(P) }](p P) (r struct{ V P }) { return }
+
+var V struct {
+	Nested struct{ Z *T }
+}
+
+type unexported struct{ Field int }
+
+func Exported() *unexported { return nil }
+`
+	testEncoderIndex(t, src)
+
+	t.Run("generic alias", func(t *testing.T) {
+		// Type parameters of aliases require go1.24,
+		// and materialized aliases.
+		testenv.NeedsGo1Point(t, 24)
+		t.Setenv("GODEBUG", "gotypesalias=1")
+		testEncoderIndex(t, src+"\ntype GA[P any] = G[P, []P]\n")
+	})
+}
+
+func testEncoderIndex(t *testing.T, src string) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	if _, err := new(types.Config).Check("p", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	var enc objectpath.Encoder
+	for i := 0; i < 2; i++ { // the second round consults the index
+		for id, obj := range info.Defs {
+			if obj == nil {
+				continue
+			}
+			want, wantErr := objectpath.For(obj)
+			got, err := enc.For(obj)
+			if got != want || (err == nil) != (wantErr == nil) {
+				t.Errorf("Encoder.For(%v) at %s = %q, %v; For returned %q, %v",
+					obj, fset.Position(id.Pos()), got, err, want, wantErr)
+			}
+		}
+	}
+}
+
+// BenchmarkEncoder measures the cost of encoding the paths of all
+// the objects declared in a large package, either by calling For for
+// each, or by reusing an Encoder.
+func BenchmarkEncoder(b *testing.B) {
+	var src strings.Builder
+	src.WriteString("package p\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&src, "type T%d struct{ A, B int; C func(x, y int) (z string) }\n", i)
+		fmt.Fprintf(&src, "func (T%d) M(p, q int) (r struct{ S int }) { return }\n", i)
+		fmt.Fprintf(&src, "type I%d interface{ N(n int) T%d }\n", i, i)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src.String(), 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	if _, err := new(types.Config).Check("p", fset, []*ast.File{f}, info); err != nil {
+		b.Fatal(err)
+	}
+	var objs []types.Object
+	for _, obj := range info.Defs {
+		if obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+
+	b.Run("For", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, obj := range objs {
+				objectpath.For(obj)
+			}
+		}
+	})
+	b.Run("Encoder", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var enc objectpath.Encoder
+			for _, obj := range objs {
+				enc.For(obj)
+			}
+		}
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"go/types"
 	"reflect"
+	"sort"
 
 	"golang.org/x/tools/internal/typeparams"
 )
@@ -28,12 +29,15 @@ type Map struct {
 	hasher Hasher             // shared by many Maps
 	table  map[uint32][]entry // maps hash to bucket; entry.key==nil means unused
 	length int                // number of map entries
+	seq    uint64             // number of insertions, for IterateOrdered
+	shared bool               // table is shared with a Snapshot; copy before mutation
 }
 
 // entry is an entry (key/value association) in a hash bucket.
 type entry struct {
 	key   types.Type
 	value any
+	seq   uint64 // insertion sequence number
 }
 
 // SetHasher sets the hasher used by Map.
@@ -64,6 +68,7 @@ func (m *Map) SetHasher(hasher Hasher) {
 // It returns true if the entry was found.
 func (m *Map) Delete(key types.Type) bool {
 	if m != nil && m.table != nil {
+		m.unshare()
 		hash := m.hasher.Hash(key)
 		bucket := m.table[hash]
 		for i, e := range bucket {
@@ -95,7 +100,9 @@ func (m *Map) At(key types.Type) any {
 // Set sets the map entry for key to val,
 // and returns the previous entry, if any.
 func (m *Map) Set(key types.Type, value any) (prev any) {
+	m.seq++
 	if m.table != nil {
+		m.unshare()
 		hash := m.hasher.Hash(key)
 		bucket := m.table[hash]
 		var hole *entry
@@ -110,16 +117,16 @@ func (m *Map) Set(key types.Type, value any) (prev any) {
 		}
 
 		if hole != nil {
-			*hole = entry{key, value} // overwrite deleted entry
+			*hole = entry{key, value, m.seq} // overwrite deleted entry
 		} else {
-			m.table[hash] = append(bucket, entry{key, value})
+			m.table[hash] = append(bucket, entry{key, value, m.seq})
 		}
 	} else {
 		if m.hasher.memo == nil {
 			m.hasher = MakeHasher()
 		}
 		hash := m.hasher.Hash(key)
-		m.table = map[uint32][]entry{hash: {entry{key, value}}}
+		m.table = map[uint32][]entry{hash: {entry{key, value, m.seq}}}
 	}
 
 	m.length++
@@ -153,6 +160,58 @@ func (m *Map) Iterate(f func(key types.Type, value any)) {
 	}
 }
 
+// IterateOrdered is like Iterate, but calls f on the entries in the
+// order in which their keys were inserted, so that the sequence of
+// calls is the same for any two maps populated by the same sequence
+// of operations. A key that is deleted and then set again is
+// considered newly inserted. Unlike Iterate, IterateOrdered sorts the
+// entries before the first call to f, so it is slower and is not
+// affected by changes to the map made by f.
+func (m *Map) IterateOrdered(f func(key types.Type, value any)) {
+	if m == nil {
+		return
+	}
+	entries := make([]entry, 0, m.length)
+	for _, bucket := range m.table {
+		for _, e := range bucket {
+			if e.key != nil {
+				entries = append(entries, e)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	for _, e := range entries {
+		f(e.key, e.value)
+	}
+}
+
+// Snapshot returns an immutable copy of the current entries of the
+// map. It takes constant time: the map and the snapshot share their
+// entries until the next change to the map, which then copies them.
+//
+// The snapshot shares the map's Hasher, so the two must not be
+// accessed concurrently.
+func (m *Map) Snapshot() *Snapshot {
+	if m == nil {
+		return &Snapshot{}
+	}
+	m.shared = m.table != nil
+	return &Snapshot{m: Map{hasher: m.hasher, table: m.table, length: m.length, seq: m.seq}}
+}
+
+// unshare gives m its own copy of a table shared with a Snapshot.
+func (m *Map) unshare() {
+	if !m.shared {
+		return
+	}
+	table := make(map[uint32][]entry, len(m.table))
+	for hash, bucket := range m.table {
+		table[hash] = append([]entry(nil), bucket...)
+	}
+	m.table = table
+	m.shared = false
+}
+
 // Keys returns a new slice containing the set of map keys.
 // The order is unspecified.
 func (m *Map) Keys() []types.Type {
@@ -170,7 +229,7 @@ func (m *Map) toString(values bool) string {
 	var buf bytes.Buffer
 	fmt.Fprint(&buf, "{")
 	sep := ""
-	m.Iterate(func(key types.Type, value any) {
+	m.IterateOrdered(func(key types.Type, value any) {
 		fmt.Fprint(&buf, sep)
 		sep = ", "
 		fmt.Fprint(&buf, key)
@@ -184,17 +243,67 @@ func (m *Map) toString(values bool) string {
 
 // String returns a string representation of the map's entries.
 // Values are printed using fmt.Sprintf("%v", v).
-// Order is unspecified.
+// Entries are printed in insertion order (see IterateOrdered).
 func (m *Map) String() string {
 	return m.toString(true)
 }
 
 // KeysString returns a string representation of the map's key set.
-// Order is unspecified.
+// Keys are printed in insertion order (see IterateOrdered).
 func (m *Map) KeysString() string {
 	return m.toString(false)
 }
 
+// A Snapshot is an immutable copy of the entries of a Map,
+// made by [Map.Snapshot].
+//
+// Just as with *Map, a nil *Snapshot is a valid empty snapshot.
+type Snapshot struct {
+	m Map
+}
+
+// At returns the snapshot entry for the given key.
+// The result is nil if the entry is not present.
+func (s *Snapshot) At(key types.Type) any {
+	if s == nil {
+		return nil
+	}
+	return s.m.At(key)
+}
+
+// Len returns the number of snapshot entries.
+func (s *Snapshot) Len() int {
+	if s == nil {
+		return 0
+	}
+	return s.m.Len()
+}
+
+// Iterate calls function f on each entry in the snapshot in
+// unspecified order.
+func (s *Snapshot) Iterate(f func(key types.Type, value any)) {
+	if s != nil {
+		s.m.Iterate(f)
+	}
+}
+
+// IterateOrdered calls function f on each entry in the snapshot
+// in insertion order, as described at [Map.IterateOrdered].
+func (s *Snapshot) IterateOrdered(f func(key types.Type, value any)) {
+	if s != nil {
+		s.m.IterateOrdered(f)
+	}
+}
+
+// Keys returns a new slice containing the set of snapshot keys.
+// The order is unspecified.
+func (s *Snapshot) Keys() []types.Type {
+	if s == nil {
+		return nil
+	}
+	return s.m.Keys()
+}
+
 ////////////////////////////////////////////////////////////////////////
 // Hasher
 
@@ -14,6 +14,7 @@ import (
 	"go/parser"
 	"go/token"
 	"go/types"
+	"reflect"
 	"testing"
 
 	"golang.org/x/tools/go/types/typeutil"
@@ -176,6 +177,64 @@ func TestMap(t *testing.T) {
 	}
 }
 
+func TestMapOrderAndSnapshot(t *testing.T) {
+	var keys []types.Type
+	for i := 0; i < 100; i++ {
+		keys = append(keys, types.NewArray(tInt, int64(i)))
+	}
+	order := func(iterate func(func(types.Type, any))) []int {
+		var got []int
+		iterate(func(key types.Type, value any) {
+			got = append(got, value.(int))
+		})
+		return got
+	}
+
+	var m typeutil.Map
+	for i, t := range keys {
+		m.Set(t, i)
+	}
+	m.Set(keys[0], 0) // update: keeps position
+	m.Delete(keys[1]) // delete and reinsert: moves to end
+	m.Set(keys[1], 1)
+	want := []int{0}
+	for i := 2; i < len(keys); i++ {
+		want = append(want, i)
+	}
+	want = append(want, 1)
+	if got := order(m.IterateOrdered); !reflect.DeepEqual(got, want) {
+		t.Errorf("IterateOrdered: got %v, want %v", got, want)
+	}
+
+	snap := m.Snapshot()
+	m.Set(tStr, -1)
+	m.Delete(keys[2])
+	m.Set(keys[3], 333)
+	if snap.Len() != len(keys) {
+		t.Errorf("snapshot Len() = %d, want %d", snap.Len(), len(keys))
+	}
+	if got := snap.At(keys[2]); got != 2 {
+		t.Errorf("snapshot At(keys[2]) = %v, want 2", got)
+	}
+	if got := snap.At(keys[3]); got != 3 {
+		t.Errorf("snapshot At(keys[3]) = %v, want 3", got)
+	}
+	if got := snap.At(tStr); got != nil {
+		t.Errorf("snapshot At(string) = %v, want nil", got)
+	}
+	if got := order(snap.IterateOrdered); !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot IterateOrdered: got %v, want %v", got, want)
+	}
+	if got := m.At(keys[3]); got != 333 {
+		t.Errorf("map At(keys[3]) after snapshot = %v, want 333", got)
+	}
+
+	var nilSnap *typeutil.Snapshot
+	if nilSnap.Len() != 0 || nilSnap.At(tInt) != nil {
+		t.Errorf("nil snapshot is not empty")
+	}
+}
+
 func TestMapGenerics(t *testing.T) {
 	const src = `
 package p
@@ -7,6 +7,7 @@
 package typeutil
 
 import (
+	"container/list"
 	"go/types"
 	"sync"
 )
@@ -14,10 +15,55 @@ import (
 // A MethodSetCache records the method set of each type T for which
 // MethodSet(T) is called so that repeat queries are fast.
 // The zero value is a ready-to-use cache instance.
+//
+// By default the cache grows without bound. Long-running programs
+// that query many types may bound it by setting Limit.
 type MethodSetCache struct {
+	// Limit, if positive, is the maximum number of entries retained
+	// by the cache: once it is exceeded, the least recently used entry
+	// is evicted. The entry for a named type N holds the method sets
+	// of both N and *N. Limit must not be changed once the cache is
+	// in use.
+	Limit int
+
 	mu     sync.Mutex
-	named  map[*types.Named]struct{ value, pointer *types.MethodSet } // method sets for named N and *N
-	others map[types.Type]*types.MethodSet                            // all other types
+	named  map[*types.Named]*list.Element // entries for named N and *N
+	others map[types.Type]*list.Element   // entries for all other types
+	lru    list.List                      // of *msetEntry, most recently used first
+	stats  MethodSetCacheStats
+}
+
+// An msetEntry is an entry in a MethodSetCache.
+type msetEntry struct {
+	key            types.Type // *types.Named for the entries of named
+	value, pointer *types.MethodSet
+}
+
+// MethodSetCacheStats holds statistics about a [MethodSetCache].
+type MethodSetCacheStats struct {
+	Entries   int // number of entries in the cache
+	Hits      int // number of calls to MethodSet that found an entry
+	Misses    int // number of calls to MethodSet that created an entry
+	Evictions int // number of entries evicted due to the Limit
+}
+
+// HitRate returns the fraction of calls to MethodSet
+// that found an entry, or zero if there were none.
+func (s MethodSetCacheStats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// Stats returns statistics about the use of the cache.
+// It is thread-safe.
+func (cache *MethodSetCache) Stats() MethodSetCacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	stats := cache.stats
+	stats.Entries = cache.lru.Len()
+	return stats
 }
 
 // MethodSet returns the method set of type T.  It is thread-safe.
@@ -44,28 +90,55 @@ func (cache *MethodSetCache) MethodSet(T types.Type) *types.MethodSet {
 
 	// all other types
 	// (The map uses pointer equivalence, not type identity.)
-	mset := cache.others[T]
-	if mset == nil {
-		mset = types.NewMethodSet(T)
-		if cache.others == nil {
-			cache.others = make(map[types.Type]*types.MethodSet)
-		}
-		cache.others[T] = mset
+	if elem := cache.others[T]; elem != nil {
+		return cache.hit(elem).value
 	}
-	return mset
+	if cache.others == nil {
+		cache.others = make(map[types.Type]*list.Element)
+	}
+	e := &msetEntry{key: T, value: types.NewMethodSet(T)}
+	cache.others[T] = cache.add(e)
+	return e.value
 }
 
-func (cache *MethodSetCache) lookupNamed(named *types.Named) struct{ value, pointer *types.MethodSet } {
+func (cache *MethodSetCache) lookupNamed(named *types.Named) *msetEntry {
+	if elem := cache.named[named]; elem != nil {
+		return cache.hit(elem)
+	}
 	if cache.named == nil {
-		cache.named = make(map[*types.Named]struct{ value, pointer *types.MethodSet })
+		cache.named = make(map[*types.Named]*list.Element)
 	}
 	// Avoid recomputing mset(*T) for each distinct Pointer
 	// instance whose underlying type is a named type.
-	msets, ok := cache.named[named]
-	if !ok {
-		msets.value = types.NewMethodSet(named)
-		msets.pointer = types.NewMethodSet(types.NewPointer(named))
-		cache.named[named] = msets
+	e := &msetEntry{
+		key:     named,
+		value:   types.NewMethodSet(named),
+		pointer: types.NewMethodSet(types.NewPointer(named)),
+	}
+	cache.named[named] = cache.add(e)
+	return e
+}
+
+// hit records a use of an existing entry.
+func (cache *MethodSetCache) hit(elem *list.Element) *msetEntry {
+	cache.stats.Hits++
+	cache.lru.MoveToFront(elem)
+	return elem.Value.(*msetEntry)
+}
+
+// add adds a new entry, evicting the least recently
+// used entries if the cache exceeds its limit.
+func (cache *MethodSetCache) add(e *msetEntry) *list.Element {
+	cache.stats.Misses++
+	elem := cache.lru.PushFront(e)
+	for cache.Limit > 0 && cache.lru.Len() > cache.Limit {
+		old := cache.lru.Remove(cache.lru.Back()).(*msetEntry)
+		if N, ok := old.key.(*types.Named); ok {
+			delete(cache.named, N)
+		} else {
+			delete(cache.others, old.key)
+		}
+		cache.stats.Evictions++
 	}
-	return msets
+	return elem
 }
@@ -0,0 +1,52 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typeutil_test
+
+import (
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+func TestMethodSetCacheLimit(t *testing.T) {
+	pkg := types.NewPackage("p", "p")
+	newNamed := func(name string) *types.Named {
+		return types.NewNamed(types.NewTypeName(0, pkg, name, nil), types.Typ[types.Int], nil)
+	}
+	A, B, C := newNamed("A"), newNamed("B"), newNamed("C")
+	S := types.NewSlice(A)
+
+	var cache typeutil.MethodSetCache
+	cache.Limit = 2
+
+	msetA := cache.MethodSet(A)
+	cache.MethodSet(types.NewPointer(A)) // hit: same entry as A
+	cache.MethodSet(S)
+	cache.MethodSet(A) // hit; S is now least recently used
+	cache.MethodSet(B) // evicts S
+	if got := cache.MethodSet(A); got != msetA {
+		t.Errorf("method set of A was not retained")
+	}
+	cache.MethodSet(C) // evicts B
+
+	want := typeutil.MethodSetCacheStats{Entries: 2, Hits: 3, Misses: 4, Evictions: 2}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if got, want := want.HitRate(), 3.0/7.0; got != want {
+		t.Errorf("HitRate() = %v, want %v", got, want)
+	}
+
+	// Without a limit, nothing is evicted.
+	var unbounded typeutil.MethodSetCache
+	for _, T := range []types.Type{A, B, C, S, A} {
+		unbounded.MethodSet(T)
+	}
+	want = typeutil.MethodSetCacheStats{Entries: 4, Hits: 1, Misses: 4}
+	if got := unbounded.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typeutil
+
+import (
+	"encoding/binary"
+	"go/types"
+	"hash"
+	"hash/fnv"
+)
+
+// StableHash computes a hash value for the given type t such that
+// Identical(t, t') => StableHash(t) == StableHash(t').
+//
+// Unlike the hash of a [Hasher], which depends on the identity of
+// the objects that declare named types and is thus meaningful only
+// within a single process, StableHash depends only on the structure
+// of t and on the names and package paths of the named types and
+// type parameters it refers to. So, for the same inputs, it is the
+// same in every process and with every toolchain, and it may be
+// used as a key in a persistent (on-disk) cache of facts about types.
+//
+// The price of stability is more collisions: distinct types hash
+// alike if they differ only in the identity of their named types,
+// such as two types declared with the same name in different
+// function bodies of a package, or in the details of the types of
+// interface methods and type parameters.
+func StableHash(t types.Type) uint64 {
+	h := stableHasher{h: fnv.New64a()}
+	h.hash(t)
+	return h.h.Sum64()
+}
+
+// A stableHasher writes a canonical encoding
+// of a type to a hash function.
+type stableHasher struct {
+	h   hash.Hash64
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (h *stableHasher) int(x int64) {
+	n := binary.PutVarint(h.buf[:], x)
+	h.h.Write(h.buf[:n])
+}
+
+func (h *stableHasher) string(s string) {
+	h.int(int64(len(s)))
+	h.h.Write([]byte(s))
+}
+
+// name encodes the name of a field or method, which for
+// unexported names is qualified by the package path.
+func (h *stableHasher) name(obj types.Object) {
+	if !obj.Exported() && obj.Pkg() != nil {
+		h.string(obj.Pkg().Path())
+	}
+	h.string(obj.Name())
+}
+
+// Type tags, distinguishing the kinds of types.
+const (
+	stableBasic = iota
+	stableArray
+	stableSlice
+	stableStruct
+	stablePointer
+	stableSignature
+	stableUnion
+	stableInterface
+	stableMap
+	stableChan
+	stableNamed
+	stableTypeParam
+	stableTuple
+)
+
+func (h *stableHasher) hash(t types.Type) {
+	// See Identical for rationale.
+	switch t := t.(type) {
+	case *types.Basic:
+		h.int(stableBasic)
+		h.int(int64(t.Kind()))
+
+	case *types.Alias:
+		h.hash(types.Unalias(t))
+
+	case *types.Array:
+		h.int(stableArray)
+		h.int(t.Len())
+		h.hash(t.Elem())
+
+	case *types.Slice:
+		h.int(stableSlice)
+		h.hash(t.Elem())
+
+	case *types.Struct:
+		h.int(stableStruct)
+		h.int(int64(t.NumFields()))
+		for i := 0; i < t.NumFields(); i++ {
+			f := t.Field(i)
+			if f.Anonymous() {
+				h.int(1)
+			} else {
+				h.int(0)
+			}
+			h.name(f)
+			h.string(t.Tag(i))
+			h.hash(f.Type())
+		}
+
+	case *types.Pointer:
+		h.int(stablePointer)
+		h.hash(t.Elem())
+
+	case *types.Signature:
+		// Signatures are identical modulo renaming of type
+		// parameters, which are encoded by their index.
+		h.int(stableSignature)
+		if t.Variadic() {
+			h.int(1)
+		} else {
+			h.int(0)
+		}
+		h.int(int64(t.TypeParams().Len()))
+		h.hash(t.Params())
+		h.hash(t.Results())
+
+	case *types.Tuple:
+		h.int(stableTuple)
+		h.int(int64(t.Len()))
+		for i := 0; i < t.Len(); i++ {
+			h.hash(t.At(i).Type())
+		}
+
+	case *types.Union:
+		// The order of terms is not significant,
+		// so encode only their number.
+		h.int(stableUnion)
+		h.int(int64(t.Len()))
+
+	case *types.Interface:
+		// Methods are ordered by name. To avoid cycles through
+		// anonymous interfaces, encode only the shape of each
+		// method signature. The type restrictions are ignored.
+		h.int(stableInterface)
+		h.int(int64(t.NumMethods()))
+		for i := 0; i < t.NumMethods(); i++ {
+			m := t.Method(i)
+			sig := m.Type().(*types.Signature)
+			h.name(m)
+			h.int(int64(sig.Params().Len()))
+			h.int(int64(sig.Results().Len()))
+		}
+
+	case *types.Map:
+		h.int(stableMap)
+		h.hash(t.Key())
+		h.hash(t.Elem())
+
+	case *types.Chan:
+		h.int(stableChan)
+		h.int(int64(t.Dir()))
+		h.hash(t.Elem())
+
+	case *types.Named:
+		h.int(stableNamed)
+		obj := t.Obj()
+		if obj.Pkg() != nil {
+			h.string(obj.Pkg().Path())
+		}
+		h.string(obj.Name())
+		targs := t.TypeArgs()
+		h.int(int64(targs.Len()))
+		for i := 0; i < targs.Len(); i++ {
+			h.hash(targs.At(i))
+		}
+
+	case *types.TypeParam:
+		// Type parameters of a signature are identical to those
+		// of another at the same index, so encode only the index.
+		h.int(stableTypeParam)
+		h.int(int64(t.Index()))
+
+	default:
+		panic(t)
+	}
+}
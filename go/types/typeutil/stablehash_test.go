@@ -0,0 +1,87 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typeutil_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+func TestStableHash(t *testing.T) {
+	const src = `package p
+
+type T[P any] struct {
+	x P ` + "`json:\"x\"`" + `
+	y []*T[P]
+}
+
+type I interface{ M(int) I }
+
+func F[A, B any](A, ...B) map[string]T[A] { return nil }
+
+var (
+	V1 T[int]
+	V2 T[string]
+	V3 chan<- I
+	V4 <-chan I
+	V5 = F[int, bool]
+	V6 struct{ x, y int }
+	V7 struct{ y, x int }
+)
+`
+	// Type-check the same package twice, as would two processes.
+	check := func() *types.Package {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "p.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg, err := new(types.Config).Check("example.com/p", fset, []*ast.File{f}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkg
+	}
+	pkg1, pkg2 := check(), check()
+
+	names := pkg1.Scope().Names()
+	hashes := make(map[uint64]string)
+	for _, name := range names {
+		t1 := pkg1.Scope().Lookup(name).Type()
+		t2 := pkg2.Scope().Lookup(name).Type()
+		h1, h2 := typeutil.StableHash(t1), typeutil.StableHash(t2)
+		if h1 != h2 {
+			t.Errorf("StableHash(%s) differs between type-checker runs: %x vs %x", name, h1, h2)
+		}
+		if prev, ok := hashes[h1]; ok {
+			t.Errorf("StableHash(%s) collides with StableHash(%s)", name, prev)
+		}
+		hashes[h1] = name
+	}
+
+	// Identical types have the same hash.
+	f := pkg1.Scope().Lookup("F").Type().(*types.Signature)
+	v5 := pkg1.Scope().Lookup("V5").Type()
+	inst, err := types.Instantiate(nil, f, []types.Type{types.Typ[types.Int], types.Typ[types.Bool]}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !types.Identical(inst, v5) {
+		t.Fatalf("F[int, bool] is not identical to type of V5")
+	}
+	if typeutil.StableHash(inst) != typeutil.StableHash(v5) {
+		t.Errorf("StableHash differs for identical types %s and %s", inst, v5)
+	}
+
+	// The hash of a type is fixed.
+	if got, want := typeutil.StableHash(types.NewSlice(types.Typ[types.Int])), uint64(0xb6a72a18586f3bd7); got != want {
+		t.Errorf("StableHash([]int) = %#x, want %#x", got, want)
+	}
+}
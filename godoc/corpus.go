@@ -67,6 +67,12 @@ type Corpus struct {
 	// MaxResults optionally specifies the maximum results for indexing.
 	MaxResults int
 
+	// IndexCacheFile optionally specifies a file in which the search
+	// index is saved each time it is updated, in per-directory parts.
+	// When the index is next built, including in a later process, only
+	// the directories whose files have changed since are reindexed.
+	IndexCacheFile string
+
 	// SummarizePackage optionally specifies a function to
 	// summarize a package. It exists as an optimization to
 	// avoid reading files to parse package comments.
@@ -99,6 +105,10 @@ type Corpus struct {
 	// SearchIndex is the search index in use.
 	searchIndex util.RWValue
 
+	dirIndexMu   sync.Mutex           // serializes index builds; guards the following
+	dirIndexes   map[string]*dirIndex // directory indexes of the last index built, by directory
+	dirIndexOpts indexOptions         // options with which dirIndexes were built
+
 	// Analysis is the result of type and pointer analysis.
 	Analysis analysis.Result
 
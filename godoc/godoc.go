@@ -80,6 +80,7 @@ func (p *Presentation) initFuncMap() {
 		"node_html":    p.node_htmlFunc,
 		"comment_html": comment_htmlFunc,
 		"sanitize":     sanitizeFunc,
+		"typeParams":   p.typeParamsFunc,
 
 		// support for URL attributes
 		"pkgLink":       pkgLinkFunc,
@@ -354,6 +355,35 @@ func comment_htmlFunc(info *PageInfo, comment string) string {
 // sanitizeFunc sanitizes the argument src by replacing newlines with
 // blanks, removing extra blanks, and by removing trailing whitespace
 // and commas before closing parentheses.
+// typeParamsFunc returns the type parameter list of the type declared
+// by decl, such as "[K comparable, V any]", or "" if it has none.
+func (p *Presentation) typeParamsFunc(info *PageInfo, decl *ast.GenDecl) string {
+	for _, spec := range decl.Specs {
+		spec, ok := spec.(*ast.TypeSpec)
+		if !ok || spec.TypeParams == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, field := range spec.TypeParams.List {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			for j, name := range field.Names {
+				if j > 0 {
+					buf.WriteString(", ")
+				}
+				buf.WriteString(name.Name)
+			}
+			buf.WriteByte(' ')
+			p.writeNode(&buf, info, info.FSet, field.Type)
+		}
+		buf.WriteByte(']')
+		return sanitizeFunc(buf.String())
+	}
+	return ""
+}
+
 func sanitizeFunc(src string) string {
 	buf := make([]byte, len(src))
 	j := 0      // buf index
@@ -47,6 +47,7 @@ import (
 	"go/doc"
 	"go/parser"
 	"go/token"
+	"hash/fnv"
 	"index/suffixarray"
 	"io"
 	"log"
@@ -441,7 +442,7 @@ type Indexer struct {
 	fset       *token.FileSet // file set for all indexed files
 	fsOpenGate chan bool      // send pre fs.Open; receive on close
 
-	mu            sync.Mutex              // guards all the following
+	mu            *sync.Mutex             // guards all the following; shared by the Indexers of an index build
 	sources       bytes.Buffer            // concatenated sources
 	strings       map[string]string       // interned string
 	packages      map[Pak]*Pak            // interned *Paks
@@ -552,6 +553,9 @@ func (x *Indexer) visitSpec(kind SpotKind, spec ast.Spec) {
 
 	case *ast.TypeSpec:
 		x.visitIdent(TypeDecl, n.Name)
+		if n.TypeParams != nil {
+			ast.Walk(x, n.TypeParams)
+		}
 		ast.Walk(x, n.Type)
 	}
 }
@@ -991,24 +995,41 @@ func (c *Corpus) throttle() float64 {
 }
 
 // NewIndex creates a new index for the .go files provided by the corpus.
+//
+// The index is assembled from separate indexes of each directory. The
+// index of a directory whose files (as determined by their names, sizes,
+// and modification times) are unchanged since the last call of NewIndex,
+// or since the index cache given by IndexCacheFile was written, is reused
+// rather than rebuilt.
 func (c *Corpus) NewIndex() *Index {
-	// initialize Indexer
-	// (use some reasonably sized maps to start)
-	x := &Indexer{
-		c:           c,
-		fset:        token.NewFileSet(),
-		fsOpenGate:  make(chan bool, maxOpenFiles),
-		strings:     make(map[string]string),
-		packages:    make(map[Pak]*Pak, 256),
-		words:       make(map[string]*IndexResult, 8192),
-		throttle:    util.NewThrottle(c.throttle(), 100*time.Millisecond), // run at least 0.1s at a time
-		importCount: make(map[string]int),
-		packagePath: make(map[string]map[string]bool),
-		exports:     make(map[string]map[string]SpotKind),
-		idents:      make(map[SpotKind]map[string][]Ident, 4),
+	c.dirIndexMu.Lock()
+	defer c.dirIndexMu.Unlock()
+
+	opts := c.indexOptions()
+	if c.dirIndexes == nil && c.IndexCacheFile != "" {
+		if err := c.readIndexCache(c.IndexCacheFile); err != nil && !os.IsNotExist(err) {
+			log.Printf("error reading index cache %s: %v", c.IndexCacheFile, err)
+		}
+	}
+	old := c.dirIndexes
+	if c.dirIndexOpts != opts {
+		old = nil // options changed; the directory indexes are stale
 	}
 
+	// The Indexers of all directories share a mutex, so that
+	// indexing proceeds one file at a time, as well as the
+	// throttle and the limit on open files.
+	var (
+		mu         sync.Mutex
+		throttle   = util.NewThrottle(c.throttle(), 100*time.Millisecond) // run at least 0.1s at a time
+		fsOpenGate = make(chan bool, maxOpenFiles)
+	)
+
 	// index all files in the directories given by dirnames
+	var (
+		dirsMu sync.Mutex
+		dirs   = make(map[string]*dirIndex)
+	)
 	var wg sync.WaitGroup // outstanding ReadDir + visitFile
 	dirGate := make(chan bool, maxOpenDirs)
 	for dirname := range c.fsDirnames() {
@@ -1026,24 +1047,241 @@ func (c *Corpus) NewIndex() *Index {
 				log.Printf("ReadDir(%q): %v; skipping directory", dirname, err)
 				return // ignore this directory
 			}
-			for _, fi := range list {
-				wg.Add(1)
-				go func(fi os.FileInfo) {
-					defer wg.Done()
-					x.visitFile(dirname, fi)
-				}(fi)
+			d := old[dirname]
+			if stamp := dirStamp(list); d == nil || d.Stamp != stamp {
+				x := c.newIndexer(&mu, throttle, fsOpenGate)
+				var fwg sync.WaitGroup
+				for _, fi := range list {
+					fwg.Add(1)
+					go func(fi os.FileInfo) {
+						defer fwg.Done()
+						x.visitFile(dirname, fi)
+					}(fi)
+				}
+				fwg.Wait()
+				d = x.dirIndex(dirname, stamp)
 			}
+			dirsMu.Lock()
+			dirs[dirname] = d
+			dirsMu.Unlock()
 		}(dirname)
 	}
 	wg.Wait()
 
-	if !c.IndexFullText {
-		// the file set, the current file, and the sources are
-		// not needed after indexing if no text index is built -
-		// help GC and clear them
-		x.fset = nil
-		x.sources.Reset()
-		x.current = nil // contains reference to fset!
+	index := c.mergeIndex(dirs, opts, throttle)
+
+	// Keep the directory indexes only if they may be reused.
+	if c.IndexCacheFile != "" || c.IndexInterval >= 0 {
+		c.dirIndexes = dirs
+		c.dirIndexOpts = opts
+	} else {
+		c.dirIndexes = nil
+	}
+	return index
+}
+
+func (c *Corpus) indexOptions() indexOptions {
+	return indexOptions{
+		Docs:       c.IndexDocs,
+		GoCode:     c.IndexGoCode,
+		FullText:   c.IndexFullText,
+		MaxResults: c.MaxResults,
+	}
+}
+
+// newIndexer returns an Indexer for the files of one directory.
+func (c *Corpus) newIndexer(mu *sync.Mutex, throttle *util.Throttle, fsOpenGate chan bool) *Indexer {
+	return &Indexer{
+		c:           c,
+		fset:        token.NewFileSet(),
+		fsOpenGate:  fsOpenGate,
+		mu:          mu,
+		strings:     make(map[string]string),
+		packages:    make(map[Pak]*Pak),
+		words:       make(map[string]*IndexResult),
+		throttle:    throttle,
+		importCount: make(map[string]int),
+		packagePath: make(map[string]map[string]bool),
+		exports:     make(map[string]map[string]SpotKind),
+		idents:      make(map[SpotKind]map[string][]Ident),
+	}
+}
+
+// A dirIndex is the index of the files of a single directory, from
+// which an Index is assembled. Its fields are exported for gob.
+type dirIndex struct {
+	Dir         string
+	Stamp       uint64 // hash of the names, sizes, and modification times of the files
+	Files       []*File
+	Words       map[string]*dirWords
+	Snippets    []*Snippet // indices are relative to this directory
+	Texts       []dirText  // file contents for the full text index
+	Stats       Statistics // Words is not set
+	ImportCount map[string]int
+	PackagePath map[string]map[string]bool
+	Exports     map[string]map[string]SpotKind
+	Idents      map[SpotKind]map[string][]Ident
+}
+
+// dirWords holds the occurrences of a word in a directory.
+type dirWords struct {
+	Decls  []dirSpot
+	Others []dirSpot
+}
+
+// A dirSpot is a Spot whose file is an index into dirIndex.Files.
+type dirSpot struct {
+	File int
+	Info SpotInfo
+}
+
+type dirText struct {
+	Name string // file path
+	Src  []byte
+}
+
+// dirStamp returns a hash of the files in list, which changes
+// when a file is added, removed, or modified.
+func dirStamp(list []os.FileInfo) uint64 {
+	h := fnv.New64a()
+	for _, fi := range list {
+		if !fi.IsDir() {
+			fmt.Fprintf(h, "%s %d %d\n", fi.Name(), fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return h.Sum64()
+}
+
+// dirIndex returns the index of the directory dirname built by x.
+func (x *Indexer) dirIndex(dirname string, stamp uint64) *dirIndex {
+	d := &dirIndex{
+		Dir:         dirname,
+		Stamp:       stamp,
+		Words:       make(map[string]*dirWords, len(x.words)),
+		Snippets:    x.snippets,
+		Stats:       x.stats,
+		ImportCount: x.importCount,
+		PackagePath: x.packagePath,
+		Exports:     x.exports,
+		Idents:      x.idents,
+	}
+	files := make(map[*File]int)
+	spots := func(list RunList) []dirSpot {
+		res := make([]dirSpot, len(list))
+		for i, s := range list {
+			s := s.(Spot)
+			f, ok := files[s.File]
+			if !ok {
+				f = len(d.Files)
+				files[s.File] = f
+				d.Files = append(d.Files, s.File)
+			}
+			res[i] = dirSpot{f, s.Info}
+		}
+		return res
+	}
+	for w, r := range x.words {
+		d.Words[w] = &dirWords{Decls: spots(r.Decls), Others: spots(r.Others)}
+	}
+	if x.c.IndexFullText {
+		src := x.sources.Bytes()
+		x.fset.Iterate(func(f *token.File) bool {
+			d.Texts = append(d.Texts, dirText{f.Name(), src[f.Base() : f.Base()+f.Size()]})
+			return true
+		})
+	}
+	return d
+}
+
+// mergeIndex assembles an Index from the directory indexes dirs.
+func (c *Corpus) mergeIndex(dirs map[string]*dirIndex, opts indexOptions, throttle *util.Throttle) *Index {
+	var (
+		fset        *token.FileSet
+		sources     bytes.Buffer
+		allWords    = make(map[string]*IndexResult, 8192)
+		snippets    []*Snippet
+		stats       Statistics
+		importCount = make(map[string]int)
+		packagePath = make(map[string]map[string]bool)
+		exports     = make(map[string]map[string]SpotKind)
+		idents      = make(map[SpotKind]map[string][]Ident, 4)
+	)
+	if opts.FullText {
+		fset = token.NewFileSet()
+	}
+	names := make([]string, 0, len(dirs))
+	size := 0
+	for dirname, d := range dirs {
+		names = append(names, dirname)
+		for _, t := range d.Texts {
+			size += 1 + len(t.Src)
+		}
+	}
+	sort.Strings(names)
+	sources.Grow(size) // so that the slices of sources below remain valid
+	for _, dirname := range names {
+		d := dirs[dirname]
+
+		// Snippet indices are stored in the SpotInfos of declarations;
+		// offset them by the snippets of the preceding directories.
+		offset := len(snippets)
+		snippets = append(snippets, d.Snippets...)
+		for w, dw := range d.Words {
+			r := allWords[w]
+			if r == nil {
+				r = new(IndexResult)
+				allWords[w] = r
+			}
+			for _, s := range dw.Decls {
+				info := makeSpotInfo(s.Info.Kind(), s.Info.Lori()+offset, true)
+				r.Decls = append(r.Decls, Spot{d.Files[s.File], info})
+			}
+			for _, s := range dw.Others {
+				r.Others = append(r.Others, Spot{d.Files[s.File], s.Info})
+			}
+		}
+
+		// Concatenate the sources in lock-step with the file set,
+		// as addFile does, and share them with the text index.
+		for i, t := range d.Texts {
+			sources.WriteByte(0)
+			base := fset.Base()
+			sources.Write(t.Src)
+			fset.AddFile(t.Name, base, len(t.Src)).SetLinesForContent(t.Src)
+			d.Texts[i].Src = sources.Bytes()[base : base+len(t.Src)]
+		}
+
+		stats.Bytes += d.Stats.Bytes
+		stats.Files += d.Stats.Files
+		stats.Lines += d.Stats.Lines
+		stats.Spots += d.Stats.Spots
+		for path, n := range d.ImportCount {
+			importCount[path] += n
+		}
+		for name, paths := range d.PackagePath {
+			if packagePath[name] == nil {
+				packagePath[name] = make(map[string]bool)
+			}
+			for path := range paths {
+				packagePath[name][path] = true
+			}
+		}
+		for path, syms := range d.Exports {
+			if exports[path] == nil {
+				exports[path] = make(map[string]SpotKind)
+			}
+			for sym, kind := range syms {
+				exports[path][sym] = kind
+			}
+		}
+		for kind, idMap := range d.Idents {
+			if idents[kind] == nil {
+				idents[kind] = make(map[string][]Ident)
+			}
+			for name, ids := range idMap {
+				idents[kind][name] = append(idents[kind][name], ids...)
+			}
+		}
 	}
 
 	// for each word, reduce the RunLists into a LookupResult;
@@ -1051,7 +1289,7 @@ func (c *Corpus) NewIndex() *Index {
 	// word list for later computation of alternative spellings
 	words := make(map[string]*LookupResult)
 	var wlist RunList
-	for w, h := range x.words {
+	for w, h := range allWords {
 		decls := reduce(h.Decls)
 		others := reduce(h.Others)
 		words[w] = &LookupResult{
@@ -1059,9 +1297,9 @@ func (c *Corpus) NewIndex() *Index {
 			Others: others,
 		}
 		wlist = append(wlist, &wordPair{canonical(w), w})
-		x.throttle.Throttle()
+		throttle.Throttle()
 	}
-	x.stats.Words = len(words)
+	stats.Words = len(words)
 
 	// reduce the word list {canonical(w), w} into
 	// a list of AltWords runs {canonical(w), {w}}
@@ -1076,34 +1314,29 @@ func (c *Corpus) NewIndex() *Index {
 
 	// create text index
 	var suffixes *suffixarray.Index
-	if c.IndexFullText {
-		suffixes = suffixarray.New(x.sources.Bytes())
+	if opts.FullText {
+		suffixes = suffixarray.New(sources.Bytes())
 	}
 
 	// sort idents by the number of imports of their respective packages
-	for _, idMap := range x.idents {
+	for _, idMap := range idents {
 		for _, ir := range idMap {
-			sort.Sort(byImportCount{ir, x.importCount})
+			sort.Sort(byImportCount{ir, importCount})
 		}
 	}
 
 	return &Index{
-		fset:        x.fset,
+		fset:        fset,
 		suffixes:    suffixes,
 		words:       words,
 		alts:        alts,
-		snippets:    x.snippets,
-		stats:       x.stats,
-		importCount: x.importCount,
-		packagePath: x.packagePath,
-		exports:     x.exports,
-		idents:      x.idents,
-		opts: indexOptions{
-			Docs:       x.c.IndexDocs,
-			GoCode:     x.c.IndexGoCode,
-			FullText:   x.c.IndexFullText,
-			MaxResults: x.c.MaxResults,
-		},
+		snippets:    snippets,
+		stats:       stats,
+		importCount: importCount,
+		packagePath: packagePath,
+		exports:     exports,
+		idents:      idents,
+		opts:        opts,
 	}
 }
 
@@ -1492,6 +1725,76 @@ func (c *Corpus) ReadIndexFrom(r io.Reader) error {
 	return nil
 }
 
+const indexCacheVersion = 1
+
+// indexCache is the gob-encoded content of an index cache file.
+type indexCache struct {
+	Version int
+	Opts    indexOptions
+	Dirs    []*dirIndex
+}
+
+// readIndexCache sets the directory indexes from the index cache
+// file filename, for reuse by NewIndex.
+func (c *Corpus) readIndexCache(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var cache indexCache
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&cache); err != nil {
+		return err
+	}
+	if cache.Version != indexCacheVersion {
+		return ErrFileIndexVersion
+	}
+	c.dirIndexes = make(map[string]*dirIndex, len(cache.Dirs))
+	for _, d := range cache.Dirs {
+		c.dirIndexes[d.Dir] = d
+	}
+	c.dirIndexOpts = cache.Opts
+	return nil
+}
+
+// writeIndexCache writes the directory indexes of the last index
+// built to the index cache file filename. The file is replaced
+// atomically, so that an interrupted write doesn't corrupt it.
+func (c *Corpus) writeIndexCache(filename string) error {
+	c.dirIndexMu.Lock()
+	defer c.dirIndexMu.Unlock()
+
+	cache := indexCache{
+		Version: indexCacheVersion,
+		Opts:    c.dirIndexOpts,
+		Dirs:    make([]*dirIndex, 0, len(c.dirIndexes)),
+	}
+	for _, d := range c.dirIndexes {
+		cache.Dirs = append(cache.Dirs, d)
+	}
+	sort.Slice(cache.Dirs, func(i, j int) bool { return cache.Dirs[i].Dir < cache.Dirs[j].Dir })
+
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = gob.NewEncoder(w).Encode(&cache)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
 func (c *Corpus) UpdateIndex() {
 	if c.Verbose {
 		log.Printf("updating index...")
@@ -1500,6 +1803,11 @@ func (c *Corpus) UpdateIndex() {
 	index := c.NewIndex()
 	stop := time.Now()
 	c.searchIndex.Set(index)
+	if c.IndexCacheFile != "" {
+		if err := c.writeIndexCache(c.IndexCacheFile); err != nil {
+			log.Printf("error writing index cache %s: %v", c.IndexCacheFile, err)
+		}
+	}
 	if c.Verbose {
 		secs := stop.Sub(start).Seconds()
 		stats := index.Stats()
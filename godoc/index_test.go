@@ -6,12 +6,14 @@ package godoc
 
 import (
 	"bytes"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
 
 	"golang.org/x/tools/godoc/vfs/mapfs"
+	"golang.org/x/tools/godoc/vfs/overlayfs"
 )
 
 func newCorpus(t *testing.T) *Corpus {
@@ -321,3 +323,138 @@ func TestIdentFilter(t *testing.T) {
 		}
 	}
 }
+
+// TestIndexTypeParams tests that the constraints of the type
+// parameters of a type are indexed as uses.
+func TestIndexTypeParams(t *testing.T) {
+	c := NewCorpus(mapfs.New(map[string]string{
+		"src/foo/foo.go": `package foo
+
+type Number interface{ ~int | ~float64 }
+
+type Vector[T Number] []T
+`,
+	}))
+	c.IndexEnabled = true
+	c.IndexGoCode = true
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	c.UpdateIndex()
+	ix, _ := c.CurrentIndex()
+	if ix == nil {
+		t.Fatal("no index")
+	}
+	match, _ := ix.lookupWord("Number")
+	if match == nil || len(match.Others) == 0 {
+		t.Errorf("use of Number as a constraint not indexed: %+v", match)
+	}
+}
+
+func TestIndexIncremental(t *testing.T) {
+	fs := overlayfs.New(mapfs.New(map[string]string{
+		"src/foo/foo.go": "package foo\n\nfunc Foo() {}\n",
+		"src/bar/bar.go": "package bar\n\nfunc Bar() {}\n",
+	}))
+	cacheFile := filepath.Join(t.TempDir(), "index.cache")
+	c := NewCorpus(fs)
+	c.IndexCacheFile = cacheFile
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	c.UpdateIndex()
+	bar := c.dirIndexes["/src/bar"]
+	foo := c.dirIndexes["/src/foo"]
+	if bar == nil || foo == nil {
+		t.Fatalf("missing directory indexes: %v", c.dirIndexes)
+	}
+
+	if err := fs.WriteFile("/src/foo/foo.go", []byte("package foo\n\nfunc Foo() {}\n\nfunc Added() {}\n")); err != nil {
+		t.Fatal(err)
+	}
+	c.UpdateIndex()
+	if c.dirIndexes["/src/bar"] != bar {
+		t.Errorf("unchanged directory /src/bar was reindexed")
+	}
+	if c.dirIndexes["/src/foo"] == foo {
+		t.Errorf("changed directory /src/foo was not reindexed")
+	}
+	checkSearch(t, c, "Added", []Match{
+		{File: "/src/foo/foo.go", Line: 5, Kind: FuncDecl},
+		{File: "/src/foo/foo.go", Line: 5, Textual: true},
+	})
+	checkSearch(t, c, "Bar", []Match{
+		{File: "/src/bar/bar.go", Line: 3, Kind: FuncDecl},
+		{File: "/src/bar/bar.go", Line: 3, Textual: true},
+	})
+
+	// A new corpus reuses the index cache. The mapfs files have no
+	// modification times, so a change to bar.go that preserves its
+	// size goes unnoticed, demonstrating the reuse.
+	c2 := NewCorpus(mapfs.New(map[string]string{
+		"src/foo/foo.go": "package foo\n\nfunc Foo() {}\n\nfunc Added() {}\n",
+		"src/bar/bar.go": "package bar\n\nfunc Baz() {}\n",
+	}))
+	c2.IndexCacheFile = cacheFile
+	if err := c2.Init(); err != nil {
+		t.Fatal(err)
+	}
+	c2.UpdateIndex()
+	checkSearch(t, c2, "Bar", []Match{
+		{File: "/src/bar/bar.go", Line: 3, Kind: FuncDecl},
+		{File: "/src/bar/bar.go", Line: 3, Textual: true},
+	})
+	checkSearch(t, c2, "Added", []Match{
+		{File: "/src/foo/foo.go", Line: 5, Kind: FuncDecl},
+		{File: "/src/foo/foo.go", Line: 5, Textual: true},
+	})
+
+	// With different options, the cache is not used.
+	c3 := NewCorpus(c2.fs)
+	c3.IndexCacheFile = cacheFile
+	c3.IndexFullText = false
+	if err := c3.Init(); err != nil {
+		t.Fatal(err)
+	}
+	c3.UpdateIndex()
+	checkSearch(t, c3, "Baz", []Match{
+		{File: "/src/bar/bar.go", Line: 3, Kind: FuncDecl},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	c := newCorpus(t)
+	c.UpdateIndex()
+	checkSearch(t, c, "Foo", []Match{
+		{File: "/src/foo/foo.go", Line: 11, Kind: TypeDecl},
+		{File: "/src/foo/foo.go", Line: 8, Kind: Use},
+		{File: "/src/foo/foo.go", Line: 13, Kind: Use},
+		{File: "/src/foo/foo.go", Line: 14, Kind: Use},
+		{File: "/src/foo/foo.go", Line: 8, Textual: true},
+		{File: "/src/foo/foo.go", Line: 10, Textual: true},
+		{File: "/src/foo/foo.go", Line: 11, Textual: true},
+		{File: "/src/foo/foo.go", Line: 13, Textual: true},
+		{File: "/src/foo/foo.go", Line: 14, Textual: true},
+	})
+	checkSearch(t, c, "Whitelisted", []Match{
+		{File: "/src/bar/readme.txt", Line: 1, Textual: true},
+	})
+	if _, err := c.Search("a(", 0); err == nil {
+		t.Errorf("Search(%q) succeeded, want error", "a(")
+	}
+	if got, err := c.Search("Foo", 2); err != nil || len(got) != 2 {
+		t.Errorf("Search(%q, 2) = %v, %v; want 2 matches", "Foo", got, err)
+	}
+}
+
+func checkSearch(t *testing.T, c *Corpus, query string, want []Match) {
+	t.Helper()
+	got, err := c.Search(query, 0)
+	if err != nil {
+		t.Errorf("Search(%q): %v", query, err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(%q) = %v, want %v", query, got, want)
+	}
+}
@@ -6,6 +6,7 @@ package godoc
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -75,6 +76,81 @@ func (c *Corpus) Lookup(query string) SearchResult {
 	return *result
 }
 
+// A Match is a single match of a query in the search index,
+// as reported by Corpus.Search.
+type Match struct {
+	File    string   // file path, such as "/src/net/http/server.go"
+	Line    int      // line number, starting at 1
+	Kind    SpotKind // kind of identifier occurrence, if not Textual
+	Textual bool     // whether the match is a full text match
+}
+
+// Search looks up query in the current search index and returns at
+// most max matches, or MaxResults matches if max <= 0. Like Lookup,
+// it reports the occurrences of query as a (qualified) identifier,
+// declarations first, followed by, if full text indexing is enabled,
+// the lines matching query as a regular expression.
+func (c *Corpus) Search(query string, max int) ([]Match, error) {
+	if max <= 0 {
+		max = c.MaxResults
+	}
+	index, _ := c.CurrentIndex()
+	if index == nil {
+		return nil, errors.New("no search index")
+	}
+
+	var matches []Match
+	r, err := index.Lookup(query)
+	if err != nil && !c.IndexFullText {
+		return nil, err
+	}
+	if r != nil && r.Hit != nil {
+		add := func(list HitList) {
+			for _, pak := range list {
+				for _, file := range pak.Files {
+					for _, group := range file.Groups {
+						for _, info := range group {
+							line := info.Lori()
+							if info.IsIndex() {
+								s := index.Snippet(line)
+								if s == nil {
+									continue
+								}
+								line = s.Line
+							}
+							matches = append(matches, Match{
+								File: file.File.Path(),
+								Line: line,
+								Kind: info.Kind(),
+							})
+						}
+					}
+				}
+			}
+		}
+		add(r.Hit.Decls)
+		add(r.Hit.Others)
+	}
+
+	if c.IndexFullText && len(matches) < max {
+		rx, err := regexp.Compile(query)
+		if err != nil {
+			return nil, err
+		}
+		_, found := index.LookupRegexp(rx, max-len(matches))
+		for _, fl := range found {
+			for _, line := range fl.Lines {
+				matches = append(matches, Match{File: fl.Filename, Line: line, Textual: true})
+			}
+		}
+	}
+
+	if len(matches) > max {
+		matches = matches[:max]
+	}
+	return matches, nil
+}
+
 // SearchResultDoc optionally specifies a function returning an HTML body
 // displaying search results matching godoc documentation.
 func (p *Presentation) SearchResultDoc(result SearchResult) []byte {
@@ -145,7 +145,7 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 			if mode&AllMethods != 0 {
 				m |= doc.AllMethods
 			}
-			info.PDoc = doc.New(pkg, pathpkg.Clean(relpath), m) // no trailing '/' in importpath
+			info.PDoc = doc.New(pkg, importPath(relpath), m)
 			if mode&NoTypeAssoc != 0 {
 				for _, t := range info.PDoc.Types {
 					info.PDoc.Consts = append(info.PDoc.Consts, t.Consts...)
@@ -223,6 +223,20 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 	return info
 }
 
+// importPath returns the import path of the package in the directory
+// relpath, without a trailing '/' and without the version of a module
+// named explicitly, as in "golang.org/x/text@v0.14.0/language".
+func importPath(relpath string) string {
+	relpath = pathpkg.Clean(relpath)
+	if i := strings.Index(relpath, "@"); i >= 0 {
+		if j := strings.Index(relpath[i:], "/"); j >= 0 {
+			return relpath[:i] + relpath[i+j:]
+		}
+		return relpath[:i]
+	}
+	return relpath
+}
+
 func (h *handlerServer) includePath(path string, mode PageInfoMode) (r bool) {
 	// if the path is under one of the exclusion paths, don't list.
 	for _, e := range h.exclude {
@@ -14,6 +14,7 @@ import (
 	"testing"
 	"text/template"
 
+	"golang.org/x/tools/godoc/static"
 	"golang.org/x/tools/godoc/vfs/mapfs"
 )
 
@@ -238,3 +239,61 @@ func NewPair[K, V any](k K, v V) Pair[K, V] {
 		t.Errorf("unexpected functions in package documentation")
 	}
 }
+
+// TestGenericsHTML tests the HTML presentation of the index of a
+// package with generic types.
+func TestGenericsHTML(t *testing.T) {
+	c := NewCorpus(mapfs.New(map[string]string{
+		"src/p/p.go": `package p
+
+type Number interface {
+	~int | ~float64
+}
+
+type List[T any] struct{ Val T }
+
+type Pair[K comparable, V Number] struct {
+	K K
+	V V
+}
+
+type Plain struct{}
+`,
+	}))
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	p := NewPresentation(c)
+	p.PackageHTML = template.Must(template.New("package.html").Funcs(p.FuncMap()).Parse(static.Files["package.html"]))
+	p.GodocHTML = template.Must(template.New("").Parse(`{{printf "%s" .Body}}`))
+
+	r := &http.Request{URL: &url.URL{Path: "/pkg/p/"}}
+	rw := httptest.NewRecorder()
+	p.ServeHTTP(rw, r)
+	for _, want := range []string{
+		`<a href="#List">type List[T any]</a>`,
+		`<a href="#Pair">type Pair[K comparable, V Number]</a>`,
+		`<a href="#Plain">type Plain</a>`,
+		`~<a href="/pkg/builtin/#int">int</a> | ~<a href="/pkg/builtin/#float64">float64</a>`,
+		`type Pair[K <a href="/pkg/builtin/#comparable">comparable</a>, V <a href="#Number">Number</a>] struct {`,
+	} {
+		if !strings.Contains(rw.Body.String(), want) {
+			t.Errorf("package page does not contain %q:\n%s", want, rw.Body)
+		}
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	for _, test := range []struct {
+		relpath, want string
+	}{
+		{"fmt", "fmt"},
+		{"golang.org/x/text/language/", "golang.org/x/text/language"},
+		{"golang.org/x/text@v0.14.0", "golang.org/x/text"},
+		{"golang.org/x/text@v0.14.0/language", "golang.org/x/text/language"},
+	} {
+		if got := importPath(test.relpath); got != test.want {
+			t.Errorf("importPath(%q) = %q; want %q", test.relpath, got, test.want)
+		}
+	}
+}
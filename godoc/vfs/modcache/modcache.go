@@ -0,0 +1,285 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modcache provides an implementation of the FileSystem
+// interface that presents the modules in a Go module cache
+// (see 'go help gomodcache') as a single tree of source files,
+// laid out like the src directory of a GOPATH workspace.
+//
+// Each module appears in the directory named by its module path,
+// such as /golang.org/x/text, holding the files of one version of
+// the module in the cache: the latest one, or the one required by
+// a go.mod file given to UseGoMod. Every version in the cache may
+// also be reached, although ReadDir does not list it, in the
+// directory named by the module path, "@", and the version, such
+// as /golang.org/x/text@v0.14.0.
+package modcache // import "golang.org/x/tools/godoc/vfs/modcache"
+
+import (
+	"os"
+	pathpkg "path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// An FS is a FileSystem presenting the modules in a module cache.
+//
+// Its methods may be called concurrently, except for UseGoMod,
+// which must be called before the FS is put to use.
+type FS struct {
+	dir     string
+	cache   vfs.FileSystem      // the module cache directory
+	modules map[string]*modInfo // by module path
+
+	selected map[string]string   // module path -> cache directory of its version in the tree
+	children map[string][]string // directory -> sorted names of its elements that lead to modules
+}
+
+// A modInfo records the versions of a module in the cache.
+type modInfo struct {
+	versions []string          // in increasing semver order
+	dirs     map[string]string // version -> directory, relative to the cache
+}
+
+// New returns an FS presenting the modules in the module cache
+// rooted at dir, such as the output of 'go env GOMODCACHE'.
+// The modules are found when New is called; modules added to
+// the cache later are not presented.
+func New(dir string) (*FS, error) {
+	fs := &FS{
+		dir:     dir,
+		cache:   vfs.OS(dir),
+		modules: make(map[string]*modInfo),
+	}
+	if err := fs.scan(""); err != nil {
+		return nil, err
+	}
+	fs.selected = make(map[string]string)
+	for path, m := range fs.modules {
+		semver.Sort(m.versions)
+		fs.selected[path] = m.dirs[latest(m.versions)]
+	}
+	fs.link()
+	return fs, nil
+}
+
+// scan records the modules in the directory rel of the cache,
+// and in its subdirectories.
+func (fs *FS) scan(rel string) error {
+	list, err := fs.cache.ReadDir("/" + rel)
+	if err != nil {
+		return err
+	}
+	for _, fi := range list {
+		if !fi.IsDir() {
+			continue
+		}
+		name := pathpkg.Join(rel, fi.Name())
+		if name == "cache" {
+			continue // the download cache, holding zip files
+		}
+		escPath, escVersion, ok := strings.Cut(name, "@")
+		if !ok {
+			if err := fs.scan(name); err != nil {
+				return err
+			}
+			continue
+		}
+		path, err := module.UnescapePath(escPath)
+		if err != nil {
+			continue
+		}
+		version, err := module.UnescapeVersion(escVersion)
+		if err != nil || !semver.IsValid(version) {
+			continue // not a module, such as a partial extraction
+		}
+		m := fs.modules[path]
+		if m == nil {
+			m = &modInfo{dirs: make(map[string]string)}
+			fs.modules[path] = m
+		}
+		m.versions = append(m.versions, version)
+		m.dirs[version] = name
+	}
+	return nil
+}
+
+// latest returns the latest of the sorted versions,
+// preferring releases to prereleases.
+func latest(versions []string) string {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if semver.Prerelease(versions[i]) == "" {
+			return versions[i]
+		}
+	}
+	return versions[len(versions)-1]
+}
+
+// link records the directories that lead to the selected modules.
+func (fs *FS) link() {
+	fs.children = make(map[string][]string)
+	for path := range fs.selected {
+		for dir := path; dir != ""; {
+			parent, name := "", dir
+			if i := strings.LastIndex(dir, "/"); i >= 0 {
+				parent, name = dir[:i], dir[i+1:]
+			}
+			fs.children[parent] = append(fs.children[parent], name)
+			dir = parent
+		}
+	}
+	for dir, names := range fs.children {
+		slices.Sort(names)
+		fs.children[dir] = slices.Compact(names)
+	}
+}
+
+// UseGoMod presents the modules required by the go.mod file with
+// the given file name and contents at the versions it requires,
+// after applying its replacements by other modules, so that the
+// tree resolves import paths as a build of the main module would.
+// Modules whose required version is not in the cache keep their
+// current version.
+func (fs *FS) UseGoMod(filename string, data []byte) error {
+	f, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		return err
+	}
+	required := make(map[string]string)
+	for _, r := range f.Require {
+		required[r.Mod.Path] = r.Mod.Version
+		if m := fs.modules[r.Mod.Path]; m != nil && m.dirs[r.Mod.Version] != "" {
+			fs.selected[r.Mod.Path] = m.dirs[r.Mod.Version]
+		}
+	}
+	for _, r := range f.Replace {
+		if r.New.Version == "" {
+			continue // replaced by a directory outside the cache
+		}
+		if r.Old.Version != "" && r.Old.Version != required[r.Old.Path] {
+			continue
+		}
+		if m := fs.modules[r.New.Path]; m != nil && m.dirs[r.New.Version] != "" {
+			fs.selected[r.Old.Path] = m.dirs[r.New.Version]
+		}
+	}
+	fs.link()
+	return nil
+}
+
+// Versions returns the versions of the module with the given path
+// in the cache, in increasing semver order.
+func (fs *FS) Versions(path string) []string {
+	if m := fs.modules[path]; m != nil {
+		return slices.Clone(m.versions)
+	}
+	return nil
+}
+
+// resolve returns the path in the cache of the file at path p,
+// or "" if p is not within a module.
+func (fs *FS) resolve(p string) string {
+	if i := strings.Index(p, "@"); i >= 0 {
+		version, rest, _ := strings.Cut(p[i+1:], "/")
+		if m := fs.modules[p[:i]]; m != nil && m.dirs[version] != "" {
+			return pathpkg.Join("/", m.dirs[version], rest)
+		}
+	}
+	for path := p; path != "." && path != ""; path = pathpkg.Dir(path) {
+		if dir, ok := fs.selected[path]; ok {
+			return pathpkg.Join("/", dir, p[len(path):])
+		}
+	}
+	return ""
+}
+
+// clean returns the cleaned form of the path p, without a leading slash.
+func clean(p string) string {
+	return strings.TrimPrefix(pathpkg.Clean("/"+p), "/")
+}
+
+func (fs *FS) String() string { return "modcache(" + fs.dir + ")" }
+
+// RootType reports that every directory holds the
+// source files of third-party packages, as in GOPATH.
+func (fs *FS) RootType(p string) vfs.RootType { return vfs.RootTypeGoPath }
+
+func (fs *FS) Open(p string) (vfs.ReadSeekCloser, error) {
+	name := fs.resolve(clean(p))
+	if name == "" {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	return fs.cache.Open(name)
+}
+
+func (fs *FS) Lstat(p string) (os.FileInfo, error) {
+	return fs.stat(p, fs.cache.Lstat)
+}
+
+func (fs *FS) Stat(p string) (os.FileInfo, error) {
+	return fs.stat(p, fs.cache.Stat)
+}
+
+func (fs *FS) stat(p string, stat func(string) (os.FileInfo, error)) (os.FileInfo, error) {
+	p = clean(p)
+	if name := fs.resolve(p); name != "" {
+		fi, err := stat(name)
+		if err == nil || fs.children[p] == nil {
+			return fi, err
+		}
+	}
+	if p == "" || fs.children[p] != nil {
+		return dirInfo(pathpkg.Base("/" + p)), nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+}
+
+func (fs *FS) ReadDir(p string) ([]os.FileInfo, error) {
+	p = clean(p)
+	children := fs.children[p]
+	var list []os.FileInfo
+	if name := fs.resolve(p); name != "" {
+		var err error
+		list, err = fs.cache.ReadDir(name)
+		if err != nil && children == nil {
+			return nil, err
+		}
+	} else if children == nil && p != "" {
+		return nil, &os.PathError{Op: "readdir", Path: p, Err: os.ErrNotExist}
+	}
+
+	// Add the directories that lead to nested modules.
+	seen := make(map[string]bool)
+	for _, fi := range list {
+		seen[fi.Name()] = true
+	}
+	added := false
+	for _, name := range children {
+		if !seen[name] {
+			list = append(list, dirInfo(name))
+			added = true
+		}
+	}
+	if added {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	}
+	return list, nil
+}
+
+// A dirInfo is the FileInfo of a directory leading to modules.
+type dirInfo string
+
+func (fi dirInfo) Name() string       { return string(fi) }
+func (fi dirInfo) Size() int64        { return 0 }
+func (fi dirInfo) Mode() os.FileMode  { return os.ModeDir | 0555 }
+func (fi dirInfo) ModTime() time.Time { return time.Time{} }
+func (fi dirInfo) IsDir() bool        { return true }
+func (fi dirInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,203 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modcache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// newCache returns an FS for a module cache holding the given files,
+// keyed by their slash-separated paths relative to the cache.
+func newCache(t *testing.T, files map[string]string) *FS {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+var cacheFiles = map[string]string{
+	"cache/download/golang.org/x/text/@v/list":                  "v0.3.0\n",
+	"golang.org/x/text@v0.3.0/go.mod":                           "module golang.org/x/text\n",
+	"golang.org/x/text@v0.3.0/language/lang.go":                 "package language // v0.3.0",
+	"golang.org/x/text@v0.14.0/go.mod":                          "module golang.org/x/text\n",
+	"golang.org/x/text@v0.14.0/language/lang.go":                "package language // v0.14.0",
+	"golang.org/x/text@v0.15.0-pre/language/lang.go":            "package language // v0.15.0-pre",
+	"golang.org/x/text@v0.3.0.tmp-1234/go.mod":                  "module golang.org/x/text\n",
+	"example.com/!upper@v1.0.0/upper.go":                        "package upper",
+	"example.com/mod@v1.0.0/mod.go":                             "package mod",
+	"example.com/mod/sub@v1.1.0/sub.go":                         "package sub",
+	"example.com/pre@v0.0.0-20240101000000-abcdefabcdef/pre.go": "package pre",
+}
+
+func readDir(t *testing.T, fs vfs.FileSystem, dir string) []string {
+	t.Helper()
+	list, err := fs.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir(%q): %v", dir, err)
+	}
+	var names []string
+	for _, fi := range list {
+		name := fi.Name()
+		if fi.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func readFile(t *testing.T, fs vfs.FileSystem, name string) string {
+	t.Helper()
+	data, err := vfs.ReadFile(fs, name)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %v", name, err)
+	}
+	return string(data)
+}
+
+func TestReadDir(t *testing.T) {
+	fs := newCache(t, cacheFiles)
+	for _, test := range []struct {
+		dir  string
+		want []string
+	}{
+		{"/", []string{"example.com/", "golang.org/"}},
+		{"/example.com", []string{"Upper/", "mod/", "pre/"}},
+		{"/example.com/mod", []string{"mod.go", "sub/"}},
+		{"/example.com/mod/sub/", []string{"sub.go"}},
+		{"/golang.org/x", []string{"text/"}},
+		{"/golang.org/x/text", []string{"go.mod", "language/"}},
+		{"/golang.org/x/text@v0.15.0-pre", []string{"language/"}},
+	} {
+		if got := readDir(t, fs, test.dir); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ReadDir(%q) = %q, want %q", test.dir, got, test.want)
+		}
+	}
+
+	for _, dir := range []string{"/cache", "/golang.org/x/text/missing", "/golang.org/x/text@v0.4.0", "/example.org"} {
+		if _, err := fs.ReadDir(dir); !os.IsNotExist(err) {
+			t.Errorf("ReadDir(%q) = %v, want a not-exist error", dir, err)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	fs := newCache(t, cacheFiles)
+	for _, test := range []struct {
+		name, want string
+	}{
+		{"/golang.org/x/text/language/lang.go", "package language // v0.14.0"},
+		{"golang.org/x/text/language/lang.go", "package language // v0.14.0"},
+		{"/golang.org/x/text@v0.3.0/language/lang.go", "package language // v0.3.0"},
+		{"/golang.org/x/text@v0.15.0-pre/language/lang.go", "package language // v0.15.0-pre"},
+		{"/example.com/Upper/upper.go", "package upper"},
+		{"/example.com/mod/sub/sub.go", "package sub"},
+		{"/example.com/pre/pre.go", "package pre"},
+	} {
+		if got := readFile(t, fs, test.name); got != test.want {
+			t.Errorf("ReadFile(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	for _, name := range []string{"/golang.org/x/text", "/golang.org/x/text/none.go", "/golang.org/x/text@v0.4.0/go.mod", "/cache/download/golang.org/x/text/@v/list"} {
+		if _, err := fs.Open(name); err == nil {
+			t.Errorf("Open(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestStat(t *testing.T) {
+	fs := newCache(t, cacheFiles)
+	for _, test := range []struct {
+		name  string
+		isDir bool
+	}{
+		{"/", true},
+		{"/golang.org", true},
+		{"/golang.org/x/text", true},
+		{"/golang.org/x/text/go.mod", false},
+		{"/golang.org/x/text@v0.3.0", true},
+		{"/example.com/mod/sub", true},
+	} {
+		fi, err := fs.Stat(test.name)
+		if err != nil {
+			t.Errorf("Stat(%q): %v", test.name, err)
+			continue
+		}
+		if fi.IsDir() != test.isDir {
+			t.Errorf("Stat(%q).IsDir() = %v, want %v", test.name, fi.IsDir(), test.isDir)
+		}
+	}
+	if _, err := fs.Stat("/golang.org/y"); !os.IsNotExist(err) {
+		t.Errorf("Stat(/golang.org/y) = %v, want a not-exist error", err)
+	}
+
+	if got, want := fs.Versions("golang.org/x/text"), []string{"v0.3.0", "v0.14.0", "v0.15.0-pre"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Versions = %q, want %q", got, want)
+	}
+}
+
+func TestUseGoMod(t *testing.T) {
+	fs := newCache(t, cacheFiles)
+	const gomod = `module example.com/main
+
+go 1.22
+
+require (
+	golang.org/x/text v0.3.0
+	example.com/old v1.0.0
+	example.com/mod v1.2.0 // not in the cache
+)
+
+replace example.com/old => example.com/mod v1.0.0
+`
+	if err := fs.UseGoMod("go.mod", []byte(gomod)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, fs, "/golang.org/x/text/language/lang.go"), "package language // v0.3.0"; got != want {
+		t.Errorf("after UseGoMod, lang.go = %q, want %q", got, want)
+	}
+	if got, want := readFile(t, fs, "/example.com/old/mod.go"), "package mod"; got != want {
+		t.Errorf("after UseGoMod, old/mod.go = %q, want %q", got, want)
+	}
+	if got, want := readDir(t, fs, "/example.com"), []string{"Upper/", "mod/", "old/", "pre/"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after UseGoMod, ReadDir(/example.com) = %q, want %q", got, want)
+	}
+	if got, want := readFile(t, fs, "/example.com/mod/mod.go"), "package mod"; got != want {
+		t.Errorf("after UseGoMod, mod/mod.go = %q, want %q", got, want)
+	}
+
+	if err := fs.UseGoMod("go.mod", []byte("require (")); err == nil {
+		t.Errorf("UseGoMod of a malformed go.mod file succeeded, want an error")
+	}
+}
+
+func TestNameSpace(t *testing.T) {
+	ns := vfs.NewNameSpace()
+	ns.Bind("/src", newCache(t, cacheFiles), "/", vfs.BindAfter)
+	if got, want := readFile(t, ns, "/src/golang.org/x/text/language/lang.go"), "package language // v0.14.0"; got != want {
+		t.Errorf("ReadFile = %q, want %q", got, want)
+	}
+	if got := ns.RootType("/src/golang.org/x/text"); got != vfs.RootTypeGoPath {
+		t.Errorf("RootType = %q, want %q", got, vfs.RootTypeGoPath)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package overlayfs provides an implementation of the FileSystem
+// interface that layers files written in memory over another FileSystem.
+//
+// It lets a tool that embeds godoc, such as an editor integration,
+// present unsaved edits without writing them to disk, and learn of
+// them through Notify in order to reindex the affected files.
+package overlayfs // import "golang.org/x/tools/godoc/vfs/overlayfs"
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	pathpkg "path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// An FS is a FileSystem whose files are those written by WriteFile,
+// and otherwise those of a base FileSystem, except for those removed
+// by Remove. Its methods may be called concurrently.
+type FS struct {
+	base vfs.FileSystem
+
+	mu     sync.Mutex
+	files  map[string]*file // by cleaned absolute path; nil for a removed file
+	notify []chan<- string
+}
+
+// A file is the content of a file written by WriteFile.
+type file struct {
+	data    []byte
+	modTime time.Time
+}
+
+// New returns an FS that initially has the same files as base.
+func New(base vfs.FileSystem) *FS {
+	return &FS{base: base, files: make(map[string]*file)}
+}
+
+// clean returns the cleaned absolute form of the path p.
+func clean(p string) string {
+	return pathpkg.Clean("/" + p)
+}
+
+func (fs *FS) String() string { return "overlay(" + fs.base.String() + ")" }
+
+func (fs *FS) RootType(p string) vfs.RootType { return fs.base.RootType(p) }
+
+// WriteFile sets the content of the named file to a copy of data,
+// creating it and any missing parent directories if necessary.
+// It does not change the base FileSystem.
+func (fs *FS) WriteFile(name string, data []byte) error {
+	name = clean(name)
+	if name == "/" {
+		return &os.PathError{Op: "write", Path: name, Err: os.ErrInvalid}
+	}
+	if fi, err := fs.Stat(name); err == nil && fi.IsDir() {
+		return fmt.Errorf("write %s: is a directory", name)
+	}
+	for dir := pathpkg.Dir(name); dir != "/"; dir = pathpkg.Dir(dir) {
+		if fi, err := fs.Stat(dir); err == nil && !fi.IsDir() {
+			return fmt.Errorf("write %s: %s is not a directory", name, dir)
+		}
+	}
+
+	fs.mu.Lock()
+	fs.files[name] = &file{data: bytes.Clone(data), modTime: time.Now()}
+	fs.mu.Unlock()
+	fs.changed(name)
+	return nil
+}
+
+// Remove removes the named file, which may be a file of the base
+// FileSystem. It does not remove directories.
+func (fs *FS) Remove(name string) error {
+	name = clean(name)
+	fi, err := fs.Stat(name)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("remove %s: is a directory", name)
+	}
+
+	fs.mu.Lock()
+	if _, err := fs.base.Lstat(name); err == nil {
+		fs.files[name] = nil // hide the base file
+	} else {
+		delete(fs.files, name)
+	}
+	fs.mu.Unlock()
+	fs.changed(name)
+	return nil
+}
+
+// Notify causes the path of each file written or removed to be sent
+// to ch. As with signal.Notify, sends do not block, so the caller must
+// ensure that ch has sufficient buffer space to keep up with the
+// expected rate of changes.
+func (fs *FS) Notify(ch chan<- string) {
+	fs.mu.Lock()
+	fs.notify = append(fs.notify, ch)
+	fs.mu.Unlock()
+}
+
+// changed notifies the registered channels of a change to name.
+func (fs *FS) changed(name string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for _, ch := range fs.notify {
+		select {
+		case ch <- name:
+		default:
+		}
+	}
+}
+
+// lookup reports whether the overlay determines the named file,
+// and if so returns it, or nil if the file was removed.
+func (fs *FS) lookup(name string) (f *file, ok bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	f, ok = fs.files[name]
+	return f, ok
+}
+
+// isDir reports whether the overlay has a file in the named directory
+// or its subdirectories.
+func (fs *FS) isDir(name string) bool {
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for p, f := range fs.files {
+		if f != nil && strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *FS) Open(name string) (vfs.ReadSeekCloser, error) {
+	name = clean(name)
+	if f, ok := fs.lookup(name); ok {
+		if f == nil {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		return nopCloser{bytes.NewReader(f.data)}, nil
+	}
+	return fs.base.Open(name)
+}
+
+func (fs *FS) Lstat(name string) (os.FileInfo, error) {
+	return fs.stat(name, fs.base.Lstat)
+}
+
+func (fs *FS) Stat(name string) (os.FileInfo, error) {
+	return fs.stat(name, fs.base.Stat)
+}
+
+func (fs *FS) stat(name string, baseStat func(string) (os.FileInfo, error)) (os.FileInfo, error) {
+	name = clean(name)
+	if f, ok := fs.lookup(name); ok {
+		if f == nil {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+		}
+		return fileInfo{pathpkg.Base(name), f}, nil
+	}
+	fi, err := baseStat(name)
+	if fs.isDir(name) && (err != nil || !fi.IsDir()) {
+		return fileInfo{name: pathpkg.Base(name)}, nil
+	}
+	return fi, err
+}
+
+func (fs *FS) ReadDir(name string) ([]os.FileInfo, error) {
+	name = clean(name)
+	baseList, err := fs.base.ReadDir(name)
+	if err != nil && !fs.isDir(name) {
+		return nil, err
+	}
+
+	entries := make(map[string]os.FileInfo)
+	for _, fi := range baseList {
+		entries[fi.Name()] = fi
+	}
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	fs.mu.Lock()
+	for p, f := range fs.files {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			// A file in a subdirectory implies the subdirectory.
+			if f != nil {
+				if fi, ok := entries[rest[:i]]; !ok || !fi.IsDir() {
+					entries[rest[:i]] = fileInfo{name: rest[:i]}
+				}
+			}
+		} else if f == nil {
+			delete(entries, rest)
+		} else {
+			entries[rest] = fileInfo{rest, f}
+		}
+	}
+	fs.mu.Unlock()
+
+	list := make([]os.FileInfo, 0, len(entries))
+	for _, fi := range entries {
+		list = append(list, fi)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+// fileInfo is the implementation of FileInfo for the files and
+// directories of the overlay.
+type fileInfo struct {
+	name string
+	f    *file // nil for a directory
+}
+
+func (fi fileInfo) Name() string { return fi.name }
+
+func (fi fileInfo) Size() int64 {
+	if fi.f != nil {
+		return int64(len(fi.f.data))
+	}
+	return 0
+}
+
+func (fi fileInfo) ModTime() time.Time {
+	if fi.f != nil {
+		return fi.f.modTime
+	}
+	return time.Time{}
+}
+
+func (fi fileInfo) Mode() os.FileMode {
+	if fi.f == nil {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi fileInfo) IsDir() bool      { return fi.f == nil }
+func (fi fileInfo) Sys() interface{} { return nil }
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
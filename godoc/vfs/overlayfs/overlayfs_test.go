@@ -0,0 +1,101 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package overlayfs_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/godoc/vfs"
+	"golang.org/x/tools/godoc/vfs/mapfs"
+	"golang.org/x/tools/godoc/vfs/overlayfs"
+)
+
+func TestOverlay(t *testing.T) {
+	fs := overlayfs.New(mapfs.New(map[string]string{
+		"src/a/a.go": "package a",
+		"src/a/b.go": "package a // b",
+		"src/c/c.go": "package c",
+	}))
+	changes := make(chan string, 10)
+	fs.Notify(changes)
+
+	if err := fs.WriteFile("/src/a/a.go", []byte("package a // edited")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("src/d/e/d.go", []byte("package d")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("/src/a/b.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("/src/a/b.go"); !os.IsNotExist(err) {
+		t.Errorf("Remove of removed file: got error %v, want not exist", err)
+	}
+	if err := fs.WriteFile("/src/a", nil); err == nil {
+		t.Errorf("WriteFile of directory succeeded")
+	}
+	if err := fs.WriteFile("/src/a/a.go/x", nil); err == nil {
+		t.Errorf("WriteFile in file succeeded")
+	}
+
+	for _, want := range []string{"/src/a/a.go", "/src/d/e/d.go", "/src/a/b.go"} {
+		if got := <-changes; got != want {
+			t.Errorf("notified of change to %s, want %s", got, want)
+		}
+	}
+	select {
+	case got := <-changes:
+		t.Errorf("unexpected notification of change to %s", got)
+	default:
+	}
+
+	for name, want := range map[string]string{
+		"/src/a/a.go":   "package a // edited",
+		"/src/c/c.go":   "package c",
+		"/src/d/e/d.go": "package d",
+	} {
+		if got, err := vfs.ReadFile(fs, name); err != nil || string(got) != want {
+			t.Errorf("ReadFile(%s) = %q, %v, want %q", name, got, err, want)
+		}
+		fi, err := fs.Stat(name)
+		if err != nil {
+			t.Errorf("Stat(%s): %v", name, err)
+		} else if fi.IsDir() || fi.Size() != int64(len(want)) {
+			t.Errorf("Stat(%s) = {IsDir: %v, Size: %d}, want file of size %d", name, fi.IsDir(), fi.Size(), len(want))
+		}
+	}
+	if _, err := fs.Open("/src/a/b.go"); !os.IsNotExist(err) {
+		t.Errorf("Open of removed file: got error %v, want not exist", err)
+	}
+	for _, name := range []string{"/src/d", "/src/d/e"} {
+		if fi, err := fs.Lstat(name); err != nil || !fi.IsDir() {
+			t.Errorf("Lstat(%s) = %v, %v, want directory", name, fi, err)
+		}
+	}
+
+	for dir, want := range map[string][]string{
+		"/src":   {"a", "c", "d"},
+		"/src/a": {"a.go"},
+		"/src/d": {"e"},
+	} {
+		list, err := fs.ReadDir(dir)
+		if err != nil {
+			t.Errorf("ReadDir(%s): %v", dir, err)
+			continue
+		}
+		var got []string
+		for _, fi := range list {
+			got = append(got, fi.Name())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadDir(%s) = %v, want %v", dir, got, want)
+		}
+	}
+	if _, err := fs.ReadDir("/src/x"); err == nil {
+		t.Errorf("ReadDir of missing directory succeeded")
+	}
+}
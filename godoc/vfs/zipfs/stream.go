@@ -0,0 +1,460 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package zipfs
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// NewStream returns a FileSystem for the zip archive of the given size
+// read from r, which may be a large file or a remote object.
+//
+// Unlike New, which holds every entry of the archive's central
+// directory in memory, NewStream keeps only the offset of each entry's
+// directory record, sorted by name, and reads the records from r as
+// needed. File contents are decompressed as they are read, and seeking
+// within a compressed file rereads it from the start when necessary.
+//
+// Only the stored and deflated compression methods are supported.
+func NewStream(r io.ReaderAt, size int64, name string) (vfs.FileSystem, error) {
+	cdOffset, cdSize, n, err := readDirectoryEnd(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("zipfs: %s: %v", name, err)
+	}
+
+	// Read the names of the entries, in order to sort their offsets.
+	type record struct {
+		name   string
+		offset int64
+	}
+	records := make([]record, 0, min(n, 1<<20))
+	br := bufio.NewReader(io.NewSectionReader(r, cdOffset, cdSize))
+	offset := cdOffset
+	for i := int64(0); i < n; i++ {
+		e, size, err := parseDirectoryRecord(br)
+		if err != nil {
+			return nil, fmt.Errorf("zipfs: %s: %v", name, err)
+		}
+		// Directories are inferred from the names of files.
+		if !strings.HasSuffix(e.name, "/") {
+			records = append(records, record{e.name, offset})
+		}
+		offset += size
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].name < records[j].name })
+	fs := &streamFS{r: r, name: name, offsets: make([]int64, len(records))}
+	for i, rec := range records {
+		fs.offsets[i] = rec.offset
+	}
+	return fs, nil
+}
+
+// streamFS is the implementation of FileSystem returned by NewStream.
+type streamFS struct {
+	r       io.ReaderAt
+	name    string
+	offsets []int64 // offsets of the central directory records, in order of name
+}
+
+func (fs *streamFS) String() string {
+	return "zip(" + fs.name + ")"
+}
+
+func (fs *streamFS) RootType(abspath string) vfs.RootType {
+	return (*zipFS)(nil).RootType(abspath)
+}
+
+// entry returns the ith entry, in order of name.
+func (fs *streamFS) entry(i int) (*entry, error) {
+	e, _, err := parseDirectoryRecord(io.NewSectionReader(fs.r, fs.offsets[i], 1<<63-1-fs.offsets[i]))
+	return e, err
+}
+
+// lookup is like zipList.lookup. It returns the smallest index of an
+// entry with an exact match for name, or an inexact match starting
+// with name/, or -1 if there is no such entry.
+func (fs *streamFS) lookup(name string) (index int, exact bool, err error) {
+	search := func(name string) int {
+		return sort.Search(len(fs.offsets), func(i int) bool {
+			e, ioErr := fs.entry(i)
+			if ioErr != nil {
+				err = ioErr
+				return true
+			}
+			return name <= e.name
+		})
+	}
+	i := search(name)
+	if err != nil || i >= len(fs.offsets) {
+		return -1, false, err
+	}
+	e, err := fs.entry(i)
+	if err != nil {
+		return -1, false, err
+	}
+	if e.name == name {
+		return i, true, nil
+	}
+	i = search(name + "/")
+	if err != nil || i >= len(fs.offsets) {
+		return -1, false, err
+	}
+	if e, err = fs.entry(i); err != nil {
+		return -1, false, err
+	}
+	if strings.HasPrefix(e.name, name+"/") {
+		return i, false, nil
+	}
+	return -1, false, nil
+}
+
+func (fs *streamFS) stat(abspath string) (int, os.FileInfo, *entry, error) {
+	if isRoot(abspath) {
+		return 0, streamFI{name: ""}, nil, nil
+	}
+	zippath, err := zipPath(abspath)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	i, exact, err := fs.lookup(zippath)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if i < 0 {
+		return 0, nil, nil, &os.PathError{Op: "stat", Path: "/" + zippath, Err: os.ErrNotExist}
+	}
+	_, name := path.Split(zippath)
+	if !exact {
+		return i, streamFI{name: name}, nil, nil // directory
+	}
+	e, err := fs.entry(i)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return i, streamFI{name, e}, e, nil
+}
+
+func (fs *streamFS) Lstat(abspath string) (os.FileInfo, error) {
+	_, fi, _, err := fs.stat(abspath)
+	return fi, err
+}
+
+func (fs *streamFS) Stat(abspath string) (os.FileInfo, error) {
+	_, fi, _, err := fs.stat(abspath)
+	return fi, err
+}
+
+func (fs *streamFS) Open(abspath string) (vfs.ReadSeekCloser, error) {
+	_, fi, e, err := fs.stat(abspath)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, fmt.Errorf("Open: %s is a directory", abspath)
+	}
+	if e.flags&0x1 != 0 {
+		return nil, fmt.Errorf("Open: %s is encrypted", abspath)
+	}
+	if e.method != 0 && e.method != 8 {
+		return nil, fmt.Errorf("Open: %s uses unsupported compression method %d", abspath, e.method)
+	}
+
+	// Find the data, after the local file header.
+	var hdr [30]byte
+	if _, err := fs.r.ReadAt(hdr[:], e.headerOffset); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(hdr[:]) != 0x04034b50 {
+		return nil, fmt.Errorf("Open: %s: invalid local file header", abspath)
+	}
+	dataOffset := e.headerOffset + 30 + int64(binary.LittleEndian.Uint16(hdr[26:])) + int64(binary.LittleEndian.Uint16(hdr[28:]))
+	f := &streamFile{e: e, data: io.NewSectionReader(fs.r, dataOffset, e.csize)}
+	f.reset()
+	return f, nil
+}
+
+func (fs *streamFS) ReadDir(abspath string) ([]os.FileInfo, error) {
+	i, fi, _, err := fs.stat(abspath)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("ReadDir: %s is not a directory", abspath)
+	}
+	var dirname string
+	if !isRoot(abspath) {
+		zippath, err := zipPath(abspath)
+		if err != nil {
+			return nil, err
+		}
+		dirname = zippath + "/"
+	}
+
+	var list []os.FileInfo
+	prevname := ""
+	for ; i < len(fs.offsets); i++ {
+		e, err := fs.entry(i)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(e.name, dirname) {
+			break // not in the same directory anymore
+		}
+		name := e.name[len(dirname):] // local name
+		if j := strings.IndexByte(name, '/'); j >= 0 {
+			// An entry in a subdirectory implies the subdirectory.
+			name, e = name[:j], nil
+		}
+		if name != prevname {
+			list = append(list, streamFI{name, e})
+			prevname = name
+		}
+	}
+	return list, nil
+}
+
+// An entry holds the fields of a central directory record.
+type entry struct {
+	name         string
+	flags        uint16
+	method       uint16
+	modified     time.Time
+	crc32        uint32
+	csize, usize int64
+	headerOffset int64
+}
+
+// parseDirectoryRecord parses the central directory record at the
+// start of r, and returns the entry and the size of the record.
+func parseDirectoryRecord(r io.Reader) (*entry, int64, error) {
+	var hdr [46]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, 0, err
+	}
+	le := binary.LittleEndian
+	if le.Uint32(hdr[:]) != 0x02014b50 {
+		return nil, 0, errors.New("invalid central directory record")
+	}
+	nameLen, extraLen, commentLen := int(le.Uint16(hdr[28:])), int(le.Uint16(hdr[30:])), int(le.Uint16(hdr[32:]))
+	buf := make([]byte, nameLen+extraLen+commentLen)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, 0, err
+	}
+	e := &entry{
+		name:         string(buf[:nameLen]),
+		flags:        le.Uint16(hdr[8:]),
+		method:       le.Uint16(hdr[10:]),
+		modified:     msDosTime(le.Uint16(hdr[14:]), le.Uint16(hdr[12:])),
+		crc32:        le.Uint32(hdr[16:]),
+		csize:        int64(le.Uint32(hdr[20:])),
+		usize:        int64(le.Uint32(hdr[24:])),
+		headerOffset: int64(le.Uint32(hdr[42:])),
+	}
+
+	// Values that do not fit in 32 bits are in the zip64 extra field,
+	// in this order.
+	for extra := buf[nameLen : nameLen+extraLen]; len(extra) >= 4; {
+		tag, size := le.Uint16(extra), int(le.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			break
+		}
+		field := extra[4 : 4+size]
+		extra = extra[4+size:]
+		if tag != 0x0001 {
+			continue
+		}
+		for _, v := range []*int64{&e.usize, &e.csize, &e.headerOffset} {
+			if *v == 0xffffffff {
+				if len(field) < 8 {
+					return nil, 0, errors.New("invalid zip64 extra field")
+				}
+				*v = int64(le.Uint64(field))
+				field = field[8:]
+			}
+		}
+	}
+	return e, int64(len(hdr) + len(buf)), nil
+}
+
+// readDirectoryEnd returns the offset and size of the central
+// directory of the zip archive of the given size, and its number of
+// entries.
+func readDirectoryEnd(r io.ReaderAt, size int64) (offset, dirSize, n int64, err error) {
+	// The end of central directory record is followed by a comment of
+	// at most 65535 bytes.
+	le := binary.LittleEndian
+	bufSize := min(size, 22+65535)
+	buf := make([]byte, bufSize)
+	if _, err := r.ReadAt(buf, size-bufSize); err != nil && err != io.EOF {
+		return 0, 0, 0, err
+	}
+	p := -1
+	for i := len(buf) - 22; i >= 0; i-- {
+		if le.Uint32(buf[i:]) == 0x06054b50 {
+			p = i
+			break
+		}
+	}
+	if p < 0 {
+		return 0, 0, 0, errors.New("not a valid zip file")
+	}
+	end := buf[p:]
+	n = int64(le.Uint16(end[10:]))
+	dirSize = int64(le.Uint32(end[12:]))
+	offset = int64(le.Uint32(end[16:]))
+
+	if n == 0xffff || dirSize == 0xffffffff || offset == 0xffffffff {
+		// Find the zip64 end of central directory record using
+		// the locator that precedes the end record.
+		locator := make([]byte, 20)
+		if _, err := r.ReadAt(locator, size-bufSize+int64(p)-20); err != nil {
+			return 0, 0, 0, err
+		}
+		if le.Uint32(locator) != 0x07064b50 {
+			return 0, 0, 0, errors.New("invalid zip64 end of central directory locator")
+		}
+		end64 := make([]byte, 56)
+		if _, err := r.ReadAt(end64, int64(le.Uint64(locator[8:]))); err != nil {
+			return 0, 0, 0, err
+		}
+		if le.Uint32(end64) != 0x06064b50 {
+			return 0, 0, 0, errors.New("invalid zip64 end of central directory record")
+		}
+		n = int64(le.Uint64(end64[32:]))
+		dirSize = int64(le.Uint64(end64[40:]))
+		offset = int64(le.Uint64(end64[48:]))
+	}
+	if offset < 0 || dirSize < 0 || offset+dirSize > size {
+		return 0, 0, 0, errors.New("invalid central directory")
+	}
+	return offset, dirSize, n, nil
+}
+
+// msDosTime converts an MS-DOS date and time into a time.Time,
+// as archive/zip does.
+func msDosTime(dosDate, dosTime uint16) time.Time {
+	return time.Date(
+		int(dosDate>>9+1980),
+		time.Month(dosDate>>5&0xf),
+		int(dosDate&0x1f),
+		int(dosTime>>11),
+		int(dosTime>>5&0x3f),
+		int(dosTime&0x1f*2),
+		0, // nanoseconds
+		time.UTC,
+	)
+}
+
+// streamFI is the implementation of FileInfo for NewStream.
+type streamFI struct {
+	name string // directory-local name
+	e    *entry // nil for a directory
+}
+
+func (fi streamFI) Name() string { return fi.name }
+
+func (fi streamFI) Size() int64 {
+	if fi.e != nil {
+		return fi.e.usize
+	}
+	return 0 // directory
+}
+
+func (fi streamFI) ModTime() time.Time {
+	if fi.e != nil {
+		return fi.e.modified
+	}
+	return time.Time{} // directory has no modified time entry
+}
+
+func (fi streamFI) Mode() os.FileMode {
+	if fi.e == nil {
+		// Unix directories typically are executable, hence 555.
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi streamFI) IsDir() bool      { return fi.e == nil }
+func (fi streamFI) Sys() interface{} { return nil }
+
+// A streamFile is an open file of a FileSystem returned by NewStream.
+type streamFile struct {
+	e    *entry
+	data *io.SectionReader // compressed data
+
+	r      io.Reader   // decompressed data
+	closer io.Closer   // of the decompressor, if any
+	crc    hash.Hash32 // of the data read so far
+	pos    int64       // offset of r within the decompressed data
+}
+
+// reset starts reading the file from the beginning.
+func (f *streamFile) reset() {
+	f.Close()
+	f.data.Seek(0, io.SeekStart)
+	f.r, f.closer = f.data, nil
+	if f.e.method == 8 {
+		rc := flate.NewReader(f.data)
+		f.r, f.closer = rc, rc
+	}
+	f.crc = crc32.NewIEEE()
+	f.pos = 0
+}
+
+func (f *streamFile) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.crc.Write(p[:n])
+	f.pos += int64(n)
+	if err == io.EOF {
+		if f.pos != f.e.usize {
+			return n, io.ErrUnexpectedEOF
+		}
+		if f.crc.Sum32() != f.e.crc32 {
+			return n, fmt.Errorf("zipfs: checksum error in %s", f.e.name)
+		}
+	}
+	return n, err
+}
+
+func (f *streamFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.e.usize
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("Seek: negative position in %s", f.e.name)
+	}
+	if offset < f.pos {
+		f.reset()
+	}
+	if _, err := io.CopyN(io.Discard, f, offset-f.pos); err != nil && err != io.EOF {
+		return f.pos, err
+	}
+	return f.pos, nil
+}
+
+func (f *streamFile) Close() error {
+	if f.closer != nil {
+		return f.closer.Close()
+	}
+	return nil
+}
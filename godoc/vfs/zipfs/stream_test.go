@@ -0,0 +1,96 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package zipfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	b := new(bytes.Buffer)
+	zw := zip.NewWriter(b)
+	for file, contents := range files {
+		w, err := zw.Create(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Directory entries are ignored.
+	if _, err := zw.Create("bar/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sfs, err := NewStream(bytes.NewReader(b.Bytes()), int64(b.Len()), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run the tests of New against the streaming file system.
+	savedFS, savedStatFuncs := fs, statFuncs
+	defer func() { fs, statFuncs = savedFS, savedStatFuncs }()
+	fs = sfs
+	statFuncs = []statFunc{
+		{"Stat", fs.Stat},
+		{"Lstat", fs.Lstat},
+	}
+	t.Run("ReadDir", TestZipFSReadDir)
+	t.Run("StatFuncs", TestZipFSStatFuncs)
+	t.Run("NotExist", TestZipFSNotExist)
+	t.Run("OpenSeek", TestZipFSOpenSeek)
+	t.Run("RootType", TestRootType)
+}
+
+func TestStreamSeek(t *testing.T) {
+	contents := strings.Repeat("0123456789", 1000)
+	b := new(bytes.Buffer)
+	zw := zip.NewWriter(b)
+	for _, method := range []uint16{zip.Store, zip.Deflate} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: "m" + string(rune('0'+method)), Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, contents)
+	}
+	zw.Close()
+	sfs, err := NewStream(bytes.NewReader(b.Bytes()), int64(b.Len()), "seek")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"/m0", "/m8"} {
+		f, err := sfs.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 5)
+		for _, offset := range []int64{5003, 17, 9990} {
+			if pos, err := f.Seek(offset, io.SeekStart); err != nil || pos != offset {
+				t.Fatalf("%s: Seek(%d) = %d, %v", name, offset, pos, err)
+			}
+			if _, err := io.ReadFull(f, buf); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(buf), contents[offset:offset+5]; got != want {
+				t.Errorf("%s: read %q at offset %d, want %q", name, got, offset, want)
+			}
+		}
+		if pos, err := f.Seek(-3, io.SeekEnd); err != nil || pos != int64(len(contents))-3 {
+			t.Fatalf("%s: Seek(-3, SeekEnd) = %d, %v", name, pos, err)
+		}
+		if rest, err := io.ReadAll(f); err != nil || string(rest) != "789" {
+			t.Errorf("%s: read %q, %v after seeking to end, want %q", name, rest, err, "789")
+		}
+		f.Close()
+	}
+}
@@ -6,10 +6,13 @@ package deprecated
 
 import (
 	"bytes"
+	"fmt"
 	"go/ast"
 	"go/format"
 	"go/token"
 	"go/types"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -19,6 +22,7 @@ import (
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
 	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/versions"
 )
 
 //go:embed doc.go
@@ -43,22 +47,39 @@ func checkDeprecated(pass *analysis.Pass) (interface{}, error) {
 		return nil, err
 	}
 
-	reportDeprecation := func(depr *deprecationFact, node ast.Node) {
+	reportDeprecation := func(depr *deprecationFact, node ast.Node, goversion string, fixes []analysis.SuggestedFix) {
 		// TODO(hyangah): staticcheck.CheckDeprecated has more complex logic. Do we need it here?
 		// TODO(hyangah): Scrub depr.Msg. depr.Msg may contain Go comments
 		// markdown syntaxes but LSP diagnostics do not support markdown syntax.
 
+		// If the deprecation took effect in a later Go release
+		// than the file's, the replacement may not be available.
+		if since := deprecatedSince(depr.Msg); since != "" && versions.Before(goversion, since) {
+			return
+		}
+
 		buf := new(bytes.Buffer)
 		if err := format.Node(buf, pass.Fset, node); err != nil {
 			// This shouldn't happen but let's be conservative.
 			buf.Reset()
 			buf.WriteString("declaration")
 		}
-		pass.ReportRangef(node, "%s is deprecated: %s", buf, depr.Msg)
+		pass.Report(analysis.Diagnostic{
+			Pos:            node.Pos(),
+			End:            node.End(),
+			Message:        fmt.Sprintf("%s is deprecated: %s", buf, depr.Msg),
+			SuggestedFixes: fixes,
+		})
 	}
 
-	nodeFilter := []ast.Node{(*ast.SelectorExpr)(nil)}
+	var goversion string // effective file version ("" => unknown)
+	nodeFilter := []ast.Node{(*ast.File)(nil), (*ast.SelectorExpr)(nil)}
 	inspector.Preorder(nodeFilter, func(node ast.Node) {
+		if file, ok := node.(*ast.File); ok {
+			goversion = versions.FileVersion(pass.TypesInfo, file)
+			return
+		}
+
 		// Caveat: this misses dot-imported objects
 		sel, ok := node.(*ast.SelectorExpr)
 		if !ok {
@@ -97,11 +118,12 @@ func checkDeprecated(pass *analysis.Pass) (interface{}, error) {
 		}
 
 		if depr, ok := deprs.objects[obj]; ok {
-			reportDeprecation(depr, sel)
+			reportDeprecation(depr, sel, goversion, renameFix(pass, sel, obj, depr.Msg))
 		}
 	})
 
 	for _, f := range pass.Files {
+		goversion := versions.FileVersion(pass.TypesInfo, f)
 		for _, spec := range f.Imports {
 			var imp *types.Package
 			var obj types.Object
@@ -134,13 +156,80 @@ func checkDeprecated(pass *analysis.Pass) (interface{}, error) {
 				continue
 			}
 			if depr, ok := deprs.packages[imp]; ok {
-				reportDeprecation(depr, spec.Path)
+				reportDeprecation(depr, spec.Path, goversion, nil)
 			}
 		}
 	}
 	return nil, nil
 }
 
+// sinceRx matches a deprecation message such as "As of Go 1.16, ...".
+var sinceRx = regexp.MustCompile(`\b(?:[Aa]s of|[Ss]ince|[Ss]tarting (?:with|in)) Go (1\.\d+)\b`)
+
+// deprecatedSince returns the Go version, such as "go1.16", stated by
+// a deprecation message as the release since which the identifier has
+// been deprecated, or "" if none.
+func deprecatedSince(msg string) string {
+	if m := sinceRx.FindStringSubmatch(msg); m != nil {
+		return "go" + m[1]
+	}
+	return ""
+}
+
+// replacementRx matches a deprecation message that names a replacement,
+// such as "Use NewT instead." or "Use [T.Close] instead.".
+var replacementRx = regexp.MustCompile(`(?:^|\. )[Uu]se \[?(?:\*?(\w+)\.)?(\w+)\]?(?:\(\))?(?: instead)?(?:[.;,]|$)`)
+
+// renameFix returns a fix that replaces the selected deprecated
+// object by the replacement named in its deprecation message, if the
+// replacement is an object of the same kind and type that may be
+// selected in the same way, so that the change is a simple rename.
+func renameFix(pass *analysis.Pass, sel *ast.SelectorExpr, obj types.Object, msg string) []analysis.SuggestedFix {
+	m := replacementRx.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	qual, name := m[1], m[2]
+	if name == obj.Name() || !token.IsExported(name) {
+		return nil
+	}
+
+	var repl types.Object
+	if selection, ok := pass.TypesInfo.Selections[sel]; ok {
+		// A field or method: the replacement must belong to the same type.
+		if qual != "" {
+			recv := selection.Recv()
+			if ptr, ok := recv.(*types.Pointer); ok {
+				recv = ptr.Elem()
+			}
+			if named, ok := recv.(*types.Named); !ok || named.Obj().Name() != qual {
+				return nil
+			}
+		}
+		repl, _, _ = types.LookupFieldOrMethod(selection.Recv(), true, obj.Pkg(), name)
+	} else {
+		// A package-level object: the replacement must belong to the same package.
+		if qual != "" && qual != obj.Pkg().Name() {
+			return nil
+		}
+		repl = obj.Pkg().Scope().Lookup(name)
+	}
+	if repl == nil || reflect.TypeOf(repl) != reflect.TypeOf(obj) {
+		return nil
+	}
+	if !types.Identical(repl.Type(), obj.Type()) {
+		return nil // the replacement may not be used in the same way
+	}
+	return []analysis.SuggestedFix{{
+		Message: fmt.Sprintf("Replace %s with %s", obj.Name(), name),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     sel.Sel.Pos(),
+			End:     sel.Sel.End(),
+			NewText: []byte(name),
+		}},
+	}}
+}
+
 type deprecationFact struct{ Msg string }
 
 func (*deprecationFact) AFact()           {}
@@ -5,12 +5,25 @@
 package deprecated
 
 import (
+	"path/filepath"
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/internal/testfiles"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, Analyzer, "a")
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "c")
+}
+
+func TestVersions(t *testing.T) {
+	testdata := analysistest.TestData()
+	for _, name := range []string{"go115", "go116"} {
+		t.Run(name, func(t *testing.T) {
+			dir := testfiles.ExtractTxtarFileToTmp(t, filepath.Join(testdata, "src", "since", name+".txtar"))
+			analysistest.Run(t, dir, Analyzer, "example.com/since")
+		})
+	}
 }
@@ -11,6 +11,16 @@
 // The deprecated analyzer looks for deprecated symbols and package
 // imports.
 //
+// A use is not reported if the deprecation note states a Go release
+// since which the identifier is deprecated (as in "Deprecated: As of
+// Go 1.16, ...") and the file is built for an earlier release, since
+// the recommended alternative may not yet be available to it.
+//
+// When the deprecation note names a replacement (as in "Deprecated:
+// Use [T.Close] instead.") that is a field, method, or package-level
+// declaration of the same kind and in the same type or package, the
+// analyzer offers a fix to use it instead.
+//
 // See https://go.dev/wiki/Deprecated to learn about Go's convention
 // for documenting and signaling deprecated identifiers.
 package deprecated
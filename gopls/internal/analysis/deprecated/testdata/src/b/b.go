@@ -0,0 +1,45 @@
+package b
+
+// Deprecated: Use NewThing instead.
+func OldThing() {} // want OldThing:`Deprecated: Use NewThing instead.`
+
+func NewThing() {}
+
+// Deprecated: Use [b.Current] instead.
+var Legacy int // want Legacy:`Deprecated: Use \[b.Current\] instead.`
+
+var Current int
+
+// Deprecated: Use Missing instead.
+func Gone() {} // want Gone:`Deprecated: Use Missing instead.`
+
+// Deprecated: Use Create instead.
+func Make(name string) {} // want Make:`Deprecated: Use Create instead.`
+
+func Create(name string, mode int) {}
+
+// Deprecated: Use Count instead.
+var Total int // want Total:`Deprecated: Use Count instead.`
+
+var Count int64
+
+// Deprecated: Use NewThing instead.
+type Thing struct{} // want Thing:`Deprecated: Use NewThing instead.`
+
+type T struct {
+	// Deprecated: Use [T.Size] instead.
+	Len  int // want Len:`Deprecated: Use \[T.Size\] instead.`
+	Size int
+}
+
+// Deprecated: Use [T.Close] instead.
+func (T) Shutdown() {} // want Shutdown:`Deprecated: Use \[T.Close\] instead.`
+
+func (T) Close() {}
+
+// Deprecated: Use [U.Close] instead.
+func (T) Stop() {} // want Stop:`Deprecated: Use \[U.Close\] instead.`
+
+type U struct{}
+
+func (U) Close() {}
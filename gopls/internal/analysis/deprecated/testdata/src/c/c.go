@@ -0,0 +1,16 @@
+package c
+
+import "b"
+
+func _() {
+	b.OldThing()        // want `b.OldThing is deprecated: Use NewThing instead.`
+	_ = b.Legacy        // want `b.Legacy is deprecated: Use \[b.Current\] instead.`
+	b.Make("")          // want `b.Make is deprecated: Use Create instead.`
+	_ = b.Total         // want `b.Total is deprecated: Use Count instead.`
+	b.Gone()            // want `b.Gone is deprecated: Use Missing instead.`
+	_ = b.Thing{}       // want `b.Thing is deprecated: Use NewThing instead.`
+	_ = b.T{}.Len       // want `b.T{}.Len is deprecated: Use \[T.Size\] instead.`
+	b.T{}.Shutdown()    // want `b.T{}.Shutdown is deprecated: Use \[T.Close\] instead.`
+	(&b.T{}).Shutdown() // want `\(&b.T{}\).Shutdown is deprecated: Use \[T.Close\] instead.`
+	b.T{}.Stop()        // want `b.T{}.Stop is deprecated: Use \[U.Close\] instead.`
+}
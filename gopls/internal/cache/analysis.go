@@ -166,6 +166,17 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 	}
 	facty = requiredAnalyzers(facty)
 
+	// Decode the settings of each analyzer.
+	configs := make(map[*analysis.Analyzer]any)
+	for _, a := range enabledAnalyzers {
+		config, err := settings.AnalyzerConfig(a, s.Options().AnalysisConfig)
+		if err != nil {
+			// The option was validated, so this should not happen.
+			return nil, bug.Errorf("invalid analysisConfig for %s: %v", a, err)
+		}
+		configs[a] = config
+	}
+
 	batch, release := s.acquireTypeChecking()
 	defer release()
 
@@ -203,6 +214,7 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 				ph:          ph,
 				analyzers:   facty, // all nodes run at least the facty analyzers
 				stableNames: stableNames,
+				configs:     configs,
 			}
 			nodes[id] = an
 
@@ -471,6 +483,7 @@ type analysisNode struct {
 	unfinishedPreds atomic.Int32                  // effectively a summary.Actions refcount
 	summary         *analyzeSummary               // serializable result of analyzing this package
 	stableNames     map[*analysis.Analyzer]string // cross-process stable names for Analyzers
+	configs         map[*analysis.Analyzer]any    // value of Pass.Config for each analyzer
 
 	summaryHashOnce sync.Once
 	_summaryHash    file.Hash // memoized hash of data affecting dependents
@@ -646,7 +659,7 @@ func (an *analysisNode) runCached(ctx context.Context, key file.Hash) (*analyzeS
 
 // analysisCacheKey returns a cache key that is a cryptographic digest
 // of the all the values that might affect type checking and analysis:
-// the analyzer names, package metadata, names and contents of
+// the analyzer names and settings, package metadata, names and contents of
 // compiled Go files, and vdeps (successor) information
 // (export data and facts).
 func (an *analysisNode) cacheKey() file.Hash {
@@ -660,6 +673,13 @@ func (an *analysisNode) cacheKey() file.Hash {
 	fmt.Fprintf(hasher, "analyzers: %d\n", len(an.analyzers))
 	for _, a := range an.analyzers {
 		fmt.Fprintln(hasher, a.Name)
+		if config := an.configs[a]; config != nil {
+			data, err := json.Marshal(config)
+			if err != nil {
+				panic(err) // decoded from JSON, so cannot fail
+			}
+			fmt.Fprintf(hasher, "config: %s\n", data)
+		}
 	}
 
 	// type checked package
@@ -713,6 +733,7 @@ func (an *analysisNode) run(ctx context.Context) (*analyzeSummary, error) {
 				a:          a,
 				fsource:    an.fsource,
 				stableName: an.stableNames[a],
+				config:     an.configs[a],
 				pkg:        pkg,
 				vdeps:      an.succs,
 				hdeps:      hdeps,
@@ -888,6 +909,7 @@ type action struct {
 	a          *analysis.Analyzer
 	fsource    file.Source // Snapshot.ReadFile, for Pass.ReadFile
 	stableName string      // cross-process stable name of analyzer
+	config     any         // value of Pass.Config
 	pkg        *analysisPackage
 	hdeps      []*action                   // horizontal dependencies
 	vdeps      map[PackageID]*analysisNode // vertical dependencies
@@ -1136,6 +1158,7 @@ func (act *action) exec(ctx context.Context) (any, *actionSummary, error) {
 		TypesInfo:    apkg.pkg.TypesInfo(),
 		TypesSizes:   apkg.pkg.TypesSizes(),
 		TypeErrors:   apkg.typeErrors,
+		Config:       act.config,
 		ResultOf:     inputs,
 		Report: func(d analysis.Diagnostic) {
 			diagnostic, err := toGobDiagnostic(posToLocation, analyzer, d)
@@ -26,6 +26,7 @@ import (
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/gopls/internal/bloom"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/methodsets"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/cache/typerefs"
 	"golang.org/x/tools/gopls/internal/file"
@@ -33,6 +34,7 @@ import (
 	"golang.org/x/tools/gopls/internal/label"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/gopls/internal/util/bug"
+	"golang.org/x/tools/gopls/internal/util/moremaps"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
 	"golang.org/x/tools/internal/analysisinternal"
 	"golang.org/x/tools/internal/event"
@@ -69,10 +71,27 @@ type typeCheckBatch struct {
 
 	parseCache       *parseCache
 	fset             *token.FileSet                          // describes all parsed or imported files
-	cpulimit         chan unit                               // concurrency limiter for CPU-bound operations
+	scheduler        *scheduler                              // concurrency limiter for CPU-bound operations
 	syntaxPackages   *futureCache[PackageID, *Package]       // transient cache of in-progress syntax futures
 	importPackages   *futureCache[PackageID, *types.Package] // persistent cache of imports
 	gopackagesdriver bool                                    // for bug reporting: were packages loaded with a driver?
+
+	// openMu guards _dependsOnOpen, which memoizes dependsOnOpen.
+	openMu         sync.Mutex
+	_dependsOnOpen map[PackageID]bool
+
+	// ticketMu guards _tickets, which holds the scheduler tickets of
+	// the syntax packages being awaited, so that callers of getPackage
+	// may raise the priority of a computation that they join.
+	ticketMu sync.Mutex
+	_tickets map[PackageID]*pkgTicket
+}
+
+// A pkgTicket is the scheduler ticket of a syntax package computation,
+// shared by the goroutines awaiting it.
+type pkgTicket struct {
+	t    *ticket
+	refs int // number of goroutines awaiting the package; guarded by ticketMu
 }
 
 // addHandles is called by each goroutine joining the type check batch, to
@@ -239,7 +258,7 @@ func (s *Snapshot) acquireTypeChecking() (*typeCheckBatch, func()) {
 
 	if s.batch == nil {
 		assert(s.batchRef == 0, "miscounted type checking")
-		s.batch = newTypeCheckBatch(s.view.parseCache, s.view.typ == GoPackagesDriverView)
+		s.batch = newTypeCheckBatch(s.view.parseCache, s.view.scheduler, s.view.typ == GoPackagesDriverView)
 	}
 	s.batchRef++
 
@@ -255,15 +274,17 @@ func (s *Snapshot) acquireTypeChecking() (*typeCheckBatch, func()) {
 }
 
 // newTypeCheckBatch creates a new type checking batch using the provided
-// shared parseCache.
+// shared parseCache and scheduler.
 //
 // If a non-nil importGraph is provided, imports in this graph will be reused.
-func newTypeCheckBatch(parseCache *parseCache, gopackagesdriver bool) *typeCheckBatch {
+func newTypeCheckBatch(parseCache *parseCache, scheduler *scheduler, gopackagesdriver bool) *typeCheckBatch {
 	return &typeCheckBatch{
 		_handles:         make(map[PackageID]*packageHandle),
+		_dependsOnOpen:   make(map[PackageID]bool),
+		_tickets:         make(map[PackageID]*pkgTicket),
 		parseCache:       parseCache,
 		fset:             fileSetWithBase(reservedForParsing),
-		cpulimit:         make(chan unit, runtime.GOMAXPROCS(0)),
+		scheduler:        scheduler,
 		syntaxPackages:   newFutureCache[PackageID, *Package](false),      // don't persist syntax packages
 		importPackages:   newFutureCache[PackageID, *types.Package](true), // ...but DO persist imports
 		gopackagesdriver: gopackagesdriver,
@@ -368,6 +389,13 @@ func (b *typeCheckBatch) handleSyntaxPackage(ctx context.Context, i int, id Pack
 
 // getPackage type checks one [Package] in the batch.
 func (b *typeCheckBatch) getPackage(ctx context.Context, ph *packageHandle) (*Package, error) {
+	prio := highPriority
+	if isLowPriority(ctx) && !b.dependsOnOpen(ph.mp.ID) {
+		prio = lowPriority
+	}
+	tkt, leave := b.joinTicket(ph.mp.ID, prio)
+	defer leave()
+
 	return b.syntaxPackages.get(ctx, ph.mp.ID, func(ctx context.Context) (*Package, error) {
 		// Wait for predecessors.
 		// Record imports of this package to avoid redundant work in typesConfig.
@@ -407,14 +435,11 @@ func (b *typeCheckBatch) getPackage(ctx context.Context, ph *packageHandle) (*Pa
 		//
 		// Note: it is important to acquire this token only after awaiting
 		// predecessors, to avoid starvation.
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case b.cpulimit <- unit{}:
-			defer func() {
-				<-b.cpulimit // release CPU token
-			}()
+		release, err := b.scheduler.acquire(ctx, tkt)
+		if err != nil {
+			return nil, err // cancelled
 		}
+		defer release() // release CPU token
 
 		// Compute the syntax package.
 		p, err := b.checkPackage(ctx, fset, ph, imports)
@@ -428,12 +453,80 @@ func (b *typeCheckBatch) getPackage(ctx context.Context, ph *packageHandle) (*Pa
 	})
 }
 
+// joinTicket returns the ticket of the computation of the syntax
+// package id, which is shared by all the goroutines awaiting it, and a
+// function to call when the caller no longer awaits it. The ticket has
+// the priority of the first caller; but if a later caller is of high
+// priority, the ticket is boosted, so that a low-priority computation
+// that the caller joins does not delay it.
+func (b *typeCheckBatch) joinTicket(id PackageID, p priority) (*ticket, func()) {
+	b.ticketMu.Lock()
+	pt, ok := b._tickets[id]
+	if !ok {
+		pt = &pkgTicket{t: newTicket(p)}
+		b._tickets[id] = pt
+	}
+	pt.refs++
+	b.ticketMu.Unlock()
+
+	if ok && p == highPriority {
+		b.scheduler.boost(pt.t)
+	}
+	return pt.t, func() {
+		b.ticketMu.Lock()
+		defer b.ticketMu.Unlock()
+		pt.refs--
+		if pt.refs == 0 {
+			delete(b._tickets, id)
+		}
+	}
+}
+
+// dependsOnOpen reports whether the package has open files, or depends
+// on a package that does: such packages are type checked with high
+// priority even by low-priority operations, as their diagnostics are
+// most likely to be of interest to the user.
+//
+// The handles of all dependencies of the package must be in the batch.
+func (b *typeCheckBatch) dependsOnOpen(id PackageID) bool {
+	b.openMu.Lock()
+	res, ok := b._dependsOnOpen[id]
+	b.openMu.Unlock()
+	if ok {
+		return res
+	}
+
+	// There are no cycles, so this recursion terminates;
+	// concurrent calls may duplicate work, but agree on the result.
+	ph := b.getHandle(id)
+	res = ph.isOpen
+	for _, depID := range ph.mp.DepsByPkgPath {
+		if res {
+			break
+		}
+		res = b.dependsOnOpen(depID)
+	}
+
+	b.openMu.Lock()
+	b._dependsOnOpen[id] = res
+	b.openMu.Unlock()
+	return res
+}
+
 // storePackageResults serializes and writes information derived from p to the
 // file cache.
 // The context is used only for logging; cancellation does not affect the operation.
 func storePackageResults(ctx context.Context, ph *packageHandle, p *Package) {
+	// The xrefs index of each file is stored by the xrefs method;
+	// the package entry records only their keys.
+	p.pkg.xrefs(ctx)
+	var xrefsKeys []byte
+	for _, key := range p.pkg.xrefsKeys {
+		xrefsKeys = append(xrefsKeys, key[:]...)
+	}
+
 	toCache := map[string][]byte{
-		xrefsKind:       p.pkg.xrefs(),
+		xrefsKind:       xrefsKeys,
 		methodSetsKind:  p.pkg.methodsets().Encode(),
 		testsKind:       p.pkg.tests().Encode(),
 		diagnosticsKind: encodeDiagnostics(p.pkg.diagnostics),
@@ -550,7 +643,9 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 
 	// Parse the compiled go files, bypassing the parse cache as packages checked
 	// for import are unlikely to get cache hits. Additionally, we can optimize
-	// parsing slightly by not passing parser.ParseComments.
+	// parsing slightly by not passing parser.ParseComments, and substantially
+	// by skipping function bodies, which are not type checked in any case.
+	// Positions are unaffected, so they are correct in the export data.
 	pgfs := make([]*parsego.File, len(ph.localInputs.compiledGoFiles))
 	{
 		var group errgroup.Group
@@ -565,7 +660,7 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		for i, fh := range ph.localInputs.compiledGoFiles {
 			i, fh := i, fh
 			group.Go(func() error {
-				pgf, err := parseGoImpl(ctx, b.fset, fh, parser.SkipObjectResolution, false)
+				pgf, err := parseGoImpl(ctx, b.fset, fh, parser.SkipObjectResolution|parsego.SkipFuncBodies)
 				pgfs[i] = pgf
 				return err
 			})
@@ -596,7 +691,14 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		return nil, ctx.Err()
 	}
 
-	// Asynchronously record export data.
+	// Asynchronously record export data, and the method-set index.
+	//
+	// Saving the index is merely a warm-up of the cache: without it,
+	// an "implementation" query, which consults the indexes of all
+	// dependencies, would compute the same index by type-checking
+	// the package again. (The index records only package-level types,
+	// which are unaffected by IgnoreFuncBodies, so it is the same as
+	// the one computed after a full type check.)
 	go func() {
 		exportData, err := gcimporter.IExportShallow(b.fset, pkg, bug.Reportf)
 		if err != nil {
@@ -606,6 +708,9 @@ func (b *typeCheckBatch) checkPackageForImport(ctx context.Context, ph *packageH
 		if err := filecache.Set(exportDataKind, ph.key, exportData); err != nil {
 			event.Error(ctx, fmt.Sprintf("storing export data for %s", ph.mp.ID), err)
 		}
+		if err := filecache.Set(methodSetsKind, ph.key, methodsets.NewIndex(b.fset, pkg).Encode()); err != nil {
+			event.Error(ctx, fmt.Sprintf("storing method sets for %s", ph.mp.ID), err)
+		}
 	}()
 	return pkg, nil
 }
@@ -1291,7 +1396,7 @@ func (s *Snapshot) typerefData(ctx context.Context, id PackageID, imports map[Im
 		bug.Reportf("internal error reading typerefs data: %v", err)
 	}
 
-	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Full&^parser.ParseComments, true, cgfs...)
+	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Signatures, cgfs...)
 	if err != nil {
 		return nil, err
 	}
@@ -1464,6 +1569,55 @@ func localPackageKey(inputs *typeCheckInputs) file.Hash {
 	return hash
 }
 
+// xrefsFileKeys returns the file cache key of the xrefs index of each
+// of the given compiled Go files of the package.
+//
+// The references from a file depend not only on its content but on
+// the resolution of its identifiers, which depends on the declarations
+// of the whole package and on its dependencies. It does not depend on
+// the bodies of functions declared in other files, so each key combines
+// the content of the file with a hash of the package's files stripped
+// of their function bodies, and of the keys of its dependencies. Thus
+// an edit within a function body invalidates the index of one file.
+func xrefsFileKeys(ph *packageHandle, pgfs []*parsego.File) []file.Hash {
+	inputs := ph.localInputs
+	hasher := sha256.New()
+
+	fmt.Fprintf(hasher, "xrefs: %s %s %s\n", inputs.id, inputs.name, inputs.pkgPath)
+	fmt.Fprintf(hasher, "go %s\n", inputs.goVersion)
+	for impPath, id := range moremaps.Sorted(inputs.depsByImpPath) {
+		fmt.Fprintf(hasher, "import %s %s\n", impPath, id)
+	}
+	for id, key := range moremaps.Sorted(ph.depKeys) {
+		fmt.Fprintf(hasher, "dep %s %s\n", id, key)
+	}
+
+	// declarations
+	fmt.Fprintf(hasher, "compiledGoFiles: %d\n", len(pgfs))
+	for _, pgf := range pgfs {
+		last := 0
+		for _, decl := range pgf.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+				// If the body has no valid extent, conservatively retain it.
+				if start, end, err := pgf.NodeOffsets(decl.Body); err == nil && last <= start {
+					fmt.Fprintf(hasher, "%d\n%s\n", start-last, pgf.Src[last:start])
+					last = end
+				}
+			}
+		}
+		fmt.Fprintf(hasher, "%d\n%s\n", len(pgf.Src)-last, pgf.Src[last:])
+	}
+
+	var base [sha256.Size]byte
+	hasher.Sum(base[:0])
+
+	keys := make([]file.Hash, len(pgfs))
+	for i, fh := range inputs.compiledGoFiles {
+		keys[i] = file.HashOf(fmt.Appendf(nil, "%x %s", base, fh.Identity().Hash))
+	}
+	return keys
+}
+
 // checkPackage type checks the parsed source files in compiledGoFiles.
 // (The resulting pkg also holds the parsed but not type-checked goFiles.)
 // deps holds the future results of type-checking the direct dependencies.
@@ -1492,11 +1646,11 @@ func (b *typeCheckBatch) checkPackage(ctx context.Context, fset *token.FileSet,
 	// Collect parsed files from the type check pass, capturing parse errors from
 	// compiled files.
 	var err error
-	pkg.goFiles, err = b.parseCache.parseFiles(ctx, pkg.fset, parsego.Full, false, inputs.goFiles...)
+	pkg.goFiles, err = b.parseCache.parseFiles(ctx, pkg.fset, parsego.Full, inputs.goFiles...)
 	if err != nil {
 		return nil, err
 	}
-	pkg.compiledGoFiles, err = b.parseCache.parseFiles(ctx, pkg.fset, parsego.Full, false, inputs.compiledGoFiles...)
+	pkg.compiledGoFiles, err = b.parseCache.parseFiles(ctx, pkg.fset, parsego.Full, inputs.compiledGoFiles...)
 	if err != nil {
 		return nil, err
 	}
@@ -1505,6 +1659,7 @@ func (b *typeCheckBatch) checkPackage(ctx context.Context, fset *token.FileSet,
 			pkg.parseErrors = append(pkg.parseErrors, pgf.ParseErr)
 		}
 	}
+	pkg.xrefsKeys = xrefsFileKeys(ph, pkg.compiledGoFiles)
 
 	// Use the default type information for the unsafe package.
 	if inputs.pkgPath == "unsafe" {
@@ -110,6 +110,7 @@ const (
 	TemplateError            DiagnosticSource = "template"
 	WorkFileError            DiagnosticSource = "go.work file"
 	ConsistencyInfo          DiagnosticSource = "consistency"
+	UnusedSymbol             DiagnosticSource = "unused symbol"
 )
 
 // A SuggestedFix represents a suggested fix (for a diagnostic)
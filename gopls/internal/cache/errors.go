@@ -489,7 +489,7 @@ func parseGoURI(ctx context.Context, fs file.Source, uri protocol.DocumentURI, m
 	if err != nil {
 		return nil, err
 	}
-	return parseGoImpl(ctx, token.NewFileSet(), fh, mode, false)
+	return parseGoImpl(ctx, token.NewFileSet(), fh, mode)
 }
 
 // parseModURI is a helper to parse the Mod file at the given URI from the file
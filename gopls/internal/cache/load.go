@@ -97,6 +97,9 @@ func (s *Snapshot) load(ctx context.Context, allowNetwork AllowNetwork, scopes .
 			query = append(query, modQuery)
 			moduleQueries[modQuery] = scope.modulePath
 
+		case directoryLoadScope:
+			query = append(query, fmt.Sprintf("%s%c...", string(scope), filepath.Separator))
+
 		case viewLoadScope:
 			// If we are outside of GOPATH, a module, or some other known
 			// build system, don't load subdirectories.
@@ -258,6 +261,9 @@ func (s *Snapshot) load(ctx context.Context, allowNetwork AllowNetwork, scopes .
 		}
 		buildMetadata(newMetadata, cfg.Dir, standalone, pkg)
 	}
+	for _, mp := range newMetadata {
+		mp.LoadQuery = query
+	}
 
 	s.mu.Lock()
 
@@ -694,6 +700,22 @@ func isWorkspacePackageLocked(ctx context.Context, s *Snapshot, meta *metadata.G
 		return false // no non-filtered files
 	}
 
+	// With loadOnlyOpenDirectories, workspace packages must be contained in
+	// the directory of an open file, or beneath it.
+	if s.loadOnlyOpenDirectories() {
+		dirs := openDirsLocked(s)
+		inOpenDir := false
+		for uri := range uris {
+			if slices.ContainsFunc(dirs, func(dir string) bool { return pathutil.InDir(dir, uri.Path()) }) {
+				inOpenDir = true
+				break
+			}
+		}
+		if !inOpenDir {
+			return false
+		}
+	}
+
 	// For non-module views (of type GOPATH or AdHoc), or if
 	// expandWorkspaceToModule is unset, workspace packages must be contained in
 	// the workspace folder.
@@ -742,6 +764,65 @@ func isWorkspacePackageLocked(ctx context.Context, s *Snapshot, meta *metadata.G
 	return true // an ad-hoc package or GOPATH package
 }
 
+// loadOnlyOpenDirectories reports whether the workspace of the snapshot
+// consists of the directories of open files (see
+// [settings.BuildOptions.LoadOnlyOpenDirectories]), rather than all
+// packages in its modules or view.
+func (s *Snapshot) loadOnlyOpenDirectories() bool {
+	return s.view.typ != AdHocView && s.Options().LoadOnlyOpenDirectories
+}
+
+// openDirsLocked returns the directories of the open Go files of the
+// snapshot that are within its view's root directory, and not filtered
+// or vendored, in lexical order. Directories within another directory
+// of the result are omitted, as loading the latter loads them too.
+//
+// s.mu must be held while calling this function.
+func openDirsLocked(s *Snapshot) []string {
+	root := s.view.root.Path()
+	filterFunc := s.view.filterFunc()
+	var dirs []string
+	for _, o := range s.files.getOverlays() {
+		uri := o.URI()
+		if !strings.HasSuffix(uri.Path(), ".go") ||
+			strings.Contains(string(uri), "/vendor/") ||
+			filterFunc(uri) ||
+			!pathutil.InDir(root, uri.Path()) {
+			continue
+		}
+		dirs = append(dirs, uri.DirPath())
+	}
+	sort.Strings(dirs)
+	dirs = slices.Compact(dirs)
+	// A directory sorts before its subdirectories,
+	// though not necessarily immediately.
+	var outer []string
+	for _, dir := range dirs {
+		if !slices.ContainsFunc(outer, func(o string) bool { return pathutil.InDir(o, dir) }) {
+			outer = append(outer, dir)
+		}
+	}
+	return outer
+}
+
+// unloadedOpenDirsLocked returns the directories of [openDirsLocked]
+// whose package trees have not yet been loaded.
+//
+// s.mu must be held while calling this function.
+func unloadedOpenDirsLocked(s *Snapshot) []string {
+	var unloaded []string
+	for _, dir := range openDirsLocked(s) {
+		loaded := false
+		s.loadedDirs.Range(func(l string) {
+			loaded = loaded || pathutil.InDir(l, dir)
+		})
+		if !loaded {
+			unloaded = append(unloaded, dir)
+		}
+	}
+	return unloaded
+}
+
 // containsOpenFileLocked reports whether any file referenced by m is open in
 // the snapshot s.
 //
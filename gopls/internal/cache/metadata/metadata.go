@@ -58,8 +58,9 @@ type Package struct {
 	DepsByPkgPath map[PackagePath]PackageID // values are unique and non-empty
 	Module        *packages.Module
 	DepsErrors    []*packagesinternal.PackageError
-	LoadDir       string // directory from which go/packages was run
-	Standalone    bool   // package synthesized for a standalone file (e.g. ignore-tagged)
+	LoadDir       string   // directory from which go/packages was run
+	LoadQuery     []string // patterns of the go/packages query that loaded the package
+	Standalone    bool     // package synthesized for a standalone file (e.g. ignore-tagged)
 }
 
 func (mp *Package) String() string { return string(mp.ID) }
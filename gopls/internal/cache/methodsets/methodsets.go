@@ -113,6 +113,7 @@ type Result struct {
 	// methods only:
 	PkgPath    string          // path of declaring package (may differ due to embedding)
 	ObjectPath objectpath.Path // path of method within declaring package
+	Key        Key             // method set of the type that has the method, for a further search
 }
 
 // Search reports each type that implements (or is implemented by) the
@@ -159,10 +160,13 @@ func (index *Index) Search(key Key, methodID string) []Result {
 						continue
 					}
 
+					key := Key{candidate}
+					key.mset.Posn = gobPosition{}
 					results = append(results, Result{
 						Location:   index.location(m.Posn),
 						PkgPath:    index.pkg.Strings[m.PkgPath],
 						ObjectPath: objectpath.Path(index.pkg.Strings[m.ObjectPath]),
+						Key:        key,
 					})
 					break
 				}
@@ -482,7 +482,7 @@ func missingModuleForImport(pgf *parsego.File, imp *ast.ImportSpec, req *modfile
 //
 // TODO(rfindley): this should key off ImportPath.
 func parseImports(ctx context.Context, s *Snapshot, files []file.Handle) (map[string]bool, error) {
-	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Header, false, files...)
+	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Header, files...)
 	if err != nil { // e.g. context cancellation
 		return nil, err
 	}
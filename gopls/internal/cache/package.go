@@ -5,6 +5,7 @@
 package cache
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
 	"go/scanner"
@@ -18,7 +19,10 @@ import (
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/cache/testfuncs"
 	"golang.org/x/tools/gopls/internal/cache/xrefs"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/filecache"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/event"
 )
 
 // Convenient aliases for very heavily used types.
@@ -57,8 +61,9 @@ type syntaxPackage struct {
 	typesSizes      types.Sizes
 	importMap       map[PackagePath]*types.Package
 
+	xrefsKeys []file.Hash // file cache key of each compiled Go file's xrefs index; see xrefsFileKeys
 	xrefsOnce sync.Once
-	_xrefs    []byte // only used by the xrefs method
+	_xrefs    [][]byte // only used by the xrefs method
 
 	methodsetsOnce sync.Once
 	_methodsets    *methodsets.Index // only used by the methodsets method
@@ -67,9 +72,29 @@ type syntaxPackage struct {
 	_tests    *testfuncs.Index // only used by the tests method
 }
 
-func (p *syntaxPackage) xrefs() []byte {
+// xrefs returns the cross-reference index of each compiled Go file of
+// the package. The index of a file is computed only if the file cache
+// does not already hold it, typically because the file, or the
+// declarations of the package, changed since it was last indexed.
+//
+// The context is used only for logging.
+func (p *syntaxPackage) xrefs(ctx context.Context) [][]byte {
 	p.xrefsOnce.Do(func() {
-		p._xrefs = xrefs.Index(p.compiledGoFiles, p.types, p.typesInfo)
+		p._xrefs = make([][]byte, len(p.compiledGoFiles))
+		for i, pgf := range p.compiledGoFiles {
+			key := p.xrefsKeys[i]
+			data, err := filecache.Get(xrefsFileKind, key)
+			if err != nil {
+				if err != filecache.ErrNotFound {
+					event.Error(ctx, "reading xrefs from filecache", err)
+				}
+				data = xrefs.IndexFile(pgf, p.types, p.typesInfo)
+				if err := filecache.Set(xrefsFileKind, key, data); err != nil {
+					event.Error(ctx, fmt.Sprintf("storing xrefs data for %s", pgf.URI), err)
+				}
+			}
+			p._xrefs[i] = data
+		}
 	})
 	return p._xrefs
 }
@@ -100,6 +125,11 @@ func (p *Package) String() string { return string(p.metadata.ID) }
 
 func (p *Package) Metadata() *metadata.Package { return p.metadata }
 
+// LoadDiagnostics returns the diagnostics that arose in loading the
+// metadata of the package, such as go list errors, including those
+// for its dependencies.
+func (p *Package) LoadDiagnostics() []*Diagnostic { return p.loadDiagnostics }
+
 // A loadScope defines a package loading scope for use with go/packages.
 //
 // TODO(rfindley): move this to load.go.
@@ -115,14 +145,16 @@ type (
 		dir        string // dir containing the go.mod file
 		modulePath string // parsed module path
 	}
-	viewLoadScope struct{} // load the workspace
+	viewLoadScope      struct{} // load the workspace
+	directoryLoadScope string   // load packages in a directory tree (the value is its path)
 )
 
 // Implement the loadScope interface.
-func (fileLoadScope) aScope()    {}
-func (packageLoadScope) aScope() {}
-func (moduleLoadScope) aScope()  {}
-func (viewLoadScope) aScope()    {}
+func (fileLoadScope) aScope()      {}
+func (packageLoadScope) aScope()   {}
+func (moduleLoadScope) aScope()    {}
+func (viewLoadScope) aScope()      {}
+func (directoryLoadScope) aScope() {}
 
 func (p *Package) CompiledGoFiles() []*parsego.File {
 	return p.pkg.compiledGoFiles
@@ -127,9 +127,8 @@ func (c *parseCache) stop() {
 
 // parseKey uniquely identifies a parsed Go file.
 type parseKey struct {
-	uri             protocol.DocumentURI
-	mode            parser.Mode
-	purgeFuncBodies bool
+	uri  protocol.DocumentURI
+	mode parser.Mode
 }
 
 type parseCacheEntry struct {
@@ -147,7 +146,7 @@ type parseCacheEntry struct {
 // The resulting slice has an entry for every given file handle, though some
 // entries may be nil if there was an error reading the file (in which case the
 // resulting error will be non-nil).
-func (c *parseCache) startParse(mode parser.Mode, purgeFuncBodies bool, fhs ...file.Handle) ([]*memoize.Promise, error) {
+func (c *parseCache) startParse(mode parser.Mode, fhs ...file.Handle) ([]*memoize.Promise, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -175,9 +174,8 @@ func (c *parseCache) startParse(mode parser.Mode, purgeFuncBodies bool, fhs ...f
 		data[i] = content
 
 		key := parseKey{
-			uri:             fh.URI(),
-			mode:            mode,
-			purgeFuncBodies: purgeFuncBodies,
+			uri:  fh.URI(),
+			mode: mode,
 		}
 
 		if e, ok := c.m[key]; ok {
@@ -200,7 +198,7 @@ func (c *parseCache) startParse(mode parser.Mode, purgeFuncBodies bool, fhs ...f
 			// inside of parseGoSrc without exceeding the allocated space.
 			base, nextBase := c.allocateSpace(2*len(content) + parsePadding)
 
-			pgf, fixes1 := parsego.Parse(ctx, fileSetWithBase(base), uri, content, mode, purgeFuncBodies)
+			pgf, fixes1 := parsego.Parse(ctx, fileSetWithBase(base), uri, content, mode)
 			file := pgf.Tok
 			if file.Base()+file.Size()+1 > nextBase {
 				// The parsed file exceeds its allocated space, likely due to multiple
@@ -212,7 +210,7 @@ func (c *parseCache) startParse(mode parser.Mode, purgeFuncBodies bool, fhs ...f
 				// there, as parseGoSrc will repeat them.
 				actual := file.Base() + file.Size() - base // actual size consumed, after re-parsing
 				base2, nextBase2 := c.allocateSpace(actual)
-				pgf2, fixes2 := parsego.Parse(ctx, fileSetWithBase(base2), uri, content, mode, purgeFuncBodies)
+				pgf2, fixes2 := parsego.Parse(ctx, fileSetWithBase(base2), uri, content, mode)
 
 				// In golang/go#59097 we observed that this panic condition was hit.
 				// One bug was found and fixed, but record more information here in
@@ -317,7 +315,7 @@ func (c *parseCache) allocateSpace(size int) (int, int) {
 //
 // If parseFiles returns an error, it still returns a slice,
 // but with a nil entry for each file that could not be parsed.
-func (c *parseCache) parseFiles(ctx context.Context, fset *token.FileSet, mode parser.Mode, purgeFuncBodies bool, fhs ...file.Handle) ([]*parsego.File, error) {
+func (c *parseCache) parseFiles(ctx context.Context, fset *token.FileSet, mode parser.Mode, fhs ...file.Handle) ([]*parsego.File, error) {
 	pgfs := make([]*parsego.File, len(fhs))
 
 	// Temporary fall-back for 32-bit systems, where reservedForParsing is too
@@ -327,7 +325,7 @@ func (c *parseCache) parseFiles(ctx context.Context, fset *token.FileSet, mode p
 	if bits.UintSize == 32 {
 		for i, fh := range fhs {
 			var err error
-			pgfs[i], err = parseGoImpl(ctx, fset, fh, mode, purgeFuncBodies)
+			pgfs[i], err = parseGoImpl(ctx, fset, fh, mode)
 			if err != nil {
 				return pgfs, err
 			}
@@ -335,7 +333,7 @@ func (c *parseCache) parseFiles(ctx context.Context, fset *token.FileSet, mode p
 		return pgfs, nil
 	}
 
-	promises, firstErr := c.startParse(mode, purgeFuncBodies, fhs...)
+	promises, firstErr := c.startParse(mode, fhs...)
 
 	// Await all parsing.
 	var g errgroup.Group
@@ -41,19 +41,30 @@ const (
 	// This is used for files of direct interest where the entire contents must
 	// be considered.
 	Full = parser.AllErrors | parser.ParseComments | parser.SkipObjectResolution
+
+	// Signatures specifies that only the declarations are needed, with the
+	// signatures of functions but not their bodies. This is the mode used
+	// when a package's types, but not its syntax, are of interest.
+	Signatures = Full&^parser.ParseComments | SkipFuncBodies
 )
 
+// SkipFuncBodies is a parse mode flag, in addition to those of go/parser,
+// that causes the statements of function bodies to be discarded before
+// parsing, as if the bodies were empty. Positions are unaffected, but the
+// Src of the resulting File is not the content of the file.
+const SkipFuncBodies parser.Mode = 1 << 30
+
 // Parse parses a buffer of Go source, repairing the tree if necessary.
 //
 // The provided ctx is used only for logging.
-func Parse(ctx context.Context, fset *token.FileSet, uri protocol.DocumentURI, src []byte, mode parser.Mode, purgeFuncBodies bool) (res *File, fixes []fixType) {
-	if purgeFuncBodies {
-		src = astutil.PurgeFuncBodies(src)
+func Parse(ctx context.Context, fset *token.FileSet, uri protocol.DocumentURI, src []byte, mode parser.Mode) (res *File, fixes []fixType) {
+	if mode&SkipFuncBodies != 0 {
+		src = astutil.BlankFuncBodies(src)
 	}
 	ctx, done := event.Start(ctx, "cache.ParseGoSrc", label.File.Of(uri.Path()))
 	defer done()
 
-	file, err := parser.ParseFile(fset, uri.Path(), src, mode)
+	file, err := parser.ParseFile(fset, uri.Path(), src, mode&^SkipFuncBodies)
 	var parseErr scanner.ErrorList
 	if err != nil {
 		// We passed a byte slice, so the only possible error is a parse error.
@@ -115,7 +126,7 @@ func Parse(ctx context.Context, fset *token.FileSet, uri protocol.DocumentURI, s
 				event.Log(ctx, fmt.Sprintf("fixSrc loop - last diff:\n%v", unified), label.File.Of(tok.Name()))
 			}
 
-			newFile, newErr := parser.ParseFile(fset, uri.Path(), newSrc, mode)
+			newFile, newErr := parser.ParseFile(fset, uri.Path(), newSrc, mode&^SkipFuncBodies)
 			assert(newFile != nil, "ParseFile returned nil") // I/O error can't happen
 
 			// Maintain the original parseError so we don't try formatting the
@@ -32,7 +32,7 @@ func _() {
 }
 `
 
-	pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), "file://foo.go", []byte(src), parsego.Full, false)
+	pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), "file://foo.go", []byte(src), parsego.Full)
 	fset := tokeninternal.FileSetFor(pgf.Tok)
 	ast.Inspect(pgf.File, func(n ast.Node) bool {
 		if n != nil {
@@ -44,3 +44,44 @@ func _() {
 		return true
 	})
 }
+
+func TestParseSignatures(t *testing.T) {
+	const src = `package p
+
+var table = [...]int{1, 2, 3}
+
+func F(x int) (int, error) {
+	if x > 0 {
+		return x, nil
+	}
+	return 0, nil
+}
+
+type T struct{ f func() }
+
+func (T) M() { println("hello") }
+`
+	pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), "file://p.go", []byte(src), parsego.Signatures)
+	if pgf.ParseErr != nil {
+		t.Fatal(pgf.ParseErr)
+	}
+	for _, decl := range pgf.File.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if len(decl.Body.List) > 0 {
+				t.Errorf("body of %s has %d statements, want none", decl.Name, len(decl.Body.List))
+			}
+		case *ast.GenDecl:
+			if spec, ok := decl.Specs[0].(*ast.ValueSpec); ok {
+				if lit := spec.Values[0].(*ast.CompositeLit); len(lit.Elts) != 3 {
+					t.Errorf("initializer of table has %d elements, want 3", len(lit.Elts))
+				}
+			}
+		}
+	}
+	// Positions are those of the file.
+	last := pgf.File.Decls[len(pgf.File.Decls)-1].(*ast.FuncDecl)
+	if got, want := safetoken.StartPosition(tokeninternal.FileSetFor(pgf.Tok), last.Name.Pos()).Line, 14; got != want {
+		t.Errorf("M declared on line %d, want %d", got, want)
+	}
+}
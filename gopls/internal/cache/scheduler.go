@@ -0,0 +1,184 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// A priority is the priority of type-checking work; see [scheduler].
+type priority int
+
+const (
+	highPriority priority = iota
+	lowPriority
+)
+
+type lowPriorityKey struct{}
+
+// WithLowPriority returns a context in which type checking is low
+// priority, except for packages that have open files or depend on
+// them. It is intended for background work whose results are not of
+// immediate interest to the user, such as diagnosing the whole
+// workspace, which should not delay the type checking of the files
+// the user is editing.
+func WithLowPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lowPriorityKey{}, true)
+}
+
+// isLowPriority reports whether ctx was returned by [WithLowPriority].
+func isLowPriority(ctx context.Context) bool {
+	return ctx.Value(lowPriorityKey{}) != nil
+}
+
+// A scheduler limits the concurrency of CPU-bound type checking across
+// all the type-checking batches of a session, and grants the tokens
+// that permit it in order of priority.
+//
+// High-priority work is granted a token whenever one is available.
+// Low-priority work is granted one only when no high-priority work is
+// waiting, and holds at most lowLimit tokens at once, so that some
+// capacity always remains for high-priority work that arrives later.
+// Tokens are acquired for each package, so a long-running low-priority
+// operation yields to high-priority work between packages; and when a
+// new edit cancels the operation, its waiting work is abandoned.
+//
+// Work is represented by a ticket, whose priority may be raised while
+// it waits, so that low-priority work on which high-priority work
+// comes to depend does not hold it up.
+type scheduler struct {
+	limit, lowLimit int // maximum number of tokens held by all work, and by low-priority work
+
+	mu      sync.Mutex
+	running [2]int       // number of tokens held, by priority
+	waiting [2][]*ticket // FIFO queues of waiters, by priority
+}
+
+// A ticket represents work that acquires tokens from a scheduler, one
+// at a time. Its fields are guarded by scheduler.mu.
+type ticket struct {
+	p     priority  // priority of the next acquire, or of the waiting one
+	held  priority  // priority at which the token was granted, if held
+	ready chan unit // closed when the token is granted; nil unless waiting
+}
+
+// newTicket returns a ticket for work of the specified priority.
+func newTicket(p priority) *ticket {
+	return &ticket{p: p}
+}
+
+// newScheduler returns a scheduler that grants at most limit tokens at
+// once, of which at most limit-1 (but at least one) to low-priority work.
+func newScheduler(limit int) *scheduler {
+	return &scheduler{
+		limit:    limit,
+		lowLimit: max(1, limit-1),
+	}
+}
+
+// acquire waits for a token for the work of the ticket,
+// and returns a function that releases it.
+// It returns an error if the context is cancelled first.
+func (s *scheduler) acquire(ctx context.Context, t *ticket) (release func(), err error) {
+	release = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.running[t.held]--
+		s.dispatchLocked()
+	}
+
+	s.mu.Lock()
+	if len(s.waiting[highPriority]) == 0 && len(s.waiting[t.p]) == 0 && s.canRunLocked(t.p) {
+		s.grantLocked(t)
+		s.mu.Unlock()
+		return release, nil
+	}
+	ready := make(chan unit)
+	t.ready = ready
+	s.waiting[t.p] = append(s.waiting[t.p], t)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-ready:
+			// The token was granted concurrently; give it back.
+			s.running[t.held]--
+			s.dispatchLocked()
+		default:
+			s.dequeueLocked(t)
+			t.ready = nil
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// boost raises the priority of the ticket to high, moving it to the
+// back of the high-priority queue if it is waiting. It has no effect
+// on a token already held, but applies to those acquired later.
+func (s *scheduler) boost(t *ticket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.p == highPriority {
+		return
+	}
+	if t.ready != nil {
+		s.dequeueLocked(t)
+		s.waiting[highPriority] = append(s.waiting[highPriority], t)
+		t.p = highPriority
+		s.dispatchLocked()
+	} else {
+		t.p = highPriority
+	}
+}
+
+// canRunLocked reports whether a token may be granted to work of
+// priority p, ignoring waiters.
+func (s *scheduler) canRunLocked(p priority) bool {
+	return s.running[highPriority]+s.running[lowPriority] < s.limit &&
+		(p == highPriority || s.running[lowPriority] < s.lowLimit)
+}
+
+// grantLocked grants a token to the work of the ticket.
+func (s *scheduler) grantLocked(t *ticket) {
+	t.held = t.p
+	s.running[t.held]++
+}
+
+// dequeueLocked removes the waiting ticket from its queue.
+func (s *scheduler) dequeueLocked(t *ticket) {
+	i := slices.Index(s.waiting[t.p], t)
+	s.waiting[t.p] = slices.Delete(s.waiting[t.p], i, i+1)
+}
+
+// dispatchLocked grants the available tokens to waiters, in order of
+// priority.
+func (s *scheduler) dispatchLocked() {
+	for {
+		var p priority
+		switch {
+		case len(s.waiting[highPriority]) > 0:
+			p = highPriority
+		case len(s.waiting[lowPriority]) > 0:
+			p = lowPriority
+		default:
+			return // no waiters
+		}
+		if !s.canRunLocked(p) {
+			return
+		}
+		t := s.waiting[p][0]
+		s.waiting[p] = s.waiting[p][1:]
+		s.grantLocked(t)
+		close(t.ready)
+		t.ready = nil
+	}
+}
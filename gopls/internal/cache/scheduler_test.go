@@ -0,0 +1,133 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+	s := newScheduler(2)
+
+	// Low-priority work is limited to one token of two.
+	releaseLow1, err := s.acquire(ctx, newTicket(lowPriority))
+	if err != nil {
+		t.Fatal(err)
+	}
+	low2 := acquireAsync(s, ctx, lowPriority)
+	assertWaiting(t, low2, "second low-priority acquire")
+
+	// ...leaving one for high-priority work.
+	releaseHigh1, err := s.acquire(ctx, newTicket(highPriority))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once all tokens are held, high-priority work waits, and is
+	// granted the next token ahead of the low-priority waiter.
+	high2 := acquireAsync(s, ctx, highPriority)
+	assertWaiting(t, high2, "high-priority acquire with no tokens")
+	releaseLow1()
+	releaseHigh2 := assertGranted(t, high2, "high-priority acquire after release")
+	assertWaiting(t, low2, "low-priority acquire while high-priority work holds all tokens")
+
+	// A cancelled waiter gives up its place.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	low3 := acquireAsync(s, cancelCtx, lowPriority)
+	assertWaiting(t, low3, "third low-priority acquire")
+	cancel()
+	if r := <-low3; r.err == nil {
+		t.Errorf("cancelled acquire succeeded")
+	}
+
+	releaseHigh1()
+	releaseLow2 := assertGranted(t, low2, "low-priority acquire after release")
+	releaseHigh2()
+	releaseLow2()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running != [2]int{} || len(s.waiting[highPriority])+len(s.waiting[lowPriority]) > 0 {
+		t.Errorf("after releasing all tokens, running = %v, waiting = %v", s.running, s.waiting)
+	}
+}
+
+// TestSchedulerBoost checks that boosting a waiting low-priority
+// ticket lets it run as high-priority work.
+func TestSchedulerBoost(t *testing.T) {
+	ctx := context.Background()
+	s := newScheduler(2)
+
+	releaseLow1, err := s.acquire(ctx, newTicket(lowPriority))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tkt := newTicket(lowPriority)
+	ch := make(chan acquireResult, 1)
+	go func() {
+		release, err := s.acquire(ctx, tkt)
+		ch <- acquireResult{release, err}
+	}()
+	assertWaiting(t, ch, "second low-priority acquire")
+
+	// The free token is reserved for high-priority work,
+	// which the boosted ticket now is.
+	s.boost(tkt)
+	releaseLow2 := assertGranted(t, ch, "boosted acquire")
+	s.mu.Lock()
+	if s.running != [2]int{1, 1} {
+		t.Errorf("after boost, running = %v, want [1 1]", s.running)
+	}
+	s.mu.Unlock()
+
+	// Later acquires of a boosted ticket are high priority too.
+	releaseLow2()
+	releaseLow1()
+	release, err := s.acquire(ctx, tkt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	if s.running != [2]int{1, 0} {
+		t.Errorf("after acquiring boosted ticket, running = %v, want [1 0]", s.running)
+	}
+	s.mu.Unlock()
+	release()
+}
+
+type acquireResult struct {
+	release func()
+	err     error
+}
+
+func acquireAsync(s *scheduler, ctx context.Context, p priority) chan acquireResult {
+	ch := make(chan acquireResult, 1)
+	go func() {
+		release, err := s.acquire(ctx, newTicket(p))
+		ch <- acquireResult{release, err}
+	}()
+	return ch
+}
+
+func assertWaiting(t *testing.T, ch chan acquireResult, what string) {
+	t.Helper()
+	select {
+	case r := <-ch:
+		t.Fatalf("%s was not blocked (err=%v)", what, r.err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func assertGranted(t *testing.T, ch chan acquireResult, what string) func() {
+	t.Helper()
+	r := <-ch
+	if r.err != nil {
+		t.Fatalf("%s failed: %v", what, r.err)
+	}
+	return r.release
+}
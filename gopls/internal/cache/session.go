@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"sort"
 	"strconv"
@@ -43,6 +44,7 @@ func NewSession(ctx context.Context, c *Cache) *Session {
 		gocmdRunner: &gocommand.Runner{},
 		overlayFS:   newOverlayFS(c),
 		parseCache:  newParseCache(1 * time.Minute), // keep recently parsed files for a minute, to optimize typing CPU
+		scheduler:   newScheduler(runtime.GOMAXPROCS(0)),
 		viewMap:     make(map[protocol.DocumentURI]*View),
 	}
 	event.Log(ctx, "New session", KeyCreateSession.Of(s))
@@ -71,6 +73,7 @@ type Session struct {
 	snapshotWG sync.WaitGroup
 
 	parseCache *parseCache
+	scheduler  *scheduler // prioritizes type checking across views
 
 	*overlayFS
 }
@@ -232,6 +235,7 @@ func (s *Session) createView(ctx context.Context, def *viewDefinition) (*View, *
 		baseCtx:              baseCtx,
 		pkgIndex:             typerefs.NewPackageIndex(),
 		parseCache:           s.parseCache,
+		scheduler:            s.scheduler,
 		ignoreFilter:         ignoreFilter,
 		fs:                   s.overlayFS,
 		viewDefinition:       def,
@@ -254,6 +258,7 @@ func (s *Session) createView(ctx context.Context, def *viewDefinition) (*View, *
 		symbolizeHandles:  new(persistent.Map[protocol.DocumentURI, *memoize.Promise]),
 		shouldLoad:        new(persistent.Map[PackageID, []PackagePath]),
 		unloadableFiles:   new(persistent.Set[protocol.DocumentURI]),
+		loadedDirs:        new(persistent.Set[string]),
 		parseModHandles:   new(persistent.Map[protocol.DocumentURI, *memoize.Promise]),
 		parseWorkHandles:  new(persistent.Map[protocol.DocumentURI, *memoize.Promise]),
 		modTidyHandles:    new(persistent.Map[protocol.DocumentURI, *memoize.Promise]),
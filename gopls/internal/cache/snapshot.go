@@ -164,6 +164,11 @@ type Snapshot struct {
 	// unloadableFiles keeps track of files that we've failed to load.
 	unloadableFiles *persistent.Set[protocol.DocumentURI]
 
+	// loadedDirs holds the directories whose package trees have been
+	// loaded, when the loadOnlyOpenDirectories setting is in effect.
+	// Like shouldLoad, it is updated after each attempted load.
+	loadedDirs *persistent.Set[string]
+
 	// TODO(rfindley): rename the handles below to "promises". A promise is
 	// different from a handle (we mutate the package handle.)
 
@@ -245,6 +250,7 @@ func (s *Snapshot) decref() {
 		s.modVulnHandles.Destroy()
 		s.modWhyHandles.Destroy()
 		s.unloadableFiles.Destroy()
+		s.loadedDirs.Destroy()
 		s.moduleUpgrades.Destroy()
 		s.vulns.Destroy()
 		s.done()
@@ -521,7 +527,8 @@ func (s *Snapshot) Overlays() []*overlay {
 // Package data kinds, identifying various package data that may be stored in
 // the file cache.
 const (
-	xrefsKind       = "xrefs"
+	xrefsKind       = "xrefs"      // keys of the xrefsFileKind data of each file
+	xrefsFileKind   = "xrefs-file" // xrefs index of a single file
 	methodSetsKind  = "methodsets"
 	testsKind       = "tests"
 	exportDataKind  = "export"
@@ -576,28 +583,49 @@ func (s *Snapshot) References(ctx context.Context, ids ...PackageID) ([]xrefInde
 	indexes := make([]xrefIndex, len(ids))
 	pre := func(i int, ph *packageHandle) bool {
 		data, err := filecache.Get(xrefsKind, ph.key)
-		if err == nil { // hit
-			indexes[i] = xrefIndex{mp: ph.mp, data: data}
-			return false
-		} else if err != filecache.ErrNotFound {
-			event.Error(ctx, "reading xrefs from filecache", err)
+		if err != nil {
+			if err != filecache.ErrNotFound {
+				event.Error(ctx, "reading xrefs from filecache", err)
+			}
+			return true
 		}
-		return true
+		// The package entry holds the key of each file's index.
+		// If any has been evicted, the package must be type-checked again.
+		files := make([][]byte, len(data)/len(file.Hash{}))
+		for j := range files {
+			var key file.Hash
+			copy(key[:], data[j*len(key):])
+			files[j], err = filecache.Get(xrefsFileKind, key)
+			if err != nil {
+				if err != filecache.ErrNotFound {
+					event.Error(ctx, "reading xrefs from filecache", err)
+				}
+				return true
+			}
+		}
+		indexes[i] = xrefIndex{mp: ph.mp, files: files} // hit
+		return false
 	}
 	post := func(i int, pkg *Package) {
-		indexes[i] = xrefIndex{mp: pkg.metadata, data: pkg.pkg.xrefs()}
+		indexes[i] = xrefIndex{mp: pkg.metadata, files: pkg.pkg.xrefs(ctx)}
 	}
 	return indexes, s.forEachPackage(ctx, ids, pre, post)
 }
 
 // An xrefIndex is a helper for looking up references in a given package.
 type xrefIndex struct {
-	mp   *metadata.Package
-	data []byte
+	mp    *metadata.Package
+	files [][]byte // xrefs index of each compiled Go file
 }
 
 func (index xrefIndex) Lookup(targets map[PackagePath]map[objectpath.Path]struct{}) []protocol.Location {
-	return xrefs.Lookup(index.mp, index.data, targets)
+	return xrefs.Lookup(index.mp, index.files, targets)
+}
+
+// Visit calls f for each reference to an object in the target set,
+// with the package path and object path that denote the object.
+func (index xrefIndex) Visit(targets map[PackagePath]map[objectpath.Path]struct{}, f func(PackagePath, objectpath.Path, protocol.Location)) {
+	xrefs.Visit(index.mp, index.files, targets, f)
 }
 
 // MethodSets returns method-set indexes for the specified packages.
@@ -1074,7 +1102,7 @@ func (s *Snapshot) Metadata(id PackageID) *metadata.Package {
 }
 
 // clearShouldLoad clears package IDs that no longer need to be reloaded after
-// scopes has been loaded.
+// scopes has been loaded, and records the directories that have been loaded.
 func (s *Snapshot) clearShouldLoad(scopes ...loadScope) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1100,6 +1128,8 @@ func (s *Snapshot) clearShouldLoad(scopes ...loadScope) {
 			for _, id := range ids {
 				s.shouldLoad.Delete(id)
 			}
+		case directoryLoadScope:
+			s.loadedDirs.Add(string(scope))
 		}
 	}
 }
@@ -1229,7 +1259,9 @@ func (s *Snapshot) AwaitInitialized(ctx context.Context) {
 	s.initialize(ctx, false)
 }
 
-// reloadWorkspace reloads the metadata for all invalidated workspace packages.
+// reloadWorkspace reloads the metadata for all invalidated workspace packages,
+// and loads the package trees of any newly opened directories if the
+// loadOnlyOpenDirectories setting is in effect.
 func (s *Snapshot) reloadWorkspace(ctx context.Context) {
 	if ctx.Err() != nil {
 		return
@@ -1250,6 +1282,11 @@ func (s *Snapshot) reloadWorkspace(ctx context.Context) {
 			scopes = append(scopes, packageLoadScope(pkgPath))
 		}
 	})
+	if s.loadOnlyOpenDirectories() {
+		for _, dir := range unloadedOpenDirsLocked(s) {
+			scopes = append(scopes, directoryLoadScope(dir))
+		}
+	}
 	s.mu.Unlock()
 
 	if len(scopes) == 0 {
@@ -1488,7 +1525,7 @@ https://github.com/golang/tools/blob/master/gopls/doc/settings.md#buildflags.`
 // We only warn about an orphaned file if it is well-formed enough to actually
 // be part of a package. Otherwise, we need more information.
 func orphanedFileDiagnosticRange(ctx context.Context, cache *parseCache, fh file.Handle) (*parsego.File, protocol.Range, bool) {
-	pgfs, err := cache.parseFiles(ctx, token.NewFileSet(), parsego.Header, false, fh)
+	pgfs, err := cache.parseFiles(ctx, token.NewFileSet(), parsego.Header, fh)
 	if err != nil {
 		return nil, protocol.Range{}, false
 	}
@@ -1569,6 +1606,7 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 		workspacePackages: s.workspacePackages,
 		shouldLoad:        s.shouldLoad.Clone(),      // not cloneWithout: shouldLoad is cleared on loads
 		unloadableFiles:   s.unloadableFiles.Clone(), // not cloneWithout: typing in a file doesn't necessarily make it loadable
+		loadedDirs:        s.loadedDirs.Clone(),
 		parseModHandles:   cloneWithout(s.parseModHandles, changedFiles, &needsDiagnosis),
 		parseWorkHandles:  cloneWithout(s.parseWorkHandles, changedFiles, &needsDiagnosis),
 		modTidyHandles:    cloneWithout(s.modTidyHandles, changedFiles, &needsDiagnosis),
@@ -1677,6 +1715,10 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 	if reinit {
 		result.initialized = false
 		needsDiagnosis = true
+
+		// Reinitialization loads the open directories afresh.
+		result.loadedDirs.Destroy()
+		result.loadedDirs = new(persistent.Set[string])
 	}
 
 	// directIDs keeps track of package IDs that have directly changed.
@@ -2059,8 +2101,8 @@ func metadataChanges(ctx context.Context, lockedSnapshot *Snapshot, oldFH, newFH
 
 	fset := token.NewFileSet()
 	// Parse headers to compare package names and imports.
-	oldHeads, oldErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Header, false, oldFH)
-	newHeads, newErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Header, false, newFH)
+	oldHeads, oldErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Header, oldFH)
+	newHeads, newErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Header, newFH)
 
 	if oldErr != nil || newErr != nil {
 		errChanged := (oldErr == nil) != (newErr == nil)
@@ -2106,8 +2148,8 @@ func metadataChanges(ctx context.Context, lockedSnapshot *Snapshot, oldFH, newFH
 	// Note: if this affects performance we can probably avoid parsing in the
 	// common case by first scanning the source for potential comments.
 	if !invalidate {
-		origFulls, oldErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Full, false, oldFH)
-		newFulls, newErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Full, false, newFH)
+		origFulls, oldErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Full, oldFH)
+		newFulls, newErr := lockedSnapshot.view.parseCache.parseFiles(ctx, fset, parsego.Full, newFH)
 		if oldErr == nil && newErr == nil {
 			invalidate = magicCommentsChanged(origFulls[0].File, newFulls[0].File)
 		} else {
@@ -2194,7 +2236,7 @@ func (s *Snapshot) BuiltinFile(ctx context.Context) (*parsego.File, error) {
 	// For the builtin file only, we need syntactic object resolution
 	// (since we can't type check).
 	mode := parsego.Full &^ parser.SkipObjectResolution
-	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), mode, false, fh)
+	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), mode, fh)
 	if err != nil {
 		return nil, err
 	}
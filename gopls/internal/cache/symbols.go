@@ -69,7 +69,7 @@ func (s *Snapshot) symbolize(ctx context.Context, uri protocol.DocumentURI) ([]S
 
 // symbolizeImpl reads and parses a file and extracts symbols from it.
 func symbolizeImpl(ctx context.Context, snapshot *Snapshot, fh file.Handle) ([]Symbol, error) {
-	pgfs, err := snapshot.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Full, false, fh)
+	pgfs, err := snapshot.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Full, fh)
 	if err != nil {
 		return nil, err
 	}
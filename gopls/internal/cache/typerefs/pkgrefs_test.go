@@ -294,7 +294,7 @@ func (p *memoizedParser) parse(ctx context.Context, uri protocol.DocumentURI) (*
 			return nil, err
 		}
 		content = astutil.PurgeFuncBodies(content)
-		pgf, _ := parsego.Parse(ctx, token.NewFileSet(), uri, content, parsego.Full, false)
+		pgf, _ := parsego.Parse(ctx, token.NewFileSet(), uri, content, parsego.Full)
 		return pgf, nil
 	}
 
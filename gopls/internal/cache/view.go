@@ -114,6 +114,10 @@ type View struct {
 	// parseCache holds an LRU cache of recently parsed files.
 	parseCache *parseCache
 
+	// scheduler limits and prioritizes type checking; it is shared by
+	// all views of the session.
+	scheduler *scheduler
+
 	// fs is the file source used to populate this view.
 	fs *overlayFS
 
@@ -643,6 +647,18 @@ func (s *Snapshot) initialize(ctx context.Context, firstAttempt bool) {
 		})
 	}
 
+	// With loadOnlyOpenDirectories, the workspace consists of the
+	// directories of open files, which are loaded in place of the modules
+	// or the view. The go.mod files are nonetheless checked.
+	openDirsOnly := s.loadOnlyOpenDirectories()
+	if openDirsOnly {
+		s.mu.Lock()
+		for _, dir := range unloadedOpenDirsLocked(s) {
+			scopes = append(scopes, directoryLoadScope(dir))
+		}
+		s.mu.Unlock()
+	}
+
 	if len(s.view.workspaceModFiles) > 0 {
 		for modURI := range s.view.workspaceModFiles {
 			// Verify that the modfile is valid before trying to load it.
@@ -676,16 +692,19 @@ func (s *Snapshot) initialize(ctx context.Context, firstAttempt bool) {
 			// Previously, we loaded <modulepath>/... for each module path, but that
 			// is actually incorrect when the pattern may match packages in more than
 			// one module. See golang/go#59458 for more details.
-			scopes = append(scopes, moduleLoadScope{dir: modURI.DirPath(), modulePath: parsed.File.Module.Mod.Path})
+			if !openDirsOnly {
+				scopes = append(scopes, moduleLoadScope{dir: modURI.DirPath(), modulePath: parsed.File.Module.Mod.Path})
+			}
 		}
-	} else {
+	} else if !openDirsOnly {
 		scopes = append(scopes, viewLoadScope{})
 	}
 
 	// If we're loading anything, ensure we also load builtin,
 	// since it provides fake definitions (and documentation)
 	// for types like int that are used everywhere.
-	if len(scopes) > 0 {
+	// (With loadOnlyOpenDirectories, there may be no open files yet.)
+	if len(scopes) > 0 || openDirsOnly {
 		scopes = append(scopes, packageLoadScope("builtin"))
 	}
 	loadErr := s.load(ctx, NetworkOK, scopes...)
@@ -699,6 +718,9 @@ func (s *Snapshot) initialize(ctx context.Context, firstAttempt bool) {
 	if loadErr != nil && ctx.Err() != nil && !firstAttempt {
 		return
 	}
+	if openDirsOnly {
+		s.clearShouldLoad(scopes...) // record the loaded directories
+	}
 
 	var initialErr *InitializationError
 	switch {
@@ -21,12 +21,18 @@ import (
 	"golang.org/x/tools/gopls/internal/util/frob"
 )
 
-// Index constructs a serializable index of outbound cross-references
-// for the specified type-checked package.
-func Index(files []*parsego.File, pkg *types.Package, info *types.Info) []byte {
+// IndexFile constructs a serializable index of outbound
+// cross-references for one file of the specified type-checked package.
+//
+// The index of a package is the list of the indexes of its compiled
+// Go files, so that when one file changes only its index need be
+// recomputed. The caller is responsible for determining when the
+// index of a file remains valid: it depends not only on the file but
+// on the declarations of the package and on its dependencies.
+func IndexFile(pgf *parsego.File, pkg *types.Package, info *types.Info) []byte {
 	// pkgObjects maps each referenced package Q to a mapping:
 	// from each referenced symbol in Q to the ordered list
-	// of references to that symbol from this package.
+	// of references to that symbol from this file.
 	// A nil types.Object indicates a reference
 	// to the package as a whole: an import.
 	pkgObjects := make(map[*types.Package]map[types.Object]*gobObject)
@@ -43,75 +49,67 @@ func Index(files []*parsego.File, pkg *types.Package, info *types.Info) []byte {
 
 	objectpathFor := new(objectpath.Encoder).For
 
-	for fileIndex, pgf := range files {
-		ast.Inspect(pgf.File, func(n ast.Node) bool {
-			switch n := n.(type) {
-			case *ast.Ident:
-				// Report a reference for each identifier that
-				// uses a symbol exported from another package.
-				// (The built-in error.Error method has no package.)
-				if n.IsExported() {
-					if obj, ok := info.Uses[n]; ok &&
-						obj.Pkg() != nil &&
-						obj.Pkg() != pkg {
-
-						// For instantiations of generic methods,
-						// use the generic object (see issue #60622).
-						if fn, ok := obj.(*types.Func); ok {
-							obj = fn.Origin()
-						}
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			// Report a reference for each identifier that
+			// uses a symbol exported from another package.
+			// (The built-in error.Error method has no package.)
+			if n.IsExported() {
+				if obj, ok := info.Uses[n]; ok &&
+					obj.Pkg() != nil &&
+					obj.Pkg() != pkg {
+
+					// For instantiations of generic methods,
+					// use the generic object (see issue #60622).
+					if fn, ok := obj.(*types.Func); ok {
+						obj = fn.Origin()
+					}
 
-						objects := getObjects(obj.Pkg())
-						gobObj, ok := objects[obj]
-						if !ok {
-							path, err := objectpathFor(obj)
-							if err != nil {
-								// Capitalized but not exported
-								// (e.g. local const/var/type).
-								return true
-							}
-							gobObj = &gobObject{Path: path}
-							objects[obj] = gobObj
+					objects := getObjects(obj.Pkg())
+					gobObj, ok := objects[obj]
+					if !ok {
+						path, err := objectpathFor(obj)
+						if err != nil {
+							// Capitalized but not exported
+							// (e.g. local const/var/type).
+							return true
 						}
+						gobObj = &gobObject{Path: path}
+						objects[obj] = gobObj
+					}
 
-						// golang/go#66683: nodes can under/overflow the file.
-						// For example, "var _ = x." creates a SelectorExpr(Sel=Ident("_"))
-						// that is beyond EOF. (Arguably Ident.Name should be "".)
-						if rng, err := pgf.NodeRange(n); err == nil {
-							gobObj.Refs = append(gobObj.Refs, gobRef{
-								FileIndex: fileIndex,
-								Range:     rng,
-							})
-						}
+					// golang/go#66683: nodes can under/overflow the file.
+					// For example, "var _ = x." creates a SelectorExpr(Sel=Ident("_"))
+					// that is beyond EOF. (Arguably Ident.Name should be "".)
+					if rng, err := pgf.NodeRange(n); err == nil {
+						gobObj.Refs = append(gobObj.Refs, rng)
 					}
 				}
+			}
 
-			case *ast.ImportSpec:
-				// Report a reference from each import path
-				// string to the imported package.
-				pkgname := info.PkgNameOf(n)
-				if pkgname == nil {
-					return true // missing import
-				}
-				objects := getObjects(pkgname.Imported())
-				gobObj, ok := objects[nil]
-				if !ok {
-					gobObj = &gobObject{Path: ""}
-					objects[nil] = gobObj
-				}
-				// golang/go#66683: nodes can under/overflow the file.
-				if rng, err := pgf.NodeRange(n.Path); err == nil {
-					gobObj.Refs = append(gobObj.Refs, gobRef{
-						FileIndex: fileIndex,
-						Range:     rng,
-					})
-				} else {
-					bug.Reportf("out of bounds import spec %+v", n.Path)
-				}
+		case *ast.ImportSpec:
+			// Report a reference from each import path
+			// string to the imported package.
+			pkgname := info.PkgNameOf(n)
+			if pkgname == nil {
+				return true // missing import
 			}
-			return true
-		})
-	}
+			objects := getObjects(pkgname.Imported())
+			gobObj, ok := objects[nil]
+			if !ok {
+				gobObj = &gobObject{Path: ""}
+				objects[nil] = gobObj
+			}
+			// golang/go#66683: nodes can under/overflow the file.
+			if rng, err := pgf.NodeRange(n.Path); err == nil {
+				gobObj.Refs = append(gobObj.Refs, rng)
+			} else {
+				bug.Reportf("out of bounds import spec %+v", n.Path)
+			}
+		}
+		return true
+	})
 
 	// Flatten the maps into slices, and sort for determinism.
 	var packages []*gobPackage
@@ -136,60 +134,67 @@ func Index(files []*parsego.File, pkg *types.Package, info *types.Info) []byte {
 	return packageCodec.Encode(packages)
 }
 
-// Lookup searches a serialized index produced by an indexPackage
-// operation on m, and returns the locations of all references from m
-// to any object in the target set. Each object is denoted by a pair
-// of (package path, object path).
-func Lookup(mp *metadata.Package, data []byte, targets map[metadata.PackagePath]map[objectpath.Path]struct{}) (locs []protocol.Location) {
-	var packages []*gobPackage
-	packageCodec.Decode(data, &packages)
-	for _, gp := range packages {
-		if objectSet, ok := targets[gp.PkgPath]; ok {
-			for _, gobObj := range gp.Objects {
-				if _, ok := objectSet[gobObj.Path]; ok {
-					for _, ref := range gobObj.Refs {
-						uri := mp.CompiledGoFiles[ref.FileIndex]
-						locs = append(locs, protocol.Location{
-							URI:   uri,
-							Range: ref.Range,
-						})
+// Lookup searches the serialized indexes produced by IndexFile for
+// each of the compiled Go files of package mp, and returns the
+// locations of all references from mp to any object in the target
+// set. Each object is denoted by a pair of (package path, object path).
+//
+// The indexes of the files are searched separately, so there is no
+// need to merge them into an index of the package as a whole.
+func Lookup(mp *metadata.Package, files [][]byte, targets map[metadata.PackagePath]map[objectpath.Path]struct{}) (locs []protocol.Location) {
+	Visit(mp, files, targets, func(_ metadata.PackagePath, _ objectpath.Path, loc protocol.Location) {
+		locs = append(locs, loc)
+	})
+	return locs
+}
+
+// Visit is like [Lookup], but calls f for each reference, along with
+// the pair that denotes the referenced object.
+func Visit(mp *metadata.Package, files [][]byte, targets map[metadata.PackagePath]map[objectpath.Path]struct{}, f func(metadata.PackagePath, objectpath.Path, protocol.Location)) {
+	for i, data := range files {
+		uri := mp.CompiledGoFiles[i]
+		var packages []*gobPackage
+		packageCodec.Decode(data, &packages)
+		for _, gp := range packages {
+			if objectSet, ok := targets[gp.PkgPath]; ok {
+				for _, gobObj := range gp.Objects {
+					if _, ok := objectSet[gobObj.Path]; ok {
+						for _, rng := range gobObj.Refs {
+							f(gp.PkgPath, gobObj.Path, protocol.Location{
+								URI:   uri,
+								Range: rng,
+							})
+						}
 					}
 				}
 			}
 		}
 	}
-
-	return locs
 }
 
 // -- serialized representation --
 
 // The cross-reference index records the location of all references
-// from one package to symbols defined in other packages
+// from one file of package P to symbols defined in other packages
 // (dependencies). It does not record within-package references.
-// The index for package P consists of a list of gopPackage records,
+// The index for a file of P consists of a list of gobPackage records,
 // each enumerating references to symbols defined a single dependency, Q.
 
 // TODO(adonovan): opt: choose a more compact encoding.
-// The gobRef.Range field is the obvious place to begin.
+// The gobObject.Refs field is the obvious place to begin.
 
 // (The name says gob but in fact we use frob.)
 var packageCodec = frob.CodecFor[[]*gobPackage]()
 
-// A gobPackage records the set of outgoing references from the index
-// package to symbols defined in a dependency package.
+// A gobPackage records the set of outgoing references from the indexed
+// file to symbols defined in a dependency package.
 type gobPackage struct {
 	PkgPath metadata.PackagePath // defining package (Q)
-	Objects []*gobObject         // set of Q objects referenced by P
+	Objects []*gobObject         // set of Q objects referenced by the file
 }
 
 // A gobObject records all references to a particular symbol.
 type gobObject struct {
-	Path objectpath.Path // symbol name within package; "" => import of package itself
-	Refs []gobRef        // locations of references within P, in lexical order
-}
-
-type gobRef struct {
-	FileIndex int            // index of enclosing file within P's CompiledGoFiles
-	Range     protocol.Range // source range of reference
+	Path objectpath.Path  // symbol name within package; "" => import of package itself
+	Refs []protocol.Range // source ranges of references within the file, in lexical order
 }
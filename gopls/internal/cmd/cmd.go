@@ -99,6 +99,7 @@ func New() *Application {
 		OCAgent: "off", //TODO: Remove this line to default the exporter to on
 
 		Serve: Serve{
+			PersistentCache:     true,
 			RemoteListenTimeout: 1 * time.Minute,
 		},
 	}
@@ -293,8 +294,8 @@ func (app *Application) featureCommands() []tool.Application {
 		&highlight{app: app},
 		&implementation{app: app},
 		&imports{app: app},
-		newRemote(app, ""),
-		newRemote(app, "inspect"),
+		newInspect(app),
+		newRemote(app),
 		&links{app: app},
 		&prepareRename{app: app},
 		&references{app: app},
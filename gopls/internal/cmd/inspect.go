@@ -0,0 +1,92 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/tool"
+)
+
+// inspect is the 'inspect' command, which reports the internal state of
+// gopls for debugging. It was once an alias for 'remote', so it retains
+// the subcommands of that command.
+type inspect struct {
+	app *Application
+	subcommands
+}
+
+func newInspect(app *Application) *inspect {
+	return &inspect{
+		app: app,
+		subcommands: subcommands{
+			&inspectPackage{app: app},
+			&listSessions{app: app},
+			&startDebugging{app: app},
+		},
+	}
+}
+
+func (i *inspect) Name() string      { return "inspect" }
+func (i *inspect) Parent() string    { return i.app.Name() }
+func (i *inspect) ShortHelp() string { return "report the internal state of gopls" }
+
+// inspectPackage is an inspect subcommand to describe packages.
+type inspectPackage struct {
+	app *Application
+}
+
+func (p *inspectPackage) Name() string   { return "package" }
+func (p *inspectPackage) Parent() string { return p.app.Name() }
+func (p *inspectPackage) Usage() string  { return "<pattern>" }
+func (p *inspectPackage) ShortHelp() string {
+	return "describe the loading and checking of packages"
+}
+
+func (p *inspectPackage) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Load the workspace for the current directory, and output a JSON description
+of each package matching the pattern: its metadata, its imports, the
+go/packages query that loaded it, and the errors found in loading, parsing,
+and type-checking it.
+
+The pattern matches a package whose ID or path is equal to it or, if it
+ends with "/...", a package whose path is the preceding prefix or lies
+beneath it. Packages that are dependencies of the workspace are included.
+
+Example:
+  $ gopls inspect package example.com/foo/...
+`)
+	printFlagDefaults(f)
+}
+
+func (p *inspectPackage) Run(ctx context.Context, args ...string) error {
+	if len(args) != 1 {
+		return tool.CommandLineErrorf("inspect package expects one pattern")
+	}
+	conn, err := p.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	cmd := command.NewInspectPackagesCommand("", command.InspectPackagesArgs{Pattern: args[0]})
+	res, err := conn.executeCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(res, "", "\t")
+	if err != nil {
+		return err
+	}
+	os.Stdout.Write(data)
+	fmt.Println()
+	return nil
+}
@@ -41,6 +41,7 @@ import (
 	"golang.org/x/tools/gopls/internal/cmd"
 	"golang.org/x/tools/gopls/internal/debug"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/version"
 	"golang.org/x/tools/internal/testenv"
@@ -561,6 +562,50 @@ func _() {
 }
 
 // TestLinks tests the 'links' subcommand (links.go).
+func TestInspectPackage(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+import "example.com/b"
+var _ = b.B + undefined
+-- b/b.go --
+package b
+const B = 1
+`)
+	// missing pattern
+	{
+		res := gopls(t, tree, "inspect", "package")
+		res.checkExit(false)
+		res.checkStderr("expects one pattern")
+	}
+	res := gopls(t, tree, "inspect", "package", "example.com/...")
+	res.checkExit(true)
+	var result command.InspectPackagesResult
+	if err := json.Unmarshal([]byte(res.stdout), &result); err != nil {
+		t.Fatalf("failed to unmarshal JSON output of inspect package: %v", err)
+	}
+	got := result.Packages
+	if len(got) != 2 || got[0].Path != "example.com/a" || got[1].Path != "example.com/b" {
+		t.Fatalf("inspect package returned %d packages, want example.com/a and example.com/b:\n%s", len(got), res.stdout)
+	}
+	a := got[0]
+	if a.Imports["example.com/b"] != "example.com/b" {
+		t.Errorf("imports of a = %v, want example.com/b", a.Imports)
+	}
+	if len(a.LoadQuery) == 0 {
+		t.Errorf("a has no load query")
+	}
+	if len(a.TypeErrors) != 1 || !strings.Contains(a.TypeErrors[0], "undefined") {
+		t.Errorf("type errors of a = %q, want one for undefined", a.TypeErrors)
+	}
+}
+
 func TestLinks(t *testing.T) {
 	t.Parallel()
 
@@ -11,11 +11,15 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/debug"
+	"golang.org/x/tools/gopls/internal/filecache"
 	"golang.org/x/tools/gopls/internal/lsprpc"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/internal/fakenet"
@@ -34,6 +38,9 @@ type Serve struct {
 	Trace       bool          `flag:"rpc.trace" help:"print the full rpc trace in lsp inspector format"`
 	Debug       string        `flag:"debug" help:"serve debug information on the supplied address"`
 
+	PersistentCache bool   `flag:"cache.persistent" help:"save the results of analyzing each package, such as its export data and indexes, in a cache on disk for reuse by later sessions and other gopls processes. If false, they are held only in memory."`
+	CacheBudget     string `flag:"cache.budget" help:"soft limit on the disk space used by the persistent cache, such as 500MB or 2GiB (default 1GB)"`
+
 	RemoteListenTimeout time.Duration `flag:"remote.listen.timeout" help:"when used with -remote=auto, the -listen.timeout value used to start the daemon"`
 	RemoteDebug         string        `flag:"remote.debug" help:"when used with -remote=auto, the -debug value used to start the daemon"`
 	RemoteLogfile       string        `flag:"remote.logfile" help:"when used with -remote=auto, the -logfile value used to start the daemon"`
@@ -71,6 +78,13 @@ func (s *Serve) remoteArgs(network, address string) []string {
 	if s.RemoteLogfile != "" {
 		args = append(args, "-logfile", s.RemoteLogfile)
 	}
+	// The daemon, not the forwarder, holds the cache.
+	if !s.PersistentCache {
+		args = append(args, "-cache.persistent=false")
+	}
+	if s.CacheBudget != "" {
+		args = append(args, "-cache.budget", s.CacheBudget)
+	}
 	return args
 }
 
@@ -81,6 +95,17 @@ func (s *Serve) Run(ctx context.Context, args ...string) error {
 		return tool.CommandLineErrorf("server does not take arguments, got %v", args)
 	}
 
+	// The cache is shared by all the sessions of the process,
+	// so it is configured by flags rather than by settings.
+	filecache.SetPersistent(s.PersistentCache)
+	if s.CacheBudget != "" {
+		budget, err := parseByteSize(s.CacheBudget)
+		if err != nil {
+			return tool.CommandLineErrorf("invalid -cache.budget: %v", err)
+		}
+		filecache.SetBudget(budget)
+	}
+
 	di := debug.GetInstance(ctx)
 	isDaemon := s.Address != "" || s.Port != 0
 	if di != nil {
@@ -144,3 +169,35 @@ func (s *Serve) Run(ctx context.Context, args ...string) error {
 	}
 	return err
 }
+
+// parseByteSize parses a size in bytes, such as "500MB" or "2GiB".
+// The units KB, MB, GB, and TB are powers of 1000, and KiB, MiB, GiB,
+// and TiB are powers of 1024.
+func parseByteSize(s string) (int64, error) {
+	num := strings.TrimRight(s, "KMGTiB")
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit := s[len(num):]
+	if unit == "" || unit == "B" {
+		return n, nil
+	}
+	base := int64(1000)
+	if prefix, ok := strings.CutSuffix(unit, "iB"); ok {
+		base, unit = 1024, prefix
+	} else {
+		unit = strings.TrimSuffix(unit, "B")
+	}
+	exp := strings.Index("KMGT", unit)
+	if len(unit) != 1 || exp < 0 {
+		return 0, fmt.Errorf("invalid size %q: unknown unit", s)
+	}
+	for ; exp >= 0; exp-- {
+		if n > math.MaxInt64/base {
+			return 0, fmt.Errorf("invalid size %q: too large", s)
+		}
+		n *= base
+	}
+	return n, nil
+}
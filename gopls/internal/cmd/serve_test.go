@@ -0,0 +1,42 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want int64 // -1 => error
+	}{
+		{"0", 0},
+		{"100", 100},
+		{"100B", 100},
+		{"2KB", 2000},
+		{"500MB", 500e6},
+		{"1GB", 1e9},
+		{"3TB", 3e12},
+		{"2KiB", 2048},
+		{"1GiB", 1 << 30},
+		{"8388607TiB", 8388607 << 40},
+		{"", -1},
+		{"MB", -1},
+		{"-1GB", -1},
+		{"1XB", -1},
+		{"1iB", -1},
+		{"1KMB", -1},
+		{"1 GB", -1},
+		{"8388608TiB", -1}, // overflow
+		{"9223372036854776KB", -1},
+	} {
+		got, err := parseByteSize(test.in)
+		if err != nil {
+			got = -1
+		}
+		if got != test.want {
+			t.Errorf("parseByteSize(%q) = %d (%v), want %d", test.in, got, err, test.want)
+		}
+	}
+}
@@ -12,6 +12,8 @@
 // recipe would produce.)
 //
 // The space budget of the cache can be controlled by [SetBudget].
+// [SetPersistent] turns the cache into a memory-only cache that
+// neither reads nor writes files.
 // Cache entries may be evicted at any time or in any order.
 // Note that "du -sh $GOPLSCACHE" may report a disk usage
 // figure that is rather larger (e.g. 50%) than the budget because
@@ -72,6 +74,9 @@ func Get(kind string, key [32]byte) ([]byte, error) {
 	if value, ok := memCache.Get(memKey{kind, key}); ok {
 		return value, nil
 	}
+	if memoryOnly.Load() && kind != bugKind {
+		return nil, ErrNotFound
+	}
 
 	iolimit <- struct{}{}        // acquire a token
 	defer func() { <-iolimit }() // release a token
@@ -140,6 +145,9 @@ var ErrNotFound = fmt.Errorf("not found")
 // Set updates the value in the cache.
 func Set(kind string, key [32]byte, value []byte) error {
 	memCache.Set(memKey{kind, key}, value, len(value))
+	if memoryOnly.Load() && kind != bugKind {
+		return nil
+	}
 
 	// Set the active event to wake up the GC.
 	select {
@@ -237,6 +245,19 @@ func SetBudget(new int64) (old int64) {
 	return atomic.SwapInt64(&budget, new)
 }
 
+// SetPersistent sets whether the cache is persistent, and returns the
+// previous value. The cache is persistent by default.
+//
+// A cache that is not persistent holds values only in memory (from
+// which they may be evicted at any time), so they are not shared with
+// other gopls processes or later sessions; existing cache files are
+// neither read nor written. Bug reports are saved regardless.
+func SetPersistent(new bool) (old bool) {
+	return !memoryOnly.Swap(!new)
+}
+
+var memoryOnly atomic.Bool // see SetPersistent
+
 // --- implementation ----
 
 // filename returns the name of the cache file of the specified kind and key.
@@ -167,12 +167,43 @@ func TestIPC(t *testing.T) {
 	}
 }
 
+// TestSetPersistent checks that a cache that is not persistent
+// holds values in memory, but not in files visible to a child process.
+func TestSetPersistent(t *testing.T) {
+	testenv.NeedsExec(t)
+
+	const kind = "TestSetPersistent"
+	key := uniqueKey()
+	value := []byte("hello")
+
+	defer filecache.SetPersistent(filecache.SetPersistent(false))
+	if err := filecache.Set(kind, key, value); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, err := filecache.Get(kind, key); err != nil || string(got) != string(value) {
+		t.Fatalf("Get = %q, %v, want %q", got, err, value)
+	}
+
+	// The child process must not find the key.
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"ENTRYPOINT=memoryOnlyChild",
+		fmt.Sprintf("KEY=%q", key))
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // We define our own main function so that portions of
 // some tests can run in a separate (child) process.
 func TestMain(m *testing.M) {
 	switch os.Getenv("ENTRYPOINT") {
 	case "ipcChild":
 		ipcChild()
+	case "memoryOnlyChild":
+		memoryOnlyChild()
 	default:
 		os.Exit(m.Run())
 	}
@@ -198,6 +229,17 @@ func ipcChild() {
 	}
 }
 
+// memoryOnlyChild is the portion of TestSetPersistent that runs in a
+// child process.
+func memoryOnlyChild() {
+	s, _ := strconv.Unquote(os.Getenv("KEY"))
+	var key [32]byte
+	copy(key[:], []byte(s))
+	if got, err := filecache.Get("TestSetPersistent", key); err != filecache.ErrNotFound {
+		log.Fatalf("child: Get(key) = %q, %v; want not found", got, err)
+	}
+}
+
 // uniqueKey returns a key that has never been used before.
 func uniqueKey() (key [32]byte) {
 	if _, err := cryptorand.Read(key[:]); err != nil {
@@ -0,0 +1,253 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/golang"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/typesutil"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// An InlineProvider provides inline completions: the "ghost text"
+// that an editor shows after the cursor as the user types, and
+// inserts on request.
+type InlineProvider interface {
+	InlineCompletion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pos protocol.Position) ([]protocol.InlineCompletionItem, error)
+}
+
+// InlineCompletion returns the inline completions at the given
+// position of a Go file, using the provider configured by the
+// inlineCompletionCommand setting or, by default, StatementProvider.
+func InlineCompletion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pos protocol.Position) ([]protocol.InlineCompletionItem, error) {
+	ctx, done := event.Start(ctx, "completion.InlineCompletion")
+	defer done()
+
+	var provider InlineProvider = StatementProvider{}
+	if argv := snapshot.Options().InlineCompletionCommand; len(argv) > 0 {
+		provider = CommandProvider{Argv: argv}
+	}
+	return provider.InlineCompletion(ctx, snapshot, fh, pos)
+}
+
+// StatementProvider is the built-in InlineProvider. It completes
+// whole statements for common patterns, deterministically:
+//
+//   - after an assignment whose last operand is an error, in a
+//     function that returns an error or a test, it offers
+//
+//     if err != nil {
+//     return ..., err
+//     }
+//
+//   - in the empty body of such an error check, it offers the
+//     return statement (or the call of t.Fatal) alone.
+//
+// A completion is offered only on an otherwise empty line, or on one
+// whose text before the cursor begins the completion.
+type StatementProvider struct{}
+
+func (StatementProvider) InlineCompletion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, protoPos protocol.Position) ([]protocol.InlineCompletionItem, error) {
+	pkg, pgf, err := golang.NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	offset, err := pgf.Mapper.PositionOffset(protoPos)
+	if err != nil {
+		return nil, err
+	}
+
+	// The text of the line before the cursor must be an
+	// indentation followed by the start of the completion,
+	// and the rest of the line must be blank.
+	start, err := pgf.Mapper.PositionOffset(protocol.Position{Line: protoPos.Line})
+	if err != nil {
+		return nil, err
+	}
+	rest := pgf.Src[offset:]
+	if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, nil
+	}
+	before := string(pgf.Src[start:offset])
+	typed := strings.TrimLeft(before, " \t")
+	indent := before[:len(before)-len(typed)]
+
+	stmt := errCheckStatement(pkg, pgf, pgf.Tok.Pos(start))
+	if stmt == "" {
+		return nil, nil
+	}
+	lines := strings.Split(stmt, "\n")
+	if !strings.HasPrefix(lines[0], typed) {
+		return nil, nil
+	}
+	for i := 1; i < len(lines); i++ {
+		lines[i] = indent + lines[i]
+	}
+	rng, err := pgf.Mapper.OffsetRange(start+len(indent), offset)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.InlineCompletionItem{{
+		InsertText: protocol.Or_InlineCompletionItem_insertText{Value: strings.Join(lines, "\n")},
+		Range:      &rng,
+	}}, nil
+}
+
+// errCheckStatement returns the error-handling statement, if any, to
+// be inserted at the start of the line at lineStart, with its lines
+// after the first indented relative to the first.
+func errCheckStatement(pkg *cache.Package, pgf *parsego.File, lineStart token.Pos) string {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, lineStart, lineStart)
+	var (
+		block  *ast.BlockStmt
+		parent ast.Node // parent of block
+	)
+	for i, n := range path {
+		if b, ok := n.(*ast.BlockStmt); ok && i+1 < len(path) {
+			block, parent = b, path[i+1]
+			break
+		}
+	}
+	fn := enclosingFunction(path, pkg.TypesInfo())
+	if block == nil || fn == nil {
+		return ""
+	}
+
+	// prev is the statement of block preceding the line, if any.
+	// Any statements after it are incomplete ones on the line.
+	var prev ast.Stmt
+	for _, stmt := range block.List {
+		if stmt.End() > lineStart {
+			break
+		}
+		prev = stmt
+	}
+
+	// handle returns the statement that handles a non-nil error errVar.
+	qf := typesutil.FileQualifier(pgf.File, pkg.Types(), pkg.TypesInfo())
+	handle := func(errVar string) string {
+		if testVar := getTestVar(fn, pkg); testVar != "" {
+			return fmt.Sprintf("%s.Fatal(%s)", testVar, errVar)
+		}
+		results := fn.sig.Results()
+		if results.Len() == 0 || !types.Identical(results.At(results.Len()-1).Type(), errorType) {
+			return ""
+		}
+		var buf strings.Builder
+		buf.WriteString("return ")
+		for i := 0; i < results.Len()-1; i++ {
+			buf.WriteString(typesinternal.ZeroString(results.At(i).Type(), qf))
+			buf.WriteString(", ")
+		}
+		buf.WriteString(errVar)
+		return buf.String()
+	}
+
+	// isError reports whether e is a variable of type error,
+	// returning its name.
+	isError := func(e ast.Expr) (string, bool) {
+		id, ok := e.(*ast.Ident)
+		if !ok || id.Name == "_" || !types.Identical(pkg.TypesInfo().TypeOf(id), errorType) {
+			return "", false
+		}
+		return id.Name, true
+	}
+
+	// In the empty body of "if err != nil {}", handle the error.
+	if prev == nil {
+		if ifStmt, ok := parent.(*ast.IfStmt); ok && ifStmt.Body == block {
+			if cond, ok := ifStmt.Cond.(*ast.BinaryExpr); ok && cond.Op == token.NEQ && isNil(cond.Y) {
+				if errVar, ok := isError(cond.X); ok {
+					return handle(errVar)
+				}
+			}
+		}
+		return ""
+	}
+
+	// After "x, err := f()", check the error.
+	assign, ok := prev.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) == 0 {
+		return ""
+	}
+	errVar, ok := isError(assign.Lhs[len(assign.Lhs)-1])
+	if !ok {
+		return ""
+	}
+	h := handle(errVar)
+	if h == "" {
+		return ""
+	}
+	return fmt.Sprintf("if %s != nil {\n\t%s\n}", errVar, h)
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func isNil(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
+
+// A CommandProvider is an InlineProvider that runs an external
+// program, given by Argv, for each request. The program reads an
+// InlineRequest from its standard input, encoded as JSON, and writes
+// a protocol.InlineCompletionList to its standard output.
+type CommandProvider struct {
+	Argv []string
+}
+
+// An InlineRequest is the request of a CommandProvider to its program.
+type InlineRequest struct {
+	URI      protocol.DocumentURI `json:"uri"`
+	Text     string               `json:"text"` // current content of the file
+	Position protocol.Position    `json:"position"`
+}
+
+func (p CommandProvider) InlineCompletion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pos protocol.Position) ([]protocol.InlineCompletionItem, error) {
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	return p.run(ctx, InlineRequest{URI: fh.URI(), Text: string(content), Position: pos})
+}
+
+// run runs the program with the given request.
+func (p CommandProvider) run(ctx context.Context, req InlineRequest) ([]protocol.InlineCompletionItem, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Argv[0], p.Argv[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("inline completion command %s: %v: %s", p.Argv[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	var list protocol.InlineCompletionList
+	if err := json.Unmarshal(stdout.Bytes(), &list); err != nil {
+		return nil, fmt.Errorf("inline completion command %s: invalid output: %v", p.Argv[0], err)
+	}
+	return list.Items, nil
+}
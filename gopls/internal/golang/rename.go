@@ -61,6 +61,7 @@ import (
 	"golang.org/x/tools/go/types/typeutil"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/methodsets"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
@@ -83,6 +84,7 @@ type renamer struct {
 	satisfyConstraints map[satisfy.Constraint]bool
 	msets              typeutil.MethodSetCache
 	changeMethods      bool
+	workspace          map[PackagePath]bool // if non-nil, the packages whose declarations may be renamed
 }
 
 // A PrepareItem holds the result of a "prepare rename" operation:
@@ -388,7 +390,7 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 		for obj := range targets {
 			objects = append(objects, obj)
 		}
-		editMap, _, err := renameObjects(newName, pkg, objects...)
+		editMap, _, err := renameObjects(newName, pkg, nil, objects...)
 		return editMap, err
 	}
 
@@ -437,21 +439,109 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 		// might expands the scope of the renaming.
 	}
 
-	// Type-check all the packages to inspect.
 	declURI := protocol.URIFromPath(pkg.FileSet().File(obj.Pos()).Name())
-	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, declURI, transitive)
+	declPkgPath := PackagePath(obj.Pkg().Path())
+	declTarget := target{declPkgPath, declObjPath}
+	initial := map[target]bool{declTarget: true}
+	declURIs := []protocol.DocumentURI{declURI}
+
+	workspaceMetas, err := snapshot.WorkspaceMetadata(ctx)
 	if err != nil {
 		return nil, err
 	}
+	workspace := make(map[PackagePath]bool)
+	for _, mp := range workspaceMetas {
+		workspace[mp.PkgPath] = true
+	}
 
-	// Apply the renaming to the (initial) object.
-	declPkgPath := PackagePath(obj.Pkg().Path())
-	return renameExported(pkgs, declPkgPath, declObjPath, newName)
+	// An exported method must be renamed along with the
+	// corresponding methods of the other types of the workspace
+	// that implement, or are implemented by, its receiver type.
+	if fn, ok := obj.(*types.Func); ok && fn.Signature().Recv() != nil {
+		methods, err := correspondingMethods(ctx, snapshot, fn, declTarget, workspace)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range methods {
+			initial[m.target] = true
+			declURIs = append(declURIs, m.declURI)
+		}
+	}
+
+	// Type-check all the packages to inspect.
+	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, transitive, declURIs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the renaming to the (initial) objects.
+	return renameExported(pkgs, workspace, initial, newName)
+}
+
+// A correspondingMethod is a method that must be renamed along with
+// another because their receiver types implement one another.
+type correspondingMethod struct {
+	target  target
+	declURI protocol.DocumentURI // file that declares the method
+}
+
+// correspondingMethods returns the methods of the workspace that
+// correspond to method fn, denoted by the given target. They are the
+// methods of the same name of the types that implement, or are
+// implemented by, its receiver type, and transitively of the types that
+// implement or are implemented by theirs, as recorded by the method-set
+// indexes of all packages, including dependencies. Types outside the
+// workspace are ignored: their methods cannot be renamed, and they
+// constrain the renaming only if a workspace type is converted to one
+// of them, which the satisfy check of each package detects.
+// Function-local and anonymous types are not indexed; they too are
+// found by the satisfy check.
+func correspondingMethods(ctx context.Context, snapshot *cache.Snapshot, fn *types.Func, declTarget target, workspace map[PackagePath]bool) ([]correspondingMethod, error) {
+	key, ok := methodsets.KeyOf(fn.Signature().Recv().Type())
+	if !ok {
+		return nil, bug.Errorf("receiver of method %s has no methods", fn.Name())
+	}
+
+	allMetas, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.RemoveIntermediateTestVariants(&allMetas)
+	ids := make([]PackageID, len(allMetas))
+	for i, mp := range allMetas {
+		ids[i] = mp.ID
+	}
+	indexes, err := snapshot.MethodSets(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("querying method sets: %v", err)
+	}
+
+	var (
+		seen    = map[target]bool{declTarget: true}
+		methods []correspondingMethod
+	)
+	for queue := []methodsets.Key{key}; len(queue) > 0; queue = queue[1:] {
+		for _, index := range indexes {
+			for _, res := range index.Search(queue[0], fn.Id()) {
+				t := target{PackagePath(res.PkgPath), res.ObjectPath}
+				if seen[t] || !workspace[t.pkg] {
+					continue
+				}
+				seen[t] = true
+				methods = append(methods, correspondingMethod{
+					target:  t,
+					declURI: protocol.URIFromPath(res.Location.Filename),
+				})
+				queue = append(queue, res.Key)
+			}
+		}
+	}
+	return methods, nil
 }
 
 // typeCheckReverseDependencies returns the type-checked packages for
 // the reverse dependencies of all packages variants containing
-// file declURI. The packages are in some topological order.
+// any of the files declURIs. The packages are in some topological order.
 //
 // It includes all variants (even intermediate test variants) for the
 // purposes of computing reverse dependencies, but discards ITVs for
@@ -460,22 +550,27 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 // (This neglects obscure edge cases where a _test.go file changes the
 // selectors used only in an ITV, but life is short. Also sin must be
 // punished.)
-func typeCheckReverseDependencies(ctx context.Context, snapshot *cache.Snapshot, declURI protocol.DocumentURI, transitive bool) ([]*cache.Package, error) {
-	variants, err := snapshot.MetadataForFile(ctx, declURI)
-	if err != nil {
-		return nil, err
-	}
+func typeCheckReverseDependencies(ctx context.Context, snapshot *cache.Snapshot, transitive bool, declURIs ...protocol.DocumentURI) ([]*cache.Package, error) {
 	// variants must include ITVs for the reverse dependency
 	// computation, but they are filtered out before we typecheck.
 	allRdeps := make(map[PackageID]*metadata.Package)
-	for _, variant := range variants {
-		rdeps, err := snapshot.ReverseDependencies(ctx, variant.ID, transitive)
+	for _, declURI := range declURIs {
+		variants, err := snapshot.MetadataForFile(ctx, declURI)
 		if err != nil {
 			return nil, err
 		}
-		allRdeps[variant.ID] = variant // include self
-		for id, meta := range rdeps {
-			allRdeps[id] = meta
+		for _, variant := range variants {
+			if _, ok := allRdeps[variant.ID]; ok {
+				continue // already visited
+			}
+			rdeps, err := snapshot.ReverseDependencies(ctx, variant.ID, transitive)
+			if err != nil {
+				return nil, err
+			}
+			allRdeps[variant.ID] = variant // include self
+			for id, meta := range rdeps {
+				allRdeps[id] = meta
+			}
 		}
 	}
 	var ids []PackageID
@@ -504,20 +599,20 @@ func typeCheckReverseDependencies(ctx context.Context, snapshot *cache.Snapshot,
 	return snapshot.TypeCheck(ctx, ids...)
 }
 
-// renameExported renames the object denoted by (pkgPath, objPath)
-// within the specified packages, along with any other objects that
-// must be renamed as a consequence. The slice of packages must be
-// topologically ordered.
-func renameExported(pkgs []*cache.Package, declPkgPath PackagePath, declObjPath objectpath.Path, newName string) (map[protocol.DocumentURI][]diff.Edit, error) {
+// A target is a name for an object that is stable across types.Packages.
+type target struct {
+	pkg PackagePath
+	obj objectpath.Path
+}
 
-	// A target is a name for an object that is stable across types.Packages.
-	type target struct {
-		pkg PackagePath
-		obj objectpath.Path
-	}
+// renameExported renames the target objects within the specified
+// packages, along with any other objects that must be renamed as a
+// consequence, which must belong to the workspace packages. The slice
+// of packages must be topologically ordered.
+func renameExported(pkgs []*cache.Package, workspace map[PackagePath]bool, targets map[target]bool, newName string) (map[protocol.DocumentURI][]diff.Edit, error) {
 
-	// Populate the initial set of target objects.
-	// This set may grow as we discover the consequences of each renaming.
+	// The initial set of target objects may grow as we
+	// discover the consequences of each renaming.
 	//
 	// TODO(adonovan): strictly, each cone of reverse dependencies
 	// of a single variant should have its own target map that
@@ -531,7 +626,6 @@ func renameExported(pkgs []*cache.Package, declPkgPath PackagePath, declObjPath
 	// Or we could decide that the logic below is fast enough not
 	// to need parallelism. In small measurements so far the
 	// type-checking step is about 95% and the renaming only 5%.
-	targets := map[target]bool{{declPkgPath, declObjPath}: true}
 
 	// Apply the renaming operation to each package.
 	allEdits := make(map[protocol.DocumentURI][]diff.Edit)
@@ -564,7 +658,7 @@ func renameExported(pkgs []*cache.Package, declPkgPath PackagePath, declObjPath
 		}
 
 		// Apply the renaming.
-		editMap, moreObjects, err := renameObjects(newName, pkg, objects...)
+		editMap, moreObjects, err := renameObjects(newName, pkg, workspace, objects...)
 		if err != nil {
 			return nil, err
 		}
@@ -929,7 +1023,7 @@ func renameImports(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.P
 				//   become shadowed by an intervening declaration that
 				//   uses the new name.
 				// It returns the edits if no conflict was detected.
-				editMap, _, err := renameObjects(localName, pkg, pkgname)
+				editMap, _, err := renameObjects(localName, pkg, nil, pkgname)
 				if err != nil {
 					return err
 				}
@@ -956,19 +1050,22 @@ func renameImports(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.P
 }
 
 // renameObjects computes the edits to the type-checked syntax package pkg
-// required to rename a set of target objects to newName.
+// required to rename a set of target objects to newName. If workspace
+// is non-nil, it rejects renamings that would require renaming methods
+// declared in other packages.
 //
 // It also returns the set of objects that were found (due to
 // corresponding methods and embedded fields) to require renaming as a
 // consequence of the requested renamings.
 //
 // It returns an error if the renaming would cause a conflict.
-func renameObjects(newName string, pkg *cache.Package, targets ...types.Object) (map[protocol.DocumentURI][]diff.Edit, map[types.Object]bool, error) {
+func renameObjects(newName string, pkg *cache.Package, workspace map[PackagePath]bool, targets ...types.Object) (map[protocol.DocumentURI][]diff.Edit, map[types.Object]bool, error) {
 	r := renamer{
 		pkg:          pkg,
 		objsToUpdate: make(map[types.Object]bool),
 		from:         targets[0].Name(),
 		to:           newName,
+		workspace:    workspace,
 	}
 
 	// A renaming initiated at an interface method indicates the
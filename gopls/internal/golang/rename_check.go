@@ -31,6 +31,15 @@ package golang
 //   discovered by the 'satisfy' pass). As a matter of usability, we
 //   require that such renamings be initiated from the interface
 //   method, not the concrete method.
+//
+//   Exported methods of package-level types of the workspace are also
+//   coupled to the corresponding methods of all the workspace types
+//   that implement, or are implemented by, their receiver type,
+//   whether or not an assignment is visible; renameOrdinary finds them
+//   using the method-set indexes and renames them all, whichever was
+//   initiated. Types outside the workspace, whose methods cannot be
+//   renamed, block the renaming only if an assignment couples them to
+//   a renamed method.
 
 import (
 	"fmt"
@@ -88,6 +97,12 @@ func (r *renamer) errorf(pos token.Pos, format string, args ...interface{}) {
 	r.conflicts = append(r.conflicts, conflict.String())
 }
 
+// isExternal reports whether obj is declared in a package whose
+// declarations may not be renamed.
+func (r *renamer) isExternal(obj types.Object) bool {
+	return r.workspace != nil && obj.Pkg() != nil && !r.workspace[PackagePath(obj.Pkg().Path())]
+}
+
 // check performs safety checks of the renaming of the 'from' object to r.to.
 func (r *renamer) check(from types.Object) {
 	if r.objsToUpdate[from] {
@@ -750,6 +765,14 @@ func (r *renamer) checkMethod(from *types.Func) {
 				}
 			}
 
+			if r.isExternal(coupled) {
+				r.errorf(from.Pos(), "renaming this method %q to %q",
+					from.Name(), r.to)
+				r.errorf(coupled.Pos(), "\twould require renaming (%s).%s, which is outside the workspace",
+					recv(coupled).Type(), from.Name())
+				return // one error is enough
+			}
+
 			if !r.changeMethods {
 				// This should be unreachable.
 				r.errorf(from.Pos(), "internal error: during renaming of abstract method %s", from)
@@ -805,7 +828,7 @@ func (r *renamer) checkMethod(from *types.Func) {
 
 			// imeth is the abstract method (e.g. I.f)
 			// and key.RHS is the concrete coupling type (e.g. D).
-			if !r.changeMethods {
+			if !r.changeMethods || r.isExternal(imeth) {
 				r.errorf(from.Pos(), "renaming this method %q to %q",
 					from.Name(), r.to)
 				var pos token.Pos
@@ -821,8 +844,12 @@ func (r *renamer) checkMethod(from *types.Func) {
 				}
 				r.errorf(pos, "\twould make %s no longer assignable to %s",
 					key.RHS, iface)
-				r.errorf(imeth.Pos(), "\t(rename %s.%s if you intend to change both types)",
-					I, from.Name())
+				if r.isExternal(imeth) {
+					r.errorf(imeth.Pos(), "\t(%s is outside the workspace, so it cannot be renamed)", I)
+				} else {
+					r.errorf(imeth.Pos(), "\t(rename %s.%s if you intend to change both types)",
+						I, from.Name())
+				}
 				return // one error is enough
 			}
 
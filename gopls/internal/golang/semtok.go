@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/token"
 	"go/types"
 	"log"
@@ -330,6 +331,10 @@ func (tv *tokenVisitor) inspect(n ast.Node) (descend bool) {
 			tv.multiline(n.Pos(), n.End(), semtok.TokString)
 			break
 		}
+		if field, ok := tv.stack[len(tv.stack)-2].(*ast.Field); ok && field.Tag == n {
+			tv.structTag(n)
+			break
+		}
 		what := semtok.TokNumber
 		if n.Kind == token.STRING {
 			what = semtok.TokString
@@ -844,6 +849,9 @@ var godirectives = map[string]struct{}{
 	"build":               {},
 	"binary-only-package": {},
 	"embed":               {},
+
+	// https://pkg.go.dev/cmd/go#hdr-Generate_Go_files_by_processing_source
+	"generate": {},
 }
 
 // Tokenize godirective at the start of the comment c, if any, and the surrounding comment.
@@ -868,7 +876,152 @@ func (tv *tokenVisitor) godirective(c *ast.Comment) {
 
 	if len(args) > 0 {
 		tailStart := c.Pos() + token.Pos(len(directive)+len(" "))
-		tv.token(tailStart, len(args), semtok.TokComment)
+		if !tv.directiveArgs(kind, c.Text, tailStart, args) {
+			tv.token(tailStart, len(args), semtok.TokComment)
+		}
+	}
+}
+
+// directiveArgs emits tokens for the arguments of the directives that
+// have a well-defined syntax: the tags and operators of a //go:build
+// constraint, the file patterns of //go:embed, and the command and
+// arguments of //go:generate. It reports false if it emitted nothing,
+// because the directive has no such syntax or the arguments are
+// ill-formed, in which case they are a plain comment.
+func (tv *tokenVisitor) directiveArgs(kind, text string, start token.Pos, args string) bool {
+	switch kind {
+	case "build":
+		if _, err := constraint.Parse(text); err != nil {
+			return false
+		}
+		for i := 0; i < len(args); {
+			switch {
+			case args[i] == ' ' || args[i] == '\t':
+				i++
+			case strings.HasPrefix(args[i:], "&&"), strings.HasPrefix(args[i:], "||"):
+				tv.token(start+token.Pos(i), len("&&"), semtok.TokOperator)
+				i += len("&&")
+			case strings.IndexByte("!()", args[i]) >= 0:
+				tv.token(start+token.Pos(i), len("!"), semtok.TokOperator)
+				i++
+			default:
+				j := i + 1
+				for j < len(args) && strings.IndexByte(" \t!()&|", args[j]) < 0 {
+					j++
+				}
+				tv.token(start+token.Pos(i), j-i, semtok.TokMacro)
+				i = j
+			}
+		}
+		return true
+
+	case "embed", "generate":
+		fields, ok := directiveFields(args)
+		if !ok || len(fields) == 0 {
+			return false
+		}
+		for i, f := range fields {
+			typ := semtok.TokString
+			if kind == "generate" && i == 0 {
+				typ = semtok.TokFunction // the command
+			}
+			tv.token(start+token.Pos(f[0]), f[1]-f[0], typ)
+		}
+		return true
+	}
+	return false
+}
+
+// directiveFields splits the arguments of a directive into fields
+// separated by spaces, where a field may be a Go string literal
+// containing spaces, and returns the [start, end) offset of each.
+// It reports false if a string literal is unterminated.
+func directiveFields(args string) (fields [][2]int, ok bool) {
+	for i := 0; i < len(args); {
+		if args[i] == ' ' || args[i] == '\t' {
+			i++
+			continue
+		}
+		j := i
+		switch quote := args[i]; quote {
+		case '"', '`':
+			for j++; j < len(args) && args[j] != quote; j++ {
+				if quote == '"' && args[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(args) {
+				return nil, false
+			}
+			j++
+		default:
+			for j < len(args) && args[j] != ' ' && args[j] != '\t' {
+				j++
+			}
+		}
+		fields = append(fields, [2]int{i, j})
+		i = j
+	}
+	return fields, true
+}
+
+// structTag emits tokens for the tag of a struct field. By convention
+// (see [reflect.StructTag]), a tag is a sequence of space-separated
+// key:"value" pairs, such as `json:"name,omitempty" xml:"name"`; each
+// key is reported as a property and the rest of the tag as strings.
+// A tag that does not follow the convention is a plain string.
+func (tv *tokenVisitor) structTag(lit *ast.BasicLit) {
+	keys := structTagKeys(lit.Value)
+	if keys == nil {
+		tv.token(lit.Pos(), len(lit.Value), semtok.TokString)
+		return
+	}
+	last := 0
+	for _, key := range keys {
+		tv.token(lit.Pos()+token.Pos(last), key[0]-last, semtok.TokString)
+		tv.token(lit.Pos()+token.Pos(key[0]), key[1]-key[0], semtok.TokProperty)
+		last = key[1]
+	}
+	tv.token(lit.Pos()+token.Pos(last), len(lit.Value)-last, semtok.TokString)
+}
+
+// structTagKeys returns the [start, end) offsets of the keys of the raw
+// string literal of a conventional struct tag, or nil if the tag is
+// empty, interpreted, or not conventional. The grammar is that of
+// [reflect.StructTag.Lookup].
+func structTagKeys(tag string) (keys [][2]int) {
+	if len(tag) < 2 || tag[0] != '`' {
+		return nil // interpreted string literals would require unescaping
+	}
+	end := len(tag) - 1 // closing quote
+	for i := 1; ; {
+		for i < end && tag[i] == ' ' {
+			i++
+		}
+		if i == end {
+			return keys
+		}
+		// A key is a non-empty sequence of non-control
+		// characters other than space, quote, and colon.
+		j := i
+		for j < end && tag[j] > ' ' && tag[j] != ':' && tag[j] != '"' && tag[j] != 0x7f {
+			j++
+		}
+		if j == i || j+1 >= end || tag[j] != ':' || tag[j+1] != '"' {
+			return nil
+		}
+		keys = append(keys, [2]int{i, j})
+		// The value is a quoted string.
+		k := j + 2
+		for ; k < end && tag[k] != '"'; k++ {
+			if tag[k] == '\\' {
+				k++
+			}
+		}
+		if k >= end {
+			return nil
+		}
+		i = k + 1
 	}
 }
 
@@ -0,0 +1,186 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/event"
+)
+
+// In Go, the type hierarchy is the "implements" relation between
+// concrete types and interfaces, which is computed by the
+// 'implementation' operator using the method-set indexes of all
+// packages, including dependencies. The supertypes of a concrete type
+// are the interfaces it implements, and the subtypes of an interface
+// are the concrete types that implement it. The supertypes of an
+// interface are the named interfaces it embeds.
+//
+// Each item is identified by the location of the name of its type, so
+// that its supertypes and subtypes are computed only when requested.
+
+// PrepareTypeHierarchy returns the item for the type denoted by the
+// identifier at the given position, if any.
+func PrepareTypeHierarchy(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "golang.PrepareTypeHierarchy")
+	defer done()
+
+	obj, pkg, err := implementsObj(ctx, snapshot, fh.URI(), pp)
+	if err != nil {
+		if errors.Is(err, ErrNoIdentFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := obj.(*types.TypeName); !ok {
+		return nil, nil // a method
+	}
+	var loc protocol.Location
+	if obj.Pos().IsValid() {
+		loc, err = mapPosition(ctx, pkg.FileSet(), snapshot, obj.Pos(), adjustedObjEnd(obj))
+	} else {
+		loc, err = errorLocation(ctx, snapshot) // the only type with no position
+	}
+	if err != nil {
+		return nil, err
+	}
+	item, err := typeHierarchyItem(ctx, snapshot, loc)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.TypeHierarchyItem{item}, nil
+}
+
+// Supertypes returns the items for the supertypes of the given item.
+func Supertypes(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "golang.Supertypes")
+	defer done()
+
+	if item.Kind != protocol.Interface {
+		locs, err := Implementation(ctx, snapshot, fh, item.SelectionRange.Start)
+		if err != nil {
+			return nil, err
+		}
+		return typeHierarchyItems(ctx, snapshot, locs)
+	}
+
+	// The supertypes of an interface are the named interfaces it embeds.
+	obj, pkg, err := implementsObj(ctx, snapshot, fh.URI(), item.SelectionRange.Start)
+	if err != nil {
+		return nil, err
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", obj.Name())
+	}
+	var locs []protocol.Location
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		named, ok := types.Unalias(iface.EmbeddedType(i)).(*types.Named)
+		if !ok {
+			continue // e.g. a type union
+		}
+		var loc protocol.Location
+		if embedded := named.Obj(); embedded.Pos().IsValid() {
+			loc, err = mapPosition(ctx, pkg.FileSet(), snapshot, embedded.Pos(), adjustedObjEnd(embedded))
+		} else {
+			loc, err = errorLocation(ctx, snapshot)
+		}
+		if err != nil {
+			return nil, err
+		}
+		locs = append(locs, loc)
+	}
+	return typeHierarchyItems(ctx, snapshot, locs)
+}
+
+// Subtypes returns the items for the subtypes of the given item.
+func Subtypes(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "golang.Subtypes")
+	defer done()
+
+	if item.Kind != protocol.Interface {
+		return nil, nil // concrete types have no subtypes
+	}
+	locs, err := Implementation(ctx, snapshot, fh, item.SelectionRange.Start)
+	if err != nil {
+		return nil, err
+	}
+	return typeHierarchyItems(ctx, snapshot, locs)
+}
+
+// typeHierarchyItems returns the items for the types whose names are
+// declared at the given locations.
+func typeHierarchyItems(ctx context.Context, snapshot *cache.Snapshot, locs []protocol.Location) ([]protocol.TypeHierarchyItem, error) {
+	items := make([]protocol.TypeHierarchyItem, 0, len(locs))
+	for _, loc := range locs {
+		item, err := typeHierarchyItem(ctx, snapshot, loc)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// typeHierarchyItem returns the item for the type whose name is
+// declared at loc. The kind of the item is determined from the syntax
+// of the declaration, so that no type checking is needed.
+func typeHierarchyItem(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) (protocol.TypeHierarchyItem, error) {
+	fh, err := snapshot.ReadFile(ctx, loc.URI)
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	startOffset, endOffset, err := safetoken.Offsets(pgf.Tok, start, end)
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+
+	kind := protocol.Class
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	for _, n := range path {
+		if spec, ok := n.(*ast.TypeSpec); ok {
+			switch spec.Type.(type) {
+			case *ast.InterfaceType:
+				kind = protocol.Interface
+			case *ast.StructType:
+				kind = protocol.Struct
+			}
+			break
+		}
+	}
+
+	detail := filepath.Base(loc.URI.Path())
+	if mps, err := snapshot.MetadataForFile(ctx, loc.URI); err == nil && len(mps) > 0 {
+		detail = fmt.Sprintf("%s • %s", mps[0].PkgPath, detail)
+	}
+
+	return protocol.TypeHierarchyItem{
+		Name:           string(pgf.Src[startOffset:endOffset]),
+		Kind:           kind,
+		Detail:         detail,
+		URI:            loc.URI,
+		Range:          loc.Range,
+		SelectionRange: loc.Range,
+	}, nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/internal/event"
+)
+
+// UnusedSymbols reports, as hints, the package-level functions, types,
+// variables, and constants declared by the given workspace packages
+// that are referenced nowhere in the workspace, as configured by the
+// unusedSymbols and unusedSymbolsIgnoreTests settings.
+//
+// Within its package, a symbol is deemed used if any other identifier
+// has its name. This conservative approximation requires only the
+// syntax of the package, not its types. An exported symbol is also
+// used if the cross-reference index of any workspace package records a
+// reference to it. These indexes are cached, and invalidated only by
+// changes to the files that refer to the symbol, so the cost of this
+// operation after an edit is mostly that of reading them.
+//
+// Files excluded from the build by constraints, such as those for
+// other platforms, are neither type-checked nor indexed, so any
+// identifier in them with the name of a symbol counts as a use: of an
+// unexported symbol if the file belongs to its package, and of an
+// exported symbol wherever the file is in the workspace.
+func UnusedSymbols(ctx context.Context, snapshot *cache.Snapshot, workspace []*metadata.Package) (map[protocol.DocumentURI][]*cache.Diagnostic, error) {
+	ctx, done := event.Start(ctx, "golang.UnusedSymbols")
+	defer done()
+
+	opts := snapshot.Options()
+	if opts.UnusedSymbols == settings.UnusedSymbolsOff {
+		return nil, nil
+	}
+	ignoreTests := opts.UnusedSymbolsIgnoreTests
+
+	// A test variant of a package (which has the same path)
+	// contributes only its test files, which may use the symbols
+	// of the package; x_test packages are just other packages.
+	var (
+		declaring = make(map[metadata.PackagePath]*metadata.Package)
+		useFiles  = make(map[metadata.PackagePath]map[protocol.DocumentURI]bool)
+		scope     []metadata.PackageID // packages whose references count
+	)
+	for _, mp := range workspace {
+		if mp.ForTest == "" {
+			declaring[mp.PkgPath] = mp
+		}
+		if mp.ForTest == "" || !ignoreTests {
+			scope = append(scope, mp.ID)
+		}
+		files := useFiles[mp.PkgPath]
+		if files == nil {
+			files = make(map[protocol.DocumentURI]bool)
+			useFiles[mp.PkgPath] = files
+		}
+		for _, uri := range mp.CompiledGoFiles {
+			if !ignoreTests || !isTestFile(uri) {
+				files[uri] = true
+			}
+		}
+	}
+
+	parse := func(uri protocol.DocumentURI) (*parsego.File, error) {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.ParseGo(ctx, fh, parsego.Full)
+	}
+
+	// Record the names of the identifiers in ignored files,
+	// by package, and for the whole workspace.
+	var (
+		ignoredNames    = make(map[metadata.PackagePath]map[string]bool)
+		allIgnoredNames = make(map[string]bool)
+		seenIgnored     = make(map[protocol.DocumentURI]bool) // test variants share ignored files
+	)
+	for _, mp := range workspace {
+		for _, uri := range mp.IgnoredFiles {
+			if !strings.HasSuffix(string(uri), ".go") || ignoreTests && isTestFile(uri) || seenIgnored[uri] {
+				continue
+			}
+			seenIgnored[uri] = true
+			pgf, err := parse(uri)
+			if err != nil {
+				return nil, err
+			}
+			names := ignoredNames[mp.PkgPath]
+			if names == nil {
+				names = make(map[string]bool)
+				ignoredNames[mp.PkgPath] = names
+			}
+			ast.Inspect(pgf.File, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					names[id.Name] = true
+					allIgnoredNames[id.Name] = true
+				}
+				return true
+			})
+		}
+	}
+
+	// Find the candidate symbols of each package,
+	// and count the identifiers that may refer to them.
+	type candidate struct {
+		pgf  *parsego.File
+		id   *ast.Ident
+		kind string
+	}
+	var (
+		unused  = make(map[metadata.PackagePath]map[string]candidate)
+		targets = make(map[metadata.PackagePath]map[objectpath.Path]struct{}) // exported candidates
+	)
+	for pkgPath, mp := range declaring {
+		candidates := make(map[string]candidate)
+		for _, uri := range mp.CompiledGoFiles {
+			pgf, err := parse(uri)
+			if err != nil {
+				return nil, err
+			}
+			if ast.IsGenerated(pgf.File) {
+				continue
+			}
+			add := func(id *ast.Ident, kind string) {
+				if id.Name == "_" ||
+					opts.UnusedSymbols == settings.UnusedSymbolsUnexported && id.IsExported() {
+					return
+				}
+				candidates[id.Name] = candidate{pgf, id, kind}
+			}
+			for _, decl := range pgf.File.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					// Methods may be needed to satisfy interfaces. Functions
+					// with no body or with directives may be used from
+					// assembly, C, or by linkname.
+					if decl.Recv != nil || decl.Body == nil || hasDirective(decl.Doc) ||
+						decl.Name.Name == "init" ||
+						decl.Name.Name == "main" && mp.Name == "main" {
+						continue
+					}
+					add(decl.Name, "function")
+
+				case *ast.GenDecl:
+					kind := declKinds[decl.Tok]
+					for _, spec := range decl.Specs {
+						switch spec := spec.(type) {
+						case *ast.TypeSpec:
+							add(spec.Name, kind)
+						case *ast.ValueSpec:
+							for _, id := range spec.Names {
+								add(id, kind)
+							}
+						}
+					}
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		// Count the identifiers with the name of each candidate.
+		// The declaration itself accounts for one.
+		uses := make(map[string]int)
+		for uri := range useFiles[pkgPath] {
+			pgf, err := parse(uri)
+			if err != nil {
+				return nil, err
+			}
+			ast.Inspect(pgf.File, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					if _, ok := candidates[id.Name]; ok {
+						uses[id.Name]++
+					}
+				}
+				return true
+			})
+		}
+		for name, c := range candidates {
+			if uses[name] > 1 || ignoredNames[pkgPath][name] ||
+				c.id.IsExported() && allIgnoredNames[name] {
+				continue
+			}
+			if unused[pkgPath] == nil {
+				unused[pkgPath] = make(map[string]candidate)
+			}
+			unused[pkgPath][name] = c
+			if c.id.IsExported() {
+				if targets[pkgPath] == nil {
+					targets[pkgPath] = make(map[objectpath.Path]struct{})
+				}
+				// The object path of a package-level object is its name.
+				targets[pkgPath][objectpath.Path(name)] = struct{}{}
+			}
+		}
+	}
+
+	// Exported symbols may be used by other packages.
+	if len(targets) > 0 {
+		indexes, err := snapshot.References(ctx, scope...)
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range indexes {
+			index.Visit(targets, func(pkgPath metadata.PackagePath, path objectpath.Path, loc protocol.Location) {
+				if !ignoreTests || !isTestFile(loc.URI) {
+					delete(unused[pkgPath], string(path))
+				}
+			})
+		}
+	}
+
+	reports := make(map[protocol.DocumentURI][]*cache.Diagnostic)
+	for _, candidates := range unused {
+		for name, c := range candidates {
+			rng, err := c.pgf.NodeRange(c.id)
+			if err != nil {
+				continue // declaration under/overflows the file
+			}
+			reports[c.pgf.URI] = append(reports[c.pgf.URI], &cache.Diagnostic{
+				URI:      c.pgf.URI,
+				Range:    rng,
+				Severity: protocol.SeverityHint,
+				Source:   cache.UnusedSymbol,
+				Message:  fmt.Sprintf("unused %s: %s", c.kind, name),
+				Tags:     []protocol.DiagnosticTag{protocol.Unnecessary},
+			})
+		}
+	}
+	return reports, nil
+}
+
+var declKinds = map[token.Token]string{
+	token.TYPE:  "type",
+	token.VAR:   "variable",
+	token.CONST: "constant",
+}
+
+// isTestFile reports whether uri denotes a Go test file.
+func isTestFile(uri protocol.DocumentURI) bool {
+	return strings.HasSuffix(string(uri), "_test.go")
+}
+
+// hasDirective reports whether the comment group contains a
+// directive, such as //go:linkname or //export.
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc != nil {
+		for _, c := range doc.List {
+			if strings.HasPrefix(c.Text, "//go:") || strings.HasPrefix(c.Text, "//export ") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -12,7 +12,7 @@ import (
 	"time"
 
 	"golang.org/x/tools/gopls/internal/protocol"
-	jsonrpc2_v2 "golang.org/x/tools/internal/jsonrpc2_v2"
+	jsonrpc2_v2 "golang.org/x/tools/jsonrpc2"
 
 	. "golang.org/x/tools/gopls/internal/lsprpc"
 )
@@ -13,8 +13,8 @@ import (
 
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/internal/event"
-	jsonrpc2_v2 "golang.org/x/tools/internal/jsonrpc2_v2"
 	"golang.org/x/tools/internal/xcontext"
+	jsonrpc2_v2 "golang.org/x/tools/jsonrpc2"
 )
 
 const HandshakeMethod = handshakeMethod
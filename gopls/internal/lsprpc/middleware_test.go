@@ -15,7 +15,7 @@ import (
 
 	. "golang.org/x/tools/gopls/internal/lsprpc"
 	"golang.org/x/tools/internal/event"
-	jsonrpc2_v2 "golang.org/x/tools/internal/jsonrpc2_v2"
+	jsonrpc2_v2 "golang.org/x/tools/jsonrpc2"
 )
 
 var noopBinder = BinderFunc(func(context.Context, *jsonrpc2_v2.Connection) jsonrpc2_v2.ConnectionOptions {
@@ -42,6 +42,7 @@ const (
 	GCDetails               Command = "gopls.gc_details"
 	Generate                Command = "gopls.generate"
 	GoGetPackage            Command = "gopls.go_get_package"
+	InspectPackages         Command = "gopls.inspect_packages"
 	ListImports             Command = "gopls.list_imports"
 	ListKnownPackages       Command = "gopls.list_known_packages"
 	MaybePromptForTelemetry Command = "gopls.maybe_prompt_for_telemetry"
@@ -88,6 +89,7 @@ var Commands = []Command{
 	GCDetails,
 	Generate,
 	GoGetPackage,
+	InspectPackages,
 	ListImports,
 	ListKnownPackages,
 	MaybePromptForTelemetry,
@@ -228,6 +230,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.GoGetPackage(ctx, a0)
+	case InspectPackages:
+		var a0 InspectPackagesArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.InspectPackages(ctx, a0)
 	case ListImports:
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -508,6 +516,14 @@ func NewGoGetPackageCommand(title string, a0 GoGetPackageArgs) *protocol.Command
 	}
 }
 
+func NewInspectPackagesCommand(title string, a0 InspectPackagesArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   InspectPackages.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewListImportsCommand(title string, a0 URIArg) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
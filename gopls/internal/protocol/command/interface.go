@@ -297,6 +297,14 @@ type Interface interface {
 	// server yet.
 	Packages(context.Context, PackagesArgs) (PackagesResult, error)
 
+	// InspectPackages: Describe the loading and checking of packages
+	//
+	// This command reports, for each package in any view that matches
+	// the pattern, its metadata, the go/packages query that loaded it,
+	// and the errors encountered in loading, parsing, and type-checking
+	// it. It is intended for debugging problems with package loading.
+	InspectPackages(context.Context, InspectPackagesArgs) (InspectPackagesResult, error)
+
 	// Modules: Return information about modules within a directory
 	//
 	// This command returns an empty result if there is no module, or if module
@@ -665,6 +673,52 @@ type Package struct {
 	TestFiles []TestFile
 }
 
+// InspectPackagesArgs holds arguments for the InspectPackages command.
+type InspectPackagesArgs struct {
+	// Pattern selects the packages to describe. It matches a package
+	// whose ID or path is equal to it or, if it ends with "/...", a
+	// package whose path is the preceding prefix or lies beneath it.
+	Pattern string
+}
+
+// InspectPackagesResult is the result of the InspectPackages command.
+type InspectPackagesResult struct {
+	// Packages describes the matching packages, ordered by view and ID.
+	Packages []InspectedPackage
+}
+
+// InspectedPackage describes how gopls loaded and checked a package.
+type InspectedPackage struct {
+	View       string // ID of the view containing the package
+	ID         string
+	Path       string
+	Name       string
+	ForTest    string `json:",omitempty"`
+	Module     string `json:",omitempty"` // module path, if any
+	Standalone bool   `json:",omitempty"` // package of a standalone file
+
+	// LoadDir and LoadQuery are the directory from which, and the
+	// patterns with which, go/packages was run to load the package.
+	LoadDir   string
+	LoadQuery []string
+
+	GoFiles         []protocol.DocumentURI
+	CompiledGoFiles []protocol.DocumentURI
+	IgnoredFiles    []protocol.DocumentURI `json:",omitempty"`
+	OtherFiles      []protocol.DocumentURI `json:",omitempty"`
+
+	// Imports maps each import path of the package to the ID of the
+	// imported package, which is empty if the import is missing.
+	Imports map[string]string
+
+	// LoadDiagnostics holds the errors reported by go/packages for the
+	// package and its dependencies. ParseErrors and TypeErrors hold the
+	// errors found in parsing and type-checking it.
+	LoadDiagnostics []protocol.Diagnostic
+	ParseErrors     []string
+	TypeErrors      []string
+}
+
 type Module struct {
 	Path    string               // module path
 	Version string               // module version if any.
@@ -28,12 +28,13 @@ const (
 	TokFunction  TokenType = "function"      // for a function
 	TokKeyword   TokenType = "keyword"       // for a keyword
 	TokLabel     TokenType = "label"         // for a control label (LSP 3.18)
-	TokMacro     TokenType = "macro"         // for text/template tokens
+	TokMacro     TokenType = "macro"         // for text/template tokens and build tags
 	TokMethod    TokenType = "method"        // for a method
 	TokNamespace TokenType = "namespace"     // for an imported package name
 	TokNumber    TokenType = "number"        // for a numeric literal
 	TokOperator  TokenType = "operator"      // for an operator
 	TokParameter TokenType = "parameter"     // for a parameter variable
+	TokProperty  TokenType = "property"      // for a struct tag key
 	TokString    TokenType = "string"        // for a string literal
 	TokType      TokenType = "type"          // for a type name (plus other uses)
 	TokTypeParam TokenType = "typeParameter" // for a type parameter
@@ -45,7 +46,6 @@ const (
 	// TokEvent      TokenType = "event"
 	// TokInterface  TokenType = "interface"
 	// TokModifier   TokenType = "modifier"
-	// TokProperty   TokenType = "property"
 	// TokRegexp     TokenType = "regexp"
 	// TokStruct     TokenType = "struct"
 )
@@ -265,6 +265,82 @@ func (h *commandHandler) Packages(ctx context.Context, args command.PackagesArgs
 	return result, err
 }
 
+func (h *commandHandler) InspectPackages(ctx context.Context, args command.InspectPackagesArgs) (command.InspectPackagesResult, error) {
+	match := func(mp *metadata.Package) bool {
+		if prefix, ok := strings.CutSuffix(args.Pattern, "/..."); ok {
+			path := string(mp.PkgPath)
+			return path == prefix || strings.HasPrefix(path, prefix+"/")
+		}
+		return string(mp.ID) == args.Pattern || string(mp.PkgPath) == args.Pattern
+	}
+
+	var result command.InspectPackagesResult
+	err := h.run(ctx, commandConfig{
+		progress: "Inspecting packages",
+	}, func(ctx context.Context, _ commandDeps) error {
+		for _, view := range h.s.session.Views() {
+			snapshot, release, err := view.Snapshot()
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			// Await loading, so that the metadata graph is complete.
+			if _, err := snapshot.WorkspaceMetadata(ctx); err != nil {
+				return err
+			}
+			var ids []cache.PackageID
+			for id, mp := range snapshot.MetadataGraph().Packages {
+				if match(mp) {
+					ids = append(ids, id)
+				}
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			pkgs, err := snapshot.TypeCheck(ctx, ids...)
+			if err != nil {
+				return err
+			}
+
+			for _, pkg := range pkgs {
+				mp := pkg.Metadata()
+				desc := command.InspectedPackage{
+					View:            view.ID(),
+					ID:              string(mp.ID),
+					Path:            string(mp.PkgPath),
+					Name:            string(mp.Name),
+					ForTest:         string(mp.ForTest),
+					Standalone:      mp.Standalone,
+					LoadDir:         mp.LoadDir,
+					LoadQuery:       mp.LoadQuery,
+					GoFiles:         mp.GoFiles,
+					CompiledGoFiles: mp.CompiledGoFiles,
+					IgnoredFiles:    mp.IgnoredFiles,
+					OtherFiles:      mp.OtherFiles,
+					Imports:         make(map[string]string),
+					LoadDiagnostics: toProtocolDiagnostics(pkg.LoadDiagnostics()),
+				}
+				if mp.Module != nil {
+					desc.Module = mp.Module.Path
+				}
+				for impPath, id := range mp.DepsByImpPath {
+					desc.Imports[string(impPath)] = string(id)
+				}
+				for _, list := range pkg.ParseErrors() {
+					for _, err := range list {
+						desc.ParseErrors = append(desc.ParseErrors, err.Error())
+					}
+				}
+				for _, err := range pkg.TypeErrors() {
+					desc.TypeErrors = append(desc.TypeErrors, err.Error())
+				}
+				result.Packages = append(result.Packages, desc)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
 func (h *commandHandler) MaybePromptForTelemetry(ctx context.Context) error {
 	// if the server's TelemetryPrompt is true, it's likely the server already
 	// handled prompting for it. Don't try to prompt again.
@@ -313,6 +313,11 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 	ctx, done := event.Start(ctx, "Server.diagnose", snapshot.Labels()...)
 	defer done()
 
+	// Diagnosing the whole workspace is background work: apart from
+	// packages that have open files or depend on them, the packages it
+	// type checks yield to those needed by other requests.
+	ctx = cache.WithLowPriority(ctx)
+
 	// Wait for a free diagnostics slot.
 	// TODO(adonovan): opt: shouldn't it be the analysis implementation's
 	// job to de-dup and limit resource consumption? In any case this
@@ -488,6 +493,13 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 		store("collecting gc_details", gcDetailsReports, err)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		unusedReports, err := golang.UnusedSymbols(ctx, snapshot, workspacePkgs)
+		store("finding unused symbols", unusedReports, err)
+	}()
+
 	// Package diagnostics and analysis diagnostics must both be computed and
 	// merged before they can be reported.
 	var pkgDiags, analysisDiags diagMap
@@ -143,6 +143,7 @@ func (s *server) Initialize(ctx context.Context, params *protocol.ParamInitializ
 			},
 			DefinitionProvider:         &protocol.Or_ServerCapabilities_definitionProvider{Value: true},
 			TypeDefinitionProvider:     &protocol.Or_ServerCapabilities_typeDefinitionProvider{Value: true},
+			TypeHierarchyProvider:      &protocol.Or_ServerCapabilities_typeHierarchyProvider{Value: true},
 			ImplementationProvider:     &protocol.Or_ServerCapabilities_implementationProvider{Value: true},
 			DocumentFormattingProvider: &protocol.Or_ServerCapabilities_documentFormattingProvider{Value: true},
 			DocumentSymbolProvider:     &protocol.Or_ServerCapabilities_documentSymbolProvider{Value: true},
@@ -155,6 +156,7 @@ func (s *server) Initialize(ctx context.Context, params *protocol.ParamInitializ
 			DocumentHighlightProvider: &protocol.Or_ServerCapabilities_documentHighlightProvider{Value: true},
 			DocumentLinkProvider:      &protocol.DocumentLinkOptions{},
 			InlayHintProvider:         protocol.InlayHintOptions{},
+			InlineCompletionProvider:  &protocol.Or_ServerCapabilities_inlineCompletionProvider{Value: true},
 			DiagnosticProvider:        diagnosticProvider,
 			ReferencesProvider:        &protocol.Or_ServerCapabilities_referencesProvider{Value: true},
 			RenameProvider:            renameOpts,
@@ -5,6 +5,8 @@
 package settings
 
 import (
+	"encoding/json"
+
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/appends"
 	"golang.org/x/tools/go/analysis/passes/asmdecl"
@@ -59,6 +61,7 @@ import (
 	"golang.org/x/tools/gopls/internal/analysis/useany"
 	"golang.org/x/tools/gopls/internal/analysis/yield"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/analysisinternal"
 )
 
 // Analyzer augments a [analysis.Analyzer] with additional LSP configuration.
@@ -187,3 +190,10 @@ func init() {
 		DefaultAnalyzers[analyzer.analyzer.Name] = analyzer
 	}
 }
+
+// AnalyzerConfig returns the value of [analysis.Pass.Config] for the
+// analyzer, given the "analysisConfig" option, which holds the
+// settings of each analyzer keyed by name.
+func AnalyzerConfig(a *analysis.Analyzer, config map[string]json.RawMessage) (any, error) {
+	return analysisinternal.MakeConfig(a, config[a.Name])
+}
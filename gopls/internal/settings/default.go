@@ -93,6 +93,7 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 							Nil:    true,
 						},
 						Vulncheck:                 ModeVulncheckOff,
+						UnusedSymbols:             UnusedSymbolsOff,
 						DiagnosticsDelay:          1 * time.Second,
 						DiagnosticsTrigger:        DiagnosticsOnEdit,
 						AnalysisProgressReporting: true,
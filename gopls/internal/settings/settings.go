@@ -5,6 +5,7 @@
 package settings
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
 	"path/filepath"
@@ -136,6 +137,23 @@ type BuildOptions struct {
 	// gopls has to do to keep your workspace up to date.
 	ExpandWorkspaceToModule bool `status:"experimental"`
 
+	// LoadOnlyOpenDirectories restricts the packages that gopls loads and
+	// diagnoses to those in the directories of open files, and beneath
+	// them, instead of the entire workspace.
+	//
+	// In a very large repository, such as a monorepo containing thousands
+	// of modules, loading the whole workspace may take minutes and
+	// gigabytes of memory. With this setting, gopls loads the package
+	// tree of each directory when a file in it is first opened, so that
+	// navigating to a file outside the directories loaded so far expands
+	// the workspace on demand. Packages outside them are still loaded as
+	// needed as dependencies, and workspace-wide queries such as
+	// references see only the packages loaded so far.
+	//
+	// This setting has no effect in ad-hoc views, which consist of a single
+	// directory in any case.
+	LoadOnlyOpenDirectories bool `status:"experimental"`
+
 	// StandaloneTags specifies a set of build constraints that identify
 	// individual Go source files that make up the entire main package of an
 	// executable.
@@ -341,6 +359,18 @@ type CompletionOptions struct {
 	// expected of the expression being completed, completion may suggest call
 	// expressions (i.e. may include parentheses).
 	CompleteFunctionCalls bool
+
+	// InlineCompletionCommand is the command line of an external
+	// program that provides inline completions (the "ghost text" shown
+	// after the cursor) in place of the built-in ones, which complete
+	// common statements such as error checks.
+	//
+	// For each textDocument/inlineCompletion request, gopls runs the
+	// program with a JSON object on its standard input, whose fields
+	// "uri", "text", and "position" give the file, its current content,
+	// and the position of the cursor. The program must write an LSP
+	// InlineCompletionList to its standard output.
+	InlineCompletionCommand []string `status:"experimental"`
 }
 
 // Note: DocumentationOptions must be comparable with reflect.DeepEqual.
@@ -437,6 +467,24 @@ type DiagnosticOptions struct {
 	// ```
 	Analyses map[string]bool
 
+	// AnalysisConfig holds the settings of analyzers that accept
+	// them, keyed by analyzer name. It has the same form as the file
+	// named by the -settings flag of command-line analysis drivers
+	// such as `go vet`, so that one set of settings applies to both.
+	//
+	// Example Usage:
+	//
+	// ```json5
+	// ...
+	// "analysisConfig": {
+	//   "printf": {
+	//     "wrappers": [{"func": "example.com/log.Infof", "kind": "printf"}]
+	//   }
+	// }
+	// ...
+	// ```
+	AnalysisConfig map[string]json.RawMessage `status:"experimental"`
+
 	// Staticcheck enables additional analyses from staticcheck.io.
 	// These analyses are documented on
 	// [Staticcheck's website](https://staticcheck.io/docs/checks/).
@@ -449,6 +497,23 @@ type DiagnosticOptions struct {
 	// Vulncheck enables vulnerability scanning.
 	Vulncheck VulncheckMode `status:"experimental"`
 
+	// UnusedSymbols controls whether gopls reports, as hints, the
+	// package-level functions, types, variables, and constants of
+	// workspace packages that are referenced nowhere in the workspace.
+	// Methods are never reported, as they may be needed to satisfy
+	// an interface.
+	//
+	// A symbol may be used in ways that gopls cannot see, such as by
+	// reflection, by assembly, or through a linkname directive in
+	// another package, so check the reported symbols before deleting
+	// them.
+	UnusedSymbols UnusedSymbolsMode `status:"experimental"`
+
+	// UnusedSymbolsIgnoreTests causes references from test files not
+	// to count as uses of a symbol, so that the unusedSymbols setting
+	// also reports symbols that are used only by tests.
+	UnusedSymbolsIgnoreTests bool `status:"experimental"`
+
 	// DiagnosticsDelay controls the amount of time that gopls waits
 	// after the most recent file modification before computing deep diagnostics.
 	// Simple diagnostics (parsing and type-checking) are always run immediately
@@ -817,6 +882,19 @@ const (
 	// TODO: VulncheckRequire, VulncheckCallgraph
 )
 
+type UnusedSymbolsMode string
+
+const (
+	// Do not report unused symbols. (default)
+	UnusedSymbolsOff UnusedSymbolsMode = "Off"
+	// Report unused unexported symbols.
+	UnusedSymbolsUnexported UnusedSymbolsMode = "Unexported"
+	// Report unused exported symbols too. As an exported symbol may be
+	// used by packages outside the workspace, this is most useful
+	// when the workspace contains all the users of its packages.
+	UnusedSymbolsAll UnusedSymbolsMode = "All"
+)
+
 type DiagnosticsTrigger string
 
 const (
@@ -1065,6 +1143,34 @@ func (o *Options) setOne(name string, value any) error {
 			return deprecatedError("the 'fieldalignment' analyzer was removed in gopls/v0.17.0; instead, hover over struct fields to see size/offset information (https://go.dev/issue/66861)")
 		}
 
+	case "analysisConfig":
+		all, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid type %T (want JSON object)", value)
+		}
+		config := make(map[string]json.RawMessage)
+		for name, settings := range all {
+			data, err := json.Marshal(settings)
+			if err != nil {
+				return err
+			}
+			config[name] = data
+		}
+		// Check the settings of the analyzers known to gopls;
+		// others may be intended for other drivers.
+		for name := range config {
+			a, ok := DefaultAnalyzers[name]
+			if !ok {
+				a, ok = StaticcheckAnalyzers[name]
+			}
+			if ok {
+				if _, err := AnalyzerConfig(a.Analyzer(), config); err != nil {
+					return err
+				}
+			}
+		}
+		o.AnalysisConfig = config
+
 	case "hints":
 		return setBoolMap(&o.Hints, value)
 
@@ -1076,6 +1182,15 @@ func (o *Options) setOne(name string, value any) error {
 			ModeVulncheckOff,
 			ModeVulncheckImports)
 
+	case "unusedSymbols":
+		return setEnum(&o.UnusedSymbols, value,
+			UnusedSymbolsOff,
+			UnusedSymbolsUnexported,
+			UnusedSymbolsAll)
+
+	case "unusedSymbolsIgnoreTests":
+		return setBool(&o.UnusedSymbolsIgnoreTests, value)
+
 	case "codelenses", "codelens":
 		lensOverrides, err := asBoolMap[CodeLensSource](value)
 		if err != nil {
@@ -1114,6 +1229,9 @@ func (o *Options) setOne(name string, value any) error {
 	case "completeFunctionCalls":
 		return setBool(&o.CompleteFunctionCalls, value)
 
+	case "inlineCompletionCommand":
+		return setStringSlice(&o.InlineCompletionCommand, value)
+
 	case "semanticTokens":
 		return setBool(&o.SemanticTokens, value)
 
@@ -1129,6 +1247,9 @@ func (o *Options) setOne(name string, value any) error {
 		// behavior in that case to *not* expand to the module.
 		return setBool(&o.ExpandWorkspaceToModule, value)
 
+	case "loadOnlyOpenDirectories":
+		return setBool(&o.LoadOnlyOpenDirectories, value)
+
 	case "experimentalPostfixCompletions":
 		return setBool(&o.ExperimentalPostfixCompletions, value)
 
@@ -197,6 +197,41 @@ func TestOptions_Set(t *testing.T) {
 				return o.Vulncheck == ModeVulncheckImports
 			},
 		},
+		{
+			name:  "unusedSymbols",
+			value: "unexported",
+			check: func(o Options) bool {
+				return o.UnusedSymbols == UnusedSymbolsUnexported
+			},
+		},
+		{
+			name:      "unusedSymbols",
+			value:     "exported",
+			wantError: true,
+			check: func(o Options) bool {
+				return o.UnusedSymbols == ""
+			},
+		},
+		{
+			name: "analysisConfig",
+			value: map[string]any{
+				"printf":   map[string]any{"wrappers": []any{map[string]any{"func": "log.Infof", "kind": "printf"}}},
+				"external": map[string]any{"unknown": true}, // not a gopls analyzer
+			},
+			check: func(o Options) bool {
+				return string(o.AnalysisConfig["printf"]) == `{"wrappers":[{"func":"log.Infof","kind":"printf"}]}`
+			},
+		},
+		{
+			name: "analysisConfig",
+			value: map[string]any{
+				"printf": map[string]any{"unknown": true},
+			},
+			wantError: true,
+			check: func(o Options) bool {
+				return o.AnalysisConfig == nil
+			},
+		},
 	}
 
 	for _, test := range tests {
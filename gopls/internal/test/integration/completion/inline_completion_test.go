@@ -0,0 +1,127 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"runtime"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+// inlineCompletion returns the text of the inline completions
+// at the end of the first match of re in the named file.
+func inlineCompletion(t *testing.T, env *Env, name, re string) []string {
+	t.Helper()
+	loc := env.RegexpSearch(name, re)
+	var params protocol.InlineCompletionParams
+	params.TextDocument.URI = loc.URI
+	params.Position = loc.Range.End
+	result, err := env.Editor.Server.InlineCompletion(env.Ctx, &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, item := range result.Value.(protocol.InlineCompletionList).Items {
+		texts = append(texts, item.InsertText.Value.(string))
+	}
+	return texts
+}
+
+func TestInlineCompletion(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- a.go --
+package a
+
+import "os"
+
+func f() (*os.File, int, error) {
+	f, err := os.Open("f")
+	if
+	return f, 0, nil
+}
+-- b.go --
+package a
+
+import "os"
+
+func g() error {
+	if _, err := os.Stat("g"); err != nil {
+		ret
+	}
+	return nil
+}
+-- c.go --
+package a
+
+import "os"
+
+func h() {
+	_, err := os.Open("h")
+	if
+	_ = err
+}
+-- a_test.go --
+package a
+
+import (
+	"os"
+	"testing"
+)
+
+func TestA(t *testing.T) {
+	_, err := os.Open("a")
+	i
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		for _, test := range []struct {
+			name, re string
+			want     []string
+		}{
+			{"a.go", `os.Open\("f"\)\n\tif`, []string{"if err != nil {\n\t\treturn nil, 0, err\n\t}"}},
+			{"b.go", `\tret`, []string{"return err"}},
+			{"c.go", `os.Open\("h"\)\n\tif`, nil}, // h does not return an error
+			{"a_test.go", `\ti`, []string{"if err != nil {\n\t\tt.Fatal(err)\n\t}"}},
+		} {
+			env.OpenFile(test.name)
+			got := inlineCompletion(t, env, test.name, test.re)
+			if len(got) != len(test.want) || len(got) > 0 && got[0] != test.want[0] {
+				t.Errorf("inline completion at %q in %s = %q, want %q", test.re, test.name, got, test.want)
+			}
+		}
+	})
+}
+
+func TestInlineCompletionCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses sh")
+	}
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- a.go --
+package a
+
+var x = 1
+`
+	const output = `{"items": [{"insertText": "from the command"}]}`
+	WithOptions(
+		Settings{"inlineCompletionCommand": []any{"sh", "-c", "cat >/dev/null; echo '" + output + "'"}},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a.go")
+		got := inlineCompletion(t, env, "a.go", `var x = 1`)
+		if len(got) != 1 || got[0] != "from the command" {
+			t.Errorf("inline completion = %q, want the output of the command", got)
+		}
+	})
+}
@@ -1241,6 +1241,55 @@ func main() {
 	})
 }
 
+// This test verifies that with loadOnlyOpenDirectories, the workspace is
+// limited to the directories of open files, and expands as files are
+// opened.
+func TestLoadOnlyOpenDirectories(t *testing.T) {
+	const mod = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+func _() {
+	var x int
+}
+-- a/sub/sub.go --
+package sub
+
+func _() {
+	var y int
+}
+-- b/b.go --
+package b
+
+func _() {
+	var z int
+}
+`
+	WithOptions(
+		Settings{"loadOnlyOpenDirectories": true},
+	).Run(t, mod, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("a/a.go", "x")),
+			Diagnostics(env.AtRegexp("a/sub/sub.go", "y")),
+			NoDiagnostics(ForFile("b/b.go")),
+		)
+		env.OpenFile("b/b.go")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("b/b.go", "z")),
+		)
+		env.CloseBuffer("b/b.go")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("a/sub/sub.go", "y")),
+			NoDiagnostics(ForFile("b/b.go")),
+		)
+	})
+}
+
 // This test verifies that the workspace scope is effectively limited to the
 // set of active modules.
 //
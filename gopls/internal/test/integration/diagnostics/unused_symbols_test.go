@@ -0,0 +1,119 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"testing"
+
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestUnusedSymbols(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- a/a.go --
+package a
+
+func used() {}
+
+func unused() {}
+
+func testOnly() {}
+
+type T int
+
+func (T) unusedMethod() {}
+
+func Exported() {}
+
+func ExportedUnused() {}
+
+func usedElsewhere() {}
+
+func ExportedUsedElsewhere() {}
+
+var _ = used
+-- a/a_other.go --
+//go:build other
+
+package a
+
+var _ = usedElsewhere
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestA(t *testing.T) { testOnly() }
+-- b/b.go --
+package b
+
+import "mod.com/a"
+
+var _ = a.Exported
+-- b/b_other.go --
+//go:build other
+
+package b
+
+import "mod.com/a"
+
+var _ = a.ExportedUsedElsewhere
+`
+	type want struct {
+		re     string
+		unused bool
+	}
+	for _, test := range []struct {
+		name     string
+		settings Settings
+		want     []want
+	}{
+		{"off", Settings{}, []want{
+			{"unused", false},
+		}},
+		{"unexported", Settings{"unusedSymbols": "Unexported"}, []want{
+			{"used", false},
+			{"unused", true},
+			{"testOnly", false},
+			{"T int", false},
+			{"unusedMethod", false},
+			{"ExportedUnused", false},
+			{"usedElsewhere", false}, // in a file excluded from the build
+		}},
+		{"all", Settings{"unusedSymbols": "All"}, []want{
+			{"unused", true},
+			{"testOnly", false},
+			{"Exported", false},
+			{"ExportedUnused", true},
+			{"ExportedUsedElsewhere", false},
+		}},
+		{"ignoreTests", Settings{"unusedSymbols": "All", "unusedSymbolsIgnoreTests": true}, []want{
+			{"used", false},
+			{"testOnly", true},
+			{"T int", false}, // used by the method
+			{"Exported", false},
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			WithOptions(test.settings).Run(t, files, func(t *testing.T, env *Env) {
+				env.OpenFile("a/a.go")
+				var expectations []Expectation
+				for _, w := range test.want {
+					loc := env.AtRegexp("a/a.go", `\b`+w.re)
+					if w.unused {
+						expectations = append(expectations, Diagnostics(loc, WithMessage("unused")))
+					} else {
+						expectations = append(expectations, NoDiagnostics(loc))
+					}
+				}
+				env.AfterChange(expectations...)
+			})
+		})
+	}
+}
@@ -572,3 +572,100 @@ func fileLocations(env *integration.Env, locs []protocol.Location) []string {
 	sort.Strings(got)
 	return got
 }
+
+// Test an 'implementation' query that finds types declared in a
+// dependency, which is type-checked only to be imported by the
+// workspace package, and whose method-set index is therefore read
+// from the cache warmed up by that type check. (The query has the
+// same result if the cache is cold; only its cost differs.)
+func TestImplementationsInDependencies(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+go 1.12
+
+-- a.go --
+package a
+
+import "strings"
+
+type RuneSeeker interface {
+	ReadRune() (rune, int, error)
+	UnreadRune() error
+	Seek(offset int64, whence int) (int64, error)
+}
+
+var _ = strings.NewReader
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a.go")
+		env.AfterChange(NoDiagnostics(ForFile("a.go")))
+
+		impls := env.Implementations(env.RegexpSearch("a.go", "RuneSeeker"))
+		got := fileLocations(env, impls)
+		if want := []string{"std:strings/reader.go"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Implementations(RuneSeeker) = %q, want %q", got, want)
+		}
+	})
+}
+
+// Test that references are updated as the files of a referring
+// package are edited, including when the edited file changes the
+// meaning of references in another, unchanged file.
+func TestReferencesAfterEdits(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+go 1.12
+
+-- lib/lib.go --
+package lib
+
+type T int
+
+func (T) M() {}
+
+type U int
+
+func (U) M() {}
+
+-- b/decl.go --
+package b
+
+import "example.com/lib"
+
+var X lib.T
+
+-- b/use.go --
+package b
+
+func f() {
+	X.M()
+}
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("lib/lib.go")
+		env.OpenFile("b/decl.go")
+		env.OpenFile("b/use.go")
+		refs := func() []string {
+			return fileLocations(env, env.References(env.RegexpSearch("lib/lib.go", `\(T\) (M)`)))
+		}
+		if got, want := refs(), []string{"b/use.go:4", "lib/lib.go:5"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("initial References = %q, want %q", got, want)
+		}
+
+		// An edit within a function body.
+		env.RegexpReplace("b/use.go", `X.M\(\)`, "X.M()\n\tX.M()")
+		env.AfterChange(NoDiagnostics(ForFile("b/use.go")))
+		if got, want := refs(), []string{"b/use.go:4", "b/use.go:5", "lib/lib.go:5"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("References after editing use.go = %q, want %q", got, want)
+		}
+
+		// An edit to a declaration used by the other file.
+		env.RegexpReplace("b/decl.go", `lib.T`, "lib.U")
+		env.AfterChange(NoDiagnostics(ForFile("b/decl.go")))
+		if got, want := refs(), []string{"lib/lib.go:5"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("References after editing decl.go = %q, want %q", got, want)
+		}
+	})
+}
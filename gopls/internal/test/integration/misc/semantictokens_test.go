@@ -193,6 +193,119 @@ func bar() {}
 	})
 }
 
+func TestSemanticDirectiveArgs(t *testing.T) {
+	src := `
+-- go.mod --
+module example.com
+
+go 1.19
+-- main.go --
+//go:build linux && !(386 || arm)
+
+package foo
+
+import _ "embed"
+
+//go:generate stringer -type "Kind"
+
+//go:embed a.txt "b c.txt"
+var s string
+
+//go:build )bad
+`
+	want := []fake.SemanticToken{
+		{Token: "//", TokenType: "comment"},
+		{Token: "go:build", TokenType: "namespace"},
+		{Token: "linux", TokenType: "macro"},
+		{Token: "&&", TokenType: "operator"},
+		{Token: "!", TokenType: "operator"},
+		{Token: "(", TokenType: "operator"},
+		{Token: "386", TokenType: "macro"},
+		{Token: "||", TokenType: "operator"},
+		{Token: "arm", TokenType: "macro"},
+		{Token: ")", TokenType: "operator"},
+
+		{Token: "package", TokenType: "keyword"},
+		{Token: "foo", TokenType: "namespace"},
+		{Token: "import", TokenType: "keyword"},
+
+		{Token: "//", TokenType: "comment"},
+		{Token: "go:generate", TokenType: "namespace"},
+		{Token: "stringer", TokenType: "function"},
+		{Token: "-type", TokenType: "string"},
+		{Token: `"Kind"`, TokenType: "string"},
+
+		{Token: "//", TokenType: "comment"},
+		{Token: "go:embed", TokenType: "namespace"},
+		{Token: "a.txt", TokenType: "string"},
+		{Token: `"b c.txt"`, TokenType: "string"},
+		{Token: "var", TokenType: "keyword"},
+		{Token: "s", TokenType: "variable", Mod: "definition string"},
+		{Token: "string", TokenType: "type", Mod: "defaultLibrary string"},
+
+		{Token: "//", TokenType: "comment"},
+		{Token: "go:build", TokenType: "namespace"},
+		{Token: ")bad", TokenType: "comment"},
+	}
+
+	WithOptions(
+		Modes(Default),
+		Settings{"semanticTokens": true},
+	).Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		seen := env.SemanticTokensFull("main.go")
+		if x := cmp.Diff(want, seen); x != "" {
+			t.Errorf("Semantic tokens do not match (-want +got):\n%s", x)
+		}
+	})
+}
+
+func TestSemanticStructTags(t *testing.T) {
+	src := `
+-- go.mod --
+module example.com
+
+go 1.19
+-- main.go --
+package foo
+
+type T struct {
+	A int ` + "`json:\"a,omitempty\" db:\"x y\"`" + `
+	B int ` + "`not a tag`" + `
+}
+`
+	want := []fake.SemanticToken{
+		{Token: "package", TokenType: "keyword"},
+		{Token: "foo", TokenType: "namespace"},
+		{Token: "type", TokenType: "keyword"},
+		{Token: "T", TokenType: "type", Mod: "definition struct"},
+		{Token: "struct", TokenType: "keyword"},
+
+		{Token: "A", TokenType: "variable", Mod: "definition number"},
+		{Token: "int", TokenType: "type", Mod: "defaultLibrary number"},
+		{Token: "`", TokenType: "string"},
+		{Token: "json", TokenType: "property"},
+		{Token: `:"a,omitempty" `, TokenType: "string"},
+		{Token: "db", TokenType: "property"},
+		{Token: `:"x y"` + "`", TokenType: "string"},
+
+		{Token: "B", TokenType: "variable", Mod: "definition number"},
+		{Token: "int", TokenType: "type", Mod: "defaultLibrary number"},
+		{Token: "`not a tag`", TokenType: "string"},
+	}
+
+	WithOptions(
+		Modes(Default),
+		Settings{"semanticTokens": true},
+	).Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		seen := env.SemanticTokensFull("main.go")
+		if x := cmp.Diff(want, seen); x != "" {
+			t.Errorf("Semantic tokens do not match (-want +got):\n%s", x)
+		}
+	})
+}
+
 // Make sure no zero-length tokens occur
 func TestSemantic_65254(t *testing.T) {
 	src := `
@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestTypeHierarchy(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- lib/lib.go --
+package lib
+
+type Reader interface { Read() }
+
+type ReadCloser interface {
+	Reader
+	Close()
+}
+-- a/a.go --
+package a
+
+import "mod.com/lib"
+
+type File struct{}
+
+func (File) Read()  {}
+func (File) Close() {}
+
+type Pipe int
+
+func (Pipe) Read() {}
+
+var _ lib.ReadCloser = File{}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		prepare := func(path, re string) protocol.TypeHierarchyItem {
+			t.Helper()
+			loc := env.RegexpSearch(path, re)
+			var params protocol.TypeHierarchyPrepareParams
+			params.TextDocument.URI = loc.URI
+			params.Position = loc.Range.Start
+			items, err := env.Editor.Server.PrepareTypeHierarchy(env.Ctx, &params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != 1 {
+				t.Fatalf("PrepareTypeHierarchy(%s) returned %d items, want 1", re, len(items))
+			}
+			return items[0]
+		}
+		names := func(items []protocol.TypeHierarchyItem) string {
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			sort.Strings(names)
+			return strings.Join(names, " ")
+		}
+
+		file := prepare("a/a.go", "type (File)")
+		if file.Name != "File" || file.Kind != protocol.Struct || file.Detail != "mod.com/a • a.go" {
+			t.Errorf("PrepareTypeHierarchy(File) = %s (kind %v, detail %q)", file.Name, file.Kind, file.Detail)
+		}
+		supers, err := env.Editor.Server.Supertypes(env.Ctx, &protocol.TypeHierarchySupertypesParams{Item: file})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := names(supers), "ReadCloser Reader"; got != want {
+			t.Errorf("Supertypes(File) = %s, want %s", got, want)
+		}
+
+		// Expand the hierarchy into the lib package.
+		var reader protocol.TypeHierarchyItem
+		for _, item := range supers {
+			if item.Name == "Reader" {
+				reader = item
+			}
+		}
+		if reader.Kind != protocol.Interface {
+			t.Errorf("kind of Reader = %v, want Interface", reader.Kind)
+		}
+		subs, err := env.Editor.Server.Subtypes(env.Ctx, &protocol.TypeHierarchySubtypesParams{Item: reader})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := names(subs), "File Pipe"; got != want {
+			t.Errorf("Subtypes(Reader) = %s, want %s", got, want)
+		}
+
+		readCloser := prepare("a/a.go", "lib.(ReadCloser)")
+		supers, err = env.Editor.Server.Supertypes(env.Ctx, &protocol.TypeHierarchySupertypesParams{Item: readCloser})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := names(supers), "Reader"; got != want {
+			t.Errorf("Supertypes(ReadCloser) = %s, want %s", got, want)
+		}
+	})
+}
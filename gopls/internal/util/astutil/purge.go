@@ -72,3 +72,63 @@ func PurgeFuncBodies(src []byte) []byte {
 	out.Write(src[cursor:])
 	return out.Bytes()
 }
+
+// BlankFuncBodies returns a copy of src in which the contents of the
+// body of each function declaration have been replaced by spaces,
+// except for newlines. Unlike [PurgeFuncBodies], it preserves the
+// positions of the remaining declarations, and the values of their
+// initializers, so a file parsed from the result has the same
+// declarations as src, at the same positions, but without statements.
+func BlankFuncBodies(src []byte) []byte {
+	out := bytes.Clone(src)
+	file := token.NewFileSet().AddFile("", -1, len(src))
+	var sc scanner.Scanner
+	sc.Init(file, src, nil, 0)
+	var (
+		prev     token.Token
+		inFunc   bool        // within a top-level func declaration
+		braces   []token.Pos // stack of unclosed braces or -1 for struct/interface type
+		bodyFrom = -1        // offset of the open brace of the current body, or -1
+	)
+	for {
+		pos, tok, _ := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch tok {
+		case token.FUNC:
+			if len(braces) == 0 && (prev == token.SEMICOLON || prev == token.ILLEGAL) {
+				inFunc = true
+			}
+
+		case token.SEMICOLON:
+			if len(braces) == 0 {
+				inFunc = false
+			}
+
+		case token.LBRACE:
+			if prev == token.STRUCT || prev == token.INTERFACE {
+				pos = -1
+			} else if inFunc && len(braces) == 0 {
+				bodyFrom, _ = safetoken.Offset(file, pos)
+			}
+			braces = append(braces, pos)
+
+		case token.RBRACE:
+			if last := len(braces) - 1; last >= 0 {
+				braces = braces[:last]
+				if last == 0 && bodyFrom >= 0 {
+					end, _ := safetoken.Offset(file, pos)
+					for i := bodyFrom + len("{"); i < end; i++ {
+						if out[i] != '\n' {
+							out[i] = ' '
+						}
+					}
+					bodyFrom = -1
+				}
+			}
+		}
+		prev = tok
+	}
+	return out
+}
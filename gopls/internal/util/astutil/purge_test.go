@@ -87,3 +87,68 @@ func TestPurgeFuncBodies(t *testing.T) {
 		}
 	})
 }
+
+// TestBlankFuncBodies tests BlankFuncBodies by comparing it against a
+// reference implementation that purges after parsing. Unlike
+// PurgeFuncBodies, it must preserve positions.
+func TestBlankFuncBodies(t *testing.T) {
+	testenv.NeedsGoBuild(t) // we need the source code for std
+
+	config := packages.Config{Mode: packages.NeedCompiledGoFiles}
+	pkgs, err := packages.Load(&config, "encoding/...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, filename := range p.CompiledGoFiles {
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Parse then purge (reference implementation).
+			fset1 := token.NewFileSet()
+			f1, _ := parser.ParseFile(fset1, filename, content, parser.SkipObjectResolution)
+			for _, decl := range f1.Decls {
+				if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+					decl.Body.List = nil
+				}
+			}
+
+			// Blank before parse (logic under test).
+			fset2 := token.NewFileSet()
+			f2, _ := parser.ParseFile(fset2, filename, astutil.BlankFuncBodies(content), parser.SkipObjectResolution)
+
+			// Compare sequence of nodes and their positions.
+			var nodes1, nodes2 []ast.Node
+			ast.Inspect(f1, func(n ast.Node) bool {
+				if n != nil {
+					nodes1 = append(nodes1, n)
+				}
+				return true
+			})
+			ast.Inspect(f2, func(n ast.Node) bool {
+				if n != nil {
+					nodes2 = append(nodes2, n)
+				}
+				return true
+			})
+			if len(nodes1) != len(nodes2) {
+				t.Errorf("%s: blanked file has %d nodes, want %d", filename, len(nodes2), len(nodes1))
+				continue
+			}
+			for i := range nodes1 {
+				x, y := nodes1[i], nodes2[i]
+				if reflect.TypeOf(x) != reflect.TypeOf(y) {
+					t.Errorf("%s: got %T, want %T", fset1.Position(x.Pos()), y, x)
+					break
+				}
+				if px, py := fset1.Position(x.Pos()), fset2.Position(y.Pos()); px != py {
+					t.Errorf("%T at %s, want %s", y, py, px)
+					break
+				}
+			}
+		}
+	})
+}
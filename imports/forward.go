@@ -12,6 +12,7 @@ import (
 
 	"golang.org/x/tools/internal/gocommand"
 	intimp "golang.org/x/tools/internal/imports"
+	"golang.org/x/tools/internal/stdlib"
 )
 
 // Options specifies options for processing files.
@@ -24,6 +25,88 @@ type Options struct {
 	TabWidth  int  // Tab width (8 if nil *Options provided)
 
 	FormatOnly bool // Disable the insertion and deletion of imports
+
+	// Strict enables a stricter formatting profile, compatible with
+	// gofumpt: all the imports of a declaration are reordered into
+	// groups (the standard library, third-party packages, and packages
+	// matching LocalPrefix), with no blank lines within a group, and
+	// import names that merely repeat the package's name are removed.
+	Strict bool
+
+	// Ranker, if non-nil, determines the order of preference among
+	// the packages that could satisfy a missing import.
+	Ranker Ranker
+
+	// Stdlib, if non-nil, replaces the built-in list of the packages of
+	// the standard library, which is that of the latest release of the
+	// gc toolchain. It maps the import path of each standard package to
+	// the names of its exported package-level symbols. Toolchains such
+	// as gccgo and TinyGo, whose standard libraries differ, may use it
+	// to import the correct packages.
+	Stdlib map[string][]string
+
+	// VendorPolicy determines how packages in vendor directories
+	// are treated when satisfying missing imports.
+	VendorPolicy VendorPolicy
+}
+
+// A VendorPolicy determines how packages in vendor directories are
+// treated when satisfying missing imports. Regardless of the policy,
+// a vendored package is used only where it is visible according to
+// the rules of the go command.
+type VendorPolicy int
+
+const (
+	VendorDefault VendorPolicy = iota // consider vendored packages like any other
+	VendorPrefer                      // prefer vendored packages to others
+	VendorExclude                     // never use vendored packages
+)
+
+// A Candidate is a package that may satisfy a missing import.
+type Candidate struct {
+	ImportPath string  // the package's import path, without any vendor prefix
+	Dir        string  // the package's directory
+	Distance   int     // number of directories between the importing file and Dir, or -1 if unrelated
+	Relevance  float64 // an estimate of relevance, highest for the current module and its direct dependencies
+}
+
+// A Ranker determines the order in which Process considers the
+// packages that could satisfy a missing import, for example to prefer
+// an organization's internal fork over a public package of the same
+// name, or to exclude some packages entirely. The first candidate that
+// provides all the referenced symbols is chosen.
+//
+// Packages of the standard library are not ranked: if they provide
+// all the missing symbols, they are chosen without a search.
+//
+// Rank may be called concurrently, for the different package names
+// referenced by a file, so implementations must be safe for
+// concurrent use.
+type Ranker interface {
+	// Rank returns the candidates for an import of a package named
+	// pkgName into the specified file, in order of preference. It may
+	// reorder the candidates slice in place, and may omit candidates
+	// that must not be used.
+	//
+	// The candidates are initially sorted by increasing Distance,
+	// then by length of import path.
+	Rank(filename, pkgName string, candidates []Candidate) []Candidate
+}
+
+// rankerAdapter adapts a Ranker to the internal interface.
+type rankerAdapter struct{ Ranker }
+
+func (r rankerAdapter) Rank(filename, pkgName string, candidates []intimp.Candidate) []intimp.Candidate {
+	cands := make([]Candidate, len(candidates))
+	for i, c := range candidates {
+		cands[i] = Candidate(c)
+	}
+	cands = r.Ranker.Rank(filename, pkgName, cands)
+	res := make([]intimp.Candidate, len(cands))
+	for i, c := range cands {
+		res[i] = intimp.Candidate(c)
+	}
+	return res
 }
 
 // Debug controls verbose logging.
@@ -49,6 +132,30 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 			return nil, err
 		}
 	}
+	return intimp.Process(filename, src, internalOptions(opt))
+}
+
+// AddImport adds an import of the package with the specified path to
+// the provided file, unless it already imports the package, and
+// returns the formatted file and the name by which it may refer to the
+// package. Unlike Process, AddImport neither adds nor removes other
+// imports. If opt is nil the defaults are used.
+//
+// If the file already imports the package, other than by a blank or
+// dot import, the name is that of the existing import. Otherwise, it
+// is name if non-empty, or else the declared name of the package,
+// found in the context of filename's directory if possible. If that
+// name is already in use in the file, a numeric suffix is added to it,
+// as in "errors1". The import is named explicitly if the name differs
+// from the declared name of the package or from the one implied by the
+// import path.
+func AddImport(filename string, src []byte, importPath, name string, opt *Options) (formatted []byte, localName string, err error) {
+	return intimp.EnsureImport(filename, src, importPath, name, internalOptions(opt))
+}
+
+// internalOptions returns the internal form of opt,
+// or of the default options if opt is nil.
+func internalOptions(opt *Options) *intimp.Options {
 	if opt == nil {
 		opt = &Options{Comments: true, TabIndent: true, TabWidth: 8}
 	}
@@ -60,14 +167,37 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 		AllErrors:   opt.AllErrors,
 		Comments:    opt.Comments,
 		FormatOnly:  opt.FormatOnly,
+		Strict:      opt.Strict,
 		Fragment:    opt.Fragment,
 		TabIndent:   opt.TabIndent,
 		TabWidth:    opt.TabWidth,
 	}
+	if opt.Stdlib != nil {
+		std := make(map[string][]stdlib.Symbol, len(opt.Stdlib))
+		for path, names := range opt.Stdlib {
+			syms := make([]stdlib.Symbol, len(names))
+			for i, name := range names {
+				// Process needs only the names of symbols,
+				// so the kind of each is immaterial.
+				syms[i] = stdlib.Symbol{Name: name, Kind: stdlib.Var}
+			}
+			std[path] = syms
+		}
+		intopt.Env.Stdlib = std
+	}
+	switch opt.VendorPolicy {
+	case VendorPrefer:
+		intopt.Env.VendorPolicy = intimp.VendorPrefer
+	case VendorExclude:
+		intopt.Env.VendorPolicy = intimp.VendorExclude
+	}
+	if opt.Ranker != nil {
+		intopt.Env.Ranker = rankerAdapter{opt.Ranker}
+	}
 	if Debug {
 		intopt.Env.Logf = log.Printf
 	}
-	return intimp.Process(filename, src, intopt)
+	return intopt
 }
 
 // VendorlessPath returns the devendorized version of the import path ipath.
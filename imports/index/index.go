@@ -0,0 +1,197 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package index provides access to the index of exported symbols of
+// the packages in the Go module cache, which goimports uses to resolve
+// references to packages that are not yet imported.
+//
+// The index is stored in the user's cache directory, where it is shared
+// by all the tools that use it. Building it for the first time may take
+// a long time for a large module cache, but subsequent updates are
+// incremental: only the directories added to the module cache since the
+// last update are scanned.
+//
+// Example:
+//
+//	gomodcache := ... // output of "go env GOMODCACHE"
+//	ix, err := index.Open(gomodcache)
+//	...
+//	for _, sym := range ix.Lookup("yaml", "Marshal") {
+//		fmt.Println(sym.ImportPath, sym.Version)
+//	}
+package index // import "golang.org/x/tools/imports/index"
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/internal/modindex"
+)
+
+// An Index is the index of a Go module cache.
+// It is safe for concurrent use.
+type Index struct {
+	cachedir string
+
+	mu     sync.Mutex
+	ix     *modindex.Index
+	byPath map[string]*modindex.Entry // entries by import path
+}
+
+// Kind is the kind of a symbol.
+type Kind int8
+
+const (
+	Const Kind = Kind(modindex.Const)
+	Var   Kind = Kind(modindex.Var)
+	Type  Kind = Kind(modindex.Type)
+	Func  Kind = Kind(modindex.Func)
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Const:
+		return "const"
+	case Var:
+		return "var"
+	case Type:
+		return "type"
+	case Func:
+		return "func"
+	}
+	return fmt.Sprintf("Kind(%d)", int8(k))
+}
+
+// A Symbol is an exported package-level symbol of a package
+// in the module cache.
+type Symbol struct {
+	Name       string // the symbol's name, such as "Marshal"
+	Kind       Kind
+	PkgName    string // the declared name of the package, such as "yaml"
+	ImportPath string // the package's import path, such as "gopkg.in/yaml.v3"
+	Version    string // the version of the module providing the package
+	Dir        string // the package's directory
+
+	// For functions only:
+	Results int     // the number of results
+	Params  []Param // the parameters
+}
+
+// A Param is a parameter of a function.
+type Param struct {
+	Name string // "_" if unnamed
+	Type string // the type, as it appears in the source
+}
+
+// Open returns the index of the module cache in the directory
+// cachedir (typically the output of "go env GOMODCACHE"), first
+// creating or updating the index as necessary.
+func Open(cachedir string) (*Index, error) {
+	cachedir, err := filepath.Abs(cachedir)
+	if err != nil {
+		return nil, err
+	}
+	ix := &Index{cachedir: cachedir}
+	if _, err := ix.Refresh(); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Refresh updates the index to account for any directories that have
+// been added to the module cache since the index was last updated,
+// by this or another process. It reports whether it reloaded the index.
+// Because directory modification times have limited precision, it may
+// occasionally reload an index whose contents are unchanged.
+func (ix *Index) Refresh() (changed bool, err error) {
+	wrote, err := modindex.Update(ix.cachedir)
+	if err != nil {
+		return false, err
+	}
+	data, err := modindex.ReadIndex(ix.cachedir)
+	if err != nil {
+		return false, err
+	}
+	if data == nil {
+		return false, fmt.Errorf("no index for module cache %s", ix.cachedir)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.ix != nil && !wrote && data.Changed.Equal(ix.ix.Changed) {
+		return false, nil // no change by this or another process
+	}
+	ix.ix = data
+	ix.byPath = make(map[string]*modindex.Entry, len(data.Entries))
+	for i := range data.Entries {
+		e := &data.Entries[i]
+		ix.byPath[e.ImportPath] = e
+	}
+	return true, nil
+}
+
+// Updated returns the time as of which the index is up to date.
+func (ix *Index) Updated() time.Time {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.ix.Changed
+}
+
+// Lookup returns the symbols with the specified name in packages
+// with the specified package name, such as ("yaml", "Marshal").
+// A module cache usually contains many packages of the same name,
+// so there may be many results, in no particular order.
+func (ix *Index) Lookup(pkgName, name string) []Symbol {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.symbols(ix.ix.Lookup(pkgName, name, false))
+}
+
+// LookupPrefix is like Lookup, but returns all the symbols
+// whose names start with the specified prefix.
+func (ix *Index) LookupPrefix(pkgName, prefix string) []Symbol {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.symbols(ix.ix.Lookup(pkgName, prefix, true))
+}
+
+// LookupPackage returns the symbols of the package with the specified
+// import path, in order of name, or nil if the module cache contains
+// no such package. If the module cache contains several versions of
+// the package, the symbols are those of the latest.
+func (ix *Index) LookupPackage(importPath string) []Symbol {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	e, ok := ix.byPath[importPath]
+	if !ok {
+		return nil
+	}
+	return ix.symbols(e.Symbols())
+}
+
+// symbols converts candidates to symbols.
+// Callers must hold ix.mu.
+func (ix *Index) symbols(cands []modindex.Candidate) []Symbol {
+	var syms []Symbol
+	for _, c := range cands {
+		sym := Symbol{
+			Name:       c.Name,
+			Kind:       Kind(c.Type),
+			PkgName:    c.PkgName,
+			ImportPath: c.ImportPath,
+			Dir:        filepath.Join(ix.cachedir, c.Dir),
+			Results:    int(c.Results),
+		}
+		if e, ok := ix.byPath[c.ImportPath]; ok {
+			sym.Version = e.Version
+		}
+		for _, f := range c.Sig {
+			sym.Params = append(sym.Params, Param{Name: f.Arg, Type: f.Type})
+		}
+		syms = append(syms, sym)
+	}
+	return syms
+}
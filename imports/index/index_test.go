@@ -0,0 +1,68 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package index_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/imports/index"
+	"golang.org/x/tools/internal/modindex"
+)
+
+func TestIndex(t *testing.T) {
+	indexDir := t.TempDir()
+	modindex.IndexDir = func() (string, error) { return indexDir, nil }
+	cachedir := t.TempDir()
+
+	addPkg := func(dir, src string) {
+		t.Helper()
+		dir = filepath.Join(cachedir, dir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "x.go"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addPkg("example.com/foo@v1.2.0", "package foo\nfunc Foo(x int, s string) error { return nil }\nconst C = 1\ntype T int\n")
+
+	ix, err := index.Open(cachedir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syms := ix.Lookup("foo", "Foo")
+	if len(syms) != 1 {
+		t.Fatalf("Lookup(foo, Foo) returned %d symbols, want 1", len(syms))
+	}
+	sym := syms[0]
+	if sym.ImportPath != "example.com/foo" || sym.Version != "v1.2.0" || sym.Kind != index.Func ||
+		sym.Results != 1 || len(sym.Params) != 2 || sym.Params[1] != (index.Param{Name: "s", Type: "string"}) ||
+		sym.Dir != filepath.Join(cachedir, "example.com/foo@v1.2.0") {
+		t.Errorf("Lookup(foo, Foo) = %+v", sym)
+	}
+
+	var names []string
+	for _, sym := range ix.LookupPackage("example.com/foo") {
+		names = append(names, sym.Kind.String()+" "+sym.Name)
+	}
+	if got, want := fmt.Sprint(names), "[const C func Foo type T]"; got != want {
+		t.Errorf("LookupPackage = %s, want %s", got, want)
+	}
+	if syms := ix.LookupPackage("example.com/bar"); syms != nil {
+		t.Errorf("LookupPackage(example.com/bar) = %v, want nil", syms)
+	}
+
+	// Add a package and refresh.
+	addPkg("example.com/bar@v0.1.0", "package bar\nvar Bar int\n")
+	if changed, err := ix.Refresh(); err != nil || !changed {
+		t.Fatalf("Refresh() = %v, %v, want true, nil", changed, err)
+	}
+	if syms := ix.LookupPrefix("bar", "B"); len(syms) != 1 || syms[0].Kind != index.Var {
+		t.Errorf("LookupPrefix(bar, B) = %v, want var Bar", syms)
+	}
+}
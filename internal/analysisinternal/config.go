@@ -0,0 +1,56 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisinternal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ParseConfig parses the JSON configuration shared by the analysis
+// drivers: an object whose fields, keyed by analyzer name, hold the
+// settings of each analyzer (see [analysis.Analyzer.Config]).
+func ParseConfig(data []byte) (map[string]json.RawMessage, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// MakeConfig returns the value of [analysis.Pass.Config] for
+// analyzer a given its settings, which may be empty. Unless they are
+// empty, it decodes the settings into a fresh copy of *a.Config;
+// unknown fields are an error.
+func MakeConfig(a *analysis.Analyzer, settings json.RawMessage) (any, error) {
+	if len(settings) == 0 {
+		return a.Config, nil
+	}
+	if a.Config == nil {
+		return nil, fmt.Errorf("analyzer %s has no settings", a.Name)
+	}
+
+	// Copy the defaults by a round trip through JSON,
+	// so that the copy shares no slices or maps with them.
+	defaults, err := json.Marshal(a.Config)
+	if err != nil {
+		return nil, fmt.Errorf("analyzer %s: encoding default settings: %v", a.Name, err)
+	}
+	config := reflect.New(reflect.TypeOf(a.Config).Elem()).Interface()
+	if err := json.Unmarshal(defaults, config); err != nil {
+		return nil, fmt.Errorf("analyzer %s: decoding default settings: %v", a.Name, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(settings))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid settings for analyzer %s: %v", a.Name, err)
+	}
+	return config, nil
+}
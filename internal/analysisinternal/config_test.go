@@ -0,0 +1,56 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisinternal_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+func TestMakeConfig(t *testing.T) {
+	type settings struct {
+		Names []string `json:"names"`
+		Max   int      `json:"max"`
+	}
+	defaults := &settings{Names: []string{"a"}, Max: 1}
+	a := &analysis.Analyzer{Name: "a", Config: defaults}
+
+	config, err := analysisinternal.ParseConfig([]byte(`{"a": {"names": ["b", "c"]}, "other": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No settings: the defaults.
+	got, err := analysisinternal.MakeConfig(a, nil)
+	if err != nil || got != defaults {
+		t.Errorf("MakeConfig(nil) = %v, %v; want defaults", got, err)
+	}
+
+	// Settings are decoded over a copy of the defaults.
+	got, err = analysisinternal.MakeConfig(a, config["a"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&settings{Names: []string{"b", "c"}, Max: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("MakeConfig = %+v, want %+v", got, want)
+	}
+	if want := (&settings{Names: []string{"a"}, Max: 1}); !reflect.DeepEqual(defaults, want) {
+		t.Errorf("defaults were modified: %+v", defaults)
+	}
+
+	// Unknown fields are an error.
+	if _, err := analysisinternal.MakeConfig(a, []byte(`{"nmes": []}`)); err == nil || !strings.Contains(err.Error(), "nmes") {
+		t.Errorf("MakeConfig with unknown field: got error %v", err)
+	}
+
+	// So are settings for an analyzer without any.
+	if _, err := analysisinternal.MakeConfig(&analysis.Analyzer{Name: "b"}, config["other"]); err == nil {
+		t.Errorf("MakeConfig of analyzer without Config succeeded")
+	}
+}
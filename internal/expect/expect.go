@@ -5,6 +5,8 @@
 /*
 Package expect provides support for interpreting structured comments in Go
 source code (including go.mod and go.work files) as test expectations.
+Notes may also appear in the comments of other kinds of files, such as
+assembly files and templates; see ParseText.
 
 This is primarily intended for writing tests of things that process Go source
 files, although it does not directly depend on the testing package.
@@ -9,6 +9,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -81,9 +82,89 @@ func Parse(fset *token.FileSet, filename string, content []byte) ([]*Note, error
 		}
 		return notes, nil
 	}
+	if syntax, ok := commentSyntaxes[filepath.Ext(filename)]; ok {
+		if content == nil {
+			var err error
+			content, err = os.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return ParseText(fset, filename, content, syntax)
+	}
 	return nil, nil
 }
 
+// A CommentSyntax describes the comments of a kind of file that is not
+// Go source code, for ParseText.
+type CommentSyntax struct {
+	Line       string // start of a line comment, such as "//" or "#"; or "" for none
+	BlockStart string // start of a block comment, such as "/*"; or "" for none
+	BlockEnd   string // end of a block comment, such as "*/"
+}
+
+// commentSyntaxes records the comment syntaxes of the files that
+// Parse passes to ParseText, by file name extension.
+var commentSyntaxes = map[string]CommentSyntax{
+	".s":      {Line: "//", BlockStart: "/*", BlockEnd: "*/"},
+	".tmpl":   {BlockStart: "{{/*", BlockEnd: "*/}}"},
+	".gotmpl": {BlockStart: "{{/*", BlockEnd: "*/}}"},
+	".txt":    {Line: "#"},
+}
+
+// ParseText collects all the notes present in the content of a file
+// whose comments have the given syntax, such as an assembly file or a
+// template. The filename is used for positions and error messages.
+//
+// As in Go files, a note comment starts with the special marker @,
+// which must immediately follow the start of the comment, as in //@ or
+// {{/*@ for a template. A line comment extends to the end of the line.
+//
+// ParseText does not lex the file, so a comment start within a string
+// is taken for a comment.
+func ParseText(fset *token.FileSet, filename string, content []byte, syntax CommentSyntax) ([]*Note, error) {
+	f := fset.AddFile(filename, -1, len(content))
+	f.SetLinesForContent(content)
+	var notes []*Note
+	text := string(content)
+	for offset := 0; offset < len(text); {
+		// Find the next comment that starts a note.
+		start, end := -1, ""
+		if syntax.Line != "" {
+			if i := strings.Index(text[offset:], syntax.Line+commentStart); i >= 0 {
+				start, end = offset+i+len(syntax.Line), "\n"
+			}
+		}
+		if syntax.BlockStart != "" {
+			if i := strings.Index(text[offset:], syntax.BlockStart+commentStart); i >= 0 && (start < 0 || offset+i < start-len(syntax.Line)) {
+				start, end = offset+i+len(syntax.BlockStart), syntax.BlockEnd
+			}
+		}
+		if start < 0 {
+			break
+		}
+
+		// start is the offset of the marker.
+		body := text[start+commentStartLen:]
+		n := strings.Index(body, end)
+		switch {
+		case n >= 0:
+			offset = start + commentStartLen + n + len(end)
+		case end == "\n":
+			n = len(body)
+			offset = len(text)
+		default:
+			return nil, fmt.Errorf("%v: unterminated comment", fset.Position(f.Pos(start)))
+		}
+		parsed, err := parse(fset, f.Pos(start), body[:n])
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, parsed...)
+	}
+	return notes, nil
+}
+
 // extractModWork collects all the notes present in a go.mod file or go.work
 // file, by way of the shared modfile.Expr statement node.
 //
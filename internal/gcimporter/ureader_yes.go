@@ -7,6 +7,7 @@
 package gcimporter
 
 import (
+	"encoding/binary"
 	"fmt"
 	"go/token"
 	"go/types"
@@ -72,6 +73,11 @@ func UImportData(fset *token.FileSet, imports map[string]*types.Package, data []
 
 	s := string(data)
 	s = s[:strings.LastIndex(s, "\n$$\n")]
+	if len(s) >= 4 {
+		if v := pkgbits.Version(binary.LittleEndian.Uint32(data[:4])); !v.Supported() {
+			return 0, nil, fmt.Errorf("cannot import %q (export data version %d), export data is newer version - update tool", path, v)
+		}
+	}
 	input := pkgbits.NewPkgDecoder(path, s)
 	pkg = readUnifiedPackage(fset, nil, imports, input)
 	return
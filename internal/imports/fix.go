@@ -245,6 +245,7 @@ type pass struct {
 	loadRealPackageNames bool        // if true, load package names from disk rather than guessing them.
 	otherFiles           []*ast.File // sibling files.
 	goroot               string
+	stdlib               map[string][]stdlib.Symbol // the standard packages and their symbols
 
 	// Intermediate state, generated by load.
 	existingImports map[string][]*ImportInfo
@@ -514,7 +515,7 @@ func (p *pass) assumeSiblingImportsValid() {
 		}
 		for left, rights := range refs {
 			if imp, ok := importsByName[left]; ok {
-				if m, ok := stdlib.PackageSymbols[imp.ImportPath]; ok {
+				if m, ok := p.stdlib[imp.ImportPath]; ok {
 					// We have the stdlib in memory; no need to guess.
 					rights = symbolNameSet(m)
 				}
@@ -588,6 +589,11 @@ func getFixesWithSource(ctx context.Context, fset *token.FileSet, f *ast.File, f
 		logf("fixImports(filename=%q), srcDir=%q ...", filename, abs, srcDir)
 	}
 
+	std := stdlib.PackageSymbols
+	if s, ok := source.(*ProcessEnvSource); ok {
+		std = s.env.stdlib()
+	}
+
 	// First pass: looking only at f, and using the naive algorithm to
 	// derive package names from import paths, see if the file is already
 	// complete. We can't add any imports yet, because we don't know
@@ -598,6 +604,7 @@ func getFixesWithSource(ctx context.Context, fset *token.FileSet, f *ast.File, f
 		srcDir: srcDir,
 		logf:   logf,
 		goroot: goroot,
+		stdlib: std,
 		source: source,
 	}
 	if fixes, done := p.load(ctx); done {
@@ -631,6 +638,7 @@ func getFixesWithSource(ctx context.Context, fset *token.FileSet, f *ast.File, f
 		srcDir: srcDir,
 		logf:   logf,
 		goroot: goroot,
+		stdlib: std,
 		source: p.source, // safe to reuse, as it's just a wrapper around env
 	}
 	p.loadRealPackageNames = true
@@ -678,7 +686,7 @@ func getCandidatePkgs(ctx context.Context, wrappedCallback *scanCallback, filena
 	dupCheck := map[string]struct{}{}
 
 	// Start off with the standard library.
-	for importPath, symbols := range stdlib.PackageSymbols {
+	for importPath, symbols := range env.stdlib() {
 		p := &pkg{
 			dir:             filepath.Join(goenv["GOROOT"], "src", importPath),
 			importPathShort: importPath,
@@ -704,7 +712,12 @@ func getCandidatePkgs(ctx context.Context, wrappedCallback *scanCallback, filena
 			// and generally redundant with the in-memory version.
 			return root.Type != gopathwalk.RootGOROOT && wrappedCallback.rootFound(root)
 		},
-		dirFound: wrappedCallback.dirFound,
+		dirFound: func(pkg *pkg) bool {
+			if env.VendorPolicy == VendorExclude && isVendored(pkg.dir) {
+				return false
+			}
+			return wrappedCallback.dirFound(pkg)
+		},
 		packageNameLoaded: func(pkg *pkg) bool {
 			mu.Lock()
 			defer mu.Unlock()
@@ -933,6 +946,20 @@ type ProcessEnv struct {
 	// multiple ProcessEnvs.
 	ModCache *DirInfoCache
 
+	// If set, Ranker determines the order of preference among the
+	// packages that could satisfy a missing import.
+	Ranker Ranker
+
+	// VendorPolicy determines how packages in vendor directories are
+	// treated when satisfying missing imports. VendorPrefer affects only
+	// the choice among candidates, not the order of GetAllCandidates.
+	VendorPolicy VendorPolicy
+
+	// If set, Stdlib replaces the built-in table of the packages of the
+	// standard library and their symbols, for toolchains whose standard
+	// library differs from that of the gc toolchain.
+	Stdlib map[string][]stdlib.Symbol
+
 	initialized bool // see TODO above
 
 	// resolver and resolverErr are lazily evaluated (see GetResolver).
@@ -949,6 +976,14 @@ func (e *ProcessEnv) goEnv() (map[string]string, error) {
 	return e.Env, nil
 }
 
+// stdlib returns the table of standard packages and their symbols.
+func (e *ProcessEnv) stdlib() map[string][]stdlib.Symbol {
+	if e != nil && e.Stdlib != nil {
+		return e.Stdlib
+	}
+	return stdlib.PackageSymbols
+}
+
 func (e *ProcessEnv) matchFile(dir, name string) (bool, error) {
 	bctx, err := e.buildContext()
 	if err != nil {
@@ -965,8 +1000,12 @@ func (e *ProcessEnv) CopyConfig() *ProcessEnv {
 		BuildFlags:  e.BuildFlags,
 		Logf:        e.Logf,
 		WorkingDir:  e.WorkingDir,
+		Ranker:      e.Ranker,
+		Stdlib:      e.Stdlib,
 		resolver:    nil,
 		Env:         map[string]string{},
+
+		VendorPolicy: e.VendorPolicy,
 	}
 	for k, v := range e.Env {
 		copy.Env[k] = v
@@ -1053,9 +1092,7 @@ func (e *ProcessEnv) logf(format string, args ...any) {
 }
 
 // buildContext returns the build.Context to use for matching files.
-//
-// TODO(rfindley): support dynamic GOOS, GOARCH here, when doing cross-platform
-// development.
+// GOOS and GOARCH are those of Env, if set there.
 func (e *ProcessEnv) buildContext() (*build.Context, error) {
 	ctx := build.Default
 	goenv, err := e.goEnv()
@@ -1064,6 +1101,12 @@ func (e *ProcessEnv) buildContext() (*build.Context, error) {
 	}
 	ctx.GOROOT = goenv["GOROOT"]
 	ctx.GOPATH = goenv["GOPATH"]
+	if goos := goenv["GOOS"]; goos != "" {
+		ctx.GOOS = goos
+	}
+	if goarch := goenv["GOARCH"]; goarch != "" {
+		ctx.GOARCH = goarch
+	}
 
 	// As of Go 1.14, build.Context has a Dir field
 	// (see golang.org/issue/34860).
@@ -1118,7 +1161,7 @@ func addStdlibCandidates(pass *pass, refs References) error {
 		if path.Base(pkg) == pass.f.Name.Name && filepath.Join(pass.goroot, "src", pkg) == pass.srcDir {
 			return
 		}
-		exports := symbolNameSet(stdlib.PackageSymbols[pkg])
+		exports := symbolNameSet(pass.stdlib[pkg])
 		pass.addCandidate(
 			&ImportInfo{ImportPath: pkg},
 			&PackageInfo{Name: localbase(pkg), Exports: exports})
@@ -1134,7 +1177,7 @@ func addStdlibCandidates(pass *pass, refs References) error {
 			add("math/rand/v2")
 			continue
 		}
-		for importPath := range stdlib.PackageSymbols {
+		for importPath := range pass.stdlib {
 			if path.Base(importPath) == left {
 				add(importPath)
 			}
@@ -1482,7 +1525,7 @@ func (r *gopathResolver) scan(ctx context.Context, callback *scanCallback) error
 }
 
 func (r *gopathResolver) scoreImportPath(ctx context.Context, path string) float64 {
-	if stdlib.HasPackage(path) {
+	if _, ok := r.env.stdlib()[path]; ok {
 		return MaxRelevance
 	}
 	return MaxRelevance - 1
@@ -1618,12 +1661,9 @@ type symbolSearcher struct {
 // exported symbols.
 //
 // If successful, returns the resulting package.
+//
+// The candidates must be in order of preference (see rankCandidates).
 func (s *symbolSearcher) search(ctx context.Context, candidates []pkgDistance, pkgName string, symbols map[string]bool) (*pkg, error) {
-	// Sort the candidates by their import package length,
-	// assuming that shorter package names are better than long
-	// ones.  Note that this sorts by the de-vendored name, so
-	// there's no "penalty" for vendoring.
-	sort.Sort(byDistanceOrImportPathShortLength(candidates))
 	if s.logf != nil {
 		for i, c := range candidates {
 			s.logf("%s candidate %d/%d: %v in %v", pkgName, i+1, len(candidates), c.pkg.importPathShort, c.pkg.dir)
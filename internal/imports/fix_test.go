@@ -1798,6 +1798,270 @@ const Y = bar.X
 	}.processTest(t, "foo.com", "test/t.go", nil, nil, want)
 }
 
+// Tests that the Strict option regroups imports and removes
+// names that are the same as the package name.
+func TestStrict(t *testing.T) {
+	const input = `package main
+
+import (
+	baz "foo.com/foo/baz"
+	fmt "fmt"
+
+	os "os"
+	bar "foo.com/foo/bar"
+	baz2 "foo.com/foo/baz"
+	"strings"
+)
+
+var _, _, _, _, _ = fmt.Print, os.Exit, bar.X, baz.X, baz2.X
+var _ = strings.ToUpper
+`
+
+	const want = `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	bar "foo.com/foo/bar"
+	"foo.com/foo/baz"
+	baz2 "foo.com/foo/baz"
+)
+
+var _, _, _, _, _ = fmt.Print, os.Exit, bar.X, baz.X, baz2.X
+var _ = strings.ToUpper
+`
+	testConfig{
+		module: packagestest.Module{
+			Name: "foo.com",
+			Files: fm{
+				"foo/bar/x.go": "package notbar \n const X = 1",
+				"foo/baz/x.go": "package baz \n const X = 1",
+				"test/t.go":    input,
+			},
+		},
+	}.test(t, func(t *goimportTest) {
+		opts := &Options{Comments: true, TabIndent: true, TabWidth: 8, Strict: true}
+		t.assertProcessEquals("foo.com", "test/t.go", nil, opts, want)
+
+		// Without import fixing, only standard imports lose their names.
+		opts = &Options{Comments: true, TabIndent: true, TabWidth: 8, Strict: true, FormatOnly: true}
+		got, err := t.process("foo.com", "test/t.go", nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), `baz "foo.com/foo/baz"`) {
+			t.Errorf("FormatOnly removed name of non-standard import:\n%s", got)
+		}
+	})
+}
+
+// prefixRanker prefers candidates with the specified import path
+// prefix, and excludes those with the denied prefix.
+type prefixRanker struct{ prefer, deny string }
+
+func (r prefixRanker) Rank(filename, pkgName string, candidates []Candidate) []Candidate {
+	var preferred, others []Candidate
+	for _, c := range candidates {
+		switch {
+		case r.deny != "" && strings.HasPrefix(c.ImportPath, r.deny):
+		case r.prefer != "" && strings.HasPrefix(c.ImportPath, r.prefer):
+			preferred = append(preferred, c)
+		default:
+			others = append(others, c)
+		}
+	}
+	return append(preferred, others...)
+}
+
+// Tests that a Ranker determines which candidate is chosen.
+func TestRanker(t *testing.T) {
+	const input = `package main
+
+var _ = bar.X
+`
+	want := func(path string) string {
+		if path == "" {
+			return input
+		}
+		return fmt.Sprintf("package main\n\nimport %q\n\nvar _ = bar.X\n", path)
+	}
+	testConfig{
+		module: packagestest.Module{
+			Name: "foo.com",
+			Files: fm{
+				"bar/x.go":               "package bar \n const X = 1",
+				"internal/fork/bar/x.go": "package bar \n const X = 1",
+				"test/t.go":              input,
+			},
+		},
+	}.test(t, func(t *goimportTest) {
+		for _, test := range []struct {
+			ranker Ranker
+			want   string
+		}{
+			{nil, "foo.com/bar"},
+			{prefixRanker{prefer: "foo.com/internal/"}, "foo.com/internal/fork/bar"},
+			{prefixRanker{deny: "foo.com/bar"}, "foo.com/internal/fork/bar"},
+			{prefixRanker{deny: "foo.com/"}, ""},
+		} {
+			t.env.Ranker = test.ranker // copied by process
+			got, err := t.process("foo.com", "test/t.go", nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want(test.want) {
+				t.Errorf("with ranker %+v, got:\n%s\nwant:\n%s", test.ranker, got, want(test.want))
+			}
+		}
+	})
+}
+
+// Tests that ProcessEnv.Stdlib replaces the standard library table.
+func TestStdlibOverride(t *testing.T) {
+	const input = `package main
+
+var _ = machine.Pin
+`
+	const want = `package main
+
+import "machine"
+
+var _ = machine.Pin
+`
+	testConfig{
+		module: packagestest.Module{
+			Name:  "foo.com",
+			Files: fm{"x.go": input},
+		},
+	}.test(t, func(t *goimportTest) {
+		t.env.Stdlib = map[string][]stdlib.Symbol{
+			"machine": {{Name: "Pin", Kind: stdlib.Type}},
+		}
+		t.assertProcessEquals("foo.com", "x.go", nil, nil, want)
+	})
+}
+
+// Tests that ProcessEnv.VendorPolicy affects the choice of vendored packages.
+func TestVendorPolicy(t *testing.T) {
+	const input = `package app
+
+var _ = bar.X
+`
+	testConfig{
+		gopathOnly: true,
+		module: packagestest.Module{
+			Name: "foo.com",
+			Files: fm{
+				"lib/bar/x.go":                  "package bar \n const X = 1",
+				"app/vendor/other.com/bar/x.go": "package bar \n const X = 1",
+				"app/t.go":                      input,
+				"app/near/x/bar/x.go":           "package bar \n const X = 1",
+				"app/near/t.go":                 input,
+			},
+		},
+	}.test(t, func(t *goimportTest) {
+		for _, test := range []struct {
+			file   string
+			policy VendorPolicy
+			want   string
+		}{
+			{"app/t.go", VendorDefault, "other.com/bar"},
+			{"app/t.go", VendorExclude, "foo.com/lib/bar"},
+			{"app/near/t.go", VendorDefault, "foo.com/app/near/x/bar"},
+			{"app/near/t.go", VendorPrefer, "other.com/bar"},
+		} {
+			t.env.VendorPolicy = test.policy // copied by process
+			got, err := t.process("foo.com", test.file, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(got), fmt.Sprintf("%q", test.want)) {
+				t.Errorf("%s with policy %d, got:\n%s\nwant import of %s", test.file, test.policy, got, test.want)
+			}
+		}
+	})
+}
+
+func TestIsVendored(t *testing.T) {
+	for _, test := range []struct {
+		dir  string
+		want bool
+	}{
+		{"/src/app/vendor/x/bar", true},
+		{"/src/app/vendor/bar", true},
+		{"/src/app/vendor", false},
+		{"/src/app/vendors/bar", false},
+		{"/src/app/myvendor/bar", false},
+		{"vendor/bar", true},
+		{"/src/app/bar", false},
+	} {
+		if got := isVendored(filepath.FromSlash(test.dir)); got != test.want {
+			t.Errorf("isVendored(%q) = %t, want %t", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestEnsureImport(t *testing.T) {
+	const input = `package main
+
+import "os"
+
+var fmt = os.Args
+`
+	testConfig{
+		module: packagestest.Module{
+			Name: "foo.com",
+			Files: fm{
+				"foo/bar/x.go": "package notbar \n const X = 1",
+				"test/t.go":    input,
+			},
+		},
+	}.test(t, func(t *goimportTest) {
+		filename := t.exported.File("foo.com", "test/t.go")
+		for _, test := range []struct {
+			src, path, name string
+			wantName        string
+			wantImports     string
+		}{
+			{input, "errors", "", "errors", `import (
+	"errors"
+	"os"
+)`},
+			{input, "fmt", "", "fmt1", `import (
+	fmt1 "fmt"
+	"os"
+)`},
+			{input, "foo.com/foo/bar", "", "notbar", `import (
+	"os"
+
+	notbar "foo.com/foo/bar"
+)`},
+			{input, "foo.com/foo/bar", "bar", "bar", `import (
+	"os"
+
+	bar "foo.com/foo/bar"
+)`},
+			{input, "os", "system", "os", `import "os"`},
+			{input, "strings", "str", "str", `import (
+	"os"
+	str "strings"
+)`},
+		} {
+			opts := &Options{Comments: true, TabIndent: true, TabWidth: 8, Env: t.env.CopyConfig()}
+			got, name, err := EnsureImport(filename, []byte(test.src), test.path, test.name, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Replace(input, `import "os"`, test.wantImports, 1)
+			if name != test.wantName || string(got) != want {
+				t.Errorf("EnsureImport(%q, %q) = %q,\n%s\nwant %q,\n%s", test.path, test.name, name, got, test.wantName, want)
+			}
+		}
+	})
+}
+
 func TestPanicAstutils(t *testing.T) {
 	t.Skip("panic in ast/astutil/imports.go, should be PostionFor(,false) at lines 273, 274, at least")
 	const input = `package main
@@ -34,6 +34,10 @@ type Options struct {
 	// into another group after 3rd-party packages.
 	LocalPrefix string
 
+	// Sections, if non-nil, specifies the sections into which Process
+	// reorders the imports of the file, overriding LocalPrefix.
+	Sections *Sections
+
 	Fragment  bool // Accept fragment of a source file (no package statement)
 	AllErrors bool // Report all errors (not just the first 10 on different lines)
 
@@ -42,6 +46,12 @@ type Options struct {
 	TabWidth  int  // Tab width (8 if nil *Options provided)
 
 	FormatOnly bool // Disable the insertion and deletion of imports
+
+	// Strict enables a stricter formatting profile, compatible with
+	// gofumpt: all the imports of a declaration are reordered into
+	// their groups, with no blank lines within a group, and import
+	// names equal to the name of the imported package are removed.
+	Strict bool
 }
 
 // Process implements golang.org/x/tools/imports.Process with explicit context in opt.Env.
@@ -64,7 +74,63 @@ func Process(filename string, src []byte, opt *Options) (formatted []byte, err e
 			return nil, err
 		}
 	}
-	return formatFile(fileSet, file, src, adjust, opt)
+	if opt.Strict {
+		removeRedundantNames(fileSet, file, filename, opt)
+	}
+	return formatFile(fileSet, filename, file, src, adjust, opt)
+}
+
+// EnsureImport implements golang.org/x/tools/imports.AddImport with explicit context in opt.Env.
+func EnsureImport(filename string, src []byte, pkgPath, name string, opt *Options) (formatted []byte, localName string, err error) {
+	fileSet := token.NewFileSet()
+	var parserMode parser.Mode
+	if opt.Comments {
+		parserMode |= parser.ParseComments
+	}
+	if opt.AllErrors {
+		parserMode |= parser.AllErrors
+	}
+	file, adjust, err := parse(fileSet, filename, src, parserMode, opt.Fragment)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Determine the declared name of the package.
+	assumed := ImportPathToAssumedName(pkgPath)
+	pkgName := assumed
+	if opt.Env != nil {
+		if source, err := NewProcessEnvSource(opt.Env, filename, file.Name.Name); err == nil {
+			names, _ := source.LoadPackageNames(context.Background(), source.srcDir, []string{pkgPath})
+			if n, ok := names[pkgPath]; ok && n != "" {
+				pkgName = withoutVersion(n)
+			}
+		}
+	}
+	if name == "" {
+		name = pkgName
+	}
+	for _, spec := range file.Imports {
+		if spec.Name == nil && importPath(spec) == pkgPath {
+			name = pkgName // existing import; see astutil.EnsureImport
+		}
+	}
+
+	n := len(file.Imports)
+	localName, _ = astutil.EnsureImport(fileSet, file, name, pkgPath)
+	if len(file.Imports) > n && (localName != assumed || localName != pkgName) {
+		// Name the new import explicitly if an unnamed import would
+		// not bind localName, or if a reader might not expect it to.
+		for _, spec := range file.Imports {
+			if spec.Name == nil && importPath(spec) == pkgPath {
+				spec.Name = ast.NewIdent(localName)
+			}
+		}
+	}
+	formatted, err = formatFile(fileSet, filename, file, src, adjust, opt)
+	if err != nil {
+		return nil, "", err
+	}
+	return formatted, localName, nil
 }
 
 // FixImports returns a list of fixes to the imports that, when applied,
@@ -112,7 +178,7 @@ func ApplyFixes(fixes []*ImportFix, filename string, src []byte, opt *Options, e
 	// Apply the fixes to the file.
 	apply(fileSet, file, fixes)
 
-	return formatFile(fileSet, file, src, nil, opt)
+	return formatFile(fileSet, filename, file, src, nil, opt)
 }
 
 // formatFile formats the file syntax tree.
@@ -121,9 +187,13 @@ func ApplyFixes(fixes []*ImportFix, filename string, src []byte, opt *Options, e
 // If an adjust function is provided, it is called after formatting
 // with the original source (formatFile's src parameter) and the
 // formatted file, and returns the postpocessed result.
-func formatFile(fset *token.FileSet, file *ast.File, src []byte, adjust func(orig []byte, src []byte) []byte, opt *Options) ([]byte, error) {
+func formatFile(fset *token.FileSet, filename string, file *ast.File, src []byte, adjust func(orig []byte, src []byte) []byte, opt *Options) ([]byte, error) {
+	group := func(importPath string) int { return importGroup(opt.LocalPrefix, importPath) }
+	if opt.Sections != nil {
+		group = opt.Sections.grouper(filename)
+	}
 	mergeImports(file)
-	sortImports(opt.LocalPrefix, fset.File(file.FileStart), file)
+	sortImports(group, opt.Sections != nil || opt.Strict, fset.File(file.FileStart), file)
 	var spacesBefore []string // import paths we need spaces before
 	for _, impSection := range astutil.Imports(fset, file) {
 		// Within each block of contiguous imports, see if any
@@ -133,7 +203,7 @@ func formatFile(fset *token.FileSet, file *ast.File, src []byte, adjust func(ori
 		lastGroup := -1
 		for _, importSpec := range impSection {
 			importPath, _ := strconv.Unquote(importSpec.Path.Value)
-			groupNum := importGroup(opt.LocalPrefix, importPath)
+			groupNum := group(importPath)
 			if groupNum != lastGroup && lastGroup != -1 {
 				spacesBefore = append(spacesBefore, importPath)
 			}
@@ -171,6 +241,50 @@ func formatFile(fset *token.FileSet, file *ast.File, src []byte, adjust func(ori
 	return out, nil
 }
 
+// removeRedundantNames removes the names of imports that are equal
+// to both the name of the imported package and the name assumed from
+// its path, so that goimports would not add them back. The names of
+// packages outside the standard library are known only if opt.Env is
+// available; otherwise, only standard library imports are affected.
+func removeRedundantNames(fset *token.FileSet, f *ast.File, filename string, opt *Options) {
+	std := opt.Env.stdlib()
+	var named []*ast.ImportSpec
+	var paths []string
+	for _, imp := range f.Imports {
+		if imp.Name == nil || imp.Name.Name == "_" || imp.Name.Name == "." {
+			continue
+		}
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || imp.Name.Name != ImportPathToAssumedName(path) {
+			continue
+		}
+		named = append(named, imp)
+		if _, ok := std[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	if len(named) == 0 {
+		return
+	}
+
+	var names map[string]string // package names of non-std imports
+	if len(paths) > 0 && !opt.FormatOnly && opt.Env != nil {
+		if source, err := NewProcessEnvSource(opt.Env, filename, f.Name.Name); err == nil {
+			names, _ = source.LoadPackageNames(context.Background(), source.srcDir, paths)
+		}
+	}
+	for _, imp := range named {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name, ok := names[path]
+		if _, isStd := std[path]; isStd {
+			name, ok = ImportPathToAssumedName(path), true
+		}
+		if ok && withoutVersion(name) == imp.Name.Name {
+			imp.Name = nil
+		}
+	}
+}
+
 // parse parses src, which was read from filename,
 // as a Go source file or statement list.
 func parse(fset *token.FileSet, filename string, src []byte, parserMode parser.Mode, fragment bool) (*ast.File, func(orig, src []byte) []byte, error) {
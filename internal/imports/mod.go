@@ -656,7 +656,7 @@ func (r *ModuleResolver) scan(ctx context.Context, callback *scanCallback) error
 }
 
 func (r *ModuleResolver) scoreImportPath(ctx context.Context, path string) float64 {
-	if stdlib.HasPackage(path) {
+	if _, ok := r.env.stdlib()[path]; ok {
 		return MaxRelevance
 	}
 	mod, _ := r.findPackage(path)
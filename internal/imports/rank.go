@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// A Candidate is a package that may satisfy a missing import.
+type Candidate struct {
+	ImportPath string  // the package's import path, without any vendor prefix
+	Dir        string  // the package's directory
+	Distance   int     // number of directories between the importing file and Dir, or -1 if unrelated
+	Relevance  float64 // the resolver's estimate of relevance; higher is better (see MaxRelevance)
+}
+
+// A Ranker determines the order in which the packages that could
+// satisfy a missing import are considered. The first candidate that
+// provides all the referenced symbols is chosen.
+//
+// Packages of the standard library are not ranked: if they provide
+// all the missing symbols, they are chosen without a search.
+//
+// Rank may be called concurrently, for the different package names
+// referenced by a file, so implementations must be safe for
+// concurrent use.
+type Ranker interface {
+	// Rank returns the candidates for an import of a package named
+	// pkgName into the specified file, in order of preference. It may
+	// reorder the candidates slice in place, and may omit candidates
+	// that must not be used.
+	//
+	// The candidates are initially sorted by increasing Distance,
+	// then by length of import path: those nearest the importing file,
+	// then those with the shortest paths, are preferred.
+	Rank(filename, pkgName string, candidates []Candidate) []Candidate
+}
+
+// A VendorPolicy determines how packages
+// in vendor directories are treated.
+type VendorPolicy int
+
+const (
+	// VendorDefault treats packages in vendor directories
+	// like any other, where they are visible.
+	VendorDefault VendorPolicy = iota
+
+	// VendorPrefer prefers packages in vendor directories to
+	// other packages that could satisfy a missing import.
+	VendorPrefer
+
+	// VendorExclude excludes packages in vendor directories
+	// from consideration.
+	VendorExclude
+)
+
+// isVendored reports whether the package directory
+// is within a vendor directory.
+func isVendored(dir string) bool {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		if filepath.Base(parent) == "vendor" {
+			return true
+		}
+		dir = parent
+	}
+}
+
+// rankCandidates sorts the candidates into the default order of
+// preference, then reorders them according to the vendor policy
+// and the ranker, if non-nil.
+func rankCandidates(ranker Ranker, policy VendorPolicy, filename, pkgName string, candidates []pkgDistance) []pkgDistance {
+	// Sort the candidates by their import package length,
+	// assuming that shorter package names are better than long
+	// ones.  Note that this sorts by the de-vendored name, so
+	// there's no "penalty" for vendoring.
+	sort.Sort(byDistanceOrImportPathShortLength(candidates))
+	if policy == VendorPrefer {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return isVendored(candidates[i].pkg.dir) && !isVendored(candidates[j].pkg.dir)
+		})
+	}
+	if ranker == nil || len(candidates) == 0 {
+		return candidates
+	}
+
+	byDir := make(map[string]pkgDistance, len(candidates))
+	cands := make([]Candidate, len(candidates))
+	for i, c := range candidates {
+		byDir[c.pkg.dir] = c
+		cands[i] = Candidate{
+			ImportPath: c.pkg.importPathShort,
+			Dir:        c.pkg.dir,
+			Distance:   c.distance,
+			Relevance:  c.pkg.relevance,
+		}
+	}
+	var ranked []pkgDistance
+	for _, c := range ranker.Rank(filename, pkgName, cands) {
+		if pd, ok := byDir[c.Dir]; ok {
+			ranked = append(ranked, pd)
+			delete(byDir, c.Dir) // ignore duplicates
+		}
+	}
+	return ranked
+}
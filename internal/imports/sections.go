@@ -0,0 +1,164 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Sections describes a division of the imports of a file into
+// sections, separated by blank lines. Unlike the grouping implied by
+// LocalPrefix, which sorts only within each run of consecutive import
+// lines, Sections are enforced: all the imports of a declaration are
+// reordered into them.
+//
+// Sections are parsed from a specification by [ParseSections].
+type Sections struct {
+	prefixes [][]string // import path prefixes of each section; nil for std, external, and module
+	std      int        // index of std section, or -1
+	external int        // index of external section, or -1
+	module   int        // index of module section, or -1
+}
+
+// ParseSections parses a specification of import sections: a list of
+// sections, in order, separated by commas or newlines. Text following
+// a '#' on a line is a comment. Each section is one of the following:
+//
+//	std       the standard library (and other paths without a dot in their first element)
+//	external  all imports not belonging to another section
+//	module    the module containing the file, according to the nearest go.mod file
+//	prefixes  a list of import path prefixes separated by '|', such as example.com/org
+//
+// An import belongs to the section with the longest matching prefix
+// (treating the module section as a prefix), or else the std section;
+// imports of no section are placed after all the others.
+//
+// For example, "std,external,example.com/org,module" specifies four
+// sections, one for the packages of an organization.
+func ParseSections(spec string) (*Sections, error) {
+	s := &Sections{std: -1, external: -1, module: -1}
+	sc := bufio.NewScanner(strings.NewReader(spec))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		for _, field := range strings.Split(line, ",") {
+			field = strings.TrimSpace(field)
+			var index *int
+			switch field {
+			case "":
+				continue
+			case "std":
+				index = &s.std
+			case "external":
+				index = &s.external
+			case "module":
+				index = &s.module
+			}
+			if index != nil {
+				if *index >= 0 {
+					return nil, fmt.Errorf("duplicate %q section", field)
+				}
+				*index = len(s.prefixes)
+				s.prefixes = append(s.prefixes, nil)
+				continue
+			}
+			var prefixes []string
+			for _, prefix := range strings.Split(field, "|") {
+				if prefix = strings.TrimSpace(prefix); prefix == "" {
+					return nil, fmt.Errorf("empty prefix in section %q", field)
+				}
+				prefixes = append(prefixes, strings.TrimSuffix(prefix, "/"))
+			}
+			s.prefixes = append(s.prefixes, prefixes)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.prefixes) == 0 {
+		return nil, fmt.Errorf("no import sections")
+	}
+	return s, nil
+}
+
+// ReadSections reads and parses a file containing
+// a specification of import sections.
+func ReadSections(filename string) (*Sections, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSections(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return s, nil
+}
+
+// grouper returns a function that maps each import path of the
+// specified file to the index of its section.
+func (s *Sections) grouper(filename string) func(importPath string) int {
+	var module string
+	if s.module >= 0 {
+		module = enclosingModulePath(filepath.Dir(filename))
+	}
+	return func(importPath string) int {
+		best, bestLen := -1, -1
+		match := func(index int, prefix string) {
+			if len(prefix) > bestLen && hasPathPrefix(importPath, prefix) {
+				best, bestLen = index, len(prefix)
+			}
+		}
+		for i, prefixes := range s.prefixes {
+			for _, prefix := range prefixes {
+				match(i, prefix)
+			}
+		}
+		if module != "" {
+			match(s.module, module)
+		}
+		if best >= 0 {
+			return best
+		}
+		if s.std >= 0 && !strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			return s.std
+		}
+		if s.external >= 0 {
+			return s.external
+		}
+		return len(s.prefixes)
+	}
+}
+
+// hasPathPrefix reports whether the import path has the
+// specified prefix, which is a sequence of complete path elements.
+func hasPathPrefix(importPath, prefix string) bool {
+	return importPath == prefix ||
+		strings.HasPrefix(importPath, prefix) && importPath[len(prefix)] == '/'
+}
+
+// enclosingModulePath returns the path of the module declared by the
+// nearest go.mod file in dir or its parents, or "" if none is found.
+func enclosingModulePath(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			return modfile.ModulePath(data)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/org/app\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "sub", "x.go")
+
+	const src = `package x
+
+import (
+	"example.com/org/app/internal/util"
+	"fmt"
+	"example.com/org/lib" // the lib
+
+	"golang.org/x/tools/go/packages"
+	"os"
+	"github.com/acme/widget"
+)
+`
+	for _, test := range []struct {
+		spec, want string
+	}{
+		{
+			spec: "std,external,example.com/org|github.com/acme,module",
+			want: `package x
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/tools/go/packages"
+
+	"example.com/org/lib" // the lib
+	"github.com/acme/widget"
+
+	"example.com/org/app/internal/util"
+)
+`,
+		},
+		{
+			// Imports of no section come last.
+			spec: "# comment\nmodule\nstd # standard library\n",
+			want: `package x
+
+import (
+	"example.com/org/app/internal/util"
+
+	"fmt"
+	"os"
+
+	"example.com/org/lib" // the lib
+	"github.com/acme/widget"
+	"golang.org/x/tools/go/packages"
+)
+`,
+		},
+	} {
+		sections, err := ParseSections(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opt := &Options{
+			Sections:   sections,
+			TabWidth:   8,
+			TabIndent:  true,
+			Comments:   true,
+			FormatOnly: true,
+		}
+		got, err := Process(filename, []byte(src), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Errorf("Process with sections %q:\ngot:\n%s\nwant:\n%s", test.spec, got, test.want)
+		}
+	}
+
+	for _, spec := range []string{"", "std,std", "a||b"} {
+		if _, err := ParseSections(spec); err == nil {
+			t.Errorf("ParseSections(%q) succeeded, want error", spec)
+		}
+	}
+}
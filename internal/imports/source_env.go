@@ -56,6 +56,9 @@ func (s *ProcessEnvSource) ResolveReferences(ctx context.Context, filename strin
 			return true // We want everything.
 		},
 		dirFound: func(pkg *pkg) bool {
+			if s.env.VendorPolicy == VendorExclude && isVendored(pkg.dir) {
+				return false
+			}
 			return pkgIsCandidate(filename, refs, pkg)
 		},
 		packageNameLoaded: func(pkg *pkg) bool {
@@ -97,7 +100,8 @@ func (s *ProcessEnvSource) ResolveReferences(ctx context.Context, filename strin
 	results := make(map[string]*Result, len(refs))
 	for pkgName, symbols := range refs {
 		g.Go(func() error {
-			found, err := searcher.search(ctx, found[pkgName], pkgName, symbols)
+			candidates := rankCandidates(s.env.Ranker, s.env.VendorPolicy, filename, pkgName, found[pkgName])
+			found, err := searcher.search(ctx, candidates, pkgName, symbols)
 			if err != nil {
 				return err
 			}
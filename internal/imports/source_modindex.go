@@ -6,6 +6,7 @@ package imports
 
 import (
 	"context"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -21,6 +22,12 @@ import (
 // And then Update is called after every 15 minutes, and a new Index
 // is read if the index changed. It is not clear the Mutex is needed.
 type IndexSource struct {
+	// If set, Ranker determines the order of preference among the
+	// packages of the same name that could satisfy missing references.
+	// Their Distance is computed as for [ProcessEnvSource], and their
+	// Relevance is zero.
+	Ranker Ranker
+
 	modcachedir string
 	mutex       sync.Mutex
 	ix          *modindex.Index
@@ -50,6 +57,7 @@ func (s *IndexSource) ResolveReferences(ctx context.Context, filename string, mi
 		}
 	}
 	found := make(map[string]*Result)
+	byName := make(map[string][]pkgDistance) // packages of each name
 	for _, c := range cs {
 		var x *Result
 		if x = found[c.ImportPath]; x == nil {
@@ -64,12 +72,18 @@ func (s *IndexSource) ResolveReferences(ctx context.Context, filename string, mi
 				},
 			}
 			found[c.ImportPath] = x
+			p := &pkg{dir: c.Dir, importPathShort: c.ImportPath, packageName: c.PkgName}
+			byName[c.PkgName] = append(byName[c.PkgName], pkgDistance{p, distance(filepath.Dir(filename), c.Dir)})
 		}
 		x.Package.Exports[c.Name] = true
 	}
+	// The first result of each name that provides all the
+	// missing symbols is used, so order them by preference.
 	var ans []*Result
-	for _, x := range found {
-		ans = append(ans, x)
+	for name, cands := range byName {
+		for _, c := range rankCandidates(s.Ranker, VendorDefault, filename, name, cands) {
+			ans = append(ans, found[c.pkg.importPathShort])
+		}
 	}
 	return ans, nil
 }
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -84,6 +86,46 @@ func TestSource(t *testing.T) {
 	}
 }
 
+// preferRanker prefers candidates whose import path has the given prefix.
+type preferRanker string
+
+func (r preferRanker) Rank(filename, pkgName string, candidates []imports.Candidate) []imports.Candidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return strings.HasPrefix(candidates[i].ImportPath, string(r)) &&
+			!strings.HasPrefix(candidates[j].ImportPath, string(r))
+	})
+	return candidates
+}
+
+func TestSourceRanker(t *testing.T) {
+	dirs := testDirs(t)
+	if err := newpkgs(dirs.cachedir, &foo, &foobar); err != nil {
+		t.Fatal(err)
+	}
+	const src = "package main\n\nvar _ = foo.Foo\n"
+	for _, test := range []struct {
+		ranker imports.Ranker
+		want   string
+	}{
+		{nil, "bar.com/foo"}, // the shorter, then lesser, import path
+		{preferRanker("foo.com/"), "foo.com/foo"},
+	} {
+		source := imports.NewIndexSource(dirs.cachedir)
+		source.Ranker = test.ranker
+		fixes, err := imports.FixImports(context.Background(), "tfile.go", []byte(src), "unused", nil, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := imports.ApplyFixes(fixes, "tfile.go", []byte(src), &imports.Options{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("import %q", test.want); !strings.Contains(string(got), want) {
+			t.Errorf("with ranker %v, got:\n%s\nwant %s", test.ranker, got, want)
+		}
+	}
+}
+
 type dirs struct {
 	tmpdir   string
 	cachedir string
@@ -70,38 +70,57 @@ func (ix *Index) Lookup(pkg, name string, prefix bool) []Candidate {
 				// past range of matching Names
 				break
 			}
-			if len(flds) < 2 {
-				continue // should never happen
-			}
-			px := Candidate{
-				PkgName:    pkg,
-				Name:       flds[0],
-				Dir:        string(e.Dir),
-				ImportPath: e.ImportPath,
-				Type:       asLexType(flds[1][0]),
-			}
-			if flds[1] == "F" {
-				n, err := strconv.Atoi(flds[2])
-				if err != nil {
-					continue // should never happen
-				}
-				px.Results = int16(n)
-				if len(flds) >= 4 {
-					sig := strings.Split(flds[3], " ")
-					for i := 0; i < len(sig); i++ {
-						// $ cannot otherwise occur. removing the spaces
-						// almost works, but for chan struct{}, e.g.
-						sig[i] = strings.Replace(sig[i], "$", " ", -1)
-					}
-					px.Sig = toFields(sig)
-				}
+			if px, ok := newCandidate(e, flds); ok {
+				ans = append(ans, px)
 			}
+		}
+	}
+	return ans
+}
+
+// Symbols returns all the symbols of the package of the entry.
+func (e *Entry) Symbols() []Candidate {
+	var ans []Candidate
+	for _, nstr := range e.Names {
+		if px, ok := newCandidate(*e, fastSplit(nstr)); ok {
 			ans = append(ans, px)
 		}
 	}
 	return ans
 }
 
+// newCandidate returns the Candidate for the symbol of entry e
+// whose Names line was split into the fields flds.
+func newCandidate(e Entry, flds []string) (Candidate, bool) {
+	if len(flds) < 2 {
+		return Candidate{}, false // should never happen
+	}
+	px := Candidate{
+		PkgName:    e.PkgName,
+		Name:       flds[0],
+		Dir:        string(e.Dir),
+		ImportPath: e.ImportPath,
+		Type:       asLexType(flds[1][0]),
+	}
+	if flds[1] == "F" {
+		n, err := strconv.Atoi(flds[2])
+		if err != nil {
+			return Candidate{}, false // should never happen
+		}
+		px.Results = int16(n)
+		if len(flds) >= 4 {
+			sig := strings.Split(flds[3], " ")
+			for i := 0; i < len(sig); i++ {
+				// $ cannot otherwise occur. removing the spaces
+				// almost works, but for chan struct{}, e.g.
+				sig[i] = strings.Replace(sig[i], "$", " ", -1)
+			}
+			px.Sig = toFields(sig)
+		}
+	}
+	return px, true
+}
+
 func toFields(sig []string) []Field {
 	ans := make([]Field, len(sig)/2)
 	for i := 0; i < len(ans); i++ {
@@ -31,6 +31,10 @@ const (
 	numVersions = iota
 )
 
+// Supported reports whether this package can decode
+// bitstreams of version v.
+func (v Version) Supported() bool { return v < numVersions }
+
 // Field denotes a unit of data in the serialized unified IR bitstream.
 // It is conceptually a like field in a structure.
 //
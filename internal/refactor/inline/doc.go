@@ -4,6 +4,8 @@
 
 /*
 Package inline implements inlining of Go function calls.
+Its API is published, for use outside x/tools, by the package
+golang.org/x/tools/refactor/inline.
 
 The client provides information about the caller and callee,
 including the source text, syntax tree, and type information, and
@@ -14,6 +14,7 @@ const (
 	Go1_20 = "go1.20"
 	Go1_21 = "go1.21"
 	Go1_22 = "go1.22"
+	Go1_23 = "go1.23"
 )
 
 // Future is an invalid unknown Go version sometime in the future.
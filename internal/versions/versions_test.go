@@ -149,6 +149,7 @@ func TestKnown(t *testing.T) {
 		versions.Go1_20,
 		versions.Go1_21,
 		versions.Go1_22,
+		versions.Go1_23,
 	} {
 		if !versions.IsValid(v) {
 			t.Errorf("Expected known version %q to be valid.", v)
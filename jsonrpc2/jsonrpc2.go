@@ -5,7 +5,29 @@
 // Package jsonrpc2 is a minimal implementation of the JSON RPC 2 spec.
 // https://www.jsonrpc.org/specification
 // It is intended to be compatible with other implementations at the wire level.
-package jsonrpc2
+//
+// A [Connection] exchanges messages with its peer over a byte stream,
+// framed by a [Framer] such as [HeaderFramer], which uses the headers of
+// the Language Server Protocol. Incoming requests are passed to a
+// [Handler], which is typically built from a [Router] that dispatches
+// each request by its method, wrapped in [Middleware] such as
+// [LogRequests], [RecordMetrics], and [Authorize]:
+//
+//	router := jsonrpc2.NewRouter()
+//	router.Register("initialize", initialize)
+//	router.Register("textDocument/", documents)
+//	handler := jsonrpc2.Chain(
+//		jsonrpc2.LogRequests(log.Printf),
+//		jsonrpc2.Authorize(checkToken),
+//	)(router)
+//
+// A [Server] accepts connections from a [Listener], such as one made by
+// [NetListener], and [Dial] makes a connection using a [Dialer]. A
+// [Multiplexer] carries any number of connections, made in either
+// direction, over a single byte stream.
+//
+// This package is used by gopls, the Go language server.
+package jsonrpc2 // import "golang.org/x/tools/jsonrpc2"
 
 import (
 	"context"
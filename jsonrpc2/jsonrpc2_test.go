@@ -13,8 +13,8 @@ import (
 	"testing"
 
 	"golang.org/x/tools/internal/event/export/eventtest"
-	jsonrpc2 "golang.org/x/tools/internal/jsonrpc2_v2"
 	"golang.org/x/tools/internal/stack/stacktest"
+	"golang.org/x/tools/jsonrpc2"
 )
 
 var callTests = []invoker{
@@ -34,6 +34,8 @@ type Request struct {
 	// Method is a string containing the method name to invoke.
 	Method string
 	// Params is either a struct or an array with the parameters of the method.
+	// It is nil if the request has no parameters, which are then omitted
+	// from the wire form.
 	Params json.RawMessage
 }
 
@@ -62,14 +64,14 @@ func (id ID) IsValid() bool { return id.value != nil }
 func (id ID) Raw() interface{} { return id.value }
 
 // NewNotification constructs a new Notification message for the supplied
-// method and parameters.
+// method and parameters. If params is nil, the message has no parameters.
 func NewNotification(method string, params interface{}) (*Request, error) {
 	p, merr := marshalToRaw(params)
 	return &Request{Method: method, Params: p}, merr
 }
 
 // NewCall constructs a new Call message for the supplied ID, method and
-// parameters.
+// parameters. If params is nil, the message has no parameters.
 func NewCall(id ID, method string, params interface{}) (*Request, error) {
 	p, merr := marshalToRaw(params)
 	return &Request{ID: id, Method: method, Params: p}, merr
@@ -169,6 +171,7 @@ func DecodeMessage(data []byte) (Message, error) {
 	return resp, nil
 }
 
+// marshalToRaw returns the JSON encoding of obj, or nil if obj is nil.
 func marshalToRaw(obj interface{}) (json.RawMessage, error) {
 	if obj == nil {
 		return nil, nil
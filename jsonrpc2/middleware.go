@@ -0,0 +1,89 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A Middleware wraps a Handler to add behavior, such as logging,
+// metrics, or access control, around the handling of each request.
+type Middleware func(Handler) Handler
+
+// Chain returns a Middleware that applies each of the given
+// middlewares in turn. The first is the outermost: it sees each
+// request first and each result last.
+func Chain(middleware ...Middleware) Middleware {
+	return func(handler Handler) Handler {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			handler = middleware[i](handler)
+		}
+		return handler
+	}
+}
+
+// LogRequests returns a Middleware that logs each request as it is
+// handled, and its outcome, by calling logf, which may be [log.Printf].
+func LogRequests(logf func(format string, args ...interface{})) Middleware {
+	return func(handler Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req *Request) (interface{}, error) {
+			desc := requestString(req)
+			logf("jsonrpc2: <-- %s", desc)
+			start := time.Now()
+			result, err := handler.Handle(ctx, req)
+			switch {
+			case errors.Is(err, ErrAsyncResponse):
+				logf("jsonrpc2: --> %s will be answered asynchronously", desc)
+			case err != nil:
+				logf("jsonrpc2: --> %s failed after %v: %v", desc, time.Since(start), err)
+			default:
+				logf("jsonrpc2: --> %s handled after %v", desc, time.Since(start))
+			}
+			return result, err
+		})
+	}
+}
+
+// requestString returns a description of req for logging.
+func requestString(req *Request) string {
+	if req.IsCall() {
+		return fmt.Sprintf("%s (id %v)", req.Method, req.ID.Raw())
+	}
+	return req.Method
+}
+
+// RecordMetrics returns a Middleware that calls record after each
+// request is handled, with the method of the request, the time taken
+// to handle it, and the error returned by the handler, if any. The
+// error is [ErrAsyncResponse] for requests answered asynchronously,
+// and [ErrNotHandled] for those that the handler does not handle.
+func RecordMetrics(record func(method string, elapsed time.Duration, err error)) Middleware {
+	return func(handler Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req *Request) (interface{}, error) {
+			start := time.Now()
+			result, err := handler.Handle(ctx, req)
+			record(req.Method, time.Since(start), err)
+			return result, err
+		})
+	}
+}
+
+// Authorize returns a Middleware that calls authorize for each request
+// before handling it. If authorize returns an error, the request is
+// not handled: the error is returned instead. Use [NewError] to choose
+// the code of the error sent to the client.
+func Authorize(authorize func(ctx context.Context, req *Request) error) Middleware {
+	return func(handler Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req *Request) (interface{}, error) {
+			if err := authorize(ctx, req); err != nil {
+				return nil, err
+			}
+			return handler.Handle(ctx, req)
+		})
+	}
+}
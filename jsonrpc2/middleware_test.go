@@ -0,0 +1,191 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonrpc2_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/jsonrpc2"
+)
+
+// serve returns a connection to a server that handles requests with h.
+func serve(t *testing.T, h jsonrpc2.Handler) *jsonrpc2.Connection {
+	t.Helper()
+	ctx := context.Background()
+	listener, err := jsonrpc2.NetPipeListener(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := jsonrpc2.NewServer(ctx, listener, jsonrpc2.ConnectionOptions{Handler: h})
+	conn, err := jsonrpc2.Dial(ctx, listener.Dialer(), jsonrpc2.ConnectionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+		server.Wait()
+	})
+	return conn
+}
+
+// callString calls method on conn and returns its result, or its error.
+func callString(conn *jsonrpc2.Connection, method string) string {
+	ctx := context.Background()
+	var result string
+	if err := conn.Call(ctx, method, nil).Await(ctx, &result); err != nil {
+		return "error: " + err.Error()
+	}
+	return result
+}
+
+func TestRouter(t *testing.T) {
+	router := jsonrpc2.NewRouter()
+	for _, pattern := range []string{"initialize", "textDocument/", "textDocument/semanticTokens/", "textDocument/semanticTokens/full"} {
+		router.Register(pattern, echoHandler{pattern})
+	}
+	conn := serve(t, router)
+	for _, test := range []struct{ method, want string }{
+		{"initialize", "initialize: initialize"},
+		{"textDocument/hover", "textDocument/: textDocument/hover"},
+		{"textDocument/semanticTokens/full", "textDocument/semanticTokens/full: textDocument/semanticTokens/full"},
+		{"textDocument/semanticTokens/range", "textDocument/semanticTokens/: textDocument/semanticTokens/range"},
+		{"textDocument/semanticTokens/full/delta", "textDocument/semanticTokens/: textDocument/semanticTokens/full/delta"},
+		{"textDocument", `error: JSON RPC method not found: "textDocument"`},
+		{"initialized", `error: JSON RPC method not found: "initialized"`},
+		{"workspace/symbol", `error: JSON RPC method not found: "workspace/symbol"`},
+	} {
+		if got := callString(conn, test.method); got != test.want {
+			t.Errorf("call(%q) = %q, want %q", test.method, got, test.want)
+		}
+	}
+
+	for _, pattern := range []string{"", "initialize"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) did not panic", pattern)
+				}
+			}()
+			router.Register(pattern, echoHandler{})
+		}()
+	}
+}
+
+func TestChain(t *testing.T) {
+	var mu sync.Mutex
+	var trace []string
+	tracer := func(name string) jsonrpc2.Middleware {
+		return func(h jsonrpc2.Handler) jsonrpc2.Handler {
+			return jsonrpc2.HandlerFunc(func(ctx context.Context, req *jsonrpc2.Request) (interface{}, error) {
+				mu.Lock()
+				trace = append(trace, "before "+name)
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					trace = append(trace, "after "+name)
+					mu.Unlock()
+				}()
+				return h.Handle(ctx, req)
+			})
+		}
+	}
+	conn := serve(t, jsonrpc2.Chain(tracer("a"), tracer("b"))(echoHandler{"h"}))
+	if got, want := callString(conn, "m"), "h: m"; got != want {
+		t.Errorf("call = %q, want %q", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"before a", "before b", "after b", "after a"}; !reflect.DeepEqual(trace, want) {
+		t.Errorf("middleware ran in order %q, want %q", trace, want)
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	var mu sync.Mutex
+	var log []string
+	logf := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, fmt.Sprintf(format, args...))
+	}
+	router := jsonrpc2.NewRouter()
+	router.Register("ok", echoHandler{})
+	conn := serve(t, jsonrpc2.LogRequests(logf)(router))
+	callString(conn, "ok")
+	callString(conn, "missing")
+
+	mu.Lock()
+	defer mu.Unlock()
+	wants := []string{
+		"jsonrpc2: <-- ok (id 1)",
+		"jsonrpc2: --> ok (id 1) handled after ",
+		"jsonrpc2: <-- missing (id 2)",
+		"jsonrpc2: --> missing (id 2) failed after ",
+	}
+	if len(log) != len(wants) {
+		t.Fatalf("log = %q, want %d lines", log, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.HasPrefix(log[i], want) {
+			t.Errorf("log[%d] = %q, want prefix %q", i, log[i], want)
+		}
+	}
+}
+
+func TestRecordMetrics(t *testing.T) {
+	type record struct {
+		method string
+		err    error
+	}
+	var mu sync.Mutex
+	var records []record
+	metrics := jsonrpc2.RecordMetrics(func(method string, elapsed time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if elapsed < 0 {
+			t.Errorf("%s took %v", method, elapsed)
+		}
+		records = append(records, record{method, err})
+	})
+	router := jsonrpc2.NewRouter()
+	router.Register("ok", echoHandler{})
+	conn := serve(t, metrics(router))
+	callString(conn, "ok")
+	callString(conn, "missing")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []record{{"ok", nil}, {"missing", jsonrpc2.ErrNotHandled}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %v, want %v", records, want)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	errDenied := jsonrpc2.NewError(-32001, "denied")
+	authorize := jsonrpc2.Authorize(func(ctx context.Context, req *jsonrpc2.Request) error {
+		if strings.HasPrefix(req.Method, "admin/") {
+			return errDenied
+		}
+		return nil
+	})
+	conn := serve(t, authorize(echoHandler{"h"}))
+	if got, want := callString(conn, "public"), "h: public"; got != want {
+		t.Errorf("call(public) = %q, want %q", got, want)
+	}
+	ctx := context.Background()
+	err := conn.Call(ctx, "admin/shutdown", nil).Await(ctx, nil)
+	var wireErr *jsonrpc2.WireError
+	if !errors.As(err, &wireErr) || wireErr.Code != -32001 {
+		t.Errorf("call(admin/shutdown) = %v, want error with code -32001", err)
+	}
+}
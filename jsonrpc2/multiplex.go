@@ -0,0 +1,343 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonrpc2
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// A Multiplexer carries any number of connections over a single byte
+// stream, such as a network connection or the standard input and
+// output of a process. Either end of the stream may open connections
+// to the other, each of which is independent of the others: a
+// connection that is slow to read delays none of them.
+//
+// A Multiplexer is a Listener, whose Accept method returns the
+// connections opened by the other end of the stream, and whose Dialer
+// opens connections to it. A Server may thus serve the connections
+// opened by the other end, while [Dial] opens connections to a Server
+// at the other end.
+//
+// Each end of the stream must use a Multiplexer. The data written to a
+// connection and not yet read by the other end is buffered in memory.
+type Multiplexer struct {
+	rwc     io.ReadWriteCloser
+	writeMu sync.Mutex // serializes the writing of frames to rwc
+
+	done chan struct{} // closed when the stream fails or is closed
+
+	mu       sync.Mutex
+	err      error // the reason the stream failed; set before done is closed
+	nextID   uint32
+	conns    map[muxKey]*muxConn
+	accepts  []*muxConn    // connections opened by the other end, not yet accepted
+	accepted chan struct{} // 1-buffered; signals an addition to accepts
+}
+
+// NewMultiplexer returns a Multiplexer of the connections carried
+// by rwc, which it closes when the Multiplexer is closed.
+func NewMultiplexer(rwc io.ReadWriteCloser) *Multiplexer {
+	m := &Multiplexer{
+		rwc:      rwc,
+		done:     make(chan struct{}),
+		conns:    make(map[muxKey]*muxConn),
+		accepted: make(chan struct{}, 1),
+	}
+	go m.read()
+	return m
+}
+
+// The frames written to the stream consist of a header, of the kind
+// of frame, the identifier of the connection, and the length of the
+// payload, followed by the payload.
+const (
+	frameOpen  = 1 // opens a connection
+	frameData  = 2 // carries data written to a connection
+	frameClose = 3 // closes a connection
+
+	// frameFromDialer is set in the kind of frames sent by the end of
+	// the stream that opened the connection, as each end numbers the
+	// connections that it opens independently.
+	frameFromDialer = 0x80
+
+	frameHeaderSize = 1 + 4 + 4
+	maxFramePayload = 32 << 10 // so that connections take turns to write
+)
+
+// A muxKey identifies a connection of a Multiplexer.
+type muxKey struct {
+	id     uint32
+	dialed bool // opened by this end of the stream
+}
+
+// Accept returns the next connection opened by the other end of the stream.
+func (m *Multiplexer) Accept(ctx context.Context) (io.ReadWriteCloser, error) {
+	for {
+		m.mu.Lock()
+		if len(m.accepts) > 0 {
+			c := m.accepts[0]
+			m.accepts = m.accepts[1:]
+			m.mu.Unlock()
+			return c, nil
+		}
+		err := m.err
+		m.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case <-m.accepted:
+		case <-m.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close closes the stream, and with it all of its connections.
+func (m *Multiplexer) Close() error {
+	m.fail(errClosed)
+	return m.rwc.Close()
+}
+
+// Dialer returns a Dialer that opens connections to the other end of
+// the stream. It is the Multiplexer itself.
+func (m *Multiplexer) Dialer() Dialer {
+	return m
+}
+
+// Dial opens a connection to the other end of the stream.
+func (m *Multiplexer) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	m.mu.Lock()
+	if m.err != nil {
+		m.mu.Unlock()
+		return nil, m.err
+	}
+	m.nextID++
+	c := m.newConn(muxKey{id: m.nextID, dialed: true})
+	m.mu.Unlock()
+	if err := m.writeFrame(frameOpen, c.key, nil); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+var _ Listener = (*Multiplexer)(nil)
+
+// newConn registers a new connection with the specified key.
+// It must be called with m.mu held.
+func (m *Multiplexer) newConn(key muxKey) *muxConn {
+	c := &muxConn{m: m, key: key, readable: make(chan struct{}, 1)}
+	m.conns[key] = c
+	return c
+}
+
+// fail records the reason the stream failed, if it is the first,
+// and ends all of its connections.
+func (m *Multiplexer) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == nil {
+		m.err = err
+		close(m.done)
+	}
+}
+
+// writeFrame writes a frame of the specified kind for the connection.
+func (m *Multiplexer) writeFrame(kind byte, key muxKey, payload []byte) error {
+	if key.dialed {
+		kind |= frameFromDialer
+	}
+	var hdr [frameHeaderSize]byte
+	hdr[0] = kind
+	binary.BigEndian.PutUint32(hdr[1:], key.id)
+	binary.BigEndian.PutUint32(hdr[5:], uint32(len(payload)))
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	select {
+	case <-m.done:
+		return m.err
+	default:
+	}
+	if _, err := m.rwc.Write(hdr[:]); err != nil {
+		m.fail(err)
+		return err
+	}
+	if _, err := m.rwc.Write(payload); err != nil {
+		m.fail(err)
+		return err
+	}
+	return nil
+}
+
+// read reads the frames of the stream until it fails,
+// and dispatches them to the connections.
+func (m *Multiplexer) read() {
+	in := bufio.NewReader(m.rwc)
+	for {
+		var hdr [frameHeaderSize]byte
+		if _, err := io.ReadFull(in, hdr[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = fmt.Errorf("jsonrpc2: truncated multiplexer frame: %w", err)
+			}
+			m.fail(err)
+			return
+		}
+		kind := hdr[0] &^ frameFromDialer
+		// A frame from the dialer of a connection
+		// is for a connection this end did not dial.
+		key := muxKey{
+			id:     binary.BigEndian.Uint32(hdr[1:]),
+			dialed: hdr[0]&frameFromDialer == 0,
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(hdr[5:]))
+		if _, err := io.ReadFull(in, payload); err != nil {
+			m.fail(fmt.Errorf("jsonrpc2: truncated multiplexer frame: %w", err))
+			return
+		}
+
+		m.mu.Lock()
+		c := m.conns[key]
+		switch {
+		case kind == frameOpen && c == nil && !key.dialed:
+			m.accepts = append(m.accepts, m.newConn(key))
+			select {
+			case m.accepted <- struct{}{}:
+			default:
+			}
+		case kind == frameData || kind == frameClose:
+			// Frames for connections that this end has closed are dropped.
+			if c != nil {
+				c.receive(payload, kind == frameClose)
+				if kind == frameClose {
+					delete(m.conns, key)
+				}
+			}
+		default:
+			m.mu.Unlock()
+			m.fail(fmt.Errorf("jsonrpc2: invalid multiplexer frame (kind %#x, id %d)", hdr[0], key.id))
+			return
+		}
+		m.mu.Unlock()
+	}
+}
+
+// A muxConn is a connection carried by a Multiplexer.
+type muxConn struct {
+	m        *Multiplexer
+	key      muxKey
+	readable chan struct{} // 1-buffered; signals a change to the fields below
+
+	mu           sync.Mutex
+	buf          []byte // data received and not yet read
+	closed       bool   // closed by this end
+	remoteClosed bool   // closed by the other end
+}
+
+// receive adds data received for the connection to its buffer, and
+// records whether the other end has closed it. It is called with the
+// Multiplexer's lock held.
+func (c *muxConn) receive(data []byte, closed bool) {
+	c.mu.Lock()
+	c.buf = append(c.buf, data...)
+	c.remoteClosed = c.remoteClosed || closed
+	c.mu.Unlock()
+	c.signal()
+}
+
+func (c *muxConn) signal() {
+	select {
+	case c.readable <- struct{}{}:
+	default:
+	}
+}
+
+// Read reads the data written to the connection by the other end. It
+// returns io.EOF once the other end has closed the connection and all
+// the data has been read.
+func (c *muxConn) Read(p []byte) (int, error) {
+	for {
+		c.mu.Lock()
+		n := copy(p, c.buf)
+		c.buf = c.buf[n:]
+		closed, remoteClosed := c.closed, c.remoteClosed
+		c.mu.Unlock()
+		switch {
+		case n > 0 || len(p) == 0:
+			return n, nil
+		case closed:
+			return 0, errClosed
+		case remoteClosed:
+			return 0, io.EOF
+		}
+		select {
+		case <-c.readable:
+		case <-c.m.done:
+			// Deliver the data received before the stream failed.
+			c.mu.Lock()
+			n := copy(p, c.buf)
+			c.buf = c.buf[n:]
+			c.mu.Unlock()
+			if n > 0 {
+				return n, nil
+			}
+			return 0, c.m.err
+		}
+	}
+}
+
+// Write writes data to the connection, to be read by the other end.
+func (c *muxConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	closed, remoteClosed := c.closed, c.remoteClosed
+	c.mu.Unlock()
+	if closed || remoteClosed {
+		return 0, errClosed
+	}
+	n := 0
+	for n < len(p) {
+		chunk := p[n:min(len(p), n+maxFramePayload)]
+		if err := c.m.writeFrame(frameData, c.key, chunk); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// Close closes the connection at both ends.
+func (c *muxConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	remoteClosed := c.remoteClosed
+	c.mu.Unlock()
+	c.signal() // end any Read
+
+	c.m.mu.Lock()
+	if c.m.conns[c.key] == c {
+		delete(c.m.conns, c.key)
+	}
+	c.m.mu.Unlock()
+	if remoteClosed {
+		return nil // the other end has already forgotten it
+	}
+	select {
+	case <-c.m.done:
+		return nil // the stream has failed, so all its connections are closed
+	default:
+	}
+	return c.m.writeFrame(frameClose, c.key, nil)
+}
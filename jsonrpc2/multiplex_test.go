@@ -0,0 +1,155 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonrpc2_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/jsonrpc2"
+)
+
+// echoHandler answers each call with its method and parameters, if any.
+type echoHandler struct{ name string }
+
+func (h echoHandler) Handle(ctx context.Context, req *jsonrpc2.Request) (interface{}, error) {
+	if req.Params == nil {
+		return fmt.Sprintf("%s: %s", h.name, req.Method), nil
+	}
+	// Params is converted to a string because the formatting of a
+	// json.RawMessage by fmt depends on the Go release.
+	return fmt.Sprintf("%s: %s %s", h.name, req.Method, string(req.Params)), nil
+}
+
+// TestMultiplexer checks that each end of a stream may serve and
+// dial many connections at once over it.
+func TestMultiplexer(t *testing.T) {
+	ctx := context.Background()
+	a, b := net.Pipe()
+	left, right := jsonrpc2.NewMultiplexer(a), jsonrpc2.NewMultiplexer(b)
+	leftServer := jsonrpc2.NewServer(ctx, left, jsonrpc2.ConnectionOptions{Handler: echoHandler{"left"}})
+	rightServer := jsonrpc2.NewServer(ctx, right, jsonrpc2.ConnectionOptions{Handler: echoHandler{"right"}})
+
+	const n = 5
+	var wg sync.WaitGroup
+	for i := range n {
+		for _, dialer := range []struct {
+			mux    *jsonrpc2.Multiplexer
+			server string
+		}{{left, "right"}, {right, "left"}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				conn, err := jsonrpc2.Dial(ctx, dialer.mux.Dialer(), jsonrpc2.ConnectionOptions{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer conn.Close()
+				for j := range 3 {
+					var got string
+					if err := conn.Call(ctx, "echo", []int{i, j}).Await(ctx, &got); err != nil {
+						t.Error(err)
+						return
+					}
+					if want := fmt.Sprintf("%s: echo [%d,%d]", dialer.server, i, j); got != want {
+						t.Errorf("Call returned %q, want %q", got, want)
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if err := left.Close(); err != nil {
+		t.Fatal(err)
+	}
+	leftServer.Wait()
+	rightServer.Wait()
+	if _, err := right.Dial(ctx); err == nil {
+		t.Errorf("Dial succeeded after the other end of the stream was closed")
+	}
+}
+
+// TestMultiplexerConns checks the independence of the connections
+// of a Multiplexer, and what their ends observe when they are closed.
+func TestMultiplexerConns(t *testing.T) {
+	ctx := context.Background()
+	a, b := net.Pipe()
+	left, right := jsonrpc2.NewMultiplexer(a), jsonrpc2.NewMultiplexer(b)
+	defer left.Close()
+	defer right.Close()
+
+	dial := func() (io.ReadWriteCloser, io.ReadWriteCloser) {
+		t.Helper()
+		d, err := left.Dial(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		acc, err := right.Accept(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d, acc
+	}
+
+	// Data written to a connection that is not being read
+	// holds up neither the writer nor the other connections.
+	idle, _ := dial()
+	big := bytes.Repeat([]byte("x"), 1<<20)
+	if n, err := idle.Write(big); n != len(big) || err != nil {
+		t.Fatalf("Write to idle connection = %d, %v", n, err)
+	}
+	d, acc := dial()
+	if _, err := d.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+	got, err := io.ReadAll(acc)
+	if string(got) != "hello" || err != nil {
+		t.Errorf("ReadAll of closed connection = %q, %v, want hello, nil", got, err)
+	}
+	if _, err := acc.Write([]byte("late")); err == nil {
+		t.Errorf("Write to connection closed by the other end succeeded")
+	}
+	if _, err := d.Write([]byte("late")); err == nil {
+		t.Errorf("Write to closed connection succeeded")
+	}
+
+	// Closing the stream ends pending reads and accepts.
+	d, _ = dial()
+	readErr := make(chan error)
+	go func() {
+		_, err := d.Read(make([]byte, 1))
+		readErr <- err
+	}()
+	acceptErr := make(chan error)
+	go func() {
+		_, err := left.Accept(ctx)
+		acceptErr <- err
+	}()
+	right.Close()
+	if err := <-readErr; err == nil {
+		t.Errorf("Read succeeded after the stream was closed")
+	}
+	if err := <-acceptErr; err == nil {
+		t.Errorf("Accept succeeded after the stream was closed")
+	}
+
+	a, _ = net.Pipe()
+	idleMux := jsonrpc2.NewMultiplexer(a)
+	defer idleMux.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := idleMux.Accept(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Accept with canceled context = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonrpc2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A Router is a Handler that dispatches each request to the handler
+// registered for its method, much as a [net/http.ServeMux] dispatches
+// by path.
+//
+// A pattern is either a method name, such as "initialize", which
+// matches only that method, or a prefix ending in "/", such as
+// "textDocument/", which matches all methods that begin with it.
+// The method itself takes precedence over prefixes, and longer
+// prefixes take precedence over shorter ones. Requests matching no
+// pattern are not handled: the Router returns [ErrNotHandled], so
+// that calls are answered with [ErrMethodNotFound].
+//
+// A Router is safe for concurrent use, so handlers may be registered
+// while connections are being served.
+type Router struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler // by pattern
+}
+
+// NewRouter returns a new Router with no handlers.
+func NewRouter() *Router {
+	return &Router{handlers: make(map[string]Handler)}
+}
+
+// Register registers the handler for the given pattern.
+// It panics if the pattern is empty or already registered.
+func (r *Router) Register(pattern string, handler Handler) {
+	if pattern == "" {
+		panic("jsonrpc2: empty Router pattern")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[pattern]; ok {
+		panic(fmt.Sprintf("jsonrpc2: multiple registrations for %q", pattern))
+	}
+	r.handlers[pattern] = handler
+}
+
+// Handler returns the handler for the given method,
+// or nil if no pattern matches it.
+func (r *Router) Handler(method string) Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if h, ok := r.handlers[method]; ok {
+		return h
+	}
+	for prefix := method; ; {
+		i := strings.LastIndexByte(strings.TrimSuffix(prefix, "/"), '/')
+		if i < 0 {
+			return nil
+		}
+		prefix = prefix[:i+1]
+		if h, ok := r.handlers[prefix]; ok {
+			return h
+		}
+	}
+}
+
+// Handle passes the request to the handler for its method.
+func (r *Router) Handle(ctx context.Context, req *Request) (interface{}, error) {
+	h := r.Handler(req.Method)
+	if h == nil {
+		return nil, ErrNotHandled
+	}
+	return h.Handle(ctx, req)
+}
+
+var _ Handler = (*Router)(nil)
@@ -12,9 +12,9 @@ import (
 	"testing"
 	"time"
 
-	jsonrpc2 "golang.org/x/tools/internal/jsonrpc2_v2"
 	"golang.org/x/tools/internal/stack/stacktest"
 	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/jsonrpc2"
 )
 
 func TestIdleTimeout(t *testing.T) {
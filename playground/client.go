@@ -0,0 +1,173 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/txtar"
+)
+
+// A Backend runs snippets. The Client type is a Backend that uses a
+// playground service; other implementations might run snippets in a
+// local sandbox.
+type Backend interface {
+	// Run is like the Run method of Client.
+	Run(ctx context.Context, body string, vet bool) (*Result, error)
+}
+
+// A Client runs programs using a playground service: either the
+// golang.org playground, or a self-hosted sandbox that implements the
+// same protocol, such as one built from golang.org/x/playground.
+//
+// The zero Client uses the golang.org playground and http.DefaultClient.
+type Client struct {
+	BaseURL    string        // URL of the playground service, such as "https://play.golang.org"
+	HTTPClient *http.Client  // client for requests to the service, or nil for http.DefaultClient
+	Header     http.Header   // additional request headers, such as Authorization for a private sandbox
+	Timeout    time.Duration // if positive, the time limit for each request
+}
+
+// A File is a file of a snippet.
+type File struct {
+	Name string // such as "prog.go", "prog_test.go", or "go.mod"
+	Data []byte
+}
+
+// Snippet returns the body of a playground snippet comprising the
+// given files, which the service builds as a module whose files are
+// in the same directory. A snippet of more than one file is encoded
+// in the txtar format; see golang.org/x/tools/txtar.
+//
+// If the files include a go.mod file, the service builds the snippet
+// in module mode, fetching its requirements from the module proxy
+// configured for the service; for a self-hosted sandbox, this may be
+// an internal proxy that serves private modules.
+//
+// The playground runs the tests of a snippet that has no main function
+// and one or more test functions.
+func Snippet(files ...File) string {
+	if len(files) == 1 && files[0].Name == "prog.go" {
+		return string(files[0].Data)
+	}
+	a := new(txtar.Archive)
+	for _, f := range files {
+		a.Files = append(a.Files, txtar.File{Name: f.Name, Data: f.Data})
+	}
+	return string(txtar.Format(a))
+}
+
+// Result is the result of running a snippet.
+type Result struct {
+	// Errors is the output of the build, if it failed,
+	// or "process took too long" if the program timed out,
+	// in which case Events holds the output before the timeout.
+	Errors string
+
+	// Events is the output of the program.
+	Events []Event
+
+	// Status is the exit status of the program.
+	Status int
+
+	// IsTest reports whether the snippet was run as a test,
+	// in which case TestsFailed is the number of failed tests.
+	IsTest      bool
+	TestsFailed int
+
+	// VetErrors is the output of vet, if it was requested and reported
+	// problems; VetOK reports whether vet ran and found none.
+	VetErrors string
+	VetOK     bool
+}
+
+// An Event is a write by a program to its standard output or error.
+type Event struct {
+	Message string
+	Kind    string        // "stdout" or "stderr"
+	Delay   time.Duration // time to wait before showing the message
+}
+
+// Output returns the concatenation of the messages of the events of
+// the given kind, such as "stdout".
+func (r *Result) Output(kind string) string {
+	var b strings.Builder
+	for _, e := range r.Events {
+		if e.Kind == kind {
+			b.WriteString(e.Message)
+		}
+	}
+	return b.String()
+}
+
+// Run builds and runs the snippet whose body is given, such as one
+// returned by Snippet, and also vets it if vet is set. Errors in the
+// snippet, including build failures, are reported in the Result; the
+// returned error reports only a failure to communicate with the service.
+func (c *Client) Run(ctx context.Context, body string, vet bool) (*Result, error) {
+	form := url.Values{"version": {"2"}, "body": {body}}
+	if vet {
+		form.Set("withVet", "true")
+	}
+	var res Result
+	if err := c.do(ctx, "/compile", form, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Vet runs vet on the snippet whose body is given, and returns its
+// output, which is empty if it found no problems.
+func (c *Client) Vet(ctx context.Context, body string) (string, error) {
+	var res struct{ Errors string }
+	if err := c.do(ctx, "/vet", url.Values{"body": {body}}, &res); err != nil {
+		return "", err
+	}
+	return res.Errors, nil
+}
+
+// do posts the form to the service endpoint at path and decodes the
+// JSON response into v.
+func (c *Client) do(ctx context.Context, path string, form url.Values, v interface{}) error {
+	base := c.BaseURL
+	if base == "" {
+		base = baseURL
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("playground %s: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("playground %s: decoding response: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package playground_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/playground"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.FormValue("body")
+		switch r.URL.Path {
+		case "/compile":
+			if r.FormValue("version") != "2" || r.FormValue("withVet") != "true" {
+				t.Errorf("unexpected form %v", r.Form)
+			}
+			if !strings.Contains(body, "-- prog_test.go --\n") {
+				t.Errorf("body is not a multi-file snippet:\n%s", body)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Events": []map[string]interface{}{
+					{"Message": "--- FAIL: TestX\n", "Kind": "stdout", "Delay": 0},
+					{"Message": "oops\n", "Kind": "stderr", "Delay": 1000},
+					{"Message": "FAIL\n", "Kind": "stdout", "Delay": 0},
+				},
+				"Status":      1,
+				"IsTest":      true,
+				"TestsFailed": 1,
+				"VetOK":       true,
+			})
+		case "/vet":
+			if body != "package main\n" {
+				t.Errorf("unexpected body %q", body)
+			}
+			json.NewEncoder(w).Encode(map[string]string{"Errors": "./prog.go:1: oops"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &playground.Client{BaseURL: srv.URL}
+	ctx := context.Background()
+	snippet := playground.Snippet(
+		playground.File{Name: "prog.go", Data: []byte("package main\n")},
+		playground.File{Name: "prog_test.go", Data: []byte("package main\n\nimport \"testing\"\n\nfunc TestX(t *testing.T) { t.Fatal(\"oops\") }\n")},
+	)
+	res, err := c.Run(ctx, snippet, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsTest || res.TestsFailed != 1 || res.Status != 1 || !res.VetOK {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if got, want := res.Output("stdout"), "--- FAIL: TestX\nFAIL\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	// A single prog.go file is sent as is.
+	vet, err := c.Vet(ctx, playground.Snippet(playground.File{Name: "prog.go", Data: []byte("package main\n")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vet != "./prog.go:1: oops" {
+		t.Errorf("Vet = %q", vet)
+	}
+
+	c.BaseURL = srv.URL + "/missing"
+	if _, err := c.Run(ctx, snippet, false); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Run with bad URL: got error %v, want 404", err)
+	}
+}
+
+func TestProxyTo(t *testing.T) {
+	// A self-hosted sandbox that requires authorization.
+	sandbox := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Events": []map[string]interface{}{{"Message": r.FormValue("body"), "Kind": "stdout"}},
+		})
+	}))
+	defer sandbox.Close()
+
+	for _, test := range []struct {
+		header http.Header
+		want   int
+	}{
+		{http.Header{"Authorization": {"Bearer secret"}}, http.StatusOK},
+		{nil, http.StatusInternalServerError},
+	} {
+		proxy := httptest.NewServer(playground.ProxyTo(&playground.Client{
+			BaseURL: sandbox.URL,
+			Header:  test.header,
+			Timeout: time.Minute,
+		}))
+		resp, err := http.PostForm(proxy.URL+"/compile", url.Values{"version": {"2"}, "body": {"hello"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != test.want {
+			t.Errorf("got status %d, want %d", resp.StatusCode, test.want)
+		} else if test.want == http.StatusOK {
+			var res playground.Result
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+			if got := res.Output("stdout"); got != "hello" {
+				t.Errorf("got output %q, want %q", got, "hello")
+			}
+		}
+		resp.Body.Close()
+		proxy.Close()
+	}
+}
@@ -5,11 +5,17 @@
 // Package playground registers an HTTP handler at "/compile" that
 // proxies requests to the golang.org playground service.
 // This package may be used unaltered on App Engine Standard with Go 1.11+ runtime.
+//
+// The package also provides a Client that runs, tests, and vets
+// programs, which may comprise several files, using the service or a
+// self-hosted sandbox. Setting DefaultBackend to such a Client directs
+// the "/compile" handler to the sandbox instead.
 package playground // import "golang.org/x/tools/playground"
 
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -23,6 +29,11 @@ func init() {
 	http.Handle("/compile", Proxy())
 }
 
+// DefaultBackend, if not nil, is the Backend that runs the snippets
+// posted to the handler returned by Proxy, in place of the golang.org
+// playground. It must be set before the handler is used.
+var DefaultBackend Backend
+
 // Proxy returns a handler that can be registered on /compile to proxy requests to the Go playground.
 //
 // This package already contains a func init that does:
@@ -36,7 +47,19 @@ func Proxy() http.Handler {
 	return http.HandlerFunc(bounce)
 }
 
+// ProxyTo returns a handler like Proxy that runs snippets using the
+// specified Backend.
+func ProxyTo(b Backend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveBackend(w, r, b)
+	})
+}
+
 func bounce(w http.ResponseWriter, r *http.Request) {
+	if DefaultBackend != nil {
+		serveBackend(w, r, DefaultBackend)
+		return
+	}
 	b := new(bytes.Buffer)
 	if err := passThru(b, r); err != nil {
 		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
@@ -46,6 +69,21 @@ func bounce(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, b)
 }
 
+// serveBackend handles a request of the playground's compile protocol
+// by running the snippet using b.
+func serveBackend(w http.ResponseWriter, r *http.Request, b Backend) {
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	res, err := b.Run(ctx, r.FormValue("body"), r.FormValue("withVet") == "true")
+	if err != nil {
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
 func passThru(w io.Writer, req *http.Request) error {
 	defer req.Body.Close()
 	url := baseURL + req.URL.Path
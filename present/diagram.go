@@ -0,0 +1,82 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package present
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	Register("diagram", parseDiagram)
+}
+
+// A Diagram is a diagram described in the language of a diagramming
+// tool, such as Mermaid or Graphviz. It is rendered as SVG by the
+// Context's RenderDiagram function if possible, and otherwise in the
+// browser.
+type Diagram struct {
+	Cmd    string // original command from present source
+	Kind   string // "mermaid" or "graphviz"
+	Source string
+	SVG    template.HTML // the rendered diagram, if rendered by the server
+	Width  int
+	Height int
+}
+
+func (d Diagram) PresentCmd() string   { return d.Cmd }
+func (d Diagram) TemplateName() string { return "diagram" }
+
+// diagramKinds maps the extensions of diagram files to their kinds.
+var diagramKinds = map[string]string{
+	".mmd":     "mermaid",
+	".mermaid": "mermaid",
+	".dot":     "graphviz",
+	".gv":      "graphviz",
+}
+
+func parseDiagram(ctx *Context, fileName string, lineno int, text string) (Elem, error) {
+	args := strings.Fields(text)
+	if len(args) < 2 {
+		return nil, fmt.Errorf("incorrect diagram invocation: %q", text)
+	}
+	d := Diagram{Cmd: text, Kind: diagramKinds[filepath.Ext(args[1])]}
+	if d.Kind == "" {
+		return nil, fmt.Errorf("%s:%d: unknown kind of diagram %q", fileName, lineno, args[1])
+	}
+	a, err := parseArgs(fileName, lineno, args[2:])
+	if err != nil {
+		return nil, err
+	}
+	switch len(a) {
+	case 0:
+		// no size parameters
+	case 2:
+		if v, ok := a[0].(int); ok {
+			d.Height = v
+		}
+		if v, ok := a[1].(int); ok {
+			d.Width = v
+		}
+	default:
+		return nil, fmt.Errorf("incorrect diagram invocation: %q", text)
+	}
+
+	b, err := ctx.ReadFile(filepath.Join(filepath.Dir(fileName), args[1]))
+	if err != nil {
+		return nil, err
+	}
+	d.Source = string(b)
+	if ctx.RenderDiagram != nil {
+		svg, err := ctx.RenderDiagram(d.Kind, b)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fileName, lineno, err)
+		}
+		d.SVG = template.HTML(svg)
+	}
+	return d, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package present
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDiagram(t *testing.T) {
+	ctx := &Context{
+		ReadFile: func(filename string) ([]byte, error) {
+			if filename != "dir/arch.dot" {
+				return nil, errors.New("no such file")
+			}
+			return []byte("digraph { a -> b }"), nil
+		},
+		RenderDiagram: func(kind string, src []byte) ([]byte, error) {
+			return []byte("<svg>" + kind + ": " + string(src) + "</svg>"), nil
+		},
+	}
+	e, err := parseDiagram(ctx, "dir/talk.slide", 1, ".diagram arch.dot 100 200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := e.(Diagram)
+	if d.Kind != "graphviz" || d.Height != 100 || d.Width != 200 {
+		t.Errorf("got kind %q, size %dx%d; want graphviz, 100x200", d.Kind, d.Height, d.Width)
+	}
+	if want := "<svg>graphviz: digraph { a -> b }</svg>"; string(d.SVG) != want {
+		t.Errorf("got SVG %q, want %q", d.SVG, want)
+	}
+
+	for _, test := range []struct {
+		cmd, want string
+	}{
+		{".diagram", "incorrect diagram invocation"},
+		{".diagram arch.png", "unknown kind of diagram"},
+		{".diagram arch.dot 100", "incorrect diagram invocation"},
+		{".diagram missing.mmd", "no such file"},
+	} {
+		if _, err := parseDiagram(ctx, "dir/talk.slide", 1, test.cmd); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want error containing %q", test.cmd, err, test.want)
+		}
+	}
+}
@@ -346,6 +346,25 @@ iframe:
 The function "iframe" injects iframes (pages inside pages).
 Its syntax is the same as that of image.
 
+diagram:
+
+The function "diagram" injects a diagram described in a file, which
+is either a Mermaid diagram, with extension .mmd or .mermaid, or a
+Graphviz graph, with extension .dot or .gv. Its syntax is otherwise
+the same as that of image.
+
+	.diagram arch.mmd
+	.diagram deps.dot 300 _
+
+The diagram is rendered as SVG by the RenderDiagram function of the
+Context, if any; the present command renders Graphviz graphs this way
+if the dot command is installed. Other diagrams are rendered in the
+browser, by scripts that the templates load from a CDN.
+
+In Markdown-enabled present, a fenced code block whose language is
+mermaid, dot, or graphviz is likewise rendered as a diagram in the
+browser.
+
 html:
 
 The function html includes the contents of the specified file as
@@ -378,5 +397,20 @@ Notes may appear anywhere within the slide text. For example:
 	Some more text.
 
 	: Presenter notes (subsequent paragraph(s))
+
+# Converting to and from Markdown
+
+ToMarkdown converts a present file, in either syntax, to a CommonMark
+document with YAML front matter, the format used by static site
+generators such as Hugo and Jekyll. The front matter holds the title,
+subtitle, date, tags, summary, old URLs (as "aliases"), and authors.
+Comments and presenter notes become HTML comments, and command
+invocations become equivalent Markdown or HTML; for instance, the code
+shown by .code and .play becomes a fenced code block.
+
+FromMarkdown converts such a document, including one written by
+ToMarkdown, to a Markdown-enabled present file, adjusting heading
+levels so that the highest level headings begin sections and escaping
+lines that present would otherwise interpret as commands or comments.
 */
 package present // import "golang.org/x/tools/present"
@@ -66,6 +66,19 @@ func renderLink(href, text string) string {
 // a rendered HTML link and the total length of the raw inline link.
 // If no inline link is present, it returns all zeroes.
 func parseInlineLink(s string) (link string, length int) {
+	rawURL, text, length := scanInlineLink(s)
+	if length == 0 {
+		return "", 0
+	}
+	return renderLink(rawURL, text), length
+}
+
+// scanInlineLink scans an inline link at the start of s, and returns
+// its URL, its label, and the total length of the raw inline link.
+// The label of a link without one is the URL without its scheme, if
+// that can be determined, and otherwise empty.
+// If no inline link is present, it returns all zeroes.
+func scanInlineLink(s string) (rawURL, text string, length int) {
 	if !strings.HasPrefix(s, "[[") {
 		return
 	}
@@ -74,10 +87,10 @@ func parseInlineLink(s string) (link string, length int) {
 		return
 	}
 	urlEnd := strings.Index(s, "]")
-	rawURL := s[2:urlEnd]
+	rawURL = s[2:urlEnd]
 	const badURLChars = `<>"{}|\^[] ` + "`" // per RFC2396 section 2.4.3
 	if strings.ContainsAny(rawURL, badURLChars) {
-		return
+		return "", "", 0
 	}
 	if urlEnd == end {
 		simpleURL := ""
@@ -92,11 +105,10 @@ func parseInlineLink(s string) (link string, length int) {
 				simpleURL = strings.TrimPrefix(rawURL, url.Scheme+":")
 			}
 		}
-		return renderLink(rawURL, simpleURL), end + 2
+		return rawURL, simpleURL, end + 2
 	}
 	if s[urlEnd:urlEnd+2] != "][" {
-		return
+		return "", "", 0
 	}
-	text := s[urlEnd+2 : end]
-	return renderLink(rawURL, text), end + 2
+	return rawURL, s[urlEnd+2 : end], end + 2
 }
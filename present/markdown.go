@@ -0,0 +1,1020 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package present
+
+// This file converts between present files and CommonMark documents
+// with YAML front matter, the format of the content of static site
+// generators such as Hugo and Jekyll.
+//
+// The front matter holds the header and authors of the present file:
+//
+//	---
+//	title: "Title of document"
+//	subtitle: "Subtitle of document"
+//	date: 2006-01-02T15:04:00Z
+//	tags: ["foo", "bar"]
+//	summary: "This is a great document you want to read."
+//	aliases: ["former-path-for-this-doc"]
+//	authors:
+//	  - ["Author Name", "Job title, Company", "joe@example.com"]
+//	---
+//
+// Comments and presenter notes become HTML comments, and command
+// invocations become the equivalent Markdown or HTML, such as fenced
+// code blocks holding the code shown by .code and .play.
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ToMarkdown converts the present file read from r, in Markdown-enabled
+// or legacy present syntax, to a CommonMark document with YAML front
+// matter. It reads the files used by command invocations, such as the
+// code shown by .code, using os.ReadFile.
+func ToMarkdown(r io.Reader, name string) ([]byte, error) {
+	ctx := Context{ReadFile: os.ReadFile}
+	return ctx.ToMarkdown(r, name)
+}
+
+// ToMarkdown converts the present file read from r, in Markdown-enabled
+// or legacy present syntax, to a CommonMark document with YAML front
+// matter. It reads the files used by command invocations, such as the
+// code shown by .code, using ctx.ReadFile.
+func (ctx *Context) ToMarkdown(r io.Reader, name string) ([]byte, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	isMarkdown := isMarkdownFile(lines)
+	sectionPrefix := "*"
+	if isMarkdown {
+		sectionPrefix = "##"
+		lines.comment = "//"
+	}
+	doc := &Doc{TitleNotes: titleNotes(lines, sectionPrefix)}
+	if err := parseHeader(doc, isMarkdown, lines); err != nil {
+		return nil, err
+	}
+	if doc.Authors, err = parseAuthors(name, sectionPrefix, lines); err != nil {
+		return nil, err
+	}
+
+	c := &markdownWriter{
+		// Diagrams are written as their source, not rendered.
+		ctx:  &Context{ReadFile: ctx.ReadFile},
+		name: name,
+	}
+	c.frontMatter(doc)
+	for _, note := range doc.TitleNotes {
+		c.note(note)
+	}
+	if isMarkdown {
+		err = c.markdownBody(lines)
+	} else {
+		err = c.legacyBody(lines)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes.TrimRight(c.buf.Bytes(), "\n"), '\n'), nil
+}
+
+// A markdownWriter writes the CommonMark form of a present file.
+type markdownWriter struct {
+	ctx  *Context
+	name string
+	buf  bytes.Buffer
+}
+
+func (c *markdownWriter) line(s string) {
+	c.buf.WriteString(s)
+	c.buf.WriteByte('\n')
+}
+
+// blank ends the current block, if any, with a blank line.
+func (c *markdownWriter) blank() {
+	if b := c.buf.Bytes(); len(b) > 0 && !bytes.HasSuffix(b, []byte("\n\n")) {
+		c.buf.WriteByte('\n')
+	}
+}
+
+func (c *markdownWriter) comment(s string) {
+	c.line(strings.TrimSpace("<!-- "+s) + " -->")
+}
+
+func (c *markdownWriter) note(s string) {
+	c.comment(strings.TrimSpace(": " + s))
+}
+
+// fence writes src as a fenced code block in the language lang.
+func (c *markdownWriter) fence(lang, src string) {
+	fence := "```"
+	for strings.Contains(src, fence) {
+		fence += "`"
+	}
+	c.blank()
+	c.line(fence + lang)
+	c.buf.WriteString(src)
+	if src != "" && !strings.HasSuffix(src, "\n") {
+		c.buf.WriteByte('\n')
+	}
+	c.line(fence)
+	c.blank()
+}
+
+func (c *markdownWriter) frontMatter(doc *Doc) {
+	c.line("---")
+	c.line("title: " + strconv.Quote(doc.Title))
+	if doc.Subtitle != "" {
+		c.line("subtitle: " + strconv.Quote(doc.Subtitle))
+	}
+	if !doc.Time.IsZero() {
+		t := doc.Time.UTC()
+		if isDateOnly(t) {
+			c.line("date: " + t.Format("2006-01-02"))
+		} else {
+			c.line("date: " + t.Format(time.RFC3339))
+		}
+	}
+	var tags []string
+	for _, tag := range doc.Tags {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		c.line("tags: " + flowSequence(tags))
+	}
+	if doc.Summary != "" {
+		c.line("summary: " + strconv.Quote(doc.Summary))
+	}
+	if len(doc.OldURL) > 0 {
+		c.line("aliases: " + flowSequence(doc.OldURL))
+	}
+	var authors []string
+	for _, a := range doc.Authors {
+		var lines []string
+		for _, e := range a.Elem {
+			switch e := e.(type) {
+			case Text:
+				for _, line := range e.Lines {
+					if line != "" {
+						lines = append(lines, line)
+					}
+				}
+			case Link:
+				lines = append(lines, e.Label)
+			}
+		}
+		if len(lines) > 0 {
+			authors = append(authors, flowSequence(lines))
+		}
+	}
+	if len(authors) > 0 {
+		c.line("authors:")
+		for _, a := range authors {
+			c.line("  - " + a)
+		}
+	}
+	c.line("---")
+	c.blank()
+}
+
+// isDateOnly reports whether t is a time parsed from a date without a
+// time of day by parseTime.
+func isDateOnly(t time.Time) bool {
+	return t.Hour() == 11 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// flowSequence returns the YAML flow sequence of the strings list.
+func flowSequence(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = strconv.Quote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// markdownBody writes the sections of a Markdown-enabled present file,
+// which are CommonMark already but for comments, notes, and commands.
+func (c *markdownWriter) markdownBody(lines *Lines) error {
+	fence := ""
+	for i := lines.line; i < len(lines.text); i++ {
+		text := lines.text[i]
+		if fence != "" {
+			c.line(text)
+			if isFenceEnd(text, fence) {
+				fence = ""
+			}
+			continue
+		}
+		switch {
+		case fenceStart(text) != "":
+			fence = fenceStart(text)
+			c.line(text)
+		case strings.HasPrefix(text, "//"):
+			c.comment(strings.TrimSpace(text[2:]))
+		case isSpeakerNote(text):
+			c.note(trimSpeakerNote(text))
+		case strings.HasPrefix(text, "."):
+			if err := c.command(i+1, text); err != nil {
+				return err
+			}
+		case isHeadingMarkdown.MatchString(text):
+			c.blank()
+			c.line(text)
+			c.blank()
+		case text == "":
+			c.blank()
+		default:
+			c.line(text)
+		}
+	}
+	return nil
+}
+
+// legacyBody writes the sections of a legacy present file,
+// converting its markup to CommonMark.
+func (c *markdownWriter) legacyBody(lines *Lines) error {
+	for i := lines.line; i < len(lines.text); {
+		text := lines.text[i]
+		r, _ := utf8.DecodeRuneInString(text)
+		switch {
+		case text == "":
+			c.blank()
+			i++
+		case strings.HasPrefix(text, "#"):
+			c.comment(strings.TrimSpace(text[1:]))
+			i++
+		case isHeadingLegacy.MatchString(text):
+			level := len(text) - len(strings.TrimLeft(text, "*"))
+			c.blank()
+			c.line(strings.TrimSpace(strings.Repeat("#", level+1) + " " + strings.TrimSpace(text[level:])))
+			c.blank()
+			i++
+		case isSpeakerNote(text):
+			c.note(trimSpeakerNote(text))
+			i++
+		case unicode.IsSpace(r):
+			// Preformatted text, as in parseSections.
+			n := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsSpace(r) })
+			if n < 0 {
+				c.blank()
+				i++
+				break
+			}
+			indent := text[:n]
+			var pre []string
+			for ; i < len(lines.text); i++ {
+				text := lines.text[i]
+				if text == "" {
+					pre = append(pre, "")
+				} else if strings.HasPrefix(text, indent) {
+					pre = append(pre, text[n:])
+				} else {
+					break
+				}
+			}
+			for len(pre) > 0 && pre[len(pre)-1] == "" {
+				pre = pre[:len(pre)-1]
+			}
+			c.fence("", strings.Join(pre, "\n"))
+		case strings.HasPrefix(text, "- "):
+			c.blank()
+			for ; i < len(lines.text); i++ {
+				text := lines.text[i]
+				if strings.HasPrefix(text, "- ") {
+					c.line("- " + markdownFont(text[2:]))
+				} else if strings.HasPrefix(text, " ") && strings.TrimSpace(text) != "" {
+					c.line("  " + markdownFont(strings.TrimSpace(text)))
+				} else {
+					break
+				}
+			}
+			c.blank()
+		case strings.HasPrefix(text, "."):
+			if err := c.command(i+1, text); err != nil {
+				return err
+			}
+			i++
+		default:
+			c.blank()
+			for ; i < len(lines.text); i++ {
+				text := lines.text[i]
+				if strings.TrimSpace(text) == "" || text[0] == '.' || isSpeakerNote(text) {
+					break
+				}
+				if strings.HasPrefix(text, "#") {
+					c.comment(strings.TrimSpace(text[1:]))
+					continue
+				}
+				text = strings.TrimPrefix(text, `\`) // Backslash escapes initial period.
+				c.line(escapeLineStart(markdownFont(text)))
+			}
+			c.blank()
+		}
+	}
+	return nil
+}
+
+// command writes the Markdown or HTML equivalent of a command invocation.
+func (c *markdownWriter) command(lineno int, text string) error {
+	args := strings.Fields(text)
+	parser := parsers[args[0]]
+	if parser == nil {
+		if args[0] == ".background" {
+			c.comment(text)
+			return nil
+		}
+		return fmt.Errorf("%s:%d: unknown command %q", c.name, lineno, text)
+	}
+	e, err := parser(c.ctx, c.name, lineno, text)
+	if err != nil {
+		return err
+	}
+	c.blank()
+	switch e := e.(type) {
+	case Code:
+		var src strings.Builder
+		for _, line := range strings.SplitAfter(string(e.Raw), "\n") {
+			if m := hlCommentRE.FindStringSubmatch(strings.TrimSuffix(line, "\n")); m != nil {
+				line = m[1] + "\n"
+			}
+			src.WriteString(line)
+		}
+		c.fence(strings.TrimPrefix(e.Ext, "."), src.String())
+	case Diagram:
+		lang := e.Kind
+		if lang == "graphviz" {
+			lang = "dot"
+		}
+		c.fence(lang, e.Source)
+	case Image:
+		if e.Width == 0 && e.Height == 0 {
+			c.line("![](" + markdownURL(e.URL) + ")")
+		} else {
+			c.line(fmt.Sprintf(`<img src="%s"%s>`, html.EscapeString(e.URL), sizeAttrs(e.Height, e.Width)))
+		}
+	case Iframe:
+		c.line(fmt.Sprintf(`<iframe src="%s"%s></iframe>`, html.EscapeString(e.URL), sizeAttrs(e.Height, e.Width)))
+	case Video:
+		c.line(fmt.Sprintf(`<video controls%s>`, sizeAttrs(e.Height, e.Width)))
+		c.line(fmt.Sprintf(`<source src="%s" type="%s">`, html.EscapeString(e.URL), html.EscapeString(e.SourceType)))
+		c.line(`</video>`)
+	case Link:
+		c.line("[" + markdownFont(e.Label) + "](" + markdownURL(e.URL.String()) + ")")
+	case Caption:
+		c.line("<figcaption>" + string(Style(e.Text)) + "</figcaption>")
+	case HTML:
+		c.line(strings.TrimRight(string(e.HTML), "\n"))
+	default:
+		// A command registered by another package.
+		c.comment(text)
+	}
+	c.blank()
+	return nil
+}
+
+func sizeAttrs(height, width int) string {
+	var s string
+	if height != 0 {
+		s += fmt.Sprintf(` height="%d"`, height)
+	}
+	if width != 0 {
+		s += fmt.Sprintf(` width="%d"`, width)
+	}
+	return s
+}
+
+// markdownURL returns the destination of a Markdown link to url.
+func markdownURL(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return url
+}
+
+// markdownFont is like font, but returns s, in legacy present markup,
+// in CommonMark instead of HTML.
+func markdownFont(s string) string {
+	words := split(s)
+Word:
+	for w, word := range words {
+		if rawURL, text, n := scanInlineLink(word); n > 0 {
+			if text == "" {
+				text = rawURL
+			}
+			words[w] = "[" + markdownFont(text) + "](" + markdownURL(rawURL) + ")"
+			continue Word
+		}
+		plain := escapeMarkdown(word)
+		words[w] = plain
+		if len(word) < 2 {
+			continue Word
+		}
+		const marker = "_*`"
+		// The markers are found as in font.
+		first := strings.IndexAny(word, marker)
+		if first == -1 {
+			continue Word
+		}
+		if first != 0 {
+			r, _ := utf8.DecodeLastRuneInString(word[:first])
+			if !unicode.IsPunct(r) {
+				continue Word
+			}
+		}
+		open, word := word[:first], word[first:]
+		char := word[0]
+		last := strings.LastIndex(word, word[:1])
+		if last == 0 {
+			continue Word
+		}
+		if last+1 != len(word) {
+			r, _ := utf8.DecodeRuneInString(word[last+1:])
+			if !unicode.IsPunct(r) {
+				continue Word
+			}
+		}
+		head, tail := word[:last+1], word[last+1:]
+		var b strings.Builder
+		var wid int
+		for i := 1; i < len(head)-1; i += wid {
+			var r rune
+			r, wid = utf8.DecodeRuneInString(head[i:])
+			if r != rune(char) {
+				b.WriteRune(r)
+				continue
+			}
+			if head[i+1] != char {
+				b.WriteRune(' ')
+				continue
+			}
+			b.WriteByte(char)
+			wid++
+		}
+		inner := b.String()
+		switch char {
+		case '_':
+			inner = "*" + escapeMarkdown(inner) + "*"
+		case '*':
+			inner = "**" + escapeMarkdown(inner) + "**"
+		case '`':
+			if strings.Contains(inner, "`") {
+				inner = "`` " + inner + " ``"
+			} else {
+				inner = "`" + inner + "`"
+			}
+		}
+		words[w] = escapeMarkdown(open) + inner + escapeMarkdown(tail)
+	}
+	return strings.Join(words, "")
+}
+
+// escapeMarkdown escapes the characters of s that CommonMark might
+// interpret as inline markup.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '\\', '`', '*', '[', ']', '<':
+			b.WriteByte('\\')
+		case '_':
+			// Underscores within words are not emphasis.
+			if i == 0 || i == len(s)-1 || !isAlnum(s[i-1]) || !isAlnum(s[i+1]) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isAlnum(ch byte) bool {
+	return '0' <= ch && ch <= '9' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+var orderedListItem = regexp.MustCompile(`^[0-9]{1,9}[.)]( |$)`)
+
+// escapeLineStart escapes the start of a line of a paragraph that
+// CommonMark might interpret as the start of another block.
+func escapeLineStart(s string) string {
+	switch {
+	case strings.HasPrefix(s, "#"), strings.HasPrefix(s, ">"),
+		strings.HasPrefix(s, "- "), strings.HasPrefix(s, "+ "),
+		strings.HasPrefix(s, "~~~"), setextHeading.MatchString(s):
+		return `\` + s
+	case orderedListItem.MatchString(s):
+		i := strings.IndexAny(s, ".)")
+		return s[:i] + `\` + s[i:]
+	}
+	return s
+}
+
+// fenceStart returns the fence that begins a fenced code block
+// at line s, or "" if there is none.
+func fenceStart(s string) string {
+	t := strings.TrimLeft(s, " ")
+	if len(s)-len(t) > 3 {
+		return ""
+	}
+	for _, ch := range "`~" {
+		fence := strings.TrimLeft(t, string(ch))
+		if n := len(t) - len(fence); n >= 3 {
+			if ch == '`' && strings.ContainsRune(fence, '`') {
+				return ""
+			}
+			return t[:n]
+		}
+	}
+	return ""
+}
+
+// isFenceEnd reports whether line s ends the fenced code
+// block that began with fence.
+func isFenceEnd(s, fence string) bool {
+	t := strings.TrimLeft(s, " ")
+	if len(s)-len(t) > 3 {
+		return false
+	}
+	rest := strings.TrimLeft(t, fence[:1])
+	return len(t)-len(rest) >= len(fence) && strings.TrimSpace(rest) == ""
+}
+
+// FromMarkdown converts a CommonMark document read from r, with
+// optional YAML front matter such as that written by ToMarkdown, to
+// a Markdown-enabled present file.
+//
+// If the front matter gives no title, the document must begin with a
+// level 1 heading giving it. The levels of the other headings are
+// adjusted so that the highest level ones begin sections.
+func FromMarkdown(r io.Reader) ([]byte, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, body, err := parseFrontMatter(lines.text)
+	if err != nil {
+		return nil, err
+	}
+	blocks := markdownBlocks(body)
+	if doc.Title == "" {
+		for len(blocks) > 0 && blocks[0].text == "" && blocks[0].level == 0 {
+			blocks = blocks[1:]
+		}
+		if len(blocks) == 0 || blocks[0].level != 1 {
+			return nil, fmt.Errorf("no title in front matter or level 1 heading")
+		}
+		doc.Title = blocks[0].text
+		blocks = blocks[1:]
+	}
+
+	w := new(markdownWriter)
+	w.line("# " + doc.Title)
+	if doc.Subtitle != "" {
+		w.line(doc.Subtitle)
+	}
+	if !doc.Time.IsZero() {
+		if isDateOnly(doc.Time) {
+			w.line(doc.Time.Format("2 Jan 2006"))
+		} else {
+			w.line(doc.Time.Format("15:04 2 Jan 2006"))
+		}
+	}
+	if len(doc.Tags) > 0 {
+		w.line("Tags: " + strings.Join(doc.Tags, ", "))
+	}
+	if doc.Summary != "" {
+		w.line("Summary: " + doc.Summary)
+	}
+	for _, u := range doc.OldURL {
+		w.line("OldURL: " + u)
+	}
+	for _, a := range doc.Authors {
+		w.blank()
+		for _, e := range a.Elem {
+			w.line(e.(Text).Lines[0])
+		}
+	}
+
+	// Notes before the first section are notes for the title slide.
+	w.blank()
+	for len(blocks) > 0 && blocks[0].level == 0 {
+		note := presentLine(blocks[0].text)
+		if blocks[0].code || blocks[0].text != "" && !isSpeakerNote(note) {
+			break
+		}
+		if blocks[0].text != "" {
+			w.line(note)
+		}
+		blocks = blocks[1:]
+	}
+
+	// Every section must begin with a heading,
+	// whose levels must increase one at a time.
+	minLevel := 0
+	for _, blk := range blocks {
+		if blk.level > 0 && (minLevel == 0 || blk.level < minLevel) {
+			minLevel = blk.level
+		}
+	}
+	if len(blocks) == 0 || blocks[0].level == 0 {
+		w.blank()
+		w.line("##")
+	}
+	prev := 1
+	for _, blk := range blocks {
+		switch {
+		case blk.level > 0:
+			level := min(blk.level-minLevel+2, prev+1)
+			prev = level
+			w.blank()
+			w.line(strings.TrimSpace(strings.Repeat("#", level) + " " + blk.text))
+		case blk.code:
+			w.line(blk.text)
+		default:
+			w.line(presentLine(blk.text))
+		}
+	}
+	return append(bytes.TrimRight(w.buf.Bytes(), "\n"), '\n'), nil
+}
+
+// A markdownBlock is a line of a CommonMark document,
+// other than the underline of a setext heading.
+type markdownBlock struct {
+	text  string // the line, or the text of a heading
+	level int    // the level of a heading, or 0
+	code  bool   // whether the line is part of a fenced code block
+}
+
+var (
+	atxHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))??(?:[ \t]+#+)?[ \t]*$`)
+	setextHeading = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	htmlComment   = regexp.MustCompile(`^<!--(.*)-->[ \t]*$`)
+)
+
+// markdownBlocks splits the lines of a CommonMark document into headings,
+// fenced code blocks, and other lines.
+func markdownBlocks(lines []string) []markdownBlock {
+	var blocks []markdownBlock
+	for i := 0; i < len(lines); i++ {
+		text := lines[i]
+		if fence := fenceStart(text); fence != "" {
+			// Fenced code is copied unchanged unless present would
+			// misinterpret a line; then the fences and the lines are
+			// all indented by a space, which CommonMark removes.
+			j := i + 1
+			for j < len(lines) && !isFenceEnd(lines[j], fence) {
+				j++
+			}
+			if j < len(lines) {
+				j++
+			}
+			code := lines[i:j]
+			indent := ""
+			if strings.HasPrefix(text, fence) {
+				for _, line := range code {
+					if line != presentLine(line) {
+						indent = " "
+						break
+					}
+				}
+			}
+			for _, line := range code {
+				if line != "" {
+					line = indent + line
+				}
+				blocks = append(blocks, markdownBlock{text: line, code: true})
+			}
+			i = j - 1
+			continue
+		}
+		if m := atxHeading.FindStringSubmatch(text); m != nil {
+			blocks = append(blocks, markdownBlock{text: m[2], level: len(m[1])})
+			continue
+		}
+		if i+1 < len(lines) && (i == 0 || strings.TrimSpace(lines[i-1]) == "") &&
+			isParagraphStart(text) {
+			if m := setextHeading.FindStringSubmatch(lines[i+1]); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				blocks = append(blocks, markdownBlock{text: strings.TrimSpace(text), level: level})
+				i++
+				continue
+			}
+		}
+		blocks = append(blocks, markdownBlock{text: text})
+	}
+	return blocks
+}
+
+// isParagraphStart reports whether line s can begin a paragraph, and so
+// be the text of a setext heading.
+func isParagraphStart(s string) bool {
+	t := strings.TrimLeft(s, " ")
+	if t == "" || len(s)-len(t) > 3 || s[0] == '\t' || htmlComment.MatchString(t) {
+		return false
+	}
+	switch t[0] {
+	case '>', '-', '+', '*', '<', '|':
+		return false
+	}
+	return !orderedListItem.MatchString(t) && !setextHeading.MatchString(t)
+}
+
+// presentLine returns the present form of the CommonMark line s,
+// other than a heading or code, escaping what present would otherwise
+// interpret as a command, comment, note, or heading, and converting
+// single-line HTML comments to present comments, notes, or commands.
+func presentLine(s string) string {
+	if m := htmlComment.FindStringSubmatch(s); m != nil {
+		c := strings.TrimSpace(m[1])
+		switch {
+		case isSpeakerNote(c):
+			return c
+		case strings.HasPrefix(c, "."):
+			if cmd := strings.Fields(c)[0]; parsers[cmd] != nil || cmd == ".background" {
+				return c
+			}
+		}
+		return strings.TrimSpace("// " + c)
+	}
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "//") || strings.HasPrefix(s, "#") || isSpeakerNote(s) {
+		return `\` + s
+	}
+	return s
+}
+
+// parseFrontMatter parses the YAML front matter at the start of lines,
+// if any, and returns the document it describes and the remaining lines.
+// It understands only the subset of YAML needed for the front matter.
+func parseFrontMatter(lines []string) (*Doc, []string, error) {
+	doc := new(Doc)
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return doc, lines, nil
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if s := strings.TrimSpace(lines[i]); s == "---" || s == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, nil, fmt.Errorf("front matter is not terminated")
+	}
+
+	for i := 1; i < end; i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") ||
+			line[0] == ' ' || line[0] == '\t' {
+			continue // The content of an unknown value, perhaps a mapping.
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("front matter:%d: unexpected line: %s", i+1, line)
+		}
+		key = strings.TrimSpace(key)
+		v, err := parseYAMLValue(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("front matter:%d: %s: %v", i+1, key, err)
+		}
+		if style := v.scalar; strings.HasPrefix(style, "|") || strings.HasPrefix(style, ">") {
+			// A block scalar, whose lines are indented.
+			var text []string
+			for i+1 < end && (lines[i+1] == "" || lines[i+1][0] == ' ' || lines[i+1][0] == '\t') {
+				i++
+				text = append(text, strings.TrimSpace(lines[i]))
+			}
+			sep := "\n"
+			if style[0] == '>' {
+				sep = " "
+			}
+			v.scalar = strings.TrimSpace(strings.Join(text, sep))
+		} else if v.scalar == "" && !v.isList {
+			// A block sequence may follow.
+			for i+1 < end {
+				item := strings.TrimSpace(lines[i+1])
+				if !strings.HasPrefix(item, "- ") && item != "-" {
+					break
+				}
+				i++
+				iv, err := parseYAMLValue(strings.TrimPrefix(item, "-"))
+				if err != nil {
+					return nil, nil, fmt.Errorf("front matter:%d: %s: %v", i+1, key, err)
+				}
+				v.isList = true
+				v.list = append(v.list, iv)
+			}
+		}
+
+		switch strings.ToLower(key) {
+		case "title":
+			doc.Title = v.String()
+		case "subtitle":
+			doc.Subtitle = v.String()
+		case "summary", "description":
+			if doc.Summary == "" || key == "summary" {
+				doc.Summary = v.String()
+			}
+		case "date":
+			if t, ok := parseYAMLTime(v.String()); ok {
+				doc.Time = t
+			} else {
+				return nil, nil, fmt.Errorf("front matter:%d: invalid date %q", i+1, v.String())
+			}
+		case "tags":
+			if v.isList {
+				doc.Tags = v.strings()
+			} else {
+				// As in present files, "tags: a, b".
+				for _, tag := range strings.Split(v.scalar, ",") {
+					if tag := strings.TrimSpace(tag); tag != "" {
+						doc.Tags = append(doc.Tags, tag)
+					}
+				}
+			}
+		case "aliases":
+			doc.OldURL = v.strings()
+		case "author", "authors":
+			for _, a := range v.items() {
+				var author Author
+				for _, line := range a.strings() {
+					author.Elem = append(author.Elem, Text{Lines: []string{line}})
+				}
+				if len(author.Elem) > 0 {
+					doc.Authors = append(doc.Authors, author)
+				}
+			}
+		}
+	}
+	return doc, lines[end+1:], nil
+}
+
+// A yamlValue is a YAML scalar or sequence.
+type yamlValue struct {
+	scalar string
+	isList bool
+	list   []yamlValue
+}
+
+func (v yamlValue) String() string {
+	if v.isList {
+		return strings.Join(v.strings(), ", ")
+	}
+	return v.scalar
+}
+
+// items returns the elements of a sequence, or the scalar v itself.
+func (v yamlValue) items() []yamlValue {
+	if v.isList {
+		return v.list
+	}
+	if v.scalar == "" {
+		return nil
+	}
+	return []yamlValue{v}
+}
+
+// strings returns the non-empty strings of the items of v.
+func (v yamlValue) strings() []string {
+	var list []string
+	for _, item := range v.items() {
+		if s := item.String(); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// parseYAMLValue parses a scalar or a flow sequence.
+func parseYAMLValue(s string) (yamlValue, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '[' && s[0] != '"' && s[0] != '\'') {
+		// A plain scalar, possibly followed by a comment.
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = strings.TrimSpace(s[:i])
+		}
+		return yamlValue{scalar: s}, nil
+	}
+	p := &yamlParser{s: s}
+	v, err := p.value(false)
+	if err != nil {
+		return yamlValue{}, err
+	}
+	if rest := strings.TrimSpace(p.s[p.i:]); rest != "" && rest[0] != '#' {
+		return yamlValue{}, fmt.Errorf("unexpected %q", rest)
+	}
+	return v, nil
+}
+
+type yamlParser struct {
+	s string
+	i int
+}
+
+func (p *yamlParser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+// value parses a value, which is within a flow sequence if inFlow.
+func (p *yamlParser) value(inFlow bool) (yamlValue, error) {
+	p.skipSpace()
+	if p.i == len(p.s) {
+		return yamlValue{}, nil
+	}
+	switch p.s[p.i] {
+	case '[':
+		p.i++
+		v := yamlValue{isList: true}
+		for {
+			p.skipSpace()
+			if p.i < len(p.s) && p.s[p.i] == ']' {
+				p.i++
+				return v, nil
+			}
+			item, err := p.value(true)
+			if err != nil {
+				return yamlValue{}, err
+			}
+			v.list = append(v.list, item)
+			p.skipSpace()
+			if p.i == len(p.s) {
+				return yamlValue{}, fmt.Errorf("unterminated sequence")
+			}
+			switch p.s[p.i] {
+			case ',':
+				p.i++
+			case ']':
+			default:
+				return yamlValue{}, fmt.Errorf("unexpected %q in sequence", p.s[p.i])
+			}
+		}
+	case '"':
+		for j := p.i + 1; j < len(p.s); j++ {
+			switch p.s[j] {
+			case '\\':
+				j++
+			case '"':
+				s, err := strconv.Unquote(p.s[p.i : j+1])
+				if err != nil {
+					return yamlValue{}, fmt.Errorf("invalid string %s", p.s[p.i:j+1])
+				}
+				p.i = j + 1
+				return yamlValue{scalar: s}, nil
+			}
+		}
+		return yamlValue{}, fmt.Errorf("unterminated string")
+	case '\'':
+		for j := p.i + 1; j < len(p.s); j++ {
+			if p.s[j] != '\'' {
+				continue
+			}
+			if j+1 < len(p.s) && p.s[j+1] == '\'' {
+				j++ // a quoted quote
+				continue
+			}
+			s := strings.ReplaceAll(p.s[p.i+1:j], "''", "'")
+			p.i = j + 1
+			return yamlValue{scalar: s}, nil
+		}
+		return yamlValue{}, fmt.Errorf("unterminated string")
+	}
+	j := p.i
+	for j < len(p.s) && !(inFlow && (p.s[j] == ',' || p.s[j] == ']')) {
+		j++
+	}
+	s := strings.TrimSpace(p.s[p.i:j])
+	p.i = j
+	return yamlValue{scalar: s}, nil
+}
+
+// parseYAMLTime parses a YAML timestamp. As in present files, a date
+// without a time of day means 11am UTC on that date.
+func parseYAMLTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Add(11 * time.Hour), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package present
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+)
+
+// TestMarkdown tests the conversions of the "input" files of the archives
+// in testdata/markdown to the "output" files: to Markdown for the
+// archives named tomd_*, and from Markdown for those named frommd_*.
+// The other files of an archive are those read by commands.
+func TestMarkdown(t *testing.T) {
+	files, err := filepath.Glob("testdata/markdown/*.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			ar, err := txtar.ParseFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := make(map[string][]byte)
+			for _, f := range ar.Files {
+				data[f.Name] = f.Data
+			}
+			ctx := &Context{
+				ReadFile: func(filename string) ([]byte, error) {
+					if b, ok := data[filename]; ok {
+						return b, nil
+					}
+					return nil, fmt.Errorf("no file %s", filename)
+				},
+			}
+
+			var got, present []byte
+			if strings.HasPrefix(name, "tomd_") {
+				got, err = ctx.ToMarkdown(bytes.NewReader(data["input"]), "input")
+				if err != nil {
+					t.Fatal(err)
+				}
+				present, err = FromMarkdown(bytes.NewReader(got))
+				if err != nil {
+					t.Fatalf("converting output back from Markdown: %v", err)
+				}
+			} else {
+				got, err = FromMarkdown(bytes.NewReader(data["input"]))
+				if err != nil {
+					t.Fatal(err)
+				}
+				present = got
+			}
+			if want := data["output"]; !bytes.Equal(got, want) {
+				diffText, err := diff("present-test-", "want", want, "have", got)
+				if err != nil {
+					t.Fatalf("diff: %v", err)
+				}
+				t.Errorf("incorrect output:\n%s", diffText)
+			}
+			if _, err := ctx.Parse(bytes.NewReader(present), "output", 0); err != nil {
+				t.Errorf("parsing present file converted from Markdown: %v\n%s", err, present)
+			}
+		})
+	}
+}
+
+func TestFromMarkdownErrors(t *testing.T) {
+	for _, test := range []struct {
+		input, want string
+	}{
+		{"Text.\n", "no title"},
+		{"---\ntitle: x\n", "front matter is not terminated"},
+		{"---\ntitle: \"x\n---\n", "unterminated string"},
+		{"---\ntags: [a, b\n---\n", "unterminated sequence"},
+		{"---\ndate: yesterday\n---\n", "invalid date"},
+	} {
+		if _, err := FromMarkdown(strings.NewReader(test.input)); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("FromMarkdown(%q): got error %v, want error containing %q", test.input, err, test.want)
+		}
+	}
+}
@@ -273,6 +273,12 @@ func (l *Lines) nextNonEmpty() (text string, ok bool) {
 type Context struct {
 	// ReadFile reads the file named by filename and returns the contents.
 	ReadFile func(filename string) ([]byte, error)
+
+	// RenderDiagram, if not nil, renders the source of a diagram of
+	// the specified kind, such as "mermaid" or "graphviz", as SVG.
+	// If it returns no SVG and no error, the diagram is rendered in
+	// the browser instead.
+	RenderDiagram func(kind string, src []byte) ([]byte, error)
 }
 
 // ParseMode represents flags for the Parse function.
@@ -291,34 +297,13 @@ func (ctx *Context) Parse(r io.Reader, name string, mode ParseMode) (*Doc, error
 		return nil, err
 	}
 
-	// Detect Markdown-enabled vs legacy present file.
-	// Markdown-enabled files have a title line beginning with "# "
-	// (like preprocessed C files of yore).
-	isMarkdown := false
-	for i := lines.line; i < len(lines.text); i++ {
-		line := lines.text[i]
-		if line == "" {
-			continue
-		}
-		isMarkdown = strings.HasPrefix(line, "# ")
-		break
-	}
-
+	isMarkdown := isMarkdownFile(lines)
 	sectionPrefix := "*"
 	if isMarkdown {
 		sectionPrefix = "##"
 		lines.comment = "//"
 	}
-
-	for i := lines.line; i < len(lines.text); i++ {
-		if strings.HasPrefix(lines.text[i], sectionPrefix) {
-			break
-		}
-
-		if isSpeakerNote(lines.text[i]) {
-			doc.TitleNotes = append(doc.TitleNotes, trimSpeakerNote(lines.text[i]))
-		}
-	}
+	doc.TitleNotes = titleNotes(lines, sectionPrefix)
 
 	err = parseHeader(doc, isMarkdown, lines)
 	if err != nil {
@@ -341,6 +326,36 @@ func (ctx *Context) Parse(r io.Reader, name string, mode ParseMode) (*Doc, error
 	return doc, nil
 }
 
+// isMarkdownFile reports whether lines are those of a Markdown-enabled
+// present file rather than a legacy one.
+func isMarkdownFile(lines *Lines) bool {
+	// Markdown-enabled files have a title line beginning with "# "
+	// (like preprocessed C files of yore).
+	for i := lines.line; i < len(lines.text); i++ {
+		line := lines.text[i]
+		if line == "" {
+			continue
+		}
+		return strings.HasPrefix(line, "# ")
+	}
+	return false
+}
+
+// titleNotes returns the presenter notes before the first section.
+func titleNotes(lines *Lines, sectionPrefix string) []string {
+	var notes []string
+	for i := lines.line; i < len(lines.text); i++ {
+		if strings.HasPrefix(lines.text[i], sectionPrefix) {
+			break
+		}
+
+		if isSpeakerNote(lines.text[i]) {
+			notes = append(notes, trimSpeakerNote(lines.text[i]))
+		}
+	}
+	return notes
+}
+
 // Parse parses a document from r. Parse reads assets used by the presentation
 // from the file system using os.ReadFile.
 func Parse(r io.Reader, name string, mode ParseMode) (*Doc, error) {
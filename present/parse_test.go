@@ -173,4 +173,8 @@ var testTmpl = `
 {{end}}
 
 {{define "html" -}}{{.HTML}}{{end}}
+
+{{define "diagram" -}}
+<pre class="diagram-{{.Kind}}"{{with .Height}} height="{{.}}"{{end}}{{with .Width}} width="{{.}}"{{end}}>{{.Source}}</pre>
+{{end}}
 `
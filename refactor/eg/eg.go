@@ -15,10 +15,14 @@ import (
 	"go/token"
 	"go/types"
 	"os"
+	"strings"
+
+	"golang.org/x/tools/internal/typeparams"
 )
 
 const Help = `
-This tool implements example-based refactoring of expressions.
+This tool implements example-based refactoring of expressions
+and statements.
 
 The transformation is specified as a Go file defining two functions,
 'before' and 'after', of identical types.  Each function body consists
@@ -45,6 +49,18 @@ in the input for the pattern to match.  If the replacement contains
 multiple occurrences of the same parameter, the expression will be
 duplicated, possibly changing the side-effects.
 
+A template may define several rules, each a pair of functions whose
+names are 'before' and 'after' followed by the same suffix, such as
+beforeRename and afterRename.  The rules are tried in the order of
+their 'before' functions, and the first that matches is applied, so
+that a migration involving several changes needs only a single run:
+
+ 	func beforeOld(s string, n int) string { return lib.Old(s, n) }
+ 	func afterOld(s string, n int) string  { return lib.New(n, s) }
+
+ 	func beforeMust(s string) int { return lib.MustParse(s) }
+ 	func afterMust(s string) int  { return lib.Parse(s, 0) }
+
 The tool analyses all Go code in the packages specified by the
 arguments, replacing all occurrences of the pattern with the
 substitution.
@@ -54,6 +70,72 @@ So, the transform above would change this input:
 to this output:
 	err := errors.New("error: " + msg)
 
+STATEMENT PATTERNS
+
+If the body of a 'before' function consists of anything other than a
+single return or expression statement, it is a statement pattern,
+which matches any sequence of consecutive statements in a block that
+match its statements, and the body of the 'after' function is their
+replacement.  The variables declared by a statement pattern are
+wildcards that match any variable, and the replacement refers to
+the matched variable by using the same name.  A parameter of type
+func() that is called as a statement, such as handle() below, is a
+statement-list wildcard, matching any sequence of statements; at the
+end of a pattern, it matches the remainder of the block.  So this
+rule changes the error handling of a lookup:
+
+ 	func before(key string, handle func()) {
+ 		v, ok := lib.Lookup(key)
+ 		if !ok {
+ 			handle()
+ 		}
+ 		fmt.Println(v)
+ 	}
+ 	func after(key string, handle func()) {
+ 		v, err := lib.Find(key)
+ 		if err != nil {
+ 			log.Print(err)
+ 			handle()
+ 		}
+ 		fmt.Println(v)
+ 	}
+
+Variables declared by a statement pattern need not be used.
+
+TYPED HOLES
+
+The 'before' and 'after' functions may have type parameters, which
+generalize a pattern over a family of types.  A type parameter matches
+any type that satisfies its constraint, and must match the same type
+at each occurrence in the pattern; a wildcard whose type mentions it
+matches any expression whose type matches.  So this rule matches a
+conversion to []byte of any string type, and a wildcard of type
+io.Reader, being a parameter of interface type, matches any expression
+implementing io.Reader:
+
+ 	func before[S ~string](s S) int { return len([]byte(s)) }
+ 	func after[S ~string](s S) int  { return len(s) }
+
+A type parameter matches within pointer, slice, array, map and channel
+types, but the replacement must not refer to a type parameter, as
+there is no syntax for the matched type.
+
+METHOD PATTERNS
+
+A wildcard that is the operand of a method call or field selection
+matches any receiver for which the selection denotes the same method
+or field, whether the receiver is a pointer or a value and whether the
+method or field is promoted from an embedded field; and if the method
+is that of an interface or constraint, it matches any method that
+implements it.  The wildcard stands for the receiver made explicit,
+for example &v, *p, or x.Embedded, but the & or * is omitted where
+the wildcard is itself the operand of a selection in the replacement.
+So this rule applies to r.Read(b) for any io.Reader r, including
+values of concrete types such as *os.File and types that embed them:
+
+ 	func before(r io.Reader, b []byte) (int, error) { return r.Read(b) }
+ 	func after(r io.Reader, b []byte) (int, error)  { return io.ReadFull(r, b) }
+
 Identifiers, including qualified identifiers (p.X) are considered to
 match only if they denote the same object.  This allows correct
 matching even in the presence of dot imports, named imports and
@@ -73,15 +155,19 @@ LIMITATIONS
 EXPRESSIVENESS
 
 Only refactorings that replace one expression with another, regardless
-of the expression's context, may be expressed.  Refactoring arbitrary
-statements (or sequences of statements) is a less well-defined problem
-and is less amenable to this approach.
+of the expression's context, or one sequence of statements within a
+block with another, may be expressed.
+
+A pattern that contains a function literal never matches, and a
+statement pattern must not contain one.
 
-A pattern that contains a function literal (and hence statements)
-never matches.
+A replacement that declares a variable not declared by the pattern
+may conflict with a variable of the same name in the input program.
 
-There is no way to generalize over related types, e.g. to express that
-a wildcard may have any integer type, for example.
+Type parameters of the template generalize only over the types that
+satisfy a constraint; a constraint may not refer to another type
+parameter, and type arguments are not inferred within named, struct,
+function or interface types.
 
 It is not possible to replace an expression by one of a different
 type, even in contexts where this is legal, such as x in fmt.Print(x).
@@ -135,43 +221,61 @@ match f'; (4) use eg to rename f' to f in all calls; (5) delete f'.
 
 // TODO(adonovan): expand upon the above documentation as an HTML page.
 
-// A Transformer represents a single example-based transformation.
+// A Transformer represents a set of example-based transformations,
+// the rules of a single template.
 type Transformer struct {
 	fset           *token.FileSet
 	verbose        bool
 	info           *types.Info // combined type info for template/input/output ASTs
 	seenInfos      map[*types.Info]bool
-	wildcards      map[*types.Var]bool                // set of parameters in func before()
-	env            map[string]ast.Expr                // maps parameter name to wildcard binding
-	importedObjs   map[types.Object]*ast.SelectorExpr // objects imported by after().
-	before, after  ast.Expr
-	afterStmts     []ast.Stmt
+	rules          []*rule
+	env            map[string]ast.Expr             // maps parameter name to wildcard binding
+	stmtEnv        map[string][]ast.Stmt           // maps parameter name to statement-list wildcard binding
+	targs          map[*types.TypeParam]types.Type // maps type parameter to inferred type argument
+	implicit       map[ast.Expr]ast.Expr           // maps receiver synthesized by matchReceiver to its operand
 	allowWildcards bool
 
 	// Working state of Transform():
+	*rule                     // rule being matched or substituted
 	nsubsts    int            // number of substitutions made
 	currentPkg *types.Package // package of current call
 }
 
+// A rule is the transformation specified by a pair of before/after
+// functions. An expression rule replaces the expression before by
+// after, preceded by afterStmts; a statement rule replaces a sequence
+// of statements matching beforeStmts by afterStmts.
+type rule struct {
+	name         string                             // name of the before function
+	wildcards    map[*types.Var]bool                // set of parameters and locals in func before()
+	locals       map[*types.Var]bool                // subset of wildcards that are locals, which match only identifiers
+	typeParams   map[*types.TypeParam]bool          // set of type parameters of func before()
+	importedObjs map[types.Object]*ast.SelectorExpr // objects imported by after().
+	before       ast.Expr                           // nil for a statement rule
+	after        ast.Expr
+	beforeStmts  []ast.Stmt
+	afterStmts   []ast.Stmt
+	nsubsts      int // number of substitutions made by the current call to Transform
+}
+
 // NewTransformer returns a transformer based on the specified template,
 // a single-file package containing "before" and "after" functions as
 // described in the package documentation.
 // tmplInfo is the type information for tmplFile.
 func NewTransformer(fset *token.FileSet, tmplPkg *types.Package, tmplFile *ast.File, tmplInfo *types.Info, verbose bool) (*Transformer, error) {
-	// Check the template.
-	beforeSig := funcSig(tmplPkg, "before")
-	if beforeSig == nil {
-		return nil, fmt.Errorf("no 'before' func found in template")
-	}
-	afterSig := funcSig(tmplPkg, "after")
-	if afterSig == nil {
-		return nil, fmt.Errorf("no 'after' func found in template")
+	// Find the rules, in order of their before functions.
+	funcs := make(map[string]*ast.FuncDecl)
+	var suffixes []string
+	for _, decl := range tmplFile.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil {
+			funcs[decl.Name.Name] = decl
+			if suffix, ok := strings.CutPrefix(decl.Name.Name, "before"); ok {
+				suffixes = append(suffixes, suffix)
+			}
+		}
 	}
-
-	// TODO(adonovan): should we also check the names of the params match?
-	if !types.Identical(afterSig, beforeSig) {
-		return nil, fmt.Errorf("before %s and after %s functions have different signatures",
-			beforeSig, afterSig)
+	if len(suffixes) == 0 {
+		return nil, fmt.Errorf("no 'before' func found in template")
 	}
 
 	for _, imp := range tmplFile.Imports {
@@ -182,67 +286,13 @@ func NewTransformer(fset *token.FileSet, tmplPkg *types.Package, tmplFile *ast.F
 			return nil, fmt.Errorf("dot-import (of %s) in template", imp.Path.Value)
 		}
 	}
-	var beforeDecl, afterDecl *ast.FuncDecl
-	for _, decl := range tmplFile.Decls {
-		if decl, ok := decl.(*ast.FuncDecl); ok {
-			switch decl.Name.Name {
-			case "before":
-				beforeDecl = decl
-			case "after":
-				afterDecl = decl
-			}
-		}
-	}
-
-	before, err := soleExpr(beforeDecl)
-	if err != nil {
-		return nil, fmt.Errorf("before: %s", err)
-	}
-	afterStmts, after, err := stmtAndExpr(afterDecl)
-	if err != nil {
-		return nil, fmt.Errorf("after: %s", err)
-	}
-
-	wildcards := make(map[*types.Var]bool)
-	for i := 0; i < beforeSig.Params().Len(); i++ {
-		wildcards[beforeSig.Params().At(i)] = true
-	}
-
-	// checkExprTypes returns an error if Tb (type of before()) is not
-	// safe to replace with Ta (type of after()).
-	//
-	// Only superficial checks are performed, and they may result in both
-	// false positives and negatives.
-	//
-	// Ideally, we would only require that the replacement be assignable
-	// to the context of a specific pattern occurrence, but the type
-	// checker doesn't record that information and it's complex to deduce.
-	// A Go type cannot capture all the constraints of a given expression
-	// context, which may include the size, constness, signedness,
-	// namedness or constructor of its type, and even the specific value
-	// of the replacement.  (Consider the rule that array literal keys
-	// must be unique.)  So we cannot hope to prove the safety of a
-	// transformation in general.
-	Tb := tmplInfo.TypeOf(before)
-	Ta := tmplInfo.TypeOf(after)
-	if types.AssignableTo(Tb, Ta) {
-		// safe: replacement is assignable to pattern.
-	} else if tuple, ok := Tb.(*types.Tuple); ok && tuple.Len() == 0 {
-		// safe: pattern has void type (must appear in an ExprStmt).
-	} else {
-		return nil, fmt.Errorf("%s is not a safe replacement for %s", Ta, Tb)
-	}
 
 	tr := &Transformer{
 		fset:           fset,
 		verbose:        verbose,
-		wildcards:      wildcards,
 		allowWildcards: true,
 		seenInfos:      make(map[*types.Info]bool),
-		importedObjs:   make(map[types.Object]*ast.SelectorExpr),
-		before:         before,
-		after:          after,
-		afterStmts:     afterStmts,
+		implicit:       make(map[ast.Expr]ast.Expr),
 	}
 
 	// Combine type info from the template and input packages, and
@@ -257,21 +307,184 @@ func NewTransformer(fset *token.FileSet, tmplPkg *types.Package, tmplFile *ast.F
 	}
 	mergeTypeInfo(tr.info, tmplInfo)
 
+	for _, suffix := range suffixes {
+		r, err := tr.newRule(tmplPkg, tmplInfo, funcs["before"+suffix], funcs["after"+suffix])
+		if err != nil {
+			return nil, err
+		}
+		tr.rules = append(tr.rules, r)
+	}
+	return tr, nil
+}
+
+// newRule returns the rule specified by the before and after functions.
+func (tr *Transformer) newRule(tmplPkg *types.Package, tmplInfo *types.Info, beforeDecl, afterDecl *ast.FuncDecl) (*rule, error) {
+	beforeName := beforeDecl.Name.Name
+	afterName := "after" + strings.TrimPrefix(beforeName, "before")
+
+	// Check the template.
+	beforeSig := funcSig(tmplPkg, beforeName)
+	if beforeSig == nil {
+		return nil, fmt.Errorf("no '%s' func found in template", beforeName)
+	}
+	afterSig := funcSig(tmplPkg, afterName)
+	if afterSig == nil || afterDecl == nil {
+		return nil, fmt.Errorf("no '%s' func found in template", afterName)
+	}
+
+	// TODO(adonovan): should we also check the names of the params match?
+	if !types.Identical(afterSig, beforeSig) {
+		return nil, fmt.Errorf("%s %s and %s %s functions have different signatures",
+			beforeName, beforeSig, afterName, afterSig)
+	}
+
+	r := &rule{
+		name:         beforeName,
+		wildcards:    make(map[*types.Var]bool),
+		importedObjs: make(map[types.Object]*ast.SelectorExpr),
+	}
+	for i := 0; i < beforeSig.Params().Len(); i++ {
+		r.wildcards[beforeSig.Params().At(i)] = true
+	}
+	if tparams := beforeSig.TypeParams(); tparams.Len() > 0 {
+		r.typeParams = make(map[*types.TypeParam]bool)
+		for i := 0; i < tparams.Len(); i++ {
+			tparam := tparams.At(i)
+			if new(typeparams.Free).Has(tparam.Constraint()) {
+				return nil, fmt.Errorf("%s: constraint of type parameter %s refers to a type parameter", beforeName, tparam)
+			}
+			r.typeParams[tparam] = true
+		}
+	}
+
+	if isStmtPattern(beforeDecl) {
+		// A statement rule.
+		r.beforeStmts = beforeDecl.Body.List
+		r.afterStmts = afterDecl.Body.List
+		if err := r.checkStmtPattern(tmplInfo); err != nil {
+			return nil, fmt.Errorf("%s: %s", beforeName, err)
+		}
+	} else {
+		before, err := soleExpr(beforeDecl)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", beforeName, err)
+		}
+		afterStmts, after, err := stmtAndExpr(afterDecl)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", afterName, err)
+		}
+
+		// checkExprTypes returns an error if Tb (type of before()) is not
+		// safe to replace with Ta (type of after()).
+		//
+		// Only superficial checks are performed, and they may result in both
+		// false positives and negatives.
+		//
+		// Ideally, we would only require that the replacement be assignable
+		// to the context of a specific pattern occurrence, but the type
+		// checker doesn't record that information and it's complex to deduce.
+		// A Go type cannot capture all the constraints of a given expression
+		// context, which may include the size, constness, signedness,
+		// namedness or constructor of its type, and even the specific value
+		// of the replacement.  (Consider the rule that array literal keys
+		// must be unique.)  So we cannot hope to prove the safety of a
+		// transformation in general.
+		Tb := tmplInfo.TypeOf(before)
+		Ta := tmplInfo.TypeOf(after)
+		if types.AssignableTo(Tb, Ta) {
+			// safe: replacement is assignable to pattern.
+		} else if tuple, ok := Tb.(*types.Tuple); ok && tuple.Len() == 0 {
+			// safe: pattern has void type (must appear in an ExprStmt).
+		} else {
+			return nil, fmt.Errorf("%s is not a safe replacement for %s", Ta, Tb)
+		}
+
+		r.before = before
+		r.after = after
+		r.afterStmts = afterStmts
+	}
+
 	// Compute set of imported objects required by after().
 	// TODO(adonovan): reject dot-imports in pattern
-	ast.Inspect(after, func(n ast.Node) bool {
+	var err error
+	ast.Inspect(afterDecl.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			// The replacement cannot mention a type parameter,
+			// as there is no syntax for its type argument.
+			if tname, ok := tr.info.Uses[id].(*types.TypeName); ok && err == nil {
+				if _, ok := tname.Type().(*types.TypeParam); ok {
+					err = fmt.Errorf("%s: replacement refers to type parameter %s", afterName, id.Name)
+				}
+			}
+		}
 		if n, ok := n.(*ast.SelectorExpr); ok {
 			if _, ok := tr.info.Selections[n]; !ok {
 				// qualified ident
-				obj := tr.info.Uses[n.Sel]
-				tr.importedObjs[obj] = n
+				if obj := tr.info.Uses[n.Sel]; obj != nil {
+					r.importedObjs[obj] = n
+				}
 				return false // prune
 			}
 		}
 		return true // recur
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return tr, nil
+	return r, nil
+}
+
+// isStmtPattern reports whether the before function specifies a
+// statement pattern, that is, whether its body is anything other than
+// a single return or expression statement.
+func isStmtPattern(fn *ast.FuncDecl) bool {
+	if fn.Body == nil || len(fn.Body.List) == 0 {
+		return false // soleExpr reports the error
+	}
+	if len(fn.Body.List) > 1 {
+		return true
+	}
+	switch fn.Body.List[0].(type) {
+	case *ast.ReturnStmt, *ast.ExprStmt:
+		return false
+	}
+	return true
+}
+
+// checkStmtPattern checks the statement pattern of r, and adds the
+// variables it declares to its wildcards.
+func (r *rule) checkStmtPattern(info *types.Info) error {
+	if len(r.afterStmts) == 0 {
+		return fmt.Errorf("replacement must contain at least one statement")
+	}
+	var err error
+	for _, stmt := range r.beforeStmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				err = fmt.Errorf("pattern must not contain a function literal")
+			case *ast.Ident:
+				if v, ok := info.Defs[n].(*types.Var); ok {
+					if r.locals == nil {
+						r.locals = make(map[*types.Var]bool)
+					}
+					r.locals[v] = true
+					r.wildcards[v] = true
+				}
+			}
+			return err == nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	for _, stmt := range r.beforeStmts {
+		if _, ok := stmtsWildcard(info, r.wildcards, stmt); !ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("pattern must contain a statement other than a statement-list wildcard")
 }
 
 // WriteAST is a convenience function that writes AST f to the specified file.
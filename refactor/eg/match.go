@@ -11,6 +11,7 @@ import (
 	"go/token"
 	"go/types"
 	"log"
+	"maps"
 	"os"
 	"reflect"
 
@@ -22,6 +23,8 @@ import (
 // If tr.allowWildcards, Idents in x that refer to parameters are
 // treated as wildcards, and match any y that is assignable to the
 // parameter type; matchExpr records this correspondence in tr.env.
+// If the parameter type mentions type parameters of the rule, y must
+// instead match it by unification (see unify).
 // Otherwise, matchExpr simply reports whether the two trees are
 // equivalent.
 //
@@ -82,8 +85,7 @@ func (tr *Transformer) matchExpr(x, y ast.Expr) bool {
 
 	case *ast.SelectorExpr:
 		y := y.(*ast.SelectorExpr)
-		return tr.matchSelectorExpr(x, y) &&
-			tr.info.Selections[x].Obj() == tr.info.Selections[y].Obj()
+		return tr.matchSelectorExpr(x, y)
 
 	case *ast.IndexExpr:
 		y := y.(*ast.IndexExpr)
@@ -149,33 +151,99 @@ func (tr *Transformer) matchExprs(xx, yy []ast.Expr) bool {
 	return true
 }
 
-// matchType reports whether the two type ASTs denote identical types.
+// matchType reports whether the two type ASTs denote identical types,
+// or, if x mentions type parameters of the rule, types that unify.
 func (tr *Transformer) matchType(x, y ast.Expr) bool {
 	tx := tr.info.Types[x].Type
 	ty := tr.info.Types[y].Type
-	return types.Identical(tx, ty)
+	return tr.unify(tx, ty)
 }
 
 func (tr *Transformer) wildcardObj(x ast.Expr) (*types.Var, bool) {
 	if x, ok := x.(*ast.Ident); ok && x != nil && tr.allowWildcards {
-		if xobj, ok := tr.info.Uses[x].(*types.Var); ok && tr.wildcards[xobj] {
+		if xobj, ok := isRef(x, tr.info).(*types.Var); ok && tr.wildcards[xobj] {
 			return xobj, true
 		}
 	}
 	return nil, false
 }
 
+// matchSelectorExpr reports whether the selection x.f of the pattern
+// matches the selection y.f.
+//
+// If x is a parameter wildcard, it matches any y for which y.f selects
+// the same field or method, whatever the form of the receiver, or,
+// if x.f is an interface method, a method of y that implements it.
 func (tr *Transformer) matchSelectorExpr(x, y *ast.SelectorExpr) bool {
-	if xobj, ok := tr.wildcardObj(x.X); ok {
-		field := x.Sel.Name
-		yt := tr.info.TypeOf(y.X)
-		o, _, _ := types.LookupFieldOrMethod(yt, true, tr.currentPkg, field)
-		if o != nil {
-			tr.env[xobj.Name()] = y.X // record binding
+	xsel, ysel := tr.info.Selections[x], tr.info.Selections[y]
+	if xsel == nil || ysel == nil {
+		// A field selection synthesized by matchReceiver
+		// is equivalent to the selection of the same field
+		// from an equivalent operand.
+		return x.Sel.Name == y.Sel.Name && tr.matchExpr(x.X, y.X)
+	}
+	if xobj, ok := tr.wildcardObj(x.X); ok && !tr.locals[xobj] {
+		if xsel.Obj() == ysel.Obj() ||
+			ysel.Kind() == types.MethodVal && x.Sel.Name == y.Sel.Name && isInterfaceMethod(xsel.Obj()) {
+			return tr.matchReceiver(xobj, y, ysel)
+		}
+		return false
+	}
+	return xsel.Obj() == ysel.Obj() && tr.matchExpr(x.X, y.X)
+}
+
+// matchReceiver binds the wildcard xobj to the receiver of the
+// selection y, made explicit in whichever form is assignable to the
+// type of xobj: the operand of y, or the embedded field through
+// which y selects a promoted field or method, or the address of
+// either, or the variable to which either points.
+//
+// Each receiver so synthesized is recorded in tr.implicit, mapped to
+// the operand of y, which subst may use in its place when the wildcard
+// is the operand of a selector.
+func (tr *Transformer) matchReceiver(xobj *types.Var, y *ast.SelectorExpr, ysel *types.Selection) bool {
+	type operand struct {
+		e           ast.Expr
+		t           types.Type
+		addressable bool
+	}
+	operands := []operand{{y.X, tr.info.TypeOf(y.X), tr.info.Types[y.X].Addressable()}}
+	index := ysel.Index()
+	for _, i := range index[:len(index)-1] {
+		op := operands[len(operands)-1]
+		t, addressable := op.t, op.addressable
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t, addressable = ptr.Elem(), true
+		}
+		field := t.Underlying().(*types.Struct).Field(i)
+		sel := &ast.SelectorExpr{X: op.e, Sel: &ast.Ident{NamePos: y.Sel.Pos(), Name: field.Name()}}
+		tr.info.Types[sel] = types.TypeAndValue{Type: field.Type()}
+		tr.implicit[sel] = y.X
+		operands = append(operands, operand{sel, field.Type(), addressable})
+	}
+
+	for _, op := range operands {
+		if tr.matchWildcard(xobj, op.e) {
 			return true
 		}
+		if op.addressable {
+			addr := &ast.UnaryExpr{OpPos: op.e.Pos(), Op: token.AND, X: op.e}
+			tr.info.Types[addr] = types.TypeAndValue{Type: types.NewPointer(op.t)}
+			tr.implicit[addr] = y.X
+			if tr.matchWildcard(xobj, addr) {
+				return true
+			}
+		}
+		if ptr, ok := types.Unalias(op.t).(*types.Pointer); ok {
+			star := &ast.StarExpr{Star: op.e.Pos(), X: op.e}
+			tr.info.Types[star] = types.TypeAndValue{Type: ptr.Elem()}
+			tr.implicit[star] = y.X
+			if tr.matchWildcard(xobj, star) {
+				return true
+			}
+		}
 	}
-	return tr.matchExpr(x.X, y.X)
+	return false
 }
 
 func (tr *Transformer) matchWildcard(xobj *types.Var, y ast.Expr) bool {
@@ -186,6 +254,14 @@ func (tr *Transformer) matchWildcard(xobj *types.Var, y ast.Expr) bool {
 			tr.fset.Position(y.Pos()), name, astString(tr.fset, y))
 	}
 
+	// A variable declared by a statement pattern matches
+	// only the identifier of a variable.
+	if tr.locals[xobj] {
+		if _, ok := y.(*ast.Ident); !ok {
+			return false
+		}
+	}
+
 	// Check that y is assignable to the declared type of the param.
 	yt := tr.info.TypeOf(y)
 	if yt == nil {
@@ -197,7 +273,7 @@ func (tr *Transformer) matchWildcard(xobj *types.Var, y ast.Expr) bool {
 		// the difference between T{v} and T{k:v} for structs.
 		return false
 	}
-	if !types.AssignableTo(yt, xobj.Type()) {
+	if !tr.assignable(yt, xobj.Type()) {
 		if tr.verbose {
 			fmt.Fprintf(os.Stderr, "%s not assignable to %s\n", yt, xobj.Type())
 		}
@@ -227,8 +303,250 @@ func (tr *Transformer) matchWildcard(xobj *types.Var, y ast.Expr) bool {
 	return true
 }
 
+// assignable reports whether a value of type y may be bound to a
+// wildcard of type x. If x mentions type parameters of the rule,
+// y matches it if the two unify, with untyped values taking their
+// default type. The type arguments are recorded in tr.targs only
+// if the match succeeds.
+func (tr *Transformer) assignable(y, x types.Type) bool {
+	if types.AssignableTo(y, x) {
+		return true
+	}
+	if len(tr.typeParams) == 0 {
+		return false
+	}
+	targs := maps.Clone(tr.targs)
+	if tr.unify(x, types.Default(y)) {
+		return true
+	}
+	tr.targs = targs
+	return false
+}
+
+// unify reports whether the type x of the pattern matches the type y.
+// A type parameter of the rule matches any type that satisfies its
+// constraint, and must match the same type at each occurrence; unify
+// records the inferred type arguments in tr.targs. Type parameters
+// match within pointer, slice, array, map, and channel types; other
+// types match only if identical.
+func (tr *Transformer) unify(x, y types.Type) bool {
+	x, y = types.Unalias(x), types.Unalias(y)
+	switch x := x.(type) {
+	case *types.TypeParam:
+		if !tr.typeParams[x] {
+			break
+		}
+		if t, ok := tr.targs[x]; ok {
+			return types.Identical(t, y)
+		}
+		iface, ok := x.Constraint().Underlying().(*types.Interface)
+		if !ok || !types.Satisfies(y, iface) {
+			return false
+		}
+		if tr.targs == nil {
+			tr.targs = make(map[*types.TypeParam]types.Type)
+		}
+		tr.targs[x] = y
+		return true
+
+	case *types.Pointer:
+		y, ok := y.(*types.Pointer)
+		return ok && tr.unify(x.Elem(), y.Elem())
+
+	case *types.Slice:
+		y, ok := y.(*types.Slice)
+		return ok && tr.unify(x.Elem(), y.Elem())
+
+	case *types.Array:
+		y, ok := y.(*types.Array)
+		return ok && x.Len() == y.Len() && tr.unify(x.Elem(), y.Elem())
+
+	case *types.Map:
+		y, ok := y.(*types.Map)
+		return ok && tr.unify(x.Key(), y.Key()) && tr.unify(x.Elem(), y.Elem())
+
+	case *types.Chan:
+		y, ok := y.(*types.Chan)
+		return ok && x.Dir() == y.Dir() && tr.unify(x.Elem(), y.Elem())
+	}
+	return types.Identical(x, y)
+}
+
+// matchStmts reports whether the statement pattern xs matches a prefix
+// of ys, or all of ys if whole is set, and if so returns the length of
+// the prefix. It records the bindings of the wildcards in tr.env and
+// tr.stmtEnv, and the inferred type arguments in tr.targs, discarding
+// those of failed attempts.
+//
+// A statement-list wildcard matches any sequence of statements,
+// trying the shortest first, except at the end of the pattern,
+// where it matches all the remaining statements.
+func (tr *Transformer) matchStmts(xs, ys []ast.Stmt, whole bool) (int, bool) {
+	if len(xs) == 0 {
+		return 0, !whole || len(ys) == 0
+	}
+
+	if name, ok := stmtsWildcard(tr.info, tr.wildcards, xs[0]); ok && tr.allowWildcards {
+		if old, ok := tr.stmtEnv[name]; ok {
+			// A wildcard appearing more than once in the pattern
+			// must match the same statements each time.
+			k := len(old)
+			if k > len(ys) {
+				return 0, false
+			}
+			tr.allowWildcards = false
+			_, same := tr.matchStmts(old, ys[:k], true)
+			tr.allowWildcards = true
+			if !same {
+				return 0, false
+			}
+			n, ok := tr.matchStmts(xs[1:], ys[k:], whole)
+			return k + n, ok
+		}
+		min := 0
+		if len(xs) == 1 {
+			min = len(ys)
+		}
+		for k := min; k <= len(ys); k++ {
+			env, stmtEnv, targs := maps.Clone(tr.env), maps.Clone(tr.stmtEnv), maps.Clone(tr.targs)
+			tr.stmtEnv[name] = ys[:k]
+			if n, ok := tr.matchStmts(xs[1:], ys[k:], whole); ok {
+				return k + n, true
+			}
+			tr.env, tr.stmtEnv, tr.targs = env, stmtEnv, targs
+		}
+		return 0, false
+	}
+
+	if len(ys) == 0 {
+		return 0, false
+	}
+	env, stmtEnv, targs := maps.Clone(tr.env), maps.Clone(tr.stmtEnv), maps.Clone(tr.targs)
+	if tr.matchStmt(xs[0], ys[0]) {
+		if n, ok := tr.matchStmts(xs[1:], ys[1:], whole); ok {
+			return 1 + n, true
+		}
+	}
+	tr.env, tr.stmtEnv, tr.targs = env, stmtEnv, targs
+	return 0, false
+}
+
+// matchStmt reports whether statement pattern x matches y.
+func (tr *Transformer) matchStmt(x, y ast.Stmt) bool {
+	if reflect.TypeOf(x) != reflect.TypeOf(y) {
+		return false
+	}
+	return tr.matchValue(reflect.ValueOf(x).Elem(), reflect.ValueOf(y).Elem())
+}
+
+// matchValue reports whether the parts x and y of statements match,
+// ignoring positions and comments. Expressions within statements are
+// matched by matchExpr, or by matchType if they denote types.
+func (tr *Transformer) matchValue(x, y reflect.Value) bool {
+	switch x.Type() {
+	case positionType, objectPtrType, scopePtrType, commentGroupPtrType:
+		return true
+	case stmtListType:
+		_, ok := tr.matchStmts(x.Interface().([]ast.Stmt), y.Interface().([]ast.Stmt), true)
+		return ok
+	}
+
+	switch x.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() && y.IsNil()
+		}
+	}
+	switch xn := x.Interface().(type) {
+	case ast.Expr:
+		yn, ok := y.Interface().(ast.Expr)
+		if !ok {
+			return false
+		}
+		if xid, ok := xn.(*ast.Ident); ok && isRef(xid, tr.info) == nil {
+			// e.g. the blank identifier
+			yid, ok := yn.(*ast.Ident)
+			return ok && isRef(yid, tr.info) == nil && xid.Name == yid.Name
+		}
+		if tr.info.Types[xn].IsType() {
+			return tr.matchType(xn, yn)
+		}
+		return tr.matchExpr(xn, yn)
+	case ast.Stmt:
+		yn, ok := y.Interface().(ast.Stmt)
+		return ok && tr.matchStmt(xn, yn)
+	}
+
+	switch x.Kind() {
+	case reflect.Interface:
+		x, y := x.Elem(), y.Elem()
+		return x.Type() == y.Type() && tr.matchValue(x, y)
+
+	case reflect.Ptr:
+		return tr.matchValue(x.Elem(), y.Elem())
+
+	case reflect.Slice:
+		if x.Len() != y.Len() {
+			return false
+		}
+		for i := 0; i < x.Len(); i++ {
+			if !tr.matchValue(x.Index(i), y.Index(i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Struct:
+		for i := 0; i < x.NumField(); i++ {
+			if !tr.matchValue(x.Field(i), y.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return x.Interface() == y.Interface()
+}
+
 // -- utilities --------------------------------------------------------
 
+// stmtsWildcard reports whether the statement s is a call of a
+// parameter of type func() among the wildcards, which makes it a
+// statement-list wildcard, and if so returns the parameter's name.
+func stmtsWildcard(info *types.Info, wildcards map[*types.Var]bool, s ast.Stmt) (string, bool) {
+	if id := callee(s); id != nil {
+		if v, ok := info.Uses[id].(*types.Var); ok && wildcards[v] {
+			if sig, ok := v.Type().(*types.Signature); ok &&
+				sig.Params().Len() == 0 && sig.Results().Len() == 0 {
+				return id.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// callee returns the identifier f if s is a statement of the form f(),
+// or nil otherwise.
+func callee(s ast.Stmt) *ast.Ident {
+	if s, ok := s.(*ast.ExprStmt); ok {
+		if call, ok := s.X.(*ast.CallExpr); ok && len(call.Args) == 0 {
+			if id, ok := call.Fun.(*ast.Ident); ok {
+				return id
+			}
+		}
+	}
+	return nil
+}
+
+// isInterfaceMethod reports whether obj is an abstract method,
+// that of an interface or of a type parameter's constraint.
+func isInterfaceMethod(obj types.Object) bool {
+	if fn, ok := obj.(*types.Func); ok {
+		recv := fn.Type().(*types.Signature).Recv()
+		return recv != nil && types.IsInterface(recv.Type())
+	}
+	return false
+}
+
 func unparen(e ast.Expr) ast.Expr { return astutil.Unparen(e) }
 
 // isRef returns the object referred to by this (possibly qualified)
@@ -236,7 +554,10 @@ func unparen(e ast.Expr) ast.Expr { return astutil.Unparen(e) }
 func isRef(n ast.Node, info *types.Info) types.Object {
 	switch n := n.(type) {
 	case *ast.Ident:
-		return info.Uses[n]
+		if obj := info.Uses[n]; obj != nil {
+			return obj
+		}
+		return info.Defs[n]
 
 	case *ast.SelectorExpr:
 		if _, ok := info.Selections[n]; !ok {
@@ -13,6 +13,7 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"maps"
 	"os"
 	"reflect"
 	"sort"
@@ -22,50 +23,107 @@ import (
 	"golang.org/x/tools/go/ast/astutil"
 )
 
+// A match records the expression rule that matched an
+// expression and the bindings of its wildcards.
+type match struct {
+	rule *rule
+	env  map[string]ast.Expr
+}
+
 // transformItem takes a reflect.Value representing a variable of type ast.Node
 // transforms its child elements recursively with apply, and then transforms the
 // actual element if it contains an expression.
-func (tr *Transformer) transformItem(rv reflect.Value) (reflect.Value, bool, map[string]ast.Expr) {
+func (tr *Transformer) transformItem(rv reflect.Value) (reflect.Value, bool, *match) {
 	// don't bother if val is invalid to start with
 	if !rv.IsValid() {
 		return reflect.Value{}, false, nil
 	}
 
-	rv, changed, newEnv := tr.apply(tr.transformItem, rv)
+	rv, changed, newMatch := tr.apply(tr.transformItem, rv)
 
 	e := rvToExpr(rv)
 	if e == nil {
-		return rv, changed, newEnv
+		return rv, changed, newMatch
 	}
 
-	savedEnv := tr.env
-	tr.env = make(map[string]ast.Expr) // inefficient!  Use a slice of k/v pairs
-
-	if tr.matchExpr(tr.before, e) {
-		if tr.verbose {
-			fmt.Fprintf(os.Stderr, "%s matches %s",
-				astString(tr.fset, tr.before), astString(tr.fset, e))
-			if len(tr.env) > 0 {
-				fmt.Fprintf(os.Stderr, " with:")
-				for name, ast := range tr.env {
-					fmt.Fprintf(os.Stderr, " %s->%s",
-						name, astString(tr.fset, ast))
+	savedEnv, savedTargs := tr.env, tr.targs
+	for _, r := range tr.rules {
+		if r.before == nil {
+			continue // statement rule
+		}
+		tr.rule = r
+		tr.env = make(map[string]ast.Expr) // inefficient!  Use a slice of k/v pairs
+		tr.targs = nil
+
+		if tr.matchExpr(tr.before, e) {
+			if tr.verbose {
+				fmt.Fprintf(os.Stderr, "%s matches %s",
+					astString(tr.fset, tr.before), astString(tr.fset, e))
+				if len(tr.env) > 0 {
+					fmt.Fprintf(os.Stderr, " with:")
+					for name, ast := range tr.env {
+						fmt.Fprintf(os.Stderr, " %s->%s",
+							name, astString(tr.fset, ast))
+					}
 				}
+				fmt.Fprintf(os.Stderr, "\n")
 			}
-			fmt.Fprintf(os.Stderr, "\n")
+			tr.nsubsts++
+			r.nsubsts++
+
+			// Clone the replacement tree, performing parameter substitution.
+			// We update all positions to n.Pos() to aid comment placement.
+			rv = tr.subst(tr.env, reflect.ValueOf(tr.after),
+				reflect.ValueOf(e.Pos()))
+			changed = true
+			newMatch = &match{r, tr.env}
+			break
 		}
-		tr.nsubsts++
-
-		// Clone the replacement tree, performing parameter substitution.
-		// We update all positions to n.Pos() to aid comment placement.
-		rv = tr.subst(tr.env, reflect.ValueOf(tr.after),
-			reflect.ValueOf(e.Pos()))
-		changed = true
-		newEnv = tr.env
 	}
-	tr.env = savedEnv
+	tr.env, tr.targs = savedEnv, savedTargs
+	tr.rule = nil
 
-	return rv, changed, newEnv
+	return rv, changed, newMatch
+}
+
+// transformStmts replaces each sequence of statements in list that
+// matches the pattern of a statement rule by the rule's replacement,
+// and returns the resulting list.
+func (tr *Transformer) transformStmts(list []ast.Stmt) []ast.Stmt {
+	var out []ast.Stmt
+	for i := 0; i < len(list); {
+		n := 0
+		for _, r := range tr.rules {
+			if r.before != nil {
+				continue // expression rule
+			}
+			tr.rule = r
+			tr.env = make(map[string]ast.Expr)
+			tr.stmtEnv = make(map[string][]ast.Stmt)
+			tr.targs = nil
+			var ok bool
+			if n, ok = tr.matchStmts(r.beforeStmts, list[i:], false); ok {
+				if tr.verbose {
+					fmt.Fprintf(os.Stderr, "%s: %s matches %d statements\n",
+						tr.fset.Position(list[i].Pos()), r.name, n)
+				}
+				tr.nsubsts++
+				r.nsubsts++
+				// Clone the replacement statements, as for an expression.
+				t := tr.subst(tr.env, reflect.ValueOf(r.afterStmts),
+					reflect.ValueOf(list[i].Pos())).Interface()
+				out = append(out, t.([]ast.Stmt)...)
+				break
+			}
+		}
+		tr.env, tr.stmtEnv, tr.targs, tr.rule = nil, nil, nil, nil
+		if n == 0 {
+			out = append(out, list[i])
+			n = 1
+		}
+		i += n
+	}
+	return out
 }
 
 // Transform applies the transformation to the specified parsed file,
@@ -84,11 +142,20 @@ func (tr *Transformer) Transform(info *types.Info, pkg *types.Package, file *ast
 	}
 	tr.currentPkg = pkg
 	tr.nsubsts = 0
+	for _, r := range tr.rules {
+		r.nsubsts = 0
+	}
 
 	if tr.verbose {
-		fmt.Fprintf(os.Stderr, "before: %s\n", astString(tr.fset, tr.before))
-		fmt.Fprintf(os.Stderr, "after: %s\n", astString(tr.fset, tr.after))
-		fmt.Fprintf(os.Stderr, "afterStmts: %s\n", tr.afterStmts)
+		for _, r := range tr.rules {
+			if r.before != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", r.name, astString(tr.fset, r.before))
+				fmt.Fprintf(os.Stderr, "after: %s\n", astString(tr.fset, r.after))
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", r.name, r.beforeStmts)
+			}
+			fmt.Fprintf(os.Stderr, "afterStmts: %s\n", r.afterStmts)
+		}
 	}
 
 	o, changed, _ := tr.apply(tr.transformItem, reflect.ValueOf(file))
@@ -106,8 +173,12 @@ func (tr *Transformer) Transform(info *types.Info, pkg *types.Package, file *ast
 	// TODO(adonovan): remove no-longer needed imports too.
 	if tr.nsubsts > 0 {
 		pkgs := make(map[string]*types.Package)
-		for obj := range tr.importedObjs {
-			pkgs[obj.Pkg().Path()] = obj.Pkg()
+		for _, r := range tr.rules {
+			if r.nsubsts > 0 {
+				for obj := range r.importedObjs {
+					pkgs[obj.Pkg().Path()] = obj.Pkg()
+				}
+			}
 		}
 
 		for _, imp := range file.Imports {
@@ -158,21 +229,24 @@ var (
 	objectPtrNil = reflect.ValueOf((*ast.Object)(nil))
 	scopePtrNil  = reflect.ValueOf((*ast.Scope)(nil))
 
-	identType        = reflect.TypeOf((*ast.Ident)(nil))
-	selectorExprType = reflect.TypeOf((*ast.SelectorExpr)(nil))
-	objectPtrType    = reflect.TypeOf((*ast.Object)(nil))
-	statementType    = reflect.TypeOf((*ast.Stmt)(nil)).Elem()
-	positionType     = reflect.TypeOf(token.NoPos)
-	scopePtrType     = reflect.TypeOf((*ast.Scope)(nil))
+	identType           = reflect.TypeOf((*ast.Ident)(nil))
+	selectorExprType    = reflect.TypeOf((*ast.SelectorExpr)(nil))
+	objectPtrType       = reflect.TypeOf((*ast.Object)(nil))
+	statementType       = reflect.TypeOf((*ast.Stmt)(nil)).Elem()
+	stmtListType        = reflect.TypeOf([]ast.Stmt(nil))
+	positionType        = reflect.TypeOf(token.NoPos)
+	scopePtrType        = reflect.TypeOf((*ast.Scope)(nil))
+	commentGroupPtrType = reflect.TypeOf((*ast.CommentGroup)(nil))
 )
 
 // apply replaces each AST field x in val with f(x), returning val.
 // To avoid extra conversions, f operates on the reflect.Value form.
 // f takes a reflect.Value representing the variable to modify of type ast.Node.
 // It returns a reflect.Value containing the transformed value of type ast.Node,
-// whether any change was made, and a map of identifiers to ast.Expr (so we can
-// do contextually correct substitutions in the parent statements).
-func (tr *Transformer) apply(f func(reflect.Value) (reflect.Value, bool, map[string]ast.Expr), val reflect.Value) (reflect.Value, bool, map[string]ast.Expr) {
+// whether any change was made, and the match that made it, whose bindings
+// of identifiers to ast.Expr let us do contextually correct substitutions
+// in the parent statements.
+func (tr *Transformer) apply(f func(reflect.Value) (reflect.Value, bool, *match), val reflect.Value) (reflect.Value, bool, *match) {
 	if !val.IsValid() {
 		return reflect.Value{}, false, nil
 	}
@@ -194,56 +268,59 @@ func (tr *Transformer) apply(f func(reflect.Value) (reflect.Value, bool, map[str
 		// no possible rewriting of statements.
 		if v.Type().Elem() != statementType {
 			changed := false
-			var envp map[string]ast.Expr
+			var matchp *match
 			for i := 0; i < v.Len(); i++ {
 				e := v.Index(i)
-				o, localchanged, env := f(e)
+				o, localchanged, m := f(e)
 				if localchanged {
 					changed = true
-					// we clobber envp here,
+					// we clobber matchp here,
 					// which means if we have two successive
 					// replacements inside the same statement
 					// we will only generate the setup for one of them.
-					envp = env
+					matchp = m
 				}
 				setValue(e, o)
 			}
-			return val, changed, envp
+			return val, changed, matchp
 		}
 
 		// statements are rewritten.
 		var out []ast.Stmt
 		for i := 0; i < v.Len(); i++ {
 			e := v.Index(i)
-			o, changed, env := f(e)
+			o, changed, m := f(e)
 			if changed {
+				tr.rule = m.rule
 				for _, s := range tr.afterStmts {
-					t := tr.subst(env, reflect.ValueOf(s), reflect.Value{}).Interface()
+					t := tr.subst(m.env, reflect.ValueOf(s), reflect.Value{}).Interface()
 					out = append(out, t.(ast.Stmt))
 				}
+				tr.rule = nil
 			}
 			setValue(e, o)
 			out = append(out, e.Interface().(ast.Stmt))
 		}
+		out = tr.transformStmts(out)
 		return reflect.ValueOf(out), false, nil
 	case reflect.Struct:
 		changed := false
-		var envp map[string]ast.Expr
+		var matchp *match
 		for i := 0; i < v.NumField(); i++ {
 			e := v.Field(i)
-			o, localchanged, env := f(e)
+			o, localchanged, m := f(e)
 			if localchanged {
 				changed = true
-				envp = env
+				matchp = m
 			}
 			setValue(e, o)
 		}
-		return val, changed, envp
+		return val, changed, matchp
 	case reflect.Interface:
 		e := v.Elem()
-		o, changed, env := f(e)
+		o, changed, m := f(e)
 		setValue(v, o)
-		return val, changed, env
+		return val, changed, m
 	}
 	return val, false, nil
 }
@@ -277,6 +354,32 @@ func (tr *Transformer) subst(env map[string]ast.Expr, pattern, pos reflect.Value
 		}
 	}
 
+	// A wildcard bound by matchReceiver to a receiver made
+	// explicit, such as &x or x.Embedded, is replaced by the
+	// original operand x when it is the operand of a selector
+	// that denotes the same field or method when applied to x,
+	// or failing that, by the receiver without its &.
+	if env != nil && pattern.Type() == selectorExprType {
+		sel := pattern.Interface().(*ast.SelectorExpr)
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if recv, ok := env[id.Name]; ok {
+				if operand, ok := tr.implicit[recv]; ok {
+					var x ast.Expr
+					if tr.selects(operand, sel) {
+						x = operand
+					} else if addr, ok := recv.(*ast.UnaryExpr); ok {
+						x = addr.X
+					}
+					if x != nil {
+						env = maps.Clone(env)
+						env[id.Name] = x
+						return tr.subst(env, pattern, pos)
+					}
+				}
+			}
+		}
+	}
+
 	// Emit qualified identifiers in the pattern by appropriate
 	// (possibly qualified) identifier in the input.
 	//
@@ -322,6 +425,21 @@ func (tr *Transformer) subst(env map[string]ast.Expr, pattern, pos reflect.Value
 		return pos
 	}
 
+	// Statement-list wildcards get replaced by the statements they matched.
+	if tr.stmtEnv != nil && pattern.Type() == stmtListType {
+		var list []ast.Stmt
+		for _, s := range pattern.Interface().([]ast.Stmt) {
+			if id := callee(s); id != nil {
+				if stmts, ok := tr.stmtEnv[id.Name]; ok {
+					list = append(list, stmts...)
+					continue
+				}
+			}
+			list = append(list, tr.subst(env, reflect.ValueOf(&s).Elem(), pos).Interface().(ast.Stmt))
+		}
+		return reflect.ValueOf(list)
+	}
+
 	// Otherwise copy.
 	switch p := pattern; p.Kind() {
 	case reflect.Slice:
@@ -365,6 +483,18 @@ func (tr *Transformer) subst(env map[string]ast.Expr, pattern, pos reflect.Value
 
 // -- utilities -------------------------------------------------------
 
+// selects reports whether the selection sel of the replacement
+// denotes the same field or method when applied to the operand x.
+func (tr *Transformer) selects(x ast.Expr, sel *ast.SelectorExpr) bool {
+	s, ok := tr.info.Selections[sel]
+	if !ok {
+		return false
+	}
+	tv := tr.info.Types[x]
+	obj, _, _ := types.LookupFieldOrMethod(tv.Type, tv.Addressable(), tr.currentPkg, sel.Sel.Name)
+	return obj == s.Obj()
+}
+
 func rvToExpr(rv reflect.Value) ast.Expr {
 	if rv.CanInterface() {
 		if e, ok := rv.Interface().(ast.Expr); ok {
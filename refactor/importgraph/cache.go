@@ -0,0 +1,287 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importgraph
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadCached is like Load, but saves its result in a file of the
+// directory cacheDir, which it creates if necessary, and returns the
+// saved result instead of loading the packages again if the state of
+// the main modules has not changed since.
+//
+// The main modules are those of the go.work or go.mod file enclosing
+// the directory cfg.Dir. Their state comprises the contents of their
+// go.work, go.mod, and go.sum files, and the name, size, and
+// modification time of each of their Go files. The cache key also
+// includes the patterns, the build flags, and the variables of the
+// environment whose names begin with GO or CGO_. Other modules are
+// assumed immutable, as go.sum ensures, and so is GOROOT for a given
+// environment.
+//
+// In GOPATH mode, where there are no main modules, or if cfg has an
+// overlay, LoadCached does not use the cache.
+func LoadCached(cacheDir string, cfg *packages.Config, patterns ...string) (forward, reverse Graph, errors map[string]error, err error) {
+	if cfg == nil {
+		cfg = new(packages.Config)
+	}
+	key, err := stateKey(cfg, patterns)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if key == "" {
+		return Load(cfg, patterns...)
+	}
+
+	filename := filepath.Join(cacheDir, key)
+	if data, err := os.ReadFile(filename); err == nil {
+		var saved savedGraph
+		if err := json.Unmarshal(data, &saved); err == nil {
+			forward, errors = saved.decode()
+			return forward, forward.Reverse(), errors, nil
+		}
+		// An unreadable file is rewritten below.
+	}
+
+	forward, reverse, errors, err = Load(cfg, patterns...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := save(filename, encode(forward, errors)); err != nil {
+		return nil, nil, nil, err
+	}
+	return forward, reverse, errors, nil
+}
+
+// A savedGraph is the form of a forward graph and its errors in a
+// cache file.
+type savedGraph struct {
+	Forward map[string][]string
+	Errors  map[string]string
+}
+
+func encode(forward Graph, errors map[string]error) *savedGraph {
+	saved := &savedGraph{Forward: make(map[string][]string)}
+	for from, edges := range forward {
+		for to := range edges {
+			saved.Forward[from] = append(saved.Forward[from], to)
+		}
+		slices.Sort(saved.Forward[from])
+	}
+	for path, err := range errors {
+		if saved.Errors == nil {
+			saved.Errors = make(map[string]string)
+		}
+		saved.Errors[path] = err.Error()
+	}
+	return saved
+}
+
+func (saved *savedGraph) decode() (forward Graph, errors map[string]error) {
+	forward = make(Graph)
+	for from, edges := range saved.Forward {
+		for _, to := range edges {
+			forward.addEdge(from, to)
+		}
+	}
+	for path, msg := range saved.Errors {
+		if errors == nil {
+			errors = make(map[string]error)
+		}
+		errors[path] = fmt.Errorf("%s", msg)
+	}
+	return forward, errors
+}
+
+// save writes the saved graph to the named file atomically, so that
+// concurrent readers see either the old file or the new one.
+func save(filename string, saved *savedGraph) error {
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// stateKey returns the cache key for loading the patterns with the
+// specified configuration, or "" if the result cannot be cached.
+func stateKey(cfg *packages.Config, patterns []string) (string, error) {
+	if len(cfg.Overlay) > 0 {
+		return "", nil
+	}
+	env := cfg.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		dir = wd
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	files, moduleDirs, err := mainModules(dir, env)
+	if err != nil || moduleDirs == nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "patterns %q\nbuildflags %q\n", patterns, cfg.BuildFlags)
+	var vars []string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GO") || strings.HasPrefix(kv, "CGO_") {
+			vars = append(vars, kv)
+		}
+	}
+	slices.Sort(vars)
+	fmt.Fprintf(h, "env %q\n", vars)
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file %s %x\n", filename, sha256.Sum256(data))
+	}
+	for _, root := range moduleDirs {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path == root {
+					return nil
+				}
+				// Skip directories that the go command ignores, and
+				// nested modules.
+				name := d.Name()
+				if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".go") {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(h, "go %s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// mainModules returns the names of the files that determine the main
+// modules for the directory dir in the environment env, and the
+// directories of the main modules, which are nil in GOPATH mode.
+func mainModules(dir string, env []string) (files, moduleDirs []string, err error) {
+	if getenv(env, "GO111MODULE") == "off" {
+		return nil, nil, nil
+	}
+
+	// Find the go.work file, if any.
+	gowork := getenv(env, "GOWORK")
+	if gowork == "" {
+		gowork = findUp(dir, "go.work")
+	} else if gowork == "off" {
+		gowork = ""
+	}
+	if gowork != "" {
+		data, err := os.ReadFile(gowork)
+		if err != nil {
+			return nil, nil, err
+		}
+		work, err := modfile.ParseWork(gowork, data, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, gowork, gowork+".sum")
+		for _, use := range work.Use {
+			modDir := use.Path
+			if !filepath.IsAbs(modDir) {
+				modDir = filepath.Join(filepath.Dir(gowork), modDir)
+			}
+			files = append(files, filepath.Join(modDir, "go.mod"), filepath.Join(modDir, "go.sum"))
+			moduleDirs = append(moduleDirs, modDir)
+		}
+		return files, moduleDirs, nil
+	}
+
+	if gomod := findUp(dir, "go.mod"); gomod != "" {
+		modDir := filepath.Dir(gomod)
+		return []string{gomod, filepath.Join(modDir, "go.sum")}, []string{modDir}, nil
+	}
+	return nil, nil, nil
+}
+
+// findUp returns the name of the file called base in dir or its
+// nearest ancestor, or "" if there is none.
+func findUp(dir, base string) string {
+	for {
+		filename := filepath.Join(dir, base)
+		if _, err := os.Stat(filename); err == nil {
+			return filename
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// getenv returns the value of the variable key in env, which, like
+// the result of os.Environ, may define it more than once.
+func getenv(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if v, ok := strings.CutPrefix(env[i], key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
@@ -4,13 +4,22 @@
 
 // Package importgraph computes the forward and reverse import
 // dependency graphs for all packages in a Go workspace.
+//
+// Build constructs the graphs by scanning a GOPATH workspace described
+// by a go/build.Context; Load constructs them from the packages
+// reported by go/packages, and so works in module mode too. LoadCached
+// saves the result of Load in a cache directory, for reuse while the
+// state of the main modules remains unchanged.
 package importgraph // import "golang.org/x/tools/refactor/importgraph"
 
 import (
+	"fmt"
 	"go/build"
+	"strings"
 	"sync"
 
 	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/packages"
 )
 
 // A Graph is an import dependency graph, either forward or reverse.
@@ -53,6 +62,29 @@ func (g Graph) Search(roots ...string) map[string]bool {
 	return seen
 }
 
+// ReverseSearch returns all the nodes of the graph from which any of
+// the specified roots is reachable, by following edges backwards.
+// Relationally, this is the reflexive transitive closure of the
+// inverse relation.
+//
+// For a forward graph, this is the set of packages affected by a
+// change to any of the roots.
+func (g Graph) ReverseSearch(roots ...string) map[string]bool {
+	return g.Reverse().Search(roots...)
+}
+
+// Reverse returns the graph with every edge reversed, which is the
+// reverse graph of a forward graph, and vice versa.
+func (g Graph) Reverse() Graph {
+	reverse := make(Graph)
+	for from, edges := range g {
+		for to := range edges {
+			reverse.addEdge(to, from)
+		}
+	}
+	return reverse
+}
+
 // Build scans the specified Go workspace and builds the forward and
 // reverse import dependency graphs for all its packages.
 // It also returns a mapping from canonical import paths to errors for packages
@@ -165,3 +197,50 @@ func Build(ctxt *build.Context) (forward, reverse Graph, errors map[string]error
 
 	return forward, reverse, errors
 }
+
+// Load builds the forward and reverse import dependency graphs for
+// the packages matched by the patterns and all their dependencies,
+// using go/packages with the specified configuration, whose Mode and
+// Tests fields it ignores; cfg may be nil. As with Build, the imports
+// of a package include those of its tests.
+//
+// It also returns a mapping from import paths to errors for packages
+// whose loading was not entirely successful. The final error result
+// reports a failure to load any packages at all.
+func Load(cfg *packages.Config, patterns ...string) (forward, reverse Graph, errors map[string]error, err error) {
+	var config packages.Config
+	if cfg != nil {
+		config = *cfg
+	}
+	config.Mode = packages.NeedName | packages.NeedImports | packages.NeedDeps
+	config.Tests = true
+	pkgs, err := packages.Load(&config, patterns...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	forward = make(Graph)
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if strings.HasSuffix(p.ID, ".test") {
+			return // test main package
+		}
+		// Attribute the imports of a test variant, and of an
+		// external test package, to the package under test.
+		path := p.PkgPath
+		if i := strings.Index(p.ID, " ["); i >= 0 {
+			path = strings.TrimSuffix(p.ID[i+len(" ["):], ".test]")
+		}
+		for _, imp := range p.Imports {
+			forward.addEdge(path, imp.PkgPath)
+		}
+		for _, e := range p.Errors {
+			if errors == nil {
+				errors = make(map[string]error)
+			}
+			if _, ok := errors[path]; !ok {
+				errors[path] = fmt.Errorf("%v", e)
+			}
+		}
+	})
+	return forward, forward.Reverse(), errors, nil
+}
@@ -13,12 +13,17 @@ import (
 	"fmt"
 	"go/build"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/internal/packagestest"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/internal/testfiles"
 	"golang.org/x/tools/refactor/importgraph"
+	"golang.org/x/tools/txtar"
 
 	_ "crypto/hmac" // just for test, below
 )
@@ -186,3 +191,116 @@ func TestBuild(t *testing.T) {
 		t.Errorf("fmt and io are not mutually reachable despite being in the same SCC")
 	}
 }
+
+func TestReverseSearch(t *testing.T) {
+	forward := importgraph.Graph{
+		"a": {"b": true},
+		"b": {"c": true},
+		"d": {"c": true},
+		"e": {"a": true},
+	}
+	got := forward.ReverseSearch("b")
+	var keys []string
+	for k := range got {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if got, want := strings.Join(keys, " "), "a b e"; got != want {
+		t.Errorf("ReverseSearch(b) = %s, want %s", got, want)
+	}
+	if !forward.Reverse()["c"]["d"] || forward.Reverse()["c"]["a"] {
+		t.Errorf("Reverse()[c] = %v, want {b, d}", forward.Reverse()["c"])
+	}
+}
+
+// module is a module whose package a imports b, which imports c,
+// and whose package d is imported only by the external test of c.
+const module = `
+-- go.mod --
+module example.com
+
+go 1.22
+
+-- a/a.go --
+package a
+
+import _ "example.com/b"
+
+-- b/b.go --
+package b
+
+import _ "example.com/c"
+
+-- c/c.go --
+package c
+
+-- c/c_test.go --
+package c_test
+
+import _ "example.com/d"
+
+-- d/d.go --
+package d
+`
+
+func TestLoadCached(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	fs, err := txtar.FS(txtar.Parse([]byte(module)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := testfiles.CopyToTmp(t, fs)
+	cacheDir := t.TempDir()
+	cfg := &packages.Config{Dir: dir}
+
+	load := func() (forward, reverse importgraph.Graph) {
+		t.Helper()
+		forward, reverse, errs, err := importgraph.LoadCached(cacheDir, cfg, "./...")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path, err := range errs {
+			t.Errorf("%s: %s", path, err)
+		}
+		return forward, reverse
+	}
+	affected := func(forward importgraph.Graph, path string) string {
+		var paths []string
+		for p := range forward.ReverseSearch(path) {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		return strings.Join(paths, " ")
+	}
+
+	forward, reverse := load()
+	if !forward["example.com/c"]["example.com/d"] || !reverse["example.com/d"]["example.com/c"] {
+		t.Errorf("edge from c to d, from its external test, not found")
+	}
+	if got, want := affected(forward, "example.com/c"), "example.com/a example.com/b example.com/c"; got != want {
+		t.Errorf("packages affected by c = %s, want %s", got, want)
+	}
+
+	// A second load uses the cache.
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("cache has %d entries, want 1", len(entries))
+	}
+	forward2, _ := load()
+	if fmt.Sprint(forward2) != fmt.Sprint(forward) {
+		t.Errorf("cached graph = %v, want %v", forward2, forward)
+	}
+
+	// A change to a Go file invalidates it.
+	if err := os.WriteFile(filepath.Join(dir, "d/d.go"), []byte("package d\n\nimport _ \"example.com/a\"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	forward, _ = load()
+	if got, want := affected(forward, "example.com/a"), "example.com/a example.com/b example.com/c example.com/d"; got != want {
+		t.Errorf("packages affected by a after change = %s, want %s", got, want)
+	}
+}
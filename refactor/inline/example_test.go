@@ -0,0 +1,77 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inline_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+
+	"golang.org/x/tools/refactor/inline"
+)
+
+// This example inlines a call to a function declared in the same file.
+func Example() {
+	const src = `package p
+
+func add(x, y int) int { return x + y }
+
+func f(z int) int { return add(z, 1) * 2 }
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{file}, info)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Analyze the callee, add, and find the call to it in f.
+	decl := file.Decls[0].(*ast.FuncDecl)
+	callee, err := inline.AnalyzeCallee(nil, fset, pkg, info, decl, []byte(src))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var call *ast.CallExpr
+	ast.Inspect(file.Decls[1], func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok {
+			call = c
+		}
+		return call == nil
+	})
+
+	res, err := inline.Inline(&inline.Caller{
+		Fset:    fset,
+		Types:   pkg,
+		Info:    info,
+		File:    file,
+		Call:    call,
+		Content: []byte(src),
+	}, callee, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s", res.Content)
+
+	// Output:
+	// package p
+	//
+	// func add(x, y int) int { return x + y }
+	//
+	// func f(z int) int { return (z + 1) * 2 }
+}
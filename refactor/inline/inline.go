@@ -0,0 +1,136 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package inline implements inlining of Go function calls: the
+// replacement of a call f(args) by the body of the function f, with
+// its parameters bound to the arguments.
+//
+// It is the inliner used by gopls, and preserves the semantics of the
+// call: argument effects are neither eliminated, repeated, nor
+// reordered; implicit conversions are made explicit; and references
+// within the callee to names that are inaccessible or shadowed at the
+// call site cause the operation to fail rather than change meaning.
+// Where a call cannot be reduced to the body of its callee, it is
+// replaced by a call of a function literal ("literalization"), which
+// is always correct but rarely tidy.
+//
+// Inlining proceeds in two steps. AnalyzeCallee analyzes a function
+// declaration to produce a Callee, which is serializable, so that it
+// may be computed once and recorded, for example as a fact in an
+// analysis.Analyzer. Inline then inlines a particular call to that
+// function, described by a Caller, and returns the updated content of
+// the file containing the call.
+//
+// The client provides the syntax, type information, and source text of
+// both caller and callee, which need not belong to the same FileSet or
+// type-checker "realm"; this lets the inliner fit into existing batch
+// and interactive tools that have their own ways of loading packages.
+// A tool that inlines all calls to a set of deprecated functions
+// across a large code base might, for each package, analyze the
+// callees it declares and export them as facts, then in each importing
+// package inline every call to such a callee, one at a time, reloading
+// the caller's file after each change.
+package inline // import "golang.org/x/tools/refactor/inline"
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/internal/refactor/inline"
+)
+
+// A Caller describes a function call and its enclosing context.
+//
+// The client is responsible for populating all its fields. The type
+// information must include Types, Defs, Uses, Implicits, Selections
+// and Scopes, and Content must be the source of File, the actual
+// input to the compiler, not the apparent source according to any
+// //line directives within it.
+type Caller struct {
+	Fset    *token.FileSet
+	Types   *types.Package
+	Info    *types.Info
+	File    *ast.File
+	Call    *ast.CallExpr
+	Content []byte // source of File
+}
+
+// A Callee holds information about an inlinable function.
+// It is serializable using encoding/gob.
+type Callee struct {
+	impl inline.Callee
+}
+
+// String returns the name of the callee, for use in messages.
+func (callee *Callee) String() string { return callee.impl.String() }
+
+// GobEncode encodes the callee for encoding/gob.
+func (callee *Callee) GobEncode() ([]byte, error) { return callee.impl.GobEncode() }
+
+// GobDecode decodes a callee encoded by GobEncode.
+func (callee *Callee) GobDecode(data []byte) error { return callee.impl.GobDecode(data) }
+
+// Options specifies parameters affecting the inliner algorithm.
+// All fields are optional.
+type Options struct {
+	Logf          func(string, ...any) // log output function, records decision-making process
+	IgnoreEffects bool                 // ignore potential side effects of arguments (unsound)
+}
+
+// Result holds the result of inlining a call.
+type Result struct {
+	Content     []byte // formatted, transformed content of caller file
+	Literalized bool   // chosen strategy replaced callee() with func(){...}()
+}
+
+// AnalyzeCallee analyzes a function that is a candidate for inlining,
+// whose declaration decl belongs to the package pkg with type
+// information info, and returns a Callee that describes it, which may
+// be passed to one or more subsequent calls to Inline, each with a
+// different Caller.
+//
+// The content is that of the file containing decl, under the same
+// conditions as Caller.Content. If logf is non-nil, it records the
+// progress of the analysis.
+func AnalyzeCallee(logf func(string, ...any), fset *token.FileSet, pkg *types.Package, info *types.Info, decl *ast.FuncDecl, content []byte) (*Callee, error) {
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	impl, err := inline.AnalyzeCallee(logf, fset, pkg, info, decl, content)
+	if err != nil {
+		return nil, err
+	}
+	return &Callee{*impl}, nil
+}
+
+// Inline inlines the called function (callee) into the function call
+// (caller) and returns the updated, formatted content of the caller
+// source file. It reports an error if the call cannot be inlined
+// without changing its meaning; opts may be nil.
+//
+// Inline does not mutate caller or callee.
+func Inline(caller *Caller, callee *Callee, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	res, err := inline.Inline(&inline.Caller{
+		Fset:    caller.Fset,
+		Types:   caller.Types,
+		Info:    caller.Info,
+		File:    caller.File,
+		Call:    caller.Call,
+		Content: caller.Content,
+	}, &callee.impl, &inline.Options{
+		Logf:          opts.Logf,
+		IgnoreEffects: opts.IgnoreEffects,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Content:     res.Content,
+		Literalized: res.Literalized,
+	}, nil
+}
@@ -0,0 +1,594 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package move
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/diff"
+	refplan "golang.org/x/tools/refactor/plan"
+)
+
+// A DeclPlan describes the changes that move a package-level
+// declaration from one package to another.
+type DeclPlan struct {
+	From, To string            // import paths of the old and new packages
+	Name     string            // name of the declaration
+	NewName  string            // name in the new package, which differs from Name after a collision
+	Files    map[string][]byte // new contents of each changed or created file, by name
+}
+
+// PrepareDecl returns the plan for moving the package-level type,
+// function, variable, or constant called name from the package whose
+// import path is from to the existing package whose import path is to.
+// A type moves with its methods. It loads the packages of the main
+// modules that refer to the declaration using the specified
+// configuration, whose Mode and Tests fields it ignores; cfg may be
+// nil.
+//
+// The declaration is appended to the file of the new package with
+// the same name as the file that declared it, which is created if
+// necessary, and each reference to it is rewritten: qualified
+// references from the new package become unqualified, and other
+// references become qualified by the new package, whose import is
+// added where needed; imports that are no longer needed are removed.
+// References within the declaration to the old package are qualified.
+// If the new package already declares the name, the declaration is
+// renamed by appending a number.
+//
+// PrepareDecl reports an error if the move would create an import
+// cycle, or make an unexported name inaccessible to a reference.
+func PrepareDecl(cfg *packages.Config, from, name, to string) (*DeclPlan, error) {
+	if from == to {
+		return nil, fmt.Errorf("cannot move %s.%s to its own package", from, name)
+	}
+	var config packages.Config
+	if cfg != nil {
+		config = *cfg
+	}
+	config.Tests = true
+
+	// Find the import graph, and the packages of the main modules
+	// that import the old package and so may refer to the declaration.
+	config.Mode = packages.NeedName | packages.NeedImports | packages.NeedModule
+	all, err := packages.Load(&config, "all")
+	if err != nil {
+		return nil, err
+	}
+	graph := make(map[string][]string) // maps package path to its imports
+	patterns := []string{from, to}
+	for _, p := range all {
+		if p.ID == p.PkgPath {
+			graph[p.PkgPath] = nil
+			for _, imp := range p.Imports {
+				graph[p.PkgPath] = append(graph[p.PkgPath], imp.PkgPath)
+			}
+		}
+		if _, ok := p.Imports[from]; ok && p.Module != nil && p.Module.Main {
+			// Load a test variant by the path of the package under test.
+			path := p.PkgPath
+			if i := strings.Index(p.ID, " ["); i >= 0 {
+				path = strings.TrimSuffix(p.ID[i+len(" ["):], ".test]")
+			}
+			if !slices.Contains(patterns, path) {
+				patterns = append(patterns, path)
+			}
+		}
+	}
+
+	for _, path := range patterns {
+		if _, ok := graph[path]; !ok {
+			return nil, fmt.Errorf("package %s not found", path)
+		}
+	}
+
+	// Load them with syntax and types.
+	config.Mode = packages.LoadAllSyntax | packages.NeedModule
+	pkgs, err := packages.Load(&config, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var src, dst *packages.Package
+	for _, p := range pkgs {
+		if len(p.Errors) > 0 {
+			return nil, fmt.Errorf("package %s has errors: %v", p.ID, p.Errors[0])
+		}
+		switch p.ID {
+		case from:
+			src = p
+		case to:
+			dst = p
+		}
+	}
+	obj := src.Types.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("package %s has no declaration of %s", from, name)
+	}
+	decls, err := findDecls(src, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &DeclPlan{
+		From:    from,
+		To:      to,
+		Name:    name,
+		NewName: name,
+		Files:   make(map[string][]byte),
+	}
+	for i := 2; declared(pkgs, to, plan.NewName); i++ {
+		plan.NewName = name + strconv.Itoa(i)
+	}
+
+	// Compute the edits of each file that refers to the declaration,
+	// and of the declaration itself.
+	m := &declMover{
+		plan:  plan,
+		fset:  src.Fset,
+		obj:   obj,
+		decls: decls,
+		dst:   dst,
+	}
+	done := make(map[string]bool)
+	m.texts = make([]string, len(decls))
+	for _, p := range pkgs {
+		if p.Module == nil || !p.Module.Main || strings.HasSuffix(p.ID, ".test") {
+			continue // not in a main module, or a test main package
+		}
+		for _, file := range p.Syntax {
+			filename := p.Fset.File(file.FileStart).Name()
+			if done[filename] {
+				continue
+			}
+			done[filename] = true
+			content, err := m.rewriteFile(p, file, filename)
+			if err != nil {
+				return nil, err
+			}
+			if content != nil {
+				plan.Files[filename] = content
+			}
+		}
+	}
+
+	// Check that the move creates no import cycle and leaves no
+	// reference to an unexported name in another package.
+	if m.srcNeedsDst && m.dstNeedsSrc {
+		return nil, fmt.Errorf("cannot move %s: it refers to %s, which refers to it", name, from)
+	}
+	if m.srcNeedsDst && reaches(graph, to, from) {
+		return nil, fmt.Errorf("cannot move %s: %s would import %s, which imports it", name, from, to)
+	}
+	if m.dstNeedsSrc && reaches(graph, from, to) {
+		return nil, fmt.Errorf("cannot move %s: %s would import %s, which imports it", name, to, from)
+	}
+	if !token.IsExported(plan.NewName) && m.srcNeedsDst {
+		return nil, fmt.Errorf("cannot move unexported %s: it is referred to by %s", name, from)
+	}
+	for _, s := range m.unexported {
+		return nil, fmt.Errorf("cannot move %s: it refers to unexported %s", name, s)
+	}
+
+	// Append the declaration to the file of the new package.
+	filename := filepath.Join(dst.Dir, filepath.Base(src.Fset.File(decls[0].file.FileStart).Name()))
+	content, ok := plan.Files[filename]
+	if !ok {
+		content, err = os.ReadFile(filename)
+		if os.IsNotExist(err) {
+			content, err = []byte("package "+dst.Name+"\n"), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(content)
+	for _, text := range m.texts {
+		buf.WriteString("\n")
+		buf.WriteString(text)
+		buf.WriteString("\n")
+	}
+	content, err = fixImports(filename, buf.Bytes(), nil, m.imports)
+	if err != nil {
+		return nil, err
+	}
+	plan.Files[filename] = content
+
+	return plan, nil
+}
+
+// Apply makes the changes described by the plan, by writing the new
+// contents of the changed files.
+func (plan *DeclPlan) Apply() error {
+	for filename, content := range plan.Files {
+		if err := os.WriteFile(filename, content, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Describe returns the plan in the common form of package plan, for
+// machine-readable output, without applying it.
+func (plan *DeclPlan) Describe() (*refplan.Plan, error) {
+	p := &refplan.Plan{Tool: "move"}
+	for filename, content := range plan.Files {
+		before, err := os.ReadFile(filename)
+		if os.IsNotExist(err) {
+			before, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.AddFile(filename, before, content)
+	}
+	if plan.NewName != plan.Name {
+		p.Notes = append(p.Notes, refplan.Note{
+			Message: fmt.Sprintf("%s is renamed %s, as %s already declares %s", plan.Name, plan.NewName, plan.To, plan.Name),
+		})
+	}
+	return p, nil
+}
+
+// A movedDecl is the syntax of a declaration to be moved, which is a
+// whole declaration, or a single spec of a GenDecl group, in which
+// case tok is its keyword.
+type movedDecl struct {
+	file       *ast.File
+	start, end token.Pos
+	tok        token.Token // keyword of a grouped spec, or ILLEGAL
+	method     bool
+}
+
+// findDecls returns the syntax of the declaration of the package-level
+// object obj of the package, and of its methods if it is a type.
+func findDecls(pkg *packages.Package, obj types.Object) ([]movedDecl, error) {
+	var decls []movedDecl
+	for i, file := range pkg.Syntax {
+		test := strings.HasSuffix(pkg.CompiledGoFiles[i], "_test.go")
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if pkg.TypesInfo.Defs[decl.Name] == obj || decl.Recv != nil && recvObj(pkg.TypesInfo, decl) == obj {
+					if test {
+						return nil, fmt.Errorf("cannot move %s: %s is declared in a test file", obj.Name(), decl.Name.Name)
+					}
+					decls = append(decls, movedDecl{file, extent(decl.Doc, decl), decl.End(), token.ILLEGAL, decl.Recv != nil})
+				}
+
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					var names []*ast.Ident
+					var doc, comment *ast.CommentGroup
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						names, doc, comment = []*ast.Ident{spec.Name}, spec.Doc, spec.Comment
+					case *ast.ValueSpec:
+						names, doc, comment = spec.Names, spec.Doc, spec.Comment
+					}
+					if !slices.ContainsFunc(names, func(id *ast.Ident) bool { return pkg.TypesInfo.Defs[id] == obj }) {
+						continue
+					}
+					if len(names) > 1 {
+						return nil, fmt.Errorf("cannot move %s: it is declared together with %s", obj.Name(), names[0].Name)
+					}
+					if len(decl.Specs) == 1 {
+						decls = append(decls, movedDecl{file, extent(decl.Doc, decl), decl.End(), token.ILLEGAL, false})
+						break
+					}
+					if decl.Tok == token.CONST {
+						return nil, fmt.Errorf("cannot move %s: it is declared in a group of constants", obj.Name())
+					}
+					end := spec.End()
+					if comment != nil {
+						end = comment.End()
+					}
+					decls = append(decls, movedDecl{file, extent(doc, spec), end, decl.Tok, false})
+				}
+			}
+		}
+	}
+	if len(decls) == 0 {
+		return nil, fmt.Errorf("cannot find the declaration of %s", obj.Name())
+	}
+	// The declaration of the type precedes its methods.
+	slices.SortStableFunc(decls, func(x, y movedDecl) int {
+		switch {
+		case x.method == y.method:
+			return 0
+		case x.method:
+			return +1
+		default:
+			return -1
+		}
+	})
+	return decls, nil
+}
+
+// extent returns the start of the node n, including its doc comment.
+func extent(doc *ast.CommentGroup, n ast.Node) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return n.Pos()
+}
+
+// recvObj returns the named type of the receiver of the method decl.
+func recvObj(info *types.Info, decl *ast.FuncDecl) types.Object {
+	t := decl.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return info.Uses[id]
+	}
+	return nil
+}
+
+// declared reports whether any variant of the package with the
+// specified path declares name at package level.
+func declared(pkgs []*packages.Package, path, name string) bool {
+	for _, p := range pkgs {
+		if p.PkgPath == path && p.Types.Scope().Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reaches reports whether the package path x imports y, directly or
+// indirectly.
+func reaches(graph map[string][]string, x, y string) bool {
+	seen := make(map[string]bool)
+	var visit func(p string) bool
+	visit = func(p string) bool {
+		if p == y {
+			return true
+		}
+		if seen[p] {
+			return false
+		}
+		seen[p] = true
+		return slices.ContainsFunc(graph[p], visit)
+	}
+	return visit(x)
+}
+
+// A declMover holds the state of PrepareDecl.
+type declMover struct {
+	plan  *DeclPlan
+	fset  *token.FileSet
+	obj   types.Object // the moved object, in the non-test variant of the old package
+	decls []movedDecl
+	dst   *packages.Package
+
+	texts       []string          // text of each moved declaration, after its edits
+	imports     map[string]string // imports needed by the moved declaration: path to name, or "" for the default
+	unexported  []string          // unexported names of the old package referred to by the declaration
+	srcNeedsDst bool              // the old package refers to the declaration
+	dstNeedsSrc bool              // the declaration refers to the old package
+}
+
+// isMoved reports whether obj, which may belong to any variant of a
+// package, is the moved object.
+func (m *declMover) isMoved(obj types.Object) bool {
+	return obj != nil && obj.Pos() == m.obj.Pos() && obj.Name() == m.obj.Name() &&
+		obj.Pkg() != nil && obj.Pkg().Path() == m.obj.Pkg().Path()
+}
+
+// rewriteFile returns the new content of the file of package p, or nil
+// if it is unchanged, and records the text of the declarations it
+// moves out.
+func (m *declMover) rewriteFile(p *packages.Package, file *ast.File, filename string) ([]byte, error) {
+	info := p.TypesInfo
+	tokFile := m.fset.File(file.FileStart)
+	offset := tokFile.Offset
+
+	var moved []int // indices of the declarations moved out of the file
+	for i, d := range m.decls {
+		if d.file == file {
+			moved = append(moved, i)
+		}
+	}
+	enclosing := func(pos token.Pos) int {
+		return slices.IndexFunc(moved, func(i int) bool { return m.decls[i].start <= pos && pos < m.decls[i].end })
+	}
+
+	// The name by which the file refers to the new package.
+	dstName, imported := m.dst.Name, false
+	for _, imp := range file.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == m.plan.To {
+			if imp.Name != nil {
+				dstName = imp.Name.Name
+			}
+			imported = true
+		}
+	}
+
+	var (
+		edits      []diff.Edit                       // edits of the file outside the moved declarations
+		movedEdits = make([][]diff.Edit, len(moved)) // edits of each moved declaration
+		uses       = make(map[*types.PkgName]int)
+		removed    = make(map[*types.PkgName]int) // uses removed by the edits
+		needsDst   = false
+	)
+	edit := func(start, end token.Pos, text string) {
+		e := diff.Edit{Start: offset(start), End: offset(end), New: text}
+		if i := enclosing(start); i >= 0 {
+			e.Start -= offset(m.decls[moved[i]].start)
+			e.End -= offset(m.decls[moved[i]].start)
+			movedEdits[i] = append(movedEdits[i], e)
+		} else {
+			edits = append(edits, e)
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			id, ok := n.X.(*ast.Ident)
+			if !ok {
+				break
+			}
+			pkgName, ok := info.Uses[id].(*types.PkgName)
+			if !ok {
+				break
+			}
+			uses[pkgName]++
+			inside := enclosing(n.Pos()) >= 0
+			switch {
+			case m.isMoved(info.Uses[n.Sel]):
+				// a.X => b.X, or X within the new package
+				removed[pkgName]++
+				if p.Types.Path() == m.plan.To {
+					edit(n.Pos(), n.End(), m.plan.NewName)
+				} else {
+					edit(n.Pos(), n.End(), dstName+"."+m.plan.NewName)
+					needsDst = true
+				}
+
+			case inside && pkgName.Imported().Path() == m.plan.To:
+				// b.Y => Y within the moved declaration
+				removed[pkgName]++
+				edit(n.Pos(), n.Sel.Pos(), "")
+
+			case inside:
+				removed[pkgName]++
+				m.addImport(pkgName, id.Name)
+			}
+			return false
+
+		case *ast.Ident:
+			inside := enclosing(n.Pos()) >= 0
+			obj := info.Uses[n]
+			if obj == nil {
+				obj = info.Defs[n]
+			}
+			switch {
+			case m.isMoved(obj):
+				if inside {
+					if m.plan.NewName != m.plan.Name {
+						edit(n.Pos(), n.End(), m.plan.NewName)
+					}
+				} else if info.Uses[n] != nil {
+					// X => b.X within the old package
+					edit(n.Pos(), n.End(), dstName+"."+m.plan.NewName)
+					needsDst = true
+					m.srcNeedsDst = true
+				}
+
+			case inside && info.Uses[n] != nil && obj.Pkg() == p.Types && obj.Parent() == p.Types.Scope():
+				// Y => a.Y within the moved declaration
+				if !obj.Exported() {
+					m.unexported = append(m.unexported, obj.Name())
+				}
+				m.dstNeedsSrc = true
+				if m.imports == nil {
+					m.imports = make(map[string]string)
+				}
+				m.imports[m.plan.From] = ""
+				edit(n.Pos(), n.Pos(), p.Types.Name()+".")
+			}
+		}
+		return true
+	})
+
+	if len(edits) == 0 && len(moved) == 0 {
+		return nil, nil
+	}
+
+	// Extract the moved declarations.
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range moved {
+		d := m.decls[j]
+		text, err := diff.ApplyBytes(src[offset(d.start):offset(d.end)], movedEdits[i])
+		if err != nil {
+			return nil, err
+		}
+		if d.tok != token.ILLEGAL {
+			text = append([]byte(d.tok.String()+" "), text...)
+		}
+		m.texts[j] = string(text)
+		edits = append(edits, diff.Edit{Start: offset(d.start), End: offset(d.end)})
+	}
+
+	content, err := diff.ApplyBytes(src, edits)
+	if err != nil {
+		return nil, err
+	}
+	var unused []*types.PkgName
+	for pkgName, n := range uses {
+		if removed[pkgName] == n {
+			unused = append(unused, pkgName)
+		}
+	}
+	var add map[string]string
+	if needsDst && !imported {
+		if info.Scopes[file].Lookup(dstName) != nil || p.Types.Scope().Lookup(dstName) != nil {
+			return nil, fmt.Errorf("cannot import %s into %s: %s is already declared", m.plan.To, filename, dstName)
+		}
+		add = map[string]string{m.plan.To: ""}
+	}
+	return fixImports(filename, content, unused, add)
+}
+
+// addImport records that the moved declaration refers to the imported
+// package pkgName by the specified name.
+func (m *declMover) addImport(pkgName *types.PkgName, name string) {
+	if m.imports == nil {
+		m.imports = make(map[string]string)
+	}
+	if name == pkgName.Imported().Name() {
+		name = ""
+	}
+	m.imports[pkgName.Imported().Path()] = name
+}
+
+// fixImports deletes the unused imports from the file content and adds
+// the specified ones, which map a path to a name, or "" for the
+// default, and formats it.
+func fixImports(filename string, content []byte, unused []*types.PkgName, add map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkgName := range unused {
+		name := ""
+		if pkgName.Name() != pkgName.Imported().Name() {
+			name = pkgName.Name()
+		}
+		astutil.DeleteNamedImport(fset, f, name, pkgName.Imported().Path())
+	}
+	for path, name := range add {
+		astutil.AddNamedImport(fset, f, name, path)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
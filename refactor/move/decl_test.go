@@ -0,0 +1,228 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package move_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/internal/testfiles"
+	"golang.org/x/tools/refactor/move"
+	"golang.org/x/tools/txtar"
+)
+
+// declModule is a module whose package a declares things to move to
+// the other packages, of which c imports a, and a imports d.
+const declModule = `
+-- go.mod --
+module example.com
+
+go 1.22
+
+-- a/a.go --
+package a
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/d"
+)
+
+const Prefix = "> "
+
+// T is a type with methods.
+type T struct{ s string }
+
+// String returns the string.
+func (t T) String() string { return Prefix + strings.ToUpper(t.s) }
+
+func (t *T) Print() { fmt.Println(t) }
+
+// Uses collides with a function of package b.
+func Uses() int { return 2 }
+
+func callsUses() int { return Uses() + d.D() }
+
+func Exported() int { return 1 }
+
+func Helper() int { return Exported() }
+
+func useHelper() int { return Helper() }
+
+var (
+	V = 1
+	w = 2
+)
+
+func useW() int { return w }
+
+func usesV() int { return V }
+
+-- a/a_test.go --
+package a_test
+
+import (
+	"testing"
+
+	"example.com/a"
+)
+
+func TestT(t *testing.T) { _ = a.T{}.String() }
+
+-- b/b.go --
+package b
+
+func Uses() {}
+
+-- b/b_test.go --
+package b_test
+
+import (
+	"testing"
+
+	"example.com/a"
+)
+
+func TestV(t *testing.T) { _ = a.V }
+
+-- c/c.go --
+package c
+
+import "example.com/a"
+
+var X a.T
+
+func F() int { return a.Uses() }
+
+-- d/d.go --
+package d
+
+func D() int { return 0 }
+`
+
+func TestMoveDecl(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GOFLAGS", "") // -mod=mod would look up unknown packages in the module proxy
+
+	for _, test := range []struct {
+		name, to string
+		newName  string
+		files    map[string]string // substrings expected in files after the move
+	}{
+		{
+			name:    "T",
+			to:      "example.com/c",
+			newName: "T",
+			files: map[string]string{
+				"c/a.go":      "package c\n\nimport (\n\t\"example.com/a\"\n\t\"fmt\"\n\t\"strings\"\n)\n\n// T is a type with methods.\ntype T struct{ s string }\n\n// String returns the string.\nfunc (t T) String() string { return a.Prefix + strings.ToUpper(t.s) }\n\nfunc (t *T) Print() { fmt.Println(t) }\n",
+				"c/c.go":      "var X T",
+				"a/a_test.go": "\"example.com/c\"\n\t\"testing\"\n)\n\nfunc TestT(t *testing.T) { _ = c.T{}.String() }",
+			},
+		},
+		{
+			name:    "Uses",
+			to:      "example.com/b",
+			newName: "Uses2",
+			files: map[string]string{
+				"a/a.go": "func callsUses() int { return b.Uses2() + d.D() }",
+				"b/a.go": "// Uses collides with a function of package b.\nfunc Uses2() int { return 2 }",
+				"c/c.go": "import (\n\t\"example.com/a\"\n\t\"example.com/b\"\n)\n\nvar X a.T\n\nfunc F() int { return b.Uses2() }",
+			},
+		},
+		{
+			name:    "V",
+			to:      "example.com/b",
+			newName: "V",
+			files: map[string]string{
+				"a/a.go":      "var (\n\tw = 2\n)",
+				"b/a.go":      "var V = 1",
+				"b/b_test.go": "\"example.com/b\"\n\t\"testing\"\n)\n\nfunc TestV(t *testing.T) { _ = b.V }",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs, err := txtar.FS(txtar.Parse([]byte(declModule)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := testfiles.CopyToTmp(t, fs)
+			cfg := &packages.Config{Dir: dir}
+			plan, err := move.PrepareDecl(cfg, "example.com/a", test.name, test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.NewName != test.newName {
+				t.Errorf("NewName = %q, want %q", plan.NewName, test.newName)
+			}
+			desc, err := plan.Describe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(desc.Files) != len(plan.Files) || (len(desc.Notes) > 0) != (plan.NewName != plan.Name) {
+				t.Errorf("Describe() has %d files and notes %v, want %d files and a note only after a rename", len(desc.Files), desc.Notes, len(plan.Files))
+			}
+			if err := plan.Apply(); err != nil {
+				t.Fatal(err)
+			}
+
+			for name, want := range test.files {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(data), want) {
+					t.Errorf("%s does not contain %q:\n%s", name, want, data)
+				}
+			}
+
+			// The module must still build.
+			cfg.Mode = packages.LoadAllSyntax
+			cfg.Tests = true
+			pkgs, err := packages.Load(cfg, "example.com/...")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packages.PrintErrors(pkgs) > 0 {
+				t.Errorf("errors after move")
+			}
+			if obj := pkgs[0].Types.Scope().Lookup(test.name); pkgs[0].PkgPath == "example.com/a" && obj != nil {
+				t.Errorf("package a still declares %s", obj)
+			}
+		})
+	}
+}
+
+func TestMoveDeclErrors(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GOFLAGS", "") // -mod=mod would look up unknown packages in the module proxy
+
+	fs, err := txtar.FS(txtar.Parse([]byte(declModule)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := testfiles.CopyToTmp(t, fs)
+	cfg := &packages.Config{Dir: dir}
+	for _, test := range []struct {
+		from, name, to, want string
+	}{
+		{"example.com/a", "Helper", "example.com/b", "which refers to it"},
+		{"example.com/a", "T", "example.com/d", "which imports it"},
+		{"example.com/a", "useW", "example.com/b", "unexported w"},
+		{"example.com/a", "w", "example.com/b", "referred to by"},
+		{"example.com/a", "Nonesuch", "example.com/b", "no declaration"},
+		{"example.com/a", "T", "example.com/nonesuch", "not found"},
+		{"example.com/a", "T", "example.com/a", "its own package"},
+	} {
+		_, err := move.PrepareDecl(cfg, test.from, test.name, test.to)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("PrepareDecl(%s, %s, %s): got error %v, want error containing %q", test.from, test.name, test.to, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package move computes and applies the changes needed to move a Go
+// package, with its subpackages, to a new import path.
+//
+// Moving a package moves its directory, updates the package clause
+// and import comment of each of its files, and rewrites the imports
+// of the moved packages in every package of the main modules, which
+// are all the modules of the workspace if there is a go.work file.
+//
+// The package name changes to the last element of the new import
+// path, unless it differs from the last element of the old one, as
+// for a main package. An import that does not name the package is
+// given the old name, so that no references need change.
+//
+// If the package moves to a different module of the workspace, the
+// go.mod file of the destination module comes to require the modules
+// that the moved packages import, with their replacements. (The
+// modules of a workspace need not require one another.) Requirements
+// of the source module that are no longer needed are left for
+// 'go mod tidy' to remove.
+//
+// Prepare computes a Plan without changing any files, so that a
+// client, such as a command-line tool or an editor, may present the
+// changes before it applies them.
+//
+// PrepareDecl similarly computes a DeclPlan, which moves a single
+// package-level declaration from one package to another and rewrites
+// the references to it.
+package move // import "golang.org/x/tools/refactor/move"
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/diff"
+	refplan "golang.org/x/tools/refactor/plan"
+)
+
+// A Plan describes the changes that move a package.
+type Plan struct {
+	From, To       string            // old and new import paths of the package
+	FromDir, ToDir string            // old and new directories of the package
+	Packages       map[string]string // maps the import path of each moved package to its new one
+	Files          map[string][]byte // new contents of each changed file, by its name before the move
+}
+
+// Prepare returns the plan for moving the package whose import path
+// is from, and its subpackages, to the import path to, which must lie
+// within one of the main modules. It loads the packages of the main
+// modules using the specified configuration, whose Mode and Tests
+// fields it ignores; cfg may be nil.
+//
+// Prepare reports an error if the package is not in a main module, if
+// its directory contains a nested module, or if a package or file
+// already exists at a destination.
+func Prepare(cfg *packages.Config, from, to string) (*Plan, error) {
+	if from == to {
+		return nil, fmt.Errorf("cannot move %s to itself", from)
+	}
+	if strings.HasPrefix(to, from+"/") {
+		return nil, fmt.Errorf("cannot move %s into its own subdirectory %s", from, to)
+	}
+
+	var config packages.Config
+	if cfg != nil {
+		config = *cfg
+	}
+	config.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedModule
+	config.Tests = true
+	pkgs, err := packages.Load(&config, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the packages and the main modules.
+	byID := make(map[string]*packages.Package)
+	mainModules := make(map[string]*packages.Module)
+	for _, p := range pkgs {
+		byID[p.ID] = p
+		if p.Module != nil && p.Module.Main {
+			mainModules[p.Module.Path] = p.Module
+		}
+	}
+	root := byID[from]
+	if root == nil {
+		return nil, fmt.Errorf("package %s not found", from)
+	}
+	srcMod := root.Module
+	if srcMod == nil || !srcMod.Main {
+		return nil, fmt.Errorf("package %s is not in a main module", from)
+	}
+	var dstMod *packages.Module
+	for _, m := range mainModules {
+		if within(to, m.Path) && (dstMod == nil || len(m.Path) > len(dstMod.Path)) {
+			dstMod = m
+		}
+	}
+	if dstMod == nil {
+		return nil, fmt.Errorf("%s is not in a main module", to)
+	}
+
+	plan := &Plan{
+		From:     from,
+		To:       to,
+		FromDir:  root.Dir,
+		ToDir:    filepath.Join(dstMod.Dir, filepath.FromSlash(strings.TrimPrefix(to, dstMod.Path))),
+		Packages: make(map[string]string),
+		Files:    make(map[string][]byte),
+	}
+	if _, err := os.Stat(plan.ToDir); err == nil {
+		return nil, fmt.Errorf("cannot move %s to %s: %s already exists", from, to, plan.ToDir)
+	}
+	if err := filepath.WalkDir(plan.FromDir, func(name string, d os.DirEntry, err error) error {
+		if err == nil && d.Name() == "go.mod" {
+			err = fmt.Errorf("cannot move %s: its directory contains the module %s", from, filepath.Dir(name))
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	// Find the moved packages, which are those of the source module
+	// within the directory of the package, and their new paths.
+	movedDirs := make(map[string]string) // maps directory of moved package to its new path
+	for _, p := range pkgs {
+		if p.ID == p.PkgPath && within(p.PkgPath, from) && p.Module != nil && p.Module.Path == srcMod.Path {
+			newPath := to + strings.TrimPrefix(p.PkgPath, from)
+			plan.Packages[p.PkgPath] = newPath
+			movedDirs[p.Dir] = newPath
+		}
+	}
+	for _, p := range pkgs {
+		for _, newPath := range plan.Packages {
+			if p.PkgPath == newPath {
+				return nil, fmt.Errorf("cannot move %s to %s: package %s already exists", from, to, newPath)
+			}
+		}
+	}
+
+	// The package name changes only if it is the last element of
+	// the import path.
+	oldName, newName := root.Name, root.Name
+	if oldName == path.Base(from) {
+		newName = path.Base(to)
+		if !token.IsIdentifier(newName) {
+			return nil, fmt.Errorf("cannot move %s to %s: %q is not a valid package name", from, to, newName)
+		}
+	}
+
+	// Rewrite the files of the main modules, noting the modules that
+	// the moved packages import.
+	imported := make(map[string]*packages.Module)
+	fset := token.NewFileSet()
+	done := make(map[string]bool)
+	for _, p := range pkgs {
+		if p.Module == nil || !p.Module.Main || strings.HasSuffix(p.ID, ".test") {
+			continue // not in a main module, or a test main package
+		}
+		_, moved := movedDirs[p.Dir]
+		for _, filename := range slices.Concat(p.GoFiles, p.IgnoredFiles) {
+			if done[filename] || !strings.HasSuffix(filename, ".go") {
+				continue
+			}
+			done[filename] = true
+			content, err := plan.rewriteFile(fset, filename, movedDirs, oldName, newName)
+			if err != nil {
+				return nil, err
+			}
+			if content != nil {
+				plan.Files[filename] = content
+			}
+		}
+		if moved {
+			for _, imp := range p.Imports {
+				if q := byID[imp.ID]; q != nil && q.Module != nil && !q.Module.Main {
+					imported[q.Module.Path] = q.Module
+				}
+			}
+		}
+	}
+
+	// Update the go.mod file of the destination module.
+	if srcMod.Path != dstMod.Path {
+		srcFile, err := readModFile(srcMod.GoMod)
+		if err != nil {
+			return nil, err
+		}
+		dstFile, err := readModFile(dstMod.GoMod)
+		if err != nil {
+			return nil, err
+		}
+		changed := false
+		for modPath, m := range imported {
+			if require(dstFile, modPath, m.Version) {
+				changed = true
+			}
+			// Carry over the replacement of the module in the
+			// source module, if any.
+			for _, r := range srcFile.Replace {
+				if r.Old.Path == modPath && !replaced(dstFile, modPath) {
+					newPath := r.New.Path
+					if modfile.IsDirectoryPath(newPath) && !filepath.IsAbs(newPath) {
+						rel, err := filepath.Rel(dstMod.Dir, filepath.Join(srcMod.Dir, newPath))
+						if err != nil {
+							return nil, err
+						}
+						newPath = filepath.ToSlash(rel)
+						if !strings.HasPrefix(newPath, "../") {
+							newPath = "./" + newPath
+						}
+					}
+					if err := dstFile.AddReplace(r.Old.Path, r.Old.Version, newPath, r.New.Version); err != nil {
+						return nil, err
+					}
+					changed = true
+				}
+			}
+		}
+		if changed {
+			dstFile.Cleanup()
+			content, err := dstFile.Format()
+			if err != nil {
+				return nil, err
+			}
+			plan.Files[dstMod.GoMod] = content
+		}
+	}
+
+	return plan, nil
+}
+
+// rewriteFile returns the new content of the named file, or nil if it
+// is unchanged. It rewrites the imports of the moved packages, and,
+// in the files of the moved packages, their import comments and
+// package clauses.
+func (plan *Plan) rewriteFile(fset *token.FileSet, filename string, movedDirs map[string]string, oldName, newName string) ([]byte, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	tokFile := fset.File(f.FileStart)
+	offset := tokFile.Offset
+	var edits []diff.Edit
+
+	if newPath, ok := movedDirs[filepath.Dir(filename)]; ok {
+		// Update the package clause of the moved package itself.
+		if newPath == plan.To && oldName != newName {
+			name := f.Name.Name
+			if name == oldName || name == oldName+"_test" {
+				name = newName + strings.TrimPrefix(name, oldName)
+				edits = append(edits, diff.Edit{Start: offset(f.Name.Pos()), End: offset(f.Name.End()), New: name})
+			}
+		}
+
+		// Update the import comment, if any.
+		line := tokFile.Line(f.Name.End())
+		for _, cg := range f.Comments {
+			c := cg.List[0]
+			if c.Slash >= f.Name.End() && tokFile.Line(c.Slash) == line &&
+				(strings.HasPrefix(c.Text, `// import "`) || strings.HasPrefix(c.Text, `/* import "`)) {
+				text := "// import " + strconv.Quote(newPath)
+				if strings.HasPrefix(c.Text, "/*") {
+					text = "/* import " + strconv.Quote(newPath) + " */"
+				}
+				edits = append(edits, diff.Edit{Start: offset(c.Pos()), End: offset(c.End()), New: text})
+				break
+			}
+		}
+	}
+
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		newPath, ok := plan.Packages[importPath]
+		if !ok {
+			continue
+		}
+		edits = append(edits, diff.Edit{Start: offset(imp.Path.Pos()), End: offset(imp.Path.End()), New: strconv.Quote(newPath)})
+
+		// Preserve the name by which the file refers to the package.
+		if importPath == plan.From && oldName != newName {
+			if imp.Name == nil {
+				edits = append(edits, diff.Edit{Start: offset(imp.Path.Pos()), End: offset(imp.Path.Pos()), New: oldName + " "})
+			} else if imp.Name.Name == newName {
+				edits = append(edits, diff.Edit{Start: offset(imp.Name.Pos()), End: offset(imp.Path.Pos())})
+			}
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	return diff.ApplyBytes(src, edits)
+}
+
+// Apply makes the changes described by the plan: it writes the new
+// contents of the changed files, then moves the directory of the
+// package, creating its parent directory if necessary.
+func (plan *Plan) Apply() error {
+	for filename, content := range plan.Files {
+		if err := os.WriteFile(filename, content, 0666); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(plan.ToDir), 0777); err != nil {
+		return err
+	}
+	return os.Rename(plan.FromDir, plan.ToDir)
+}
+
+// Describe returns the plan in the common form of package plan, for
+// machine-readable output, without applying it.
+func (plan *Plan) Describe() (*refplan.Plan, error) {
+	p := &refplan.Plan{Tool: "move"}
+	for filename, content := range plan.Files {
+		before, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		p.AddFile(filename, before, content)
+		if filepath.Base(filename) == "go.mod" {
+			p.Notes = append(p.Notes, refplan.Note{
+				Pos:     filename,
+				Message: "requirements that the source module no longer needs are left for 'go mod tidy' to remove",
+			})
+		}
+	}
+	p.Renames = []refplan.Rename{{From: plan.FromDir, To: plan.ToDir}}
+	return p, nil
+}
+
+// readModFile reads and parses the named go.mod file.
+func readModFile(filename string) (*modfile.File, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return modfile.Parse(filename, data, nil)
+}
+
+// require adds to f a requirement of the module path at version,
+// unless it already requires that version or a later one, and
+// reports whether f changed.
+func require(f *modfile.File, path, version string) bool {
+	if f.Module != nil && f.Module.Mod.Path == path {
+		return false
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path == path && semver.Compare(r.Mod.Version, version) >= 0 {
+			return false
+		}
+	}
+	f.AddRequire(path, version)
+	return true
+}
+
+// replaced reports whether f replaces any version of the module path.
+func replaced(f *modfile.File, path string) bool {
+	for _, r := range f.Replace {
+		if r.Old.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether the import path p is prefix or lies beneath it.
+func within(p, prefix string) bool {
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
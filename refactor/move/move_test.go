@@ -0,0 +1,193 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package move_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/internal/testfiles"
+	"golang.org/x/tools/refactor/move"
+	"golang.org/x/tools/txtar"
+)
+
+// workspace is a go.work workspace of two modules, a and b,
+// the first of which requires the module c, replaced by a directory.
+const workspace = `
+-- go.work --
+go 1.22
+
+use (
+	./a
+	./b
+)
+
+-- a/go.mod --
+module example.com/a
+
+go 1.22
+
+require example.com/c v0.1.0
+
+replace example.com/c => ../c
+
+-- a/main.go --
+package main
+
+import (
+	"example.com/a/x"
+	"example.com/a/x/sub"
+	renamed "example.com/a/x"
+)
+
+func main() { x.F(); sub.G(); renamed.F() }
+
+-- a/util/util.go --
+package util
+
+func H() {}
+
+-- a/x/x.go --
+package x // import "example.com/a/x"
+
+import (
+	"example.com/a/util"
+	"example.com/c"
+)
+
+func F() { util.H(); c.C() }
+
+-- a/x/x_test.go --
+package x_test
+
+import (
+	"testing"
+
+	"example.com/a/x"
+)
+
+func TestF(t *testing.T) { x.F() }
+
+-- a/x/sub/sub.go --
+package sub
+
+func G() {}
+
+-- b/go.mod --
+module example.com/b
+
+go 1.22
+
+-- b/b.go --
+package b
+
+-- c/go.mod --
+module example.com/c
+
+go 1.22
+
+-- c/c.go --
+package c
+
+func C() {}
+`
+
+func TestMove(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GOFLAGS", "") // -mod=mod is incompatible with workspaces
+
+	for _, test := range []struct {
+		to    string
+		files map[string]string // substrings expected in files after the move
+	}{
+		{
+			to: "example.com/a/y",
+			files: map[string]string{
+				"a/main.go":     `x "example.com/a/y"` + "\n\t\"example.com/a/y/sub\"\n\trenamed \"example.com/a/y\"",
+				"a/y/x.go":      `package y // import "example.com/a/y"`,
+				"a/y/x_test.go": `x "example.com/a/y"`,
+				"a/go.mod":      "module example.com/a\n\ngo 1.22\n\nrequire example.com/c v0.1.0\n",
+			},
+		},
+		{
+			to: "example.com/b/x",
+			files: map[string]string{
+				"a/main.go": `"example.com/b/x"` + "\n\t\"example.com/b/x/sub\"\n\trenamed \"example.com/b/x\"",
+				"b/x/x.go":  `package x // import "example.com/b/x"`,
+				"b/go.mod":  "require example.com/c v0.1.0\n\nreplace example.com/c => ../c\n",
+			},
+		},
+	} {
+		t.Run(test.to, func(t *testing.T) {
+			fs, err := txtar.FS(txtar.Parse([]byte(workspace)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := testfiles.CopyToTmp(t, fs)
+			cfg := &packages.Config{Dir: filepath.Join(dir, "a")}
+			plan, err := move.Prepare(cfg, "example.com/a/x", test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := plan.Packages["example.com/a/x/sub"], test.to+"/sub"; got != want {
+				t.Errorf("new path of subpackage = %q, want %q", got, want)
+			}
+			if err := plan.Apply(); err != nil {
+				t.Fatal(err)
+			}
+
+			for name, want := range test.files {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(data), want) {
+					t.Errorf("%s does not contain %q:\n%s", name, want, data)
+				}
+			}
+
+			// The workspace must still build.
+			cfg.Mode = packages.LoadAllSyntax
+			cfg.Tests = true
+			pkgs, err := packages.Load(cfg, "example.com/a/...", "example.com/b/...")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packages.PrintErrors(pkgs) > 0 {
+				t.Errorf("errors after move")
+			}
+		})
+	}
+}
+
+func TestMoveErrors(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GOFLAGS", "")
+
+	fs, err := txtar.FS(txtar.Parse([]byte(workspace)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := testfiles.CopyToTmp(t, fs)
+	cfg := &packages.Config{Dir: filepath.Join(dir, "a")}
+	for _, test := range []struct {
+		from, to, want string
+	}{
+		{"example.com/a/x", "example.com/a/x/sub2", "into its own subdirectory"},
+		{"example.com/a/x", "example.com/a/util", "already exists"},
+		{"example.com/a/x", "example.com/c/x", "not in a main module"},
+		{"example.com/a/x", "example.com/b/go-x", "not a valid package name"},
+		{"example.com/a/nonesuch", "example.com/a/y", "not found"},
+	} {
+		_, err := move.Prepare(cfg, test.from, test.to)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Prepare(%s, %s): got error %v, want error containing %q", test.from, test.to, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package plan defines a common, machine-readable form for the changes
+// computed by the refactoring tools, such as rename, eg, and move.
+//
+// A Plan lists the edits to each file, the files and directories to
+// rename, any conflicts that make the change unsafe, and notes for a
+// reviewer, without applying any of them. Encoded as JSON, it lets
+// automation such as a bot post a preview of a refactoring on a pull
+// request, and apply the plan later once the change is approved.
+//
+// The edits to a file are expressed as byte offsets into its content
+// at the time the plan was made, whose SHA-256 hash the plan records,
+// so that Apply can refuse to edit a file that has changed since. For
+// display, the plan also records the edits as a unified diff.
+package plan // import "golang.org/x/tools/refactor/plan"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/internal/diff"
+)
+
+// A Plan describes the changes made by a refactoring.
+type Plan struct {
+	Tool      string   `json:"tool"`                // name of the refactoring, such as "rename"
+	Files     []File   `json:"files,omitempty"`     // edits to each file, in order of name
+	Renames   []Rename `json:"renames,omitempty"`   // files or directories to rename after the edits
+	Conflicts []Note   `json:"conflicts,omitempty"` // reasons the change is unsafe
+	Notes     []Note   `json:"notes,omitempty"`     // other information for a reviewer
+}
+
+// A File describes the edits to a file.
+type File struct {
+	Name  string `json:"name"`
+	Hash  string `json:"hash,omitempty"` // hex SHA-256 hash of the content before the edits; empty for a new file
+	New   bool   `json:"new,omitempty"`  // the file is created
+	Edits []Edit `json:"edits"`
+	Diff  string `json:"diff"` // the edits as a unified diff, for display
+}
+
+// An Edit replaces the bytes [Start, End) of a file with New.
+type Edit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	New   string `json:"new"`
+}
+
+// A Rename renames a file or directory, creating the parent directory
+// of To if necessary.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// A Note is a message about the change, optionally at a source position
+// in the form "file:line:column".
+type Note struct {
+	Pos     string `json:"pos,omitempty"`
+	Message string `json:"message"`
+}
+
+// AddFile records the change of the named file from the content before
+// to after, which is nil if the file is to be created. It does nothing
+// if the content is unchanged.
+func (p *Plan) AddFile(name string, before, after []byte) {
+	file := File{Name: name, New: before == nil}
+	if !file.New {
+		file.Hash = hash(before)
+	}
+	for _, e := range diff.Bytes(before, after) {
+		file.Edits = append(file.Edits, Edit{Start: e.Start, End: e.End, New: e.New})
+	}
+	if !file.New && len(file.Edits) == 0 {
+		return
+	}
+	file.Diff = diff.Unified(name, name, string(before), string(after))
+	i, found := slices.BinarySearchFunc(p.Files, name, func(f File, name string) int {
+		return strings.Compare(f.Name, name)
+	})
+	if found {
+		p.Files[i] = file
+	} else {
+		p.Files = slices.Insert(p.Files, i, file)
+	}
+}
+
+// Apply makes the changes described by the plan: it edits the files,
+// then renames the files and directories. Before it changes anything,
+// it checks that no file has changed since the plan was made, and that
+// no new file exists yet.
+//
+// Apply does not consult Conflicts; it is up to the client to decide
+// whether to apply a plan that has conflicts.
+func (p *Plan) Apply() error {
+	contents := make([][]byte, len(p.Files))
+	for i, file := range p.Files {
+		before, err := os.ReadFile(file.Name)
+		if file.New {
+			if err == nil {
+				return fmt.Errorf("%s already exists", file.Name)
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if hash(before) != file.Hash {
+			return fmt.Errorf("%s has changed since the plan was made", file.Name)
+		}
+		edits := make([]diff.Edit, len(file.Edits))
+		for j, e := range file.Edits {
+			edits[j] = diff.Edit{Start: e.Start, End: e.End, New: e.New}
+		}
+		after, err := diff.ApplyBytes(before, edits)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Name, err)
+		}
+		contents[i] = after
+	}
+
+	for i, file := range p.Files {
+		if err := os.WriteFile(file.Name, contents[i], 0666); err != nil {
+			return err
+		}
+	}
+	for _, r := range p.Renames {
+		if err := os.MkdirAll(filepath.Dir(r.To), 0777); err != nil {
+			return err
+		}
+		if err := os.Rename(r.From, r.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plan_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/refactor/plan"
+)
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	if err := os.WriteFile(a, []byte("package p\n\nvar x = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &plan.Plan{Tool: "test"}
+	p.AddFile(a, []byte("package p\n\nvar x = 1\n"), []byte("package p\n\nvar y = 1\n"))
+	p.AddFile(b, nil, []byte("package p\n"))
+	p.AddFile(filepath.Join(dir, "c.go"), []byte("package p\n"), []byte("package p\n")) // unchanged
+	p.Renames = []plan.Rename{{From: dir, To: dir + "2"}}
+	if len(p.Files) != 2 || p.Files[0].Name != a || !p.Files[1].New {
+		t.Fatalf("unexpected files: %+v", p.Files)
+	}
+	if want := "-var x = 1\n+var y = 1\n"; !strings.Contains(p.Files[0].Diff, want) {
+		t.Errorf("diff of a.go does not contain %q:\n%s", want, p.Files[0].Diff)
+	}
+
+	// The plan survives a round trip through JSON.
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p2 plan.Plan
+	if err := json.Unmarshal(data, &p2); err != nil {
+		t.Fatal(err)
+	}
+	if err := p2.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"a.go": "package p\n\nvar y = 1\n",
+		"b.go": "package p\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir+"2", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestApplyStale(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	for _, test := range []struct {
+		setup func(p *plan.Plan) error
+		want  string
+	}{
+		{
+			func(p *plan.Plan) error {
+				p.AddFile(a, []byte("package p\n"), []byte("package q\n"))
+				return os.WriteFile(a, []byte("package r\n"), 0666)
+			},
+			"has changed",
+		},
+		{
+			func(p *plan.Plan) error {
+				p.AddFile(b, nil, []byte("package q\n"))
+				return os.WriteFile(b, []byte("package r\n"), 0666)
+			},
+			"already exists",
+		},
+	} {
+		p := new(plan.Plan)
+		if err := test.setup(p); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Apply(); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Apply: got error %v, want error containing %q", err, test.want)
+		}
+	}
+	// Neither file was edited.
+	for _, name := range []string{a, b} {
+		if got, _ := os.ReadFile(name); string(got) != "package r\n" {
+			t.Errorf("%s = %q, want it unchanged", name, got)
+		}
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"go/token"
 	"go/types"
 
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/internal/typeparams"
 	"golang.org/x/tools/internal/typesinternal"
 	"golang.org/x/tools/refactor/satisfy"
@@ -208,7 +207,7 @@ func (r *renamer) checkInLocalScope(from types.Object) {
 //
 // Removing the old name (and all references to it) is always safe, and
 // requires no checks.
-func (r *renamer) checkInLexicalScope(from types.Object, info *loader.PackageInfo) {
+func (r *renamer) checkInLexicalScope(from types.Object, info *packageInfo) {
 	b := from.Parent() // the block defining the 'from' object
 	if b != nil {
 		toBlock, to := b.LookupParent(r.to, from.Parent().End())
@@ -311,7 +310,7 @@ func deeper(x, y *types.Scope) bool {
 // info that is a reference to obj in lexical scope.  block is the
 // lexical block enclosing the reference.  If fn returns false the
 // iteration is terminated and findLexicalRefs returns false.
-func forEachLexicalRef(info *loader.PackageInfo, obj types.Object, fn func(id *ast.Ident, block *types.Scope) bool) bool {
+func forEachLexicalRef(info *packageInfo, obj types.Object, fn func(id *ast.Ident, block *types.Scope) bool) bool {
 	ok := true
 	var stack []ast.Node
 
@@ -841,7 +840,7 @@ func recv(meth *types.Func) *types.Var {
 }
 
 // someUse returns an arbitrary use of obj within info.
-func someUse(info *loader.PackageInfo, obj types.Object) *ast.Ident {
+func someUse(info *packageInfo, obj types.Object) *ast.Ident {
 	for id, o := range info.Uses {
 		if o == obj {
 			return id
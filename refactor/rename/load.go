@@ -0,0 +1,401 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rename
+
+// This file contains the logic for loading and type-checking the
+// packages affected by a renaming, using go/packages to discover them.
+//
+// go/packages type-checks each test variant of a package separately,
+// so that a declaration in a package with in-package tests would be
+// represented by two objects. The renaming algorithm, like the
+// go/loader it was originally built on, wants exactly one object per
+// declaration, so it uses only the metadata from go/packages (which
+// accounts for modules, workspaces, and vendoring) and type-checks the
+// packages itself, augmenting each initial package with its
+// in-package test files.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/refactor/importgraph"
+)
+
+// A program is a set of type-checked packages.
+type program struct {
+	Fset        *token.FileSet
+	AllPackages map[*types.Package]*packageInfo
+	Imported    map[string]*packageInfo // initial packages, augmented by their in-package tests
+	Created     []*packageInfo          // external test packages of initial packages
+}
+
+// A packageInfo holds the syntax and type information of a package.
+type packageInfo struct {
+	Pkg    *types.Package
+	Files  []*ast.File
+	Errors []error // non-nil if the package had errors
+	types.Info
+}
+
+// PathEnclosingInterval returns the package containing the source
+// interval [start, end), and the path of nodes from the root of its
+// file to the interval, as described at astutil.PathEnclosingInterval.
+func (prog *program) PathEnclosingInterval(start, end token.Pos) (pkg *packageInfo, path []ast.Node, exact bool) {
+	for _, info := range prog.AllPackages {
+		for _, f := range info.Files {
+			if f.FileStart == token.NoPos {
+				// Workaround for #70162 (undefined FileStart).
+				// TODO(adonovan): delete once go1.24 is assured.
+				continue
+			}
+			tf := prog.Fset.File(f.FileStart)
+			if p := int(start); p < tf.Base() || p >= tf.Base()+tf.Size() {
+				continue
+			}
+			if path, exact := astutil.PathEnclosingInterval(f, start, end); path != nil {
+				return info, path, exact
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// packagesConfig returns a go/packages configuration for the specified
+// mode that honors the GOOS, GOARCH, GOPATH, cgo, and build tag
+// settings of ctxt.
+func packagesConfig(ctxt *build.Context, mode packages.LoadMode) *packages.Config {
+	cfg := &packages.Config{Mode: mode, Tests: true}
+	if ctxt != nil {
+		cgo := "0"
+		if ctxt.CgoEnabled {
+			cgo = "1"
+		}
+		cfg.Env = append(os.Environ(),
+			"GOOS="+ctxt.GOOS,
+			"GOARCH="+ctxt.GOARCH,
+			"GOPATH="+ctxt.GOPATH,
+			"CGO_ENABLED="+cgo)
+		if len(ctxt.BuildTags) > 0 {
+			cfg.BuildFlags = []string{"-tags=" + strings.Join(ctxt.BuildTags, ",")}
+		}
+	}
+	return cfg
+}
+
+// findPackage returns the import path of the package denoted by the
+// pattern, which is an import path, or "file=" and the name of a file
+// in the package. A file of an external test package belongs to the
+// package under test.
+func findPackage(ctxt *build.Context, pattern string) (string, error) {
+	pkgs, err := packages.Load(packagesConfig(ctxt, packages.NeedName|packages.NeedFiles), pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue // test main package
+		}
+		if strings.HasSuffix(pkg.Name, "_test") {
+			return strings.TrimSuffix(pkg.PkgPath, "_test"), nil
+		}
+		if len(pkg.GoFiles) > 0 || len(pkg.Errors) == 0 {
+			return pkg.PkgPath, nil
+		}
+	}
+	return "", fmt.Errorf("no package for %s", pattern)
+}
+
+// importGraph returns the reverse import graph of the workspace: all
+// packages in the main modules and their dependencies, or in GOPATH
+// mode, all packages in GOROOT and GOPATH. As with importgraph.Build,
+// the imports of a package's tests are considered imports of the
+// package itself, and packages that could not be loaded are reported
+// in the errors mapping.
+//
+// The graph may omit packages that cannot import any of the packages
+// whose paths are specified.
+func importGraph(ctxt *build.Context, paths []string) (reverse importgraph.Graph, errors map[string]error, err error) {
+	patterns, err := workspacePatterns(ctxt, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgs, err := packages.Load(packagesConfig(ctxt, packages.NeedName|packages.NeedImports), patterns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	reverse = make(importgraph.Graph)
+	errors = make(map[string]error)
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue // test main package
+		}
+		from := pkg.PkgPath
+		if strings.HasSuffix(pkg.Name, "_test") {
+			from = strings.TrimSuffix(from, "_test")
+		}
+		if len(pkg.Errors) > 0 {
+			errors[from] = pkg.Errors[0]
+		}
+		for _, imp := range pkg.Imports {
+			if from == imp.PkgPath {
+				continue // external test importing package under test
+			}
+			edges := reverse[imp.PkgPath]
+			if edges == nil {
+				edges = make(map[string]bool)
+				reverse[imp.PkgPath] = edges
+			}
+			edges[from] = true
+		}
+	}
+	return reverse, errors, nil
+}
+
+// workspacePatterns returns the package patterns for importGraph.
+// In GOPATH mode, if none of the specified packages belongs to GOROOT,
+// they are the top-level directories of GOPATH, as GOROOT packages
+// cannot import GOPATH packages; scanning all of GOROOT takes a while.
+func workspacePatterns(ctxt *build.Context, paths []string) ([]string, error) {
+	all := []string{"all"}
+	pkgs, err := packages.Load(packagesConfig(ctxt, packages.NeedName|packages.NeedFiles|packages.NeedModule), paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	gopath := build.Default.GOPATH
+	if ctxt != nil {
+		gopath = ctxt.GOPATH
+	}
+	var srcDirs []string
+	for _, dir := range filepath.SplitList(gopath) {
+		if dir, err := filepath.EvalSymlinks(filepath.Join(dir, "src")); err == nil {
+			srcDirs = append(srcDirs, dir)
+		}
+	}
+	inGOPATH := func(filename string) bool {
+		filename, err := filepath.EvalSymlinks(filename)
+		if err != nil {
+			return false
+		}
+		for _, dir := range srcDirs {
+			if strings.HasPrefix(filename, dir+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, pkg := range pkgs {
+		if pkg.Module != nil || len(pkg.GoFiles) == 0 || !inGOPATH(pkg.GoFiles[0]) {
+			return all, nil // module mode, or a GOROOT package
+		}
+	}
+
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, dir := range srcDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() && !seen[name] && !strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "_") && name != "testdata" {
+				seen[name] = true
+				patterns = append(patterns, name+"/...")
+			}
+		}
+	}
+	if patterns == nil {
+		return all, nil
+	}
+	return patterns, nil
+}
+
+// loadProgram loads the specified set of packages (plus their tests)
+// and all their dependencies, from source, using the specified build
+// context. Only packages in pkgs will have their functions bodies
+// type-checked.
+func loadProgram(ctxt *build.Context, pkgs map[string]bool) (*program, error) {
+	var patterns []string
+	for pkg := range pkgs {
+		patterns = append(patterns, pkg)
+	}
+	sort.Strings(patterns)
+	if Verbose {
+		for _, pkg := range patterns {
+			log.Printf("Loading package: %s", pkg)
+		}
+	}
+
+	cfg := packagesConfig(ctxt, packages.NeedName|packages.NeedFiles|packages.NeedCompiledGoFiles|
+		packages.NeedImports|packages.NeedDeps|packages.NeedTypesSizes|packages.NeedModule)
+	initial, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Choose the metadata for each package path. An initial package is
+	// represented by its in-package test variant, if any, and each
+	// external test package is created separately.
+	var (
+		byPath   = make(map[string]*packages.Package)
+		xtests   []*packages.Package
+		mainPkgs []string // import paths of initial packages
+	)
+	packages.Visit(initial, nil, func(pkg *packages.Package) {
+		switch {
+		case strings.HasSuffix(pkg.ID, ".test"):
+			// test main package
+		case strings.HasSuffix(pkg.Name, "_test") && pkgs[strings.TrimSuffix(pkg.PkgPath, "_test")]:
+			xtests = append(xtests, pkg)
+		case pkg.ID == pkg.PkgPath:
+			if byPath[pkg.PkgPath] == nil {
+				byPath[pkg.PkgPath] = pkg
+			}
+		case pkgs[pkg.PkgPath] && pkg.ID == fmt.Sprintf("%s [%s.test]", pkg.PkgPath, pkg.PkgPath):
+			byPath[pkg.PkgPath] = pkg // prefer in-package test variant
+		}
+	})
+	for _, pkg := range initial {
+		if pkgs[pkg.PkgPath] && pkg.ID == pkg.PkgPath {
+			mainPkgs = append(mainPkgs, pkg.PkgPath)
+		}
+	}
+
+	prog := &program{
+		Fset:        token.NewFileSet(),
+		AllPackages: make(map[*types.Package]*packageInfo),
+		Imported:    make(map[string]*packageInfo),
+	}
+
+	// check parses and type-checks the specified package, after its
+	// dependencies, and returns its information.
+	var cycle error
+	checked := make(map[*packages.Package]*packageInfo)
+	var check func(meta *packages.Package, stack []string) *packageInfo
+	check = func(meta *packages.Package, stack []string) *packageInfo {
+		stack = append(stack, meta.PkgPath)
+		if info, ok := checked[meta]; ok {
+			if info == nil && cycle == nil {
+				cycle = fmt.Errorf("import cycle: %s", strings.Join(stack, " -> "))
+			}
+			return info
+		}
+		checked[meta] = nil // in progress
+
+		info := &packageInfo{
+			Info: types.Info{
+				Types:        make(map[ast.Expr]types.TypeAndValue),
+				Defs:         make(map[*ast.Ident]types.Object),
+				Uses:         make(map[*ast.Ident]types.Object),
+				Implicits:    make(map[ast.Node]types.Object),
+				Instances:    make(map[*ast.Ident]types.Instance),
+				Scopes:       make(map[ast.Node]*types.Scope),
+				Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+				FileVersions: make(map[*ast.File]string),
+			},
+		}
+		for _, err := range meta.Errors {
+			info.Errors = append(info.Errors, err)
+		}
+		for _, filename := range meta.CompiledGoFiles {
+			f, err := parser.ParseFile(prog.Fset, filename, nil, parser.ParseComments)
+			if err != nil {
+				info.Errors = append(info.Errors, err)
+			}
+			if f != nil {
+				info.Files = append(info.Files, f)
+			}
+		}
+
+		tc := &types.Config{
+			// Optimization: don't type-check the bodies of functions in our
+			// dependencies, since we only need exported package members.
+			IgnoreFuncBodies: !pkgs[strings.TrimSuffix(meta.PkgPath, "_test")],
+			Importer: importerFunc(func(path string) (*types.Package, error) {
+				if path == "unsafe" {
+					return types.Unsafe, nil
+				}
+				imp := meta.Imports[path]
+				if imp == nil {
+					return nil, fmt.Errorf("no metadata for %s", path)
+				}
+				if alt := byPath[imp.PkgPath]; alt != nil {
+					imp = alt
+				}
+				if info := check(imp, stack); info != nil {
+					return info.Pkg, nil
+				}
+				return nil, fmt.Errorf("import cycle via %s", path)
+			}),
+			Error: func(err error) { info.Errors = append(info.Errors, err) },
+			Sizes: meta.TypesSizes,
+		}
+		if meta.Module != nil && meta.Module.GoVersion != "" {
+			tc.GoVersion = "go" + meta.Module.GoVersion
+		}
+		info.Pkg, _ = tc.Check(meta.PkgPath, prog.Fset, info.Files, &info.Info)
+
+		checked[meta] = info
+		prog.AllPackages[info.Pkg] = info
+		return info
+	}
+
+	for _, path := range mainPkgs {
+		prog.Imported[path] = check(byPath[path], nil)
+	}
+	for _, meta := range xtests {
+		prog.Created = append(prog.Created, check(meta, nil))
+	}
+	if cycle != nil {
+		return nil, cycle
+	}
+
+	// Ideally we would report all errors, but go/types reports
+	// certain "soft" errors that gc does not (Go issue 14596),
+	// so we allow them.
+	var errpkgs []string
+	for _, info := range prog.AllPackages {
+		if containsHardErrors(info.Errors) {
+			errpkgs = append(errpkgs, info.Pkg.Path())
+		}
+	}
+	if errpkgs != nil {
+		sort.Strings(errpkgs)
+		var more string
+		if len(errpkgs) > 3 {
+			more = fmt.Sprintf(" and %d more", len(errpkgs)-3)
+			errpkgs = errpkgs[:3]
+		}
+		return nil, fmt.Errorf("couldn't load packages due to errors: %s%s",
+			strings.Join(errpkgs, ", "), more)
+	}
+	return prog, nil
+}
+
+func containsHardErrors(errors []error) bool {
+	for _, err := range errors {
+		if err, ok := err.(types.Error); ok && err.Soft {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -17,7 +17,9 @@ import (
 	"go/ast"
 	"go/build"
 	"go/format"
+	"go/parser"
 	"go/token"
+	"go/types"
 	"log"
 	"os"
 	"os/exec"
@@ -25,6 +27,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -81,7 +84,7 @@ func Move(ctxt *build.Context, from, to, moveTmpl string) error {
 	}
 
 	// Load all the affected packages.
-	iprog, err := loadProgram(ctxt, affectedPackages)
+	iprog, err := loadContextProgram(ctxt, affectedPackages)
 	if err != nil {
 		return err
 	}
@@ -161,7 +164,7 @@ func subpackages(ctxt *build.Context, srcDir string, root string) map[string]boo
 type mover struct {
 	// iprog contains all packages whose contents need to be updated
 	// with new package names or import paths.
-	iprog *loader.Program
+	iprog *program
 	ctxt  *build.Context
 	// rev is the reverse import graph.
 	rev importgraph.Graph
@@ -363,3 +366,83 @@ func sameLine(fset *token.FileSet, x, y token.Pos) bool {
 var moveDirectory = func(from, to string) error {
 	return os.Rename(from, to)
 }
+
+// loadContextProgram is like loadProgram, but it loads packages using
+// go/loader through the specified build context, which may be virtual,
+// as moving a package is meaningful only in GOPATH mode.
+func loadContextProgram(ctxt *build.Context, pkgs map[string]bool) (*program, error) {
+	conf := loader.Config{
+		Build:      ctxt,
+		ParserMode: parser.ParseComments,
+
+		// TODO(adonovan): enable this.  Requires making a lot of code more robust!
+		AllowErrors: false,
+	}
+	// Optimization: don't type-check the bodies of functions in our
+	// dependencies, since we only need exported package members.
+	conf.TypeCheckFuncBodies = func(p string) bool {
+		return pkgs[p] || pkgs[strings.TrimSuffix(p, "_test")]
+	}
+
+	if Verbose {
+		var list []string
+		for pkg := range pkgs {
+			list = append(list, pkg)
+		}
+		sort.Strings(list)
+		for _, pkg := range list {
+			log.Printf("Loading package: %s", pkg)
+		}
+	}
+
+	for pkg := range pkgs {
+		conf.ImportWithTests(pkg)
+	}
+
+	// Ideally we would just return conf.Load() here, but go/types
+	// reports certain "soft" errors that gc does not (Go issue 14596).
+	// As a workaround, we set AllowErrors=true and then duplicate
+	// the loader's error checking but allow soft errors.
+	// It would be nice if the loader API permitted "AllowErrors: soft".
+	conf.AllowErrors = true
+	prog, err := conf.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var errpkgs []string
+	// Report hard errors in indirectly imported packages.
+	for _, info := range prog.AllPackages {
+		if containsHardErrors(info.Errors) {
+			errpkgs = append(errpkgs, info.Pkg.Path())
+		}
+	}
+	if errpkgs != nil {
+		var more string
+		if len(errpkgs) > 3 {
+			more = fmt.Sprintf(" and %d more", len(errpkgs)-3)
+			errpkgs = errpkgs[:3]
+		}
+		return nil, fmt.Errorf("couldn't load packages due to errors: %s%s",
+			strings.Join(errpkgs, ", "), more)
+	}
+
+	iprog := &program{
+		Fset:        prog.Fset,
+		AllPackages: make(map[*types.Package]*packageInfo),
+		Imported:    make(map[string]*packageInfo),
+	}
+	convert := func(info *loader.PackageInfo) *packageInfo {
+		return iprog.AllPackages[info.Pkg]
+	}
+	for pkg, info := range prog.AllPackages {
+		iprog.AllPackages[pkg] = &packageInfo{Pkg: pkg, Files: info.Files, Errors: info.Errors, Info: info.Info}
+	}
+	for path, info := range prog.Imported {
+		iprog.Imported[path] = convert(info)
+	}
+	for _, info := range prog.Created {
+		iprog.Created = append(iprog.Created, convert(info))
+	}
+	return iprog, nil
+}
@@ -2,22 +2,23 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package rename contains the obsolete implementation of the deleted
-// golang.org/x/tools/cmd/gorename. This logic has not worked properly
-// since the advent of Go modules, and should be deleted too.
+// Package rename contains the implementation of the deleted
+// golang.org/x/tools/cmd/gorename. It loads packages using go/packages,
+// and so supports modules, go.work workspaces, and vendoring, but
+// renaming of packages (see [Move]) still requires GOPATH mode.
 //
-// Use gopls instead, either via the Rename LSP method or the "gopls
-// rename" subcommand.
+// For interactive use, prefer gopls, either via the Rename LSP method
+// or the "gopls rename" subcommand.
 package rename
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go/ast"
 	"go/build"
 	"go/format"
-	"go/parser"
 	"go/token"
 	"go/types"
 	"io"
@@ -26,13 +27,10 @@ import (
 	"os/exec"
 	"path"
 	"regexp"
-	"sort"
 	"strconv"
-	"strings"
 
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/types/typeutil"
-	"golang.org/x/tools/refactor/importgraph"
+	"golang.org/x/tools/refactor/plan"
 	"golang.org/x/tools/refactor/satisfy"
 )
 
@@ -89,7 +87,8 @@ Flags:
 gorename automatically computes the set of packages that might be
 affected.  For a local renaming, this is just the package specified by
 -from or -offset, but for a potentially exported name, gorename scans
-the workspace ($GOROOT and $GOPATH).
+the workspace (the main modules and their dependencies, or in GOPATH
+mode, $GOROOT and $GOPATH).
 
 gorename rejects renamings of concrete methods that would change the
 assignability relation between types and interfaces. If the interface
@@ -120,7 +119,7 @@ $ gorename -from '"bytes".Buffer.Len' -to Size
 
 // Features:
 // - support running on packages specified as *.go files on the command line
-// - support running on programs containing errors (loader.Config.AllowErrors)
+// - support running on programs containing errors
 // - allow users to specify a scope other than "global" (to avoid being
 //   stuck by neglected packages in $GOPATH that don't build).
 // - support renaming the package clause (no object)
@@ -135,7 +134,6 @@ $ gorename -from '"bytes".Buffer.Len' -to Size
 //   all receiver vars of a given type,
 //   all local variables of a given type,
 //   all PkgNames for a given package.
-// - emit JSON output for other editors and tools.
 
 var (
 	// Force enables patching of the source files even if conflicts were reported.
@@ -146,6 +144,11 @@ var (
 	// Diff causes the tool to display diffs instead of rewriting files.
 	Diff bool
 
+	// JSON causes the tool to print a JSON plan of the changes, in the
+	// form of package golang.org/x/tools/refactor/plan, instead of
+	// rewriting files. The plan includes any conflicts.
+	JSON bool
+
 	// DiffCmd specifies the diff command used by the -d feature.
 	// (The command must accept a -u flag and two filename arguments.)
 	DiffCmd = "diff"
@@ -161,12 +164,12 @@ var (
 var stdout io.Writer = os.Stdout
 
 type renamer struct {
-	iprog              *loader.Program
+	iprog              *program
 	objsToUpdate       map[types.Object]bool
 	hadConflicts       bool
 	from, to           string
 	satisfyConstraints map[satisfy.Constraint]bool
-	packages           map[*types.Package]*loader.PackageInfo // subset of iprog.AllPackages to inspect
+	packages           map[*types.Package]*packageInfo // subset of iprog.AllPackages to inspect
 	msets              typeutil.MethodSetCache
 	changeMethods      bool
 }
@@ -178,7 +181,7 @@ var reportError = func(posn token.Position, message string) {
 // importName renames imports of fromPath within the package specified by info.
 // If fromName is not empty, importName renames only imports as fromName.
 // If the renaming would lead to a conflict, the file is left unchanged.
-func importName(iprog *loader.Program, info *loader.PackageInfo, fromPath, fromName, to string) error {
+func importName(iprog *program, info *packageInfo, fromPath, fromName, to string) error {
 	if fromName == to {
 		return nil // no-op (e.g. rename x/foo to y/foo)
 	}
@@ -202,7 +205,7 @@ func importName(iprog *loader.Program, info *loader.PackageInfo, fromPath, fromN
 			iprog:        iprog,
 			objsToUpdate: make(map[types.Object]bool),
 			to:           to,
-			packages:     map[*types.Package]*loader.PackageInfo{info.Pkg: info},
+			packages:     map[*types.Package]*packageInfo{info.Pkg: info},
 		}
 		r.check(from)
 		if r.hadConflicts {
@@ -217,7 +220,11 @@ func importName(iprog *loader.Program, info *loader.PackageInfo, fromPath, fromN
 	return nil
 }
 
-func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) error {
+// Main renames the object specified by offsetFlag or fromFlag to the
+// name to, as described in Usage. It loads packages in module or GOPATH
+// mode as the go command would in the current directory, using the
+// GOOS, GOARCH, GOPATH, cgo, and build tag settings of ctxt.
+func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) (err error) {
 	// -- Parse the -from or -offset specifier ----------------------------
 
 	if (offsetFlag == "") == (fromFlag == "") {
@@ -233,8 +240,36 @@ func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) error {
 		writeFile = diff
 	}
 
+	if JSON {
+		p := &plan.Plan{Tool: "rename"}
+		defer func(savedWrite func(string, []byte) error, savedReport func(token.Position, string)) {
+			writeFile, reportError = savedWrite, savedReport
+			if err == nil || err == ConflictError {
+				if Force && len(p.Conflicts) > 0 {
+					p.Notes = append(p.Notes, plan.Note{Message: "renamed despite conflicts; the result may be ill-formed"})
+				}
+				data, jsonErr := json.MarshalIndent(p, "", "\t")
+				if jsonErr != nil {
+					err = jsonErr
+					return
+				}
+				stdout.Write(append(data, '\n'))
+			}
+		}(writeFile, reportError)
+		writeFile = func(filename string, content []byte) error {
+			before, err := os.ReadFile(filename)
+			if err != nil {
+				return err
+			}
+			p.AddFile(filename, before, content)
+			return nil
+		}
+		reportError = func(posn token.Position, message string) {
+			p.Conflicts = append(p.Conflicts, plan.Note{Pos: posn.String(), Message: message})
+		}
+	}
+
 	var spec *spec
-	var err error
 	if fromFlag != "" {
 		spec, err = parseFromFlag(ctxt, fromFlag)
 	} else {
@@ -273,9 +308,16 @@ func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) error {
 		}
 
 		// Scan the workspace and build the import graph.
-		_, rev, errors := importgraph.Build(ctxt)
+		var fromPkgs []string
+		for _, obj := range fromObjects {
+			fromPkgs = append(fromPkgs, obj.Pkg().Path())
+		}
+		rev, errors, err := importGraph(ctxt, fromPkgs)
+		if err != nil {
+			return err
+		}
 		if len(errors) > 0 {
-			// With a large GOPATH tree, errors are inevitable.
+			// With a large workspace, errors are inevitable.
 			// Report them but proceed.
 			fmt.Fprintf(os.Stderr, "While scanning Go workspace:\n")
 			for path, err := range errors {
@@ -317,7 +359,7 @@ func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) error {
 		objsToUpdate: make(map[types.Object]bool),
 		from:         spec.fromName,
 		to:           to,
-		packages:     make(map[*types.Package]*loader.PackageInfo),
+		packages:     make(map[*types.Package]*packageInfo),
 	}
 
 	// A renaming initiated at an interface method indicates the
@@ -354,78 +396,6 @@ func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) error {
 	return r.update()
 }
 
-// loadProgram loads the specified set of packages (plus their tests)
-// and all their dependencies, from source, through the specified build
-// context.  Only packages in pkgs will have their functions bodies typechecked.
-func loadProgram(ctxt *build.Context, pkgs map[string]bool) (*loader.Program, error) {
-	conf := loader.Config{
-		Build:      ctxt,
-		ParserMode: parser.ParseComments,
-
-		// TODO(adonovan): enable this.  Requires making a lot of code more robust!
-		AllowErrors: false,
-	}
-	// Optimization: don't type-check the bodies of functions in our
-	// dependencies, since we only need exported package members.
-	conf.TypeCheckFuncBodies = func(p string) bool {
-		return pkgs[p] || pkgs[strings.TrimSuffix(p, "_test")]
-	}
-
-	if Verbose {
-		var list []string
-		for pkg := range pkgs {
-			list = append(list, pkg)
-		}
-		sort.Strings(list)
-		for _, pkg := range list {
-			log.Printf("Loading package: %s", pkg)
-		}
-	}
-
-	for pkg := range pkgs {
-		conf.ImportWithTests(pkg)
-	}
-
-	// Ideally we would just return conf.Load() here, but go/types
-	// reports certain "soft" errors that gc does not (Go issue 14596).
-	// As a workaround, we set AllowErrors=true and then duplicate
-	// the loader's error checking but allow soft errors.
-	// It would be nice if the loader API permitted "AllowErrors: soft".
-	conf.AllowErrors = true
-	prog, err := conf.Load()
-	if err != nil {
-		return nil, err
-	}
-
-	var errpkgs []string
-	// Report hard errors in indirectly imported packages.
-	for _, info := range prog.AllPackages {
-		if containsHardErrors(info.Errors) {
-			errpkgs = append(errpkgs, info.Pkg.Path())
-		}
-	}
-	if errpkgs != nil {
-		var more string
-		if len(errpkgs) > 3 {
-			more = fmt.Sprintf(" and %d more", len(errpkgs)-3)
-			errpkgs = errpkgs[:3]
-		}
-		return nil, fmt.Errorf("couldn't load packages due to errors: %s%s",
-			strings.Join(errpkgs, ", "), more)
-	}
-	return prog, nil
-}
-
-func containsHardErrors(errors []error) bool {
-	for _, err := range errors {
-		if err, ok := err.(types.Error); ok && err.Soft {
-			continue
-		}
-		return true
-	}
-	return false
-}
-
 // requiresGlobalRename reports whether this renaming could potentially
 // affect other packages in the Go workspace.
 func requiresGlobalRename(fromObjects []types.Object, to string) bool {
@@ -529,7 +499,7 @@ func (r *renamer) update() error {
 			}
 		}
 	}
-	if !Diff {
+	if !Diff && !JSON {
 		fmt.Printf("Renamed %d occurrence%s in %d file%s in %d package%s.\n",
 			nidents, plural(nidents),
 			len(filesToUpdate), plural(len(filesToUpdate)),
@@ -6,6 +6,7 @@ package rename
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"go/build"
 	"go/token"
@@ -20,6 +21,7 @@ import (
 	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/internal/aliases"
 	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/refactor/plan"
 )
 
 // TODO(adonovan): test reported source positions, somehow.
@@ -30,8 +32,10 @@ func TestConflicts(t *testing.T) {
 		reportError = savedReportError
 	}(writeFile, reportError)
 	writeFile = func(string, []byte) error { return nil }
+	gopathMode(t)
 
 	var ctxt *build.Context
+	var gopath string
 	for _, test := range []struct {
 		ctxt             *build.Context // nil => use previous
 		offset, from, to string         // values of the -offset/-from and -to flags
@@ -40,11 +44,11 @@ func TestConflicts(t *testing.T) {
 		// init() checks
 		{
 			ctxt: fakeContext(map[string][]string{
-				"fmt": {`package fmt; type Stringer interface { String() }`},
+				"fakefmt": {`package fakefmt; type Stringer interface { String() }`},
 				"main": {`
 package main
 
-import foo "fmt"
+import foo "fakefmt"
 
 var v foo.Stringer
 
@@ -67,12 +71,12 @@ func f() { v.String(); f() }
 
 		// Export checks
 		{
-			from: "fmt.Stringer", to: "stringer",
+			from: "fakefmt.Stringer", to: "stringer",
 			want: `renaming this type "Stringer" to "stringer" would make it unexported.*` +
 				`breaking references from packages such as "main"`,
 		},
 		{
-			from: "(fmt.Stringer).String", to: "string",
+			from: "(fakefmt.Stringer).String", to: "string",
 			want: `renaming this method "String" to "string" would make it unexported.*` +
 				`breaking references from packages such as "main"`,
 		},
@@ -389,9 +393,9 @@ var _ I = E{}
 			conflicts = append(conflicts, message)
 		}
 		if test.ctxt != nil {
-			ctxt = test.ctxt
+			ctxt, gopath = materialize(t, test.ctxt)
 		}
-		err := Main(ctxt, test.offset, test.from, test.to)
+		err := Main(ctxt, fakePath(test.offset, gopath), fakePath(test.from, gopath), test.to)
 		var prefix string
 		if test.offset == "" {
 			prefix = fmt.Sprintf("-from %q -to %q", test.from, test.to)
@@ -420,12 +424,13 @@ var _ I = E{}
 }
 
 func TestInvalidIdentifiers(t *testing.T) {
-	ctxt := fakeContext(map[string][]string{
+	gopathMode(t)
+	ctxt, _ := materialize(t, fakeContext(map[string][]string{
 		"main": {`
 package main
 
 func f() { }
-`}})
+`}}))
 
 	for _, test := range []struct {
 		from, to string // values of the -offset/-from and -to flags
@@ -462,8 +467,10 @@ func TestRewrites(t *testing.T) {
 	defer func(savedWriteFile func(string, []byte) error) {
 		writeFile = savedWriteFile
 	}(writeFile)
+	gopathMode(t)
 
 	var ctxt *build.Context
+	var gopath string
 	for _, test := range []struct {
 		ctxt             *build.Context    // nil => use previous
 		offset, from, to string            // values of the -from/-offset and -to flags
@@ -1312,12 +1319,14 @@ func main() {
 		},
 	} {
 		if test.ctxt != nil {
-			ctxt = test.ctxt
+			ctxt, gopath = materialize(t, test.ctxt)
 		}
 
 		got := make(map[string]string)
 		writeFile = func(filename string, content []byte) error {
-			got[filepath.ToSlash(filename)] = string(content)
+			// Report the file by its name in the fake GOPATH.
+			filename = strings.Replace(filepath.ToSlash(filename), filepath.ToSlash(gopath), "/go", 1)
+			got[filename] = string(content)
 			return nil
 		}
 
@@ -1329,7 +1338,7 @@ func main() {
 			continue
 		}
 
-		err := Main(ctxt, test.offset, test.from, test.to)
+		err := Main(ctxt, fakePath(test.offset, gopath), fakePath(test.from, gopath), test.to)
 		var prefix string
 		if test.offset == "" {
 			prefix = fmt.Sprintf("-from %q -to %q", test.from, test.to)
@@ -1440,6 +1449,174 @@ func justHereForTestingDiff() {
 	}
 }
 
+// TestJSON tests that the JSON plan records the edits and conflicts
+// of a renaming without applying it.
+func TestJSON(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+
+	defer func() {
+		JSON = false
+		stdout = os.Stdout
+	}()
+	JSON = true
+
+	dir := t.TempDir()
+	const goFile = `package p
+
+func f() { f() }
+
+func g() {}
+`
+	filename := filepath.Join(dir, "p.go")
+	for name, content := range map[string]string{
+		"go.mod": "module example.com/p\ngo 1.22\n",
+		"p.go":   goFile,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prevWD, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(prevWD)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// A conflicting renaming reports the conflicts and no edits.
+	buf := new(bytes.Buffer)
+	stdout = buf
+	if err := Main(&build.Default, "", `"example.com/p".f`, "g"); err != ConflictError {
+		t.Fatalf("Main: got error %v, want ConflictError", err)
+	}
+	var p plan.Plan
+	if err := json.Unmarshal(buf.Bytes(), &p); err != nil {
+		t.Fatalf("invalid plan: %v\n%s", err, buf)
+	}
+	if len(p.Conflicts) == 0 || len(p.Files) > 0 {
+		t.Errorf("conflicting plan has %d conflicts and %d files, want some and none:\n%s", len(p.Conflicts), len(p.Files), buf)
+	}
+
+	// A successful renaming leaves the file unchanged until the plan
+	// is applied.
+	buf = new(bytes.Buffer)
+	stdout = buf
+	if err := Main(&build.Default, "", `"example.com/p".f`, "h"); err != nil {
+		t.Fatal(err)
+	}
+	p = plan.Plan{}
+	if err := json.Unmarshal(buf.Bytes(), &p); err != nil {
+		t.Fatalf("invalid plan: %v\n%s", err, buf)
+	}
+	if p.Tool != "rename" || len(p.Files) != 1 || p.Files[0].Name != filename || len(p.Conflicts) > 0 {
+		t.Fatalf("unexpected plan:\n%s", buf)
+	}
+	if got, _ := os.ReadFile(filename); string(got) != goFile {
+		t.Errorf("file changed before the plan was applied:\n%s", got)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filename); !strings.Contains(string(got), "func h() { h() }") {
+		t.Errorf("unexpected file after applying the plan:\n%s", got)
+	}
+}
+
+// TestWorkspace tests a renaming that spans the modules of a go.work
+// workspace, including an external test package.
+func TestWorkspace(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+
+	defer func(savedWriteFile func(string, []byte) error) {
+		writeFile = savedWriteFile
+	}(writeFile)
+	got := make(map[string]string)
+	writeFile = func(filename string, content []byte) error {
+		got[filepath.Base(filename)] = string(content)
+		return nil
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.work":  "go 1.22\nuse (\n\t./a\n\t./b\n)\n",
+		"a/go.mod": "module example.com/a\ngo 1.22\n",
+		"a/a.go": `package a
+
+// F is a function.
+func F() {}
+`,
+		"b/go.mod": "module example.com/b\ngo 1.22\nrequire example.com/a v0.0.0\n",
+		"b/b.go": `package b
+
+import "example.com/a"
+
+func G() { a.F() }
+`,
+		"b/b_test.go": `package b_test
+
+import "example.com/a"
+
+var _ = a.F
+`,
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prevWD, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(prevWD)
+	if err := os.Chdir(filepath.Join(dir, "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Main(&build.Default, "", `"example.com/a".F`, "H"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"a.go": `package a
+
+// H is a function.
+func H() {}
+`,
+		"b.go": `package b
+
+import "example.com/a"
+
+func G() { a.H() }
+`,
+		"b_test.go": `package b_test
+
+import "example.com/a"
+
+var _ = a.H
+`,
+	}
+	for file, wantContent := range want {
+		if gotContent := got[file]; gotContent != wantContent {
+			t.Errorf("rewritten file %s: got <<<%s>>>, want <<<%s>>>", file, gotContent, wantContent)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("rewrote %d files, want %d", len(got), len(want))
+	}
+}
+
 // ---------------------------------------------------------------------
 
 // Simplifying wrapper around buildutil.FakeContext for packages whose
@@ -1454,7 +1631,48 @@ func fakeContext(pkgs map[string][]string) *build.Context {
 		}
 		pkgs2[path] = filemap
 	}
-	return buildutil.FakeContext(pkgs2)
+	ctxt := buildutil.FakeContext(pkgs2)
+	fakePackages[ctxt] = pkgs
+	return ctxt
+}
+
+// fakePackages records the packages of each context returned by fakeContext.
+var fakePackages = make(map[*build.Context]map[string][]string)
+
+// materialize writes the packages of a context returned by fakeContext
+// to a temporary GOPATH directory, so that they may be loaded by
+// go/packages, and returns a context for it, and the directory.
+func materialize(t *testing.T, fake *build.Context) (*build.Context, string) {
+	testenv.NeedsGoPackages(t)
+
+	gopath := t.TempDir()
+	for path, files := range fakePackages[fake] {
+		dir := filepath.Join(gopath, "src", filepath.FromSlash(path))
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+		for i, contents := range files {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.go", i)), []byte(contents), 0666); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	return &ctxt, gopath
+}
+
+// fakePath replaces the directory /go/src/ of the GOROOT of a fake
+// context in the file name of a -from or -offset flag by the src
+// directory of the materialized GOPATH.
+func fakePath(flag, gopath string) string {
+	return strings.Replace(flag, "/go/src/", filepath.ToSlash(gopath)+"/src/", 1)
+}
+
+// gopathMode causes the go command to use GOPATH mode
+// for the remainder of the test.
+func gopathMode(t *testing.T) {
+	t.Setenv("GO111MODULE", "off")
 }
 
 // helper for single-file main packages with no imports.
@@ -23,8 +23,6 @@ import (
 	"strconv"
 	"strings"
 
-	"golang.org/x/tools/go/buildutil"
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/internal/typesinternal"
 )
 
@@ -91,15 +89,11 @@ func parseFromFlag(ctxt *build.Context, fromFlag string) (*spec, error) {
 			return nil, fmt.Errorf("-from: filename %q must have a ::name suffix", main)
 		}
 		spec.filename = main
-		if !buildutil.FileExists(ctxt, spec.filename) {
-			return nil, fmt.Errorf("no such file: %s", spec.filename)
-		}
-
-		bp, err := buildutil.ContainingPackage(ctxt, wd, spec.filename)
+		pkg, err := containingPackage(ctxt, spec.filename)
 		if err != nil {
 			return nil, err
 		}
-		spec.pkg = bp.ImportPath
+		spec.pkg = pkg
 
 	} else {
 		// main is one of:
@@ -115,17 +109,14 @@ func parseFromFlag(ctxt *build.Context, fromFlag string) (*spec, error) {
 		spec.fromName = spec.searchFor
 	}
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
-
 	// Sanitize the package.
-	bp, err := ctxt.Import(spec.pkg, cwd, build.FindOnly)
-	if err != nil {
-		return nil, fmt.Errorf("can't find package %q", spec.pkg)
+	if spec.filename == "" {
+		pkg, err := findPackage(ctxt, spec.pkg)
+		if err != nil {
+			return nil, fmt.Errorf("can't find package %q", spec.pkg)
+		}
+		spec.pkg = pkg
 	}
-	spec.pkg = bp.ImportPath
 
 	if !isValidIdentifier(spec.fromName) {
 		return nil, fmt.Errorf("-from: invalid identifier %q", spec.fromName)
@@ -214,15 +205,11 @@ func parseOffsetFlag(ctxt *build.Context, offsetFlag string) (*spec, error) {
 	}
 
 	spec.filename = parts[0]
-	if !buildutil.FileExists(ctxt, spec.filename) {
-		return nil, fmt.Errorf("no such file: %s", spec.filename)
-	}
-
-	bp, err := buildutil.ContainingPackage(ctxt, wd, spec.filename)
+	pkg, err := containingPackage(ctxt, spec.filename)
 	if err != nil {
 		return nil, err
 	}
-	spec.pkg = bp.ImportPath
+	spec.pkg = pkg
 
 	for _, r := range parts[1] {
 		if !isDigit(r) {
@@ -236,7 +223,7 @@ func parseOffsetFlag(ctxt *build.Context, offsetFlag string) (*spec, error) {
 
 	// Parse the file and check there's an identifier at that offset.
 	fset := token.NewFileSet()
-	f, err := buildutil.ParseFile(fset, ctxt, nil, wd, spec.filename, parser.ParseComments)
+	f, err := parser.ParseFile(fset, spec.filename, nil, parser.ParseComments)
 	if err != nil {
 		return nil, fmt.Errorf("-offset %q: cannot parse file: %s", offsetFlag, err)
 	}
@@ -251,13 +238,18 @@ func parseOffsetFlag(ctxt *build.Context, offsetFlag string) (*spec, error) {
 	return &spec, nil
 }
 
-var wd = func() string {
-	wd, err := os.Getwd()
+// containingPackage returns the import path of the package containing
+// the named file.
+func containingPackage(ctxt *build.Context, filename string) (string, error) {
+	if _, err := os.Stat(filename); err != nil {
+		return "", fmt.Errorf("no such file: %s", filename)
+	}
+	abs, err := filepath.Abs(filename)
 	if err != nil {
-		panic("cannot get working directory: " + err.Error())
+		return "", err
 	}
-	return wd
-}()
+	return findPackage(ctxt, "file="+abs)
+}
 
 // For source trees built with 'go build', the -from or -offset
 // spec identifies exactly one initial 'from' object to rename ,
@@ -266,7 +258,7 @@ var wd = func() string {
 // copy of its library), so there may be multiple objects for
 // the same source entity.
 
-func findFromObjects(iprog *loader.Program, spec *spec) ([]types.Object, error) {
+func findFromObjects(iprog *program, spec *spec) ([]types.Object, error) {
 	if spec.filename != "" {
 		return findFromObjectsInFile(iprog, spec)
 	}
@@ -284,7 +276,7 @@ func findFromObjects(iprog *loader.Program, spec *spec) ([]types.Object, error)
 	// info := iprog.AllPackages[pkg]
 
 	// Workaround: lookup by value.
-	var info *loader.PackageInfo
+	var info *packageInfo
 	var pkg *types.Package
 	for pkg, info = range iprog.AllPackages {
 		if pkg.Path() == spec.pkg {
@@ -306,7 +298,7 @@ func findFromObjects(iprog *loader.Program, spec *spec) ([]types.Object, error)
 	return objects, nil
 }
 
-func findFromObjectsInFile(iprog *loader.Program, spec *spec) ([]types.Object, error) {
+func findFromObjectsInFile(iprog *program, spec *spec) ([]types.Object, error) {
 	var fromObjects []types.Object
 	for _, info := range iprog.AllPackages {
 		// restrict to specified filename
@@ -413,7 +405,7 @@ func typeSwitchVar(info *types.Info, path []ast.Node) types.Object {
 // spec.fromName matching the spec.  On success, the result has exactly
 // one element unless spec.searchFor!="", in which case it has at least one
 // element.
-func findObjects(info *loader.PackageInfo, spec *spec) ([]types.Object, error) {
+func findObjects(info *packageInfo, spec *spec) ([]types.Object, error) {
 	if spec.pkgMember == "" {
 		if spec.searchFor == "" {
 			panic(spec)
@@ -510,7 +502,7 @@ func findObjects(info *loader.PackageInfo, spec *spec) ([]types.Object, error) {
 	return objects, nil
 }
 
-func funcDecl(info *loader.PackageInfo, fn *types.Func) *ast.FuncDecl {
+func funcDecl(info *packageInfo, fn *types.Func) *ast.FuncDecl {
 	for _, f := range info.Files {
 		for _, d := range f.Decls {
 			if d, ok := d.(*ast.FuncDecl); ok && info.Defs[d.Name] == fn {
@@ -8,9 +8,25 @@
 // interface, and this fact is necessary for the package to be
 // well-typed.
 //
-// THIS PACKAGE IS EXPERIMENTAL AND MAY CHANGE AT ANY TIME.
+// Refactoring tools such as gopls and gorename use these constraints
+// to determine which methods must be renamed together. The package
+// requires well-typed inputs.
 //
-// It is provided only for the gopls tool. It requires well-typed inputs.
+// # Generics
+//
+// In addition to assignability conversions, the instantiation of a
+// generic function or type requires that each type argument satisfy
+// the constraint of its type parameter; each such fact whose
+// constraint has methods is reported as a Constraint whose LHS is the
+// constraint, as declared.
+//
+// Within a generic function, a value whose type is a type parameter
+// satisfies an interface only by virtue of the methods of its
+// constraint, so such a fact is reported as a Constraint whose RHS is
+// the constraint of the type parameter, not the type parameter itself.
+//
+// The types of a Constraint may mention type parameters, in which case
+// it is implicitly universally quantified over them.
 package satisfy // import "golang.org/x/tools/refactor/satisfy"
 
 // NOTES:
@@ -34,6 +50,9 @@ package satisfy // import "golang.org/x/tools/refactor/satisfy"
 // - in sends ch <- x, from x to the channel element type
 // - in type assertions x.(T) and switch x.(type) { case T: }
 //
+// - in instantiations F[T] or T[A], from each type argument to the
+//   constraint of its type parameter, whether explicit or inferred
+//
 // The results of this pass provide information equivalent to the
 // ssa.MakeInterface and ssa.ChangeInterface instructions.
 
@@ -62,9 +81,13 @@ type Constraint struct {
 // assignable to y, y is an interface, and both x and y have methods.
 //
 // In other words, it returns the subset of the "implements" relation
-// that is checked during compilation of a package.  Refactoring tools
-// will need to preserve at least this part of the relation to ensure
-// continued compilation.
+// that is checked during compilation of a package, including the
+// satisfaction of type parameter constraints by type arguments.
+// Refactoring tools will need to preserve at least this part of the
+// relation to ensure continued compilation.
+//
+// The zero value is ready to use. A single Finder may be used to
+// inspect several packages, accumulating their constraints.
 type Finder struct {
 	Result    map[Constraint]bool
 	msetcache typeutil.MethodSetCache
@@ -81,8 +104,9 @@ type Finder struct {
 // tends to preserves names of interface types better).
 //
 // The package must be free of type errors, and
-// info.{Defs,Uses,Selections,Types} must have been populated by the
-// type-checker.
+// info.{Defs,Uses,Selections,Types,Instances} must have been populated
+// by the type-checker. If info.Instances is nil, the constraints
+// arising from instantiation are not reported.
 func (f *Finder) Find(info *types.Info, files []*ast.File) {
 	if f.Result == nil {
 		f.Result = make(map[Constraint]bool)
@@ -108,9 +132,41 @@ func (f *Finder) Find(info *types.Info, files []*ast.File) {
 			}
 		}
 	}
+	f.instances(files)
 	f.info = nil
 }
 
+// instances records, for each instantiation of a generic function or
+// type within files, the constraints that its type arguments satisfy
+// the constraints of the corresponding type parameters.
+func (f *Finder) instances(files []*ast.File) {
+	for id, inst := range f.info.Instances {
+		if !within(id, files) {
+			continue
+		}
+		var tparams *types.TypeParamList
+		switch t := f.info.Uses[id].Type().(type) {
+		case *types.Named:
+			tparams = t.TypeParams()
+		case *types.Signature:
+			tparams = t.TypeParams()
+		}
+		for i := 0; i < tparams.Len() && i < inst.TypeArgs.Len(); i++ {
+			f.assign(tparams.At(i).Constraint(), inst.TypeArgs.At(i))
+		}
+	}
+}
+
+// within reports whether the node n lies within one of the files.
+func within(n ast.Node, files []*ast.File) bool {
+	for _, file := range files {
+		if file.FileStart <= n.Pos() && n.Pos() < file.FileEnd {
+			return true
+		}
+	}
+	return false
+}
+
 var (
 	tInvalid     = types.Typ[types.Invalid]
 	tUntypedBool = types.Typ[types.UntypedBool]
@@ -270,10 +326,17 @@ func (f *Finder) valueSpec(spec *ast.ValueSpec) {
 // types are uninteresting (e.g. lhs is a concrete type, or the empty
 // interface; rhs has no methods).
 func (f *Finder) assign(lhs, rhs types.Type) {
-	if types.Identical(lhs, rhs) {
+	if !isInterface(lhs) || typeparams.IsTypeParam(lhs) {
 		return
 	}
-	if !isInterface(lhs) {
+
+	// A type parameter satisfies an interface by virtue of
+	// its constraint, which must continue to satisfy it.
+	if tparam, ok := types.Unalias(rhs).(*types.TypeParam); ok {
+		rhs = tparam.Constraint()
+	}
+
+	if types.Identical(lhs, rhs) {
 		return
 	}
 
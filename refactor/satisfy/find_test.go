@@ -241,3 +241,65 @@ func constraints(t *testing.T, src string) []string {
 	sort.Strings(constraints)
 	return constraints
 }
+
+// This test exercises the constraints arising from instantiation and
+// from the use of values whose type is a type parameter.
+func TestGenericConstraints(t *testing.T) {
+	const src = `package foo
+
+type I interface { f() }
+
+type Lesser[T any] interface { Less(T) bool }
+
+type A struct{}
+func (A) f() {}
+
+type B int
+func (B) Less(B) bool { return false }
+
+type C struct{}
+func (C) f() {}
+
+type D struct{}
+func (D) f() {}
+
+func F[T I](T) {}
+
+func Min[T Lesser[T]](x, y T) T { return x }
+
+type Set[T interface{ comparable; f() }] map[T]bool
+
+func _() {
+	F(A{})                // I <- A (inferred)
+	F[C](C{})             // I <- C (explicit)
+	_ = Min(B(1), B(2))   // Lesser[T] <- B
+	_ = Set[D]{}          // interface{f(); comparable} <- D
+}
+
+func _[T I](x T) I {
+	F(x)     // no constraint: T's constraint is I
+	return x // I <- I, not recorded
+}
+
+type J interface { f(); g() }
+
+func _[T J](x T) I {
+	return x // I <- J
+}
+
+func _[T interface{ ~int | ~string }](x T) any {
+	return x // no methods
+}
+`
+	got := constraints(t, src)
+	want := []string{
+		"interface{f(); comparable} <- p.D",
+		"p.I <- p.A",
+		"p.I <- p.C",
+		"p.I <- p.J",
+		"p.Lesser[T] <- p.B",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("found unexpected constraints: got %s, want %s", got, want)
+	}
+}
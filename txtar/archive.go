@@ -11,7 +11,7 @@
 //   - diff nicely in git history and code reviews.
 //
 // Non-goals include being a completely general archive format,
-// storing binary data, storing file modes, storing special files like
+// storing binary data compactly, storing file modes, storing special files like
 // symbolic links, and so on.
 //
 // # Txtar format
@@ -29,13 +29,33 @@
 // parsers should consider a final newline to be present anyway.
 //
 // There are no possible syntax errors in a txtar archive.
+//
+// # Binary data
+//
+// Files whose data the format cannot hold, such as data that is not
+// UTF-8, that lacks a final newline, or that contains file marker
+// lines, may be stored base64-encoded by Encode or Writer.CreateBinary.
+// The file marker line of such a file is of the form
+// "-- FILENAME (base64) --", and its data is the base64 encoding of
+// the original data, in lines of at most 76 bytes. Decode, or a Reader
+// with its Decode field set, restores the original data. Archives
+// parsed without decoding keep the encoded files as they are, so an
+// archive may be encoded only where all of its readers decode it.
+//
+// # Streaming
+//
+// A Reader reads the files of an archive one at a time from an
+// io.Reader, and a Writer writes them one at a time to an io.Writer,
+// so that an archive need not be held in memory all at once.
 package txtar
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
 // An Archive is a collection of files.
@@ -127,6 +147,92 @@ func isMarker(data []byte) (name string, after []byte) {
 	return strings.TrimSpace(string(data[len(marker) : len(data)-len(markerEnd)])), after
 }
 
+// base64Suffix is the suffix of the name in the file
+// marker line of a base64-encoded file.
+const base64Suffix = " (base64)"
+
+// base64LineLen is the length of the lines of base64-encoded data.
+const base64LineLen = 76
+
+// Encode returns a copy of the archive a in which the data of each
+// file that Format cannot serialize faithfully is base64-encoded:
+// data that is not valid UTF-8, that is not empty and does not end
+// in a newline, or that contains a file marker line. The other files
+// of the copy share their data with a.
+func Encode(a *Archive) *Archive {
+	e := &Archive{Comment: a.Comment, Files: make([]File, len(a.Files))}
+	for i, f := range a.Files {
+		if needsEncoding(f) {
+			f = File{f.Name + base64Suffix, encodeBase64(f.Data)}
+		}
+		e.Files[i] = f
+	}
+	return e
+}
+
+// needsEncoding reports whether Format would not preserve the file f.
+func needsEncoding(f File) bool {
+	if strings.HasSuffix(f.Name, base64Suffix) {
+		return true // Decode would decode it
+	}
+	if len(f.Data) > 0 && f.Data[len(f.Data)-1] != '\n' {
+		return true
+	}
+	if !utf8.Valid(f.Data) {
+		return true
+	}
+	_, name, _ := findFileMarker(f.Data)
+	return name != ""
+}
+
+// encodeBase64 returns the base64 encoding of data,
+// split into lines of base64LineLen bytes.
+func encodeBase64(data []byte) []byte {
+	enc := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(enc, data)
+	var buf bytes.Buffer
+	for len(enc) > 0 {
+		n := min(len(enc), base64LineLen)
+		buf.Write(enc[:n])
+		buf.WriteByte('\n')
+		enc = enc[n:]
+	}
+	return buf.Bytes()
+}
+
+// Decode returns a copy of the archive a in which each file
+// base64-encoded by Encode or Writer.CreateBinary is decoded.
+// The other files of the copy share their data with a.
+// Decode returns an error if the data of an encoded file is
+// not valid base64.
+func Decode(a *Archive) (*Archive, error) {
+	d := &Archive{Comment: a.Comment, Files: make([]File, len(a.Files))}
+	for i, f := range a.Files {
+		if name, ok := strings.CutSuffix(f.Name, base64Suffix); ok {
+			data, err := decodeBase64(f.Data)
+			if err != nil {
+				return nil, fmt.Errorf("decoding %s: %v", name, err)
+			}
+			f = File{name, data}
+		}
+		d.Files[i] = f
+	}
+	return d, nil
+}
+
+// decodeBase64 returns the data encoded in base64 by enc,
+// ignoring newlines.
+func decodeBase64(enc []byte) ([]byte, error) {
+	enc = bytes.ReplaceAll(enc, []byte("\n"), nil)
+	enc = bytes.ReplaceAll(enc, []byte("\r"), nil)
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(enc)))
+	n, err := base64.StdEncoding.Decode(data, enc)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
 // If data is empty or ends in \n, fixNL returns data.
 // Otherwise fixNL returns a new slice consisting of data with a final \n added.
 func fixNL(data []byte) []byte {
@@ -0,0 +1,128 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DirOptions selects the files copied between an archive
+// and a directory tree by FromDir and Extract.
+//
+// The patterns have the syntax of path.Match, and are matched against
+// the slash-separated name of each file, relative to the root of the
+// tree, and against the names of the directories that contain it, so
+// that a pattern such as ".git" or "testdata/*" selects all the files
+// within the directories it matches.
+type DirOptions struct {
+	// Include, if not empty, lists the patterns of which a file
+	// must match at least one to be selected.
+	Include []string
+
+	// Exclude lists the patterns of which a file
+	// must match none to be selected.
+	Exclude []string
+}
+
+// selects reports whether the options select the file with the given name.
+// A nil *DirOptions selects all files.
+func (opts *DirOptions) selects(name string) (bool, error) {
+	if opts == nil {
+		return true, nil
+	}
+	if len(opts.Include) > 0 {
+		ok, err := matchAny(opts.Include, name)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	ok, err := matchAny(opts.Exclude, name)
+	return !ok, err
+}
+
+// matchAny reports whether any of the patterns matches the
+// file name or the name of any of its parent directories.
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		for dir := name; dir != "."; dir = path.Dir(dir) {
+			ok, err := path.Match(pattern, dir)
+			if err != nil {
+				return false, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+			}
+			if ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// FromDir returns an archive holding the regular files in the
+// directory tree rooted at dir that are selected by opts, which may
+// be nil to select all of them. The files are named by their
+// slash-separated paths relative to dir, and are listed in lexical
+// order. Symbolic links and other special files are ignored.
+//
+// The data of the files is as read; binary files should be encoded
+// by Encode before the archive is formatted.
+func FromDir(dir string, opts *DirOptions) (*Archive, error) {
+	a := new(Archive)
+	fsys := os.DirFS(dir)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		if ok, err := opts.selects(name); !ok || err != nil {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		a.Files = append(a.Files, File{name, data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Extract writes the files of the archive a that are selected by opts,
+// which may be nil to select all of them, to the directory tree rooted
+// at dir, creating dir and any other directories as needed, and
+// replacing any existing files of the same names.
+// It returns an error if any of the file names in the archive are not
+// valid file system names, before writing any files.
+func Extract(a *Archive, dir string, opts *DirOptions) error {
+	var files []File
+	for _, f := range a.Files {
+		if !fs.ValidPath(f.Name) || f.Name == "." || strings.Contains(f.Name, `\`) {
+			return fmt.Errorf("extracting %s: invalid file name", f.Name)
+		}
+		ok, err := opts.selects(f.Name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			files = append(files, f)
+		}
+	}
+	for _, f := range files {
+		name := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, f.Data, 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
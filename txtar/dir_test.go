@@ -0,0 +1,62 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+)
+
+func TestDir(t *testing.T) {
+	a := txtar.Parse([]byte(`-- a.go --
+package a
+-- a_test.go --
+package a
+-- .git/config --
+[core]
+-- testdata/x.txt --
+x
+-- testdata/y.go --
+package y
+`))
+	dir := t.TempDir()
+	if err := txtar.Extract(a, dir, &txtar.DirOptions{Exclude: []string{".git"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+		t.Errorf("excluded directory .git was extracted")
+	}
+
+	for _, test := range []struct {
+		opts *txtar.DirOptions
+		want string
+	}{
+		{nil, "-- a.go --\npackage a\n-- a_test.go --\npackage a\n-- testdata/x.txt --\nx\n-- testdata/y.go --\npackage y\n"},
+		{&txtar.DirOptions{Include: []string{"*.go"}}, "-- a.go --\npackage a\n-- a_test.go --\npackage a\n"},
+		{&txtar.DirOptions{Include: []string{"*.go", "testdata"}, Exclude: []string{"*_test.go", "testdata/*.go"}}, "-- a.go --\npackage a\n-- testdata/x.txt --\nx\n"},
+	} {
+		got, err := txtar.FromDir(dir, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(txtar.Format(got)) != test.want {
+			t.Errorf("FromDir(%+v):\n%s\nwant:\n%s", test.opts, txtar.Format(got), test.want)
+		}
+	}
+
+	if _, err := txtar.FromDir(dir, &txtar.DirOptions{Include: []string{"["}}); err == nil {
+		t.Errorf("FromDir with an invalid pattern succeeded, want an error")
+	}
+	bad := &txtar.Archive{Files: []txtar.File{{Name: "ok.txt"}, {Name: "../escape.txt"}}}
+	if err := txtar.Extract(bad, dir, nil); err == nil {
+		t.Errorf("Extract of ../escape.txt succeeded, want an error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ok.txt")); !os.IsNotExist(err) {
+		t.Errorf("Extract wrote files before failing")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// Get returns the data of the first file in the archive
+// with the given name, and reports whether there is one.
+func (a *Archive) Get(name string) ([]byte, bool) {
+	for _, f := range a.Files {
+		if f.Name == name {
+			return f.Data, true
+		}
+	}
+	return nil, false
+}
+
+// Set sets the data of the file in the archive with the given name,
+// keeping its position, and removes any later files with the same
+// name. If there is no such file, Set appends one to the archive.
+func (a *Archive) Set(name string, data []byte) {
+	i := slices.IndexFunc(a.Files, func(f File) bool { return f.Name == name })
+	if i < 0 {
+		a.Files = append(a.Files, File{name, data})
+		return
+	}
+	a.Files[i].Data = data
+	rest := slices.DeleteFunc(a.Files[i+1:], func(f File) bool { return f.Name == name })
+	a.Files = a.Files[:i+1+len(rest)]
+}
+
+// Delete removes the files in the archive with the given name,
+// and reports whether there were any.
+func (a *Archive) Delete(name string) bool {
+	n := len(a.Files)
+	a.Files = slices.DeleteFunc(a.Files, func(f File) bool { return f.Name == name })
+	return len(a.Files) < n
+}
+
+// Map returns a copy of the archive a in which the data of each file
+// matched by pattern is replaced by the result of calling f with the
+// file. The pattern has the syntax of path.Match, and matches a file
+// if it matches its name or the name of any directory that contains
+// it, as for DirOptions. If f returns an error, Map stops and returns
+// the error. The other files of the copy share their data with a.
+func Map(a *Archive, pattern string, f func(File) ([]byte, error)) (*Archive, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	b := &Archive{Comment: a.Comment, Files: slices.Clone(a.Files)}
+	for i, file := range b.Files {
+		ok, err := matchAny([]string{pattern}, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		data, err := f(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		b.Files[i].Data = data
+	}
+	return b, nil
+}
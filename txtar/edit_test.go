@@ -0,0 +1,95 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+)
+
+func TestEdit(t *testing.T) {
+	a := txtar.Parse([]byte(`comment
+-- a.txt --
+a
+-- b.txt --
+b
+-- a.txt --
+duplicate
+-- c.txt --
+c
+`))
+
+	if data, ok := a.Get("a.txt"); !ok || string(data) != "a\n" {
+		t.Errorf(`Get("a.txt") = %q, %t, want "a\n", true`, data, ok)
+	}
+	if data, ok := a.Get("d.txt"); ok {
+		t.Errorf(`Get("d.txt") = %q, true, want false`, data)
+	}
+
+	a.Set("a.txt", []byte("A\n"))
+	a.Set("d.txt", []byte("D\n"))
+	if !a.Delete("b.txt") {
+		t.Error(`Delete("b.txt") = false`)
+	}
+	if a.Delete("b.txt") {
+		t.Error(`second Delete("b.txt") = true`)
+	}
+	want := `comment
+-- a.txt --
+A
+-- c.txt --
+c
+-- d.txt --
+D
+`
+	if got := string(txtar.Format(a)); got != want {
+		t.Errorf("after Set and Delete, archive is:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMap(t *testing.T) {
+	a := txtar.Parse([]byte(`-- go.mod --
+module m
+-- a/a.go --
+package a
+-- a/a.golden --
+old
+-- b/b.golden --
+old
+`))
+	upper := func(f txtar.File) ([]byte, error) { return bytes.ToUpper(f.Data), nil }
+
+	for _, test := range []struct {
+		pattern, want string
+	}{
+		{"*/*.golden", "-- go.mod --\nmodule m\n-- a/a.go --\npackage a\n-- a/a.golden --\nOLD\n-- b/b.golden --\nOLD\n"},
+		{"a", "-- go.mod --\nmodule m\n-- a/a.go --\nPACKAGE A\n-- a/a.golden --\nOLD\n-- b/b.golden --\nold\n"},
+		{"a/*.go", "-- go.mod --\nmodule m\n-- a/a.go --\nPACKAGE A\n-- a/a.golden --\nold\n-- b/b.golden --\nold\n"},
+		{"x", "-- go.mod --\nmodule m\n-- a/a.go --\npackage a\n-- a/a.golden --\nold\n-- b/b.golden --\nold\n"},
+	} {
+		b, err := txtar.Map(a, test.pattern, upper)
+		if err != nil {
+			t.Fatalf("Map(%q): %v", test.pattern, err)
+		}
+		if got := string(txtar.Format(b)); got != test.want {
+			t.Errorf("Map(%q) =\n%s\nwant:\n%s", test.pattern, got, test.want)
+		}
+	}
+	if got := string(a.Files[3].Data); got != "old\n" {
+		t.Errorf("Map modified the original archive: %q", got)
+	}
+
+	if _, err := txtar.Map(a, "[", upper); err == nil {
+		t.Error("Map with invalid pattern succeeded")
+	}
+	errFail := errors.New("fail")
+	_, err := txtar.Map(a, "*/*.golden", func(txtar.File) ([]byte, error) { return nil, errFail })
+	if !errors.Is(err, errFail) {
+		t.Errorf("Map returned error %v, want %v", err, errFail)
+	}
+}
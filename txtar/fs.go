@@ -22,12 +22,21 @@ import (
 // If the file system detects that it has been modified, calls to the
 // file system return an ErrModified error.
 func FS(a *Archive) (fs.FS, error) {
+	fsys, err := newFilesystem(a)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create fs.FS from txtar.Archive: %s", err)
+	}
+	return fsys, nil
+}
+
+// newFilesystem returns the filesystem of the archive a.
+func newFilesystem(a *Archive) (*filesystem, error) {
 	// Create a filesystem with a root directory.
 	root := &node{fileinfo: fileinfo{path: ".", mode: readOnlyDir}}
 	fsys := &filesystem{a, map[string]*node{root.path: root}}
 
 	if err := initFiles(fsys); err != nil {
-		return nil, fmt.Errorf("cannot create fs.FS from txtar.Archive: %s", err)
+		return nil, err
 	}
 	return fsys, nil
 }
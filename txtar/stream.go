@@ -0,0 +1,308 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// A Reader reads an archive one file at a time.
+// Next advances to the next file, whose data is then read by Read.
+//
+// Reading an archive with a Reader yields the same comment and files
+// as Parse, with file data read by Read rather than held in memory.
+type Reader struct {
+	// If Decode is set, the Reader decodes the files
+	// base64-encoded by Encode or Writer.CreateBinary,
+	// as Decode does.
+	Decode bool
+
+	r       *bufio.Reader
+	comment []byte
+	started bool // whether the comment has been read
+
+	name    string    // name of the current file
+	dec     io.Reader // reader of the decoded data of the current file, if encoded
+	line    []byte    // unread data of the current line
+	midLine bool      // whether the next data read from r continues a line
+	done    bool      // whether the end of the current section has been reached
+	next    string    // name of the next file, if done
+	err     error     // sticky error
+}
+
+// NewReader returns a Reader reading an archive from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Comment returns the comment of the archive.
+func (r *Reader) Comment() ([]byte, error) {
+	if err := r.readComment(); err != nil {
+		return nil, err
+	}
+	return r.comment, nil
+}
+
+// readComment reads the comment of the archive, if it has not yet been read.
+func (r *Reader) readComment() error {
+	if r.started {
+		return nil
+	}
+	comment, err := io.ReadAll(section{r})
+	if err != nil {
+		return err
+	}
+	r.comment, r.started = comment, true
+	return nil
+}
+
+// Next advances to the next file in the archive, skipping any unread
+// data of the current one, and returns its name.
+// At the end of the archive, Next returns the error io.EOF.
+func (r *Reader) Next() (string, error) {
+	if err := r.readComment(); err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(io.Discard, section{r}); err != nil {
+		return "", err
+	}
+	r.name, r.dec = "", nil
+	if r.next == "" {
+		return "", io.EOF
+	}
+	r.name, r.next = r.next, ""
+	r.done, r.midLine = false, false
+	if r.Decode {
+		if name, ok := strings.CutSuffix(r.name, base64Suffix); ok {
+			r.name = name
+			r.dec = base64.NewDecoder(base64.StdEncoding, section{r})
+		}
+	}
+	return r.name, nil
+}
+
+// Read reads from the data of the current file in the archive.
+// It returns (0, io.EOF) when it reaches the end of that file,
+// until Next is called to advance to the next file.
+func (r *Reader) Read(b []byte) (int, error) {
+	if r.name == "" {
+		return 0, io.EOF
+	}
+	if r.dec != nil {
+		return r.dec.Read(b)
+	}
+	return section{r}.Read(b)
+}
+
+// A section reads the data of the current section of the archive,
+// the comment or a file, as it appears in the archive.
+type section struct{ r *Reader }
+
+func (s section) Read(b []byte) (int, error) {
+	r := s.r
+	for len(r.line) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.fill()
+	}
+	n := copy(b, r.line)
+	r.line = r.line[n:]
+	return n, nil
+}
+
+// fill reads the next line, or part of a long line, of the current
+// section into r.line, noting the end of the section at a file marker
+// line or at the end of the archive.
+func (r *Reader) fill() {
+	line, err := r.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull && !r.midLine && bytes.HasPrefix(line, marker) {
+		// A long line that may be a file marker line: read all of it.
+		line = append([]byte(nil), line...)
+		var rest []byte
+		rest, err = r.r.ReadBytes('\n')
+		line = append(line, rest...)
+	}
+	if !r.midLine {
+		if name, _ := isMarker(line); name != "" {
+			r.done, r.next = true, name
+			return
+		}
+	}
+	switch {
+	case err == bufio.ErrBufferFull:
+		r.midLine = true
+	case err == io.EOF:
+		r.done = true
+		if len(line) == 0 && r.midLine || len(line) > 0 && line[len(line)-1] != '\n' {
+			// As in Parse, supply the missing final newline.
+			line = append(append([]byte(nil), line...), '\n')
+		}
+	case err != nil:
+		r.err = err
+	default:
+		r.midLine = false
+	}
+	r.line = line
+}
+
+// A Writer writes an archive one file at a time.
+// Create or CreateBinary starts a new file, whose data is
+// written to the io.Writer they return.
+//
+// As with Format, the comment and the data of files started by Create
+// must not contain file marker lines, and a final newline is added to
+// them if it is missing.
+type Writer struct {
+	w       io.Writer
+	started bool           // whether a file has been started
+	midLine bool           // whether the data written so far ends within a line
+	enc     io.WriteCloser // encoder of the current file, if binary
+	err     error          // sticky error
+}
+
+// NewWriter returns a Writer writing an archive to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// errCommentAfterFile is returned by WriteComment after a file has been started.
+var errCommentAfterFile = errors.New("txtar: comment written after a file")
+
+// WriteComment writes the comment of the archive.
+// It must be called before any files are created.
+func (w *Writer) WriteComment(comment []byte) error {
+	if w.started {
+		return errCommentAfterFile
+	}
+	_, err := w.write(comment)
+	return err
+}
+
+// Create starts a new file with the given name, ending the current
+// file, if any, and returns an io.Writer for its data.
+// The io.Writer may be used only until the next call to Create,
+// CreateBinary, or Close.
+func (w *Writer) Create(name string) (io.Writer, error) {
+	if err := w.startFile(name); err != nil {
+		return nil, err
+	}
+	return textWriter{w}, nil
+}
+
+// CreateBinary is like Create, but the data written to the
+// returned io.Writer is base64-encoded, as by Encode, so that it
+// may hold any bytes.
+func (w *Writer) CreateBinary(name string) (io.Writer, error) {
+	if err := w.startFile(name + base64Suffix); err != nil {
+		return nil, err
+	}
+	w.enc = base64.NewEncoder(base64.StdEncoding, &lineWriter{w: textWriter{w}})
+	return w.enc, nil
+}
+
+// WriteFile writes the file f, base64-encoding its data if
+// Format could not serialize it faithfully, as Encode does.
+func (w *Writer) WriteFile(f File) error {
+	var fw io.Writer
+	var err error
+	if needsEncoding(f) {
+		fw, err = w.CreateBinary(f.Name)
+	} else {
+		fw, err = w.Create(f.Name)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(f.Data)
+	return err
+}
+
+// Close ends the current file, if any. It does not close the
+// underlying io.Writer.
+func (w *Writer) Close() error {
+	return w.endSection()
+}
+
+// startFile ends the current section and writes the file marker line
+// of a file with the given name.
+func (w *Writer) startFile(name string) error {
+	if err := w.endSection(); err != nil {
+		return err
+	}
+	w.started = true
+	_, err := w.write([]byte("-- " + name + " --\n"))
+	return err
+}
+
+// endSection completes the data of the current section.
+func (w *Writer) endSection() error {
+	if w.enc != nil {
+		enc := w.enc
+		w.enc = nil
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+	if w.midLine {
+		if _, err := w.write([]byte("\n")); err != nil {
+			return err
+		}
+	}
+	return w.err
+}
+
+// write writes b to the underlying io.Writer.
+func (w *Writer) write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(b)
+	if n > 0 {
+		w.midLine = b[n-1] != '\n'
+	}
+	w.err = err
+	return n, err
+}
+
+// A textWriter writes the data of a file started by Create.
+type textWriter struct{ w *Writer }
+
+func (t textWriter) Write(b []byte) (int, error) { return t.w.write(b) }
+
+// A lineWriter splits the data written to it
+// into lines of base64LineLen bytes.
+type lineWriter struct {
+	w   io.Writer
+	col int
+}
+
+func (l *lineWriter) Write(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		if l.col == base64LineLen {
+			if _, err := l.w.Write([]byte("\n")); err != nil {
+				return n, err
+			}
+			l.col = 0
+		}
+		m := min(len(b), base64LineLen-l.col)
+		if _, err := l.w.Write(b[:m]); err != nil {
+			return n, err
+		}
+		n += m
+		l.col += m
+		b = b[m:]
+	}
+	return n, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var streamTests = []string{
+	"",
+	"comment only",
+	"-- a --\n-- b --",
+	"comment\n-- a --\nA\n-- b --\nB without newline",
+	"-- a --\n-- not a marker\n -- b --\n--  c  --\nC\n",
+	"-- long --\n" + strings.Repeat("x", 10000) + "\n-- " + strings.Repeat("y", 10000) + " --\n" + strings.Repeat("z", 5000),
+	"-- long marker --\n-- " + strings.Repeat("m", 10000) + " -\n-- " + strings.Repeat("n", 10000) + " --\nN",
+	"-- bin (base64) --\nAAEC\n-- bad (base64) --\n!!!\n",
+}
+
+// readAll reads an archive with r.
+func readAll(r *Reader) (*Archive, error) {
+	a := new(Archive)
+	for {
+		name, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		a.Files = append(a.Files, File{name, data})
+	}
+	comment, err := r.Comment()
+	if err != nil {
+		return nil, err
+	}
+	a.Comment = comment
+	return a, nil
+}
+
+// equal reports whether the archives have the same contents,
+// treating nil and empty data alike.
+func equal(a, b *Archive) bool {
+	if !bytes.Equal(a.Comment, b.Comment) || len(a.Files) != len(b.Files) {
+		return false
+	}
+	for i := range a.Files {
+		if a.Files[i].Name != b.Files[i].Name || !bytes.Equal(a.Files[i].Data, b.Files[i].Data) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReader(t *testing.T) {
+	for _, text := range streamTests {
+		want := Parse([]byte(text))
+		got, err := readAll(NewReader(strings.NewReader(text)))
+		if err != nil {
+			t.Errorf("reading %.40q: %v", text, err)
+		} else if !equal(got, want) {
+			t.Errorf("reading %.40q: wrong output:\nhave:\n%.400s\nwant:\n%.400s", text, shortArchive(got), shortArchive(want))
+		}
+	}
+}
+
+func TestReaderSkip(t *testing.T) {
+	r := NewReader(strings.NewReader("comment\n-- a --\nA\n-- b --\nB\n"))
+	for _, want := range []string{"a", "b"} {
+		if name, err := r.Next(); name != want || err != nil {
+			t.Fatalf("Next() = %q, %v; want %q, nil", name, err, want)
+		}
+	}
+	if data, err := io.ReadAll(r); string(data) != "B\n" || err != nil {
+		t.Errorf("ReadAll = %q, %v; want %q, nil", data, err, "B\n")
+	}
+	if name, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() = %q, %v; want io.EOF", name, err)
+	}
+	if comment, err := r.Comment(); string(comment) != "comment\n" || err != nil {
+		t.Errorf("Comment() = %q, %v; want %q, nil", comment, err, "comment\n")
+	}
+}
+
+var binaryArchive = &Archive{
+	Comment: []byte("comment\n"),
+	Files: []File{
+		{"text", []byte("hello\n")},
+		{"empty", []byte{}},
+		{"noNL", []byte("no newline")},
+		{"binary", []byte{0, 1, 2, 0xff, 0xfe, '\n'}},
+		{"marker", []byte("line\n-- inner --\nmore\n")},
+		{"name (base64)", []byte("looks encoded\n")},
+		{"long", bytes.Repeat([]byte{0x80}, 200)},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	enc := Encode(binaryArchive)
+	var encoded []string
+	for _, f := range enc.Files {
+		if strings.HasSuffix(f.Name, base64Suffix) {
+			encoded = append(encoded, f.Name)
+		}
+		for _, line := range strings.SplitAfter(string(f.Data), "\n") {
+			if len(line) > base64LineLen+1 {
+				t.Errorf("file %q has a line of length %d", f.Name, len(line))
+			}
+		}
+	}
+	want := []string{"noNL (base64)", "binary (base64)", "marker (base64)", "name (base64) (base64)", "long (base64)"}
+	if !reflect.DeepEqual(encoded, want) {
+		t.Errorf("encoded files = %q, want %q", encoded, want)
+	}
+
+	dec, err := Decode(Parse(Format(enc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(dec, binaryArchive) {
+		t.Errorf("Decode after Encode: wrong output:\nhave:\n%s\nwant:\n%s", shortArchive(dec), shortArchive(binaryArchive))
+	}
+
+	if _, err := Decode(Parse([]byte("-- bad (base64) --\n!!!\n"))); err == nil {
+		t.Errorf("Decode of invalid base64 succeeded, want an error")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WriteComment(binaryArchive.Comment); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range binaryArchive.Files {
+		if err := w.WriteFile(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := Format(Encode(binaryArchive)); buf.String() != string(want) {
+		t.Errorf("Writer output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if err := w.WriteComment([]byte("late")); err == nil {
+		t.Errorf("WriteComment after a file succeeded, want an error")
+	}
+
+	r := NewReader(&buf)
+	r.Decode = true
+	got, err := readAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(got, binaryArchive) {
+		t.Errorf("decoding Reader: wrong output:\nhave:\n%s\nwant:\n%s", shortArchive(got), shortArchive(binaryArchive))
+	}
+}
+
+func TestWriterText(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	fw, err := w.Create("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(fw, "partial")
+	io.WriteString(fw, " line")
+	if _, err := w.Create("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "-- a --\npartial line\n-- b --\n"; got != want {
+		t.Errorf("Writer output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A WritableFS is an in-memory file system, initially holding the
+// files of an archive, whose files may be written and removed.
+// Its Archive method serializes its current contents, so that a
+// test may modify a fixture and compare the result with a golden
+// archive. Its methods may be called concurrently.
+type WritableFS struct {
+	mu   sync.Mutex
+	fsys *filesystem // file system of the current contents; its archive is never modified
+}
+
+var _ fs.ReadFileFS = (*WritableFS)(nil)
+
+// NewWritableFS returns a WritableFS holding the files of the archive a,
+// which it does not modify.
+// It returns an error if any of the file names in the archive
+// are not valid file system names.
+func NewWritableFS(a *Archive) (*WritableFS, error) {
+	fsys, err := newFilesystem(&Archive{Comment: a.Comment, Files: slices.Clone(a.Files)})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create txtar.WritableFS from txtar.Archive: %s", err)
+	}
+	return &WritableFS{fsys: fsys}, nil
+}
+
+// current returns the file system of the current contents.
+func (w *WritableFS) current() *filesystem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fsys
+}
+
+func (w *WritableFS) Open(name string) (fs.File, error) {
+	return w.current().Open(name)
+}
+
+func (w *WritableFS) ReadFile(name string) ([]byte, error) {
+	return w.current().ReadFile(name)
+}
+
+// Archive returns an archive holding the current contents of the file
+// system: the files of the original archive, in their original order,
+// followed by the files created by WriteFile, in the order of creation.
+// The archive shares file data with the file system, and neither
+// the archive nor the data may be modified.
+func (w *WritableFS) Archive() *Archive {
+	ar := w.current().ar
+	return &Archive{Comment: ar.Comment, Files: slices.Clone(ar.Files)}
+}
+
+// WriteFile writes data to the named file, creating it if necessary,
+// along with its parent directories. Files opened earlier keep their
+// previous contents. WriteFile does not retain data.
+func (w *WritableFS) WriteFile(name string, data []byte) error {
+	return w.update("write", name, func(files []File) ([]File, error) {
+		f := File{name, slices.Clone(data)}
+		if i := slices.IndexFunc(files, func(f File) bool { return f.Name == name }); i >= 0 {
+			files[i] = f
+			return files, nil
+		}
+		return append(files, f), nil
+	})
+}
+
+// Remove removes the named file.
+func (w *WritableFS) Remove(name string) error {
+	return w.update("remove", name, func(files []File) ([]File, error) {
+		i := slices.IndexFunc(files, func(f File) bool { return f.Name == name })
+		if i < 0 {
+			if n := w.fsys.nodes[name]; n != nil && n.IsDir() {
+				return nil, errIsDir
+			}
+			return nil, fs.ErrNotExist
+		}
+		return slices.Delete(files, i, i+1), nil
+	})
+}
+
+// errIsDir is returned by Remove for a directory.
+var errIsDir = errors.New("is a directory")
+
+// RemoveAll removes the named file, or the named directory and all the
+// files within it. It returns nil if there is no such file or directory.
+func (w *WritableFS) RemoveAll(name string) error {
+	return w.update("removeall", name, func(files []File) ([]File, error) {
+		return slices.DeleteFunc(files, func(f File) bool {
+			return name == "." || f.Name == name || strings.HasPrefix(f.Name, name+"/")
+		}), nil
+	})
+}
+
+// update replaces the contents of the file system with the files
+// returned by edit, given a copy of the current files.
+// The errors of update are *fs.PathErrors for the operation op
+// on the named file.
+func (w *WritableFS) update(op, name string, edit func([]File) ([]File, error)) error {
+	if !fs.ValidPath(name) || name == "." && op != "removeall" {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	files, err := edit(slices.Clone(w.fsys.ar.Files))
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	fsys, err := newFilesystem(&Archive{Comment: w.fsys.ar.Comment, Files: files})
+	if err != nil {
+		// A conflict between a file and a directory.
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	w.fsys = fsys
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/tools/txtar"
+)
+
+func TestWritableFS(t *testing.T) {
+	a := txtar.Parse([]byte(`comment
+-- one.txt --
+one
+-- dir/two.txt --
+two
+-- dir/sub/three.txt --
+three
+`))
+	fsys, err := txtar.NewWritableFS(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "one.txt", "dir/two.txt", "dir/sub/three.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := fsys.Open("one.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("one.txt", []byte("uno\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("new/four.txt", []byte("four\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.RemoveAll("dir/sub"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.Remove("dir/two.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "one.txt", "new/four.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(old); string(data) != "one\n" || err != nil {
+		t.Errorf("earlier opened file = %q, %v; want %q, nil", data, err, "one\n")
+	}
+
+	want := `comment
+-- one.txt --
+uno
+-- new/four.txt --
+four
+`
+	if got := string(txtar.Format(fsys.Archive())); got != want {
+		t.Errorf("Archive:\n%s\nwant:\n%s", got, want)
+	}
+	if got := string(txtar.Format(a)); got[len(got)-6:] != "three\n" {
+		t.Errorf("original archive was modified:\n%s", got)
+	}
+
+	for _, test := range []struct {
+		desc string
+		err  error // wanted error, or nil for any error
+		f    func() error
+	}{
+		{"write over a directory", nil, func() error { return fsys.WriteFile("new", nil) }},
+		{"write below a file", nil, func() error { return fsys.WriteFile("one.txt/x", nil) }},
+		{"write an invalid name", fs.ErrInvalid, func() error { return fsys.WriteFile("../x", nil) }},
+		{"remove a missing file", fs.ErrNotExist, func() error { return fsys.Remove("missing.txt") }},
+		{"remove a directory", nil, func() error { return fsys.Remove("new") }},
+	} {
+		err := test.f()
+		if err == nil {
+			t.Errorf("%s succeeded, want an error", test.desc)
+		} else if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: %v, want %v", test.desc, err, test.err)
+		}
+	}
+	if err := fsys.RemoveAll("missing"); err != nil {
+		t.Errorf("RemoveAll(missing): %v", err)
+	}
+}